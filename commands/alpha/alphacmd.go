@@ -19,6 +19,7 @@ import (
 
 	"github.com/GoogleContainerTools/kpt/commands/alpha/license"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/live"
+	porchcmd "github.com/GoogleContainerTools/kpt/commands/alpha/porch"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/repo"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/sync"
@@ -54,6 +55,7 @@ func GetCommand(ctx context.Context, name, version string) *cobra.Command {
 		wasm.NewCommand(ctx, version),
 		live.GetCommand(ctx, "", version),
 		license.NewCommand(ctx, version),
+		porchcmd.NewCommand(ctx, version),
 	)
 
 	return alpha
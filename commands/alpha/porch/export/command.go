@@ -0,0 +1,266 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/porchdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	command = "cmdporchexport"
+)
+
+// Archive is the archive of the state Porch keeps outside of its repositories. The
+// repository entries have the format of the repository exports of the Porch engine.
+type Archive struct {
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository is the metadata of the package revisions of a repository.
+type Repository struct {
+	Name             string            `json:"name"`
+	Namespace        string            `json:"namespace"`
+	Remote           Remote            `json:"remote"`
+	PackageRevisions []PackageRevision `json:"packageRevisions,omitempty"`
+}
+
+// Remote identifies the storage of a repository.
+type Remote struct {
+	Type      string `json:"type"`
+	Address   string `json:"address"`
+	Branch    string `json:"branch,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+// PackageRevision is the metadata of a package revision. The conditions are kept in the
+// repository and are archived for reference only.
+type PackageRevision struct {
+	Name        string                   `json:"name"`
+	PackageName string                   `json:"packageName"`
+	Revision    string                   `json:"revision,omitempty"`
+	Lifecycle   string                   `json:"lifecycle"`
+	Labels      map[string]string        `json:"labels,omitempty"`
+	Annotations map[string]string        `json:"annotations,omitempty"`
+	Conditions  []map[string]interface{} `json:"conditions,omitempty"`
+}
+
+// RemoteOf returns the remote of the repository, normalized so remotes written
+// differently compare equal. Keep in sync with the normalization of the Porch engine.
+func RemoteOf(repo *configapi.Repository) Remote {
+	switch {
+	case repo.Spec.Git != nil:
+		branch := repo.Spec.Git.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		return Remote{
+			Type:      string(configapi.RepositoryTypeGit),
+			Address:   strings.TrimSuffix(strings.TrimSuffix(repo.Spec.Git.Repo, "/"), ".git"),
+			Branch:    branch,
+			Directory: strings.Trim(repo.Spec.Git.Directory, "/"),
+		}
+	case repo.Spec.Oci != nil:
+		return Remote{
+			Type:    string(configapi.RepositoryTypeOCI),
+			Address: strings.TrimSuffix(repo.Spec.Oci.Registry, "/"),
+		}
+	default:
+		return Remote{Type: string(repo.Spec.Type)}
+	}
+}
+
+func (r Remote) String() string {
+	s := r.Type + " " + r.Address
+	if r.Branch != "" {
+		s += " branch " + r.Branch
+	}
+	if r.Directory != "" {
+		s += " directory " + r.Directory
+	}
+	return s
+}
+
+// ListPackageRevisions lists the package revisions of the namespace, or of all
+// namespaces if namespace is empty. The conditions of package revisions aren't part of
+// all versions of the PackageRevision API; use unstructured communication.
+func ListPackageRevisions(ctx context.Context, c client.Client, namespace string) ([]unstructured.Unstructured, error) {
+	var list unstructured.UnstructuredList
+	list.SetGroupVersionKind(porchapi.SchemeGroupVersion.WithKind("PackageRevisionList"))
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if err := c.List(ctx, &list, opts...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "export [flags]",
+		Short:   porchdocs.ExportShort,
+		Long:    porchdocs.ExportShort + "\n" + porchdocs.ExportLong,
+		Example: porchdocs.ExportExamples,
+		Args:    cobra.NoArgs,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVarP(&r.output, "output", "o", "", "File to write the archive to. Defaults to stdout.")
+	c.Flags().BoolVarP(&r.allNamespaces, "all-namespaces", "A", false, "Export the repositories of all namespaces.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	output        string
+	allNamespaces bool
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	namespace := *r.cfg.Namespace
+	if r.allNamespaces {
+		namespace = ""
+	}
+
+	var repositories configapi.RepositoryList
+	if err := r.client.List(r.ctx, &repositories, client.InNamespace(namespace)); err != nil {
+		return errors.E(op, err)
+	}
+	pkgRevs, err := ListPackageRevisions(r.ctx, r.client, namespace)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	archive := buildArchive(repositories.Items, pkgRevs)
+	b, err := yaml.Marshal(archive)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	if r.output == "" {
+		if _, err := cmd.OutOrStdout().Write(b); err != nil {
+			return errors.E(op, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(r.output, b, 0600); err != nil {
+		return errors.E(op, err)
+	}
+	count := 0
+	for _, repo := range archive.Repositories {
+		count += len(repo.PackageRevisions)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "exported %d repositories with %d package revisions to %s\n", len(archive.Repositories), count, r.output)
+	return nil
+}
+
+// buildArchive archives the metadata of the package revisions of the repositories.
+func buildArchive(repositories []configapi.Repository, pkgRevs []unstructured.Unstructured) *Archive {
+	byRepository := map[string][]PackageRevision{}
+	for _, pr := range pkgRevs {
+		repo, _, _ := unstructured.NestedString(pr.Object, "spec", "repository")
+		packageName, _, _ := unstructured.NestedString(pr.Object, "spec", "packageName")
+		revision, _, _ := unstructured.NestedString(pr.Object, "spec", "revision")
+		lifecycle, _, _ := unstructured.NestedString(pr.Object, "spec", "lifecycle")
+		conditions, _, _ := unstructured.NestedSlice(pr.Object, "status", "conditions")
+
+		labels := pr.GetLabels()
+		// The latest revision label is maintained by Porch.
+		delete(labels, porchapi.LatestPackageRevisionKey)
+		if len(labels) == 0 {
+			labels = nil
+		}
+		archived := PackageRevision{
+			Name:        pr.GetName(),
+			PackageName: packageName,
+			Revision:    revision,
+			Lifecycle:   lifecycle,
+			Labels:      labels,
+			Annotations: pr.GetAnnotations(),
+		}
+		for _, c := range conditions {
+			if c, ok := c.(map[string]interface{}); ok {
+				archived.Conditions = append(archived.Conditions, c)
+			}
+		}
+		key := pr.GetNamespace() + "/" + repo
+		byRepository[key] = append(byRepository[key], archived)
+	}
+
+	archive := &Archive{}
+	for i := range repositories {
+		repo := &repositories[i]
+		revisions := byRepository[repo.Namespace+"/"+repo.Name]
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].Name < revisions[j].Name })
+		archive.Repositories = append(archive.Repositories, Repository{
+			Name:             repo.Name,
+			Namespace:        repo.Namespace,
+			Remote:           RemoteOf(repo),
+			PackageRevisions: revisions,
+		})
+	}
+	sort.Slice(archive.Repositories, func(i, j int) bool {
+		a, b := archive.Repositories[i], archive.Repositories[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return archive
+}
@@ -0,0 +1,286 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importcmd implements the porch import command; import is a Go keyword.
+package importcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/commands/alpha/porch/export"
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/porchdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	command = "cmdporchimport"
+
+	actionCreate = "Create"
+	actionSkip   = "Skip"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "import ARCHIVE [flags]",
+		Short:   porchdocs.ImportShort,
+		Long:    porchdocs.ImportShort + "\n" + porchdocs.ImportLong,
+		Example: porchdocs.ImportExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().BoolVar(&r.dryRun, "dry-run", false, "Report what would be created and skipped without changing any package revision.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	dryRun bool
+}
+
+// result reports the import of an archived package revision.
+type result struct {
+	namespace  string
+	repository string
+	name       string
+	action     string
+	reason     string
+
+	// labels and annotations are the archived labels and annotations to add to the
+	// package revision.
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return errors.E(op, err)
+	}
+	var archive export.Archive
+	if err := yaml.Unmarshal(b, &archive); err != nil {
+		return errors.E(op, fmt.Errorf("invalid archive %s: %w", args[0], err))
+	}
+
+	// Check all remotes before changing anything.
+	registered := map[string]bool{}
+	for _, archived := range archive.Repositories {
+		var repo configapi.Repository
+		err := r.client.Get(r.ctx, client.ObjectKey{Namespace: archived.Namespace, Name: archived.Name}, &repo)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.E(op, err)
+		}
+		if actual := export.RemoteOf(&repo); actual != archived.Remote {
+			return errors.E(op, fmt.Errorf("repository %s/%s refers to %s; the archive was made from %s", archived.Namespace, archived.Name, actual, archived.Remote))
+		}
+		registered[archived.Namespace+"/"+archived.Name] = true
+	}
+
+	existing := map[string]*unstructured.Unstructured{}
+	listed := map[string]bool{}
+	for _, archived := range archive.Repositories {
+		if !registered[archived.Namespace+"/"+archived.Name] || listed[archived.Namespace] {
+			continue
+		}
+		pkgRevs, err := export.ListPackageRevisions(r.ctx, r.client, archived.Namespace)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		for i := range pkgRevs {
+			existing[pkgRevs[i].GetNamespace()+"/"+pkgRevs[i].GetName()] = &pkgRevs[i]
+		}
+		listed[archived.Namespace] = true
+	}
+
+	results := planImport(&archive, registered, existing)
+	if !r.dryRun {
+		for _, res := range results {
+			if res.action != actionCreate {
+				continue
+			}
+			if err := r.restore(existing[res.namespace+"/"+res.name], res); err != nil {
+				return errors.E(op, err)
+			}
+		}
+	}
+	return printReport(cmd.OutOrStdout(), results, r.dryRun)
+}
+
+// planImport decides what to do with each archived package revision. Only labels and
+// annotations missing from a package revision are added: values which were changed
+// since the archive was made are kept.
+func planImport(archive *export.Archive, registered map[string]bool, existing map[string]*unstructured.Unstructured) []result {
+	var results []result
+	for _, archivedRepo := range archive.Repositories {
+		for _, archived := range archivedRepo.PackageRevisions {
+			res := result{
+				namespace:  archivedRepo.Namespace,
+				repository: archivedRepo.Name,
+				name:       archived.Name,
+				action:     actionSkip,
+			}
+			pr, found := existing[archivedRepo.Namespace+"/"+archived.Name]
+			switch {
+			case !registered[archivedRepo.Namespace+"/"+archivedRepo.Name]:
+				res.reason = "repository not registered"
+			case !found:
+				res.reason = "package revision not found in the repository"
+			default:
+				res.labels = missing(archived.Labels, pr.GetLabels())
+				res.annotations = missing(archived.Annotations, pr.GetAnnotations())
+				if len(res.labels) == 0 && len(res.annotations) == 0 {
+					res.reason = "labels and annotations already exist"
+				} else {
+					res.action = actionCreate
+				}
+			}
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// missing returns the entries of archived whose keys aren't in current.
+func missing(archived, current map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range archived {
+		if _, found := current[k]; found {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// restore adds the archived labels and annotations of the result to the package
+// revision.
+func (r *runner) restore(pr *unstructured.Unstructured, res result) error {
+	labels := pr.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range res.labels {
+		labels[k] = v
+	}
+	pr.SetLabels(labels)
+
+	annotations := pr.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range res.annotations {
+		annotations[k] = v
+	}
+	pr.SetAnnotations(annotations)
+
+	if err := r.client.Update(r.ctx, pr); err != nil {
+		return fmt.Errorf("cannot restore the metadata of package revision %s/%s: %w", res.namespace, res.name, err)
+	}
+	return nil
+}
+
+func printReport(out io.Writer, results []result, dryRun bool) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "NAMESPACE\tREPOSITORY\tNAME\tACTION\tDETAILS")
+	created, skipped := 0, 0
+	for _, res := range results {
+		details := res.reason
+		if res.action == actionCreate {
+			created++
+			details = describe(res)
+		} else {
+			skipped++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", res.namespace, res.repository, res.name, res.action, details)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(out, "%d package revisions would be restored, %d skipped\n", created, skipped)
+	} else {
+		fmt.Fprintf(out, "%d package revisions restored, %d skipped\n", created, skipped)
+	}
+	return nil
+}
+
+// describe lists the keys of the labels and annotations the result adds.
+func describe(res result) string {
+	var parts []string
+	if len(res.labels) != 0 {
+		parts = append(parts, "labels "+strings.Join(sortedKeys(res.labels), ","))
+	}
+	if len(res.annotations) != 0 {
+		parts = append(parts, "annotations "+strings.Join(sortedKeys(res.annotations), ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/repodocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrepoedit"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "edit REPOSITORY [flags]",
+		Short:   repodocs.EditShort,
+		Long:    repodocs.EditShort + "\n" + repodocs.EditLong,
+		Example: repodocs.EditExamples,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.directory, "directory", "", "Directory within the repository where to look for packages.")
+	c.Flags().StringVar(&r.branch, "branch", "", "Branch in the repository where finalized packages are committed.")
+	c.Flags().StringVar(&r.repo, "repo", "", "Address of the git repository.")
+	c.Flags().StringVar(&r.description, "description", "", "Brief description of the package repository.")
+	c.Flags().BoolVar(&r.dryRun, "dry-run", false, "Validate the change and report its impact without applying it.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	directory   string
+	branch      string
+	repo        string
+	description string
+	dryRun      bool
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	// Porch reports the impact of risky changes as warnings; show them to the user.
+	wrap := r.cfg.WrapConfigFn
+	r.cfg.WrapConfigFn = func(rc *rest.Config) *rest.Config {
+		if wrap != nil {
+			rc = wrap(rc)
+		}
+		rc.WarningHandler = rest.NewWarningWriter(cmd.ErrOrStderr(), rest.WarningWriterOptions{Deduplicate: true})
+		return rc
+	}
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	if len(args) == 0 {
+		return errors.E(op, fmt.Errorf("REPOSITORY is a required positional argument"))
+	}
+
+	key := client.ObjectKey{
+		Namespace: *r.cfg.Namespace,
+		Name:      args[0],
+	}
+
+	// Use unstructured communication so that fields of the Repository API unknown to
+	// this version of kpt are preserved.
+	repo := &unstructured.Unstructured{}
+	repo.SetGroupVersionKind(configapi.GroupVersion.WithKind("Repository"))
+	if err := r.client.Get(r.ctx, key, repo); err != nil {
+		return errors.E(op, err)
+	}
+
+	flags := cmd.Flags()
+	type field struct {
+		flag  string
+		value string
+		path  []string
+	}
+	var changed bool
+	for _, f := range []field{
+		{flag: "description", value: r.description, path: []string{"spec", "description"}},
+		{flag: "repo", value: r.repo, path: []string{"spec", "git", "repo"}},
+		{flag: "branch", value: r.branch, path: []string{"spec", "git", "branch"}},
+		{flag: "directory", value: r.directory, path: []string{"spec", "git", "directory"}},
+	} {
+		if !flags.Changed(f.flag) {
+			continue
+		}
+		if f.path[1] == "git" {
+			if _, found, _ := unstructured.NestedMap(repo.Object, "spec", "git"); !found {
+				return errors.E(op, fmt.Errorf("--%s is only supported for git repositories", f.flag))
+			}
+		}
+		if err := unstructured.SetNestedField(repo.Object, f.value, f.path...); err != nil {
+			return errors.E(op, err)
+		}
+		changed = true
+	}
+	if !changed {
+		return errors.E(op, fmt.Errorf("no changes specified"))
+	}
+
+	var opts []client.UpdateOption
+	if r.dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	if err := r.client.Update(r.ctx, repo, opts...); err != nil {
+		return errors.E(op, err)
+	}
+
+	if r.dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s edited (dry run)\n", key.Name)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s edited\n", key.Name)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/repodocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrepofsck"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "fsck REPOSITORY [flags]",
+		Short:   repodocs.FsckShort,
+		Long:    repodocs.FsckShort + "\n" + repodocs.FsckLong,
+		Example: repodocs.FsckExamples,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().BoolVar(&r.repair, "repair", false, "Refresh the cache and fix the metadata of the repository.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	repair bool
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	if len(args) == 0 {
+		return errors.E(op, fmt.Errorf("REPOSITORY is a required positional argument"))
+	}
+	repository := args[0]
+
+	// The RepositoryConsistencyCheck isn't part of all versions of the Porch API;
+	// use unstructured communication. Porch runs the check when it is created and
+	// returns it with the findings.
+	check := &unstructured.Unstructured{}
+	check.SetGroupVersionKind(porchapi.SchemeGroupVersion.WithKind("RepositoryConsistencyCheck"))
+	check.SetNamespace(*r.cfg.Namespace)
+	if err := unstructured.SetNestedField(check.Object, repository, "spec", "repositoryName"); err != nil {
+		return errors.E(op, err)
+	}
+	if err := unstructured.SetNestedField(check.Object, r.repair, "spec", "repair"); err != nil {
+		return errors.E(op, err)
+	}
+	if err := r.client.Create(r.ctx, check); err != nil {
+		return errors.E(op, err)
+	}
+
+	findings, _, err := unstructured.NestedSlice(check.Object, "status", "findings")
+	if err != nil {
+		return errors.E(op, err)
+	}
+	repaired := 0
+	for _, f := range findings {
+		finding, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		findingType, _, _ := unstructured.NestedString(finding, "type")
+		packageRevision, _, _ := unstructured.NestedString(finding, "packageRevision")
+		message, _, _ := unstructured.NestedString(finding, "message")
+		line := fmt.Sprintf("%s %s: %s", findingType, packageRevision, message)
+		if fixed, _, _ := unstructured.NestedBool(finding, "repaired"); fixed {
+			repaired++
+			line += " (repaired)"
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), line)
+	}
+	if r.repair {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d inconsistencies found in %s, %d repaired\n", len(findings), repository, repaired)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d inconsistencies found in %s\n", len(findings), repository)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prune
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/repodocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrepoprune"
+
+	// Keep in sync with configapi.PruneRequestAnnotation and configapi.PruneDryRunAnnotation.
+	pruneRequestAnnotation = "config.porch.kpt.dev/prune-request"
+	pruneDryRunAnnotation  = "config.porch.kpt.dev/prune-dry-run"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "prune REPOSITORY [flags]",
+		Short:   repodocs.PruneShort,
+		Long:    repodocs.PruneShort + "\n" + repodocs.PruneLong,
+		Example: repodocs.PruneExamples,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().BoolVar(&r.dryRun, "dry-run", false, "List the stale refs and the bytes that would be reclaimed without deleting them.")
+	c.Flags().DurationVar(&r.timeout, "timeout", 2*time.Minute, "How long to wait for Porch to run the prune.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	dryRun  bool
+	timeout time.Duration
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	if len(args) == 0 {
+		return errors.E(op, fmt.Errorf("REPOSITORY is a required positional argument"))
+	}
+
+	key := client.ObjectKey{
+		Namespace: *r.cfg.Namespace,
+		Name:      args[0],
+	}
+
+	// The prune status isn't part of all versions of the Repository API;
+	// use unstructured communication.
+	repo := &unstructured.Unstructured{}
+	repo.SetGroupVersionKind(configapi.GroupVersion.WithKind("Repository"))
+	if err := r.client.Get(r.ctx, key, repo); err != nil {
+		return errors.E(op, err)
+	}
+
+	// Request the prune; Porch runs it and reports the result in the repository status.
+	request := strconv.FormatInt(time.Now().UnixNano(), 10)
+	patch := client.MergeFrom(repo.DeepCopy())
+	annotations := repo.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[pruneRequestAnnotation] = request
+	if r.dryRun {
+		annotations[pruneDryRunAnnotation] = "true"
+	} else {
+		delete(annotations, pruneDryRunAnnotation)
+	}
+	repo.SetAnnotations(annotations)
+	if err := r.client.Patch(r.ctx, repo, patch); err != nil {
+		return errors.E(op, err)
+	}
+
+	var status map[string]interface{}
+	if err := wait.PollImmediate(time.Second, r.timeout, func() (bool, error) {
+		if err := r.client.Get(r.ctx, key, repo); err != nil {
+			return false, err
+		}
+		prune, found, err := unstructured.NestedMap(repo.Object, "status", "prune")
+		if err != nil || !found || prune["request"] != request {
+			return false, err
+		}
+		status = prune
+		return true, nil
+	}); err != nil {
+		return errors.E(op, fmt.Errorf("waiting for repository %s to be pruned: %w", key.Name, err))
+	}
+
+	if msg, _, _ := unstructured.NestedString(status, "error"); msg != "" {
+		return errors.E(op, fmt.Errorf("failed to prune repository %s: %s", key.Name, msg))
+	}
+
+	refs, _, _ := unstructured.NestedStringSlice(status, "refs")
+	bytes, _, _ := unstructured.NestedInt64(status, "bytes")
+	for _, ref := range refs {
+		fmt.Fprintln(cmd.OutOrStdout(), ref)
+	}
+	if r.dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d stale refs would be pruned from %s, reclaiming %d bytes\n", len(refs), key.Name, bytes)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d stale refs pruned from %s, reclaiming %d bytes\n", len(refs), key.Name, bytes)
+	}
+	return nil
+}
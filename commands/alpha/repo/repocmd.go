@@ -19,7 +19,10 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/edit"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/fsck"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/get"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/prune"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/reg"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/repo/unreg"
 	"github.com/GoogleContainerTools/kpt/internal/docs/generated/repodocs"
@@ -64,6 +67,9 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 		reg.NewCommand(ctx, kubeflags),
 		get.NewCommand(ctx, kubeflags),
 		unreg.NewCommand(ctx, kubeflags),
+		prune.NewCommand(ctx, kubeflags),
+		edit.NewCommand(ctx, kubeflags),
+		fsck.NewCommand(ctx, kubeflags),
 	)
 
 	return repo
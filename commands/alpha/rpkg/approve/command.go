@@ -17,6 +17,7 @@ package approve
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
@@ -24,6 +25,7 @@ import (
 	"github.com/GoogleContainerTools/kpt/internal/util/porch"
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +33,8 @@ import (
 
 const (
 	command = "cmdrpkgapprove"
+
+	outcomePublished = "Published"
 )
 
 func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
@@ -59,10 +63,12 @@ func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner
 }
 
 type runner struct {
-	ctx     context.Context
-	cfg     *genericclioptions.ConfigFlags
-	client  rest.Interface
-	Command *cobra.Command
+	ctx    context.Context
+	cfg    *genericclioptions.ConfigFlags
+	client rest.Interface
+	// bulkClient creates the BulkApproval of several package revisions.
+	bulkClient client.Client
+	Command    *cobra.Command
 
 	// Flags
 }
@@ -75,6 +81,14 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, err)
 	}
 	r.client = client
+
+	if len(args) > 1 {
+		bulkClient, err := porch.CreateClient(r.cfg)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		r.bulkClient = bulkClient
+	}
 	return nil
 }
 
@@ -84,6 +98,10 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 
 	namespace := *r.cfg.Namespace
 
+	if len(args) > 1 {
+		return r.approveAll(args)
+	}
+
 	for _, name := range args {
 		if err := porch.UpdatePackageRevisionApproval(r.ctx, r.client, client.ObjectKey{
 			Namespace: namespace,
@@ -102,3 +120,55 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// approveAll approves the package revisions with a BulkApproval, which checks all of them
+// before publishing any, and publishes them concurrently.
+func (r *runner) approveAll(names []string) error {
+	const op errors.Op = command + ".approveAll"
+
+	// The BulkApproval isn't part of all versions of the Porch API; use unstructured
+	// communication. Porch runs the approval when it is created and returns it with the
+	// outcome.
+	approval := &unstructured.Unstructured{}
+	approval.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("BulkApproval"))
+	approval.SetNamespace(*r.cfg.Namespace)
+	packageRevisions := make([]interface{}, len(names))
+	for i, name := range names {
+		packageRevisions[i] = name
+	}
+	if err := unstructured.SetNestedSlice(approval.Object, packageRevisions, "spec", "packageRevisions"); err != nil {
+		return errors.E(op, err)
+	}
+	if err := r.bulkClient.Create(r.ctx, approval); err != nil {
+		return errors.E(op, err)
+	}
+
+	if failed := printApproval(r.Command.OutOrStderr(), r.Command.ErrOrStderr(), approval); len(failed) > 0 {
+		return errors.E(op, fmt.Errorf("package revisions not approved: %s", strings.Join(failed, ", ")))
+	}
+	return nil
+}
+
+// printApproval prints the outcome for each package revision of the approval, and returns
+// the names of the package revisions which weren't published.
+func printApproval(out, errOut io.Writer, approval *unstructured.Unstructured) []string {
+	var failed []string
+	results, _, _ := unstructured.NestedSlice(approval.Object, "status", "results")
+	for _, r := range results {
+		result, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(result, "name")
+		outcome, _, _ := unstructured.NestedString(result, "outcome")
+		if outcome == outcomePublished {
+			revision, _, _ := unstructured.NestedString(result, "revision")
+			fmt.Fprintf(out, "%s approved (revision %s)\n", name, revision)
+			continue
+		}
+		message, _, _ := unstructured.NestedString(result, "message")
+		fmt.Fprintf(errOut, "%s %s (%s)\n", name, strings.ToLower(outcome), message)
+		failed = append(failed, name)
+	}
+	return failed
+}
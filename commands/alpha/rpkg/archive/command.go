@@ -0,0 +1,98 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrpkgarchive"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "archive PACKAGE [flags]",
+		Short:   rpkgdocs.ArchiveShort,
+		Long:    rpkgdocs.ArchiveShort + "\n" + rpkgdocs.ArchiveLong,
+		Example: rpkgdocs.ArchiveExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.repository, "repository", "", "Repository containing the package. Required if more than one repository in the namespace contains a package with the name.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	repository string
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	pkg, err := porch.FindPackage(r.ctx, r.client, *r.cfg.Namespace, r.repository, args[0], false)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if err := unstructured.SetNestedField(pkg.Object, true, "spec", "archived"); err != nil {
+		return errors.E(op, err)
+	}
+	if err := r.client.Update(r.ctx, pkg); err != nil {
+		return errors.E(op, fmt.Errorf("cannot archive package %q: %w", args[0], err))
+	}
+	repository, _, _ := unstructured.NestedString(pkg.Object, "spec", "repository")
+	fmt.Fprintf(r.Command.OutOrStdout(), "%s archived in repository %s\n", args[0], repository)
+	return nil
+}
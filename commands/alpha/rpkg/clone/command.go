@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -68,6 +69,12 @@ func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner
 	c.Flags().StringVar(&r.ref, "ref", "", "Branch in the repository where the upstream package is located.")
 	c.Flags().StringVar(&r.repository, "repository", "", "Repository to which package will be cloned (downstream repository).")
 	c.Flags().StringVar(&r.revision, "revision", "v1", "Revision of the downstream package.")
+	c.Flags().StringArrayVar(&r.contextFlags, "context", nil,
+		"Value of the package context of the new package, as key=value. Can be repeated.")
+	c.Flags().StringArrayVar(&r.labelFlags, "label", nil,
+		"Label of the new package revision, as key=value. Can be repeated.")
+	c.Flags().BoolVar(&r.propose, "propose", false, "Create the new package revision as proposed rather than draft.")
+	c.Flags().BoolVar(&r.dryRun, "dry-run", false, "Print the package revision that would be created, without creating it.")
 
 	return r
 }
@@ -78,7 +85,9 @@ type runner struct {
 	client  client.Client
 	Command *cobra.Command
 
-	clone porchapi.PackageCloneTaskSpec
+	clone   porchapi.PackageCloneTaskSpec
+	context map[string]string
+	labels  map[string]string
 
 	// Flags
 	strategy   string
@@ -87,6 +96,11 @@ type runner struct {
 	repository string // Target repository
 	revision   string // Target package revision
 	target     string // Target package name
+
+	contextFlags []string
+	labelFlags   []string
+	propose      bool
+	dryRun       bool
 }
 
 func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
@@ -111,6 +125,21 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, fmt.Errorf("--repository is required to specify downstream repository"))
 	}
 
+	// Validate the context values and labels before anything is created, so a package
+	// revision is never left half configured.
+	if r.context, err = parseKeyValues("context", r.contextFlags); err != nil {
+		return errors.E(op, err)
+	}
+	if err := validateContext(r.context); err != nil {
+		return errors.E(op, err)
+	}
+	if r.labels, err = parseKeyValues("label", r.labelFlags); err != nil {
+		return errors.E(op, err)
+	}
+	if err := validateLabels(r.labels); err != nil {
+		return errors.E(op, err)
+	}
+
 	source := args[0]
 	target := args[1]
 
@@ -172,32 +201,59 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 func (r *runner) runE(cmd *cobra.Command, args []string) error {
 	const op errors.Op = command + ".runE"
 
-	pr := &porchapi.PackageRevision{
+	pr := r.packageRevision()
+	if r.dryRun {
+		out, err := yaml.Marshal(pr)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	}
+
+	if err := r.client.Create(r.ctx, pr); err != nil {
+		return errors.E(op, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s created (%s)\n", pr.Name, pr.Spec.Lifecycle)
+	return nil
+}
+
+// packageRevision returns the package revision to create: the clone of the source
+// package, with its package context values set by a function evaluated after the clone,
+// in a single request.
+func (r *runner) packageRevision() *porchapi.PackageRevision {
+	tasks := []porchapi.Task{
+		{
+			Type:  porchapi.TaskTypeClone,
+			Clone: &r.clone,
+		},
+	}
+	if len(r.context) != 0 {
+		tasks = append(tasks, setContextTask(r.context))
+	}
+	lifecycle := porchapi.PackageRevisionLifecycleDraft
+	if r.propose {
+		lifecycle = porchapi.PackageRevisionLifecycleProposed
+	}
+
+	return &porchapi.PackageRevision{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PackageRevision",
 			APIVersion: porchapi.SchemeGroupVersion.Identifier(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: *r.cfg.Namespace,
+			Labels:    r.labels,
 		},
 		Spec: porchapi.PackageRevisionSpec{
 			PackageName:    r.target,
 			Revision:       r.revision,
 			RepositoryName: r.repository,
-			Tasks: []porchapi.Task{
-				{
-					Type:  porchapi.TaskTypeClone,
-					Clone: &r.clone,
-				},
-			},
+			Lifecycle:      lifecycle,
+			Tasks:          tasks,
 		},
 	}
-	if err := r.client.Create(r.ctx, pr); err != nil {
-		return errors.E(op, err)
-	}
-
-	fmt.Fprintf(cmd.OutOrStdout(), "%s created\n", pr.Name)
-	return nil
 }
 
 func toMergeStrategy(strategy string) (porchapi.PackageMergeStrategy, error) {
@@ -0,0 +1,105 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clone
+
+import (
+	"testing"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	testcases := map[string]struct {
+		input    []string
+		expected map[string]string
+		err      string
+	}{
+		"none": {},
+		"pairs": {
+			input:    []string{"region=us-east1", "tier=", "expr=a=b"},
+			expected: map[string]string{"region": "us-east1", "tier": "", "expr": "a=b"},
+		},
+		"no value": {
+			input: []string{"region"},
+			err:   `invalid --context "region": must be of the form key=value`,
+		},
+		"no key": {
+			input: []string{"=us-east1"},
+			err:   `invalid --context "=us-east1": must be of the form key=value`,
+		},
+		"duplicate": {
+			input: []string{"region=a", "region=b"},
+			err:   `invalid --context "region=b": key "region" is set more than once`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			output, err := parseKeyValues("context", tc.input)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Equal(t, tc.err, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validateContext(map[string]string{"region": "us-east1", "site.id": "x"}))
+	require.Error(t, validateContext(map[string]string{"package-path": "foo"}))
+	require.Error(t, validateContext(map[string]string{"a/b": "x"}))
+
+	require.NoError(t, validateLabels(map[string]string{"example.com/team": "blue"}))
+	require.Error(t, validateLabels(map[string]string{"team": "not a value"}))
+	require.Error(t, validateLabels(map[string]string{"-team": "blue"}))
+}
+
+func TestPackageRevision(t *testing.T) {
+	namespace := "default"
+	r := &runner{
+		cfg:        &genericclioptions.ConfigFlags{Namespace: &namespace},
+		target:     "foo",
+		revision:   "v1",
+		repository: "deployments",
+		context:    map[string]string{"region": "us-east1"},
+		labels:     map[string]string{"team": "blue"},
+		propose:    true,
+	}
+	r.clone.Upstream.UpstreamRef = &porchapi.PackageRevisionRef{Name: "blueprints-123"}
+
+	pr := r.packageRevision()
+	require.Equal(t, map[string]string{"team": "blue"}, pr.Labels)
+	require.Equal(t, porchapi.PackageRevisionLifecycleProposed, pr.Spec.Lifecycle)
+	require.Len(t, pr.Spec.Tasks, 2)
+	require.Equal(t, porchapi.TaskTypeClone, pr.Spec.Tasks[0].Type)
+
+	eval := pr.Spec.Tasks[1].Eval
+	require.Equal(t, porchapi.TaskTypeEval, pr.Spec.Tasks[1].Type)
+	require.Equal(t, setContextImage, eval.Image)
+	require.Equal(t, map[string]string{
+		"source":         setContextSource,
+		"context.region": "us-east1",
+	}, eval.ConfigMap)
+
+	r.context, r.propose = nil, false
+	pr = r.packageRevision()
+	require.Equal(t, porchapi.PackageRevisionLifecycleDraft, pr.Spec.Lifecycle)
+	require.Len(t, pr.Spec.Tasks, 1)
+}
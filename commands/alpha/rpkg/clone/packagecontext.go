@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clone
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// setContextImage is the function setting the package context values. It is a
+	// builtin function of Porch.
+	setContextImage = "gcr.io/kpt-fn/starlark:v0.4.3"
+
+	// contextKeyPrefix prefixes the keys of the package context values in the function
+	// config, so they can't clash with the source of the program.
+	contextKeyPrefix = "context."
+)
+
+// setContextSource is the starlark program setting the package context values passed in
+// its function config. It fails if the package has no package context, so the package
+// revision isn't created without its context values.
+var setContextSource = fmt.Sprintf(`
+def is_root_context(r):
+  if r.get("apiVersion") != "v1" or r.get("kind") != "ConfigMap":
+    return False
+  metadata = r.get("metadata") or {}
+  if metadata.get("name") != %[1]q:
+    return False
+  annotations = metadata.get("annotations") or {}
+  path = annotations.get("internal.config.kubernetes.io/path") or annotations.get("config.kubernetes.io/path")
+  return path == %[2]q
+
+def set_context(resources, params):
+  for r in resources:
+    if is_root_context(r):
+      data = r.get("data") or {}
+      for k, v in params.items():
+        if k.startswith(%[3]q):
+          data[k[len(%[3]q):]] = v
+      r["data"] = data
+      return
+  fail("package has no package context (%[2]s); package context values can only be set in deployment repositories")
+
+set_context(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["data"])
+`, builtins.PkgContextName, builtins.PkgContextFile, contextKeyPrefix)
+
+// parseKeyValues parses the key=value pairs of the flag.
+func parseKeyValues(flag string, pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, found := strings.Cut(pair, "=")
+		if !found || k == "" {
+			return nil, fmt.Errorf("invalid --%s %q: must be of the form key=value", flag, pair)
+		}
+		if _, dup := values[k]; dup {
+			return nil, fmt.Errorf("invalid --%s %q: key %q is set more than once", flag, pair, k)
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// validateLabels returns an error if a label key or value is invalid.
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+			return fmt.Errorf("invalid --label key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return fmt.Errorf("invalid --label value %q of key %q: %s", v, k, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// validateContext returns an error if a package context key is invalid, or is set by
+// Porch.
+func validateContext(values map[string]string) error {
+	for k := range values {
+		if errs := validation.IsConfigMapKey(k); len(errs) != 0 {
+			return fmt.Errorf("invalid --context key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if k == builtins.ConfigKeyPackagePath {
+			return fmt.Errorf("invalid --context key %q: the key is set by Porch", k)
+		}
+	}
+	return nil
+}
+
+// setContextTask returns the task setting the package context values.
+func setContextTask(values map[string]string) porchapi.Task {
+	config := make(map[string]string, len(values)+1)
+	config["source"] = setContextSource
+	for k, v := range values {
+		config[contextKeyPrefix+k] = v
+	}
+	return porchapi.Task{
+		Type: porchapi.TaskTypeEval,
+		Eval: &porchapi.FunctionEvalTaskSpec{
+			Image:     setContextImage,
+			ConfigMap: config,
+		},
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrpkgfreeze"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "freeze PACKAGE [flags]",
+		Short:   rpkgdocs.FreezeShort,
+		Long:    rpkgdocs.FreezeShort + "\n" + rpkgdocs.FreezeLong,
+		Example: rpkgdocs.FreezeExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.repository, "repository", "", "Repository containing the package. Required if more than one repository in the namespace contains a package with the name.")
+	c.Flags().StringVar(&r.reason, "reason", "", "Reason for freezing the package, reported to users whose changes are rejected. Required.")
+
+	return r
+}
+
+type runner struct {
+	ctx        context.Context
+	cfg        *genericclioptions.ConfigFlags
+	client     client.Client
+	restClient rest.Interface
+	Command    *cobra.Command
+
+	// Flags
+	repository string
+	reason     string
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	if strings.TrimSpace(r.reason) == "" {
+		return errors.E(op, fmt.Errorf("--reason is required"))
+	}
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+
+	restClient, err := porch.CreateRESTClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.restClient = restClient
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	pkg, err := porch.FindPackage(r.ctx, r.client, *r.cfg.Namespace, r.repository, args[0], false)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	frozen, err := porch.UpdatePackageFreeze(r.ctx, r.restClient, pkg, r.reason)
+	if err != nil {
+		return errors.E(op, fmt.Errorf("cannot freeze package %q: %w", args[0], err))
+	}
+	repository, _, _ := unstructured.NestedString(frozen.Object, "spec", "repository")
+	frozenBy, _, _ := unstructured.NestedString(frozen.Object, "spec", "freeze", "frozenBy")
+	if frozenBy != "" {
+		fmt.Fprintf(r.Command.OutOrStdout(), "%s frozen in repository %s by %s\n", args[0], repository, frozenBy)
+	} else {
+		fmt.Fprintf(r.Command.OutOrStdout(), "%s frozen in repository %s\n", args[0], repository)
+	}
+	return nil
+}
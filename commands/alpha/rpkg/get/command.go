@@ -62,6 +62,7 @@ func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner
 	// Create flags
 	cmd.Flags().StringVar(&r.packageName, "name", "", "Name of the packages to get. Any package whose name contains this value will be included in the results.")
 	cmd.Flags().StringVar(&r.revision, "revision", "", "Revision of the packages to get. Any package whose revision matches this value will be included in the results.")
+	cmd.Flags().BoolVar(&r.archived, "archived", false, "Get the package revisions of archived packages instead of active ones.")
 
 	r.getFlags.AddFlags(cmd)
 	r.printFlags.AddFlags(cmd)
@@ -80,6 +81,7 @@ type runner struct {
 	// Flags
 	packageName string
 	revision    string
+	archived    bool
 	printFlags  *get.PrintFlags
 
 	requestTable bool
@@ -139,6 +141,14 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 		if r.packageName != "" {
 			fieldSelector = fields.OneTermEqualSelector("spec.packageName", r.packageName)
 		}
+		if r.archived {
+			archived := fields.OneTermEqualSelector("status.archived", "true")
+			if fieldSelector.Empty() {
+				fieldSelector = archived
+			} else {
+				fieldSelector = fields.AndSelectors(fieldSelector, archived)
+			}
+		}
 		if s := fieldSelector.String(); s != "" {
 			b = b.FieldSelectorParam(s)
 		} else {
@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	command = "cmdrpkglogs"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "logs PACKAGE_REVISION [flags]",
+		Short:   rpkgdocs.LogsShort,
+		Long:    rpkgdocs.LogsShort + "\n" + rpkgdocs.LogsLong,
+		Example: rpkgdocs.LogsExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().IntVar(&r.task, "task", -1, "Index of the task to print the function logs of. If unspecified, the logs of all tasks are printed.")
+
+	return r
+}
+
+type runner struct {
+	ctx        context.Context
+	cfg        *genericclioptions.ConfigFlags
+	restClient rest.Interface
+	Command    *cobra.Command
+
+	// Flags
+	task int
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	if cmd.Flags().Changed("task") && r.task < 0 {
+		return errors.E(op, fmt.Errorf("--task must not be negative"))
+	}
+
+	restClient, err := porch.CreateRESTClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.restClient = restClient
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	name := args[0]
+	tasks, err := porch.GetPackageRevisionLogs(r.ctx, r.restClient, *r.cfg.Namespace, name)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	if r.task >= 0 {
+		var selected []porch.TaskLog
+		for _, task := range tasks {
+			if task.Task == r.task {
+				selected = append(selected, task)
+			}
+		}
+		if len(selected) == 0 {
+			return errors.E(op, fmt.Errorf("no function logs of task %d of %s; the task ran no functions which logged, or the logs expired", r.task, name))
+		}
+		tasks = selected
+	} else if len(tasks) == 0 {
+		return errors.E(op, fmt.Errorf("no function logs of %s; its tasks ran no functions which logged, or the logs expired", name))
+	}
+	printLogs(cmd.OutOrStdout(), tasks)
+	return nil
+}
+
+// printLogs prints the log of each function under a header naming its task and image.
+func printLogs(out io.Writer, tasks []porch.TaskLog) {
+	for _, task := range tasks {
+		for _, function := range task.Functions {
+			fmt.Fprintf(out, "--- task %d: %s\n", task.Task, function.Image)
+			if function.Truncated {
+				fmt.Fprintln(out, "[beginning of log truncated]")
+			}
+			fmt.Fprint(out, function.Log)
+			if !strings.HasSuffix(function.Log, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package move
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrpkgmove"
+
+	// outcomeFailed is the outcome of a step of the move which failed.
+	outcomeFailed = "Failed"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "move PACKAGE [flags]",
+		Short:   rpkgdocs.MoveShort,
+		Long:    rpkgdocs.MoveShort + "\n" + rpkgdocs.MoveLong,
+		Example: rpkgdocs.MoveExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.repository, "repository", "", "Repository the package is moved from.")
+	c.Flags().StringVar(&r.destination, "destination", "", "Repository the package is moved to.")
+	c.Flags().BoolVar(&r.includeHistory, "include-history", false, "Move all published revisions of the package instead of the latest.")
+	c.Flags().BoolVar(&r.updateDownstreams, "update-downstreams", false, "Update the drafts based on the moved revisions to the moved revisions.")
+	c.Flags().BoolVar(&r.dryRun, "dry-run", false, "Print the steps of the move without running them.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	repository        string
+	destination       string
+	includeHistory    bool
+	updateDownstreams bool
+	dryRun            bool
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	if r.repository == "" {
+		return errors.E(op, fmt.Errorf("--repository is required to specify the repository of the package"))
+	}
+	if r.destination == "" {
+		return errors.E(op, fmt.Errorf("--destination is required to specify the repository to move the package to"))
+	}
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	packageName := args[0]
+
+	// The PackageMove isn't part of all versions of the Porch API; use unstructured
+	// communication. Porch runs the move when it is created and returns it with the
+	// outcome.
+	move := &unstructured.Unstructured{}
+	move.SetGroupVersionKind(porchapi.SchemeGroupVersion.WithKind("PackageMove"))
+	move.SetNamespace(*r.cfg.Namespace)
+	for field, value := range map[string]interface{}{
+		"packageName":           packageName,
+		"sourceRepository":      r.repository,
+		"destinationRepository": r.destination,
+		"includeHistory":        r.includeHistory,
+		"updateDownstreams":     r.updateDownstreams,
+		"dryRun":                r.dryRun,
+	} {
+		if err := unstructured.SetNestedField(move.Object, value, "spec", field); err != nil {
+			return errors.E(op, err)
+		}
+	}
+	if err := r.client.Create(r.ctx, move); err != nil {
+		return errors.E(op, err)
+	}
+
+	if failed := printMove(cmd.OutOrStdout(), move); failed {
+		return errors.E(op, fmt.Errorf("package %s was not completely moved; run the command again to resume the move", packageName))
+	}
+	return nil
+}
+
+// printMove prints the outcome of each step of the move, and returns true if a step
+// failed.
+func printMove(out io.Writer, move *unstructured.Unstructured) bool {
+	failed := false
+	outcome := func(step map[string]interface{}) string {
+		o, _, _ := unstructured.NestedString(step, "outcome")
+		if o == outcomeFailed {
+			failed = true
+		}
+		if message, _, _ := unstructured.NestedString(step, "message"); message != "" {
+			return o + ": " + message
+		}
+		return o
+	}
+
+	revisions, _, _ := unstructured.NestedSlice(move.Object, "status", "revisions")
+	for _, r := range revisions {
+		revision, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, _, _ := unstructured.NestedString(revision, "source")
+		destination, _, _ := unstructured.NestedString(revision, "destination")
+		if destination == "" {
+			destination = "(new)"
+		}
+		fmt.Fprintf(out, "revision %s -> %s: %s\n", source, destination, outcome(revision))
+	}
+	downstreams, _, _ := unstructured.NestedSlice(move.Object, "status", "downstreams")
+	for _, d := range downstreams {
+		downstream, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(downstream, "name")
+		upstream, _, _ := unstructured.NestedString(downstream, "upstream")
+		line := fmt.Sprintf("downstream %s of %s", name, upstream)
+		if newUpstream, _, _ := unstructured.NestedString(downstream, "newUpstream"); newUpstream != "" {
+			line += " -> " + newUpstream
+		}
+		fmt.Fprintf(out, "%s: %s\n", line, outcome(downstream))
+	}
+	source, _, _ := unstructured.NestedString(move.Object, "status", "source")
+	sourceMessage, _, _ := unstructured.NestedString(move.Object, "status", "sourceMessage")
+	fmt.Fprintf(out, "source package: %s\n", outcome(map[string]interface{}{"outcome": source, "message": sourceMessage}))
+	return failed
+}
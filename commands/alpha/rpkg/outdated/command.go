@@ -0,0 +1,220 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outdated
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrpkgoutdated"
+
+	statusOutdated     = "OUTDATED"
+	statusUpToDate     = "UP-TO-DATE"
+	statusUpstreamGone = "UPSTREAM-GONE"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "outdated",
+		Short:   rpkgdocs.OutdatedShort,
+		Long:    rpkgdocs.OutdatedShort + "\n" + rpkgdocs.OutdatedLong,
+		Example: rpkgdocs.OutdatedExamples,
+		Args:    cobra.NoArgs,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.repository, "repository", "", "Repository containing the downstream packages. If unspecified, all repositories in the namespace are included.")
+	c.Flags().StringVarP(&r.output, "output", "o", "", "Output format. One of: (json). Defaults to a table.")
+
+	return r
+}
+
+type runner struct {
+	ctx     context.Context
+	cfg     *genericclioptions.ConfigFlags
+	client  client.Client
+	Command *cobra.Command
+
+	// Flags
+	repository string
+	output     string
+}
+
+// drift compares the upstream revision a downstream package revision is based on with
+// the latest published revision of the upstream package.
+type drift struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	Package    string `json:"package"`
+	// Upstream is the name of the upstream package revision.
+	Upstream string `json:"upstream"`
+	// UpstreamPackage is the upstream package, as repository/package.
+	UpstreamPackage string `json:"upstreamPackage,omitempty"`
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	LatestRevision  string `json:"latestRevision,omitempty"`
+	Status          string `json:"status"`
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	if r.output != "" && r.output != "json" {
+		return errors.E(op, fmt.Errorf("unsupported output format %q", r.output))
+	}
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	// Upstreams may be in any repository of the namespace, so all package revisions
+	// are listed even if the downstreams are limited to one repository.
+	var list porchapi.PackageRevisionList
+	if err := r.client.List(r.ctx, &list, client.InNamespace(*r.cfg.Namespace)); err != nil {
+		return errors.E(op, err)
+	}
+
+	drifts := findDrifts(list.Items, r.repository)
+
+	if r.output == "json" {
+		return printJSON(cmd.OutOrStdout(), drifts)
+	}
+	return printTable(cmd.OutOrStdout(), drifts)
+}
+
+// findDrifts reports, for every package revision of the repository (or of all
+// repositories if empty) cloned from a registered upstream, whether the upstream has a
+// newer published revision.
+func findDrifts(revisions []porchapi.PackageRevision, repository string) []drift {
+	byName := map[string]*porchapi.PackageRevision{}
+	latest := map[string]string{}
+	for i := range revisions {
+		pr := &revisions[i]
+		byName[pr.Name] = pr
+		if pr.Spec.Lifecycle != porchapi.PackageRevisionLifecyclePublished || !semver.IsValid(pr.Spec.Revision) {
+			continue
+		}
+		key := packageKey(pr)
+		if current, found := latest[key]; !found || semver.Compare(pr.Spec.Revision, current) > 0 {
+			latest[key] = pr.Spec.Revision
+		}
+	}
+
+	var drifts []drift
+	for i := range revisions {
+		pr := &revisions[i]
+		if repository != "" && pr.Spec.RepositoryName != repository {
+			continue
+		}
+		upstreamName := currentUpstream(pr)
+		if upstreamName == "" {
+			continue
+		}
+		d := drift{
+			Name:       pr.Name,
+			Repository: pr.Spec.RepositoryName,
+			Package:    pr.Spec.PackageName,
+			Upstream:   upstreamName,
+		}
+		upstream, found := byName[upstreamName]
+		if !found {
+			d.Status = statusUpstreamGone
+			drifts = append(drifts, d)
+			continue
+		}
+		d.UpstreamPackage = packageKey(upstream)
+		d.CurrentRevision = upstream.Spec.Revision
+		d.LatestRevision = latest[d.UpstreamPackage]
+		d.Status = statusUpToDate
+		if d.LatestRevision != "" && d.LatestRevision != d.CurrentRevision &&
+			(!semver.IsValid(d.CurrentRevision) || semver.Compare(d.LatestRevision, d.CurrentRevision) > 0) {
+			d.Status = statusOutdated
+		}
+		drifts = append(drifts, d)
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		return drifts[i].Name < drifts[j].Name
+	})
+	return drifts
+}
+
+// currentUpstream returns the name of the upstream package revision the package
+// revision was last cloned from or updated to, or "" if it has no registered upstream.
+func currentUpstream(pr *porchapi.PackageRevision) string {
+	upstream := ""
+	for _, task := range pr.Spec.Tasks {
+		switch {
+		case task.Type == porchapi.TaskTypeClone && task.Clone != nil && task.Clone.Upstream.UpstreamRef != nil:
+			upstream = task.Clone.Upstream.UpstreamRef.Name
+		case task.Type == porchapi.TaskTypeUpdate && task.Update != nil && task.Update.Upstream.UpstreamRef != nil:
+			upstream = task.Update.Upstream.UpstreamRef.Name
+		}
+	}
+	return upstream
+}
+
+func packageKey(pr *porchapi.PackageRevision) string {
+	return pr.Spec.RepositoryName + "/" + pr.Spec.PackageName
+}
+
+func printJSON(out io.Writer, drifts []drift) error {
+	e := json.NewEncoder(out)
+	e.SetIndent("", "  ")
+	if drifts == nil {
+		drifts = []drift{}
+	}
+	return e.Encode(drifts)
+}
+
+func printTable(out io.Writer, drifts []drift) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "PACKAGE REVISION\tREPOSITORY\tPACKAGE\tUPSTREAM\tCURRENT\tLATEST\tSTATUS")
+	for _, d := range drifts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Name, d.Repository, d.Package, d.Upstream, d.CurrentRevision, d.LatestRevision, d.Status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outdated
+
+import (
+	"testing"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func packageRevision(name, repository, pkg, revision string, lifecycle porchapi.PackageRevisionLifecycle, tasks ...porchapi.Task) porchapi.PackageRevision {
+	return porchapi.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: porchapi.PackageRevisionSpec{
+			RepositoryName: repository,
+			PackageName:    pkg,
+			Revision:       revision,
+			Lifecycle:      lifecycle,
+			Tasks:          tasks,
+		},
+	}
+}
+
+func cloneTask(upstream string) porchapi.Task {
+	return porchapi.Task{Type: porchapi.TaskTypeClone, Clone: &porchapi.PackageCloneTaskSpec{
+		Upstream: porchapi.UpstreamPackage{UpstreamRef: &porchapi.PackageRevisionRef{Name: upstream}},
+	}}
+}
+
+func updateTask(upstream string) porchapi.Task {
+	return porchapi.Task{Type: porchapi.TaskTypeUpdate, Update: &porchapi.PackageUpdateTaskSpec{
+		Upstream: porchapi.UpstreamPackage{UpstreamRef: &porchapi.PackageRevisionRef{Name: upstream}},
+	}}
+}
+
+func TestFindDrifts(t *testing.T) {
+	published := porchapi.PackageRevisionLifecyclePublished
+	revisions := []porchapi.PackageRevision{
+		packageRevision("blueprints-v1", "blueprints", "app", "v1", published),
+		packageRevision("blueprints-v2", "blueprints", "app", "v2", published),
+		packageRevision("blueprints-v3", "blueprints", "app", "v3", porchapi.PackageRevisionLifecycleDraft),
+		packageRevision("deployments-old", "deployments", "app-old", "v1", published, cloneTask("blueprints-v1")),
+		packageRevision("deployments-new", "deployments", "app-new", "v1", published, cloneTask("blueprints-v1"), updateTask("blueprints-v2")),
+		packageRevision("deployments-gone", "deployments", "app-gone", "v1", published, cloneTask("blueprints-v0")),
+		packageRevision("staging-old", "staging", "app", "v1", published, cloneTask("blueprints-v1")),
+		packageRevision("staging-init", "staging", "other", "v1", published, porchapi.Task{Type: porchapi.TaskTypeInit}),
+	}
+
+	for _, tc := range []struct {
+		name       string
+		repository string
+		want       []drift
+	}{
+		{
+			name: "all repositories",
+			want: []drift{
+				{Name: "deployments-gone", Repository: "deployments", Package: "app-gone", Upstream: "blueprints-v0", Status: statusUpstreamGone},
+				{Name: "deployments-new", Repository: "deployments", Package: "app-new", Upstream: "blueprints-v2", UpstreamPackage: "blueprints/app", CurrentRevision: "v2", LatestRevision: "v2", Status: statusUpToDate},
+				{Name: "deployments-old", Repository: "deployments", Package: "app-old", Upstream: "blueprints-v1", UpstreamPackage: "blueprints/app", CurrentRevision: "v1", LatestRevision: "v2", Status: statusOutdated},
+				{Name: "staging-old", Repository: "staging", Package: "app", Upstream: "blueprints-v1", UpstreamPackage: "blueprints/app", CurrentRevision: "v1", LatestRevision: "v2", Status: statusOutdated},
+			},
+		},
+		{
+			name:       "single repository",
+			repository: "staging",
+			want: []drift{
+				{Name: "staging-old", Repository: "staging", Package: "app", Upstream: "blueprints-v1", UpstreamPackage: "blueprints/app", CurrentRevision: "v1", LatestRevision: "v2", Status: statusOutdated},
+			},
+		},
+		{
+			name:       "no upstreams",
+			repository: "blueprints",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := findDrifts(revisions, tc.repository)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected drifts (-want, +got): %s", diff)
+			}
+		})
+	}
+}
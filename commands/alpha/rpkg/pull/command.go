@@ -32,6 +32,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -58,6 +59,9 @@ func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner
 		Hidden:     porch.HidePorchCommands,
 	}
 	r.Command = c
+	r.Command.Flags().BoolVar(&r.canonical, "canonical", false, "Write resources in canonical form so that diffs of two pulls only show semantic changes.")
+	r.Command.Flags().StringVar(&r.progress, "progress", "", "Report progress to stderr, as a progress line (text) or newline-delimited JSON events (json).")
+	r.Command.Flags().Lookup("progress").NoOptDefVal = porch.ProgressText
 	return r
 }
 
@@ -66,11 +70,17 @@ func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.
 }
 
 type runner struct {
-	ctx     context.Context
-	cfg     *genericclioptions.ConfigFlags
-	client  client.Client
-	Command *cobra.Command
-	printer printer.Printer
+	ctx        context.Context
+	cfg        *genericclioptions.ConfigFlags
+	client     client.Client
+	restClient rest.Interface
+	Command    *cobra.Command
+	printer    printer.Printer
+
+	canonical bool
+	progress  string
+
+	reportProgress porch.ProgressFunc
 }
 
 func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
@@ -80,6 +90,12 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, err)
 	}
 
+	r.reportProgress, err = porch.NewProgressPrinter(cmd.ErrOrStderr(), r.progress)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	porch.WithProgress(config, "pull", r.reportProgress)
+
 	scheme, err := createScheme()
 	if err != nil {
 		return errors.E(op, err)
@@ -90,7 +106,13 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, err)
 	}
 
+	restClient, err := porch.CreateRESTClientForConfig(config)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
 	r.client = c
+	r.restClient = restClient
 	r.printer = printer.FromContextOrDie(r.ctx)
 	return nil
 }
@@ -105,26 +127,41 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 	packageName := args[0]
 
 	var resources porchapi.PackageRevisionResources
-	if err := r.client.Get(r.ctx, client.ObjectKey{
+	if r.canonical {
+		canonical, err := porch.GetCanonicalResources(r.ctx, r.restClient, *r.cfg.Namespace, packageName)
+		if err != nil {
+			return errors.E(op, err)
+		}
+		resources = *canonical
+	} else if err := r.client.Get(r.ctx, client.ObjectKey{
 		Namespace: *r.cfg.Namespace,
 		Name:      packageName,
 	}, &resources); err != nil {
 		return errors.E(op, err)
 	}
 
+	contents := resources.Spec.Resources
+
 	if len(args) > 1 {
-		if err := writeToDir(resources.Spec.Resources, args[1]); err != nil {
+		if err := writeToDir(contents, args[1], r.reportProgress); err != nil {
 			return errors.E(op, err)
 		}
 	} else {
-		if err := writeToWriter(resources.Spec.Resources, r.printer.OutStream()); err != nil {
+		if err := writeToWriter(contents, r.printer.OutStream()); err != nil {
 			return errors.E(op, err)
 		}
 	}
+
+	done := porch.ProgressEvent{Operation: "pull", Phase: porch.ProgressDone, Files: len(contents)}
+	for _, v := range contents {
+		done.Bytes += int64(len(v))
+	}
+	r.reportProgress.Report(done)
 	return nil
 }
 
-func writeToDir(resources map[string]string, dir string) error {
+// writeToDir writes the resources to dir, reporting every file written to progress.
+func writeToDir(resources map[string]string, dir string, progress porch.ProgressFunc) error {
 	if err := cmdutil.CheckDirectoryNotPresent(dir); err != nil {
 		return err
 	}
@@ -132,6 +169,7 @@ func writeToDir(resources map[string]string, dir string) error {
 		return err
 	}
 
+	event := porch.ProgressEvent{Operation: "pull", Phase: porch.ProgressFiles}
 	for k, v := range resources {
 		f := filepath.Join(dir, k)
 		d := filepath.Dir(f)
@@ -141,6 +179,9 @@ func writeToDir(resources map[string]string, dir string) error {
 		if err := os.WriteFile(f, []byte(v), 0644); err != nil {
 			return err
 		}
+		event.Files++
+		event.Bytes += int64(len(v))
+		progress.Report(event)
 	}
 	return nil
 }
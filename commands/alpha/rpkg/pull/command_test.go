@@ -16,16 +16,24 @@ package pull
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/GoogleContainerTools/kpt/internal/printer"
 	fakeprint "github.com/GoogleContainerTools/kpt/internal/printer/fake"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
 	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -185,3 +193,107 @@ items:
 		})
 	}
 }
+
+func TestCmdCanonical(t *testing.T) {
+	ns := "ns"
+	canonical := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: game-config # the game\n  namespace: default\n"
+
+	var paths []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&porchapi.PackageRevisionResources{
+			TypeMeta:   metav1.TypeMeta{APIVersion: porchapi.SchemeGroupVersion.String(), Kind: "PackageRevisionResources"},
+			ObjectMeta: metav1.ObjectMeta{Name: "repo-original", Namespace: ns},
+			Spec: porchapi.PackageRevisionResourcesSpec{
+				PackageName: "foo",
+				Resources:   map[string]string{"cm.yaml": canonical},
+			},
+		})
+	}))
+	defer httpServer.Close()
+	restClient, err := porch.CreateRESTClientForConfig(&rest.Config{Host: httpServer.URL})
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "foo")
+	output := &bytes.Buffer{}
+	ctx := fakeprint.CtxWithPrinter(output, output)
+	r := &runner{
+		ctx: ctx,
+		cfg: &genericclioptions.ConfigFlags{
+			Namespace: &ns,
+		},
+		restClient:     restClient,
+		printer:        printer.FromContextOrDie(ctx),
+		canonical:      true,
+		reportProgress: func(porch.ProgressEvent) {},
+	}
+	if err := r.runE(&cobra.Command{}, []string{"repo-original", dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"/apis/porch.kpt.dev/v1alpha1/namespaces/ns/packagerevisionresources/repo-original/canonical"}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Errorf("unexpected requests (-want, +got): %s", diff)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "cm.yaml"))
+	if err != nil {
+		t.Fatalf("cannot read pulled file: %v", err)
+	}
+	if string(got) != canonical {
+		t.Errorf("pull didn't write the canonical resources: got %q, want %q", got, canonical)
+	}
+}
+
+func TestCmdProgress(t *testing.T) {
+	ns := "ns"
+	const files = 2000
+
+	scheme, err := createScheme()
+	if err != nil {
+		t.Fatalf("error creating scheme: %v", err)
+	}
+
+	resources := map[string]string{}
+	for i := 0; i < files; i++ {
+		resources[fmt.Sprintf("cm/cm-%04d.yaml", i)] = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-%04d\n", i)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&porchapi.PackageRevisionResources{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo-large", Namespace: ns},
+			Spec:       porchapi.PackageRevisionResourcesSpec{PackageName: "large", Resources: resources},
+		}).
+		Build()
+
+	var events []porch.ProgressEvent
+	output := &bytes.Buffer{}
+	ctx := fakeprint.CtxWithPrinter(output, output)
+	r := &runner{
+		ctx:            ctx,
+		cfg:            &genericclioptions.ConfigFlags{Namespace: &ns},
+		client:         c,
+		printer:        printer.FromContextOrDie(ctx),
+		reportProgress: func(event porch.ProgressEvent) { events = append(events, event) },
+	}
+	if err := r.runE(&cobra.Command{}, []string{"repo-large", filepath.Join(t.TempDir(), "large")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(events), files+1; got != want {
+		t.Fatalf("got %d progress events; want one per file and a final one (%d)", got, want)
+	}
+	for i, event := range events[:files] {
+		if event.Phase != porch.ProgressFiles || event.Files != i+1 {
+			t.Fatalf("event %d doesn't report the files written so far: %+v", i, event)
+		}
+		if i > 0 && event.Bytes <= events[i-1].Bytes {
+			t.Fatalf("event %d doesn't report more bytes than the previous one: %+v", i, event)
+		}
+	}
+	if last := events[files]; last.Phase != porch.ProgressDone || last.Files != files || last.Bytes != events[files-1].Bytes {
+		t.Errorf("unexpected final event: %+v", last)
+	}
+}
@@ -28,12 +28,9 @@ import (
 	"github.com/GoogleContainerTools/kpt/internal/errors"
 	"github.com/GoogleContainerTools/kpt/internal/printer"
 	"github.com/GoogleContainerTools/kpt/internal/util/porch"
-	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -60,6 +57,8 @@ func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner
 		Hidden:     porch.HidePorchCommands,
 	}
 	r.Command = c
+	r.Command.Flags().StringVar(&r.progress, "progress", "", "Report progress to stderr, as a progress line (text) or newline-delimited JSON events (json).")
+	r.Command.Flags().Lookup("progress").NoOptDefVal = porch.ProgressText
 	return r
 }
 
@@ -70,9 +69,12 @@ func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.
 type runner struct {
 	ctx     context.Context
 	cfg     *genericclioptions.ConfigFlags
-	client  client.Client
+	client  rest.Interface
 	Command *cobra.Command
 	printer printer.Printer
+
+	progress       string
+	reportProgress porch.ProgressFunc
 }
 
 func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
@@ -82,12 +84,13 @@ func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, err)
 	}
 
-	scheme, err := createScheme()
+	r.reportProgress, err = porch.NewProgressPrinter(cmd.ErrOrStderr(), r.progress)
 	if err != nil {
 		return errors.E(op, err)
 	}
+	porch.WithProgress(config, "push", r.reportProgress)
 
-	c, err := client.New(config, client.Options{Scheme: scheme})
+	c, err := porch.CreateRESTClientForConfig(config)
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -109,7 +112,7 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 	var err error
 
 	if len(args) > 1 {
-		resources, err = readFromDir(args[1])
+		resources, err = readFromDir(args[1], r.reportProgress)
 	} else {
 		resources, err = readFromReader(cmd.InOrStdin())
 	}
@@ -117,26 +120,33 @@ func (r *runner) runE(cmd *cobra.Command, args []string) error {
 		return errors.E(op, err)
 	}
 
-	if err := r.client.Update(r.ctx, &porchapi.PackageRevisionResources{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PackageRevisionResources",
-			APIVersion: porchapi.SchemeGroupVersion.Identifier(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      packageName,
-			Namespace: *r.cfg.Namespace,
-		},
-		Spec: porchapi.PackageRevisionResourcesSpec{
-			Resources: resources,
-		},
-	}); err != nil {
+	// The resources are pushed in chunks, so that running push again after it was
+	// interrupted uploads only the chunks the server is missing. Servers which don't
+	// support staged pushes are updated in a single request.
+	_, err = porch.PushStagedResources(r.ctx, r.client, *r.cfg.Namespace, packageName, resources, r.reportProgress)
+	if porch.IsStagingNotSupported(err) {
+		err = porch.UpdateResources(r.ctx, r.client, *r.cfg.Namespace, packageName, resources)
+	}
+	if err != nil {
+		// Show which patches failed so they can all be fixed at once.
+		if printErr := porch.PrintPatchResults(cmd.ErrOrStderr(), err); printErr != nil {
+			return errors.E(op, printErr)
+		}
 		return errors.E(op, err)
 	}
+
+	done := porch.ProgressEvent{Operation: "push", Phase: porch.ProgressDone, Files: len(resources)}
+	for _, v := range resources {
+		done.Bytes += int64(len(v))
+	}
+	r.reportProgress.Report(done)
 	return nil
 }
 
-func readFromDir(dir string) (map[string]string, error) {
+// readFromDir reads the resources from dir, reporting every file read to progress.
+func readFromDir(dir string, progress porch.ProgressFunc) (map[string]string, error) {
 	resources := map[string]string{}
+	event := porch.ProgressEvent{Operation: "push", Phase: porch.ProgressFiles}
 	if err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -153,6 +163,9 @@ func readFromDir(dir string) (map[string]string, error) {
 			return err
 		}
 		resources[rel] = string(contents)
+		event.Files++
+		event.Bytes += int64(len(contents))
+		progress.Report(event)
 		return nil
 	}); err != nil {
 		return nil, err
@@ -178,19 +191,6 @@ func readFromReader(in io.Reader) (map[string]string, error) {
 	return rw.resources, nil
 }
 
-func createScheme() (*runtime.Scheme, error) {
-	scheme := runtime.NewScheme()
-
-	for _, api := range (runtime.SchemeBuilder{
-		porchapi.AddToScheme,
-	}) {
-		if err := api(scheme); err != nil {
-			return nil, err
-		}
-	}
-	return scheme, nil
-}
-
 type resourceWriter struct {
 	resources map[string]string
 }
@@ -20,15 +20,23 @@ import (
 	"fmt"
 
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/approve"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/archive"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/clone"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/copy"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/del"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/freeze"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/get"
 	initialization "github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/init"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/logs"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/move"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/outdated"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/propose"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/pull"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/push"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/reject"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/restore"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/timeline"
+	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/unfreeze"
 	"github.com/GoogleContainerTools/kpt/commands/alpha/rpkg/update"
 	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
 	"github.com/GoogleContainerTools/kpt/internal/util/porch"
@@ -78,8 +86,16 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 		approve.NewCommand(ctx, kubeflags),
 		reject.NewCommand(ctx, kubeflags),
 		del.NewCommand(ctx, kubeflags),
+		archive.NewCommand(ctx, kubeflags),
+		restore.NewCommand(ctx, kubeflags),
+		freeze.NewCommand(ctx, kubeflags),
+		unfreeze.NewCommand(ctx, kubeflags),
 		copy.NewCommand(ctx, kubeflags),
 		update.NewCommand(ctx, kubeflags),
+		timeline.NewCommand(ctx, kubeflags),
+		logs.NewCommand(ctx, kubeflags),
+		move.NewCommand(ctx, kubeflags),
+		outdated.NewCommand(ctx, kubeflags),
 	)
 
 	return repo
@@ -0,0 +1,154 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/internal/docs/generated/rpkgdocs"
+	"github.com/GoogleContainerTools/kpt/internal/errors"
+	"github.com/GoogleContainerTools/kpt/internal/util/porch"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	command = "cmdrpkgtimeline"
+)
+
+func NewCommand(ctx context.Context, rcg *genericclioptions.ConfigFlags) *cobra.Command {
+	return newRunner(ctx, rcg).Command
+}
+
+func newRunner(ctx context.Context, rcg *genericclioptions.ConfigFlags) *runner {
+	r := &runner{
+		ctx: ctx,
+		cfg: rcg,
+	}
+	c := &cobra.Command{
+		Use:     "timeline PACKAGE",
+		Short:   rpkgdocs.TimelineShort,
+		Long:    rpkgdocs.TimelineShort + "\n" + rpkgdocs.TimelineLong,
+		Example: rpkgdocs.TimelineExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+		Hidden:  porch.HidePorchCommands,
+	}
+	r.Command = c
+
+	c.Flags().StringVar(&r.repository, "repository", "", "Repository containing the package. If unspecified, all repositories in the namespace are included.")
+	c.Flags().StringVarP(&r.output, "output", "o", "", "Output format. One of: (json). Defaults to a table.")
+
+	return r
+}
+
+type runner struct {
+	ctx        context.Context
+	cfg        *genericclioptions.ConfigFlags
+	client     client.Client
+	restClient rest.Interface
+	Command    *cobra.Command
+
+	// Flags
+	repository string
+	output     string
+}
+
+func (r *runner) preRunE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".preRunE"
+
+	if r.output != "" && r.output != "json" {
+		return errors.E(op, fmt.Errorf("unsupported output format %q", r.output))
+	}
+
+	client, err := porch.CreateClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.client = client
+
+	restClient, err := porch.CreateRESTClient(r.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	r.restClient = restClient
+	return nil
+}
+
+func (r *runner) runE(cmd *cobra.Command, args []string) error {
+	const op errors.Op = command + ".runE"
+
+	packageName := args[0]
+
+	fields := client.MatchingFields{"spec.packageName": packageName}
+	if r.repository != "" {
+		fields["spec.repository"] = r.repository
+	}
+	var list unstructured.UnstructuredList
+	list.SetGroupVersionKind(porch.PackageGVK.GroupVersion().WithKind(porch.PackageGVK.Kind + "List"))
+	if err := r.client.List(r.ctx, &list, client.InNamespace(*r.cfg.Namespace), fields); err != nil {
+		return errors.E(op, err)
+	}
+	if len(list.Items) == 0 {
+		return errors.E(op, fmt.Errorf("package %q not found", packageName))
+	}
+
+	var events []porch.TimelineEvent
+	for _, pkg := range list.Items {
+		pkgEvents, err := porch.GetPackageTimeline(r.ctx, r.restClient, pkg.GetNamespace(), pkg.GetName())
+		if err != nil {
+			return errors.E(op, err)
+		}
+		events = append(events, pkgEvents...)
+	}
+	// The timelines of the package in different repositories are merged.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(&events[j].Time)
+	})
+
+	if r.output == "json" {
+		return printJSON(cmd.OutOrStdout(), events)
+	}
+	return printTable(cmd.OutOrStdout(), events)
+}
+
+func printJSON(out io.Writer, events []porch.TimelineEvent) error {
+	e := json.NewEncoder(out)
+	e.SetIndent("", "  ")
+	return e.Encode(events)
+}
+
+func printTable(out io.Writer, events []porch.TimelineEvent) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "TIME\tTYPE\tREVISION\tACTOR\tDURATION\tMESSAGE")
+	for _, e := range events {
+		var duration string
+		if e.Duration != nil {
+			duration = e.Duration.Duration.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.UTC().Format(time.RFC3339), e.Type, e.Revision, e.Actor, duration, e.Message)
+	}
+	return w.Flush()
+}
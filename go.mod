@@ -119,3 +119,7 @@ require (
 	sigs.k8s.io/json v0.0.0-20211208200746-9f7c6b3444d2 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.1 // indirect
 )
+
+// The CLI uses the Porch API of the same tree, so that commands can use new API types
+// without waiting for a release of the API module.
+replace github.com/GoogleContainerTools/kpt/porch/api => ./porch/api
@@ -6,6 +6,62 @@ var RepoLong = `
 The ` + "`" + `repo` + "`" + ` command group contains subcommands for managing package repositories.
 `
 
+var EditShort = `Change the settings of a registered repository.`
+var EditLong = `
+  kpt alpha repo edit REPOSITORY_NAME [flags]
+
+Args:
+
+  REPOSITORY_NAME:
+    The name of a registered repository.
+
+Flags:
+
+  --branch:
+    Branch in the repository where finalized packages are committed.
+  
+  --description:
+    Description of the repository.
+  
+  --directory:
+    Directory within the repository where to look for packages.
+  
+  --dry-run:
+    Validate the change and report its impact without applying it.
+  
+  --repo:
+    Address of the git repository.
+`
+var EditExamples = `
+  # check the impact of looking for packages of the repository named blueprints in another directory
+  $ kpt alpha repo edit blueprints --directory=catalog --dry-run
+
+  # change the description of the repository named blueprints
+  $ kpt alpha repo edit blueprints --description="Curated blueprints"
+`
+
+var FsckShort = `Check the consistency of the package revisions of a repository.`
+var FsckLong = `
+  kpt alpha repo fsck REPOSITORY_NAME [flags]
+
+Args:
+
+  REPOSITORY_NAME:
+    The name of a registered repository.
+
+Flags:
+
+  --repair:
+    Refresh the cache and fix the metadata of the repository.
+`
+var FsckExamples = `
+  # check the consistency of the repository named deployments
+  $ kpt alpha repo fsck deployments --namespace=default
+
+  # repair the inconsistencies of the repository named deployments
+  $ kpt alpha repo fsck deployments --namespace=default --repair
+`
+
 var GetShort = `List registered repositories.`
 var GetLong = `
   kpt alpha repo get [REPOSITORY_NAME] [flags]
@@ -25,6 +81,32 @@ var GetExamples = `
   $ kpt alpha repo get foo --namespace bar
 `
 
+var PruneShort = `Delete stale draft and proposed branches from a repository.`
+var PruneLong = `
+  kpt alpha repo prune REPOSITORY_NAME [flags]
+
+Args:
+
+  REPOSITORY_NAME:
+    The name of a registered git repository.
+
+Flags:
+
+  --dry-run:
+    Only list the stale refs and the bytes that would be reclaimed,
+    without deleting them.
+  
+  --timeout:
+    How long to wait for Porch to run the prune. Defaults to 2m.
+`
+var PruneExamples = `
+  # list the stale refs of the repository named deployments
+  $ kpt alpha repo prune deployments --namespace=default --dry-run
+
+  # delete the stale refs of the repository named deployments
+  $ kpt alpha repo prune deployments --namespace=default
+`
+
 var RegShort = `Register a package repository.`
 var RegLong = `
   kpt alpha repo reg REPOSITORY [flags]
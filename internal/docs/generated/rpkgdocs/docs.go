@@ -15,10 +15,41 @@ Args:
   PACKAGE_REV_NAME...:
     The name of one or more package revisions. If more than
     one is provided, they must be space-separated.
+
+When more than one package revision is given, they are approved together: all of
+them are checked before any is published, the ones which can be approved are
+published concurrently, and the outcome is reported for each of them. A package
+revision which can't be approved doesn't stop the others.
 `
 var ApproveExamples = `
   # approve package revision blueprint-91817620282c133138177d16c981cf35f0083cad
   $ kpt alpha rpkg approve blueprint-91817620282c133138177d16c981cf35f0083cad --namespace=default
+  
+  # approve two package revisions together
+  $ kpt alpha rpkg approve deployments-a1b2c3 deployments-d4e5f6 --namespace=default
+`
+
+var ArchiveShort = `Archive a package.`
+var ArchiveLong = `
+  kpt alpha rpkg archive PACKAGE [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the package (spec.packageName) to archive.
+
+Flags:
+
+  --repository
+    Repository containing the package. Required if more than one
+    repository in the namespace contains a package with the name.
+`
+var ArchiveExamples = `
+  # archive package istions in the default namespace
+  $ kpt alpha rpkg archive istions --namespace=default
+
+  # archive package istions of repository blueprints
+  $ kpt alpha rpkg archive istions --repository=blueprints
 `
 
 var CloneShort = `Create a clone of an existing package revision.`
@@ -45,11 +76,28 @@ Args:
 
 Flags:
 
+  --context
+    Value of the package context of the new package revision, as key=value.
+    Can be repeated. The values are set in the package context by a function
+    evaluated right after the clone, in the same request; the package must have
+    a package context, which Porch only generates in deployment repositories.
+    The package-path key is set by Porch and can't be set.
+  
   --directory
     Directory within the repository where the upstream
     package revision is located. This only applies if the source package is in git
     or oci.
   
+  --dry-run
+    Print the package revision that would be created, as YAML, without
+    creating it.
+  
+  --label
+    Label of the new package revision, as key=value. Can be repeated.
+  
+  --propose
+    Create the new package revision as proposed rather than draft.
+  
   --ref
     Ref in the repository where the upstream package revision
     is located (branch, tag, SHA). This only applies when the source package
@@ -75,6 +123,11 @@ var CloneExamples = `
   # clone the git repository at https://github.com/repo/blueprint.git at reference base/v0 and in directory base. The new
   # package revision will be created in repository blueprint and namespace default.
   $ kpt alpha rpkg clone https://github.com/repo/blueprint.git bar --repository=blueprint --ref=base/v0 --namespace=default --directory=base
+
+  # clone the blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a package into the deployments repository,
+  # setting the region in its package context and a label, and propose it right away.
+  $ kpt alpha rpkg clone blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a foo --repository deployments \
+    --context region=us-east1 --label team=blue --propose
 `
 
 var CopyShort = `Create a new package revision from an existing one.`
@@ -114,6 +167,33 @@ var DelExamples = `
   $ kpt alpha rpkg del blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a --namespace=default
 `
 
+var FreezeShort = `Freeze a package.`
+var FreezeLong = `
+  kpt alpha rpkg freeze PACKAGE --reason=REASON [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the package (spec.packageName) to freeze.
+
+Flags:
+
+  --reason
+    Reason for freezing the package, reported to users whose changes
+    are rejected. Required.
+  
+  --repository
+    Repository containing the package. Required if more than one
+    repository in the namespace contains a package with the name.
+`
+var FreezeExamples = `
+  # freeze package istions in the default namespace
+  $ kpt alpha rpkg freeze istions --namespace=default --reason="INC-1234: rollout paused"
+
+  # freeze package istions of repository blueprints
+  $ kpt alpha rpkg freeze istions --repository=blueprints --reason="INC-1234: rollout paused"
+`
+
 var GetShort = `List package revisions in registered repositories.`
 var GetLong = `
   kpt alpha rpkg get [PACKAGE_REV_NAME] [flags]
@@ -134,6 +214,10 @@ Flags:
   --revision
     Revision of the package to get. Any package whose revision
     matches this value will be included in the results.
+  
+  --archived
+    Get the package revisions of archived packages instead of
+    active ones.
 `
 var GetExamples = `
   # get a specific package revision in the default namespace
@@ -144,6 +228,12 @@ var GetExamples = `
 
   # get all package revisions with revision v0
   $ kpt alpha rpkg get --revision=v0
+
+  # get all package revisions of archived packages
+  $ kpt alpha rpkg get --archived
+
+  # get all package revisions, showing how long drafts and proposals have been waiting
+  $ kpt alpha rpkg get -o wide
 `
 
 var InitShort = `Initializes a new package in a repository.`
@@ -177,6 +267,87 @@ var InitExamples = `
   $ kpt alpha rpkg init foo --namespace=default --repository=blueprint
 `
 
+var LogsShort = `Print the logs of the functions run for a package revision.`
+var LogsLong = `
+  kpt alpha rpkg logs PACKAGE_REVISION [flags]
+
+Args:
+
+  PACKAGE_REVISION:
+    The name of the package revision to print the function logs of.
+
+Flags:
+
+  --task
+    Index of the task to print the function logs of, among the tasks applied by
+    the last create or update of the package revision. The render following the
+    tasks has the next index. If unspecified, the logs of all tasks are printed.
+`
+var LogsExamples = `
+  # print the function logs of package revision blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a
+  $ kpt alpha rpkg logs blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a --namespace=default
+
+  # print the logs of the functions run by task 3
+  $ kpt alpha rpkg logs blueprint-e982b2196b35a4f5e81e92f49a430fe463aa9f1a --task 3
+`
+
+var MoveShort = `Move a package to another repository.`
+var MoveLong = `
+  kpt alpha rpkg move PACKAGE [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the package to move, as the package name of its package
+    revisions.
+
+Flags:
+
+  --repository
+    The repository the package is moved from.
+  
+  --destination
+    The repository the package is moved to, in the same namespace.
+  
+  --include-history
+    Move all published revisions of the package instead of the latest.
+  
+  --update-downstreams
+    Update the drafts based on the moved revisions to the moved revisions.
+    Proposed and published downstreams keep their upstream.
+  
+  --dry-run
+    Print the steps of the move without running them.
+`
+var MoveExamples = `
+  # print the steps of moving package app from repository blueprints to repository catalog
+  $ kpt alpha rpkg move app --repository=blueprints --destination=catalog --update-downstreams --dry-run
+
+  # move package app with all its published revisions, and update its drafted downstreams
+  $ kpt alpha rpkg move app --repository=blueprints --destination=catalog --include-history --update-downstreams
+`
+
+var OutdatedShort = `Report downstream packages that are behind their upstream.`
+var OutdatedLong = `
+  kpt alpha rpkg outdated [flags]
+
+Flags:
+
+  --repository
+    Repository containing the downstream packages. If unspecified, all
+    repositories in the namespace are included.
+  
+  --output, -o
+    Output format. Supported values: json. Defaults to a table.
+`
+var OutdatedExamples = `
+  # report the packages of all repositories in the default namespace
+  $ kpt alpha rpkg outdated --namespace=default
+
+  # report the packages of repository deployments as JSON
+  $ kpt alpha rpkg outdated --repository=deployments -o json
+`
+
 var ProposeShort = `Propose that a package revision should be published.`
 var ProposeLong = `
   kpt alpha rpkg propose [PACKAGE_REV_NAME...] [flags]
@@ -204,6 +375,20 @@ Args:
   DIR:
     A local directory where the package manifests will be written.
     If not provided, the manifests are written to stdout.
+
+Flags:
+
+  --canonical
+    Write the resources in canonical form: the Porch server re-serializes
+    YAML files with consistent indentation and field ordering, so that
+    diffs of two pulls only show semantic changes. Comments and the order of documents
+    and list items are preserved.
+  
+  --progress[=FORMAT]
+    Report progress to stderr while the resources are transferred and
+    the files are written. FORMAT is text (default), a single progress
+    line updated in place, or json, newline-delimited JSON events with
+    the operation, phase (transfer, files or done), files and bytes.
 `
 var PullExamples = `
   # pull the content of package revision blueprint-d5b944d27035efba53836562726fb96e51758d97
@@ -222,6 +407,15 @@ Args:
   DIR:
     A local directory with the new manifest. If not provided,
     the manifests will be read from stdin.
+
+Flags:
+
+  --progress[=FORMAT]
+    Report progress to stderr while the resources are transferred and
+    the files are read. FORMAT is text (default), a single progress
+    line updated in place, or json, newline-delimited JSON events with
+    the operation, phase (transfer, files, chunks or done), files and
+    bytes, and the staged and total chunks.
 `
 var PushExamples = `
   # update the package revision blueprint-f977350dff904fa677100b087a5bd989106d0456 with the resources
@@ -243,3 +437,69 @@ var RejectExamples = `
   # reject the proposal for package revision blueprint-8f9a0c7bf29eb2cbac9476319cd1ad2e897be4f9
   $ kpt alpha rpkg reject blueprint-8f9a0c7bf29eb2cbac9476319cd1ad2e897be4f9 --namespace=default
 `
+
+var RestoreShort = `Restore an archived package.`
+var RestoreLong = `
+  kpt alpha rpkg restore PACKAGE [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the archived package (spec.packageName) to restore.
+
+Flags:
+
+  --repository
+    Repository containing the package. Required if more than one
+    repository in the namespace contains an archived package with the name.
+`
+var RestoreExamples = `
+  # restore archived package istions in the default namespace
+  $ kpt alpha rpkg restore istions --namespace=default
+`
+
+var TimelineShort = `Show the history of a package.`
+var TimelineLong = `
+  kpt alpha rpkg timeline PACKAGE [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the package (spec.packageName) to show the timeline for.
+
+Flags:
+
+  --repository
+    Repository containing the package. If unspecified, all repositories in the
+    namespace are included.
+  
+  --output, -o
+    Output format. Supported values: json. Defaults to a table.
+`
+var TimelineExamples = `
+  # show the timeline of package istions in the default namespace
+  $ kpt alpha rpkg timeline istions --namespace=default
+
+  # show the timeline of package istions as JSON
+  $ kpt alpha rpkg timeline istions --repository=blueprints -o json
+`
+
+var UnfreezeShort = `Unfreeze a package.`
+var UnfreezeLong = `
+  kpt alpha rpkg unfreeze PACKAGE [flags]
+
+Args:
+
+  PACKAGE:
+    The name of the package (spec.packageName) to unfreeze.
+
+Flags:
+
+  --repository
+    Repository containing the package. Required if more than one
+    repository in the namespace contains a package with the name.
+`
+var UnfreezeExamples = `
+  # unfreeze package istions in the default namespace
+  $ kpt alpha rpkg unfreeze istions --namespace=default
+`
@@ -0,0 +1,45 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"encoding/json"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"k8s.io/client-go/rest"
+)
+
+// GetCanonicalResources returns the resources of a package revision in canonical form,
+// from the canonical subresource of package revision resources. The server re-serializes
+// YAML files and Kptfiles consistently, so byte-level diffs between two reads only show
+// semantic changes.
+func GetCanonicalResources(ctx context.Context, client rest.Interface, namespace, name string) (*porchapi.PackageRevisionResources, error) {
+	raw, err := client.Get().
+		Namespace(namespace).
+		Resource("packagerevisionresources").
+		Name(name).
+		SubResource("canonical").
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	var resources porchapi.PackageRevisionResources
+	if err := json.Unmarshal(raw, &resources); err != nil {
+		return nil, err
+	}
+	return &resources, nil
+}
@@ -81,7 +81,13 @@ func CreateRESTClient(flags *genericclioptions.ConfigFlags) (rest.Interface, err
 	if err != nil {
 		return nil, err
 	}
+	return CreateRESTClientForConfig(config)
+}
 
+// CreateRESTClientForConfig returns a REST client for the Porch API using config, for
+// commands which customize the config, for example to report progress.
+func CreateRESTClientForConfig(config *rest.Config) (rest.Interface, error) {
+	config = rest.CopyConfig(config)
 	scheme, err := createScheme()
 	if err != nil {
 		return nil, err
@@ -126,6 +132,7 @@ func createRESTMapper() meta.RESTMapper {
 		plural, singular string
 	}{
 		{kind: configapi.GroupVersion.WithKind("Repository"), plural: "repositories", singular: "repository"},
+		{kind: porchapi.SchemeGroupVersion.WithKind("Package"), plural: "packages", singular: "package"},
 		{kind: porchapi.SchemeGroupVersion.WithKind("PackageRevision"), plural: "packagerevisions", singular: "packagerevision"},
 		{kind: porchapi.SchemeGroupVersion.WithKind("PackageRevisionResources"), plural: "packagerevisionresources", singular: "packagerevisionresources"},
 		{kind: porchapi.SchemeGroupVersion.WithKind("Function"), plural: "functions", singular: "function"},
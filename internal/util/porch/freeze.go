@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+// UpdatePackageFreeze freezes the package with the reason, or unfreezes it if the reason
+// is empty, and returns the updated package. Packages are frozen through the freeze
+// subresource, which can be authorized separately from updates of packages.
+func UpdatePackageFreeze(ctx context.Context, client rest.Interface, pkg *unstructured.Unstructured, reason string) (*unstructured.Unstructured, error) {
+	pkg = pkg.DeepCopy()
+	if reason == "" {
+		unstructured.RemoveNestedField(pkg.Object, "spec", "freeze")
+	} else if err := unstructured.SetNestedStringMap(pkg.Object, map[string]string{"reason": reason}, "spec", "freeze"); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Put().
+		Namespace(pkg.GetNamespace()).
+		Resource("packages").
+		Name(pkg.GetName()).
+		SubResource("freeze").
+		Body(body).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	updated := &unstructured.Unstructured{}
+	if err := json.Unmarshal(raw, &updated.Object); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/client-go/rest"
+)
+
+// TaskLog is the log of the functions run by a task of a package revision.
+type TaskLog struct {
+	Task      int           `json:"task"`
+	Functions []FunctionLog `json:"functions,omitempty"`
+}
+
+// FunctionLog is the log a function wrote while running on the Porch server.
+type FunctionLog struct {
+	Image     string `json:"image"`
+	Log       string `json:"log,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// GetPackageRevisionLogs returns the logs the functions of the package revision wrote
+// during its last create or update, from the logs subresource of package revisions.
+func GetPackageRevisionLogs(ctx context.Context, client rest.Interface, namespace, name string) ([]TaskLog, error) {
+	raw, err := client.Get().
+		Namespace(namespace).
+		Resource("packagerevisions").
+		Name(name).
+		SubResource("logs").
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	var logs struct {
+		Tasks []TaskLog `json:"tasks"`
+	}
+	if err := json.Unmarshal(raw, &logs); err != nil {
+		return nil, err
+	}
+	return logs.Tasks, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PackageGVK is the kind of the Package resources served by Porch.
+var PackageGVK = schema.GroupVersionKind{Group: "porch.kpt.dev", Version: "v1alpha1", Kind: "Package"}
+
+// FindPackage returns the Package with the given package name in the namespace. If
+// repository is empty, the package name must be unique across the repositories of the
+// namespace. Porch only lists archived packages when asked to, so archived selects whether
+// to look for an archived or an active package.
+func FindPackage(ctx context.Context, c client.Client, namespace, repository, packageName string, archived bool) (*unstructured.Unstructured, error) {
+	fields := client.MatchingFields{
+		"spec.packageName": packageName,
+		"spec.archived":    strconv.FormatBool(archived),
+	}
+	if repository != "" {
+		fields["spec.repository"] = repository
+	}
+
+	var list unstructured.UnstructuredList
+	list.SetGroupVersionKind(PackageGVK.GroupVersion().WithKind(PackageGVK.Kind + "List"))
+	if err := c.List(ctx, &list, client.InNamespace(namespace), fields); err != nil {
+		return nil, err
+	}
+
+	state := "active"
+	if archived {
+		state = "archived"
+	}
+	switch len(list.Items) {
+	case 0:
+		return nil, fmt.Errorf("no %s package %q found", state, packageName)
+	case 1:
+		return &list.Items[0], nil
+	default:
+		return nil, fmt.Errorf("%s package %q found in %d repositories; use --repository to select one", state, packageName, len(list.Items))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// patchCausePrefix is the prefix of the cause types Porch reports for the patches of a
+// patch task which cannot be applied: PatchApplied, PatchFailed and PatchSkipped.
+const patchCausePrefix = "Patch"
+
+// PrintPatchResults prints the outcome of every patch as a table if err reports a patch
+// task which cannot be applied. Other errors are ignored.
+func PrintPatchResults(out io.Writer, err error) error {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || !apierrors.IsInvalid(err) {
+		return nil
+	}
+	details := status.Status().Details
+	if details == nil || len(details.Causes) == 0 {
+		return nil
+	}
+	for _, cause := range details.Causes {
+		if !strings.HasPrefix(string(cause.Type), patchCausePrefix) {
+			return nil
+		}
+	}
+
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "PATCH\tFILE\tRESULT\tREASON")
+	for i, cause := range details.Causes {
+		result := strings.TrimPrefix(string(cause.Type), patchCausePrefix)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i, cause.Field, result, cause.Message)
+	}
+	return w.Flush()
+}
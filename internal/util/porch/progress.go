@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// ProgressText renders progress as a single line which is updated in place.
+	ProgressText = "text"
+	// ProgressJSON renders progress as newline-delimited JSON events.
+	ProgressJSON = "json"
+
+	// progressChunkSize is the number of bytes transferred between two progress events.
+	progressChunkSize = 64 * 1024
+)
+
+// ProgressPhase is the stage of an operation a ProgressEvent reports on.
+type ProgressPhase string
+
+const (
+	// ProgressTransfer reports the bytes sent to or received from Porch.
+	ProgressTransfer ProgressPhase = "transfer"
+	// ProgressFiles reports the local files read or written.
+	ProgressFiles ProgressPhase = "files"
+	// ProgressChunks reports the chunks of a staged push which are staged on the server.
+	ProgressChunks ProgressPhase = "chunks"
+	// ProgressDone reports the completion of the operation.
+	ProgressDone ProgressPhase = "done"
+)
+
+// ProgressEvent reports the progress of a pull or push of package resources.
+type ProgressEvent struct {
+	Operation string        `json:"operation"`
+	Phase     ProgressPhase `json:"phase"`
+	// Files is the number of files processed so far.
+	Files int `json:"files"`
+	// Bytes is the number of bytes transferred or processed so far.
+	Bytes int64 `json:"bytes"`
+	// TotalBytes is the number of bytes to transfer, if known.
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+	// Chunks is the number of chunks of a staged push which are staged so far.
+	Chunks int `json:"chunks,omitempty"`
+	// TotalChunks is the number of chunks of a staged push.
+	TotalChunks int `json:"totalChunks,omitempty"`
+}
+
+// ProgressFunc is called with every progress event. A nil ProgressFunc ignores them.
+type ProgressFunc func(ProgressEvent)
+
+// Report calls f with the event, if f is set.
+func (f ProgressFunc) Report(event ProgressEvent) {
+	if f != nil {
+		f(event)
+	}
+}
+
+// NewProgressPrinter returns a ProgressFunc printing the events to out in the given
+// format, ProgressText or ProgressJSON. An empty format disables progress reporting.
+func NewProgressPrinter(out io.Writer, format string) (ProgressFunc, error) {
+	var mu sync.Mutex
+	switch format {
+	case "":
+		return nil, nil
+	case ProgressText:
+		return func(event ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch event.Phase {
+			case ProgressTransfer:
+				if event.TotalBytes > 0 {
+					fmt.Fprintf(out, "\r%s: transferred %d of %d bytes", event.Operation, event.Bytes, event.TotalBytes)
+				} else {
+					fmt.Fprintf(out, "\r%s: transferred %d bytes", event.Operation, event.Bytes)
+				}
+			case ProgressFiles:
+				fmt.Fprintf(out, "\r%s: processed %d files (%d bytes)", event.Operation, event.Files, event.Bytes)
+			case ProgressChunks:
+				fmt.Fprintf(out, "\r%s: staged %d of %d chunks", event.Operation, event.Chunks, event.TotalChunks)
+			case ProgressDone:
+				fmt.Fprintf(out, "\r%s: done, %d files (%d bytes)\n", event.Operation, event.Files, event.Bytes)
+			}
+		}, nil
+	case ProgressJSON:
+		encoder := json.NewEncoder(out)
+		return func(event ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			_ = encoder.Encode(event)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported progress format %q; must be %q or %q", format, ProgressText, ProgressJSON)
+	}
+}
+
+// WithProgress wraps the transport of config to report the bytes of request and
+// response bodies as they are sent and received, every 64KiB.
+func WithProgress(config *rest.Config, operation string, progress ProgressFunc) {
+	if progress == nil {
+		return
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &progressRoundTripper{delegate: rt, operation: operation, progress: progress}
+	})
+}
+
+type progressRoundTripper struct {
+	delegate  http.RoundTripper
+	operation string
+	progress  ProgressFunc
+}
+
+func (t *progressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.ContentLength != 0 {
+		req = req.Clone(req.Context())
+		req.Body = newProgressReader(req.Body, req.ContentLength, t.operation, t.progress)
+	}
+	resp, err := t.delegate.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body != nil {
+		resp.Body = newProgressReader(resp.Body, resp.ContentLength, t.operation, t.progress)
+	}
+	return resp, nil
+}
+
+// progressReader reports the bytes read through it, every progressChunkSize bytes and
+// at the end of the stream.
+type progressReader struct {
+	io.ReadCloser
+	operation string
+	progress  ProgressFunc
+
+	total, read, reported int64
+}
+
+func newProgressReader(r io.ReadCloser, total int64, operation string, progress ProgressFunc) *progressReader {
+	if total < 0 {
+		total = 0
+	}
+	return &progressReader{ReadCloser: r, total: total, operation: operation, progress: progress}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read-r.reported >= progressChunkSize || (err == io.EOF && r.read > r.reported) {
+		r.reported = r.read
+		r.progress.Report(ProgressEvent{
+			Operation:  r.operation,
+			Phase:      ProgressTransfer,
+			Bytes:      r.read,
+			TotalBytes: r.total,
+		})
+	}
+	return n, err
+}
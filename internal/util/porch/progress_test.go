@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestProgressTransfer(t *testing.T) {
+	const size = 10*progressChunkSize + 123
+	body := strings.Repeat("x", size)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			t.Errorf("reading request body failed: %v", err)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	var events []ProgressEvent
+	config := &rest.Config{Host: server.URL}
+	WithProgress(config, "pull", func(event ProgressEvent) { events = append(events, event) })
+	client := &http.Client{Transport: config.WrapTransport(http.DefaultTransport)}
+
+	// Upload.
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	uploaded := len(events)
+	if uploaded < 2 || events[uploaded-1].Bytes != size || events[uploaded-1].TotalBytes != size {
+		t.Errorf("unexpected upload events: %+v", events)
+	}
+
+	// Download; events are reported while the body is read, not only at the end.
+	buf := make([]byte, progressChunkSize)
+	if _, err := io.ReadFull(resp.Body, buf); err != nil {
+		t.Fatalf("reading response failed: %v", err)
+	}
+	if got := len(events) - uploaded; got != 1 {
+		t.Errorf("got %d events after reading the first chunk of the response; want 1", got)
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatalf("reading response failed: %v", err)
+	}
+	resp.Body.Close()
+	downloaded := events[uploaded:]
+	if len(downloaded) < 2 || len(downloaded) > 11 {
+		t.Fatalf("got %d download events; want one per 64KiB chunk", len(downloaded))
+	}
+	for i, event := range downloaded {
+		if event.Phase != ProgressTransfer || event.Operation != "pull" {
+			t.Errorf("unexpected event %d: %+v", i, event)
+		}
+		if i > 0 && event.Bytes <= downloaded[i-1].Bytes {
+			t.Errorf("event %d doesn't report more bytes than the previous one: %+v", i, event)
+		}
+	}
+	if last := downloaded[len(downloaded)-1]; last.Bytes != size {
+		t.Errorf("last event reports %d bytes; want %d", last.Bytes, size)
+	}
+}
+
+func TestProgressPrinter(t *testing.T) {
+	events := []ProgressEvent{
+		{Operation: "push", Phase: ProgressFiles, Files: 1, Bytes: 10},
+		{Operation: "push", Phase: ProgressTransfer, Bytes: 20, TotalBytes: 40},
+		{Operation: "push", Phase: ProgressDone, Files: 1, Bytes: 10},
+	}
+
+	var out bytes.Buffer
+	progress, err := NewProgressPrinter(&out, ProgressJSON)
+	if err != nil {
+		t.Fatalf("NewProgressPrinter failed: %v", err)
+	}
+	for _, event := range events {
+		progress.Report(event)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(events) {
+		t.Fatalf("got %d lines; want one per event:\n%s", len(lines), out.String())
+	}
+	for i, line := range lines {
+		var event ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event != events[i] {
+			t.Errorf("line %d doesn't encode event %+v: %s", i, events[i], line)
+		}
+	}
+
+	out.Reset()
+	if progress, err = NewProgressPrinter(&out, ProgressText); err != nil {
+		t.Fatalf("NewProgressPrinter failed: %v", err)
+	}
+	for _, event := range events {
+		progress.Report(event)
+	}
+	if got, want := out.String(), "\rpush: processed 1 files (10 bytes)\rpush: transferred 20 of 40 bytes\rpush: done, 1 files (10 bytes)\n"; got != want {
+		t.Errorf("unexpected text progress: got %q, want %q", got, want)
+	}
+
+	if progress, err := NewProgressPrinter(&out, ""); err != nil || progress != nil {
+		t.Errorf("empty format doesn't disable progress reporting")
+	}
+	if _, err := NewProgressPrinter(&out, "xml"); err == nil {
+		t.Errorf("unsupported format was accepted")
+	}
+}
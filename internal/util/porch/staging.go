@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"sort"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// stagingChunkSize is the size of the chunks the resources of a staged upload are
+	// split into.
+	stagingChunkSize = 256 * 1024
+	// stagingBatchSize bounds the size of the chunks staged by a single request.
+	stagingBatchSize = 1024 * 1024
+)
+
+// StagedPush is the outcome of a staged push.
+type StagedPush struct {
+	// Chunks is the number of distinct chunks of the resources.
+	Chunks int
+	// Uploaded is the number of chunks uploaded by the push.
+	Uploaded int
+}
+
+// PushStagedResources replaces the resources of the draft package revision through a
+// resumable upload: the resources are split into content-addressed chunks, the chunks
+// the server is missing are staged in batches, and the upload is finalized with the
+// manifest of the files. Chunks staged by an earlier, interrupted push aren't uploaded
+// again. The number of staged chunks is reported to progress after every batch.
+func PushStagedResources(ctx context.Context, client rest.Interface, namespace, name string, resources map[string]string, progress ProgressFunc) (StagedPush, error) {
+	files, chunks := porchapi.SplitResources(resources, stagingChunkSize)
+	digests := make([]string, 0, len(chunks))
+	for digest := range chunks {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	push := StagedPush{Chunks: len(digests)}
+
+	missing, err := stageChunks(ctx, client, namespace, name, nil, digests)
+	if err != nil {
+		return push, err
+	}
+	event := ProgressEvent{Operation: "push", Phase: ProgressChunks, Chunks: len(digests) - len(missing), TotalChunks: len(digests)}
+	progress.Report(event)
+
+	var batch []porchapi.StagedChunk
+	batchBytes := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := stageChunks(ctx, client, namespace, name, batch, nil); err != nil {
+			return err
+		}
+		push.Uploaded += len(batch)
+		event.Chunks += len(batch)
+		progress.Report(event)
+		batch, batchBytes = nil, 0
+		return nil
+	}
+	for _, digest := range missing {
+		data, found := chunks[digest]
+		if !found {
+			// Digests which aren't chunks of the resources are ignored.
+			continue
+		}
+		if batchBytes > 0 && batchBytes+len(data) > stagingBatchSize {
+			if err := flush(); err != nil {
+				return push, err
+			}
+		}
+		batch = append(batch, porchapi.StagedChunk{Digest: digest, Data: data})
+		batchBytes += len(data)
+	}
+	if err := flush(); err != nil {
+		return push, err
+	}
+
+	finalize := &porchapi.PackageRevisionResourcesFinalize{
+		TypeMeta:   metav1.TypeMeta{APIVersion: porchapi.SchemeGroupVersion.Identifier(), Kind: "PackageRevisionResourcesFinalize"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       porchapi.PackageRevisionResourcesFinalizeSpec{Files: files},
+	}
+	return push, client.Post().
+		Namespace(namespace).
+		Resource("packagerevisionresources").
+		Name(name).
+		SubResource("finalize").
+		Body(finalize).
+		Do(ctx).
+		Error()
+}
+
+// IsStagingNotSupported returns true if err is returned by PushStagedResources because
+// the server doesn't serve the staging and finalize subresources, either because it
+// predates them or because staging is disabled on the server.
+func IsStagingNotSupported(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err)
+}
+
+// UpdateResources replaces the resources of the draft package revision in a single
+// request, for servers which don't support staged pushes.
+func UpdateResources(ctx context.Context, client rest.Interface, namespace, name string, resources map[string]string) error {
+	update := &porchapi.PackageRevisionResources{
+		TypeMeta:   metav1.TypeMeta{APIVersion: porchapi.SchemeGroupVersion.Identifier(), Kind: "PackageRevisionResources"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       porchapi.PackageRevisionResourcesSpec{Resources: resources},
+	}
+	return client.Put().
+		Namespace(namespace).
+		Resource("packagerevisionresources").
+		Name(name).
+		Body(update).
+		Do(ctx).
+		Error()
+}
+
+// stageChunks stages the chunks through the staging subresource, and returns the
+// digests which the server is missing.
+func stageChunks(ctx context.Context, client rest.Interface, namespace, name string, chunks []porchapi.StagedChunk, digests []string) ([]string, error) {
+	staging := &porchapi.PackageRevisionResourcesStaging{
+		TypeMeta:   metav1.TypeMeta{APIVersion: porchapi.SchemeGroupVersion.Identifier(), Kind: "PackageRevisionResourcesStaging"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       porchapi.PackageRevisionResourcesStagingSpec{Chunks: chunks, Digests: digests},
+	}
+	var result porchapi.PackageRevisionResourcesStaging
+	if err := client.Post().
+		Namespace(namespace).
+		Resource("packagerevisionresources").
+		Name(name).
+		SubResource("staging").
+		Body(staging).
+		Do(ctx).
+		Into(&result); err != nil {
+		return nil, err
+	}
+	return result.Status.Missing, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/rest"
+)
+
+// stagingServer serves the staging and finalize subresources of one package revision,
+// failing every staging request with chunks once failAfter chunks were staged.
+type stagingServer struct {
+	t         *testing.T
+	failAfter int
+
+	staged    map[string][]byte
+	uploads   int
+	resources map[string]string
+}
+
+func (s *stagingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/apis/porch.kpt.dev/v1alpha1/namespaces/default/packagerevisionresources/repo-1234/"
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch strings.TrimPrefix(r.URL.Path, prefix) {
+	case "staging":
+		var req porchapi.PackageRevisionResourcesStaging
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("cannot decode staging request: %v", err)
+		}
+		for _, chunk := range req.Spec.Chunks {
+			if s.failAfter >= 0 && len(s.staged) >= s.failAfter {
+				http.Error(w, "connection reset", http.StatusServiceUnavailable)
+				return
+			}
+			s.staged[chunk.Digest] = chunk.Data
+			s.uploads++
+		}
+		var resp porchapi.PackageRevisionResourcesStaging
+		for _, digest := range req.Spec.Digests {
+			if _, found := s.staged[digest]; !found {
+				resp.Status.Missing = append(resp.Status.Missing, digest)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case "finalize":
+		var req porchapi.PackageRevisionResourcesFinalize
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("cannot decode finalize request: %v", err)
+		}
+		s.resources = map[string]string{}
+		for path, digests := range req.Spec.Files {
+			var contents strings.Builder
+			for _, digest := range digests {
+				contents.Write(s.staged[digest])
+			}
+			s.resources[path] = contents.String()
+		}
+		_, _ = w.Write([]byte("{}"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPushStagedResourcesResumes(t *testing.T) {
+	resources := map[string]string{
+		"Kptfile": "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"empty":   "",
+	}
+	for i := 0; i < 4; i++ {
+		// Every file spans several chunks and batches.
+		var contents strings.Builder
+		for line := 0; contents.Len() < 3*stagingBatchSize/2; line++ {
+			fmt.Fprintf(&contents, "# file %d, line %d\n", i, line)
+		}
+		resources[fmt.Sprintf("data-%d.yaml", i)] = contents.String()
+	}
+	_, chunks := porchapi.SplitResources(resources, stagingChunkSize)
+	if len(chunks) < 20 {
+		t.Fatalf("resources were split into %d chunks; want at least 20", len(chunks))
+	}
+
+	server := &stagingServer{t: t, failAfter: len(chunks) / 2, staged: map[string][]byte{}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client, err := CreateRESTClientForConfig(&rest.Config{Host: httpServer.URL})
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	ctx := context.Background()
+
+	// The first push is interrupted half way.
+	if _, err := PushStagedResources(ctx, client, "default", "repo-1234", resources, nil); err == nil {
+		t.Fatalf("interrupted push succeeded")
+	}
+	if server.resources != nil {
+		t.Fatalf("interrupted push was finalized")
+	}
+	interrupted := server.uploads
+
+	// The second push uploads only the missing chunks, and reports them.
+	server.failAfter = -1
+	var events []ProgressEvent
+	push, err := PushStagedResources(ctx, client, "default", "repo-1234", resources, func(event ProgressEvent) { events = append(events, event) })
+	if err != nil {
+		t.Fatalf("PushStagedResources failed: %v", err)
+	}
+	if want := len(chunks) - interrupted; push.Uploaded != want || server.uploads != len(chunks) {
+		t.Errorf("resumed push uploaded %d chunks (%d in total); want %d (%d)", push.Uploaded, server.uploads, want, len(chunks))
+	}
+	if first := events[0]; first.Phase != ProgressChunks || first.Chunks != interrupted || first.TotalChunks != len(chunks) {
+		t.Errorf("unexpected first progress event %+v; want %d of %d chunks staged", first, interrupted, len(chunks))
+	}
+	if diff := cmp.Diff(resources, server.resources); diff != "" {
+		t.Errorf("unexpected finalized resources (-want, +got): %s", diff)
+	}
+}
+
+func TestPushStagedResourcesNotSupported(t *testing.T) {
+	var updated map[string]string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server predates the staging and finalize subresources.
+		if r.Method != http.MethodPut || r.URL.Path != "/apis/porch.kpt.dev/v1alpha1/namespaces/default/packagerevisionresources/repo-1234" {
+			http.NotFound(w, r)
+			return
+		}
+		var req porchapi.PackageRevisionResources
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("cannot decode update request: %v", err)
+		}
+		updated = req.Spec.Resources
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(req)
+	}))
+	defer httpServer.Close()
+	client, err := CreateRESTClientForConfig(&rest.Config{Host: httpServer.URL})
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	ctx := context.Background()
+	resources := map[string]string{"Kptfile": "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n"}
+
+	_, err = PushStagedResources(ctx, client, "default", "repo-1234", resources, nil)
+	if !IsStagingNotSupported(err) {
+		t.Fatalf("PushStagedResources returned %v; want staging not supported", err)
+	}
+	if err := UpdateResources(ctx, client, "default", "repo-1234", resources); err != nil {
+		t.Fatalf("UpdateResources failed: %v", err)
+	}
+	if diff := cmp.Diff(resources, updated); diff != "" {
+		t.Errorf("unexpected updated resources (-want, +got): %s", diff)
+	}
+}
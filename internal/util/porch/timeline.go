@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// TimelineEvent is a single entry in the timeline of a package.
+type TimelineEvent struct {
+	Time     metav1.Time      `json:"time"`
+	Type     string           `json:"type"`
+	Revision string           `json:"revision"`
+	Actor    string           `json:"actor,omitempty"`
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	Message  string           `json:"message"`
+}
+
+// GetPackageTimeline returns the events of all revisions of the package in chronological
+// order, from the timeline subresource of packages.
+func GetPackageTimeline(ctx context.Context, client rest.Interface, namespace, name string) ([]TimelineEvent, error) {
+	raw, err := client.Get().
+		Namespace(namespace).
+		Resource("packages").
+		Name(name).
+		SubResource("timeline").
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	var timeline struct {
+		Events []TimelineEvent `json:"events"`
+	}
+	if err := json.Unmarshal(raw, &timeline); err != nil {
+		return nil, err
+	}
+	return timeline.Events, nil
+}
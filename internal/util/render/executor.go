@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/GoogleContainerTools/kpt/internal/errors"
 	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
@@ -63,6 +64,11 @@ type Renderer struct {
 
 	// FileSystem is the input filesystem to operate on
 	FileSystem filesys.FileSystem
+
+	// MaxConcurrency is the maximum number of package pipelines run
+	// concurrently. Sibling subpackages are independent of each other and
+	// are hydrated in parallel when it is greater than 1.
+	MaxConcurrency int
 }
 
 // Execute runs a pipeline.
@@ -85,6 +91,9 @@ func (e *Renderer) Execute(ctx context.Context) error {
 		fileSystem:    e.FileSystem,
 		runtime:       e.Runtime,
 	}
+	if e.MaxConcurrency > 1 {
+		hctx.pipelineSlots = make(chan struct{}, e.MaxConcurrency)
+	}
 
 	if _, err = hydrate(ctx, root, hctx); err != nil {
 		// Note(droot): ignore the error in function result saving
@@ -191,6 +200,47 @@ type hydrationContext struct {
 
 	// function runtime
 	runtime fn.FunctionRuntime
+
+	// pipelineSlots bounds the number of pipelines running concurrently.
+	// It is nil when packages are hydrated sequentially.
+	pipelineSlots chan struct{}
+}
+
+// fork returns a hydration context for hydrating a subpackage concurrently
+// with its siblings. It shares the immutable bits with hctx and gathers its
+// own state, which is folded back into hctx by merge.
+func (hctx *hydrationContext) fork() *hydrationContext {
+	pkgs := make(map[types.UniquePath]*pkgNode, len(hctx.pkgs))
+	for k, v := range hctx.pkgs {
+		pkgs[k] = v
+	}
+	return &hydrationContext{
+		root:          hctx.root,
+		pkgs:          pkgs,
+		fnResults:     fnresult.NewResultList(),
+		runnerOptions: hctx.runnerOptions,
+		fileSystem:    hctx.fileSystem,
+		runtime:       hctx.runtime,
+		pipelineSlots: hctx.pipelineSlots,
+	}
+}
+
+// merge folds the state gathered by a forked hydration context into hctx.
+func (hctx *hydrationContext) merge(forked *hydrationContext) {
+	for k, v := range forked.pkgs {
+		hctx.pkgs[k] = v
+	}
+	if len(forked.inputFiles) > 0 {
+		if hctx.inputFiles == nil {
+			hctx.inputFiles = sets.String{}
+		}
+		hctx.inputFiles.Insert(forked.inputFiles.List()...)
+	}
+	hctx.executedFunctionCnt += forked.executedFunctionCnt
+	hctx.fnResults.Items = append(hctx.fnResults.Items, forked.fnResults.Items...)
+	if forked.fnResults.ExitCode != 0 {
+		hctx.fnResults.ExitCode = forked.fnResults.ExitCode
+	}
 }
 
 // pkgNode represents a package being hydrated. Think of it as a node in the hydration DAG.
@@ -289,20 +339,26 @@ func hydrate(ctx context.Context, pn *pkgNode, hctx *hydrationContext) (output [
 		return output, errors.E(op, curr.pkg.UniquePath, err)
 	}
 	// hydrate recursively and gather hydated transitive resources.
-	for _, subpkg := range subpkgs {
-		var transitiveResources []*yaml.RNode
-		var subPkgNode *pkgNode
-
-		if subPkgNode, err = newPkgNode(hctx.fileSystem, "", subpkg); err != nil {
-			return output, errors.E(op, subpkg.UniquePath, err)
+	if hctx.pipelineSlots != nil && len(subpkgs) > 1 {
+		if input, err = hydrateSubpackages(ctx, subpkgs, hctx); err != nil {
+			return output, err
 		}
+	} else {
+		for _, subpkg := range subpkgs {
+			var transitiveResources []*yaml.RNode
+			var subPkgNode *pkgNode
 
-		transitiveResources, err = hydrate(ctx, subPkgNode, hctx)
-		if err != nil {
-			return output, errors.E(op, subpkg.UniquePath, err)
-		}
+			if subPkgNode, err = newPkgNode(hctx.fileSystem, "", subpkg); err != nil {
+				return output, errors.E(op, subpkg.UniquePath, err)
+			}
 
-		input = append(input, transitiveResources...)
+			transitiveResources, err = hydrate(ctx, subPkgNode, hctx)
+			if err != nil {
+				return output, errors.E(op, subpkg.UniquePath, err)
+			}
+
+			input = append(input, transitiveResources...)
+		}
 	}
 
 	// gather resources present at the current package
@@ -319,7 +375,13 @@ func hydrate(ctx context.Context, pn *pkgNode, hctx *hydrationContext) (output [
 	// include current package's resources in the input resource list
 	input = append(input, currPkgResources...)
 
+	if hctx.pipelineSlots != nil {
+		hctx.pipelineSlots <- struct{}{}
+	}
 	output, err = curr.runPipeline(ctx, hctx, input)
+	if hctx.pipelineSlots != nil {
+		<-hctx.pipelineSlots
+	}
 	if err != nil {
 		return output, errors.E(op, curr.pkg.UniquePath, err)
 	}
@@ -331,6 +393,46 @@ func hydrate(ctx context.Context, pn *pkgNode, hctx *hydrationContext) (output [
 	return output, err
 }
 
+// hydrateSubpackages hydrates sibling subpackages concurrently and returns
+// their wet resources. Each subpackage is hydrated with a fork of hctx, and
+// the forks are merged back in subpackage order so that the resources, function
+// results and errors are the same as those of a sequential walk.
+func hydrateSubpackages(ctx context.Context, subpkgs []*pkg.Pkg, hctx *hydrationContext) ([]*yaml.RNode, error) {
+	const op errors.Op = "pkg.render"
+
+	forks := make([]*hydrationContext, len(subpkgs))
+	outputs := make([][]*yaml.RNode, len(subpkgs))
+	errs := make([]error, len(subpkgs))
+
+	var wg sync.WaitGroup
+	for i, subpkg := range subpkgs {
+		forks[i] = hctx.fork()
+		wg.Add(1)
+		go func(i int, subpkg *pkg.Pkg) {
+			defer wg.Done()
+			subPkgNode, err := newPkgNode(hctx.fileSystem, "", subpkg)
+			if err != nil {
+				errs[i] = errors.E(op, subpkg.UniquePath, err)
+				return
+			}
+			if outputs[i], err = hydrate(ctx, subPkgNode, forks[i]); err != nil {
+				errs[i] = errors.E(op, subpkg.UniquePath, err)
+			}
+		}(i, subpkg)
+	}
+	wg.Wait()
+
+	var resources []*yaml.RNode
+	for i := range subpkgs {
+		hctx.merge(forks[i])
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		resources = append(resources, outputs[i]...)
+	}
+	return resources, nil
+}
+
 // runPipeline runs the pipeline defined at current pkgNode on given input resources.
 func (pn *pkgNode) runPipeline(ctx context.Context, hctx *hydrationContext, input []*yaml.RNode) ([]*yaml.RNode, error) {
 	const op errors.Op = "pipeline.run"
@@ -23,6 +23,9 @@ import (
 type RenderOptions struct {
 	PkgPath string
 	Runtime FunctionRuntime
+	// MaxConcurrency is the maximum number of subpackage pipelines rendered
+	// concurrently. Values below 2 render subpackages sequentially.
+	MaxConcurrency int
 }
 
 type Renderer interface {
@@ -47,6 +47,8 @@ type InitOptions struct {
 	Desc     string
 	Keywords []string
 	Site     string
+	// License is the SPDX license identifier of the package.
+	License string
 }
 
 // DefaultInitilizer implements Initializer interface.
@@ -94,6 +96,7 @@ func (i *DefaultInitializer) Initialize(
 				Description: opts.Desc,
 				Site:        opts.Site,
 				Keywords:    opts.Keywords,
+				License:     opts.License,
 			},
 		}
 
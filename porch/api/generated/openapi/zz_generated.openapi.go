@@ -29,95 +29,321 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition":                    schema_porch_api_porch_v1alpha1_Condition(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Function":                     schema_porch_api_porch_v1alpha1_Function(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionConfig":               schema_porch_api_porch_v1alpha1_FunctionConfig(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionEvalTaskSpec":         schema_porch_api_porch_v1alpha1_FunctionEvalTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionList":                 schema_porch_api_porch_v1alpha1_FunctionList(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionRef":                  schema_porch_api_porch_v1alpha1_FunctionRef(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionSpec":                 schema_porch_api_porch_v1alpha1_FunctionSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionStatus":               schema_porch_api_porch_v1alpha1_FunctionStatus(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.GitLock":                      schema_porch_api_porch_v1alpha1_GitLock(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.GitPackage":                   schema_porch_api_porch_v1alpha1_GitPackage(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.OciPackage":                   schema_porch_api_porch_v1alpha1_OciPackage(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Package":                      schema_porch_api_porch_v1alpha1_Package(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageCloneTaskSpec":         schema_porch_api_porch_v1alpha1_PackageCloneTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageEditTaskSpec":          schema_porch_api_porch_v1alpha1_PackageEditTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageInitTaskSpec":          schema_porch_api_porch_v1alpha1_PackageInitTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageList":                  schema_porch_api_porch_v1alpha1_PackageList(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackagePatchTaskSpec":         schema_porch_api_porch_v1alpha1_PackagePatchTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision":              schema_porch_api_porch_v1alpha1_PackageRevision(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionList":          schema_porch_api_porch_v1alpha1_PackageRevisionList(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionRef":           schema_porch_api_porch_v1alpha1_PackageRevisionRef(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources":     schema_porch_api_porch_v1alpha1_PackageRevisionResources(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesList": schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec": schema_porch_api_porch_v1alpha1_PackageRevisionResourcesSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec":          schema_porch_api_porch_v1alpha1_PackageRevisionSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus":        schema_porch_api_porch_v1alpha1_PackageRevisionStatus(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageSpec":                  schema_porch_api_porch_v1alpha1_PackageSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageStatus":                schema_porch_api_porch_v1alpha1_PackageStatus(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageUpdateTaskSpec":        schema_porch_api_porch_v1alpha1_PackageUpdateTaskSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference":              schema_porch_api_porch_v1alpha1_ParentReference(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec":                    schema_porch_api_porch_v1alpha1_PatchSpec(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate":                schema_porch_api_porch_v1alpha1_ReadinessGate(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryRef":                schema_porch_api_porch_v1alpha1_RepositoryRef(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.SecretRef":                    schema_porch_api_porch_v1alpha1_SecretRef(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Selector":                     schema_porch_api_porch_v1alpha1_Selector(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task":                         schema_porch_api_porch_v1alpha1_Task(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock":                 schema_porch_api_porch_v1alpha1_UpstreamLock(ref),
-		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage":              schema_porch_api_porch_v1alpha1_UpstreamPackage(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroup":                                             schema_pkg_apis_meta_v1_APIGroup(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroupList":                                         schema_pkg_apis_meta_v1_APIGroupList(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.APIResource":                                          schema_pkg_apis_meta_v1_APIResource(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.APIResourceList":                                      schema_pkg_apis_meta_v1_APIResourceList(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.APIVersions":                                          schema_pkg_apis_meta_v1_APIVersions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ApplyOptions":                                         schema_pkg_apis_meta_v1_ApplyOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Condition":                                            schema_pkg_apis_meta_v1_Condition(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.CreateOptions":                                        schema_pkg_apis_meta_v1_CreateOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.DeleteOptions":                                        schema_pkg_apis_meta_v1_DeleteOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Duration":                                             schema_pkg_apis_meta_v1_Duration(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.FieldsV1":                                             schema_pkg_apis_meta_v1_FieldsV1(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GetOptions":                                           schema_pkg_apis_meta_v1_GetOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupKind":                                            schema_pkg_apis_meta_v1_GroupKind(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupResource":                                        schema_pkg_apis_meta_v1_GroupResource(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersion":                                         schema_pkg_apis_meta_v1_GroupVersion(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionForDiscovery":                             schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionKind":                                     schema_pkg_apis_meta_v1_GroupVersionKind(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionResource":                                 schema_pkg_apis_meta_v1_GroupVersionResource(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.InternalEvent":                                        schema_pkg_apis_meta_v1_InternalEvent(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector":                                        schema_pkg_apis_meta_v1_LabelSelector(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelectorRequirement":                             schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.List":                                                 schema_pkg_apis_meta_v1_List(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta":                                             schema_pkg_apis_meta_v1_ListMeta(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ListOptions":                                          schema_pkg_apis_meta_v1_ListOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ManagedFieldsEntry":                                   schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime":                                            schema_pkg_apis_meta_v1_MicroTime(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta":                                           schema_pkg_apis_meta_v1_ObjectMeta(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference":                                       schema_pkg_apis_meta_v1_OwnerReference(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.PartialObjectMetadata":                                schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.PartialObjectMetadataList":                            schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Patch":                                                schema_pkg_apis_meta_v1_Patch(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.PatchOptions":                                         schema_pkg_apis_meta_v1_PatchOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Preconditions":                                        schema_pkg_apis_meta_v1_Preconditions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.RootPaths":                                            schema_pkg_apis_meta_v1_RootPaths(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.ServerAddressByClientCIDR":                            schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Status":                                               schema_pkg_apis_meta_v1_Status(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.StatusCause":                                          schema_pkg_apis_meta_v1_StatusCause(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.StatusDetails":                                        schema_pkg_apis_meta_v1_StatusDetails(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Table":                                                schema_pkg_apis_meta_v1_Table(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.TableColumnDefinition":                                schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.TableOptions":                                         schema_pkg_apis_meta_v1_TableOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.TableRow":                                             schema_pkg_apis_meta_v1_TableRow(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.TableRowCondition":                                    schema_pkg_apis_meta_v1_TableRowCondition(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Time":                                                 schema_pkg_apis_meta_v1_Time(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.Timestamp":                                            schema_pkg_apis_meta_v1_Timestamp(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta":                                             schema_pkg_apis_meta_v1_TypeMeta(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.UpdateOptions":                                        schema_pkg_apis_meta_v1_UpdateOptions(ref),
-		"k8s.io/apimachinery/pkg/apis/meta/v1.WatchEvent":                                           schema_pkg_apis_meta_v1_WatchEvent(ref),
-		"k8s.io/apimachinery/pkg/runtime.RawExtension":                                              schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
-		"k8s.io/apimachinery/pkg/runtime.TypeMeta":                                                  schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
-		"k8s.io/apimachinery/pkg/runtime.Unknown":                                                   schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
-		"k8s.io/apimachinery/pkg/version.Info":                                                      schema_k8sio_apimachinery_pkg_version_Info(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Artifact":                              schema_porch_api_porch_v1alpha1_Artifact(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApproval":                          schema_porch_api_porch_v1alpha1_BulkApproval(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalResult":                    schema_porch_api_porch_v1alpha1_BulkApprovalResult(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalSpec":                      schema_porch_api_porch_v1alpha1_BulkApprovalSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalStatus":                    schema_porch_api_porch_v1alpha1_BulkApprovalStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition":                             schema_porch_api_porch_v1alpha1_Condition(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ConsistencyFinding":                    schema_porch_api_porch_v1alpha1_ConsistencyFinding(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.DeploymentStatus":                      schema_porch_api_porch_v1alpha1_DeploymentStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Function":                              schema_porch_api_porch_v1alpha1_Function(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionConfig":                        schema_porch_api_porch_v1alpha1_FunctionConfig(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionEvalTaskSpec":                  schema_porch_api_porch_v1alpha1_FunctionEvalTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionList":                          schema_porch_api_porch_v1alpha1_FunctionList(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionLog":                           schema_porch_api_porch_v1alpha1_FunctionLog(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionRef":                           schema_porch_api_porch_v1alpha1_FunctionRef(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionResult":                        schema_porch_api_porch_v1alpha1_FunctionResult(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionSpec":                          schema_porch_api_porch_v1alpha1_FunctionSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionStatus":                        schema_porch_api_porch_v1alpha1_FunctionStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.GitLock":                               schema_porch_api_porch_v1alpha1_GitLock(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.GitPackage":                            schema_porch_api_porch_v1alpha1_GitPackage(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedDownstream":                       schema_porch_api_porch_v1alpha1_MovedDownstream(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedPackageRevision":                  schema_porch_api_porch_v1alpha1_MovedPackageRevision(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.OciPackage":                            schema_porch_api_porch_v1alpha1_OciPackage(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Package":                               schema_porch_api_porch_v1alpha1_Package(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageCloneTaskSpec":                  schema_porch_api_porch_v1alpha1_PackageCloneTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageEditTaskSpec":                   schema_porch_api_porch_v1alpha1_PackageEditTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageInitTaskSpec":                   schema_porch_api_porch_v1alpha1_PackageInitTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageFreeze":                         schema_porch_api_porch_v1alpha1_PackageFreeze(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageList":                           schema_porch_api_porch_v1alpha1_PackageList(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMove":                           schema_porch_api_porch_v1alpha1_PackageMove(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveSpec":                       schema_porch_api_porch_v1alpha1_PackageMoveSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveStatus":                     schema_porch_api_porch_v1alpha1_PackageMoveStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackagePatchTaskSpec":                  schema_porch_api_porch_v1alpha1_PackagePatchTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRenderTaskSpec":                 schema_porch_api_porch_v1alpha1_PackageRenderTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision":                       schema_porch_api_porch_v1alpha1_PackageRevision(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionList":                   schema_porch_api_porch_v1alpha1_PackageRevisionList(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionLogs":                   schema_porch_api_porch_v1alpha1_PackageRevisionLogs(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionRef":                    schema_porch_api_porch_v1alpha1_PackageRevisionRef(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources":              schema_porch_api_porch_v1alpha1_PackageRevisionResources(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesFinalize":      schema_porch_api_porch_v1alpha1_PackageRevisionResourcesFinalize(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesFinalizeSpec":  schema_porch_api_porch_v1alpha1_PackageRevisionResourcesFinalizeSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesList":          schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec":          schema_porch_api_porch_v1alpha1_PackageRevisionResourcesSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStaging":       schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStaging(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingSpec":   schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStagingSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingStatus": schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStagingStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec":                   schema_porch_api_porch_v1alpha1_PackageRevisionSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus":                 schema_porch_api_porch_v1alpha1_PackageRevisionStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionTimestamps":             schema_porch_api_porch_v1alpha1_PackageRevisionTimestamps(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageSpec":                           schema_porch_api_porch_v1alpha1_PackageSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageStatus":                         schema_porch_api_porch_v1alpha1_PackageStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageTimeline":                       schema_porch_api_porch_v1alpha1_PackageTimeline(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageUpdateTaskSpec":                 schema_porch_api_porch_v1alpha1_PackageUpdateTaskSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference":                       schema_porch_api_porch_v1alpha1_ParentReference(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec":                             schema_porch_api_porch_v1alpha1_PatchSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate":                         schema_porch_api_porch_v1alpha1_ReadinessGate(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RenderChange":                          schema_porch_api_porch_v1alpha1_RenderChange(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheck":            schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheck(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckSpec":        schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheckSpec(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckStatus":      schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheckStatus(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryRef":                         schema_porch_api_porch_v1alpha1_RepositoryRef(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.SecretKeyRef":                          schema_porch_api_porch_v1alpha1_SecretKeyRef(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.SecretRef":                             schema_porch_api_porch_v1alpha1_SecretRef(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Selector":                              schema_porch_api_porch_v1alpha1_Selector(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.StagedChunk":                           schema_porch_api_porch_v1alpha1_StagedChunk(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task":                                  schema_porch_api_porch_v1alpha1_Task(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskLog":                               schema_porch_api_porch_v1alpha1_TaskLog(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskResult":                            schema_porch_api_porch_v1alpha1_TaskResult(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TimelineEvent":                         schema_porch_api_porch_v1alpha1_TimelineEvent(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock":                          schema_porch_api_porch_v1alpha1_UpstreamLock(ref),
+		"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage":                       schema_porch_api_porch_v1alpha1_UpstreamPackage(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroup":                                                      schema_pkg_apis_meta_v1_APIGroup(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroupList":                                                  schema_pkg_apis_meta_v1_APIGroupList(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.APIResource":                                                   schema_pkg_apis_meta_v1_APIResource(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.APIResourceList":                                               schema_pkg_apis_meta_v1_APIResourceList(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.APIVersions":                                                   schema_pkg_apis_meta_v1_APIVersions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ApplyOptions":                                                  schema_pkg_apis_meta_v1_ApplyOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Condition":                                                     schema_pkg_apis_meta_v1_Condition(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.CreateOptions":                                                 schema_pkg_apis_meta_v1_CreateOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.DeleteOptions":                                                 schema_pkg_apis_meta_v1_DeleteOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Duration":                                                      schema_pkg_apis_meta_v1_Duration(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.FieldsV1":                                                      schema_pkg_apis_meta_v1_FieldsV1(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GetOptions":                                                    schema_pkg_apis_meta_v1_GetOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupKind":                                                     schema_pkg_apis_meta_v1_GroupKind(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupResource":                                                 schema_pkg_apis_meta_v1_GroupResource(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersion":                                                  schema_pkg_apis_meta_v1_GroupVersion(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionForDiscovery":                                      schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionKind":                                              schema_pkg_apis_meta_v1_GroupVersionKind(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.GroupVersionResource":                                          schema_pkg_apis_meta_v1_GroupVersionResource(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.InternalEvent":                                                 schema_pkg_apis_meta_v1_InternalEvent(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector":                                                 schema_pkg_apis_meta_v1_LabelSelector(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelectorRequirement":                                      schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.List":                                                          schema_pkg_apis_meta_v1_List(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta":                                                      schema_pkg_apis_meta_v1_ListMeta(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ListOptions":                                                   schema_pkg_apis_meta_v1_ListOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ManagedFieldsEntry":                                            schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime":                                                     schema_pkg_apis_meta_v1_MicroTime(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta":                                                    schema_pkg_apis_meta_v1_ObjectMeta(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference":                                                schema_pkg_apis_meta_v1_OwnerReference(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.PartialObjectMetadata":                                         schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.PartialObjectMetadataList":                                     schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Patch":                                                         schema_pkg_apis_meta_v1_Patch(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.PatchOptions":                                                  schema_pkg_apis_meta_v1_PatchOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Preconditions":                                                 schema_pkg_apis_meta_v1_Preconditions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.RootPaths":                                                     schema_pkg_apis_meta_v1_RootPaths(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ServerAddressByClientCIDR":                                     schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Status":                                                        schema_pkg_apis_meta_v1_Status(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.StatusCause":                                                   schema_pkg_apis_meta_v1_StatusCause(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.StatusDetails":                                                 schema_pkg_apis_meta_v1_StatusDetails(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Table":                                                         schema_pkg_apis_meta_v1_Table(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TableColumnDefinition":                                         schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TableOptions":                                                  schema_pkg_apis_meta_v1_TableOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TableRow":                                                      schema_pkg_apis_meta_v1_TableRow(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TableRowCondition":                                             schema_pkg_apis_meta_v1_TableRowCondition(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Time":                                                          schema_pkg_apis_meta_v1_Time(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Timestamp":                                                     schema_pkg_apis_meta_v1_Timestamp(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta":                                                      schema_pkg_apis_meta_v1_TypeMeta(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.UpdateOptions":                                                 schema_pkg_apis_meta_v1_UpdateOptions(ref),
+		"k8s.io/apimachinery/pkg/apis/meta/v1.WatchEvent":                                                    schema_pkg_apis_meta_v1_WatchEvent(ref),
+		"k8s.io/apimachinery/pkg/runtime.RawExtension":                                                       schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
+		"k8s.io/apimachinery/pkg/runtime.TypeMeta":                                                           schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
+		"k8s.io/apimachinery/pkg/runtime.Unknown":                                                            schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
+		"k8s.io/apimachinery/pkg/version.Info":                                                               schema_k8sio_apimachinery_pkg_version_Info(ref),
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_Artifact(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Artifact is an artifact exported from a packagerevision on publish.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the format of the artifact.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URL is the location of the artifact, for example oci://registry/repository:tag.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digest is the digest of the artifact.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "url"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_BulkApproval(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BulkApproval approves several proposed package revisions at once. The preconditions of all package revisions are checked before any of them is published, and the package revisions which meet them are published concurrently; a package revision which can't be approved doesn't stop the others. Like a PackageMove, it can only be created: the approval runs when it is created, and the created object is returned with the outcome for every package revision in its status, but not stored.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_BulkApprovalResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BulkApprovalResult is the outcome of approving one package revision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the package revision.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"outcome": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Outcome is the outcome of the approval of the package revision.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the revision of the published package revision.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is the reason of the error of a package revision which wasn't published, as in the status of a failed request.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message explains the error of a package revision which wasn't published.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "outcome"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_BulkApprovalSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BulkApprovalSpec defines the package revisions to approve.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"packageRevisions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PackageRevisions are the names of the proposed package revisions to approve, in the namespace of the approval.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"packageRevisions"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_BulkApprovalStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BulkApprovalStatus is the outcome of the approval.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Results are the outcomes for the package revisions, in the order of spec.packageRevisions.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalResult"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.BulkApprovalResult"},
 	}
 }
 
@@ -160,6 +386,103 @@ func schema_porch_api_porch_v1alpha1_Condition(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_porch_api_porch_v1alpha1_ConsistencyFinding(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ConsistencyFinding is an inconsistency found by a RepositoryConsistencyCheck.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the class of the inconsistency.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"packageRevision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PackageRevision is the name of the package revision the inconsistency is about.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describes the inconsistency.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"repaired": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Repaired is true if the inconsistency was repaired by the check.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "message"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_DeploymentStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DeploymentStatus is the state of a packagerevision observed in the cluster it is deployed to.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster identifies the cluster the packagerevision is deployed to.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observedCommit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedCommit is the commit of the packagerevision the agent last applied.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"syncStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SyncStatus is whether the packagerevision is in sync with the cluster.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"syncedTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SyncedTime is when the agent last synced the packagerevision.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reportedTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReportedTime is when Porch received the report. It is set by Porch.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"cluster", "syncStatus"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_porch_api_porch_v1alpha1_Function(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -298,6 +621,21 @@ func schema_porch_api_porch_v1alpha1_FunctionEvalTaskSpec(ref common.ReferenceCa
 							Ref:         ref("k8s.io/apimachinery/pkg/runtime.RawExtension"),
 						},
 					},
+					"configSecretRefs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`ConfigSecretRefs` maps function config keys to keys of Secrets in the namespace of the package revision. The secret values are set in the function config when the function is evaluated, and are never stored in the package revision. With `ConfigMap`, the config key is a key of its data; with `Config`, it is a dot-separated field path.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.SecretKeyRef"),
+									},
+								},
+							},
+						},
+					},
 					"includeMetaResources": {
 						SchemaProps: spec.SchemaProps{
 							Description: "If enabled, meta resources (i.e. `Kptfile` and `functionConfig`) are included in the input to the function. By default it is disabled.",
@@ -323,7 +661,7 @@ func schema_porch_api_porch_v1alpha1_FunctionEvalTaskSpec(ref common.ReferenceCa
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionRef", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Selector", "k8s.io/apimachinery/pkg/runtime.RawExtension"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionRef", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.SecretKeyRef", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Selector", "k8s.io/apimachinery/pkg/runtime.RawExtension"},
 	}
 }
 
@@ -376,25 +714,116 @@ func schema_porch_api_porch_v1alpha1_FunctionList(ref common.ReferenceCallback)
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_FunctionRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_FunctionLog(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "FunctionRef is a reference to a Function resource.",
+				Description: "FunctionLog is the log a function wrote to stderr. If the function failed, it is the error the function runtime reported. Secret values injected into the function config are redacted.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"image": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name is the name of the Function resource referenced. The resource is expected to be within the same namespace.",
+							Description: "Image is the image of the function.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-				Required: []string{"name"},
-			},
-		},
+					"log": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Log is the end of the log of the function.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"truncated": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Truncated is set if the beginning of the log was dropped to bound its size.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"image"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_FunctionRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FunctionRef is a reference to a Function resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the Function resource referenced. The resource is expected to be within the same namespace.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_FunctionResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FunctionResult is a result which a function reported when the package was rendered.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"function": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Function` is the image (or exec path) of the function which reported the result.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Message` is the message of the result.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"severity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Severity` is the severity of the result: error, warning or info.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Resource` identifies the resource the result refers to, if any, as apiVersion/kind/namespace/name.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"field": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Field` is the path of the field the result refers to, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`File` is the path of the file the result refers to, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -585,6 +1014,110 @@ func schema_porch_api_porch_v1alpha1_GitPackage(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_porch_api_porch_v1alpha1_MovedDownstream(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MovedDownstream is the outcome of updating a package revision based on a moved package revision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the downstream package revision.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"upstream": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Upstream is the name of the moved package revision the downstream is based on.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"newUpstream": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NewUpstream is the name of the package revision in the destination repository the downstream is updated to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"outcome": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Outcome is the outcome of the update.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message explains the outcome.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "upstream", "outcome"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_MovedPackageRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MovedPackageRevision is the outcome of moving one package revision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the revision of the package revision, kept by the move.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the name of the package revision in the source repository.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"destination": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Destination is the name of the package revision in the destination repository.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"outcome": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Outcome is the outcome of the move of the package revision.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message explains the outcome.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"revision", "source", "outcome"},
+			},
+		},
+	}
+}
+
 func schema_porch_api_porch_v1alpha1_OciPackage(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -674,11 +1207,17 @@ func schema_porch_api_porch_v1alpha1_PackageCloneTaskSpec(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"resolvedUpstreamRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`ResolvedUpstreamRef` is set by the server when `Upstream.UpstreamRef` refers to a package rather than to a specific PackageRevision. It records the PackageRevision that was cloned so that the package is recloned from the same revision as long as `Upstream` does not change.",
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionRef"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionRef", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"},
 	}
 }
 
@@ -744,9 +1283,69 @@ func schema_porch_api_porch_v1alpha1_PackageInitTaskSpec(ref common.ReferenceCal
 							Format:      "",
 						},
 					},
+					"license": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`License` is the SPDX license identifier of the package, for example Apache-2.0.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"starters": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Starters` are the names of starter resources to add to the package, from the set configured in Porch, for example namespace and resourcequota.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageFreeze(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageFreeze is the freeze of a package, for example during incident response.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is why the package is frozen.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"frozenBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FrozenBy is the user who froze the package. It is set by Porch.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"frozenAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FrozenAt is when the package was frozen. It is set by Porch.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
+				Required: []string{"reason"},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -799,39 +1398,11 @@ func schema_porch_api_porch_v1alpha1_PackageList(ref common.ReferenceCallback) c
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackagePatchTaskSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"patches": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Patches is a list of individual patch operations.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec"),
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec"},
-	}
-}
-
-func schema_porch_api_porch_v1alpha1_PackageRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageMove(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevision",
+				Description: "PackageMove moves a package to another repository. The published revisions of the package are republished in the destination repository as clones of the originals, the source package is archived and frozen with a forwarding annotation, and the drafts based on the package can be updated to the moved revisions. Like a RepositoryConsistencyCheck, it can only be created: the move runs when it is created, and the created object is returned with the outcome in its status, but not stored. Every step of a move is skipped if it was already done, so a move which failed part way is resumed by creating it again.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -857,99 +1428,237 @@ func schema_porch_api_porch_v1alpha1_PackageRevision(ref common.ReferenceCallbac
 					"spec": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec"),
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveSpec"),
 						},
 					},
 					"status": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus"),
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageMoveStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageMoveSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionList",
+				Description: "PackageMoveSpec defines the move to run.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"packageName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "PackageName is the name of the package to move.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"sourceRepository": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "SourceRepository is the name of the Repository the package is moved from, in the namespace of the move.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"destinationRepository": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							Description: "DestinationRepository is the name of the Repository the package is moved to, in the namespace of the move.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"items": {
+					"includeHistory": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "IncludeHistory moves all published revisions of the package instead of the latest.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"updateDownstreams": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdateDownstreams updates the drafts based on the moved revisions to the moved revisions in the destination repository.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"dryRun": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DryRun reports the steps of the move without running them.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"packageName", "sourceRepository", "destinationRepository"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageMoveStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageMoveStatus is the outcome of the move.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"revisions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revisions are the moved package revisions, oldest revision first.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision"),
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedPackageRevision"),
+									},
+								},
+							},
+						},
+					},
+					"downstreams": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Downstreams are the package revisions based on the moved package revisions.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedDownstream"),
 									},
 								},
 							},
 						},
 					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the outcome of archiving and freezing the source package, which is done once all revisions are moved.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sourceMessage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SourceMessage explains the outcome of marking the source package.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
-				Required: []string{"items"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedDownstream", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.MovedPackageRevision"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackagePatchTaskSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionRef is a reference to a package revision.",
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"patches": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Patches is a list of individual patch operations.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PatchSpec"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRenderTaskSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRenderTaskSpec defines the render of the package, which runs the functions of the pipelines of its Kptfiles.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"recordChanges": {
 						SchemaProps: spec.SchemaProps{
-							Description: "`Name` is the name of the referenced PackageRevision resource.",
-							Default:     "",
-							Type:        []string{"string"},
+							Description: "`RecordChanges`, if enabled, records which function changed which resources in the `Changes` of the render task.",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
+					"appendedFunctions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`AppendedFunctions` are the images of the functions which were appended to the pipeline of the package by the porch.kpt.dev/pipeline-append annotation when it was rendered. It is set by Porch.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Results` are the results, such as warnings and validation failures, which the functions reported when the package was rendered. It is set by Porch.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionResult"),
+									},
+								},
+							},
+						},
+					},
+					"changes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Changes` are the changes which the functions made to the resources, in pipeline order, if `RecordChanges` is enabled or the server records the changes of every render. It is set by Porch.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RenderChange"),
+									},
+								},
+							},
+						},
+					},
 				},
-				Required: []string{"name"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionResult", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RenderChange"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionResources(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionResources",
+				Description: "PackageRevision",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -975,22 +1684,28 @@ func schema_porch_api_porch_v1alpha1_PackageRevisionResources(ref common.Referen
 					"spec": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec"),
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageRevisionList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionResourcesList",
+				Description: "PackageRevisionList",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -1020,7 +1735,7 @@ func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref common.Ref
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources"),
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision"),
 									},
 								},
 							},
@@ -1031,49 +1746,46 @@ func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref common.Ref
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevision", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageRevisionLogs(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionResourcesSpec represents resources (as ResourceList serialized as yaml string) of the PackageRevision.",
+				Description: "PackageRevisionLogs are the logs the functions of a package revision wrote to stderr while its tasks ran on the Porch server, for debugging functions which misbehave. It is the logs subresource of a PackageRevision. The logs are kept in memory for a limited time after the tasks ran, and are lost when the server restarts.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"packageName": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PackageName identifies the package in the repository.",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"revision": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Revision identifies the version of the package.",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"repository": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RepositoryName is the name of the Repository object containing this package.",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
 						},
 					},
-					"resources": {
+					"tasks": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Resources are the content of the package.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Tasks are the logs of the tasks whose functions wrote logs, ordered by task index.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskLog"),
 									},
 								},
 							},
@@ -1082,124 +1794,604 @@ func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesSpec(ref common.Ref
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskLog", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageRevisionRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionSpec defines the desired state of PackageRevision",
+				Description: "PackageRevisionRef is a reference to a package revision.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"packageName": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PackageName identifies the package in the repository.",
+							Description: "`Name` is the name of the referenced PackageRevision resource.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"revision": {
+					"repository": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Revision identifies the version of the package.",
+							Description: "`Repository` is the name of the Repository containing the referenced package. Used together with `Package` when `Name` is not set.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"repository": {
+					"package": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RepositoryName is the name of the Repository object containing this package.",
+							Description: "`Package` is the name of the referenced package. Used together with `Repository` when `Name` is not set.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"parent": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Parent references a package that provides resources to us",
-							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference"),
-						},
-					},
-					"lifecycle": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"tasks": {
+					"revision": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task"),
-									},
-								},
-							},
+							Description: "`Revision` of the referenced package. If empty or `latest`, the latest published revision is referenced.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"readinessGates": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate"),
-									},
-								},
-							},
+							Description: "`Namespace` of the Repository containing the referenced package, if it is not the namespace of the referencing package. The Repository must be shared with the namespace of the referencing package.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"name"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PackageRevisionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_PackageRevisionResources(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PackageRevisionStatus defines the observed state of PackageRevision",
+				Description: "PackageRevisionResources",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"upstreamLock": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock"),
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"publishedBy": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PublishedBy is the identity of the user who approved the packagerevision.",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"publishTimestamp": {
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesFinalize(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesFinalize replaces the resources of a draft package revision with the files of a manifest, assembled from the chunks staged through the staging subresource. It is the finalize subresource of a PackageRevisionResources, and can only be created; the updated PackageRevisionResources is returned. Finalizing fails with a BadRequest error if any chunk of the manifest isn't staged, and discards the staged chunks once it succeeds.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesFinalizeSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesFinalizeSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesFinalizeSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesFinalizeSpec is the manifest of the new resources.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"files": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Files are the digests of the chunks making up each file of the package, in order.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type: []string{"array"},
+										Items: &spec.SchemaOrArray{
+											Schema: &spec.Schema{
+												SchemaProps: spec.SchemaProps{
+													Default: "",
+													Type:    []string{"string"},
+													Format:  "",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"files"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesList",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResources", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesSpec represents resources (as ResourceList serialized as yaml string) of the PackageRevision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"packageName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PackageName identifies the package in the repository.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision identifies the version of the package.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"repository": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RepositoryName is the name of the Repository object containing this package.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources are the content of the package.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"fileModes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileModes are the modes of the files of the package which aren't regular files, such as executable scripts, by the path of the file. Files which aren't listed are regular files. When the resources are updated, the listed modes are set, and the files which aren't listed keep their modes.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStaging(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesStaging stages chunks of new resources of a draft package revision, for a resumable upload. It is the staging subresource of a PackageRevisionResources, and can only be created: the chunks are staged when it is created, and the created object is returned with the chunks the server is missing in its status. Staged chunks are kept on disk, so an interrupted upload is resumed after the server restarts by staging only the missing chunks, until the upload is finalized or abandoned for longer than the staging TTL of the server.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionResourcesStagingStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStagingSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesStagingSpec defines the chunks to stage.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"chunks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Chunks are the chunks to stage.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.StagedChunk"),
+									},
+								},
+							},
+						},
+					},
+					"digests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digests are the digests of chunks whose staging is checked once the chunks are staged; the digests which aren't staged are returned in the status.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.StagedChunk"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionResourcesStagingStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionResourcesStagingStatus reports the chunks which aren't staged.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"missing": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Missing are the digests of the spec which aren't staged.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionSpec defines the desired state of PackageRevision",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"packageName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PackageName identifies the package in the repository.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision identifies the version of the package.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"repository": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RepositoryName is the name of the Repository object containing this package.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"parent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Parent references a package that provides resources to us",
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference"),
+						},
+					},
+					"lifecycle": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"tasks": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task"),
+									},
+								},
+							},
+						},
+					},
+					"readinessGates": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ParentReference", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ReadinessGate", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Task"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionStatus defines the observed state of PackageRevision",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"upstreamLock": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock"),
+						},
+					},
+					"publishedBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PublishedBy is the identity of the user who approved the packagerevision.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"publishTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PublishedAt is the time when the packagerevision were approved.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"draftCreatedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DraftCreatedAt is the time when the packagerevision was created as a draft.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"proposedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProposedAt is the time when the packagerevision was last proposed.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"proposedBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProposedBy is the identity of the user who last proposed the packagerevision.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deployment": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deployment is true if this is a deployment package (in a deployment repository).",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition"),
+									},
+								},
+							},
+						},
+					},
+					"artifacts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PublishedAt is the time when the packagerevision were approved.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							Description: "Artifacts are the artifacts exported from the packagerevision when it was published.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Artifact"),
+									},
+								},
+							},
 						},
 					},
-					"deployment": {
+					"archived": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deployment is true if this is a deployment package (in a deployment repository).",
+							Description: "Archived is true if the package of the packagerevision is archived.",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"conditions": {
+					"sharedFrom": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "SharedFrom is the namespace of the shared repository of the packagerevision, if it is listed in another namespace the repository is shared with.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timestamps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamps are the raw timestamps the times of the packagerevision are derived from, for debugging clock skew between the repository and the API server.",
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionTimestamps"),
+						},
+					},
+					"deploymentStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeploymentStatus is the state of the packagerevision observed where it is deployed, as last reported through the deployment subresource by the agent syncing it, for example Config Sync or Flux. It doesn't affect the lifecycle of the packagerevision.",
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.DeploymentStatus"),
+						},
+					},
+					"taskResults": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TaskResults are the results of the tasks applied by the last create or update of the packagerevision, in the order they were applied.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition"),
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskResult"),
 									},
 								},
 							},
@@ -1209,7 +2401,43 @@ func schema_porch_api_porch_v1alpha1_PackageRevisionStatus(ref common.ReferenceC
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Artifact", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.Condition", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.DeploymentStatus", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRevisionTimestamps", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TaskResult", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamLock", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PackageRevisionTimestamps(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageRevisionTimestamps are the raw timestamps of a packagerevision: the time of its commit in the repository, and the times Porch recorded with the clock of the API server. The times reported by a packagerevision are derived from them: the commit time for a published packagerevision and the recorded creation time for a draft or proposed one. They may disagree if the clocks are skewed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"commitTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CommitTime is the time of the last commit of the packagerevision in the repository.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"recordedCreatedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RecordedCreatedAt is the time Porch recorded the creation of the packagerevision.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"recordedPublishedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RecordedPublishedAt is the time Porch recorded the publication of the packagerevision.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -1234,9 +2462,31 @@ func schema_porch_api_porch_v1alpha1_PackageSpec(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Description is a human readable description of the package. Like the labels and annotations of the package, it is kept by Porch rather than in the repository.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archived": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Archived packages and their revisions are hidden from lists unless selected with a field selector, and no new revisions can be created. Their contents are preserved; clearing Archived restores the package.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"freeze": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Freeze, if set, freezes the package: no revisions of the package can be created, proposed, published or deleted until it is cleared. It can only be changed through the freeze subresource.",
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageFreeze"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageFreeze"},
 	}
 }
 
@@ -1260,6 +2510,55 @@ func schema_porch_api_porch_v1alpha1_PackageStatus(ref common.ReferenceCallback)
 	}
 }
 
+func schema_porch_api_porch_v1alpha1_PackageTimeline(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackageTimeline is the history of a package: the creation of its revisions, the tasks applied to them, their lifecycle transitions and conditions, and their deletions. It is the timeline subresource of a Package, and is computed from the task results and lifecycle times Porch records in the metadata of the package revisions.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"events": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Events are the events of the package in chronological order.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TimelineEvent"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.TimelineEvent", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
 func schema_porch_api_porch_v1alpha1_PackageUpdateTaskSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1268,106 +2567,278 @@ func schema_porch_api_porch_v1alpha1_PackageUpdateTaskSpec(ref common.ReferenceC
 				Properties: map[string]spec.Schema{
 					"upstreamRef": {
 						SchemaProps: spec.SchemaProps{
-							Description: "`Upstream` is the reference to the upstream package.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"),
+							Description: "`Upstream` is the reference to the upstream package.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_ParentReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ParentReference is a reference to a parent package",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the parent PackageRevision",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_PatchSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"contents": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"patchType": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_ReadinessGate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditionType": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_RenderChange(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RenderChange is a change which a function made to a resource when the package was rendered.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"function": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Function` is the image (or exec path) of the function which made the change.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Resource` identifies the resource as apiVersion/kind/namespace/name.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Type` is the type of the change: Created, Modified or Deleted.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"resource", "type"},
+			},
+		},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheck(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RepositoryConsistencyCheck audits the consistency of the package revisions of a repository between the repository contents, their metadata and the Porch cache. Like a SubjectAccessReview, it can only be created: the check runs when it is created, and the created object is returned with the findings in its status, but not stored.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.UpstreamPackage"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.RepositoryConsistencyCheckStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_ParentReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheckSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ParentReference is a reference to a parent package",
+				Description: "RepositoryConsistencyCheckSpec defines the check to run.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"repositoryName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name is the name of the parent PackageRevision",
+							Description: "RepositoryName is the name of the Repository to check, in the namespace of the check.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"repair": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Repair applies the safe subset of fixes: the cache is refreshed from the repository, missing metadata is recreated, and orphaned metadata and stored system labels are removed. The findings the fixes resolved are marked as repaired.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
-				Required: []string{"name"},
+				Required: []string{"repositoryName"},
 			},
 		},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_PatchSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_RepositoryConsistencyCheckStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "RepositoryConsistencyCheckStatus is the result of the check.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"file": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"contents": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
-						},
-					},
-					"patchType": {
+					"findings": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Findings are the inconsistencies found.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ConsistencyFinding"),
+									},
+								},
+							},
 						},
 					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.ConsistencyFinding"},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_ReadinessGate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_RepositoryRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "RepositoryRef identifies a reference to a Repository resource.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"conditionType": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Name of the Repository resource referenced.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"name"},
 			},
 		},
 	}
 }
 
-func schema_porch_api_porch_v1alpha1_RepositoryRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_porch_api_porch_v1alpha1_SecretKeyRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RepositoryRef identifies a reference to a Repository resource.",
+				Description: "SecretKeyRef refers to a key of a Secret.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the Repository resource referenced.",
+							Description: "Name of the secret. The secret is expected to be located in the same namespace as the resource containing the reference.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of the secret value.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"name"},
+				Required: []string{"name", "key"},
 			},
 		},
 	}
@@ -1435,6 +2906,35 @@ func schema_porch_api_porch_v1alpha1_Selector(ref common.ReferenceCallback) comm
 	}
 }
 
+func schema_porch_api_porch_v1alpha1_StagedChunk(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StagedChunk is a content-addressed chunk of a file of the package.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digest is the SHA-256 digest of the data of the chunk, in the form \"sha256:<hex>\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"data": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Data is the data of the chunk.",
+							Type:        []string{"string"},
+							Format:      "byte",
+						},
+					},
+				},
+				Required: []string{"digest"},
+			},
+		},
+	}
+}
+
 func schema_porch_api_porch_v1alpha1_Task(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1478,12 +2978,217 @@ func schema_porch_api_porch_v1alpha1_Task(ref common.ReferenceCallback) common.O
 							Ref: ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageUpdateTaskSpec"),
 						},
 					},
+					"render": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRenderTaskSpec"),
+						},
+					},
 				},
 				Required: []string{"type"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionEvalTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageCloneTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageEditTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageInitTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackagePatchTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageUpdateTaskSpec"},
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionEvalTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageCloneTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageEditTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageInitTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackagePatchTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageRenderTaskSpec", "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.PackageUpdateTaskSpec"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_TaskLog(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TaskLog is the log of the functions run by a task.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"task": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Task is the index of the task among the tasks applied by the last create or update of the package revision; the render which follows the tasks has the next index.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"functions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Functions are the logs of the functions of the task, in the order they ran.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionLog"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"task"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionLog"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_TaskResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TaskResult is the result of a task applied to a packagerevision.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"task": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Task` is the index of the task among the tasks applied by the create or update; the render which follows the tasks has the next index.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Type` is the type of the task.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Duration` is how long the task took to apply.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"startedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`StartedAt` is when the task started to apply. It is unset for tasks applied before Porch recorded it.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"added": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Added` are the paths of the files the task added.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"changed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Changed` are the paths of the files the task changed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"deleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Deleted` are the paths of the files the task deleted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "`Results` are the results the functions run by an eval or render task reported.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionResult"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"task", "type", "duration"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1.FunctionResult", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_porch_api_porch_v1alpha1_TimelineEvent(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TimelineEvent is a single entry in the timeline of a package.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time is when the event happened.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the type of the event.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the name of the package revision of the event.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"actor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Actor is the user who caused the event, if it is known.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration is how long the task of a TaskApplied event took to apply, if it is known.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describes the event.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"time", "type", "revision", "message"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
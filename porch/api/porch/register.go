@@ -46,6 +46,13 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PackageRevisionResourcesList{},
 		&Function{},
 		&FunctionList{},
+		&RepositoryConsistencyCheck{},
+		&PackageRevisionLogs{},
+		&PackageTimeline{},
+		&PackageMove{},
+		&BulkApproval{},
+		&PackageRevisionResourcesStaging{},
+		&PackageRevisionResourcesFinalize{},
 	)
 	return nil
 }
@@ -0,0 +1,75 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BulkApproval approves several proposed package revisions at once. The preconditions
+// of all package revisions are checked before any of them is published, and the package
+// revisions which meet them are published concurrently; a package revision which can't
+// be approved doesn't stop the others. Like a PackageMove, it can only be created: the
+// approval runs when it is created, and the created object is returned with the outcome
+// for every package revision in its status, but not stored.
+// +k8s:openapi-gen=true
+type BulkApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BulkApprovalSpec   `json:"spec,omitempty"`
+	Status BulkApprovalStatus `json:"status,omitempty"`
+}
+
+// BulkApprovalSpec defines the package revisions to approve.
+type BulkApprovalSpec struct {
+	// PackageRevisions are the names of the proposed package revisions to approve, in the
+	// namespace of the approval.
+	PackageRevisions []string `json:"packageRevisions"`
+}
+
+// BulkApprovalStatus is the outcome of the approval.
+type BulkApprovalStatus struct {
+	// Results are the outcomes for the package revisions, in the order of
+	// spec.packageRevisions.
+	Results []BulkApprovalResult `json:"results,omitempty"`
+}
+
+type BulkApprovalOutcome string
+
+const (
+	// BulkApprovalOutcomePublished is a package revision which was published.
+	BulkApprovalOutcomePublished BulkApprovalOutcome = "Published"
+	// BulkApprovalOutcomeRejected is a package revision which doesn't meet the
+	// preconditions of an approval, and wasn't published.
+	BulkApprovalOutcomeRejected BulkApprovalOutcome = "Rejected"
+	// BulkApprovalOutcomeFailed is a package revision whose publication failed.
+	BulkApprovalOutcomeFailed BulkApprovalOutcome = "Failed"
+)
+
+// BulkApprovalResult is the outcome of approving one package revision.
+type BulkApprovalResult struct {
+	// Name is the name of the package revision.
+	Name string `json:"name"`
+	// Outcome is the outcome of the approval of the package revision.
+	Outcome BulkApprovalOutcome `json:"outcome"`
+	// Revision is the revision of the published package revision.
+	Revision string `json:"revision,omitempty"`
+	// Reason is the reason of the error of a package revision which wasn't published, as
+	// in the status of a failed request.
+	Reason metav1.StatusReason `json:"reason,omitempty"`
+	// Message explains the error of a package revision which wasn't published.
+	Message string `json:"message,omitempty"`
+}
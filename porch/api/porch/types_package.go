@@ -47,6 +47,32 @@ type PackageSpec struct {
 
 	// RepositoryName is the name of the Repository object containing this package.
 	RepositoryName string `json:"repository,omitempty"`
+
+	// Description is a human readable description of the package. Like the labels and
+	// annotations of the package, it is kept by Porch rather than in the repository.
+	Description string `json:"description,omitempty"`
+
+	// Archived packages and their revisions are hidden from lists unless selected
+	// with a field selector, and no new revisions can be created. Their contents are
+	// preserved; clearing Archived restores the package.
+	Archived bool `json:"archived,omitempty"`
+
+	// Freeze, if set, freezes the package: no revisions of the package can be created,
+	// proposed, published or deleted until it is cleared. It can only be changed
+	// through the freeze subresource.
+	Freeze *PackageFreeze `json:"freeze,omitempty"`
+}
+
+// PackageFreeze is the freeze of a package, for example during incident response.
+type PackageFreeze struct {
+	// Reason is why the package is frozen.
+	Reason string `json:"reason"`
+
+	// FrozenBy is the user who froze the package. It is set by Porch.
+	FrozenBy string `json:"frozenBy,omitempty"`
+
+	// FrozenAt is when the package was frozen. It is set by Porch.
+	FrozenAt metav1.Time `json:"frozenAt,omitempty"`
 }
 
 // PackageStatus defines the observed state of Package
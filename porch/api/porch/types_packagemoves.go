@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PackageMove moves a package to another repository. The published revisions of the
+// package are republished in the destination repository as clones of the originals, the
+// source package is archived and frozen with a forwarding annotation, and the drafts
+// based on the package can be updated to the moved revisions. Like a
+// RepositoryConsistencyCheck, it can only be created: the move runs when it is created,
+// and the created object is returned with the outcome in its status, but not stored.
+// Every step of a move is skipped if it was already done, so a move which failed part
+// way is resumed by creating it again.
+// +k8s:openapi-gen=true
+type PackageMove struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackageMoveSpec   `json:"spec,omitempty"`
+	Status PackageMoveStatus `json:"status,omitempty"`
+}
+
+// PackageMoveSpec defines the move to run.
+type PackageMoveSpec struct {
+	// PackageName is the name of the package to move.
+	PackageName string `json:"packageName"`
+
+	// SourceRepository is the name of the Repository the package is moved from, in the
+	// namespace of the move.
+	SourceRepository string `json:"sourceRepository"`
+
+	// DestinationRepository is the name of the Repository the package is moved to, in the
+	// namespace of the move.
+	DestinationRepository string `json:"destinationRepository"`
+
+	// IncludeHistory moves all published revisions of the package instead of the latest.
+	IncludeHistory bool `json:"includeHistory,omitempty"`
+
+	// UpdateDownstreams updates the drafts based on the moved revisions to the moved
+	// revisions in the destination repository.
+	UpdateDownstreams bool `json:"updateDownstreams,omitempty"`
+
+	// DryRun reports the steps of the move without running them.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// PackageMoveStatus is the outcome of the move.
+type PackageMoveStatus struct {
+	// Revisions are the moved package revisions, oldest revision first.
+	Revisions []MovedPackageRevision `json:"revisions,omitempty"`
+
+	// Downstreams are the package revisions based on the moved package revisions.
+	Downstreams []MovedDownstream `json:"downstreams,omitempty"`
+
+	// Source is the outcome of archiving and freezing the source package, which is done
+	// once all revisions are moved.
+	Source PackageMoveOutcome `json:"source,omitempty"`
+
+	// SourceMessage explains the outcome of marking the source package.
+	SourceMessage string `json:"sourceMessage,omitempty"`
+}
+
+type PackageMoveOutcome string
+
+const (
+	// PackageMoveOutcomePending is a step a dry run would run.
+	PackageMoveOutcomePending PackageMoveOutcome = "Pending"
+	// PackageMoveOutcomeDone is a step the move ran.
+	PackageMoveOutcomeDone PackageMoveOutcome = "Done"
+	// PackageMoveOutcomeAlreadyDone is a step an earlier move ran.
+	PackageMoveOutcomeAlreadyDone PackageMoveOutcome = "AlreadyDone"
+	// PackageMoveOutcomeSkipped is a step the move didn't run.
+	PackageMoveOutcomeSkipped PackageMoveOutcome = "Skipped"
+	// PackageMoveOutcomeFailed is a step which failed.
+	PackageMoveOutcomeFailed PackageMoveOutcome = "Failed"
+)
+
+// MovedPackageRevision is the outcome of moving one package revision.
+type MovedPackageRevision struct {
+	// Revision is the revision of the package revision, kept by the move.
+	Revision string `json:"revision"`
+	// Source is the name of the package revision in the source repository.
+	Source string `json:"source"`
+	// Destination is the name of the package revision in the destination repository.
+	Destination string `json:"destination,omitempty"`
+	// Outcome is the outcome of the move of the package revision.
+	Outcome PackageMoveOutcome `json:"outcome"`
+	// Message explains the outcome.
+	Message string `json:"message,omitempty"`
+}
+
+// MovedDownstream is the outcome of updating a package revision based on a moved
+// package revision.
+type MovedDownstream struct {
+	// Name is the name of the downstream package revision.
+	Name string `json:"name"`
+	// Upstream is the name of the moved package revision the downstream is based on.
+	Upstream string `json:"upstream"`
+	// NewUpstream is the name of the package revision in the destination repository the
+	// downstream is updated to.
+	NewUpstream string `json:"newUpstream,omitempty"`
+	// Outcome is the outcome of the update.
+	Outcome PackageMoveOutcome `json:"outcome"`
+	// Message explains the outcome.
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PackageRevisionLogs are the logs the functions of a package revision wrote to stderr
+// while its tasks ran on the Porch server, for debugging functions which misbehave. It is
+// the logs subresource of a PackageRevision. The logs are kept in memory for a limited
+// time after the tasks ran, and are lost when the server restarts.
+// +k8s:openapi-gen=true
+type PackageRevisionLogs struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Tasks are the logs of the tasks whose functions wrote logs, ordered by task index.
+	Tasks []TaskLog `json:"tasks,omitempty"`
+}
+
+// TaskLog is the log of the functions run by a task.
+type TaskLog struct {
+	// Task is the index of the task among the tasks applied by the last create or update
+	// of the package revision; the render which follows the tasks has the next index.
+	Task int `json:"task"`
+	// Functions are the logs of the functions of the task, in the order they ran.
+	Functions []FunctionLog `json:"functions,omitempty"`
+}
+
+// FunctionLog is the log a function wrote to stderr. If the function failed, it is the
+// error the function runtime reported. Secret values injected into the function config
+// are redacted.
+type FunctionLog struct {
+	// Image is the image of the function.
+	Image string `json:"image"`
+	// Log is the end of the log of the function.
+	Log string `json:"log,omitempty"`
+	// Truncated is set if the beginning of the log was dropped to bound its size.
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -53,4 +53,20 @@ type PackageRevisionResourcesSpec struct {
 
 	// Resources are the content of the package.
 	Resources map[string]string `json:"resources,omitempty"`
+
+	// FileModes are the modes of the files of the package which aren't regular files,
+	// such as executable scripts, by the path of the file. Files which aren't listed are
+	// regular files. When the resources are updated, the listed modes are set, and the
+	// files which aren't listed keep their modes.
+	FileModes map[string]FileMode `json:"fileModes,omitempty"`
 }
+
+// FileMode is the mode of a file of a package.
+type FileMode string
+
+const (
+	// FileModeRegular is the mode of regular files.
+	FileModeRegular FileMode = "0644"
+	// FileModeExecutable is the mode of executable files.
+	FileModeExecutable FileMode = "0755"
+)
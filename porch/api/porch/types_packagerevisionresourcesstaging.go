@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PackageRevisionResourcesStaging stages chunks of new resources of a draft package
+// revision, for a resumable upload. It is the staging subresource of a
+// PackageRevisionResources, and can only be created: the chunks are staged when it is
+// created, and the created object is returned with the chunks the server is missing in
+// its status. Staged chunks are kept on disk, so an interrupted upload is resumed after
+// the server restarts by staging only the missing chunks, until the upload is finalized
+// or abandoned for longer than the staging TTL of the server.
+// +k8s:openapi-gen=true
+type PackageRevisionResourcesStaging struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackageRevisionResourcesStagingSpec   `json:"spec,omitempty"`
+	Status PackageRevisionResourcesStagingStatus `json:"status,omitempty"`
+}
+
+// PackageRevisionResourcesStagingSpec defines the chunks to stage.
+type PackageRevisionResourcesStagingSpec struct {
+	// Chunks are the chunks to stage.
+	Chunks []StagedChunk `json:"chunks,omitempty"`
+
+	// Digests are the digests of chunks whose staging is checked once the chunks are
+	// staged; the digests which aren't staged are returned in the status.
+	Digests []string `json:"digests,omitempty"`
+}
+
+// StagedChunk is a content-addressed chunk of a file of the package.
+type StagedChunk struct {
+	// Digest is the SHA-256 digest of the data of the chunk, in the form "sha256:<hex>".
+	Digest string `json:"digest"`
+	// Data is the data of the chunk.
+	Data []byte `json:"data,omitempty"`
+}
+
+// PackageRevisionResourcesStagingStatus reports the chunks which aren't staged.
+type PackageRevisionResourcesStagingStatus struct {
+	// Missing are the digests of the spec which aren't staged.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PackageRevisionResourcesFinalize replaces the resources of a draft package revision
+// with the files of a manifest, assembled from the chunks staged through the staging
+// subresource. It is the finalize subresource of a PackageRevisionResources, and can
+// only be created; the updated PackageRevisionResources is returned. Finalizing fails
+// with a BadRequest error if any chunk of the manifest isn't staged, and discards the
+// staged chunks once it succeeds.
+// +k8s:openapi-gen=true
+type PackageRevisionResourcesFinalize struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PackageRevisionResourcesFinalizeSpec `json:"spec,omitempty"`
+}
+
+// PackageRevisionResourcesFinalizeSpec is the manifest of the new resources.
+type PackageRevisionResourcesFinalizeSpec struct {
+	// Files are the digests of the chunks making up each file of the package, in order.
+	Files map[string][]string `json:"files"`
+}
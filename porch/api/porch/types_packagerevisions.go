@@ -92,10 +92,134 @@ type PackageRevisionStatus struct {
 	// PublishedAt is the time when the packagerevision were approved.
 	PublishedAt metav1.Time `json:"publishTimestamp,omitempty"`
 
+	// DraftCreatedAt is the time when the packagerevision was created as a draft.
+	DraftCreatedAt metav1.Time `json:"draftCreatedAt,omitempty"`
+
+	// ProposedAt is the time when the packagerevision was last proposed.
+	ProposedAt metav1.Time `json:"proposedAt,omitempty"`
+
+	// ProposedBy is the identity of the user who last proposed the packagerevision.
+	ProposedBy string `json:"proposedBy,omitempty"`
+
 	// Deployment is true if this is a deployment package (in a deployment repository).
 	Deployment bool `json:"deployment,omitempty"`
 
 	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Artifacts are the artifacts exported from the packagerevision when it was published.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// Archived is true if the package of the packagerevision is archived.
+	Archived bool `json:"archived,omitempty"`
+
+	// SharedFrom is the namespace of the shared repository of the packagerevision, if it is
+	// listed in another namespace the repository is shared with.
+	SharedFrom string `json:"sharedFrom,omitempty"`
+
+	// Timestamps are the raw timestamps the times of the packagerevision are derived
+	// from, for debugging clock skew between the repository and the API server.
+	Timestamps *PackageRevisionTimestamps `json:"timestamps,omitempty"`
+
+	// DeploymentStatus is the state of the packagerevision observed where it is deployed,
+	// as last reported through the deployment subresource by the agent syncing it, for
+	// example Config Sync or Flux. It doesn't affect the lifecycle of the packagerevision.
+	DeploymentStatus *DeploymentStatus `json:"deploymentStatus,omitempty"`
+
+	// TaskResults are the results of the tasks applied by the last create or update of
+	// the packagerevision, in the order they were applied.
+	TaskResults []TaskResult `json:"taskResults,omitempty"`
+}
+
+// TaskResult is the result of a task applied to a packagerevision.
+type TaskResult struct {
+	// `Task` is the index of the task among the tasks applied by the create or update; the
+	// render which follows the tasks has the next index.
+	Task int `json:"task"`
+	// `Type` is the type of the task.
+	Type TaskType `json:"type"`
+	// `Duration` is how long the task took to apply.
+	Duration metav1.Duration `json:"duration"`
+	// `StartedAt` is when the task started to apply. It is unset for tasks applied
+	// before Porch recorded it.
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// `Added` are the paths of the files the task added.
+	Added []string `json:"added,omitempty"`
+	// `Changed` are the paths of the files the task changed.
+	Changed []string `json:"changed,omitempty"`
+	// `Deleted` are the paths of the files the task deleted.
+	Deleted []string `json:"deleted,omitempty"`
+	// `Results` are the results the functions run by an eval or render task reported.
+	Results []FunctionResult `json:"results,omitempty"`
+}
+
+// SyncStatus is whether a deployed packagerevision is in sync with the cluster.
+type SyncStatus string
+
+const (
+	// SyncStatusSynced means the packagerevision is applied to the cluster.
+	SyncStatusSynced SyncStatus = "Synced"
+	// SyncStatusOutOfSync means the cluster differs from the packagerevision.
+	SyncStatusOutOfSync SyncStatus = "OutOfSync"
+	// SyncStatusError means the agent failed to apply the packagerevision.
+	SyncStatusError SyncStatus = "Error"
+	// SyncStatusUnknown means the agent doesn't know; reports which are stale are also
+	// displayed as unknown.
+	SyncStatusUnknown SyncStatus = "Unknown"
+)
+
+// DeploymentStatus is the state of a packagerevision observed in the cluster it is
+// deployed to.
+type DeploymentStatus struct {
+	// Cluster identifies the cluster the packagerevision is deployed to.
+	Cluster string `json:"cluster"`
+
+	// ObservedCommit is the commit of the packagerevision the agent last applied.
+	ObservedCommit string `json:"observedCommit,omitempty"`
+
+	// SyncStatus is whether the packagerevision is in sync with the cluster.
+	SyncStatus SyncStatus `json:"syncStatus"`
+
+	// SyncedTime is when the agent last synced the packagerevision.
+	SyncedTime metav1.Time `json:"syncedTime,omitempty"`
+
+	// ReportedTime is when Porch received the report. It is set by Porch.
+	ReportedTime metav1.Time `json:"reportedTime,omitempty"`
+}
+
+// PackageRevisionTimestamps are the raw timestamps of a packagerevision: the time of its
+// commit in the repository, and the times Porch recorded with the clock of the API
+// server. The times reported by a packagerevision are derived from them: the commit time
+// for a published packagerevision and the recorded creation time for a draft or
+// proposed one. They may disagree if the clocks are skewed.
+type PackageRevisionTimestamps struct {
+	// CommitTime is the time of the last commit of the packagerevision in the repository.
+	CommitTime metav1.Time `json:"commitTime,omitempty"`
+
+	// RecordedCreatedAt is the time Porch recorded the creation of the packagerevision.
+	RecordedCreatedAt metav1.Time `json:"recordedCreatedAt,omitempty"`
+
+	// RecordedPublishedAt is the time Porch recorded the publication of the packagerevision.
+	RecordedPublishedAt metav1.Time `json:"recordedPublishedAt,omitempty"`
+}
+
+// ArtifactType is the format of an artifact exported from a packagerevision.
+type ArtifactType string
+
+const (
+	// ArtifactTypeFluxOCI is a Flux OCI artifact, consumable by a Flux OCIRepository.
+	ArtifactTypeFluxOCI ArtifactType = "flux-oci"
+)
+
+// Artifact is an artifact exported from a packagerevision on publish.
+type Artifact struct {
+	// Type is the format of the artifact.
+	Type ArtifactType `json:"type"`
+
+	// URL is the location of the artifact, for example oci://registry/repository:tag.
+	URL string `json:"url"`
+
+	// Digest is the digest of the artifact.
+	Digest string `json:"digest,omitempty"`
 }
 
 type TaskType string
@@ -107,6 +231,7 @@ const (
 	TaskTypeEdit   TaskType = "edit"
 	TaskTypeEval   TaskType = "eval"
 	TaskTypeUpdate TaskType = "update"
+	TaskTypeRender TaskType = "render"
 )
 
 type Task struct {
@@ -117,6 +242,7 @@ type Task struct {
 	Edit   *PackageEditTaskSpec   `json:"edit,omitempty"`
 	Eval   *FunctionEvalTaskSpec  `json:"eval,omitempty"`
 	Update *PackageUpdateTaskSpec `json:"update,omitempty"`
+	Render *PackageRenderTaskSpec `json:"render,omitempty"`
 }
 
 // PackageInitTaskSpec defines the package initialization task.
@@ -129,6 +255,11 @@ type PackageInitTaskSpec struct {
 	Keywords []string `json:"keywords,omitempty"`
 	// `Site is a link to page with information about the package.
 	Site string `json:"site,omitempty"`
+	// `License` is the SPDX license identifier of the package, for example Apache-2.0.
+	License string `json:"license,omitempty"`
+	// `Starters` are the names of starter resources to add to the package, from the set
+	// configured in Porch, for example namespace and resourcequota.
+	Starters []string `json:"starters,omitempty"`
 }
 
 type PackageCloneTaskSpec struct {
@@ -146,6 +277,11 @@ type PackageCloneTaskSpec struct {
 	//  * force-delete-replace: Wipe all the local changes to the package and replace
 	//    it with the remote version.
 	Strategy PackageMergeStrategy `json:"strategy,omitempty"`
+
+	// `ResolvedUpstreamRef` is set by the server when `Upstream.UpstreamRef` refers to a package rather than
+	// to a specific PackageRevision. It records the PackageRevision that was cloned so that the package is
+	// recloned from the same revision as long as `Upstream` does not change.
+	ResolvedUpstreamRef *PackageRevisionRef `json:"resolvedUpstreamRef,omitempty"`
 }
 
 type PackageMergeStrategy string
@@ -155,6 +291,53 @@ type PackageUpdateTaskSpec struct {
 	Upstream UpstreamPackage `json:"upstreamRef,omitempty"`
 }
 
+// PackageRenderTaskSpec defines the render of the package, which runs the functions of
+// the pipelines of its Kptfiles.
+type PackageRenderTaskSpec struct {
+	// `RecordChanges`, if enabled, records which function changed which resources in
+	// the `Changes` of the render task.
+	RecordChanges bool `json:"recordChanges,omitempty"`
+	// `AppendedFunctions` are the images of the functions which were appended to the
+	// pipeline of the package by the porch.kpt.dev/pipeline-append annotation when it
+	// was rendered. It is set by Porch.
+	AppendedFunctions []string `json:"appendedFunctions,omitempty"`
+	// `Results` are the results, such as warnings and validation failures, which the
+	// functions reported when the package was rendered. It is set by Porch.
+	Results []FunctionResult `json:"results,omitempty"`
+	// `Changes` are the changes which the functions made to the resources, in pipeline
+	// order, if `RecordChanges` is enabled or the server records the changes of every
+	// render. It is set by Porch.
+	Changes []RenderChange `json:"changes,omitempty"`
+}
+
+// FunctionResult is a result which a function reported when the package was rendered.
+type FunctionResult struct {
+	// `Function` is the image (or exec path) of the function which reported the result.
+	Function string `json:"function,omitempty"`
+	// `Message` is the message of the result.
+	Message string `json:"message,omitempty"`
+	// `Severity` is the severity of the result: error, warning or info.
+	Severity string `json:"severity,omitempty"`
+	// `Resource` identifies the resource the result refers to, if any, as
+	// apiVersion/kind/namespace/name.
+	Resource string `json:"resource,omitempty"`
+	// `Field` is the path of the field the result refers to, if any.
+	Field string `json:"field,omitempty"`
+	// `File` is the path of the file the result refers to, if any.
+	File string `json:"file,omitempty"`
+}
+
+// RenderChange is a change which a function made to a resource when the package was
+// rendered.
+type RenderChange struct {
+	// `Function` is the image (or exec path) of the function which made the change.
+	Function string `json:"function,omitempty"`
+	// `Resource` identifies the resource as apiVersion/kind/namespace/name.
+	Resource string `json:"resource"`
+	// `Type` is the type of the change: Created, Modified or Deleted.
+	Type string `json:"type"`
+}
+
 const (
 	ResourceMerge      PackageMergeStrategy = "resource-merge"
 	FastForward        PackageMergeStrategy = "fast-forward"
@@ -226,6 +409,14 @@ type SecretRef struct {
 	Name string `json:"name"`
 }
 
+// SecretKeyRef refers to a key of a Secret.
+type SecretKeyRef struct {
+	// Name of the secret. The secret is expected to be located in the same namespace as the resource containing the reference.
+	Name string `json:"name"`
+	// Key of the secret value.
+	Key string `json:"key"`
+}
+
 // OciPackage describes a repository compatible with the Open Coutainer Registry standard.
 type OciPackage struct {
 	// Image is the address of an OCI image.
@@ -236,6 +427,20 @@ type OciPackage struct {
 type PackageRevisionRef struct {
 	// `Name` is the name of the referenced PackageRevision resource.
 	Name string `json:"name"`
+
+	// `Repository` is the name of the Repository containing the referenced package. Used together with
+	// `Package` when `Name` is not set.
+	Repository string `json:"repository,omitempty"`
+
+	// `Package` is the name of the referenced package. Used together with `Repository` when `Name` is not set.
+	Package string `json:"package,omitempty"`
+
+	// `Revision` of the referenced package. If empty or `latest`, the latest published revision is referenced.
+	Revision string `json:"revision,omitempty"`
+
+	// `Namespace` of the Repository containing the referenced package, if it is not the namespace of the
+	// referencing package. The Repository must be shared with the namespace of the referencing package.
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // RepositoryRef identifies a reference to a Repository resource.
@@ -257,6 +462,12 @@ type FunctionEvalTaskSpec struct {
 	// `Config` specifies the function config, arbitrary KRM resource. Mutually exclusive with ConfigMap.
 	Config runtime.RawExtension `json:"config,omitempty"`
 
+	// `ConfigSecretRefs` maps function config keys to keys of Secrets in the namespace of the package revision.
+	// The secret values are set in the function config when the function is evaluated, and are never stored in
+	// the package revision. With `ConfigMap`, the config key is a key of its data; with `Config`, it is a
+	// dot-separated field path.
+	ConfigSecretRefs map[string]SecretKeyRef `json:"configSecretRefs,omitempty"`
+
 	// If enabled, meta resources (i.e. `Kptfile` and `functionConfig`) are included
 	// in the input to the function. By default it is disabled.
 	IncludeMetaResources bool `json:"includeMetaResources,omitempty"`
@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RepositoryConsistencyCheck audits the consistency of the package revisions of a
+// repository between the repository contents, their metadata and the Porch cache.
+// Like a SubjectAccessReview, it can only be created: the check runs when it is created,
+// and the created object is returned with the findings in its status, but not stored.
+// +k8s:openapi-gen=true
+type RepositoryConsistencyCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositoryConsistencyCheckSpec   `json:"spec,omitempty"`
+	Status RepositoryConsistencyCheckStatus `json:"status,omitempty"`
+}
+
+// RepositoryConsistencyCheckSpec defines the check to run.
+type RepositoryConsistencyCheckSpec struct {
+	// RepositoryName is the name of the Repository to check, in the namespace of the check.
+	RepositoryName string `json:"repositoryName"`
+
+	// Repair applies the safe subset of fixes: the cache is refreshed from the repository,
+	// missing metadata is recreated, and orphaned metadata and stored system labels are
+	// removed. The findings the fixes resolved are marked as repaired.
+	Repair bool `json:"repair,omitempty"`
+}
+
+// RepositoryConsistencyCheckStatus is the result of the check.
+type RepositoryConsistencyCheckStatus struct {
+	// Findings are the inconsistencies found.
+	Findings []ConsistencyFinding `json:"findings,omitempty"`
+}
+
+type ConsistencyFindingType string
+
+const (
+	// ConsistencyFindingOrphanedMetadata is metadata of a package revision which isn't in
+	// the repository.
+	ConsistencyFindingOrphanedMetadata ConsistencyFindingType = "OrphanedMetadata"
+	// ConsistencyFindingUnindexedPackageRevision is a package revision of the repository
+	// without metadata.
+	ConsistencyFindingUnindexedPackageRevision ConsistencyFindingType = "UnindexedPackageRevision"
+	// ConsistencyFindingCacheMismatch is a package revision the cache disagrees with the
+	// repository on.
+	ConsistencyFindingCacheMismatch ConsistencyFindingType = "CacheMismatch"
+	// ConsistencyFindingLatestLabel is a package revision wrongly labelled, or not
+	// labelled, as the latest revision of its package.
+	ConsistencyFindingLatestLabel ConsistencyFindingType = "LatestLabel"
+)
+
+// ConsistencyFinding is an inconsistency found by a RepositoryConsistencyCheck.
+type ConsistencyFinding struct {
+	// Type is the class of the inconsistency.
+	Type ConsistencyFindingType `json:"type"`
+	// PackageRevision is the name of the package revision the inconsistency is about.
+	PackageRevision string `json:"packageRevision,omitempty"`
+	// Message describes the inconsistency.
+	Message string `json:"message"`
+	// Repaired is true if the inconsistency was repaired by the check.
+	Repaired bool `json:"repaired,omitempty"`
+}
@@ -32,6 +32,10 @@ var (
 	PackageRevisionGVR          = SchemeGroupVersion.WithResource("packagerevisions")
 	PackageRevisionResourcesGVR = SchemeGroupVersion.WithResource("packagerevisionresources")
 	FunctionGVR                 = SchemeGroupVersion.WithResource("functions")
+
+	RepositoryConsistencyCheckGVR = SchemeGroupVersion.WithResource("repositoryconsistencychecks")
+	PackageMoveGVR                = SchemeGroupVersion.WithResource("packagemoves")
+	BulkApprovalGVR               = SchemeGroupVersion.WithResource("bulkapprovals")
 )
 
 func init() {
@@ -51,6 +55,13 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PackageRevisionResourcesList{},
 		&Function{},
 		&FunctionList{},
+		&RepositoryConsistencyCheck{},
+		&PackageRevisionLogs{},
+		&PackageTimeline{},
+		&PackageMove{},
+		&BulkApproval{},
+		&PackageRevisionResourcesStaging{},
+		&PackageRevisionResourcesFinalize{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -0,0 +1,65 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// StagedChunkDigest returns the digest of the data of a StagedChunk.
+func StagedChunkDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// stagedChunkDigestPattern matches the digests returned by StagedChunkDigest.
+var stagedChunkDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// ValidateStagedChunkDigest returns an error if digest isn't in the form returned by
+// StagedChunkDigest.
+func ValidateStagedChunkDigest(digest string) error {
+	if !stagedChunkDigestPattern.MatchString(digest) {
+		return fmt.Errorf("invalid chunk digest %q; must be sha256:<64 lowercase hex digits>", digest)
+	}
+	return nil
+}
+
+// SplitResources splits the resources of a package into chunks of at most chunkSize
+// bytes, and returns the files of the PackageRevisionResourcesFinalize manifest and the
+// chunks by digest. Clients and the server split resources alike, so chunks staged by an
+// interrupted upload are recognized when it is resumed.
+func SplitResources(resources map[string]string, chunkSize int) (map[string][]string, map[string][]byte) {
+	files := map[string][]string{}
+	chunks := map[string][]byte{}
+	for path, contents := range resources {
+		data := []byte(contents)
+		digests := []string{}
+		for start := 0; start < len(data); start += chunkSize {
+			end := start + chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			chunk := data[start:end]
+			digest := StagedChunkDigest(chunk)
+			chunks[digest] = chunk
+			digests = append(digests, digest)
+		}
+		files[path] = digests
+	}
+	return files, chunks
+}
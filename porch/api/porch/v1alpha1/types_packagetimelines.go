@@ -0,0 +1,59 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PackageTimeline is the history of a package: the creation of its revisions, the tasks
+// applied to them, their lifecycle transitions and conditions, and their deletions. It is
+// the timeline subresource of a Package, and is computed from the task results and
+// lifecycle times Porch records in the metadata of the package revisions.
+// +k8s:openapi-gen=true
+type PackageTimeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Events are the events of the package in chronological order.
+	Events []TimelineEvent `json:"events,omitempty"`
+}
+
+// TimelineEventType is the type of an event in the timeline of a package.
+type TimelineEventType string
+
+const (
+	TimelineEventRevisionCreated  TimelineEventType = "RevisionCreated"
+	TimelineEventTaskApplied      TimelineEventType = "TaskApplied"
+	TimelineEventLifecycleChanged TimelineEventType = "LifecycleChanged"
+	TimelineEventCondition        TimelineEventType = "Condition"
+	TimelineEventRevisionDeleted  TimelineEventType = "RevisionDeleted"
+)
+
+// TimelineEvent is a single entry in the timeline of a package.
+type TimelineEvent struct {
+	// Time is when the event happened.
+	Time metav1.Time `json:"time"`
+	// Type is the type of the event.
+	Type TimelineEventType `json:"type"`
+	// Revision is the name of the package revision of the event.
+	Revision string `json:"revision"`
+	// Actor is the user who caused the event, if it is known.
+	Actor string `json:"actor,omitempty"`
+	// Duration is how long the task of a TaskApplied event took to apply, if it is known.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Message describes the event.
+	Message string `json:"message"`
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"strconv"
+	"strings"
+
+	sigsjson "sigs.k8s.io/json"
+)
+
+// UnknownTaskFieldsAnnotation is set when a PackageRevision is decoded from JSON to the
+// comma separated paths of the fields of spec.tasks which are not part of the API, and
+// are otherwise dropped silently. It is set by decoding only and never stored; the
+// Porch server rejects the fields if it validates tasks strictly.
+const UnknownTaskFieldsAnnotation = "internal.porch.kpt.dev/unknown-task-fields"
+
+// UnmarshalJSON decodes the PackageRevision like the API server does, recording the
+// unknown fields of its tasks in the UnknownTaskFieldsAnnotation. The typed object
+// no longer has the fields, so they can only be found while it is decoded.
+func (in *PackageRevision) UnmarshalJSON(data []byte) error {
+	type packageRevision PackageRevision
+	strictErrs, err := sigsjson.UnmarshalStrict(data, (*packageRevision)(in), sigsjson.DisallowUnknownFields)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for _, strictErr := range strictErrs {
+		path, err := strconv.Unquote(strings.TrimPrefix(strictErr.Error(), "unknown field "))
+		if err != nil || !strings.HasPrefix(path, "spec.tasks[") {
+			continue
+		}
+		unknown = append(unknown, path)
+	}
+
+	// The annotation can only be set by decoding.
+	delete(in.Annotations, UnknownTaskFieldsAnnotation)
+	if len(unknown) > 0 {
+		if in.Annotations == nil {
+			in.Annotations = map[string]string{}
+		}
+		in.Annotations[UnknownTaskFieldsAnnotation] = strings.Join(unknown, ",")
+	}
+	return nil
+}
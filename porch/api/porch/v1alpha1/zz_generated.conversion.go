@@ -23,6 +23,7 @@ import (
 	unsafe "unsafe"
 
 	porch "github.com/GoogleContainerTools/kpt/porch/api/porch"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -34,6 +35,56 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*Artifact)(nil), (*porch.Artifact)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Artifact_To_porch_Artifact(a.(*Artifact), b.(*porch.Artifact), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.Artifact)(nil), (*Artifact)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_Artifact_To_v1alpha1_Artifact(a.(*porch.Artifact), b.(*Artifact), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BulkApproval)(nil), (*porch.BulkApproval)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BulkApproval_To_porch_BulkApproval(a.(*BulkApproval), b.(*porch.BulkApproval), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.BulkApproval)(nil), (*BulkApproval)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_BulkApproval_To_v1alpha1_BulkApproval(a.(*porch.BulkApproval), b.(*BulkApproval), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BulkApprovalResult)(nil), (*porch.BulkApprovalResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BulkApprovalResult_To_porch_BulkApprovalResult(a.(*BulkApprovalResult), b.(*porch.BulkApprovalResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.BulkApprovalResult)(nil), (*BulkApprovalResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_BulkApprovalResult_To_v1alpha1_BulkApprovalResult(a.(*porch.BulkApprovalResult), b.(*BulkApprovalResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BulkApprovalSpec)(nil), (*porch.BulkApprovalSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec(a.(*BulkApprovalSpec), b.(*porch.BulkApprovalSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.BulkApprovalSpec)(nil), (*BulkApprovalSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec(a.(*porch.BulkApprovalSpec), b.(*BulkApprovalSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BulkApprovalStatus)(nil), (*porch.BulkApprovalStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus(a.(*BulkApprovalStatus), b.(*porch.BulkApprovalStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.BulkApprovalStatus)(nil), (*BulkApprovalStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus(a.(*porch.BulkApprovalStatus), b.(*BulkApprovalStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Condition)(nil), (*porch.Condition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Condition_To_porch_Condition(a.(*Condition), b.(*porch.Condition), scope)
 	}); err != nil {
@@ -44,6 +95,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ConsistencyFinding)(nil), (*porch.ConsistencyFinding)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ConsistencyFinding_To_porch_ConsistencyFinding(a.(*ConsistencyFinding), b.(*porch.ConsistencyFinding), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.ConsistencyFinding)(nil), (*ConsistencyFinding)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_ConsistencyFinding_To_v1alpha1_ConsistencyFinding(a.(*porch.ConsistencyFinding), b.(*ConsistencyFinding), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DeploymentStatus)(nil), (*porch.DeploymentStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DeploymentStatus_To_porch_DeploymentStatus(a.(*DeploymentStatus), b.(*porch.DeploymentStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.DeploymentStatus)(nil), (*DeploymentStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_DeploymentStatus_To_v1alpha1_DeploymentStatus(a.(*porch.DeploymentStatus), b.(*DeploymentStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Function)(nil), (*porch.Function)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Function_To_porch_Function(a.(*Function), b.(*porch.Function), scope)
 	}); err != nil {
@@ -84,6 +155,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*FunctionLog)(nil), (*porch.FunctionLog)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_FunctionLog_To_porch_FunctionLog(a.(*FunctionLog), b.(*porch.FunctionLog), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.FunctionLog)(nil), (*FunctionLog)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_FunctionLog_To_v1alpha1_FunctionLog(a.(*porch.FunctionLog), b.(*FunctionLog), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*FunctionRef)(nil), (*porch.FunctionRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_FunctionRef_To_porch_FunctionRef(a.(*FunctionRef), b.(*porch.FunctionRef), scope)
 	}); err != nil {
@@ -94,6 +175,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*FunctionResult)(nil), (*porch.FunctionResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_FunctionResult_To_porch_FunctionResult(a.(*FunctionResult), b.(*porch.FunctionResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.FunctionResult)(nil), (*FunctionResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_FunctionResult_To_v1alpha1_FunctionResult(a.(*porch.FunctionResult), b.(*FunctionResult), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*FunctionSpec)(nil), (*porch.FunctionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_FunctionSpec_To_porch_FunctionSpec(a.(*FunctionSpec), b.(*porch.FunctionSpec), scope)
 	}); err != nil {
@@ -134,6 +225,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*MovedDownstream)(nil), (*porch.MovedDownstream)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MovedDownstream_To_porch_MovedDownstream(a.(*MovedDownstream), b.(*porch.MovedDownstream), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.MovedDownstream)(nil), (*MovedDownstream)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_MovedDownstream_To_v1alpha1_MovedDownstream(a.(*porch.MovedDownstream), b.(*MovedDownstream), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*MovedPackageRevision)(nil), (*porch.MovedPackageRevision)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MovedPackageRevision_To_porch_MovedPackageRevision(a.(*MovedPackageRevision), b.(*porch.MovedPackageRevision), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.MovedPackageRevision)(nil), (*MovedPackageRevision)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_MovedPackageRevision_To_v1alpha1_MovedPackageRevision(a.(*porch.MovedPackageRevision), b.(*MovedPackageRevision), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*OciPackage)(nil), (*porch.OciPackage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_OciPackage_To_porch_OciPackage(a.(*OciPackage), b.(*porch.OciPackage), scope)
 	}); err != nil {
@@ -184,6 +295,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageFreeze)(nil), (*porch.PackageFreeze)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageFreeze_To_porch_PackageFreeze(a.(*PackageFreeze), b.(*porch.PackageFreeze), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageFreeze)(nil), (*PackageFreeze)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageFreeze_To_v1alpha1_PackageFreeze(a.(*porch.PackageFreeze), b.(*PackageFreeze), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageList)(nil), (*porch.PackageList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageList_To_porch_PackageList(a.(*PackageList), b.(*porch.PackageList), scope)
 	}); err != nil {
@@ -194,6 +315,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageMove)(nil), (*porch.PackageMove)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageMove_To_porch_PackageMove(a.(*PackageMove), b.(*porch.PackageMove), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageMove)(nil), (*PackageMove)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageMove_To_v1alpha1_PackageMove(a.(*porch.PackageMove), b.(*PackageMove), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PackageMoveSpec)(nil), (*porch.PackageMoveSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec(a.(*PackageMoveSpec), b.(*porch.PackageMoveSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageMoveSpec)(nil), (*PackageMoveSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec(a.(*porch.PackageMoveSpec), b.(*PackageMoveSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PackageMoveStatus)(nil), (*porch.PackageMoveStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus(a.(*PackageMoveStatus), b.(*porch.PackageMoveStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageMoveStatus)(nil), (*PackageMoveStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus(a.(*porch.PackageMoveStatus), b.(*PackageMoveStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackagePatchTaskSpec)(nil), (*porch.PackagePatchTaskSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackagePatchTaskSpec_To_porch_PackagePatchTaskSpec(a.(*PackagePatchTaskSpec), b.(*porch.PackagePatchTaskSpec), scope)
 	}); err != nil {
@@ -204,6 +355,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageRenderTaskSpec)(nil), (*porch.PackageRenderTaskSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRenderTaskSpec_To_porch_PackageRenderTaskSpec(a.(*PackageRenderTaskSpec), b.(*porch.PackageRenderTaskSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRenderTaskSpec)(nil), (*PackageRenderTaskSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRenderTaskSpec_To_v1alpha1_PackageRenderTaskSpec(a.(*porch.PackageRenderTaskSpec), b.(*PackageRenderTaskSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageRevision)(nil), (*porch.PackageRevision)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageRevision_To_porch_PackageRevision(a.(*PackageRevision), b.(*porch.PackageRevision), scope)
 	}); err != nil {
@@ -224,6 +385,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionLogs)(nil), (*porch.PackageRevisionLogs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionLogs_To_porch_PackageRevisionLogs(a.(*PackageRevisionLogs), b.(*porch.PackageRevisionLogs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionLogs)(nil), (*PackageRevisionLogs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionLogs_To_v1alpha1_PackageRevisionLogs(a.(*porch.PackageRevisionLogs), b.(*PackageRevisionLogs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageRevisionRef)(nil), (*porch.PackageRevisionRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageRevisionRef_To_porch_PackageRevisionRef(a.(*PackageRevisionRef), b.(*porch.PackageRevisionRef), scope)
 	}); err != nil {
@@ -244,6 +415,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesFinalize)(nil), (*porch.PackageRevisionResourcesFinalize)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionResourcesFinalize_To_porch_PackageRevisionResourcesFinalize(a.(*PackageRevisionResourcesFinalize), b.(*porch.PackageRevisionResourcesFinalize), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionResourcesFinalize)(nil), (*PackageRevisionResourcesFinalize)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionResourcesFinalize_To_v1alpha1_PackageRevisionResourcesFinalize(a.(*porch.PackageRevisionResourcesFinalize), b.(*PackageRevisionResourcesFinalize), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesFinalizeSpec)(nil), (*porch.PackageRevisionResourcesFinalizeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec(a.(*PackageRevisionResourcesFinalizeSpec), b.(*porch.PackageRevisionResourcesFinalizeSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionResourcesFinalizeSpec)(nil), (*PackageRevisionResourcesFinalizeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec(a.(*porch.PackageRevisionResourcesFinalizeSpec), b.(*PackageRevisionResourcesFinalizeSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesList)(nil), (*porch.PackageRevisionResourcesList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageRevisionResourcesList_To_porch_PackageRevisionResourcesList(a.(*PackageRevisionResourcesList), b.(*porch.PackageRevisionResourcesList), scope)
 	}); err != nil {
@@ -264,6 +455,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesStaging)(nil), (*porch.PackageRevisionResourcesStaging)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionResourcesStaging_To_porch_PackageRevisionResourcesStaging(a.(*PackageRevisionResourcesStaging), b.(*porch.PackageRevisionResourcesStaging), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionResourcesStaging)(nil), (*PackageRevisionResourcesStaging)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionResourcesStaging_To_v1alpha1_PackageRevisionResourcesStaging(a.(*porch.PackageRevisionResourcesStaging), b.(*PackageRevisionResourcesStaging), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesStagingSpec)(nil), (*porch.PackageRevisionResourcesStagingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec(a.(*PackageRevisionResourcesStagingSpec), b.(*porch.PackageRevisionResourcesStagingSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionResourcesStagingSpec)(nil), (*PackageRevisionResourcesStagingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec(a.(*porch.PackageRevisionResourcesStagingSpec), b.(*PackageRevisionResourcesStagingSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionResourcesStagingStatus)(nil), (*porch.PackageRevisionResourcesStagingStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus(a.(*PackageRevisionResourcesStagingStatus), b.(*porch.PackageRevisionResourcesStagingStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionResourcesStagingStatus)(nil), (*PackageRevisionResourcesStagingStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus(a.(*porch.PackageRevisionResourcesStagingStatus), b.(*PackageRevisionResourcesStagingStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageRevisionSpec)(nil), (*porch.PackageRevisionSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageRevisionSpec_To_porch_PackageRevisionSpec(a.(*PackageRevisionSpec), b.(*porch.PackageRevisionSpec), scope)
 	}); err != nil {
@@ -284,6 +505,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageRevisionTimestamps)(nil), (*porch.PackageRevisionTimestamps)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageRevisionTimestamps_To_porch_PackageRevisionTimestamps(a.(*PackageRevisionTimestamps), b.(*porch.PackageRevisionTimestamps), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageRevisionTimestamps)(nil), (*PackageRevisionTimestamps)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageRevisionTimestamps_To_v1alpha1_PackageRevisionTimestamps(a.(*porch.PackageRevisionTimestamps), b.(*PackageRevisionTimestamps), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageSpec)(nil), (*porch.PackageSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageSpec_To_porch_PackageSpec(a.(*PackageSpec), b.(*porch.PackageSpec), scope)
 	}); err != nil {
@@ -304,6 +535,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PackageTimeline)(nil), (*porch.PackageTimeline)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PackageTimeline_To_porch_PackageTimeline(a.(*PackageTimeline), b.(*porch.PackageTimeline), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.PackageTimeline)(nil), (*PackageTimeline)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_PackageTimeline_To_v1alpha1_PackageTimeline(a.(*porch.PackageTimeline), b.(*PackageTimeline), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PackageUpdateTaskSpec)(nil), (*porch.PackageUpdateTaskSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_PackageUpdateTaskSpec_To_porch_PackageUpdateTaskSpec(a.(*PackageUpdateTaskSpec), b.(*porch.PackageUpdateTaskSpec), scope)
 	}); err != nil {
@@ -344,6 +585,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RenderChange)(nil), (*porch.RenderChange)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RenderChange_To_porch_RenderChange(a.(*RenderChange), b.(*porch.RenderChange), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.RenderChange)(nil), (*RenderChange)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_RenderChange_To_v1alpha1_RenderChange(a.(*porch.RenderChange), b.(*RenderChange), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RepositoryConsistencyCheck)(nil), (*porch.RepositoryConsistencyCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RepositoryConsistencyCheck_To_porch_RepositoryConsistencyCheck(a.(*RepositoryConsistencyCheck), b.(*porch.RepositoryConsistencyCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.RepositoryConsistencyCheck)(nil), (*RepositoryConsistencyCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_RepositoryConsistencyCheck_To_v1alpha1_RepositoryConsistencyCheck(a.(*porch.RepositoryConsistencyCheck), b.(*RepositoryConsistencyCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RepositoryConsistencyCheckSpec)(nil), (*porch.RepositoryConsistencyCheckSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec(a.(*RepositoryConsistencyCheckSpec), b.(*porch.RepositoryConsistencyCheckSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.RepositoryConsistencyCheckSpec)(nil), (*RepositoryConsistencyCheckSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec(a.(*porch.RepositoryConsistencyCheckSpec), b.(*RepositoryConsistencyCheckSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RepositoryConsistencyCheckStatus)(nil), (*porch.RepositoryConsistencyCheckStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus(a.(*RepositoryConsistencyCheckStatus), b.(*porch.RepositoryConsistencyCheckStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.RepositoryConsistencyCheckStatus)(nil), (*RepositoryConsistencyCheckStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus(a.(*porch.RepositoryConsistencyCheckStatus), b.(*RepositoryConsistencyCheckStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RepositoryRef)(nil), (*porch.RepositoryRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_RepositoryRef_To_porch_RepositoryRef(a.(*RepositoryRef), b.(*porch.RepositoryRef), scope)
 	}); err != nil {
@@ -354,6 +635,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*SecretKeyRef)(nil), (*porch.SecretKeyRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_SecretKeyRef_To_porch_SecretKeyRef(a.(*SecretKeyRef), b.(*porch.SecretKeyRef), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.SecretKeyRef)(nil), (*SecretKeyRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_SecretKeyRef_To_v1alpha1_SecretKeyRef(a.(*porch.SecretKeyRef), b.(*SecretKeyRef), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*SecretRef)(nil), (*porch.SecretRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_SecretRef_To_porch_SecretRef(a.(*SecretRef), b.(*porch.SecretRef), scope)
 	}); err != nil {
@@ -374,6 +665,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*StagedChunk)(nil), (*porch.StagedChunk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StagedChunk_To_porch_StagedChunk(a.(*StagedChunk), b.(*porch.StagedChunk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.StagedChunk)(nil), (*StagedChunk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_StagedChunk_To_v1alpha1_StagedChunk(a.(*porch.StagedChunk), b.(*StagedChunk), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Task)(nil), (*porch.Task)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Task_To_porch_Task(a.(*Task), b.(*porch.Task), scope)
 	}); err != nil {
@@ -384,6 +685,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*TaskLog)(nil), (*porch.TaskLog)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TaskLog_To_porch_TaskLog(a.(*TaskLog), b.(*porch.TaskLog), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.TaskLog)(nil), (*TaskLog)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_TaskLog_To_v1alpha1_TaskLog(a.(*porch.TaskLog), b.(*TaskLog), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*TaskResult)(nil), (*porch.TaskResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TaskResult_To_porch_TaskResult(a.(*TaskResult), b.(*porch.TaskResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.TaskResult)(nil), (*TaskResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_TaskResult_To_v1alpha1_TaskResult(a.(*porch.TaskResult), b.(*TaskResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*TimelineEvent)(nil), (*porch.TimelineEvent)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_TimelineEvent_To_porch_TimelineEvent(a.(*TimelineEvent), b.(*porch.TimelineEvent), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*porch.TimelineEvent)(nil), (*TimelineEvent)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_porch_TimelineEvent_To_v1alpha1_TimelineEvent(a.(*porch.TimelineEvent), b.(*TimelineEvent), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*UpstreamLock)(nil), (*porch.UpstreamLock)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_UpstreamLock_To_porch_UpstreamLock(a.(*UpstreamLock), b.(*porch.UpstreamLock), scope)
 	}); err != nil {
@@ -407,6 +738,130 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha1_Artifact_To_porch_Artifact(in *Artifact, out *porch.Artifact, s conversion.Scope) error {
+	out.Type = porch.ArtifactType(in.Type)
+	out.URL = in.URL
+	out.Digest = in.Digest
+	return nil
+}
+
+// Convert_v1alpha1_Artifact_To_porch_Artifact is an autogenerated conversion function.
+func Convert_v1alpha1_Artifact_To_porch_Artifact(in *Artifact, out *porch.Artifact, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Artifact_To_porch_Artifact(in, out, s)
+}
+
+func autoConvert_porch_Artifact_To_v1alpha1_Artifact(in *porch.Artifact, out *Artifact, s conversion.Scope) error {
+	out.Type = ArtifactType(in.Type)
+	out.URL = in.URL
+	out.Digest = in.Digest
+	return nil
+}
+
+// Convert_porch_Artifact_To_v1alpha1_Artifact is an autogenerated conversion function.
+func Convert_porch_Artifact_To_v1alpha1_Artifact(in *porch.Artifact, out *Artifact, s conversion.Scope) error {
+	return autoConvert_porch_Artifact_To_v1alpha1_Artifact(in, out, s)
+}
+
+func autoConvert_v1alpha1_BulkApproval_To_porch_BulkApproval(in *BulkApproval, out *porch.BulkApproval, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_BulkApproval_To_porch_BulkApproval is an autogenerated conversion function.
+func Convert_v1alpha1_BulkApproval_To_porch_BulkApproval(in *BulkApproval, out *porch.BulkApproval, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BulkApproval_To_porch_BulkApproval(in, out, s)
+}
+
+func autoConvert_porch_BulkApproval_To_v1alpha1_BulkApproval(in *porch.BulkApproval, out *BulkApproval, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_porch_BulkApproval_To_v1alpha1_BulkApproval is an autogenerated conversion function.
+func Convert_porch_BulkApproval_To_v1alpha1_BulkApproval(in *porch.BulkApproval, out *BulkApproval, s conversion.Scope) error {
+	return autoConvert_porch_BulkApproval_To_v1alpha1_BulkApproval(in, out, s)
+}
+
+func autoConvert_v1alpha1_BulkApprovalResult_To_porch_BulkApprovalResult(in *BulkApprovalResult, out *porch.BulkApprovalResult, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Outcome = porch.BulkApprovalOutcome(in.Outcome)
+	out.Revision = in.Revision
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_BulkApprovalResult_To_porch_BulkApprovalResult is an autogenerated conversion function.
+func Convert_v1alpha1_BulkApprovalResult_To_porch_BulkApprovalResult(in *BulkApprovalResult, out *porch.BulkApprovalResult, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BulkApprovalResult_To_porch_BulkApprovalResult(in, out, s)
+}
+
+func autoConvert_porch_BulkApprovalResult_To_v1alpha1_BulkApprovalResult(in *porch.BulkApprovalResult, out *BulkApprovalResult, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Outcome = BulkApprovalOutcome(in.Outcome)
+	out.Revision = in.Revision
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_porch_BulkApprovalResult_To_v1alpha1_BulkApprovalResult is an autogenerated conversion function.
+func Convert_porch_BulkApprovalResult_To_v1alpha1_BulkApprovalResult(in *porch.BulkApprovalResult, out *BulkApprovalResult, s conversion.Scope) error {
+	return autoConvert_porch_BulkApprovalResult_To_v1alpha1_BulkApprovalResult(in, out, s)
+}
+
+func autoConvert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec(in *BulkApprovalSpec, out *porch.BulkApprovalSpec, s conversion.Scope) error {
+	out.PackageRevisions = *(*[]string)(unsafe.Pointer(&in.PackageRevisions))
+	return nil
+}
+
+// Convert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec is an autogenerated conversion function.
+func Convert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec(in *BulkApprovalSpec, out *porch.BulkApprovalSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BulkApprovalSpec_To_porch_BulkApprovalSpec(in, out, s)
+}
+
+func autoConvert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec(in *porch.BulkApprovalSpec, out *BulkApprovalSpec, s conversion.Scope) error {
+	out.PackageRevisions = *(*[]string)(unsafe.Pointer(&in.PackageRevisions))
+	return nil
+}
+
+// Convert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec is an autogenerated conversion function.
+func Convert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec(in *porch.BulkApprovalSpec, out *BulkApprovalSpec, s conversion.Scope) error {
+	return autoConvert_porch_BulkApprovalSpec_To_v1alpha1_BulkApprovalSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus(in *BulkApprovalStatus, out *porch.BulkApprovalStatus, s conversion.Scope) error {
+	out.Results = *(*[]porch.BulkApprovalResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus is an autogenerated conversion function.
+func Convert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus(in *BulkApprovalStatus, out *porch.BulkApprovalStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BulkApprovalStatus_To_porch_BulkApprovalStatus(in, out, s)
+}
+
+func autoConvert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus(in *porch.BulkApprovalStatus, out *BulkApprovalStatus, s conversion.Scope) error {
+	out.Results = *(*[]BulkApprovalResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus is an autogenerated conversion function.
+func Convert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus(in *porch.BulkApprovalStatus, out *BulkApprovalStatus, s conversion.Scope) error {
+	return autoConvert_porch_BulkApprovalStatus_To_v1alpha1_BulkApprovalStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_Condition_To_porch_Condition(in *Condition, out *porch.Condition, s conversion.Scope) error {
 	out.Type = in.Type
 	out.Status = porch.ConditionStatus(in.Status)
@@ -433,6 +888,60 @@ func Convert_porch_Condition_To_v1alpha1_Condition(in *porch.Condition, out *Con
 	return autoConvert_porch_Condition_To_v1alpha1_Condition(in, out, s)
 }
 
+func autoConvert_v1alpha1_ConsistencyFinding_To_porch_ConsistencyFinding(in *ConsistencyFinding, out *porch.ConsistencyFinding, s conversion.Scope) error {
+	out.Type = porch.ConsistencyFindingType(in.Type)
+	out.PackageRevision = in.PackageRevision
+	out.Message = in.Message
+	out.Repaired = in.Repaired
+	return nil
+}
+
+// Convert_v1alpha1_ConsistencyFinding_To_porch_ConsistencyFinding is an autogenerated conversion function.
+func Convert_v1alpha1_ConsistencyFinding_To_porch_ConsistencyFinding(in *ConsistencyFinding, out *porch.ConsistencyFinding, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ConsistencyFinding_To_porch_ConsistencyFinding(in, out, s)
+}
+
+func autoConvert_porch_ConsistencyFinding_To_v1alpha1_ConsistencyFinding(in *porch.ConsistencyFinding, out *ConsistencyFinding, s conversion.Scope) error {
+	out.Type = ConsistencyFindingType(in.Type)
+	out.PackageRevision = in.PackageRevision
+	out.Message = in.Message
+	out.Repaired = in.Repaired
+	return nil
+}
+
+// Convert_porch_ConsistencyFinding_To_v1alpha1_ConsistencyFinding is an autogenerated conversion function.
+func Convert_porch_ConsistencyFinding_To_v1alpha1_ConsistencyFinding(in *porch.ConsistencyFinding, out *ConsistencyFinding, s conversion.Scope) error {
+	return autoConvert_porch_ConsistencyFinding_To_v1alpha1_ConsistencyFinding(in, out, s)
+}
+
+func autoConvert_v1alpha1_DeploymentStatus_To_porch_DeploymentStatus(in *DeploymentStatus, out *porch.DeploymentStatus, s conversion.Scope) error {
+	out.Cluster = in.Cluster
+	out.ObservedCommit = in.ObservedCommit
+	out.SyncStatus = porch.SyncStatus(in.SyncStatus)
+	out.SyncedTime = in.SyncedTime
+	out.ReportedTime = in.ReportedTime
+	return nil
+}
+
+// Convert_v1alpha1_DeploymentStatus_To_porch_DeploymentStatus is an autogenerated conversion function.
+func Convert_v1alpha1_DeploymentStatus_To_porch_DeploymentStatus(in *DeploymentStatus, out *porch.DeploymentStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DeploymentStatus_To_porch_DeploymentStatus(in, out, s)
+}
+
+func autoConvert_porch_DeploymentStatus_To_v1alpha1_DeploymentStatus(in *porch.DeploymentStatus, out *DeploymentStatus, s conversion.Scope) error {
+	out.Cluster = in.Cluster
+	out.ObservedCommit = in.ObservedCommit
+	out.SyncStatus = SyncStatus(in.SyncStatus)
+	out.SyncedTime = in.SyncedTime
+	out.ReportedTime = in.ReportedTime
+	return nil
+}
+
+// Convert_porch_DeploymentStatus_To_v1alpha1_DeploymentStatus is an autogenerated conversion function.
+func Convert_porch_DeploymentStatus_To_v1alpha1_DeploymentStatus(in *porch.DeploymentStatus, out *DeploymentStatus, s conversion.Scope) error {
+	return autoConvert_porch_DeploymentStatus_To_v1alpha1_DeploymentStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_Function_To_porch_Function(in *Function, out *porch.Function, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_FunctionSpec_To_porch_FunctionSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -491,6 +1000,7 @@ func autoConvert_v1alpha1_FunctionEvalTaskSpec_To_porch_FunctionEvalTaskSpec(in
 	out.FunctionRef = (*porch.FunctionRef)(unsafe.Pointer(in.FunctionRef))
 	out.ConfigMap = *(*map[string]string)(unsafe.Pointer(&in.ConfigMap))
 	out.Config = in.Config
+	out.ConfigSecretRefs = *(*map[string]porch.SecretKeyRef)(unsafe.Pointer(&in.ConfigSecretRefs))
 	out.IncludeMetaResources = in.IncludeMetaResources
 	out.EnableNetwork = in.EnableNetwork
 	if err := Convert_v1alpha1_Selector_To_porch_Selector(&in.Match, &out.Match, s); err != nil {
@@ -510,6 +1020,7 @@ func autoConvert_porch_FunctionEvalTaskSpec_To_v1alpha1_FunctionEvalTaskSpec(in
 	out.FunctionRef = (*FunctionRef)(unsafe.Pointer(in.FunctionRef))
 	out.ConfigMap = *(*map[string]string)(unsafe.Pointer(&in.ConfigMap))
 	out.Config = in.Config
+	out.ConfigSecretRefs = *(*map[string]SecretKeyRef)(unsafe.Pointer(&in.ConfigSecretRefs))
 	out.IncludeMetaResources = in.IncludeMetaResources
 	out.EnableNetwork = in.EnableNetwork
 	if err := Convert_porch_Selector_To_v1alpha1_Selector(&in.Match, &out.Match, s); err != nil {
@@ -545,6 +1056,30 @@ func Convert_porch_FunctionList_To_v1alpha1_FunctionList(in *porch.FunctionList,
 	return autoConvert_porch_FunctionList_To_v1alpha1_FunctionList(in, out, s)
 }
 
+func autoConvert_v1alpha1_FunctionLog_To_porch_FunctionLog(in *FunctionLog, out *porch.FunctionLog, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Log = in.Log
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_v1alpha1_FunctionLog_To_porch_FunctionLog is an autogenerated conversion function.
+func Convert_v1alpha1_FunctionLog_To_porch_FunctionLog(in *FunctionLog, out *porch.FunctionLog, s conversion.Scope) error {
+	return autoConvert_v1alpha1_FunctionLog_To_porch_FunctionLog(in, out, s)
+}
+
+func autoConvert_porch_FunctionLog_To_v1alpha1_FunctionLog(in *porch.FunctionLog, out *FunctionLog, s conversion.Scope) error {
+	out.Image = in.Image
+	out.Log = in.Log
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_porch_FunctionLog_To_v1alpha1_FunctionLog is an autogenerated conversion function.
+func Convert_porch_FunctionLog_To_v1alpha1_FunctionLog(in *porch.FunctionLog, out *FunctionLog, s conversion.Scope) error {
+	return autoConvert_porch_FunctionLog_To_v1alpha1_FunctionLog(in, out, s)
+}
+
 func autoConvert_v1alpha1_FunctionRef_To_porch_FunctionRef(in *FunctionRef, out *porch.FunctionRef, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
@@ -565,6 +1100,36 @@ func Convert_porch_FunctionRef_To_v1alpha1_FunctionRef(in *porch.FunctionRef, ou
 	return autoConvert_porch_FunctionRef_To_v1alpha1_FunctionRef(in, out, s)
 }
 
+func autoConvert_v1alpha1_FunctionResult_To_porch_FunctionResult(in *FunctionResult, out *porch.FunctionResult, s conversion.Scope) error {
+	out.Function = in.Function
+	out.Message = in.Message
+	out.Severity = in.Severity
+	out.Resource = in.Resource
+	out.Field = in.Field
+	out.File = in.File
+	return nil
+}
+
+// Convert_v1alpha1_FunctionResult_To_porch_FunctionResult is an autogenerated conversion function.
+func Convert_v1alpha1_FunctionResult_To_porch_FunctionResult(in *FunctionResult, out *porch.FunctionResult, s conversion.Scope) error {
+	return autoConvert_v1alpha1_FunctionResult_To_porch_FunctionResult(in, out, s)
+}
+
+func autoConvert_porch_FunctionResult_To_v1alpha1_FunctionResult(in *porch.FunctionResult, out *FunctionResult, s conversion.Scope) error {
+	out.Function = in.Function
+	out.Message = in.Message
+	out.Severity = in.Severity
+	out.Resource = in.Resource
+	out.Field = in.Field
+	out.File = in.File
+	return nil
+}
+
+// Convert_porch_FunctionResult_To_v1alpha1_FunctionResult is an autogenerated conversion function.
+func Convert_porch_FunctionResult_To_v1alpha1_FunctionResult(in *porch.FunctionResult, out *FunctionResult, s conversion.Scope) error {
+	return autoConvert_porch_FunctionResult_To_v1alpha1_FunctionResult(in, out, s)
+}
+
 func autoConvert_v1alpha1_FunctionSpec_To_porch_FunctionSpec(in *FunctionSpec, out *porch.FunctionSpec, s conversion.Scope) error {
 	out.Image = in.Image
 	if err := Convert_v1alpha1_RepositoryRef_To_porch_RepositoryRef(&in.RepositoryRef, &out.RepositoryRef, s); err != nil {
@@ -675,6 +1240,62 @@ func Convert_porch_GitPackage_To_v1alpha1_GitPackage(in *porch.GitPackage, out *
 	return autoConvert_porch_GitPackage_To_v1alpha1_GitPackage(in, out, s)
 }
 
+func autoConvert_v1alpha1_MovedDownstream_To_porch_MovedDownstream(in *MovedDownstream, out *porch.MovedDownstream, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Upstream = in.Upstream
+	out.NewUpstream = in.NewUpstream
+	out.Outcome = porch.PackageMoveOutcome(in.Outcome)
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_MovedDownstream_To_porch_MovedDownstream is an autogenerated conversion function.
+func Convert_v1alpha1_MovedDownstream_To_porch_MovedDownstream(in *MovedDownstream, out *porch.MovedDownstream, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MovedDownstream_To_porch_MovedDownstream(in, out, s)
+}
+
+func autoConvert_porch_MovedDownstream_To_v1alpha1_MovedDownstream(in *porch.MovedDownstream, out *MovedDownstream, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Upstream = in.Upstream
+	out.NewUpstream = in.NewUpstream
+	out.Outcome = PackageMoveOutcome(in.Outcome)
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_porch_MovedDownstream_To_v1alpha1_MovedDownstream is an autogenerated conversion function.
+func Convert_porch_MovedDownstream_To_v1alpha1_MovedDownstream(in *porch.MovedDownstream, out *MovedDownstream, s conversion.Scope) error {
+	return autoConvert_porch_MovedDownstream_To_v1alpha1_MovedDownstream(in, out, s)
+}
+
+func autoConvert_v1alpha1_MovedPackageRevision_To_porch_MovedPackageRevision(in *MovedPackageRevision, out *porch.MovedPackageRevision, s conversion.Scope) error {
+	out.Revision = in.Revision
+	out.Source = in.Source
+	out.Destination = in.Destination
+	out.Outcome = porch.PackageMoveOutcome(in.Outcome)
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_MovedPackageRevision_To_porch_MovedPackageRevision is an autogenerated conversion function.
+func Convert_v1alpha1_MovedPackageRevision_To_porch_MovedPackageRevision(in *MovedPackageRevision, out *porch.MovedPackageRevision, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MovedPackageRevision_To_porch_MovedPackageRevision(in, out, s)
+}
+
+func autoConvert_porch_MovedPackageRevision_To_v1alpha1_MovedPackageRevision(in *porch.MovedPackageRevision, out *MovedPackageRevision, s conversion.Scope) error {
+	out.Revision = in.Revision
+	out.Source = in.Source
+	out.Destination = in.Destination
+	out.Outcome = PackageMoveOutcome(in.Outcome)
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_porch_MovedPackageRevision_To_v1alpha1_MovedPackageRevision is an autogenerated conversion function.
+func Convert_porch_MovedPackageRevision_To_v1alpha1_MovedPackageRevision(in *porch.MovedPackageRevision, out *MovedPackageRevision, s conversion.Scope) error {
+	return autoConvert_porch_MovedPackageRevision_To_v1alpha1_MovedPackageRevision(in, out, s)
+}
+
 func autoConvert_v1alpha1_OciPackage_To_porch_OciPackage(in *OciPackage, out *porch.OciPackage, s conversion.Scope) error {
 	out.Image = in.Image
 	return nil
@@ -732,6 +1353,7 @@ func autoConvert_v1alpha1_PackageCloneTaskSpec_To_porch_PackageCloneTaskSpec(in
 		return err
 	}
 	out.Strategy = porch.PackageMergeStrategy(in.Strategy)
+	out.ResolvedUpstreamRef = (*porch.PackageRevisionRef)(unsafe.Pointer(in.ResolvedUpstreamRef))
 	return nil
 }
 
@@ -745,6 +1367,7 @@ func autoConvert_porch_PackageCloneTaskSpec_To_v1alpha1_PackageCloneTaskSpec(in
 		return err
 	}
 	out.Strategy = PackageMergeStrategy(in.Strategy)
+	out.ResolvedUpstreamRef = (*PackageRevisionRef)(unsafe.Pointer(in.ResolvedUpstreamRef))
 	return nil
 }
 
@@ -778,47 +1401,163 @@ func autoConvert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec(in *P
 	out.Description = in.Description
 	out.Keywords = *(*[]string)(unsafe.Pointer(&in.Keywords))
 	out.Site = in.Site
+	out.License = in.License
+	out.Starters = *(*[]string)(unsafe.Pointer(&in.Starters))
+	return nil
+}
+
+// Convert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec is an autogenerated conversion function.
+func Convert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec(in *PackageInitTaskSpec, out *porch.PackageInitTaskSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec(in, out, s)
+}
+
+func autoConvert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in *porch.PackageInitTaskSpec, out *PackageInitTaskSpec, s conversion.Scope) error {
+	out.Subpackage = in.Subpackage
+	out.Description = in.Description
+	out.Keywords = *(*[]string)(unsafe.Pointer(&in.Keywords))
+	out.Site = in.Site
+	out.License = in.License
+	out.Starters = *(*[]string)(unsafe.Pointer(&in.Starters))
+	return nil
+}
+
+// Convert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec is an autogenerated conversion function.
+func Convert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in *porch.PackageInitTaskSpec, out *PackageInitTaskSpec, s conversion.Scope) error {
+	return autoConvert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageFreeze_To_porch_PackageFreeze(in *PackageFreeze, out *porch.PackageFreeze, s conversion.Scope) error {
+	out.Reason = in.Reason
+	out.FrozenBy = in.FrozenBy
+	out.FrozenAt = in.FrozenAt
+	return nil
+}
+
+// Convert_v1alpha1_PackageFreeze_To_porch_PackageFreeze is an autogenerated conversion function.
+func Convert_v1alpha1_PackageFreeze_To_porch_PackageFreeze(in *PackageFreeze, out *porch.PackageFreeze, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageFreeze_To_porch_PackageFreeze(in, out, s)
+}
+
+func autoConvert_porch_PackageFreeze_To_v1alpha1_PackageFreeze(in *porch.PackageFreeze, out *PackageFreeze, s conversion.Scope) error {
+	out.Reason = in.Reason
+	out.FrozenBy = in.FrozenBy
+	out.FrozenAt = in.FrozenAt
+	return nil
+}
+
+// Convert_porch_PackageFreeze_To_v1alpha1_PackageFreeze is an autogenerated conversion function.
+func Convert_porch_PackageFreeze_To_v1alpha1_PackageFreeze(in *porch.PackageFreeze, out *PackageFreeze, s conversion.Scope) error {
+	return autoConvert_porch_PackageFreeze_To_v1alpha1_PackageFreeze(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageList_To_porch_PackageList(in *PackageList, out *porch.PackageList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]porch.Package)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_PackageList_To_porch_PackageList is an autogenerated conversion function.
+func Convert_v1alpha1_PackageList_To_porch_PackageList(in *PackageList, out *porch.PackageList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageList_To_porch_PackageList(in, out, s)
+}
+
+func autoConvert_porch_PackageList_To_v1alpha1_PackageList(in *porch.PackageList, out *PackageList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]Package)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_porch_PackageList_To_v1alpha1_PackageList is an autogenerated conversion function.
+func Convert_porch_PackageList_To_v1alpha1_PackageList(in *porch.PackageList, out *PackageList, s conversion.Scope) error {
+	return autoConvert_porch_PackageList_To_v1alpha1_PackageList(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageMove_To_porch_PackageMove(in *PackageMove, out *porch.PackageMove, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_PackageMove_To_porch_PackageMove is an autogenerated conversion function.
+func Convert_v1alpha1_PackageMove_To_porch_PackageMove(in *PackageMove, out *porch.PackageMove, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageMove_To_porch_PackageMove(in, out, s)
+}
+
+func autoConvert_porch_PackageMove_To_v1alpha1_PackageMove(in *porch.PackageMove, out *PackageMove, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_porch_PackageMove_To_v1alpha1_PackageMove is an autogenerated conversion function.
+func Convert_porch_PackageMove_To_v1alpha1_PackageMove(in *porch.PackageMove, out *PackageMove, s conversion.Scope) error {
+	return autoConvert_porch_PackageMove_To_v1alpha1_PackageMove(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec(in *PackageMoveSpec, out *porch.PackageMoveSpec, s conversion.Scope) error {
+	out.PackageName = in.PackageName
+	out.SourceRepository = in.SourceRepository
+	out.DestinationRepository = in.DestinationRepository
+	out.IncludeHistory = in.IncludeHistory
+	out.UpdateDownstreams = in.UpdateDownstreams
+	out.DryRun = in.DryRun
 	return nil
 }
 
-// Convert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec is an autogenerated conversion function.
-func Convert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec(in *PackageInitTaskSpec, out *porch.PackageInitTaskSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha1_PackageInitTaskSpec_To_porch_PackageInitTaskSpec(in, out, s)
+// Convert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec is an autogenerated conversion function.
+func Convert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec(in *PackageMoveSpec, out *porch.PackageMoveSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageMoveSpec_To_porch_PackageMoveSpec(in, out, s)
 }
 
-func autoConvert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in *porch.PackageInitTaskSpec, out *PackageInitTaskSpec, s conversion.Scope) error {
-	out.Subpackage = in.Subpackage
-	out.Description = in.Description
-	out.Keywords = *(*[]string)(unsafe.Pointer(&in.Keywords))
-	out.Site = in.Site
+func autoConvert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec(in *porch.PackageMoveSpec, out *PackageMoveSpec, s conversion.Scope) error {
+	out.PackageName = in.PackageName
+	out.SourceRepository = in.SourceRepository
+	out.DestinationRepository = in.DestinationRepository
+	out.IncludeHistory = in.IncludeHistory
+	out.UpdateDownstreams = in.UpdateDownstreams
+	out.DryRun = in.DryRun
 	return nil
 }
 
-// Convert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec is an autogenerated conversion function.
-func Convert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in *porch.PackageInitTaskSpec, out *PackageInitTaskSpec, s conversion.Scope) error {
-	return autoConvert_porch_PackageInitTaskSpec_To_v1alpha1_PackageInitTaskSpec(in, out, s)
+// Convert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec is an autogenerated conversion function.
+func Convert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec(in *porch.PackageMoveSpec, out *PackageMoveSpec, s conversion.Scope) error {
+	return autoConvert_porch_PackageMoveSpec_To_v1alpha1_PackageMoveSpec(in, out, s)
 }
 
-func autoConvert_v1alpha1_PackageList_To_porch_PackageList(in *PackageList, out *porch.PackageList, s conversion.Scope) error {
-	out.ListMeta = in.ListMeta
-	out.Items = *(*[]porch.Package)(unsafe.Pointer(&in.Items))
+func autoConvert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus(in *PackageMoveStatus, out *porch.PackageMoveStatus, s conversion.Scope) error {
+	out.Revisions = *(*[]porch.MovedPackageRevision)(unsafe.Pointer(&in.Revisions))
+	out.Downstreams = *(*[]porch.MovedDownstream)(unsafe.Pointer(&in.Downstreams))
+	out.Source = porch.PackageMoveOutcome(in.Source)
+	out.SourceMessage = in.SourceMessage
 	return nil
 }
 
-// Convert_v1alpha1_PackageList_To_porch_PackageList is an autogenerated conversion function.
-func Convert_v1alpha1_PackageList_To_porch_PackageList(in *PackageList, out *porch.PackageList, s conversion.Scope) error {
-	return autoConvert_v1alpha1_PackageList_To_porch_PackageList(in, out, s)
+// Convert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus is an autogenerated conversion function.
+func Convert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus(in *PackageMoveStatus, out *porch.PackageMoveStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageMoveStatus_To_porch_PackageMoveStatus(in, out, s)
 }
 
-func autoConvert_porch_PackageList_To_v1alpha1_PackageList(in *porch.PackageList, out *PackageList, s conversion.Scope) error {
-	out.ListMeta = in.ListMeta
-	out.Items = *(*[]Package)(unsafe.Pointer(&in.Items))
+func autoConvert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus(in *porch.PackageMoveStatus, out *PackageMoveStatus, s conversion.Scope) error {
+	out.Revisions = *(*[]MovedPackageRevision)(unsafe.Pointer(&in.Revisions))
+	out.Downstreams = *(*[]MovedDownstream)(unsafe.Pointer(&in.Downstreams))
+	out.Source = PackageMoveOutcome(in.Source)
+	out.SourceMessage = in.SourceMessage
 	return nil
 }
 
-// Convert_porch_PackageList_To_v1alpha1_PackageList is an autogenerated conversion function.
-func Convert_porch_PackageList_To_v1alpha1_PackageList(in *porch.PackageList, out *PackageList, s conversion.Scope) error {
-	return autoConvert_porch_PackageList_To_v1alpha1_PackageList(in, out, s)
+// Convert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus is an autogenerated conversion function.
+func Convert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus(in *porch.PackageMoveStatus, out *PackageMoveStatus, s conversion.Scope) error {
+	return autoConvert_porch_PackageMoveStatus_To_v1alpha1_PackageMoveStatus(in, out, s)
 }
 
 func autoConvert_v1alpha1_PackagePatchTaskSpec_To_porch_PackagePatchTaskSpec(in *PackagePatchTaskSpec, out *porch.PackagePatchTaskSpec, s conversion.Scope) error {
@@ -841,6 +1580,32 @@ func Convert_porch_PackagePatchTaskSpec_To_v1alpha1_PackagePatchTaskSpec(in *por
 	return autoConvert_porch_PackagePatchTaskSpec_To_v1alpha1_PackagePatchTaskSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageRenderTaskSpec_To_porch_PackageRenderTaskSpec(in *PackageRenderTaskSpec, out *porch.PackageRenderTaskSpec, s conversion.Scope) error {
+	out.RecordChanges = in.RecordChanges
+	out.AppendedFunctions = *(*[]string)(unsafe.Pointer(&in.AppendedFunctions))
+	out.Results = *(*[]porch.FunctionResult)(unsafe.Pointer(&in.Results))
+	out.Changes = *(*[]porch.RenderChange)(unsafe.Pointer(&in.Changes))
+	return nil
+}
+
+// Convert_v1alpha1_PackageRenderTaskSpec_To_porch_PackageRenderTaskSpec is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRenderTaskSpec_To_porch_PackageRenderTaskSpec(in *PackageRenderTaskSpec, out *porch.PackageRenderTaskSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRenderTaskSpec_To_porch_PackageRenderTaskSpec(in, out, s)
+}
+
+func autoConvert_porch_PackageRenderTaskSpec_To_v1alpha1_PackageRenderTaskSpec(in *porch.PackageRenderTaskSpec, out *PackageRenderTaskSpec, s conversion.Scope) error {
+	out.RecordChanges = in.RecordChanges
+	out.AppendedFunctions = *(*[]string)(unsafe.Pointer(&in.AppendedFunctions))
+	out.Results = *(*[]FunctionResult)(unsafe.Pointer(&in.Results))
+	out.Changes = *(*[]RenderChange)(unsafe.Pointer(&in.Changes))
+	return nil
+}
+
+// Convert_porch_PackageRenderTaskSpec_To_v1alpha1_PackageRenderTaskSpec is an autogenerated conversion function.
+func Convert_porch_PackageRenderTaskSpec_To_v1alpha1_PackageRenderTaskSpec(in *porch.PackageRenderTaskSpec, out *PackageRenderTaskSpec, s conversion.Scope) error {
+	return autoConvert_porch_PackageRenderTaskSpec_To_v1alpha1_PackageRenderTaskSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageRevision_To_porch_PackageRevision(in *PackageRevision, out *porch.PackageRevision, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_PackageRevisionSpec_To_porch_PackageRevisionSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -895,8 +1660,34 @@ func Convert_porch_PackageRevisionList_To_v1alpha1_PackageRevisionList(in *porch
 	return autoConvert_porch_PackageRevisionList_To_v1alpha1_PackageRevisionList(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageRevisionLogs_To_porch_PackageRevisionLogs(in *PackageRevisionLogs, out *porch.PackageRevisionLogs, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.Tasks = *(*[]porch.TaskLog)(unsafe.Pointer(&in.Tasks))
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionLogs_To_porch_PackageRevisionLogs is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionLogs_To_porch_PackageRevisionLogs(in *PackageRevisionLogs, out *porch.PackageRevisionLogs, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionLogs_To_porch_PackageRevisionLogs(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionLogs_To_v1alpha1_PackageRevisionLogs(in *porch.PackageRevisionLogs, out *PackageRevisionLogs, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.Tasks = *(*[]TaskLog)(unsafe.Pointer(&in.Tasks))
+	return nil
+}
+
+// Convert_porch_PackageRevisionLogs_To_v1alpha1_PackageRevisionLogs is an autogenerated conversion function.
+func Convert_porch_PackageRevisionLogs_To_v1alpha1_PackageRevisionLogs(in *porch.PackageRevisionLogs, out *PackageRevisionLogs, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionLogs_To_v1alpha1_PackageRevisionLogs(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageRevisionRef_To_porch_PackageRevisionRef(in *PackageRevisionRef, out *porch.PackageRevisionRef, s conversion.Scope) error {
 	out.Name = in.Name
+	out.Repository = in.Repository
+	out.Package = in.Package
+	out.Revision = in.Revision
+	out.Namespace = in.Namespace
 	return nil
 }
 
@@ -907,6 +1698,10 @@ func Convert_v1alpha1_PackageRevisionRef_To_porch_PackageRevisionRef(in *Package
 
 func autoConvert_porch_PackageRevisionRef_To_v1alpha1_PackageRevisionRef(in *porch.PackageRevisionRef, out *PackageRevisionRef, s conversion.Scope) error {
 	out.Name = in.Name
+	out.Repository = in.Repository
+	out.Package = in.Package
+	out.Revision = in.Revision
+	out.Namespace = in.Namespace
 	return nil
 }
 
@@ -941,6 +1736,52 @@ func Convert_porch_PackageRevisionResources_To_v1alpha1_PackageRevisionResources
 	return autoConvert_porch_PackageRevisionResources_To_v1alpha1_PackageRevisionResources(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageRevisionResourcesFinalize_To_porch_PackageRevisionResourcesFinalize(in *PackageRevisionResourcesFinalize, out *porch.PackageRevisionResourcesFinalize, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionResourcesFinalize_To_porch_PackageRevisionResourcesFinalize is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionResourcesFinalize_To_porch_PackageRevisionResourcesFinalize(in *PackageRevisionResourcesFinalize, out *porch.PackageRevisionResourcesFinalize, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionResourcesFinalize_To_porch_PackageRevisionResourcesFinalize(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionResourcesFinalize_To_v1alpha1_PackageRevisionResourcesFinalize(in *porch.PackageRevisionResourcesFinalize, out *PackageRevisionResourcesFinalize, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_porch_PackageRevisionResourcesFinalize_To_v1alpha1_PackageRevisionResourcesFinalize is an autogenerated conversion function.
+func Convert_porch_PackageRevisionResourcesFinalize_To_v1alpha1_PackageRevisionResourcesFinalize(in *porch.PackageRevisionResourcesFinalize, out *PackageRevisionResourcesFinalize, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionResourcesFinalize_To_v1alpha1_PackageRevisionResourcesFinalize(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec(in *PackageRevisionResourcesFinalizeSpec, out *porch.PackageRevisionResourcesFinalizeSpec, s conversion.Scope) error {
+	out.Files = *(*map[string][]string)(unsafe.Pointer(&in.Files))
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec(in *PackageRevisionResourcesFinalizeSpec, out *porch.PackageRevisionResourcesFinalizeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionResourcesFinalizeSpec_To_porch_PackageRevisionResourcesFinalizeSpec(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec(in *porch.PackageRevisionResourcesFinalizeSpec, out *PackageRevisionResourcesFinalizeSpec, s conversion.Scope) error {
+	out.Files = *(*map[string][]string)(unsafe.Pointer(&in.Files))
+	return nil
+}
+
+// Convert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec is an autogenerated conversion function.
+func Convert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec(in *porch.PackageRevisionResourcesFinalizeSpec, out *PackageRevisionResourcesFinalizeSpec, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionResourcesFinalizeSpec_To_v1alpha1_PackageRevisionResourcesFinalizeSpec(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageRevisionResourcesList_To_porch_PackageRevisionResourcesList(in *PackageRevisionResourcesList, out *porch.PackageRevisionResourcesList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	out.Items = *(*[]porch.PackageRevisionResources)(unsafe.Pointer(&in.Items))
@@ -968,6 +1809,7 @@ func autoConvert_v1alpha1_PackageRevisionResourcesSpec_To_porch_PackageRevisionR
 	out.Revision = in.Revision
 	out.RepositoryName = in.RepositoryName
 	out.Resources = *(*map[string]string)(unsafe.Pointer(&in.Resources))
+	out.FileModes = *(*map[string]porch.FileMode)(unsafe.Pointer(&in.FileModes))
 	return nil
 }
 
@@ -981,6 +1823,7 @@ func autoConvert_porch_PackageRevisionResourcesSpec_To_v1alpha1_PackageRevisionR
 	out.Revision = in.Revision
 	out.RepositoryName = in.RepositoryName
 	out.Resources = *(*map[string]string)(unsafe.Pointer(&in.Resources))
+	out.FileModes = *(*map[string]FileMode)(unsafe.Pointer(&in.FileModes))
 	return nil
 }
 
@@ -989,6 +1832,80 @@ func Convert_porch_PackageRevisionResourcesSpec_To_v1alpha1_PackageRevisionResou
 	return autoConvert_porch_PackageRevisionResourcesSpec_To_v1alpha1_PackageRevisionResourcesSpec(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageRevisionResourcesStaging_To_porch_PackageRevisionResourcesStaging(in *PackageRevisionResourcesStaging, out *porch.PackageRevisionResourcesStaging, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionResourcesStaging_To_porch_PackageRevisionResourcesStaging is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionResourcesStaging_To_porch_PackageRevisionResourcesStaging(in *PackageRevisionResourcesStaging, out *porch.PackageRevisionResourcesStaging, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionResourcesStaging_To_porch_PackageRevisionResourcesStaging(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionResourcesStaging_To_v1alpha1_PackageRevisionResourcesStaging(in *porch.PackageRevisionResourcesStaging, out *PackageRevisionResourcesStaging, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_porch_PackageRevisionResourcesStaging_To_v1alpha1_PackageRevisionResourcesStaging is an autogenerated conversion function.
+func Convert_porch_PackageRevisionResourcesStaging_To_v1alpha1_PackageRevisionResourcesStaging(in *porch.PackageRevisionResourcesStaging, out *PackageRevisionResourcesStaging, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionResourcesStaging_To_v1alpha1_PackageRevisionResourcesStaging(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec(in *PackageRevisionResourcesStagingSpec, out *porch.PackageRevisionResourcesStagingSpec, s conversion.Scope) error {
+	out.Chunks = *(*[]porch.StagedChunk)(unsafe.Pointer(&in.Chunks))
+	out.Digests = *(*[]string)(unsafe.Pointer(&in.Digests))
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec(in *PackageRevisionResourcesStagingSpec, out *porch.PackageRevisionResourcesStagingSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionResourcesStagingSpec_To_porch_PackageRevisionResourcesStagingSpec(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec(in *porch.PackageRevisionResourcesStagingSpec, out *PackageRevisionResourcesStagingSpec, s conversion.Scope) error {
+	out.Chunks = *(*[]StagedChunk)(unsafe.Pointer(&in.Chunks))
+	out.Digests = *(*[]string)(unsafe.Pointer(&in.Digests))
+	return nil
+}
+
+// Convert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec is an autogenerated conversion function.
+func Convert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec(in *porch.PackageRevisionResourcesStagingSpec, out *PackageRevisionResourcesStagingSpec, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionResourcesStagingSpec_To_v1alpha1_PackageRevisionResourcesStagingSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus(in *PackageRevisionResourcesStagingStatus, out *porch.PackageRevisionResourcesStagingStatus, s conversion.Scope) error {
+	out.Missing = *(*[]string)(unsafe.Pointer(&in.Missing))
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus(in *PackageRevisionResourcesStagingStatus, out *porch.PackageRevisionResourcesStagingStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionResourcesStagingStatus_To_porch_PackageRevisionResourcesStagingStatus(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus(in *porch.PackageRevisionResourcesStagingStatus, out *PackageRevisionResourcesStagingStatus, s conversion.Scope) error {
+	out.Missing = *(*[]string)(unsafe.Pointer(&in.Missing))
+	return nil
+}
+
+// Convert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus is an autogenerated conversion function.
+func Convert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus(in *porch.PackageRevisionResourcesStagingStatus, out *PackageRevisionResourcesStagingStatus, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionResourcesStagingStatus_To_v1alpha1_PackageRevisionResourcesStagingStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageRevisionSpec_To_porch_PackageRevisionSpec(in *PackageRevisionSpec, out *porch.PackageRevisionSpec, s conversion.Scope) error {
 	out.PackageName = in.PackageName
 	out.Revision = in.Revision
@@ -1025,8 +1942,17 @@ func autoConvert_v1alpha1_PackageRevisionStatus_To_porch_PackageRevisionStatus(i
 	out.UpstreamLock = (*porch.UpstreamLock)(unsafe.Pointer(in.UpstreamLock))
 	out.PublishedBy = in.PublishedBy
 	out.PublishedAt = in.PublishedAt
+	out.DraftCreatedAt = in.DraftCreatedAt
+	out.ProposedAt = in.ProposedAt
+	out.ProposedBy = in.ProposedBy
 	out.Deployment = in.Deployment
 	out.Conditions = *(*[]porch.Condition)(unsafe.Pointer(&in.Conditions))
+	out.Artifacts = *(*[]porch.Artifact)(unsafe.Pointer(&in.Artifacts))
+	out.Archived = in.Archived
+	out.SharedFrom = in.SharedFrom
+	out.Timestamps = (*porch.PackageRevisionTimestamps)(unsafe.Pointer(in.Timestamps))
+	out.DeploymentStatus = (*porch.DeploymentStatus)(unsafe.Pointer(in.DeploymentStatus))
+	out.TaskResults = *(*[]porch.TaskResult)(unsafe.Pointer(&in.TaskResults))
 	return nil
 }
 
@@ -1039,8 +1965,17 @@ func autoConvert_porch_PackageRevisionStatus_To_v1alpha1_PackageRevisionStatus(i
 	out.UpstreamLock = (*UpstreamLock)(unsafe.Pointer(in.UpstreamLock))
 	out.PublishedBy = in.PublishedBy
 	out.PublishedAt = in.PublishedAt
+	out.DraftCreatedAt = in.DraftCreatedAt
+	out.ProposedAt = in.ProposedAt
+	out.ProposedBy = in.ProposedBy
 	out.Deployment = in.Deployment
 	out.Conditions = *(*[]Condition)(unsafe.Pointer(&in.Conditions))
+	out.Artifacts = *(*[]Artifact)(unsafe.Pointer(&in.Artifacts))
+	out.Archived = in.Archived
+	out.SharedFrom = in.SharedFrom
+	out.Timestamps = (*PackageRevisionTimestamps)(unsafe.Pointer(in.Timestamps))
+	out.DeploymentStatus = (*DeploymentStatus)(unsafe.Pointer(in.DeploymentStatus))
+	out.TaskResults = *(*[]TaskResult)(unsafe.Pointer(&in.TaskResults))
 	return nil
 }
 
@@ -1049,9 +1984,36 @@ func Convert_porch_PackageRevisionStatus_To_v1alpha1_PackageRevisionStatus(in *p
 	return autoConvert_porch_PackageRevisionStatus_To_v1alpha1_PackageRevisionStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageRevisionTimestamps_To_porch_PackageRevisionTimestamps(in *PackageRevisionTimestamps, out *porch.PackageRevisionTimestamps, s conversion.Scope) error {
+	out.CommitTime = in.CommitTime
+	out.RecordedCreatedAt = in.RecordedCreatedAt
+	out.RecordedPublishedAt = in.RecordedPublishedAt
+	return nil
+}
+
+// Convert_v1alpha1_PackageRevisionTimestamps_To_porch_PackageRevisionTimestamps is an autogenerated conversion function.
+func Convert_v1alpha1_PackageRevisionTimestamps_To_porch_PackageRevisionTimestamps(in *PackageRevisionTimestamps, out *porch.PackageRevisionTimestamps, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageRevisionTimestamps_To_porch_PackageRevisionTimestamps(in, out, s)
+}
+
+func autoConvert_porch_PackageRevisionTimestamps_To_v1alpha1_PackageRevisionTimestamps(in *porch.PackageRevisionTimestamps, out *PackageRevisionTimestamps, s conversion.Scope) error {
+	out.CommitTime = in.CommitTime
+	out.RecordedCreatedAt = in.RecordedCreatedAt
+	out.RecordedPublishedAt = in.RecordedPublishedAt
+	return nil
+}
+
+// Convert_porch_PackageRevisionTimestamps_To_v1alpha1_PackageRevisionTimestamps is an autogenerated conversion function.
+func Convert_porch_PackageRevisionTimestamps_To_v1alpha1_PackageRevisionTimestamps(in *porch.PackageRevisionTimestamps, out *PackageRevisionTimestamps, s conversion.Scope) error {
+	return autoConvert_porch_PackageRevisionTimestamps_To_v1alpha1_PackageRevisionTimestamps(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageSpec_To_porch_PackageSpec(in *PackageSpec, out *porch.PackageSpec, s conversion.Scope) error {
 	out.PackageName = in.PackageName
 	out.RepositoryName = in.RepositoryName
+	out.Description = in.Description
+	out.Archived = in.Archived
+	out.Freeze = (*porch.PackageFreeze)(unsafe.Pointer(in.Freeze))
 	return nil
 }
 
@@ -1063,6 +2025,9 @@ func Convert_v1alpha1_PackageSpec_To_porch_PackageSpec(in *PackageSpec, out *por
 func autoConvert_porch_PackageSpec_To_v1alpha1_PackageSpec(in *porch.PackageSpec, out *PackageSpec, s conversion.Scope) error {
 	out.PackageName = in.PackageName
 	out.RepositoryName = in.RepositoryName
+	out.Description = in.Description
+	out.Archived = in.Archived
+	out.Freeze = (*PackageFreeze)(unsafe.Pointer(in.Freeze))
 	return nil
 }
 
@@ -1091,6 +2056,28 @@ func Convert_porch_PackageStatus_To_v1alpha1_PackageStatus(in *porch.PackageStat
 	return autoConvert_porch_PackageStatus_To_v1alpha1_PackageStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_PackageTimeline_To_porch_PackageTimeline(in *PackageTimeline, out *porch.PackageTimeline, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.Events = *(*[]porch.TimelineEvent)(unsafe.Pointer(&in.Events))
+	return nil
+}
+
+// Convert_v1alpha1_PackageTimeline_To_porch_PackageTimeline is an autogenerated conversion function.
+func Convert_v1alpha1_PackageTimeline_To_porch_PackageTimeline(in *PackageTimeline, out *porch.PackageTimeline, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PackageTimeline_To_porch_PackageTimeline(in, out, s)
+}
+
+func autoConvert_porch_PackageTimeline_To_v1alpha1_PackageTimeline(in *porch.PackageTimeline, out *PackageTimeline, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.Events = *(*[]TimelineEvent)(unsafe.Pointer(&in.Events))
+	return nil
+}
+
+// Convert_porch_PackageTimeline_To_v1alpha1_PackageTimeline is an autogenerated conversion function.
+func Convert_porch_PackageTimeline_To_v1alpha1_PackageTimeline(in *porch.PackageTimeline, out *PackageTimeline, s conversion.Scope) error {
+	return autoConvert_porch_PackageTimeline_To_v1alpha1_PackageTimeline(in, out, s)
+}
+
 func autoConvert_v1alpha1_PackageUpdateTaskSpec_To_porch_PackageUpdateTaskSpec(in *PackageUpdateTaskSpec, out *porch.PackageUpdateTaskSpec, s conversion.Scope) error {
 	if err := Convert_v1alpha1_UpstreamPackage_To_porch_UpstreamPackage(&in.Upstream, &out.Upstream, s); err != nil {
 		return err
@@ -1179,6 +2166,104 @@ func Convert_porch_ReadinessGate_To_v1alpha1_ReadinessGate(in *porch.ReadinessGa
 	return autoConvert_porch_ReadinessGate_To_v1alpha1_ReadinessGate(in, out, s)
 }
 
+func autoConvert_v1alpha1_RenderChange_To_porch_RenderChange(in *RenderChange, out *porch.RenderChange, s conversion.Scope) error {
+	out.Function = in.Function
+	out.Resource = in.Resource
+	out.Type = in.Type
+	return nil
+}
+
+// Convert_v1alpha1_RenderChange_To_porch_RenderChange is an autogenerated conversion function.
+func Convert_v1alpha1_RenderChange_To_porch_RenderChange(in *RenderChange, out *porch.RenderChange, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RenderChange_To_porch_RenderChange(in, out, s)
+}
+
+func autoConvert_porch_RenderChange_To_v1alpha1_RenderChange(in *porch.RenderChange, out *RenderChange, s conversion.Scope) error {
+	out.Function = in.Function
+	out.Resource = in.Resource
+	out.Type = in.Type
+	return nil
+}
+
+// Convert_porch_RenderChange_To_v1alpha1_RenderChange is an autogenerated conversion function.
+func Convert_porch_RenderChange_To_v1alpha1_RenderChange(in *porch.RenderChange, out *RenderChange, s conversion.Scope) error {
+	return autoConvert_porch_RenderChange_To_v1alpha1_RenderChange(in, out, s)
+}
+
+func autoConvert_v1alpha1_RepositoryConsistencyCheck_To_porch_RepositoryConsistencyCheck(in *RepositoryConsistencyCheck, out *porch.RepositoryConsistencyCheck, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_RepositoryConsistencyCheck_To_porch_RepositoryConsistencyCheck is an autogenerated conversion function.
+func Convert_v1alpha1_RepositoryConsistencyCheck_To_porch_RepositoryConsistencyCheck(in *RepositoryConsistencyCheck, out *porch.RepositoryConsistencyCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RepositoryConsistencyCheck_To_porch_RepositoryConsistencyCheck(in, out, s)
+}
+
+func autoConvert_porch_RepositoryConsistencyCheck_To_v1alpha1_RepositoryConsistencyCheck(in *porch.RepositoryConsistencyCheck, out *RepositoryConsistencyCheck, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_porch_RepositoryConsistencyCheck_To_v1alpha1_RepositoryConsistencyCheck is an autogenerated conversion function.
+func Convert_porch_RepositoryConsistencyCheck_To_v1alpha1_RepositoryConsistencyCheck(in *porch.RepositoryConsistencyCheck, out *RepositoryConsistencyCheck, s conversion.Scope) error {
+	return autoConvert_porch_RepositoryConsistencyCheck_To_v1alpha1_RepositoryConsistencyCheck(in, out, s)
+}
+
+func autoConvert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec(in *RepositoryConsistencyCheckSpec, out *porch.RepositoryConsistencyCheckSpec, s conversion.Scope) error {
+	out.RepositoryName = in.RepositoryName
+	out.Repair = in.Repair
+	return nil
+}
+
+// Convert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec is an autogenerated conversion function.
+func Convert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec(in *RepositoryConsistencyCheckSpec, out *porch.RepositoryConsistencyCheckSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RepositoryConsistencyCheckSpec_To_porch_RepositoryConsistencyCheckSpec(in, out, s)
+}
+
+func autoConvert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec(in *porch.RepositoryConsistencyCheckSpec, out *RepositoryConsistencyCheckSpec, s conversion.Scope) error {
+	out.RepositoryName = in.RepositoryName
+	out.Repair = in.Repair
+	return nil
+}
+
+// Convert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec is an autogenerated conversion function.
+func Convert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec(in *porch.RepositoryConsistencyCheckSpec, out *RepositoryConsistencyCheckSpec, s conversion.Scope) error {
+	return autoConvert_porch_RepositoryConsistencyCheckSpec_To_v1alpha1_RepositoryConsistencyCheckSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus(in *RepositoryConsistencyCheckStatus, out *porch.RepositoryConsistencyCheckStatus, s conversion.Scope) error {
+	out.Findings = *(*[]porch.ConsistencyFinding)(unsafe.Pointer(&in.Findings))
+	return nil
+}
+
+// Convert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus is an autogenerated conversion function.
+func Convert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus(in *RepositoryConsistencyCheckStatus, out *porch.RepositoryConsistencyCheckStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RepositoryConsistencyCheckStatus_To_porch_RepositoryConsistencyCheckStatus(in, out, s)
+}
+
+func autoConvert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus(in *porch.RepositoryConsistencyCheckStatus, out *RepositoryConsistencyCheckStatus, s conversion.Scope) error {
+	out.Findings = *(*[]ConsistencyFinding)(unsafe.Pointer(&in.Findings))
+	return nil
+}
+
+// Convert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus is an autogenerated conversion function.
+func Convert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus(in *porch.RepositoryConsistencyCheckStatus, out *RepositoryConsistencyCheckStatus, s conversion.Scope) error {
+	return autoConvert_porch_RepositoryConsistencyCheckStatus_To_v1alpha1_RepositoryConsistencyCheckStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_RepositoryRef_To_porch_RepositoryRef(in *RepositoryRef, out *porch.RepositoryRef, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
@@ -1199,6 +2284,28 @@ func Convert_porch_RepositoryRef_To_v1alpha1_RepositoryRef(in *porch.RepositoryR
 	return autoConvert_porch_RepositoryRef_To_v1alpha1_RepositoryRef(in, out, s)
 }
 
+func autoConvert_v1alpha1_SecretKeyRef_To_porch_SecretKeyRef(in *SecretKeyRef, out *porch.SecretKeyRef, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Key = in.Key
+	return nil
+}
+
+// Convert_v1alpha1_SecretKeyRef_To_porch_SecretKeyRef is an autogenerated conversion function.
+func Convert_v1alpha1_SecretKeyRef_To_porch_SecretKeyRef(in *SecretKeyRef, out *porch.SecretKeyRef, s conversion.Scope) error {
+	return autoConvert_v1alpha1_SecretKeyRef_To_porch_SecretKeyRef(in, out, s)
+}
+
+func autoConvert_porch_SecretKeyRef_To_v1alpha1_SecretKeyRef(in *porch.SecretKeyRef, out *SecretKeyRef, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Key = in.Key
+	return nil
+}
+
+// Convert_porch_SecretKeyRef_To_v1alpha1_SecretKeyRef is an autogenerated conversion function.
+func Convert_porch_SecretKeyRef_To_v1alpha1_SecretKeyRef(in *porch.SecretKeyRef, out *SecretKeyRef, s conversion.Scope) error {
+	return autoConvert_porch_SecretKeyRef_To_v1alpha1_SecretKeyRef(in, out, s)
+}
+
 func autoConvert_v1alpha1_SecretRef_To_porch_SecretRef(in *SecretRef, out *porch.SecretRef, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
@@ -1245,6 +2352,28 @@ func Convert_porch_Selector_To_v1alpha1_Selector(in *porch.Selector, out *Select
 	return autoConvert_porch_Selector_To_v1alpha1_Selector(in, out, s)
 }
 
+func autoConvert_v1alpha1_StagedChunk_To_porch_StagedChunk(in *StagedChunk, out *porch.StagedChunk, s conversion.Scope) error {
+	out.Digest = in.Digest
+	out.Data = *(*[]byte)(unsafe.Pointer(&in.Data))
+	return nil
+}
+
+// Convert_v1alpha1_StagedChunk_To_porch_StagedChunk is an autogenerated conversion function.
+func Convert_v1alpha1_StagedChunk_To_porch_StagedChunk(in *StagedChunk, out *porch.StagedChunk, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StagedChunk_To_porch_StagedChunk(in, out, s)
+}
+
+func autoConvert_porch_StagedChunk_To_v1alpha1_StagedChunk(in *porch.StagedChunk, out *StagedChunk, s conversion.Scope) error {
+	out.Digest = in.Digest
+	out.Data = *(*[]byte)(unsafe.Pointer(&in.Data))
+	return nil
+}
+
+// Convert_porch_StagedChunk_To_v1alpha1_StagedChunk is an autogenerated conversion function.
+func Convert_porch_StagedChunk_To_v1alpha1_StagedChunk(in *porch.StagedChunk, out *StagedChunk, s conversion.Scope) error {
+	return autoConvert_porch_StagedChunk_To_v1alpha1_StagedChunk(in, out, s)
+}
+
 func autoConvert_v1alpha1_Task_To_porch_Task(in *Task, out *porch.Task, s conversion.Scope) error {
 	out.Type = porch.TaskType(in.Type)
 	out.Init = (*porch.PackageInitTaskSpec)(unsafe.Pointer(in.Init))
@@ -1253,6 +2382,7 @@ func autoConvert_v1alpha1_Task_To_porch_Task(in *Task, out *porch.Task, s conver
 	out.Edit = (*porch.PackageEditTaskSpec)(unsafe.Pointer(in.Edit))
 	out.Eval = (*porch.FunctionEvalTaskSpec)(unsafe.Pointer(in.Eval))
 	out.Update = (*porch.PackageUpdateTaskSpec)(unsafe.Pointer(in.Update))
+	out.Render = (*porch.PackageRenderTaskSpec)(unsafe.Pointer(in.Render))
 	return nil
 }
 
@@ -1269,6 +2399,7 @@ func autoConvert_porch_Task_To_v1alpha1_Task(in *porch.Task, out *Task, s conver
 	out.Edit = (*PackageEditTaskSpec)(unsafe.Pointer(in.Edit))
 	out.Eval = (*FunctionEvalTaskSpec)(unsafe.Pointer(in.Eval))
 	out.Update = (*PackageUpdateTaskSpec)(unsafe.Pointer(in.Update))
+	out.Render = (*PackageRenderTaskSpec)(unsafe.Pointer(in.Render))
 	return nil
 }
 
@@ -1277,6 +2408,92 @@ func Convert_porch_Task_To_v1alpha1_Task(in *porch.Task, out *Task, s conversion
 	return autoConvert_porch_Task_To_v1alpha1_Task(in, out, s)
 }
 
+func autoConvert_v1alpha1_TaskLog_To_porch_TaskLog(in *TaskLog, out *porch.TaskLog, s conversion.Scope) error {
+	out.Task = in.Task
+	out.Functions = *(*[]porch.FunctionLog)(unsafe.Pointer(&in.Functions))
+	return nil
+}
+
+// Convert_v1alpha1_TaskLog_To_porch_TaskLog is an autogenerated conversion function.
+func Convert_v1alpha1_TaskLog_To_porch_TaskLog(in *TaskLog, out *porch.TaskLog, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TaskLog_To_porch_TaskLog(in, out, s)
+}
+
+func autoConvert_porch_TaskLog_To_v1alpha1_TaskLog(in *porch.TaskLog, out *TaskLog, s conversion.Scope) error {
+	out.Task = in.Task
+	out.Functions = *(*[]FunctionLog)(unsafe.Pointer(&in.Functions))
+	return nil
+}
+
+// Convert_porch_TaskLog_To_v1alpha1_TaskLog is an autogenerated conversion function.
+func Convert_porch_TaskLog_To_v1alpha1_TaskLog(in *porch.TaskLog, out *TaskLog, s conversion.Scope) error {
+	return autoConvert_porch_TaskLog_To_v1alpha1_TaskLog(in, out, s)
+}
+
+func autoConvert_v1alpha1_TaskResult_To_porch_TaskResult(in *TaskResult, out *porch.TaskResult, s conversion.Scope) error {
+	out.Task = in.Task
+	out.Type = porch.TaskType(in.Type)
+	out.Duration = in.Duration
+	out.StartedAt = in.StartedAt
+	out.Added = *(*[]string)(unsafe.Pointer(&in.Added))
+	out.Changed = *(*[]string)(unsafe.Pointer(&in.Changed))
+	out.Deleted = *(*[]string)(unsafe.Pointer(&in.Deleted))
+	out.Results = *(*[]porch.FunctionResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_v1alpha1_TaskResult_To_porch_TaskResult is an autogenerated conversion function.
+func Convert_v1alpha1_TaskResult_To_porch_TaskResult(in *TaskResult, out *porch.TaskResult, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TaskResult_To_porch_TaskResult(in, out, s)
+}
+
+func autoConvert_porch_TaskResult_To_v1alpha1_TaskResult(in *porch.TaskResult, out *TaskResult, s conversion.Scope) error {
+	out.Task = in.Task
+	out.Type = TaskType(in.Type)
+	out.Duration = in.Duration
+	out.StartedAt = in.StartedAt
+	out.Added = *(*[]string)(unsafe.Pointer(&in.Added))
+	out.Changed = *(*[]string)(unsafe.Pointer(&in.Changed))
+	out.Deleted = *(*[]string)(unsafe.Pointer(&in.Deleted))
+	out.Results = *(*[]FunctionResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_porch_TaskResult_To_v1alpha1_TaskResult is an autogenerated conversion function.
+func Convert_porch_TaskResult_To_v1alpha1_TaskResult(in *porch.TaskResult, out *TaskResult, s conversion.Scope) error {
+	return autoConvert_porch_TaskResult_To_v1alpha1_TaskResult(in, out, s)
+}
+
+func autoConvert_v1alpha1_TimelineEvent_To_porch_TimelineEvent(in *TimelineEvent, out *porch.TimelineEvent, s conversion.Scope) error {
+	out.Time = in.Time
+	out.Type = porch.TimelineEventType(in.Type)
+	out.Revision = in.Revision
+	out.Actor = in.Actor
+	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_TimelineEvent_To_porch_TimelineEvent is an autogenerated conversion function.
+func Convert_v1alpha1_TimelineEvent_To_porch_TimelineEvent(in *TimelineEvent, out *porch.TimelineEvent, s conversion.Scope) error {
+	return autoConvert_v1alpha1_TimelineEvent_To_porch_TimelineEvent(in, out, s)
+}
+
+func autoConvert_porch_TimelineEvent_To_v1alpha1_TimelineEvent(in *porch.TimelineEvent, out *TimelineEvent, s conversion.Scope) error {
+	out.Time = in.Time
+	out.Type = TimelineEventType(in.Type)
+	out.Revision = in.Revision
+	out.Actor = in.Actor
+	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_porch_TimelineEvent_To_v1alpha1_TimelineEvent is an autogenerated conversion function.
+func Convert_porch_TimelineEvent_To_v1alpha1_TimelineEvent(in *porch.TimelineEvent, out *TimelineEvent, s conversion.Scope) error {
+	return autoConvert_porch_TimelineEvent_To_v1alpha1_TimelineEvent(in, out, s)
+}
+
 func autoConvert_v1alpha1_UpstreamLock_To_porch_UpstreamLock(in *UpstreamLock, out *porch.UpstreamLock, s conversion.Scope) error {
 	out.Type = porch.OriginType(in.Type)
 	out.Git = (*porch.GitLock)(unsafe.Pointer(in.Git))
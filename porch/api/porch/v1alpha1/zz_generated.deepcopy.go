@@ -20,9 +20,112 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Artifact) DeepCopyInto(out *Artifact) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Artifact.
+func (in *Artifact) DeepCopy() *Artifact {
+	if in == nil {
+		return nil
+	}
+	out := new(Artifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkApproval) DeepCopyInto(out *BulkApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkApproval.
+func (in *BulkApproval) DeepCopy() *BulkApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BulkApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkApprovalResult) DeepCopyInto(out *BulkApprovalResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkApprovalResult.
+func (in *BulkApprovalResult) DeepCopy() *BulkApprovalResult {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkApprovalResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkApprovalSpec) DeepCopyInto(out *BulkApprovalSpec) {
+	*out = *in
+	if in.PackageRevisions != nil {
+		in, out := &in.PackageRevisions, &out.PackageRevisions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkApprovalSpec.
+func (in *BulkApprovalSpec) DeepCopy() *BulkApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkApprovalStatus) DeepCopyInto(out *BulkApprovalStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]BulkApprovalResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkApprovalStatus.
+func (in *BulkApprovalStatus) DeepCopy() *BulkApprovalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkApprovalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -39,6 +142,40 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyFinding) DeepCopyInto(out *ConsistencyFinding) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyFinding.
+func (in *ConsistencyFinding) DeepCopy() *ConsistencyFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+	*out = *in
+	in.SyncedTime.DeepCopyInto(&out.SyncedTime)
+	in.ReportedTime.DeepCopyInto(&out.ReportedTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
+func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Function) DeepCopyInto(out *Function) {
 	*out = *in
@@ -105,6 +242,13 @@ func (in *FunctionEvalTaskSpec) DeepCopyInto(out *FunctionEvalTaskSpec) {
 		}
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.ConfigSecretRefs != nil {
+		in, out := &in.ConfigSecretRefs, &out.ConfigSecretRefs
+		*out = make(map[string]SecretKeyRef, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	out.Match = in.Match
 	return
 }
@@ -152,6 +296,22 @@ func (in *FunctionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionLog) DeepCopyInto(out *FunctionLog) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionLog.
+func (in *FunctionLog) DeepCopy() *FunctionLog {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FunctionRef) DeepCopyInto(out *FunctionRef) {
 	*out = *in
@@ -168,6 +328,22 @@ func (in *FunctionRef) DeepCopy() *FunctionRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionResult) DeepCopyInto(out *FunctionResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionResult.
+func (in *FunctionResult) DeepCopy() *FunctionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FunctionSpec) DeepCopyInto(out *FunctionSpec) {
 	*out = *in
@@ -251,6 +427,38 @@ func (in *GitPackage) DeepCopy() *GitPackage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MovedDownstream) DeepCopyInto(out *MovedDownstream) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MovedDownstream.
+func (in *MovedDownstream) DeepCopy() *MovedDownstream {
+	if in == nil {
+		return nil
+	}
+	out := new(MovedDownstream)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MovedPackageRevision) DeepCopyInto(out *MovedPackageRevision) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MovedPackageRevision.
+func (in *MovedPackageRevision) DeepCopy() *MovedPackageRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(MovedPackageRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OciPackage) DeepCopyInto(out *OciPackage) {
 	*out = *in
@@ -272,7 +480,7 @@ func (in *Package) DeepCopyInto(out *Package) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 	return
 }
@@ -299,6 +507,11 @@ func (in *Package) DeepCopyObject() runtime.Object {
 func (in *PackageCloneTaskSpec) DeepCopyInto(out *PackageCloneTaskSpec) {
 	*out = *in
 	in.Upstream.DeepCopyInto(&out.Upstream)
+	if in.ResolvedUpstreamRef != nil {
+		in, out := &in.ResolvedUpstreamRef, &out.ResolvedUpstreamRef
+		*out = new(PackageRevisionRef)
+		**out = **in
+	}
 	return
 }
 
@@ -341,6 +554,11 @@ func (in *PackageInitTaskSpec) DeepCopyInto(out *PackageInitTaskSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Starters != nil {
+		in, out := &in.Starters, &out.Starters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -354,6 +572,23 @@ func (in *PackageInitTaskSpec) DeepCopy() *PackageInitTaskSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageFreeze) DeepCopyInto(out *PackageFreeze) {
+	*out = *in
+	in.FrozenAt.DeepCopyInto(&out.FrozenAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageFreeze.
+func (in *PackageFreeze) DeepCopy() *PackageFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageList) DeepCopyInto(out *PackageList) {
 	*out = *in
@@ -387,6 +622,76 @@ func (in *PackageList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageMove) DeepCopyInto(out *PackageMove) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageMove.
+func (in *PackageMove) DeepCopy() *PackageMove {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageMove) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageMoveSpec) DeepCopyInto(out *PackageMoveSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageMoveSpec.
+func (in *PackageMoveSpec) DeepCopy() *PackageMoveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageMoveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageMoveStatus) DeepCopyInto(out *PackageMoveStatus) {
+	*out = *in
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]MovedPackageRevision, len(*in))
+		copy(*out, *in)
+	}
+	if in.Downstreams != nil {
+		in, out := &in.Downstreams, &out.Downstreams
+		*out = make([]MovedDownstream, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageMoveStatus.
+func (in *PackageMoveStatus) DeepCopy() *PackageMoveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageMoveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackagePatchTaskSpec) DeepCopyInto(out *PackagePatchTaskSpec) {
 	*out = *in
@@ -408,6 +713,37 @@ func (in *PackagePatchTaskSpec) DeepCopy() *PackagePatchTaskSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRenderTaskSpec) DeepCopyInto(out *PackageRenderTaskSpec) {
+	*out = *in
+	if in.AppendedFunctions != nil {
+		in, out := &in.AppendedFunctions, &out.AppendedFunctions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]FunctionResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]RenderChange, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRenderTaskSpec.
+func (in *PackageRenderTaskSpec) DeepCopy() *PackageRenderTaskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRenderTaskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRevision) DeepCopyInto(out *PackageRevision) {
 	*out = *in
@@ -470,23 +806,56 @@ func (in *PackageRevisionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PackageRevisionRef) DeepCopyInto(out *PackageRevisionRef) {
+func (in *PackageRevisionLogs) DeepCopyInto(out *PackageRevisionLogs) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]TaskLog, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionRef.
-func (in *PackageRevisionRef) DeepCopy() *PackageRevisionRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionLogs.
+func (in *PackageRevisionLogs) DeepCopy() *PackageRevisionLogs {
 	if in == nil {
 		return nil
 	}
-	out := new(PackageRevisionRef)
+	out := new(PackageRevisionLogs)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PackageRevisionResources) DeepCopyInto(out *PackageRevisionResources) {
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageRevisionLogs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionRef) DeepCopyInto(out *PackageRevisionRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionRef.
+func (in *PackageRevisionRef) DeepCopy() *PackageRevisionRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResources) DeepCopyInto(out *PackageRevisionResources) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -512,6 +881,64 @@ func (in *PackageRevisionResources) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResourcesFinalize) DeepCopyInto(out *PackageRevisionResourcesFinalize) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionResourcesFinalize.
+func (in *PackageRevisionResourcesFinalize) DeepCopy() *PackageRevisionResourcesFinalize {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionResourcesFinalize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageRevisionResourcesFinalize) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResourcesFinalizeSpec) DeepCopyInto(out *PackageRevisionResourcesFinalizeSpec) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionResourcesFinalizeSpec.
+func (in *PackageRevisionResourcesFinalizeSpec) DeepCopy() *PackageRevisionResourcesFinalizeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionResourcesFinalizeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRevisionResourcesList) DeepCopyInto(out *PackageRevisionResourcesList) {
 	*out = *in
@@ -555,6 +982,13 @@ func (in *PackageRevisionResourcesSpec) DeepCopyInto(out *PackageRevisionResourc
 			(*out)[key] = val
 		}
 	}
+	if in.FileModes != nil {
+		in, out := &in.FileModes, &out.FileModes
+		*out = make(map[string]FileMode, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -568,6 +1002,83 @@ func (in *PackageRevisionResourcesSpec) DeepCopy() *PackageRevisionResourcesSpec
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResourcesStaging) DeepCopyInto(out *PackageRevisionResourcesStaging) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionResourcesStaging.
+func (in *PackageRevisionResourcesStaging) DeepCopy() *PackageRevisionResourcesStaging {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionResourcesStaging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageRevisionResourcesStaging) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResourcesStagingSpec) DeepCopyInto(out *PackageRevisionResourcesStagingSpec) {
+	*out = *in
+	if in.Chunks != nil {
+		in, out := &in.Chunks, &out.Chunks
+		*out = make([]StagedChunk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Digests != nil {
+		in, out := &in.Digests, &out.Digests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionResourcesStagingSpec.
+func (in *PackageRevisionResourcesStagingSpec) DeepCopy() *PackageRevisionResourcesStagingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionResourcesStagingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionResourcesStagingStatus) DeepCopyInto(out *PackageRevisionResourcesStagingStatus) {
+	*out = *in
+	if in.Missing != nil {
+		in, out := &in.Missing, &out.Missing
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionResourcesStagingStatus.
+func (in *PackageRevisionResourcesStagingStatus) DeepCopy() *PackageRevisionResourcesStagingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionResourcesStagingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRevisionSpec) DeepCopyInto(out *PackageRevisionSpec) {
 	*out = *in
@@ -610,11 +1121,35 @@ func (in *PackageRevisionStatus) DeepCopyInto(out *PackageRevisionStatus) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.PublishedAt.DeepCopyInto(&out.PublishedAt)
+	in.DraftCreatedAt.DeepCopyInto(&out.DraftCreatedAt)
+	in.ProposedAt.DeepCopyInto(&out.ProposedAt)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
 		copy(*out, *in)
 	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]Artifact, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timestamps != nil {
+		in, out := &in.Timestamps, &out.Timestamps
+		*out = new(PackageRevisionTimestamps)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeploymentStatus != nil {
+		in, out := &in.DeploymentStatus, &out.DeploymentStatus
+		*out = new(DeploymentStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TaskResults != nil {
+		in, out := &in.TaskResults, &out.TaskResults
+		*out = make([]TaskResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -628,9 +1163,33 @@ func (in *PackageRevisionStatus) DeepCopy() *PackageRevisionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionTimestamps) DeepCopyInto(out *PackageRevisionTimestamps) {
+	*out = *in
+	in.CommitTime.DeepCopyInto(&out.CommitTime)
+	in.RecordedCreatedAt.DeepCopyInto(&out.RecordedCreatedAt)
+	in.RecordedPublishedAt.DeepCopyInto(&out.RecordedPublishedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionTimestamps.
+func (in *PackageRevisionTimestamps) DeepCopy() *PackageRevisionTimestamps {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionTimestamps)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageSpec) DeepCopyInto(out *PackageSpec) {
 	*out = *in
+	if in.Freeze != nil {
+		in, out := &in.Freeze, &out.Freeze
+		*out = new(PackageFreeze)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -660,6 +1219,39 @@ func (in *PackageStatus) DeepCopy() *PackageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageTimeline) DeepCopyInto(out *PackageTimeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]TimelineEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageTimeline.
+func (in *PackageTimeline) DeepCopy() *PackageTimeline {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageTimeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageTimeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageUpdateTaskSpec) DeepCopyInto(out *PackageUpdateTaskSpec) {
 	*out = *in
@@ -725,6 +1317,87 @@ func (in *ReadinessGate) DeepCopy() *ReadinessGate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenderChange) DeepCopyInto(out *RenderChange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderChange.
+func (in *RenderChange) DeepCopy() *RenderChange {
+	if in == nil {
+		return nil
+	}
+	out := new(RenderChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryConsistencyCheck) DeepCopyInto(out *RepositoryConsistencyCheck) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryConsistencyCheck.
+func (in *RepositoryConsistencyCheck) DeepCopy() *RepositoryConsistencyCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryConsistencyCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RepositoryConsistencyCheck) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryConsistencyCheckSpec) DeepCopyInto(out *RepositoryConsistencyCheckSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryConsistencyCheckSpec.
+func (in *RepositoryConsistencyCheckSpec) DeepCopy() *RepositoryConsistencyCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryConsistencyCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryConsistencyCheckStatus) DeepCopyInto(out *RepositoryConsistencyCheckStatus) {
+	*out = *in
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]ConsistencyFinding, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryConsistencyCheckStatus.
+func (in *RepositoryConsistencyCheckStatus) DeepCopy() *RepositoryConsistencyCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryConsistencyCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepositoryRef) DeepCopyInto(out *RepositoryRef) {
 	*out = *in
@@ -741,6 +1414,22 @@ func (in *RepositoryRef) DeepCopy() *RepositoryRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -773,6 +1462,27 @@ func (in *Selector) DeepCopy() *Selector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StagedChunk) DeepCopyInto(out *StagedChunk) {
+	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StagedChunk.
+func (in *StagedChunk) DeepCopy() *StagedChunk {
+	if in == nil {
+		return nil
+	}
+	out := new(StagedChunk)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Task) DeepCopyInto(out *Task) {
 	*out = *in
@@ -806,6 +1516,11 @@ func (in *Task) DeepCopyInto(out *Task) {
 		*out = new(PackageUpdateTaskSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Render != nil {
+		in, out := &in.Render, &out.Render
+		*out = new(PackageRenderTaskSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -819,6 +1534,87 @@ func (in *Task) DeepCopy() *Task {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskLog) DeepCopyInto(out *TaskLog) {
+	*out = *in
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]FunctionLog, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskLog.
+func (in *TaskLog) DeepCopy() *TaskLog {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskResult) DeepCopyInto(out *TaskResult) {
+	*out = *in
+	out.Duration = in.Duration
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Changed != nil {
+		in, out := &in.Changed, &out.Changed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deleted != nil {
+		in, out := &in.Deleted, &out.Deleted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]FunctionResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskResult.
+func (in *TaskResult) DeepCopy() *TaskResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimelineEvent) DeepCopyInto(out *TimelineEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimelineEvent.
+func (in *TimelineEvent) DeepCopy() *TimelineEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(TimelineEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamLock) DeepCopyInto(out *UpstreamLock) {
 	*out = *in
@@ -84,6 +84,98 @@ type RepositorySpec struct {
 	// Based on the Kubernetest Admission Controllers (https://kubernetes.io/docs/reference/access-authn-authz/admission-controllers/). The functions will be evaluated
 	// in the order specified in the list.
 	Validators []FunctionEval `json:"validators,omitempty"`
+
+	// Retention limits the published package revisions kept in the repository. If unset,
+	// all published revisions are kept.
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+
+	// AllowedUpstreams restricts the upstream packages that packages in the repository can be
+	// cloned from or updated to. An upstream is allowed if it matches any entry. If empty, all
+	// upstreams are allowed.
+	AllowedUpstreams []AllowedUpstream `json:"allowedUpstreams,omitempty"`
+
+	// Shared shares the published package revisions of the repository with the namespaces
+	// selected by SharedWith. Packages in those namespaces can be cloned from and updated to
+	// the shared package revisions, but can't change the repository.
+	Shared RepositorySharing `json:"shared,omitempty"`
+
+	// SharedWith selects the namespaces the repository is shared with. Required if Shared is set.
+	SharedWith *RepositorySharedWith `json:"sharedWith,omitempty"`
+
+	// DefaultMetadata are labels and annotations added to the package revisions created in
+	// the repository. Labels and annotations set on a package revision take precedence.
+	// Changes apply to package revisions created afterwards.
+	DefaultMetadata *DefaultMetadata `json:"defaultMetadata,omitempty"`
+
+	// CommitTrailers add labels and annotations of package revisions as trailers to the
+	// commit publishing them, in the order listed. Only supported by Git repositories.
+	CommitTrailers []CommitTrailer `json:"commitTrailers,omitempty"`
+}
+
+// DefaultMetadata is the metadata added to new package revisions of a repository. Keys
+// managed by Porch, in the kpt.dev domain and its subdomains, aren't allowed.
+type DefaultMetadata struct {
+	// Labels are the default labels of new package revisions.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the default annotations of new package revisions.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CommitTrailer adds a label or an annotation of a package revision as a trailer to the
+// commit publishing it. Exactly one of Label and Annotation must be set. The trailer is
+// omitted if the package revision doesn't have the label or annotation.
+type CommitTrailer struct {
+	// Trailer is the key of the trailer, such as `Reviewed-By`.
+	Trailer string `json:"trailer"`
+	// Label is the key of the label whose value is the value of the trailer.
+	Label string `json:"label,omitempty"`
+	// Annotation is the key of the annotation whose value is the value of the trailer.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// RepositorySharing is how a repository is shared with other namespaces.
+type RepositorySharing string
+
+const (
+	// RepositorySharingReadOnly shares the published package revisions of a repository
+	// for reading.
+	RepositorySharingReadOnly RepositorySharing = "readOnly"
+)
+
+// RepositorySharedWith selects the namespaces a repository is shared with. A namespace
+// is selected if it is listed or matches the selector.
+type RepositorySharedWith struct {
+	// Namespaces are the names of the namespaces the repository is shared with. `*` shares
+	// the repository with all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the repository is shared with by their labels.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// AllowedUpstream is an allowed source of upstream packages. Exactly one field must be set.
+type AllowedUpstream struct {
+	// Repository is the name of a registered repository in the same namespace, or the
+	// `namespace/name` of a repository shared with the namespace.
+	Repository string `json:"repository,omitempty"`
+	// Git is an allowed Git repository address, or a prefix of allowed addresses such as
+	// `https://github.com/example`.
+	Git string `json:"git,omitempty"`
+	// Oci is an allowed OCI image, or a prefix of allowed images such as
+	// `us-docker.pkg.dev/example/blueprints`.
+	Oci string `json:"oci,omitempty"`
+}
+
+// RetentionPolicy selects the published package revisions Porch deletes from a repository.
+// A published revision is only deleted if none of the rules keep it: the latest revision
+// of each package and revisions referenced as the upstream of a package revision in the
+// namespace are always kept.
+type RetentionPolicy struct {
+	// KeepLast is the number of most recent published revisions of each package to keep.
+	KeepLast int `json:"keepLast"`
+	// MinAge keeps published revisions which were published less than MinAge ago.
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
+	// DryRun only reports the revisions which would be deleted in the repository status.
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // GitRepository describes a Git repository.
@@ -100,6 +192,10 @@ type GitRepository struct {
 	Directory string `json:"directory,omitempty"`
 	// Reference to secret containing authentication credentials.
 	SecretRef SecretRef `json:"secretRef,omitempty"`
+	// PruneStaleRefs allows Porch to delete draft and proposed branches that no longer correspond to a package revision when a prune is requested. Dry-run prunes are allowed regardless.
+	PruneStaleRefs bool `json:"pruneStaleRefs,omitempty"`
+	// WebhookSecretRef enables push webhooks of the Git provider to refresh the repository. The `secret` key of the secret is the shared secret the webhooks must be configured with.
+	WebhookSecretRef SecretRef `json:"webhookSecretRef,omitempty"`
 }
 
 // OciRepository describes a repository compatible with the Open Container Registry standard.
@@ -158,12 +254,104 @@ const (
 	ReasonError = "Error"
 	// Reason for the condition is the repository is ready.
 	ReasonReady = "Ready"
+
+	// Type of the Repository condition reporting packages whose paths differ only by
+	// case or unicode normalization, which collide when the repository is checked out on
+	// a case-insensitive filesystem. The condition is only set while such packages exist.
+	RepositoryPackagePathCollision = "PackagePathCollision"
+	// Reason for the condition is colliding package paths.
+	ReasonPackagePathCollision = "PackagePathCollision"
 )
 
+const (
+	// PruneRequestAnnotation requests pruning of the stale refs of the repository.
+	// The value is an opaque request ID, reported back in status.prune.request once
+	// the prune has run; setting a new value requests another prune.
+	PruneRequestAnnotation = "config.porch.kpt.dev/prune-request"
+	// PruneDryRunAnnotation set to "true" makes the requested prune only report the
+	// stale refs without deleting them.
+	PruneDryRunAnnotation = "config.porch.kpt.dev/prune-dry-run"
+)
+
+// StrictTasksAnnotation set to "true" on a Repository rejects package revisions
+// whose tasks don't start with an init or clone task, instead of creating an empty
+// package with an inserted init task.
+const StrictTasksAnnotation = "config.porch.kpt.dev/strict-tasks"
+
+// DeploymentNamespaceAnnotation on a deployment Repository sets the namespace of the
+// namespaced resources of packages cloned into the repository.
+const DeploymentNamespaceAnnotation = "config.porch.kpt.dev/deployment-namespace"
+
+// SafeDeleteAnnotation set to "true" on a Repository only allows deleting packages
+// which have been archived first.
+const SafeDeleteAnnotation = "config.porch.kpt.dev/safe-delete"
+
+// ProtectPackageContextAnnotation set to "true" on a Repository rejects updates of the
+// resources of its packages which remove the package context ConfigMap; by default
+// the removal is only logged.
+const ProtectPackageContextAnnotation = "config.porch.kpt.dev/protect-package-context"
+
+// DescriptionTemplateAnnotation on a Repository is a Go template generating the
+// description of packages created in the repository without one, using {{.Package}},
+// {{.PackagePath}} and {{.Repository}}. It takes precedence over the default template
+// of the Porch server.
+const DescriptionTemplateAnnotation = "config.porch.kpt.dev/description-template"
+
 // RepositoryStatus defines the observed state of Repository
 type RepositoryStatus struct {
 	// Conditions describes the reconciliation state of the object.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Prune is the result of the last requested prune of the repository.
+	Prune *RepositoryPruneStatus `json:"prune,omitempty"`
+	// Retention is the result of the last enforcement of the retention policy.
+	Retention *RepositoryRetentionStatus `json:"retention,omitempty"`
+	// Webhook describes how to configure the push webhook of the Git provider, if enabled
+	// by spec.git.webhookSecretRef.
+	Webhook *RepositoryWebhookStatus `json:"webhook,omitempty"`
+}
+
+// WebhookSecretKey is the key of the shared secret of push webhooks in the secret
+// referenced by spec.git.webhookSecretRef.
+const WebhookSecretKey = "secret"
+
+// RepositoryWebhookStatus describes the push webhook to configure in the Git provider to
+// refresh the repository as soon as it changes.
+type RepositoryWebhookStatus struct {
+	// Path is the path of the webhook endpoint on the Porch server.
+	Path string `json:"path"`
+	// SecretName is the name of the secret containing the shared secret to configure.
+	SecretName string `json:"secretName"`
+	// SecretKey is the key of the shared secret in the secret.
+	SecretKey string `json:"secretKey"`
+}
+
+// RepositoryPruneStatus describes the result of pruning stale refs from a repository.
+type RepositoryPruneStatus struct {
+	// Request is the value of the prune request annotation this result corresponds to.
+	Request string `json:"request"`
+	// DryRun is true if the stale refs were only reported and not deleted.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Time is when the prune ran.
+	Time metav1.Time `json:"time,omitempty"`
+	// Refs are the stale refs that were deleted (or would be deleted in a dry run).
+	Refs []string `json:"refs,omitempty"`
+	// Bytes is the size of the objects reachable only from the stale refs.
+	Bytes int64 `json:"bytes,omitempty"`
+	// Error is set if the prune failed.
+	Error string `json:"error,omitempty"`
+}
+
+// RepositoryRetentionStatus describes the result of enforcing the retention policy of a
+// repository.
+type RepositoryRetentionStatus struct {
+	// DryRun is true if the revisions were only reported and not deleted.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Time is when the retention policy was enforced.
+	Time metav1.Time `json:"time,omitempty"`
+	// Revisions are the package revisions which were deleted (or would be deleted in a dry run).
+	Revisions []string `json:"revisions,omitempty"`
+	// Error is set if the retention policy couldn't be enforced.
+	Error string `json:"error,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -24,6 +24,65 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedUpstream) DeepCopyInto(out *AllowedUpstream) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedUpstream.
+func (in *AllowedUpstream) DeepCopy() *AllowedUpstream {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedUpstream)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitTrailer) DeepCopyInto(out *CommitTrailer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitTrailer.
+func (in *CommitTrailer) DeepCopy() *CommitTrailer {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitTrailer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultMetadata) DeepCopyInto(out *DefaultMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultMetadata.
+func (in *DefaultMetadata) DeepCopy() *DefaultMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FunctionEval) DeepCopyInto(out *FunctionEval) {
 	*out = *in
@@ -70,6 +129,7 @@ func (in *FunctionRef) DeepCopy() *FunctionRef {
 func (in *GitRepository) DeepCopyInto(out *GitRepository) {
 	*out = *in
 	out.SecretRef = in.SecretRef
+	out.WebhookSecretRef = in.WebhookSecretRef
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepository.
@@ -157,6 +217,27 @@ func (in *RepositoryList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryPruneStatus) DeepCopyInto(out *RepositoryPruneStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Refs != nil {
+		in, out := &in.Refs, &out.Refs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryPruneStatus.
+func (in *RepositoryPruneStatus) DeepCopy() *RepositoryPruneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryPruneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepositoryRef) DeepCopyInto(out *RepositoryRef) {
 	*out = *in
@@ -172,6 +253,52 @@ func (in *RepositoryRef) DeepCopy() *RepositoryRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryRetentionStatus) DeepCopyInto(out *RepositoryRetentionStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryRetentionStatus.
+func (in *RepositoryRetentionStatus) DeepCopy() *RepositoryRetentionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryRetentionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositorySharedWith) DeepCopyInto(out *RepositorySharedWith) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositorySharedWith.
+func (in *RepositorySharedWith) DeepCopy() *RepositorySharedWith {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositorySharedWith)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 	*out = *in
@@ -204,6 +331,32 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+
+	if in.AllowedUpstreams != nil {
+		in, out := &in.AllowedUpstreams, &out.AllowedUpstreams
+		*out = make([]AllowedUpstream, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedWith != nil {
+		in, out := &in.SharedWith, &out.SharedWith
+		*out = new(RepositorySharedWith)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultMetadata != nil {
+		in, out := &in.DefaultMetadata, &out.DefaultMetadata
+		*out = new(DefaultMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommitTrailers != nil {
+		in, out := &in.CommitTrailers, &out.CommitTrailers
+		*out = make([]CommitTrailer, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositorySpec.
@@ -226,6 +379,21 @@ func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(RepositoryPruneStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RepositoryRetentionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(RepositoryWebhookStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatus.
@@ -238,6 +406,41 @@ func (in *RepositoryStatus) DeepCopy() *RepositoryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryWebhookStatus) DeepCopyInto(out *RepositoryWebhookStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryWebhookStatus.
+func (in *RepositoryWebhookStatus) DeepCopy() *RepositoryWebhookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryWebhookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicy) DeepCopyInto(out *RetentionPolicy) {
+	*out = *in
+	if in.MinAge != nil {
+		in, out := &in.MinAge, &out.MinAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicy.
+func (in *RetentionPolicy) DeepCopy() *RetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
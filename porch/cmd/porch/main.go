@@ -23,13 +23,17 @@ import (
 	"strings"
 
 	"github.com/GoogleContainerTools/kpt/porch/pkg/cmd/server"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
 	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
 	genericapiserver "k8s.io/apiserver/pkg/server"
@@ -70,18 +74,28 @@ func (t *telemetry) Start() error {
 		return nil
 	}
 
+	// set global propagator to tracecontext (the default is no-op), so traces of
+	// clients continue through the aggregated apiserver.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
 	if config == "stdout" {
-		exportOpts := []stdout.Option{
-			stdout.WithPrettyPrint(),
-		}
-		// Registers both a trace and meter Provider globally.
-		tracerProvider, pusher, err := stdout.InstallNewPipeline(exportOpts, nil)
+		exporter, err := stdout.NewExporter(stdout.WithPrettyPrint())
 		if err != nil {
 			return fmt.Errorf("error initializing stdout exporter: %w", err)
 		}
+		pusher := basic.New(
+			processor.New(simple.NewWithInexpensiveDistribution(), exporter),
+			basic.WithExporter(exporter),
+		)
+		if err := pusher.Start(context.Background()); err != nil {
+			return fmt.Errorf("error initializing stdout exporter: %w", err)
+		}
 
-		t.tp = tracerProvider
+		// Registers both a trace and meter Provider globally.
+		t.tp = newTracerProvider(exporter)
 		t.pusher = pusher
+		otel.SetTracerProvider(t.tp)
+		global.SetMeterProvider(pusher.MeterProvider())
 		return nil
 	}
 
@@ -105,24 +119,35 @@ func (t *telemetry) Start() error {
 			otlpgrpc.WithDialOption(grpc.WithBlock()), // useful for testing
 		)
 
-		// set global propagator to tracecontext (the default is no-op).
-		otel.SetTextMapPropagator(propagation.TraceContext{})
-
-		// Registers both a trace and meter Provider globally.
-		exporter, tracerProvider, pusher, err := otlp.InstallNewPipeline(ctx, driver)
+		exporter, err := otlp.NewExporter(ctx, driver)
 		if err != nil {
 			return fmt.Errorf("error initializing otel exporter: %w", err)
 		}
+		pusher := basic.New(processor.New(simple.NewWithInexpensiveDistribution(), exporter))
+		if err := pusher.Start(ctx); err != nil {
+			return fmt.Errorf("error initializing otel exporter: %w", err)
+		}
 
-		t.tp = tracerProvider
+		// Registers the trace Provider globally.
+		t.tp = newTracerProvider(exporter)
 		t.pusher = pusher
 		t.exporter = exporter
+		otel.SetTracerProvider(t.tp)
 		return nil
 	}
 
 	return fmt.Errorf("unknown OTEL configuration %q", config)
 }
 
+// newTracerProvider returns a tracer provider exporting the traces sampled by
+// engine.DefaultTraceSampler, which samples reads at the rate of the server flags.
+func newTracerProvider(exporter trace.SpanExporter) *trace.TracerProvider {
+	return trace.NewTracerProvider(
+		trace.WithBatcher(exporter),
+		trace.WithSampler(engine.DefaultTraceSampler),
+	)
+}
+
 func (t *telemetry) Stop() {
 	if t.pusher != nil {
 		if err := t.pusher.Stop(context.Background()); err != nil {
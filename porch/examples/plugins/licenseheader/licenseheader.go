@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package licenseheader is an example of a custom task handler of the Porch engine. Its
+// task adds a license header to the YAML files of a package:
+//
+//	engine.NewCaDEngine(
+//		engine.WithTaskHandlers(map[api.TaskType]engine.MutationFactory{
+//			licenseheader.TaskType: licenseheader.NewMutationFactory("Copyright 2022 Example Inc."),
+//		}),
+//		...
+//	)
+//
+// and is added to the tasks of a package revision as
+//
+//	tasks:
+//	- type: license-header
+package licenseheader
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// TaskType is the type of the tasks adding the license header.
+const TaskType api.TaskType = "license-header"
+
+// SkipAnnotation, when set to "true" on a package revision, rejects license-header tasks,
+// for packages which carry the license of their upstream.
+const SkipAnnotation = "licenseheader.example.com/skip"
+
+// NewMutationFactory returns the handler of license-header tasks adding header, which
+// may have multiple lines, as a comment.
+func NewMutationFactory(header string) engine.MutationFactory {
+	var comment strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+		comment.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	return func(ctx context.Context, obj *api.PackageRevision, task *api.Task) (engine.Mutation, error) {
+		if strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("no license header is configured")
+		}
+		if obj.Annotations[SkipAnnotation] == "true" {
+			return nil, fmt.Errorf("package %q is annotated with %s", obj.Spec.PackageName, SkipAnnotation)
+		}
+		return &mutation{comment: comment.String()}, nil
+	}
+}
+
+type mutation struct {
+	comment string
+}
+
+var _ engine.Mutation = &mutation{}
+
+func (m *mutation) Name() string {
+	return "license-header"
+}
+
+func (m *mutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	contents := make(map[string]string, len(resources.Contents))
+	for name, data := range resources.Contents {
+		if ext := path.Ext(name); (ext == ".yaml" || ext == ".yml") && !strings.HasPrefix(data, m.comment) {
+			data = m.comment + data
+		}
+		contents[name] = data
+	}
+	return repository.PackageResources{Contents: contents}, nil, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package licenseheader
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLicenseHeader(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMutationFactory("Copyright 2022 Example Inc.\n\nAll rights reserved.")
+	obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "app"}}
+	task := &api.Task{Type: TaskType}
+
+	m, err := factory(ctx, obj, task)
+	if err != nil {
+		t.Fatalf("factory failed: %v", err)
+	}
+	const header = "# Copyright 2022 Example Inc.\n#\n# All rights reserved.\n"
+	resources := repository.PackageResources{Contents: map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\n",
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\n",
+		"service.yaml":   header + "apiVersion: v1\nkind: Service\n",
+		"README.md":      "# app\n",
+	}}
+
+	applied, recorded, err := m.Apply(ctx, resources)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if recorded != nil {
+		t.Errorf("mutation recorded task %v; want the engine to record its task", recorded)
+	}
+	want := map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\n",
+		"configmap.yaml": header + "apiVersion: v1\nkind: ConfigMap\n",
+		"service.yaml":   header + "apiVersion: v1\nkind: Service\n",
+		"README.md":      "# app\n",
+	}
+	if diff := cmp.Diff(want, applied.Contents); diff != "" {
+		t.Errorf("unexpected resources (-want, +got): %s", diff)
+	}
+
+	skipped := obj.DeepCopy()
+	skipped.Annotations = map[string]string{SkipAnnotation: "true"}
+	if _, err := factory(ctx, skipped, task); err == nil {
+		t.Errorf("task of package annotated with %s is valid", SkipAnnotation)
+	}
+	if _, err := NewMutationFactory(" ")(ctx, obj, task); err == nil {
+		t.Errorf("task without a configured header is valid")
+	}
+}
@@ -16,6 +16,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 //+kubebuilder:object:root=true
@@ -33,10 +34,137 @@ type PackageRev struct {
 
 // PackageRevSpec defines the desired state of PackageRev
 type PackageRevSpec struct {
+	// Archived is true if the package of the package revision is archived.
+	Archived bool `json:"archived,omitempty"`
+
+	// Documents are structured metadata documents attached to the package revision,
+	// by key. They are not part of the package resources.
+	Documents map[string]runtime.RawExtension `json:"documents,omitempty"`
+
+	// Freeze is the freeze of the package of the package revision, if it is frozen.
+	Freeze *PackageFreeze `json:"freeze,omitempty"`
+
+	// PackageMetadata is the metadata of the package of the package revision set
+	// through the Package.
+	PackageMetadata *PackageMetadata `json:"packageMetadata,omitempty"`
+}
+
+// PackageMetadata is the metadata of a package: its labels, annotations and
+// description.
+type PackageMetadata struct {
+	// Labels are the labels of the package.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the annotations of the package.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Description is the description of the package.
+	Description string `json:"description,omitempty"`
+	// UpdatedAt is when the metadata was last updated.
+	UpdatedAt metav1.Time `json:"updatedAt,omitempty"`
+}
+
+// PackageFreeze is the freeze of a package.
+type PackageFreeze struct {
+	// Reason is why the package is frozen.
+	Reason string `json:"reason"`
+	// FrozenBy is the user who froze the package.
+	FrozenBy string `json:"frozenBy,omitempty"`
+	// FrozenAt is when the package was frozen.
+	FrozenAt metav1.Time `json:"frozenAt,omitempty"`
 }
 
 // PackageRevStatus defines the observed state of PackageRev
 type PackageRevStatus struct {
+	// Artifacts are the artifacts exported from the package revision when it was published.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// DraftCreatedAt is the time when the package revision was created as a draft.
+	DraftCreatedAt *metav1.Time `json:"draftCreatedAt,omitempty"`
+	// ProposedAt is the time when the package revision was last proposed.
+	ProposedAt *metav1.Time `json:"proposedAt,omitempty"`
+	// ProposedBy is the user who last proposed the package revision.
+	ProposedBy string `json:"proposedBy,omitempty"`
+	// PublishedAt is the time when the package revision was published.
+	PublishedAt *metav1.Time `json:"publishedAt,omitempty"`
+
+	// Deployment is the state of the package revision last reported by the agent
+	// deploying it.
+	Deployment *DeploymentStatus `json:"deployment,omitempty"`
+
+	// TaskResults are the results of the tasks applied by the last create or update of
+	// the package revision.
+	TaskResults []TaskResult `json:"taskResults,omitempty"`
+
+	// Deletions are the deleted package revisions of the package of the package
+	// revision. They are recorded in the metadata of the package revisions of the
+	// package which remain.
+	Deletions []RevisionDeletion `json:"deletions,omitempty"`
+
+	// ConditionChanges are the changes of the conditions of the package revision, in the
+	// order they were made.
+	ConditionChanges []ConditionChange `json:"conditionChanges,omitempty"`
+}
+
+// ConditionChange records a change of a condition of a package revision.
+type ConditionChange struct {
+	// Type is the type of the condition.
+	Type string `json:"type"`
+	// Status is the status the condition changed to.
+	Status string `json:"status"`
+	// ChangedBy is the user who changed the condition.
+	ChangedBy string `json:"changedBy,omitempty"`
+	// ChangedAt is when the condition changed.
+	ChangedAt metav1.Time `json:"changedAt"`
+}
+
+// RevisionDeletion records the deletion of a package revision.
+type RevisionDeletion struct {
+	// Name is the name of the deleted package revision.
+	Name string `json:"name"`
+	// Revision is the revision of the deleted package revision.
+	Revision string `json:"revision,omitempty"`
+	// DeletedBy is the user who deleted the package revision.
+	DeletedBy string `json:"deletedBy,omitempty"`
+	// DeletedAt is when the package revision was deleted.
+	DeletedAt metav1.Time `json:"deletedAt"`
+}
+
+// DeploymentStatus is the state of a package revision observed in the cluster it is
+// deployed to.
+type DeploymentStatus struct {
+	Cluster        string      `json:"cluster"`
+	ObservedCommit string      `json:"observedCommit,omitempty"`
+	SyncStatus     string      `json:"syncStatus"`
+	SyncedTime     metav1.Time `json:"syncedTime,omitempty"`
+	ReportedTime   metav1.Time `json:"reportedTime,omitempty"`
+}
+
+// TaskResult is the result of a task applied to a package revision.
+type TaskResult struct {
+	Task      int              `json:"task"`
+	Type      string           `json:"type"`
+	Duration  metav1.Duration  `json:"duration"`
+	StartedAt metav1.Time      `json:"startedAt,omitempty"`
+	Added     []string         `json:"added,omitempty"`
+	Changed   []string         `json:"changed,omitempty"`
+	Deleted   []string         `json:"deleted,omitempty"`
+	Results   []FunctionResult `json:"results,omitempty"`
+}
+
+// FunctionResult is a result reported by a function run by a task.
+type FunctionResult struct {
+	Function string `json:"function,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Field    string `json:"field,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
+// Artifact is an artifact exported from a package revision.
+type Artifact struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Digest string `json:"digest,omitempty"`
 }
 
 //+kubebuilder:object:root=true
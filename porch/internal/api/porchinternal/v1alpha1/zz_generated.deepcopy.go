@@ -23,13 +23,122 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Artifact) DeepCopyInto(out *Artifact) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Artifact.
+func (in *Artifact) DeepCopy() *Artifact {
+	if in == nil {
+		return nil
+	}
+	out := new(Artifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionChange) DeepCopyInto(out *ConditionChange) {
+	*out = *in
+	in.ChangedAt.DeepCopyInto(&out.ChangedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionChange.
+func (in *ConditionChange) DeepCopy() *ConditionChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+	*out = *in
+	in.SyncedTime.DeepCopyInto(&out.SyncedTime)
+	in.ReportedTime.DeepCopyInto(&out.ReportedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
+func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionResult) DeepCopyInto(out *FunctionResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionResult.
+func (in *FunctionResult) DeepCopy() *FunctionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageFreeze) DeepCopyInto(out *PackageFreeze) {
+	*out = *in
+	in.FrozenAt.DeepCopyInto(&out.FrozenAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageFreeze.
+func (in *PackageFreeze) DeepCopy() *PackageFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageMetadata) DeepCopyInto(out *PackageMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.UpdatedAt.DeepCopyInto(&out.UpdatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageMetadata.
+func (in *PackageMetadata) DeepCopy() *PackageMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRev) DeepCopyInto(out *PackageRev) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRev.
@@ -85,6 +194,23 @@ func (in *PackageRevList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRevSpec) DeepCopyInto(out *PackageRevSpec) {
 	*out = *in
+	if in.Documents != nil {
+		in, out := &in.Documents, &out.Documents
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Freeze != nil {
+		in, out := &in.Freeze, &out.Freeze
+		*out = new(PackageFreeze)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PackageMetadata != nil {
+		in, out := &in.PackageMetadata, &out.PackageMetadata
+		*out = new(PackageMetadata)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevSpec.
@@ -100,6 +226,49 @@ func (in *PackageRevSpec) DeepCopy() *PackageRevSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageRevStatus) DeepCopyInto(out *PackageRevStatus) {
 	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]Artifact, len(*in))
+		copy(*out, *in)
+	}
+	if in.DraftCreatedAt != nil {
+		in, out := &in.DraftCreatedAt, &out.DraftCreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ProposedAt != nil {
+		in, out := &in.ProposedAt, &out.ProposedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PublishedAt != nil {
+		in, out := &in.PublishedAt, &out.PublishedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(DeploymentStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TaskResults != nil {
+		in, out := &in.TaskResults, &out.TaskResults
+		*out = make([]TaskResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deletions != nil {
+		in, out := &in.Deletions, &out.Deletions
+		*out = make([]RevisionDeletion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConditionChanges != nil {
+		in, out := &in.ConditionChanges, &out.ConditionChanges
+		*out = make([]ConditionChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevStatus.
@@ -111,3 +280,56 @@ func (in *PackageRevStatus) DeepCopy() *PackageRevStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionDeletion) DeepCopyInto(out *RevisionDeletion) {
+	*out = *in
+	in.DeletedAt.DeepCopyInto(&out.DeletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionDeletion.
+func (in *RevisionDeletion) DeepCopy() *RevisionDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskResult) DeepCopyInto(out *TaskResult) {
+	*out = *in
+	out.Duration = in.Duration
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Changed != nil {
+		in, out := &in.Changed, &out.Changed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deleted != nil {
+		in, out := &in.Deleted, &out.Deleted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]FunctionResult, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskResult.
+func (in *TaskResult) DeepCopy() *TaskResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskResult)
+	in.DeepCopyInto(out)
+	return out
+}
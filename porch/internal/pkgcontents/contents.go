@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkgcontents manipulates the contents of packages: the files of a package by
+// slash-separated path relative to the root of the package, as held by
+// repository.PackageResources.
+//
+// Mutations should use it rather than manipulating the files directly, so that the
+// Kptfile, resources and comments are handled the same way everywhere.
+package pkgcontents
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+)
+
+// Contents are the files of a package by slash-separated path.
+type Contents map[string]string
+
+// FileKind classifies the files of a package.
+type FileKind string
+
+const (
+	// KRM files are YAML files and Kptfiles, which hold KRM resources.
+	KRM FileKind = "KRM"
+	// NonKRM files are all other files, which are passed through as they are.
+	NonKRM FileKind = "NonKRM"
+)
+
+// Classify returns the kind of the file at the path.
+func Classify(p string) FileKind {
+	base := path.Base(p)
+	// TODO: use authoritative kpt filtering
+	if ext := path.Ext(base); ext == ".yaml" || ext == ".yml" || base == kptfile.KptFileName {
+		return KRM
+	}
+	return NonKRM
+}
+
+// Paths returns the sorted paths of the files.
+func (c Contents) Paths() []string {
+	paths := make([]string, 0, len(c))
+	for p := range c {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WalkFunc is called by Walk for each file.
+type WalkFunc func(path string, kind FileKind, contents string) error
+
+// Walk calls fn for each file in the order of their paths, and stops at the first error.
+func (c Contents) Walk(fn WalkFunc) error {
+	for _, p := range c.Paths() {
+		if err := fn(p, Classify(p), c[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeletePath deletes the file at the path, or all files of the directory at the path,
+// and returns the sorted paths of the deleted files.
+func (c Contents) DeletePath(p string) []string {
+	p = strings.TrimSuffix(path.Clean(p), "/")
+	var deleted []string
+	for _, name := range c.Paths() {
+		if name == p || p == "." || strings.HasPrefix(name, p+"/") {
+			delete(c, name)
+			deleted = append(deleted, name)
+		}
+	}
+	return deleted
+}
+
+// Changes are the paths of the files changed from one version of package contents to
+// another, each sorted.
+type Changes struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// Diff returns the files changed from old to new.
+func Diff(old, new Contents) Changes {
+	var changes Changes
+	for _, p := range new.Paths() {
+		if oldContents, found := old[p]; !found {
+			changes.Added = append(changes.Added, p)
+		} else if oldContents != new[p] {
+			changes.Modified = append(changes.Modified, p)
+		}
+	}
+	for _, p := range old.Paths() {
+		if _, found := new[p]; !found {
+			changes.Deleted = append(changes.Deleted, p)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcontents
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWalk(t *testing.T) {
+	contents := Contents{
+		"README.md":        "# app\n",
+		"Kptfile":          "apiVersion: kpt.dev/v1\nkind: Kptfile\n",
+		"sub/Kptfile":      "apiVersion: kpt.dev/v1\nkind: Kptfile\n",
+		"sub/service.yml":  "apiVersion: v1\nkind: Service\n",
+		"deployment.yaml":  "apiVersion: apps/v1\nkind: Deployment\n",
+		"scripts/setup.sh": "#!/bin/sh\n",
+	}
+	type file struct {
+		Path string
+		Kind FileKind
+	}
+	var got []file
+	if err := contents.Walk(func(path string, kind FileKind, _ string) error {
+		got = append(got, file{path, kind})
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	want := []file{
+		{"Kptfile", KRM},
+		{"README.md", NonKRM},
+		{"deployment.yaml", KRM},
+		{"scripts/setup.sh", NonKRM},
+		{"sub/Kptfile", KRM},
+		{"sub/service.yml", KRM},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected walk (-want, +got): %s", diff)
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	for _, tc := range []struct {
+		path        string
+		wantDeleted []string
+		wantLeft    []string
+	}{
+		{path: "sub/Kptfile", wantDeleted: []string{"sub/Kptfile"}, wantLeft: []string{"Kptfile", "sub/a.yaml", "sub/b/c.yaml", "subway.yaml"}},
+		{path: "sub", wantDeleted: []string{"sub/Kptfile", "sub/a.yaml", "sub/b/c.yaml"}, wantLeft: []string{"Kptfile", "subway.yaml"}},
+		{path: "sub/b/", wantDeleted: []string{"sub/b/c.yaml"}, wantLeft: []string{"Kptfile", "sub/Kptfile", "sub/a.yaml", "subway.yaml"}},
+		{path: "missing.yaml", wantLeft: []string{"Kptfile", "sub/Kptfile", "sub/a.yaml", "sub/b/c.yaml", "subway.yaml"}},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			contents := Contents{"Kptfile": "", "sub/Kptfile": "", "sub/a.yaml": "", "sub/b/c.yaml": "", "subway.yaml": ""}
+			if diff := cmp.Diff(tc.wantDeleted, contents.DeletePath(tc.path)); diff != "" {
+				t.Errorf("unexpected deleted files (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLeft, contents.Paths()); diff != "" {
+				t.Errorf("unexpected files left (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Contents{"Kptfile": "kf", "a.yaml": "a", "b.yaml": "b", "c.yaml": "c"}
+	new := Contents{"Kptfile": "kf", "a.yaml": "a2", "c.yaml": "c", "d.yaml": "d", "b/e.yaml": "e"}
+	want := Changes{
+		Added:    []string{"b/e.yaml", "d.yaml"},
+		Modified: []string{"a.yaml"},
+		Deleted:  []string{"b.yaml"},
+	}
+	if diff := cmp.Diff(want, Diff(old, new)); diff != "" {
+		t.Errorf("unexpected changes (-want, +got): %s", diff)
+	}
+}
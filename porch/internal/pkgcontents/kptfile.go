@@ -0,0 +1,58 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcontents
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// GetKptfile returns the root Kptfile of the package.
+func (c Contents) GetKptfile() (kptfile.KptFile, error) {
+	kfString, found := c[kptfile.KptFileName]
+	if !found {
+		return kptfile.KptFile{}, fmt.Errorf("packagerevision does not have a Kptfile")
+	}
+	kf, err := internalpkg.DecodeKptfile(strings.NewReader(kfString))
+	if err != nil {
+		return kptfile.KptFile{}, fmt.Errorf("error decoding Kptfile: %w", err)
+	}
+	return *kf, nil
+}
+
+// SetKptfile replaces the root Kptfile of the package. The Kptfile is encoded from its
+// fields, so comments of the replaced Kptfile are lost.
+func (c Contents) SetKptfile(kf kptfile.KptFile) error {
+	encoded, err := EncodeKptfile(kf)
+	if err != nil {
+		return err
+	}
+	c[kptfile.KptFileName] = encoded
+	return nil
+}
+
+// EncodeKptfile returns the Kptfile encoded as YAML.
+func EncodeKptfile(kf kptfile.KptFile) (string, error) {
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(kf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
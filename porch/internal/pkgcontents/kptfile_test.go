@@ -0,0 +1,64 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcontents
+
+import (
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKptfile(t *testing.T) {
+	contents := Contents{"README.md": "# app\n"}
+	if _, err := contents.GetKptfile(); err == nil {
+		t.Errorf("GetKptfile of a package without a Kptfile succeeded")
+	}
+
+	contents[kptfile.KptFileName] = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+info:
+  description: The app
+`
+	kf, err := contents.GetKptfile()
+	if err != nil {
+		t.Fatalf("GetKptfile failed: %v", err)
+	}
+	if got, want := kf.Name, "app"; got != want {
+		t.Errorf("Kptfile name: got %q, want %q", got, want)
+	}
+
+	kf.Info.ReadinessGates = []kptfile.ReadinessGate{{ConditionType: "Ready"}}
+	if err := contents.SetKptfile(kf); err != nil {
+		t.Fatalf("SetKptfile failed: %v", err)
+	}
+	want := `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+info:
+  description: The app
+  readinessGates:
+  - conditionType: Ready
+`
+	if diff := cmp.Diff(want, contents[kptfile.KptFileName]); diff != "" {
+		t.Errorf("unexpected Kptfile (-want, +got): %s", diff)
+	}
+	if got, want := contents["README.md"], "# app\n"; got != want {
+		t.Errorf("SetKptfile changed another file: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcontents
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/comments"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Reader reads the KRM resources of package contents, annotated with the paths of their
+// files. The files which aren't KRM files are collected in Other, if it is set.
+type Reader struct {
+	Contents Contents
+	Other    Contents
+}
+
+var _ kio.Reader = &Reader{}
+
+func (r *Reader) Read() ([]*yaml.RNode, error) {
+	results := []*yaml.RNode{}
+	for k, v := range r.Contents {
+		if Classify(k) != KRM {
+			if r.Other != nil {
+				r.Other[k] = v
+			}
+			continue
+		}
+		nodes, err := readFile(k, v)
+		if err != nil {
+			// TODO: fail, or bypass this file too?
+			return nil, err
+		}
+		results = append(results, nodes...)
+	}
+	return results, nil
+}
+
+// Writer writes KRM resources to Contents, into the files of their path annotations.
+// Resources without a path annotation are written to <namespace>/<name>.yaml.
+type Writer struct {
+	Contents Contents
+}
+
+var _ kio.Writer = &Writer{}
+
+func (w *Writer) Write(nodes []*yaml.RNode) error {
+	paths := map[string][]*yaml.RNode{}
+	for _, node := range nodes {
+		path := PathOf(node)
+		paths[path] = append(paths[path], node)
+	}
+	for path, nodes := range paths {
+		contents, err := writeFile(nodes)
+		if err != nil {
+			return err
+		}
+		w.Contents[path] = contents
+	}
+	return nil
+}
+
+// PathOf returns the path of the file of a resource: its path annotation, or
+// <namespace>/<name>.yaml if it has none.
+func PathOf(node *yaml.RNode) string {
+	ann := node.GetAnnotations()
+	if path, ok := ann[kioutil.PathAnnotation]; ok {
+		return path
+	}
+	ns := node.GetNamespace()
+	if ns == "" {
+		ns = "non-namespaced"
+	}
+	name := node.GetName()
+	if name == "" {
+		name = "unnamed"
+	}
+	// TODO: harden for escaping etc.
+	return path.Join(ns, fmt.Sprintf("%s.yaml", name))
+}
+
+// readFile reads the resources of a KRM file, annotated with its path.
+func readFile(p, contents string) ([]*yaml.RNode, error) {
+	return (&kio.ByteReader{
+		Reader: strings.NewReader(contents),
+		SetAnnotations: map[string]string{
+			kioutil.PathAnnotation: p,
+		},
+		DisableUnwrapping: true,
+	}).Read()
+}
+
+// writeFile serializes the resources of a KRM file, without the annotations set by
+// readFile.
+func writeFile(nodes []*yaml.RNode) (string, error) {
+	var buf bytes.Buffer
+	bw := kio.ByteWriter{
+		Writer: &buf,
+		ClearAnnotations: []string{
+			kioutil.PathAnnotation,
+			kioutil.LegacyPathAnnotation,
+		},
+	}
+	if err := bw.Write(nodes); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Resource is a KRM resource of a package.
+type Resource struct {
+	// Path is the path of the file of the resource.
+	Path string
+	// Node is the resource, annotated with the path of its file.
+	Node *yaml.RNode
+}
+
+// FindResources returns the resources with the group, version and kind, and the name,
+// in the order of their files. Empty fields of gvk and an empty name match any value.
+// Files which can't be parsed hold no resources to find and are skipped.
+func (c Contents) FindResources(gvk schema.GroupVersionKind, name string) []Resource {
+	var found []Resource
+	for _, p := range c.Paths() {
+		if Classify(p) != KRM {
+			continue
+		}
+		nodes, err := readFile(p, c[p])
+		if err != nil {
+			continue
+		}
+		for _, node := range nodes {
+			if matches(node, gvk, name) {
+				found = append(found, Resource{Path: p, Node: node})
+			}
+		}
+	}
+	return found
+}
+
+func matches(node *yaml.RNode, gvk schema.GroupVersionKind, name string) bool {
+	nodeGVK := schema.FromAPIVersionAndKind(node.GetApiVersion(), node.GetKind())
+	switch {
+	case gvk.Group != "" && gvk.Group != nodeGVK.Group:
+		return false
+	case gvk.Version != "" && gvk.Version != nodeGVK.Version:
+		return false
+	case gvk.Kind != "" && gvk.Kind != nodeGVK.Kind:
+		return false
+	case name != "" && name != node.GetName():
+		return false
+	}
+	return true
+}
+
+// sameResource returns true if a and b have the same apiVersion, kind, namespace and name.
+func sameResource(a, b *yaml.RNode) bool {
+	return a.GetNamespace() == b.GetNamespace() &&
+		a.GetName() == b.GetName() &&
+		a.GetApiVersion() == b.GetApiVersion() &&
+		a.GetKind() == b.GetKind()
+}
+
+// UpsertResource replaces the resource of the KRM file at the path which has the same
+// apiVersion, kind, namespace and name as obj, or appends obj to the file if it has no
+// such resource. The other resources of the file keep their order and comments. If
+// preserveComments is set, the comments of the replaced resource are copied to obj.
+func (c Contents) UpsertResource(p string, obj *yaml.RNode, preserveComments bool) error {
+	if Classify(p) != KRM {
+		return fmt.Errorf("cannot add resource to %s, which isn't a KRM file", p)
+	}
+	obj = obj.Copy()
+	var nodes []*yaml.RNode
+	if existing, found := c[p]; found {
+		var err error
+		if nodes, err = readFile(p, existing); err != nil {
+			return fmt.Errorf("cannot parse %s: %w", p, err)
+		}
+	}
+
+	replaced := false
+	for i, node := range nodes {
+		if !sameResource(node, obj) {
+			continue
+		}
+		if preserveComments {
+			if err := comments.CopyComments(node, obj); err != nil {
+				return err
+			}
+		}
+		nodes[i] = obj
+		replaced = true
+		break
+	}
+	if !replaced {
+		nodes = append(nodes, obj)
+	}
+
+	contents, err := writeFile(nodes)
+	if err != nil {
+		return err
+	}
+	c[p] = contents
+	return nil
+}
+
+// CopyComments returns the updated contents with the comments of the resources of the
+// original contents copied to the resources of the updated contents with the same
+// apiVersion, kind, namespace and name. It restores the comments clients drop when they
+// edit resources without preserving them.
+func CopyComments(original, updated Contents) (Contents, error) {
+	originalResources, err := (&Reader{Contents: original}).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read old packge resources: %w", err)
+	}
+
+	var filter kio.FilterFunc = func(r []*yaml.RNode) ([]*yaml.RNode, error) {
+		for _, n := range r {
+			for _, original := range originalResources {
+				if sameResource(n, original) {
+					comments.CopyComments(original, n)
+				}
+			}
+		}
+		return r, nil
+	}
+
+	other := Contents{}
+	result := Contents{}
+	if err := (kio.Pipeline{
+		Inputs:                []kio.Reader{&Reader{Contents: updated, Other: other}},
+		Filters:               []kio.Filter{filter},
+		Outputs:               []kio.Writer{&Writer{Contents: result}},
+		ContinueOnEmptyResult: true,
+	}).Execute(); err != nil {
+		return nil, err
+	}
+	for k, v := range other {
+		result[k] = v
+	}
+	return result, nil
+}
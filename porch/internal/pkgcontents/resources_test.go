@@ -0,0 +1,194 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgcontents
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const updateGoldenFiles = "UPDATE_GOLDEN_FILES"
+
+// checkGolden compares got to the golden file, which it updates first if
+// UPDATE_GOLDEN_FILES is set.
+func checkGolden(t *testing.T, golden, got string) {
+	t.Helper()
+	if os.Getenv(updateGoldenFiles) != "" {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), got); diff != "" {
+		t.Errorf("Unexpected contents of %s (-want, +got): %s", golden, diff)
+	}
+}
+
+func readInput(t *testing.T) Contents {
+	t.Helper()
+	input, err := os.ReadFile(filepath.Join("testdata", "upsert", "input.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read input: %v", err)
+	}
+	return Contents{"app.yaml": string(input), "README.md": "# app\n"}
+}
+
+func TestUpsertResource(t *testing.T) {
+	// The settings without their comments, as returned by a client which doesn't
+	// preserve them, with a new value.
+	settings := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: app
+data:
+  replicas: "3"
+  list:
+  - a
+  - b
+`)
+
+	for _, tc := range []struct {
+		name             string
+		path             string
+		obj              *yaml.RNode
+		preserveComments bool
+		golden           string
+	}{
+		{name: "preserve comments", path: "app.yaml", obj: settings, preserveComments: true, golden: "replaced.yaml"},
+		{name: "drop comments", path: "app.yaml", obj: settings, golden: "replaced-without-comments.yaml"},
+		{name: "append", path: "app.yaml", obj: yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other # new\n  namespace: app\n"), golden: "appended.yaml"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			contents := readInput(t)
+			if err := contents.UpsertResource(tc.path, tc.obj, tc.preserveComments); err != nil {
+				t.Fatalf("UpsertResource failed: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", "upsert", tc.golden), contents[tc.path])
+			if got, want := contents["README.md"], "# app\n"; got != want {
+				t.Errorf("UpsertResource changed another file: got %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		contents := readInput(t)
+		input := contents["app.yaml"]
+		found := contents.FindResources(schema.GroupVersionKind{Kind: "Service"}, "app")
+		if len(found) != 1 {
+			t.Fatalf("found %d services; want 1", len(found))
+		}
+		if err := contents.UpsertResource("app.yaml", found[0].Node, true); err != nil {
+			t.Fatalf("UpsertResource failed: %v", err)
+		}
+		if diff := cmp.Diff(input, contents["app.yaml"]); diff != "" {
+			t.Errorf("upserting an unchanged resource changed the file (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("new file", func(t *testing.T) {
+		contents := readInput(t)
+		if err := contents.UpsertResource("sub/settings.yaml", settings, true); err != nil {
+			t.Fatalf("UpsertResource failed: %v", err)
+		}
+		if diff := cmp.Diff(settings.MustString(), contents["sub/settings.yaml"]); diff != "" {
+			t.Errorf("unexpected new file (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("non-KRM file", func(t *testing.T) {
+		contents := readInput(t)
+		if err := contents.UpsertResource("README.md", settings, true); err == nil {
+			t.Errorf("UpsertResource into a non-KRM file succeeded")
+		}
+	})
+}
+
+func TestFindResources(t *testing.T) {
+	contents := readInput(t)
+	contents["Kptfile"] = "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n"
+	contents["broken.yaml"] = "apiVersion: v1\nkind: [\n"
+	contents["sub/settings.yaml"] = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"
+
+	type resource struct{ Path, Kind, Name string }
+	find := func(gvk schema.GroupVersionKind, name string) []resource {
+		var got []resource
+		for _, r := range contents.FindResources(gvk, name) {
+			got = append(got, resource{r.Path, r.Node.GetKind(), r.Node.GetName()})
+		}
+		return got
+	}
+
+	for _, tc := range []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		obj  string
+		want []resource
+	}{
+		{name: "by kind and name", gvk: schema.GroupVersionKind{Kind: "ConfigMap"}, obj: "settings", want: []resource{{"app.yaml", "ConfigMap", "settings"}, {"sub/settings.yaml", "ConfigMap", "settings"}}},
+		{name: "by group", gvk: schema.GroupVersionKind{Group: "kpt.dev"}, want: []resource{{"Kptfile", "Kptfile", "app"}}},
+		{name: "by version", gvk: schema.GroupVersionKind{Version: "v1"}, obj: "app", want: []resource{{"Kptfile", "Kptfile", "app"}, {"app.yaml", "Service", "app"}}},
+		{name: "no match", gvk: schema.GroupVersionKind{Group: "apps", Kind: "ConfigMap"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, find(tc.gvk, tc.obj)); diff != "" {
+				t.Errorf("unexpected resources (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCopyComments(t *testing.T) {
+	original := readInput(t)
+	updated := Contents{
+		"README.md": "# app, updated\n",
+		"app.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: app
+data:
+  replicas: "3"
+  list:
+  - a
+  - b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: app
+spec:
+  ports:
+  - port: 80
+`,
+	}
+	healed, err := CopyComments(original, updated)
+	if err != nil {
+		t.Fatalf("CopyComments failed: %v", err)
+	}
+	checkGolden(t, filepath.Join("testdata", "upsert", "healed.yaml"), healed["app.yaml"])
+	if got, want := healed["README.md"], "# app, updated\n"; got != want {
+		t.Errorf("unexpected non-KRM file: got %q, want %q", got, want)
+	}
+}
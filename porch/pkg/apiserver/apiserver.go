@@ -17,6 +17,10 @@ package apiserver
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/install"
@@ -27,6 +31,7 @@ import (
 	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/registry/porch"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sts/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +44,7 @@ import (
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -73,6 +79,68 @@ type ExtraConfig struct {
 	CoreAPIKubeconfigPath string
 	CacheDirectory        string
 	FunctionRunnerAddress string
+	// StrictTaskValidation rejects package revisions with unknown fields in spec.tasks.
+	StrictTaskValidation bool
+	// RenderConcurrency is the maximum number of subpackages rendered concurrently.
+	RenderConcurrency int
+	// DeferRender stores the resources of drafts without rendering them until they are proposed or published.
+	DeferRender bool
+	// ForceRender renders packages after their tasks even if they have no pipeline.
+	ForceRender bool
+	// RenderChangeReport records which pipeline function changed which resources on every render task.
+	RenderChangeReport bool
+	// StrictTasks rejects package revisions whose tasks don't start with an init or clone task.
+	StrictTasks bool
+	// StampDeploymentNamespace sets the namespace of resources of packages cloned into deployment repositories.
+	StampDeploymentNamespace bool
+	// UpstreamFallback updates packages whose upstream revision was deleted from an earlier upstream revision.
+	UpstreamFallback bool
+	// AllowedFunctionImages restricts the function images package revisions can run.
+	AllowedFunctionImages []string
+	// RestrictExternalSources forbids package revisions from fetching from outside the registered repositories.
+	RestrictExternalSources bool
+	// FunctionSecretNamespaces are the namespaces whose eval tasks may reference Secrets in their function config.
+	FunctionSecretNamespaces []string
+	// EvalConflictPolicy resolves eval tasks setting the same resource field to different values.
+	EvalConflictPolicy string
+	// WorkspaceNameTemplate generates workspace names of package revisions created without a revision.
+	WorkspaceNameTemplate string
+	// DescriptionTemplate generates descriptions of packages created without one.
+	DescriptionTemplate string
+	// UpstreamVerificationKeys is the path to the public keys upstream packages must be signed with to be cloned.
+	UpstreamVerificationKeys string
+	// ProposalWebhook is the URL proposal events, with the diff of the proposal, are posted to.
+	ProposalWebhook string
+	// ProposalDiffBytes bounds the size of the diffs of proposal events.
+	ProposalDiffBytes int
+	// StarterResources is the directory of the starter resources init tasks can add to packages.
+	StarterResources string
+	// FluxArtifactRegistry is the registry path published package revisions are exported to as Flux OCI artifacts.
+	FluxArtifactRegistry string
+	// PhaseTimeouts limits the duration of the fetch, render and close phases of package revision operations.
+	PhaseTimeouts engine.PhaseTimeouts
+	// StagingTTL is how long the staged chunks of abandoned resumable uploads are kept.
+	StagingTTL time.Duration
+	// FunctionLogTTL is how long the logs of the functions run by the last create or update of package revisions are kept.
+	FunctionLogTTL time.Duration
+	// DraftBufferBytes is the package size up to which task contents are buffered in memory until the draft is closed.
+	DraftBufferBytes int64
+	// AutoProposeDelay is how long the readiness gates of drafts annotated for automatic proposal must stay true.
+	AutoProposeDelay time.Duration
+	// RenderToolchain is the version of the render toolchain recorded on new package revisions.
+	RenderToolchain string
+	// ToolchainFunctionRunners are the function runner addresses of earlier render toolchain versions, by version.
+	ToolchainFunctionRunners map[string]string
+	// TraceReadSampleRate is the rate at which the traces of reads are sampled.
+	TraceReadSampleRate float64
+	// Readiness decides when the server is ready, based on the state of its repositories.
+	Readiness ReadinessPolicy
+	// RepositorySyncWait is how long reads of a repository wait for its initial sync.
+	RepositorySyncWait time.Duration
+	// MetadataStoreBackend is where the metadata of package revisions is kept.
+	MetadataStoreBackend meta.Backend
+	// MigrateMetadataFrom is the backend the metadata of package revisions is copied from at startup, if any.
+	MigrateMetadataFrom meta.Backend
 }
 
 // Config defines the config for the apiserver
@@ -86,6 +154,7 @@ type PorchServer struct {
 	GenericAPIServer *genericapiserver.GenericAPIServer
 	coreClient       client.WithWatch
 	cache            *cache.Cache
+	cad              engine.CaDEngine
 }
 
 type completedConfig struct {
@@ -100,6 +169,11 @@ type CompletedConfig struct {
 
 // Complete fills in any fields not set that are required to have valid data. It's mutating the receiver.
 func (cfg *Config) Complete() CompletedConfig {
+	buildHandlerChain := cfg.GenericConfig.BuildHandlerChainFunc
+	cfg.GenericConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return withTraceContext(withRequestID(buildHandlerChain(apiHandler, c)))
+	}
+
 	c := completedConfig{
 		cfg.GenericConfig.Complete(),
 		&cfg.ExtraConfig,
@@ -204,7 +278,15 @@ func (c completedConfig) New() (*PorchServer, error) {
 		porch.NewGcloudWIResolver(coreV1Client, stsClient),
 	}
 
-	metadataStore := meta.NewCrdMetadataStore(coreClient)
+	metadataStore, err := meta.NewMetadataStore(c.ExtraConfig.MetadataStoreBackend, coreClient)
+	if err != nil {
+		return nil, err
+	}
+	if from := c.ExtraConfig.MigrateMetadataFrom; from != "" {
+		if err := migrateMetadata(context.Background(), coreClient, from, c.ExtraConfig.MetadataStoreBackend); err != nil {
+			return nil, fmt.Errorf("failed to migrate the metadata of package revisions: %w", err)
+		}
+	}
 
 	credentialResolver := porch.NewCredentialResolver(coreClient, resolverChain)
 	referenceResolver := porch.NewReferenceResolver(coreClient)
@@ -222,7 +304,23 @@ func (c completedConfig) New() (*PorchServer, error) {
 		UserInfoProvider:   userInfoProvider,
 		MetadataStore:      metadataStore,
 	})
-	cad, err := engine.NewCaDEngine(
+	// Staged chunks are kept on disk, so that uploads can be resumed after a restart.
+	stagingStore, err := staging.NewStore(filepath.Join(c.ExtraConfig.CacheDirectory, "staging"), c.ExtraConfig.StagingTTL)
+	if err != nil {
+		return nil, err
+	}
+	var upstreamVerifier engine.UpstreamVerifier
+	if path := c.ExtraConfig.UpstreamVerificationKeys; path != "" {
+		keys, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upstream verification keys: %w", err)
+		}
+		if upstreamVerifier, err = engine.NewSignatureVerifier(keys); err != nil {
+			return nil, fmt.Errorf("invalid upstream verification keys %s: %w", path, err)
+		}
+	}
+
+	engineOptions := []engine.EngineOption{
 		engine.WithCache(cache),
 		// The order of registering the function runtimes matters here. When
 		// evaluating a function, the runtimes will be tried in the same
@@ -232,9 +330,72 @@ func (c completedConfig) New() (*PorchServer, error) {
 		engine.WithCredentialResolver(credentialResolver),
 		engine.WithRenderer(renderer),
 		engine.WithReferenceResolver(referenceResolver),
+		engine.WithRepositoryLister(porch.NewRepositoryLister(coreClient)),
 		engine.WithUserInfoProvider(userInfoProvider),
 		engine.WithMetadataStore(metadataStore),
-	)
+		engine.WithRenderConcurrency(c.ExtraConfig.RenderConcurrency),
+		engine.WithEvalConflictPolicy(engine.EvalConflictPolicy(c.ExtraConfig.EvalConflictPolicy)),
+		engine.WithWorkspaceNameTemplate(c.ExtraConfig.WorkspaceNameTemplate),
+		engine.WithDescriptionTemplate(c.ExtraConfig.DescriptionTemplate),
+		engine.WithUpstreamVerifier(upstreamVerifier),
+		engine.WithPhaseTimeouts(c.ExtraConfig.PhaseTimeouts),
+		engine.WithStagingStore(stagingStore),
+		engine.WithAutoProposeDelay(c.ExtraConfig.AutoProposeDelay),
+		engine.WithRenderToolchain(c.ExtraConfig.RenderToolchain),
+		engine.WithTraceReadSampleRate(c.ExtraConfig.TraceReadSampleRate),
+		engine.WithRepositorySyncWait(c.ExtraConfig.RepositorySyncWait),
+		engine.WithProposalDiffBytes(c.ExtraConfig.ProposalDiffBytes),
+		engine.WithFunctionLogTTL(c.ExtraConfig.FunctionLogTTL),
+		engine.WithDraftBufferBytes(c.ExtraConfig.DraftBufferBytes),
+	}
+	for version, address := range c.ExtraConfig.ToolchainFunctionRunners {
+		engineOptions = append(engineOptions, engine.WithToolchainFunctionRunner(version, address))
+	}
+	if c.ExtraConfig.DeferRender {
+		engineOptions = append(engineOptions, engine.WithDeferredRender())
+	}
+	if c.ExtraConfig.ForceRender {
+		engineOptions = append(engineOptions, engine.WithForceRender())
+	}
+	if c.ExtraConfig.RenderChangeReport {
+		engineOptions = append(engineOptions, engine.WithRenderChangeReport())
+	}
+	if c.ExtraConfig.StrictTasks {
+		engineOptions = append(engineOptions, engine.WithStrictTasks())
+	}
+	if c.ExtraConfig.StrictTaskValidation {
+		engineOptions = append(engineOptions, engine.WithStrictTaskValidation())
+	}
+	if c.ExtraConfig.StampDeploymentNamespace {
+		engineOptions = append(engineOptions, engine.WithDeploymentNamespace())
+	}
+	if images := c.ExtraConfig.AllowedFunctionImages; len(images) != 0 {
+		engineOptions = append(engineOptions, engine.WithAllowedFunctionImages(images))
+	}
+	if c.ExtraConfig.RestrictExternalSources {
+		engineOptions = append(engineOptions, engine.WithRestrictedExternalSources())
+	}
+	if namespaces := c.ExtraConfig.FunctionSecretNamespaces; len(namespaces) != 0 {
+		engineOptions = append(engineOptions, engine.WithFunctionSecretNamespaces(namespaces))
+	}
+	if c.ExtraConfig.UpstreamFallback {
+		engineOptions = append(engineOptions, engine.WithUpstreamFallback())
+	}
+	if registry := c.ExtraConfig.FluxArtifactRegistry; registry != "" {
+		engineOptions = append(engineOptions, engine.WithPublishHook(engine.NewFluxArtifactExporter(registry)))
+	}
+	if url := c.ExtraConfig.ProposalWebhook; url != "" {
+		engineOptions = append(engineOptions, engine.WithProposeHook(engine.NewProposalWebhook(url)))
+	}
+	if dir := c.ExtraConfig.StarterResources; dir != "" {
+		starters, err := engine.LoadStarterResources(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read starter resources: %w", err)
+		}
+		engineOptions = append(engineOptions, engine.WithStarterResources(starters))
+	}
+
+	cad, err := engine.NewCaDEngine(engineOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -248,6 +409,7 @@ func (c completedConfig) New() (*PorchServer, error) {
 		GenericAPIServer: genericServer,
 		coreClient:       coreClient,
 		cache:            cache,
+		cad:              cad,
 	}
 
 	// Install the groups.
@@ -255,10 +417,39 @@ func (c completedConfig) New() (*PorchServer, error) {
 		return nil, err
 	}
 
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(RepositoryValidationPath, &repositoryValidationHandler{validator: cad})
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(PushWebhookPath, &pushWebhookHandler{client: coreClient, refresher: cache})
+
+	// Liveness stays independent of the repositories and the metadata store.
+	if err := s.GenericAPIServer.AddReadyzChecks(newRepositoryReadinessCheck(c.ExtraConfig.Readiness, cache, coreClient, c.ExtraConfig.MetadataStoreBackend)); err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
+// migrateMetadata copies the metadata of the package revisions of all repositories from
+// one metadata store backend to another.
+func migrateMetadata(ctx context.Context, coreClient client.Client, from, to meta.Backend) error {
+	if to == "" {
+		to = meta.CRDBackend
+	}
+	var repositories configapi.RepositoryList
+	if err := coreClient.List(ctx, &repositories); err != nil {
+		return fmt.Errorf("error listing repositories: %w", err)
+	}
+	for i := range repositories.Items {
+		repo := &repositories.Items[i]
+		n, err := meta.MigrateMetadata(ctx, coreClient, from, to, repo)
+		if err != nil {
+			return fmt.Errorf("repository %s/%s: %w", repo.Namespace, repo.Name, err)
+		}
+		klog.Infof("Migrated the metadata of %d package revisions of repository %s/%s from %s to %s", n, repo.Namespace, repo.Name, from, to)
+	}
+	return nil
+}
+
 func (s *PorchServer) Run(ctx context.Context) error {
-	porch.RunBackground(ctx, s.coreClient, s.cache)
+	porch.RunBackground(ctx, s.coreClient, s.cache, s.cad, PushWebhookPath)
 	return s.GenericAPIServer.PrepareRun().Run(ctx.Done())
 }
@@ -0,0 +1,263 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PushWebhookPath is the path of the endpoint receiving the push webhooks of Git providers
+// (GitHub and GitLab). A push refreshes the cache of the Repositories of the pushed
+// repository which enable webhooks with spec.git.webhookSecretRef.
+const PushWebhookPath = "/webhooks/push"
+
+// maxPushWebhookSize limits the size of the webhook payloads read.
+const maxPushWebhookSize = 10 << 20
+
+type repositoryRefresher interface {
+	RefreshRepository(repositorySpec *configapi.Repository) bool
+}
+
+// pushWebhookHandler serves the push webhooks of Git providers. The webhooks are sent
+// without Kubernetes credentials; they are authenticated with the shared secret of the
+// Repository instead.
+type pushWebhookHandler struct {
+	client    client.Reader
+	refresher repositoryRefresher
+}
+
+var _ http.Handler = &pushWebhookHandler{}
+
+// pushWebhookResponse is the response to an accepted push webhook.
+type pushWebhookResponse struct {
+	// Refreshed are the Repositories, as <namespace>/<name>, scheduled for a refresh.
+	Refreshed []string `json:"refreshed"`
+}
+
+func (h *pushWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "push webhooks must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushWebhookSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read webhook: %v", err), http.StatusBadRequest)
+		return
+	}
+	event, err := parsePushEvent(r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if event == nil {
+		// Not a push, such as the ping GitHub sends when the webhook is created.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	status, response, err := h.handle(r.Context(), event)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		klog.Errorf("cannot encode push webhook response: %v", err)
+	}
+}
+
+// handle refreshes the Repositories of the pushed repository and branch whose shared
+// secret the event is authenticated with.
+func (h *pushWebhookHandler) handle(ctx context.Context, event *pushEvent) (int, *pushWebhookResponse, error) {
+	var repositories configapi.RepositoryList
+	if err := h.client.List(ctx, &repositories); err != nil {
+		return http.StatusInternalServerError, nil, fmt.Errorf("error listing repository objects: %w", err)
+	}
+
+	found, authenticated := false, false
+	response := &pushWebhookResponse{Refreshed: []string{}}
+	for i := range repositories.Items {
+		repo := &repositories.Items[i]
+		git := repo.Spec.Git
+		if repo.Spec.Type != configapi.RepositoryTypeGit || git == nil || git.WebhookSecretRef.Name == "" || !event.isRepository(git.Repo) {
+			continue
+		}
+		found = true
+
+		secret, err := h.webhookSecret(ctx, repo)
+		if err != nil {
+			klog.Warningf("Cannot read webhook secret of repository %s:%s: %v", repo.Namespace, repo.Name, err)
+			continue
+		}
+		if !event.authenticate(secret) {
+			continue
+		}
+		authenticated = true
+
+		if !event.changes(git.Branch) {
+			continue
+		}
+		// Repositories which aren't cached yet are read on the next background refresh.
+		if h.refresher.RefreshRepository(repo) {
+			klog.Infof("Push webhook scheduled refresh of repository %s:%s", repo.Namespace, repo.Name)
+			response.Refreshed = append(response.Refreshed, repo.Namespace+"/"+repo.Name)
+		}
+	}
+
+	switch {
+	case !found:
+		return http.StatusNotFound, nil, fmt.Errorf("no repository with push webhooks enabled is registered for %s", event.urls[0])
+	case !authenticated:
+		return http.StatusUnauthorized, nil, fmt.Errorf("webhook doesn't match the secret of any repository registered for %s", event.urls[0])
+	default:
+		return http.StatusAccepted, response, nil
+	}
+}
+
+func (h *pushWebhookHandler) webhookSecret(ctx context.Context, repo *configapi.Repository) ([]byte, error) {
+	var secret corev1.Secret
+	key := client.ObjectKey{Namespace: repo.Namespace, Name: repo.Spec.Git.WebhookSecretRef.Name}
+	if err := h.client.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+	value := secret.Data[configapi.WebhookSecretKey]
+	if len(value) == 0 {
+		return nil, fmt.Errorf("secret %q has no %q key", key.Name, configapi.WebhookSecretKey)
+	}
+	return value, nil
+}
+
+// pushEvent is a push to a Git repository reported by a webhook.
+type pushEvent struct {
+	// ref is the pushed ref, such as refs/heads/main.
+	ref string
+	// urls are the addresses of the repository.
+	urls []string
+
+	body []byte
+	// signature is the HMAC of the body (GitHub).
+	signature string
+	// token is the shared secret itself (GitLab).
+	token string
+}
+
+// parsePushEvent returns the push reported by a GitHub or GitLab webhook, or nil if the
+// webhook reports another event.
+func parsePushEvent(header http.Header, body []byte) (*pushEvent, error) {
+	switch {
+	case header.Get("X-GitHub-Event") != "":
+		if header.Get("X-GitHub-Event") != "push" {
+			return nil, nil
+		}
+		var payload struct {
+			Ref        string `json:"ref"`
+			Repository struct {
+				CloneURL string `json:"clone_url"`
+				SSHURL   string `json:"ssh_url"`
+				HTMLURL  string `json:"html_url"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("cannot decode GitHub push event: %w", err)
+		}
+		return newPushEvent(payload.Ref, body, header.Get("X-Hub-Signature-256"), "",
+			payload.Repository.CloneURL, payload.Repository.SSHURL, payload.Repository.HTMLURL)
+
+	case header.Get("X-Gitlab-Event") != "":
+		if event := header.Get("X-Gitlab-Event"); event != "Push Hook" && event != "Tag Push Hook" {
+			return nil, nil
+		}
+		var payload struct {
+			Ref     string `json:"ref"`
+			Project struct {
+				HTTPURL string `json:"git_http_url"`
+				SSHURL  string `json:"git_ssh_url"`
+				WebURL  string `json:"web_url"`
+			} `json:"project"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("cannot decode GitLab push event: %w", err)
+		}
+		return newPushEvent(payload.Ref, body, "", header.Get("X-Gitlab-Token"),
+			payload.Project.HTTPURL, payload.Project.SSHURL, payload.Project.WebURL)
+
+	default:
+		return nil, fmt.Errorf("unsupported webhook; only GitHub and GitLab push events are supported")
+	}
+}
+
+func newPushEvent(ref string, body []byte, signature, token string, urls ...string) (*pushEvent, error) {
+	event := &pushEvent{ref: ref, body: body, signature: signature, token: token}
+	for _, url := range urls {
+		if url != "" {
+			event.urls = append(event.urls, url)
+		}
+	}
+	if ref == "" || len(event.urls) == 0 {
+		return nil, fmt.Errorf("push event has no ref or repository address")
+	}
+	return event, nil
+}
+
+// isRepository returns true if the event is a push to the repository at address repo.
+func (e *pushEvent) isRepository(repo string) bool {
+	normalized := git.NormalizeRepositoryURL(repo)
+	for _, url := range e.urls {
+		if git.NormalizeRepositoryURL(url) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns true if the event was sent with the shared secret.
+func (e *pushEvent) authenticate(secret []byte) bool {
+	if e.signature != "" {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(e.body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(expected), []byte(e.signature))
+	}
+	return e.token != "" && subtle.ConstantTimeCompare([]byte(e.token), secret) == 1
+}
+
+// changes returns true if the pushed ref can change the packages of a repository whose
+// packages are on branch: the branch itself, tags of published revisions, and the
+// branches of drafts and proposed revisions.
+func (e *pushEvent) changes(branch string) bool {
+	if branch == "" {
+		branch = "main"
+	}
+	return e.ref == "refs/heads/"+branch ||
+		strings.HasPrefix(e.ref, "refs/tags/") ||
+		strings.HasPrefix(e.ref, "refs/heads/drafts/") ||
+		strings.HasPrefix(e.ref, "refs/heads/proposed/")
+}
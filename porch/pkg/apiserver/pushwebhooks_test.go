@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeRefresher struct {
+	refreshed []string
+}
+
+func (r *fakeRefresher) RefreshRepository(repositorySpec *configapi.Repository) bool {
+	r.refreshed = append(r.refreshed, repositorySpec.Name)
+	return true
+}
+
+type webhookRequest struct {
+	header http.Header
+	body   string
+}
+
+func TestPushWebhookHandler(t *testing.T) {
+	gitRepository := func(name, address, branch, secret string) *configapi.Repository {
+		return &configapi.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: configapi.RepositorySpec{
+				Type: configapi.RepositoryTypeGit,
+				Git: &configapi.GitRepository{
+					Repo:             address,
+					Branch:           branch,
+					WebhookSecretRef: configapi.SecretRef{Name: secret},
+				},
+			},
+		}
+	}
+	webhookSecret := func(name, value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string][]byte{configapi.WebhookSecretKey: []byte(value)},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := configapi.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme failed: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme failed: %v", err)
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		gitRepository("blueprints", "https://github.com/example/blueprints.git", "", "blueprints-webhook"),
+		gitRepository("blueprints-staging", "https://github.com/example/blueprints", "staging", "blueprints-webhook"),
+		gitRepository("deployments", "git@gitlab.com:example/deployments.git", "main", "deployments-webhook"),
+		gitRepository("catalog", "https://github.com/example/catalog.git", "main", ""),
+		webhookSecret("blueprints-webhook", "blueprints-s3cr3t"),
+		webhookSecret("deployments-webhook", "deployments-s3cr3t"),
+	).Build()
+
+	github := func(ref, secret string) webhookRequest {
+		body := `{"ref":"` + ref + `","repository":{"clone_url":"https://github.com/example/blueprints.git","ssh_url":"git@github.com:example/blueprints.git"}}`
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return webhookRequest{
+			header: http.Header{
+				"X-Github-Event":      {"push"},
+				"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(mac.Sum(nil))},
+			},
+			body: body,
+		}
+	}
+	gitlab := func(ref, token string) webhookRequest {
+		return webhookRequest{
+			header: http.Header{"X-Gitlab-Event": {"Push Hook"}, "X-Gitlab-Token": {token}},
+			body:   `{"ref":"` + ref + `","project":{"git_http_url":"https://gitlab.com/example/deployments.git","git_ssh_url":"git@gitlab.com:example/deployments.git"}}`,
+		}
+	}
+
+	testCases := map[string]struct {
+		request       webhookRequest
+		wantStatus    int
+		wantRefreshed []string
+	}{
+		"github push to branch": {
+			request:       github("refs/heads/main", "blueprints-s3cr3t"),
+			wantStatus:    http.StatusAccepted,
+			wantRefreshed: []string{"blueprints"},
+		},
+		"github push of tag": {
+			request:       github("refs/tags/app/v1", "blueprints-s3cr3t"),
+			wantStatus:    http.StatusAccepted,
+			wantRefreshed: []string{"blueprints", "blueprints-staging"},
+		},
+		"github push to other branch": {
+			request:    github("refs/heads/feature", "blueprints-s3cr3t"),
+			wantStatus: http.StatusAccepted,
+		},
+		"github bad signature": {
+			request:    github("refs/heads/main", "wrong"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		"github ping": {
+			request:    webhookRequest{header: http.Header{"X-Github-Event": {"ping"}}, body: `{}`},
+			wantStatus: http.StatusOK,
+		},
+		"gitlab push": {
+			request:       gitlab("refs/heads/main", "deployments-s3cr3t"),
+			wantStatus:    http.StatusAccepted,
+			wantRefreshed: []string{"deployments"},
+		},
+		"gitlab bad token": {
+			request:    gitlab("refs/heads/main", "blueprints-s3cr3t"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		"webhooks not enabled": {
+			request: webhookRequest{
+				header: http.Header{"X-Github-Event": {"push"}},
+				body:   `{"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/example/catalog.git"}}`,
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			refresher := &fakeRefresher{}
+			handler := &pushWebhookHandler{client: client, refresher: refresher}
+
+			request := httptest.NewRequest(http.MethodPost, PushWebhookPath, bytes.NewBufferString(tc.request.body))
+			for k, v := range tc.request.header {
+				request.Header[k] = v
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.wantStatus {
+				t.Errorf("unexpected status %d, want %d: %s", recorder.Code, tc.wantStatus, recorder.Body.String())
+			}
+			if diff := cmp.Diff(tc.wantRefreshed, refresher.refreshed); diff != "" {
+				t.Errorf("unexpected refreshed repositories (-want, +got): %s", diff)
+			}
+		})
+	}
+}
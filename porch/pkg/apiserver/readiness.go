@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// metadataStoreCheckTimeout limits how long the readiness check waits for the metadata store.
+const metadataStoreCheckTimeout = 5 * time.Second
+
+// ReadinessPolicy decides when the server is ready to serve requests.
+type ReadinessPolicy struct {
+	// MinSyncedFraction is the fraction, between 0 and 1, of the repositories which must be
+	// synced for the server to be ready. Zero, the default, ignores the state of repositories.
+	MinSyncedFraction float64
+	// SyncThreshold is how recently a repository must have been synced to count as synced.
+	SyncThreshold time.Duration
+}
+
+type repositoryHealthSource interface {
+	RepositoryHealth() []cache.RepositoryHealth
+}
+
+// repositoryReadinessCheck reports the server ready only when the metadata store is
+// reachable and, if the policy requires it, enough repositories have synced recently. The reason of a failed check,
+// listing the unhealthy repositories and their last errors, is shown by the readyz
+// endpoint of the check.
+type repositoryReadinessCheck struct {
+	policy       ReadinessPolicy
+	repositories repositoryHealthSource
+	// checkMetadataStore returns an error if the metadata store is unreachable.
+	checkMetadataStore func(ctx context.Context) error
+	now                func() time.Time
+}
+
+var _ healthz.HealthChecker = &repositoryReadinessCheck{}
+
+// newRepositoryReadinessCheck returns the readiness check of the repositories of the
+// cache, whose metadata is stored in the backend with the client.
+func newRepositoryReadinessCheck(policy ReadinessPolicy, repositories repositoryHealthSource, c client.Reader, backend meta.Backend) *repositoryReadinessCheck {
+	return &repositoryReadinessCheck{
+		policy:       policy,
+		repositories: repositories,
+		checkMetadataStore: func(ctx context.Context) error {
+			return meta.CheckReachable(ctx, backend, c)
+		},
+		now: time.Now,
+	}
+}
+
+func (c *repositoryReadinessCheck) Name() string {
+	return "porch-repositories"
+}
+
+func (c *repositoryReadinessCheck) Check(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), metadataStoreCheckTimeout)
+	defer cancel()
+	if err := c.checkMetadataStore(ctx); err != nil {
+		return fmt.Errorf("metadata store is unreachable: %w", err)
+	}
+	return evaluateReadiness(c.policy, c.now(), c.repositories.RepositoryHealth())
+}
+
+// evaluateReadiness returns an error listing the unhealthy repositories if fewer than the
+// minimum fraction of the repositories synced within the threshold. A repository is
+// unhealthy if its last sync failed, or if it didn't sync within the threshold.
+func evaluateReadiness(policy ReadinessPolicy, now time.Time, repositories []cache.RepositoryHealth) error {
+	if len(repositories) == 0 || policy.MinSyncedFraction <= 0 {
+		return nil
+	}
+	var unhealthy []string
+	for _, r := range repositories {
+		switch {
+		case r.Err != nil:
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: %v", r.Repository, r.Err))
+		case r.LastSynced.IsZero():
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: not synced yet", r.Repository))
+		case now.Sub(r.LastSynced) > policy.SyncThreshold:
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: last synced %s ago", r.Repository, now.Sub(r.LastSynced).Round(time.Second)))
+		}
+	}
+	synced := len(repositories) - len(unhealthy)
+	if float64(synced) >= policy.MinSyncedFraction*float64(len(repositories)) {
+		return nil
+	}
+	return fmt.Errorf("%d of %d repositories synced within %s; unhealthy repositories:\n%s",
+		synced, len(repositories), policy.SyncThreshold, strings.Join(unhealthy, "\n"))
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
+)
+
+type fakeRepositoryHealth []cache.RepositoryHealth
+
+func (f fakeRepositoryHealth) RepositoryHealth() []cache.RepositoryHealth {
+	return f
+}
+
+func TestEvaluateReadiness(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	healthy := func(name string) cache.RepositoryHealth {
+		return cache.RepositoryHealth{Repository: name, LastSynced: now.Add(-time.Minute)}
+	}
+	stale := cache.RepositoryHealth{Repository: "default/stale", LastSynced: now.Add(-time.Hour)}
+	failing := cache.RepositoryHealth{Repository: "default/failing", LastSynced: now.Add(-time.Minute), Err: fmt.Errorf("authentication required")}
+	unsynced := cache.RepositoryHealth{Repository: "default/unsynced"}
+
+	policy := ReadinessPolicy{MinSyncedFraction: 0.5, SyncThreshold: 5 * time.Minute}
+
+	for _, tc := range []struct {
+		name         string
+		policy       ReadinessPolicy
+		repositories []cache.RepositoryHealth
+		// wantUnhealthy are the repositories reported unhealthy; empty if ready.
+		wantUnhealthy []string
+	}{
+		{
+			name:   "no repositories",
+			policy: policy,
+		},
+		{
+			name:         "all synced",
+			policy:       policy,
+			repositories: []cache.RepositoryHealth{healthy("default/a"), healthy("default/b")},
+		},
+		{
+			name:         "enough synced",
+			policy:       policy,
+			repositories: []cache.RepositoryHealth{healthy("default/a"), healthy("default/b"), stale, failing},
+		},
+		{
+			name:          "too few synced",
+			policy:        policy,
+			repositories:  []cache.RepositoryHealth{healthy("default/a"), stale, failing, unsynced},
+			wantUnhealthy: []string{"default/stale: last synced 1h0m0s ago", "default/failing: authentication required", "default/unsynced: not synced yet"},
+		},
+		{
+			name:          "all unreachable",
+			policy:        policy,
+			repositories:  []cache.RepositoryHealth{failing},
+			wantUnhealthy: []string{"default/failing: authentication required"},
+		},
+		{
+			name:         "repositories ignored",
+			policy:       ReadinessPolicy{SyncThreshold: 5 * time.Minute},
+			repositories: []cache.RepositoryHealth{failing},
+		},
+		{
+			name:          "all required",
+			policy:        ReadinessPolicy{MinSyncedFraction: 1, SyncThreshold: 5 * time.Minute},
+			repositories:  []cache.RepositoryHealth{healthy("default/a"), healthy("default/b"), stale},
+			wantUnhealthy: []string{"default/stale: last synced 1h0m0s ago"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := evaluateReadiness(tc.policy, now, tc.repositories)
+			if len(tc.wantUnhealthy) == 0 {
+				if err != nil {
+					t.Errorf("not ready: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ready; want unhealthy repositories %v", tc.wantUnhealthy)
+			}
+			for _, want := range tc.wantUnhealthy {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("readiness error %q doesn't report %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryReadinessCheckMetadataStore(t *testing.T) {
+	check := &repositoryReadinessCheck{
+		policy:       ReadinessPolicy{MinSyncedFraction: 0.5, SyncThreshold: time.Minute},
+		repositories: fakeRepositoryHealth{},
+		checkMetadataStore: func(ctx context.Context) error {
+			return fmt.Errorf("connection refused")
+		},
+		now: time.Now,
+	}
+	err := check.Check(httptest.NewRequest("GET", "/readyz", nil))
+	if err == nil || !strings.Contains(err.Error(), "metadata store is unreachable") {
+		t.Errorf("Check returned %v; want metadata store error", err)
+	}
+
+	check.checkMetadataStore = func(ctx context.Context) error { return nil }
+	if err := check.Check(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+}
+
+func TestRepositoryReadinessCheckDefaultPolicy(t *testing.T) {
+	reachable := true
+	check := &repositoryReadinessCheck{
+		repositories: fakeRepositoryHealth{
+			{Repository: "default/failing", Err: fmt.Errorf("authentication required")},
+			{Repository: "default/unsynced"},
+		},
+		checkMetadataStore: func(ctx context.Context) error {
+			if !reachable {
+				return fmt.Errorf("connection refused")
+			}
+			return nil
+		},
+		now: time.Now,
+	}
+	if err := check.Check(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Errorf("Check with unhealthy repositories failed under the default policy: %v", err)
+	}
+
+	reachable = false
+	if err := check.Check(httptest.NewRequest("GET", "/readyz", nil)); err == nil {
+		t.Errorf("Check succeeded with an unreachable metadata store")
+	}
+}
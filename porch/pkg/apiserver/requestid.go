@@ -0,0 +1,33 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+)
+
+// withRequestID gives each request an ID correlating its log lines, and returns the ID
+// to the client in a response header so users can quote it in bug reports. The ID is the
+// trace ID of the request, so the handler must run within the span of the request.
+func withRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		id := engine.NewRequestID(ctx)
+		w.Header().Set(engine.RequestIDHeader, id)
+		handler.ServeHTTP(w, req.WithContext(engine.WithRequestID(ctx, id)))
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+)
+
+func TestWithRequestID(t *testing.T) {
+	var handled string
+	handler := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handled = engine.RequestIDFromContext(req.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/apis/porch.kpt.dev/v1alpha1/packagerevisions", nil))
+
+	returned := w.Result().Header.Get(engine.RequestIDHeader)
+	if returned == "" {
+		t.Fatalf("no %s header in the response", engine.RequestIDHeader)
+	}
+	if handled != returned {
+		t.Errorf("request was handled with ID %q; %q was returned to the client", handled, returned)
+	}
+}
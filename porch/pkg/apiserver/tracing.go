@@ -0,0 +1,34 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// withTraceContext starts a span for each request, continuing the trace of the request
+// if the client sent its trace context, so the spans of the engine connect to the traces
+// of clients calling through the aggregated apiserver.
+func withTraceContext(handler http.Handler) http.Handler {
+	return otelhttp.NewHandler(handler, "porch",
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(operation string, req *http.Request) string {
+			return operation + "::" + req.Method + " " + req.URL.Path
+		}),
+	)
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// RepositoryValidationPath is the path of the validating admission webhook which checks
+// the impact of changes of Repository specs. It is registered for updates of
+// repositories.config.porch.kpt.dev by the ValidatingWebhookConfiguration in the
+// Porch deployment.
+const RepositoryValidationPath = "/validate-repository"
+
+type repositoryChangeValidator interface {
+	ValidateRepositoryChange(ctx context.Context, oldRepo, newRepo *configapi.Repository) (*engine.RepositoryChangeImpact, error)
+}
+
+// repositoryValidationHandler serves the AdmissionReviews of Repository updates. It rejects
+// blocking changes and admits risky ones with warnings, which kubectl and kpt display; a
+// server-side dry run shows the impact of a change without applying it.
+type repositoryValidationHandler struct {
+	validator repositoryChangeValidator
+}
+
+var _ http.Handler = &repositoryValidationHandler{}
+
+func (h *repositoryValidationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("cannot decode AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	response := h.review(r.Context(), review.Request)
+	response.UID = review.Request.UID
+	review.Request = nil
+	review.Response = response
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&review); err != nil {
+		klog.Errorf("cannot encode AdmissionReview response: %v", err)
+	}
+}
+
+func (h *repositoryValidationHandler) review(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	var oldRepo, newRepo configapi.Repository
+	if err := json.Unmarshal(request.OldObject.Raw, &oldRepo); err != nil {
+		return deniedResponse(metav1.StatusReasonBadRequest, fmt.Sprintf("cannot decode existing repository: %v", err))
+	}
+	if err := json.Unmarshal(request.Object.Raw, &newRepo); err != nil {
+		return deniedResponse(metav1.StatusReasonBadRequest, fmt.Sprintf("cannot decode repository: %v", err))
+	}
+
+	impact, err := h.validator.ValidateRepositoryChange(ctx, &oldRepo, &newRepo)
+	if err != nil {
+		// Don't hold up edits of repositories Porch cannot currently read.
+		klog.Warningf("cannot determine impact of change of repository %s/%s: %v", newRepo.Namespace, newRepo.Name, err)
+		return &admissionv1.AdmissionResponse{
+			Allowed:  true,
+			Warnings: []string{fmt.Sprintf("cannot determine the impact of the change: %v", err)},
+		}
+	}
+
+	switch impact.Severity {
+	case engine.RepositoryChangeBlocking:
+		return deniedResponse(metav1.StatusReasonForbidden, fmt.Sprintf("change of repository %s rejected: %s", newRepo.Name, strings.Join(impact.Problems, "; ")))
+	case engine.RepositoryChangeWarning:
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: impact.Problems}
+	default:
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+}
+
+func deniedResponse(reason metav1.StatusReason, message string) *admissionv1.AdmissionResponse {
+	code := int32(http.StatusForbidden)
+	if reason == metav1.StatusReasonBadRequest {
+		code = http.StatusBadRequest
+	}
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  reason,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakeChangeValidator struct {
+	impact *engine.RepositoryChangeImpact
+}
+
+func (v *fakeChangeValidator) ValidateRepositoryChange(ctx context.Context, oldRepo, newRepo *configapi.Repository) (*engine.RepositoryChangeImpact, error) {
+	return v.impact, nil
+}
+
+func TestRepositoryValidationHandler(t *testing.T) {
+	repo, err := json.Marshal(&configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	testCases := map[string]struct {
+		impact       engine.RepositoryChangeImpact
+		wantAllowed  bool
+		wantWarnings []string
+	}{
+		"safe": {
+			impact:      engine.RepositoryChangeImpact{Severity: engine.RepositoryChangeSafe},
+			wantAllowed: true,
+		},
+		"warning": {
+			impact:       engine.RepositoryChangeImpact{Severity: engine.RepositoryChangeWarning, Problems: []string{"1 of 2 known package revisions would no longer be visible"}},
+			wantAllowed:  true,
+			wantWarnings: []string{"1 of 2 known package revisions would no longer be visible"},
+		},
+		"blocking": {
+			impact: engine.RepositoryChangeImpact{Severity: engine.RepositoryChangeBlocking, Problems: []string{"2 of 2 known package revisions would no longer be visible"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(&admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+				Request: &admissionv1.AdmissionRequest{
+					UID:       "1234",
+					Operation: admissionv1.Update,
+					Object:    runtime.RawExtension{Raw: repo},
+					OldObject: runtime.RawExtension{Raw: repo},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			handler := &repositoryValidationHandler{validator: &fakeChangeValidator{impact: &tc.impact}}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, RepositoryValidationPath, bytes.NewReader(body)))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", recorder.Code, recorder.Body.String())
+			}
+
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(recorder.Body.Bytes(), &review); err != nil {
+				t.Fatalf("cannot decode response: %v", err)
+			}
+			response := review.Response
+			if response == nil || response.UID != "1234" {
+				t.Fatalf("response doesn't match the request: %v", response)
+			}
+			if response.Allowed != tc.wantAllowed {
+				t.Errorf("unexpected admission: got allowed=%t, want %t (%v)", response.Allowed, tc.wantAllowed, response.Result)
+			}
+			if diff := cmp.Diff(tc.wantWarnings, response.Warnings); diff != "" {
+				t.Errorf("unexpected warnings (-want, +got): %s", diff)
+			}
+			if !tc.wantAllowed && (response.Result == nil || response.Result.Message == "") {
+				t.Errorf("rejection has no message")
+			}
+		})
+	}
+}
@@ -46,6 +46,8 @@ type Cache struct {
 	credentialResolver repository.CredentialResolver
 	userInfoProvider   repository.UserInfoProvider
 	metadataStore      meta.MetadataStore
+	// openErrors are the errors of the repositories which failed to open, by key.
+	openErrors map[string]RepositoryHealth
 
 	objectCache *objectCache
 }
@@ -65,6 +67,7 @@ func NewCache(cacheDir string, opts CacheOptions) *Cache {
 		credentialResolver: opts.CredentialResolver,
 		userInfoProvider:   opts.UserInfoProvider,
 		metadataStore:      opts.MetadataStore,
+		openErrors:         make(map[string]RepositoryHealth),
 		objectCache:        objectCache,
 	}
 }
@@ -93,10 +96,13 @@ func (c *Cache) OpenRepository(ctx context.Context, repositorySpec *configapi.Re
 		if cr == nil {
 			r, err := oci.OpenRepository(repositorySpec.Name, repositorySpec.Namespace, repositorySpec.Spec.Content, ociSpec, repositorySpec.Spec.Deployment, filepath.Join(c.cacheDir, "oci"))
 			if err != nil {
+				c.recordOpenError(key, repositorySpec, err)
 				return nil, err
 			}
 			cr = newRepository(key, repositorySpec, r, c.objectCache, c.metadataStore)
+			cr.startInitialSync()
 			c.repositories[key] = cr
+			delete(c.openErrors, key)
 		}
 		return cr, nil
 
@@ -129,10 +135,13 @@ func (c *Cache) OpenRepository(ctx context.Context, repositorySpec *configapi.Re
 				UserInfoProvider:   c.userInfoProvider,
 				MainBranchStrategy: mbs,
 			}); err != nil {
+				c.recordOpenError(key, repositorySpec, err)
 				return nil, err
 			} else {
 				cr = newRepository(key, repositorySpec, r, c.objectCache, c.metadataStore)
+				cr.startInitialSync()
 				c.repositories[key] = cr
+				delete(c.openErrors, key)
 			}
 		} else {
 			// If there is an error from the background refresh goroutine, return it.
@@ -147,30 +156,62 @@ func (c *Cache) OpenRepository(ctx context.Context, repositorySpec *configapi.Re
 	}
 }
 
+// recordOpenError records that the repository failed to open. mutex must be held.
+func (c *Cache) recordOpenError(key string, repositorySpec *configapi.Repository, err error) {
+	c.openErrors[key] = RepositoryHealth{
+		Repository: repositorySpec.Namespace + "/" + repositorySpec.Name,
+		Err:        err,
+	}
+}
+
 func isPackageContent(content configapi.RepositoryContent) bool {
 	return content == configapi.RepositoryContentPackage
 }
 
-func (c *Cache) CloseRepository(repositorySpec *configapi.Repository) error {
-	var key string
+// RefreshRepository schedules a refresh of the cached repository, for example because it
+// was pushed to. It returns false if the repository isn't cached.
+func (c *Cache) RefreshRepository(repositorySpec *configapi.Repository) bool {
+	key, err := repositoryKey(repositorySpec)
+	if err != nil {
+		return false
+	}
+
+	c.mutex.Lock()
+	cr := c.repositories[key]
+	c.mutex.Unlock()
 
+	if cr == nil {
+		return false
+	}
+	cr.requestRefresh()
+	return true
+}
+
+func repositoryKey(repositorySpec *configapi.Repository) (string, error) {
 	switch repositorySpec.Spec.Type {
 	case configapi.RepositoryTypeOCI:
 		oci := repositorySpec.Spec.Oci
 		if oci == nil {
-			return fmt.Errorf("oci not configured for %s:%s", repositorySpec.ObjectMeta.Namespace, repositorySpec.ObjectMeta.Name)
+			return "", fmt.Errorf("oci not configured for %s:%s", repositorySpec.ObjectMeta.Namespace, repositorySpec.ObjectMeta.Name)
 		}
-		key = "oci://" + oci.Registry
+		return "oci://" + oci.Registry, nil
 
 	case configapi.RepositoryTypeGit:
 		git := repositorySpec.Spec.Git
 		if git == nil {
-			return fmt.Errorf("git not configured for %s:%s", repositorySpec.ObjectMeta.Namespace, repositorySpec.ObjectMeta.Name)
+			return "", fmt.Errorf("git not configured for %s:%s", repositorySpec.ObjectMeta.Namespace, repositorySpec.ObjectMeta.Name)
 		}
-		key = "git://" + git.Repo
+		return "git://" + git.Repo, nil
 
 	default:
-		return fmt.Errorf("unknown repository type: %q", repositorySpec.Spec.Type)
+		return "", fmt.Errorf("unknown repository type: %q", repositorySpec.Spec.Type)
+	}
+}
+
+func (c *Cache) CloseRepository(repositorySpec *configapi.Repository) error {
+	key, err := repositoryKey(repositorySpec)
+	if err != nil {
+		return err
 	}
 
 	// TODO: Multiple Repository resources can point to the same underlying repository
@@ -179,6 +220,7 @@ func (c *Cache) CloseRepository(repositorySpec *configapi.Repository) error {
 	var repository *cachedRepository
 	{
 		c.mutex.Lock()
+		delete(c.openErrors, key)
 		if r, ok := c.repositories[key]; ok {
 			delete(c.repositories, key)
 			repository = r
@@ -20,9 +20,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	enginefake "github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
@@ -125,6 +127,137 @@ func TestPublishedLatest(t *testing.T) {
 	}
 }
 
+func TestEqualRevisionsLatest(t *testing.T) {
+	// v1 and v1.0.0 compare equal; the one committed last is the latest, whatever the
+	// order of the map.
+	revision := func(name, revision string, committed time.Time) *cachedPackageRevision {
+		return &cachedPackageRevision{PackageRevision: &enginefake.PackageRevision{
+			Name:               name,
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: revision},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+			PackageRevision:    &api.PackageRevision{Status: api.PackageRevisionStatus{PublishedAt: metav1.NewTime(committed)}},
+		}}
+	}
+	committed := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	older := revision("blueprints-2222", "v1", committed)
+	newer := revision("blueprints-1111", "v1.0.0", committed.Add(time.Hour))
+
+	for i := 0; i < 10; i++ {
+		identifyLatestRevisions(map[repository.PackageRevisionKey]*cachedPackageRevision{
+			older.Key(): older,
+			newer.Key(): newer,
+		})
+		if older.isLatestRevision || !newer.isLatestRevision {
+			t.Fatalf("latest of equal revisions: got %s latest %t, %s latest %t; want only %s", older.Key().Revision, older.isLatestRevision, newer.Key().Revision, newer.isLatestRevision, newer.Key().Revision)
+		}
+	}
+}
+
+func TestPackagePathCollisions(t *testing.T) {
+	ctx := context.Background()
+	revision := func(name, packageName string) *enginefake.PackageRevision {
+		return &enginefake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: packageName, Revision: "v1"},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+		}
+	}
+	repo := &enginefake.Repository{PackageRevisions: []repository.PackageRevision{
+		revision("blueprints-1111", "Foo"),
+		revision("blueprints-2222", "foo"),
+		revision("blueprints-3333", "bar"),
+	}}
+	repoSpec := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	cached := newRepository("blueprints", repoSpec, repo, &objectCache{}, &fake.MemoryMetadataStore{})
+	t.Cleanup(func() { cached.Close() })
+
+	revisions, err := cached.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, true)
+	if err != nil {
+		t.Fatalf("getPackageRevisions failed: %v", err)
+	}
+	// Both colliding packages are listed, rather than one of them.
+	if got, want := len(revisions), 3; got != want {
+		t.Errorf("listed %d package revisions; want %d", got, want)
+	}
+	want := []repository.PackagePathCollision{{Path: "Foo", Other: "foo"}}
+	if diff := cmp.Diff(want, cached.PackagePathCollisions()); diff != "" {
+		t.Errorf("unexpected collisions (-want, +got): %s", diff)
+	}
+
+	// The collision is cleared once one of the packages is gone.
+	repo.PackageRevisions = repo.PackageRevisions[1:]
+	if _, err := cached.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, true); err != nil {
+		t.Fatalf("getPackageRevisions failed: %v", err)
+	}
+	if got := cached.PackagePathCollisions(); len(got) != 0 {
+		t.Errorf("collisions left after refresh: %v", got)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	ctx := context.Background()
+	revision := func(name, rev, version string, lifecycle api.PackageRevisionLifecycle) *enginefake.PackageRevision {
+		return &enginefake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "foo", Revision: rev},
+			PackageLifecycle:   lifecycle,
+			PackageRevision:    &api.PackageRevision{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: version}},
+		}
+	}
+	repo := &enginefake.Repository{PackageRevisions: []repository.PackageRevision{
+		revision("blueprints-v1", "v1", "1", api.PackageRevisionLifecyclePublished),
+		revision("blueprints-v2", "v2", "1", api.PackageRevisionLifecycleDraft),
+		revision("blueprints-v3", "v3", "1", api.PackageRevisionLifecycleDraft),
+		revision("blueprints-v4", "v4", "1", api.PackageRevisionLifecycleDraft),
+	}}
+	repoSpec := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	cached := newRepository("blueprints", repoSpec, repo, &objectCache{}, &fake.MemoryMetadataStore{})
+	t.Cleanup(func() { cached.Close() })
+
+	// Nothing is cached before the first sync.
+	if got, err := cached.CheckCache(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("CheckCache before sync returned %v, %v; want no discrepancies", got, err)
+	}
+	if err := cached.RefreshCache(ctx); err != nil {
+		t.Fatalf("RefreshCache failed: %v", err)
+	}
+	if got, err := cached.CheckCache(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("CheckCache after sync returned %v, %v; want no discrepancies", got, err)
+	}
+
+	// The repository changes behind the back of the cache, like after a force push.
+	repo.PackageRevisions = []repository.PackageRevision{
+		revision("blueprints-v1", "v1", "1", api.PackageRevisionLifecyclePublished),
+		revision("blueprints-v2", "v2", "1", api.PackageRevisionLifecyclePublished),
+		revision("blueprints-v3", "v3", "2", api.PackageRevisionLifecycleDraft),
+		revision("blueprints-v5", "v5", "1", api.PackageRevisionLifecycleDraft),
+	}
+	want := []repository.CacheDiscrepancy{
+		{Name: "blueprints-v1", Latest: true, Message: "cached as the latest revision of its package, but it isn't"},
+		{Name: "blueprints-v2", Message: "cached lifecycle Draft differs from lifecycle Published in the repository"},
+		{Name: "blueprints-v3", Message: `cached version "1" differs from version "2" in the repository`},
+		{Name: "blueprints-v4", Message: "cached package revision is no longer in the repository"},
+		{Name: "blueprints-v5", Message: "package revision is missing from the cache"},
+	}
+	got, err := cached.CheckCache(ctx)
+	if err != nil {
+		t.Fatalf("CheckCache failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected discrepancies (-want, +got): %s", diff)
+	}
+
+	// Refreshing the cache resolves them.
+	if err := cached.RefreshCache(ctx); err != nil {
+		t.Fatalf("RefreshCache failed: %v", err)
+	}
+	if got, err := cached.CheckCache(ctx); err != nil || len(got) != 0 {
+		t.Errorf("CheckCache after refresh returned %v, %v; want no discrepancies", got, err)
+	}
+}
+
 func openRepositoryFromArchive(t *testing.T, ctx context.Context, testPath, name string) (*gogit.Repository, *cachedRepository) {
 	t.Helper()
 
@@ -184,3 +317,127 @@ func createMetadataStoreFromArchive(t *testing.T, testPath, name string) meta.Me
 		Metas: metas,
 	}
 }
+
+// unreachableRepository fails to list its package revisions.
+type unreachableRepository struct {
+	enginefake.Repository
+}
+
+func (r *unreachableRepository) ListPackageRevisions(context.Context, repository.ListPackageRevisionFilter) ([]repository.PackageRevision, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestRepositoryHealth(t *testing.T) {
+	ctx := context.Background()
+	newCached := func(name string, repo repository.Repository) *cachedRepository {
+		repoSpec := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		cached := newRepository(name, repoSpec, repo, &objectCache{}, &fake.MemoryMetadataStore{})
+		t.Cleanup(func() { cached.Close() })
+		return cached
+	}
+	healthy := newCached("healthy", &enginefake.Repository{})
+	unreachable := newCached("unreachable", &unreachableRepository{})
+	unsynced := newCached("unsynced", &enginefake.Repository{})
+
+	if _, err := healthy.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, true); err != nil {
+		t.Fatalf("getPackageRevisions failed: %v", err)
+	}
+	if _, err := unreachable.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, true); err == nil {
+		t.Fatalf("getPackageRevisions of unreachable repository succeeded")
+	}
+
+	c := NewCache(t.TempDir(), CacheOptions{})
+	c.repositories["healthy"] = healthy
+	c.repositories["unreachable"] = unreachable
+	c.repositories["unsynced"] = unsynced
+	c.recordOpenError("broken", &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"}}, fmt.Errorf("authentication required"))
+
+	health := c.RepositoryHealth()
+	var got []string
+	for _, h := range health {
+		got = append(got, fmt.Sprintf("%s synced=%t err=%v", h.Repository, !h.LastSynced.IsZero(), h.Err))
+	}
+	want := []string{
+		"default/broken synced=false err=authentication required",
+		"default/healthy synced=true err=<nil>",
+		"default/unreachable synced=false err=error listing packages: connection refused",
+		"default/unsynced synced=false err=<nil>",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected repository health (-want, +got): %s", diff)
+	}
+}
+
+// slowRepository lists its package revisions once released.
+type slowRepository struct {
+	enginefake.Repository
+	release chan struct{}
+}
+
+func (r *slowRepository) ListPackageRevisions(ctx context.Context, filter repository.ListPackageRevisionFilter) ([]repository.PackageRevision, error) {
+	select {
+	case <-r.release:
+		return r.Repository.ListPackageRevisions(ctx, filter)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestInitialSync(t *testing.T) {
+	ctx := context.Background()
+	repo := &slowRepository{
+		Repository: enginefake.Repository{PackageRevisions: []repository.PackageRevision{
+			&enginefake.PackageRevision{
+				Name:               "blueprints-1111",
+				Namespace:          "default",
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "foo", Revision: "v1"},
+				PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+			},
+		}},
+		release: make(chan struct{}),
+	}
+	repoSpec := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	cached := newRepository("blueprints", repoSpec, repo, &objectCache{}, &fake.MemoryMetadataStore{})
+	t.Cleanup(func() { cached.Close() })
+
+	// Before the sync starts.
+	if got := cached.SyncProgress(); got.Synced || !got.Started.IsZero() {
+		t.Errorf("progress before sync: got %+v, want neither synced nor started", got)
+	}
+
+	// During the sync, progress is reported without waiting for the sync.
+	cached.startInitialSync()
+	deadline := time.Now().Add(10 * time.Second)
+	for cached.SyncProgress().Started.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatalf("initial sync didn't start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := cached.SyncProgress(); got.Synced {
+		t.Errorf("progress during sync: got %+v, want not synced", got)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := cached.WaitForSync(waitCtx); err == nil {
+		t.Errorf("WaitForSync during sync succeeded; want deadline exceeded")
+	}
+
+	// After the sync.
+	close(repo.release)
+	waitCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := cached.WaitForSync(waitCtx); err != nil {
+		t.Fatalf("WaitForSync failed: %v", err)
+	}
+	if got := cached.SyncProgress(); !got.Synced || got.Started.IsZero() {
+		t.Errorf("progress after sync: got %+v, want synced", got)
+	}
+	revisions, err := cached.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		t.Fatalf("ListPackageRevisions failed: %v", err)
+	}
+	if got, want := len(revisions), 1; got != want {
+		t.Errorf("listed %d package revisions; want %d", got, want)
+	}
+}
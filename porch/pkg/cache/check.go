@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ repository.CacheChecker = &cachedRepository{}
+
+// cachedState is a cached package revision and whether it is cached as the latest
+// revision of its package.
+type cachedState struct {
+	repository.PackageRevision
+	latest bool
+}
+
+// CheckCache compares the cached package revisions with those of the repository. A
+// repository which wasn't synced yet has nothing cached to disagree with.
+func (r *cachedRepository) CheckCache(ctx context.Context) ([]repository.CacheDiscrepancy, error) {
+	ctx, span := tracer.Start(ctx, "Repository::CheckCache", trace.WithAttributes())
+	defer span.End()
+
+	// The latest revisions are recomputed under the mutex, so they are copied.
+	r.mutex.Lock()
+	cached := make(map[repository.PackageRevisionKey]cachedState, len(r.cachedPackageRevisions))
+	for k, v := range r.cachedPackageRevisions {
+		cached[k] = cachedState{PackageRevision: v.PackageRevision, latest: v.isLatestRevision}
+	}
+	synced := r.cachedPackageRevisions != nil
+	r.mutex.Unlock()
+	if !synced {
+		return nil, nil
+	}
+
+	revisions, err := r.repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing packages: %w", err)
+	}
+	current := make(map[repository.PackageRevisionKey]*cachedPackageRevision, len(revisions))
+	for _, rev := range revisions {
+		current[rev.Key()] = &cachedPackageRevision{PackageRevision: rev}
+	}
+	identifyLatestRevisions(current)
+
+	var discrepancies []repository.CacheDiscrepancy
+	for k, c := range cached {
+		if _, found := current[k]; !found {
+			discrepancies = append(discrepancies, repository.CacheDiscrepancy{
+				Name:    c.KubeObjectName(),
+				Message: "cached package revision is no longer in the repository",
+			})
+		}
+	}
+	for k, cur := range current {
+		c, found := cached[k]
+		if !found {
+			discrepancies = append(discrepancies, repository.CacheDiscrepancy{
+				Name:    cur.KubeObjectName(),
+				Message: "package revision is missing from the cache",
+			})
+			continue
+		}
+		if c.Lifecycle() != cur.Lifecycle() {
+			discrepancies = append(discrepancies, repository.CacheDiscrepancy{
+				Name:    cur.KubeObjectName(),
+				Message: fmt.Sprintf("cached lifecycle %s differs from lifecycle %s in the repository", c.Lifecycle(), cur.Lifecycle()),
+			})
+			continue
+		}
+		if cachedVersion, currentVersion := resourceVersion(ctx, c), resourceVersion(ctx, cur); cachedVersion != currentVersion {
+			discrepancies = append(discrepancies, repository.CacheDiscrepancy{
+				Name:    cur.KubeObjectName(),
+				Message: fmt.Sprintf("cached version %q differs from version %q in the repository", cachedVersion, currentVersion),
+			})
+			continue
+		}
+		if c.latest != cur.isLatestRevision {
+			message := "cached as the latest revision of its package, but it isn't"
+			if cur.isLatestRevision {
+				message = "not cached as the latest revision of its package, but it is"
+			}
+			discrepancies = append(discrepancies, repository.CacheDiscrepancy{
+				Name:    cur.KubeObjectName(),
+				Latest:  true,
+				Message: message,
+			})
+		}
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].Name < discrepancies[j].Name
+	})
+	return discrepancies, nil
+}
+
+// RefreshCache refreshes the cached package revisions from the repository.
+func (r *cachedRepository) RefreshCache(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Repository::RefreshCache", trace.WithAttributes())
+	defer span.End()
+
+	_, err := r.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, true)
+	return err
+}
+
+// resourceVersion returns the resource version of the package revision, which changes
+// whenever the package revision does, or "" if it can't be read.
+func resourceVersion(ctx context.Context, rev repository.PackageRevision) string {
+	apiRev, err := rev.GetPackageRevision(ctx)
+	if err != nil || apiRev == nil {
+		return ""
+	}
+	return apiRev.ResourceVersion
+}
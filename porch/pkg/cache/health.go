@@ -0,0 +1,64 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+// RepositoryHealth is the sync state of a repository of the cache.
+type RepositoryHealth struct {
+	// Repository is the namespace/name of the Repository resource.
+	Repository string
+	// LastSynced is when the package revisions of the repository were last refreshed
+	// successfully. It is zero if they never were.
+	LastSynced time.Time
+	// Err is the error of the last refresh of the repository, or of opening it. It is nil
+	// if the last refresh succeeded.
+	Err error
+}
+
+// RepositoryHealth returns the sync state of the repositories opened through the cache,
+// including the repositories which failed to open, sorted by repository.
+func (c *Cache) RepositoryHealth() []RepositoryHealth {
+	c.mutex.Lock()
+	repositories := make([]*cachedRepository, 0, len(c.repositories))
+	for _, r := range c.repositories {
+		repositories = append(repositories, r)
+	}
+	health := make([]RepositoryHealth, 0, len(c.repositories)+len(c.openErrors))
+	for _, h := range c.openErrors {
+		health = append(health, h)
+	}
+	c.mutex.Unlock()
+
+	for _, r := range repositories {
+		health = append(health, r.health())
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Repository < health[j].Repository })
+	return health
+}
+
+func (r *cachedRepository) health() RepositoryHealth {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return RepositoryHealth{
+		Repository: r.repoSpec.Namespace + "/" + r.repoSpec.Name,
+		LastSynced: r.lastSynced,
+		Err:        r.lastSyncError,
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -42,6 +43,8 @@ var tracer = otel.Tracer("cache")
 
 var _ repository.Repository = &cachedRepository{}
 var _ repository.FunctionRepository = &cachedRepository{}
+var _ repository.RefPruner = &cachedRepository{}
+var _ repository.SyncReporter = &cachedRepository{}
 
 type cachedRepository struct {
 	id string
@@ -49,11 +52,15 @@ type cachedRepository struct {
 	// ownerreferences to PackageRevision resources.
 	repoSpec *configapi.Repository
 	repo     repository.Repository
-	cancel   context.CancelFunc
+	// ctx is cancelled when the repository is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	mutex                  sync.Mutex
 	cachedPackageRevisions map[repository.PackageRevisionKey]*cachedPackageRevision
 	cachedPackages         map[repository.PackageKey]*cachedPackage
+	// pathCollisions are the colliding package paths found by the last refresh.
+	pathCollisions []repository.PackagePathCollision
 
 	// TODO: Currently we support repositories with homogenous content (only packages xor functions). Model this more optimally?
 	cachedFunctions []repository.Function
@@ -61,21 +68,48 @@ type cachedRepository struct {
 	// This is returned back by the cache to the background goroutine when it calls periodicall to resync repositories.
 	refreshRevisionsError error
 	refreshPkgsError      error
+	// lastSynced is when the package revisions were last refreshed successfully, and
+	// lastSyncError the error of the last refresh, if it failed.
+	lastSynced    time.Time
+	lastSyncError error
+
+	// syncMutex guards syncStarted, which is read without waiting for a sync holding mutex.
+	syncMutex sync.Mutex
+	// syncStarted is when the initial sync started.
+	syncStarted time.Time
+	// synced is closed once the initial sync completed.
+	synced     chan struct{}
+	syncedOnce sync.Once
 
 	objectCache *objectCache
 
 	metadataStore meta.MetadataStore
+
+	// refreshRequests receives requests to refresh the repository out of band, such as
+	// from push webhooks.
+	refreshRequests chan struct{}
 }
 
+const (
+	// pollInterval is how often repositories are refreshed if they aren't refreshed on request.
+	pollInterval = 1 * time.Minute
+	// refreshDebounce is how long refresh requests are collected before refreshing, so a
+	// burst of pushes refreshes the repository once.
+	refreshDebounce = 5 * time.Second
+)
+
 func newRepository(id string, repoSpec *configapi.Repository, repo repository.Repository, objectCache *objectCache, metadataStore meta.MetadataStore) *cachedRepository {
 	ctx, cancel := context.WithCancel(context.Background())
 	r := &cachedRepository{
-		id:            id,
-		repoSpec:      repoSpec,
-		repo:          repo,
-		cancel:        cancel,
-		objectCache:   objectCache,
-		metadataStore: metadataStore,
+		id:              id,
+		repoSpec:        repoSpec,
+		repo:            repo,
+		ctx:             ctx,
+		cancel:          cancel,
+		objectCache:     objectCache,
+		metadataStore:   metadataStore,
+		refreshRequests: make(chan struct{}, 1),
+		synced:          make(chan struct{}),
 	}
 
 	// TODO: Should we fetch the packages here?
@@ -113,8 +147,11 @@ func (r *cachedRepository) getRefreshError() error {
 }
 
 func (r *cachedRepository) getPackageRevisions(ctx context.Context, filter repository.ListPackageRevisionFilter, forceRefresh bool) ([]repository.PackageRevision, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("cache sync wait started")
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	span.AddEvent("cache sync wait finished")
 
 	_, packageRevisions, err := r.getCachedPackages(ctx, forceRefresh)
 	if err != nil {
@@ -125,8 +162,11 @@ func (r *cachedRepository) getPackageRevisions(ctx context.Context, filter repos
 }
 
 func (r *cachedRepository) getPackages(ctx context.Context, filter repository.ListPackageFilter, forceRefresh bool) ([]repository.Package, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("cache sync wait started")
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	span.AddEvent("cache sync wait finished")
 
 	packages, _, err := r.getCachedPackages(ctx, forceRefresh)
 	if err != nil {
@@ -150,12 +190,66 @@ func (r *cachedRepository) getCachedPackages(ctx context.Context, forceRefresh b
 	}
 
 	if packages == nil {
+		r.markSyncStarted()
 		packages, packageRevisions, err = r.refreshAllCachedPackages(ctx)
+		r.lastSyncError = err
+		if err == nil {
+			r.lastSynced = time.Now()
+			r.syncedOnce.Do(func() { close(r.synced) })
+		}
 	}
 
 	return packages, packageRevisions, err
 }
 
+func (r *cachedRepository) markSyncStarted() {
+	r.syncMutex.Lock()
+	defer r.syncMutex.Unlock()
+
+	if r.syncStarted.IsZero() {
+		r.syncStarted = time.Now()
+	}
+}
+
+// SyncProgress returns the progress of the initial sync of the repository. It doesn't
+// wait for a sync in progress.
+func (r *cachedRepository) SyncProgress() repository.SyncProgress {
+	r.syncMutex.Lock()
+	started := r.syncStarted
+	r.syncMutex.Unlock()
+
+	select {
+	case <-r.synced:
+		return repository.SyncProgress{Synced: true, Started: started}
+	default:
+		return repository.SyncProgress{Started: started}
+	}
+}
+
+// WaitForSync blocks until the initial sync of the repository completes or the context
+// is done.
+func (r *cachedRepository) WaitForSync(ctx context.Context) error {
+	select {
+	case <-r.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startInitialSync syncs the repository in the background, so its content is available
+// without waiting for a read or the first poll to sync it.
+func (r *cachedRepository) startInitialSync() {
+	go func() {
+		ctx, span := tracer.Start(r.ctx, "Repository::initialSync", trace.WithAttributes())
+		defer span.End()
+
+		if _, err := r.getPackageRevisions(ctx, repository.ListPackageRevisionFilter{}, false); err != nil {
+			klog.Warningf("error syncing repo %s: %v", r.id, err)
+		}
+	}()
+}
+
 func (r *cachedRepository) getFunctions(ctx context.Context, force bool) ([]repository.Function, error) {
 	var functions []repository.Function
 
@@ -273,6 +367,23 @@ func (r *cachedRepository) CreatePackage(ctx context.Context, obj *v1alpha1.Pack
 	return r.repo.CreatePackage(ctx, obj)
 }
 
+func (r *cachedRepository) UpdatePackage(ctx context.Context, old repository.Package, new *v1alpha1.Package) (repository.Package, error) {
+	// Unwrap
+	oldPackage := old.(*cachedPackage)
+	updated, err := r.repo.UpdatePackage(ctx, oldPackage.Package, new)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: Do something more efficient than a full cache flush
+	r.flush()
+
+	return &cachedPackage{
+		Package:               updated,
+		latestPackageRevision: oldPackage.latestPackageRevision,
+	}, nil
+}
+
 func (r *cachedRepository) DeletePackage(ctx context.Context, old repository.Package) error {
 	// Unwrap
 	unwrapped := old.(*cachedPackage).Package
@@ -286,18 +397,64 @@ func (r *cachedRepository) DeletePackage(ctx context.Context, old repository.Pac
 	return nil
 }
 
+// PruneStaleRefs prunes the stale references of the underlying repository, if it
+// supports pruning.
+func (r *cachedRepository) PruneStaleRefs(ctx context.Context, dryRun bool) (*repository.PruneResult, error) {
+	pruner, ok := r.repo.(repository.RefPruner)
+	if !ok {
+		return nil, fmt.Errorf("repository %s does not support pruning", r.id)
+	}
+	result, err := pruner.PruneStaleRefs(ctx, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun && len(result.Refs) > 0 {
+		r.flush()
+	}
+	return result, nil
+}
+
+// PackagePathCollisions returns the colliding package paths found by the last refresh
+// of the repository.
+func (r *cachedRepository) PackagePathCollisions() []repository.PackagePathCollision {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.pathCollisions
+}
+
 func (r *cachedRepository) Close() error {
 	r.cancel()
 	return nil
 }
 
 // pollForever will continue polling until signal channel is closed or ctx is done.
+// Refresh requests are debounced by refreshDebounce; while they keep the repository
+// fresh, the periodic poll is skipped.
 func (r *cachedRepository) pollForever(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var lastRefresh time.Time
+	var debounce <-chan time.Time
 	for {
 		select {
 		case <-ticker.C:
+			if time.Since(lastRefresh) < pollInterval {
+				continue
+			}
+			r.pollOnce(ctx)
+			lastRefresh = time.Now()
+
+		case <-r.refreshRequests:
+			if debounce == nil {
+				debounce = time.After(refreshDebounce)
+			}
+
+		case <-debounce:
+			debounce = nil
 			r.pollOnce(ctx)
+			lastRefresh = time.Now()
 
 		case <-ctx.Done():
 			klog.V(2).Infof("exiting repository poller, because context is done: %v", ctx.Err())
@@ -306,6 +463,15 @@ func (r *cachedRepository) pollForever(ctx context.Context) {
 	}
 }
 
+// requestRefresh schedules a refresh of the repository. Requests made while a refresh is
+// already scheduled are merged into it.
+func (r *cachedRepository) requestRefresh() {
+	select {
+	case r.refreshRequests <- struct{}{}:
+	default:
+	}
+}
+
 func (r *cachedRepository) pollOnce(ctx context.Context) {
 	klog.Infof("background-refreshing repo %q", r.id)
 	ctx, span := tracer.Start(ctx, "Repository::pollOnce", trace.WithAttributes())
@@ -341,10 +507,13 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 	// Look up all existing PackageRevCRs so we an compare those to the
 	// actual Packagerevisions found in git/oci, and add/prune PackageRevCRs
 	// as necessary.
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("metadata batch fetch started")
 	existingPkgRevCRs, err := r.metadataStore.List(ctx, r.repoSpec)
 	if err != nil {
 		return nil, nil, err
 	}
+	span.AddEvent("metadata batch fetch finished", trace.WithAttributes(attribute.Int("count", len(existingPkgRevCRs))))
 	// Create a map so we can quickly check if a specific PackageRevisionMeta exists.
 	existingPkgRevCRsMap := make(map[string]bool)
 	for _, pr := range existingPkgRevCRs {
@@ -358,7 +527,7 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 	}
 
 	newPackageRevisionMap := make(map[repository.PackageRevisionKey]*cachedPackageRevision, len(newPackageRevisions))
-	newPackageRevisionNames := make(map[string]bool)
+	newPackageRevisionNames := make(map[string]repository.PackageRevision)
 	for _, newPackage := range newPackageRevisions {
 		k := newPackage.Key()
 		if newPackageRevisionMap[k] != nil {
@@ -369,11 +538,23 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 			PackageRevision:  newPackage,
 			isLatestRevision: false,
 		}
-		newPackageRevisionNames[newPackage.KubeObjectName()] = true
+		newPackageRevisionNames[newPackage.KubeObjectName()] = newPackage
 	}
 
 	identifyLatestRevisions(newPackageRevisionMap)
 
+	// Packages whose paths differ only by case collide when checked out on a
+	// case-insensitive filesystem. Both are kept and the collision is reported, rather
+	// than picking one of them.
+	var packagePaths []string
+	for k := range newPackageRevisionMap {
+		packagePaths = append(packagePaths, k.Package)
+	}
+	r.pathCollisions = repository.FindPackagePathCollisions(packagePaths)
+	for _, c := range r.pathCollisions {
+		klog.Warningf("repository %s has colliding package paths %q and %q", r.id, c.Path, c.Other)
+	}
+
 	newPackageMap := make(map[repository.PackageKey]*cachedPackage)
 
 	for _, newPackageRevision := range newPackageRevisionMap {
@@ -394,7 +575,8 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 	// in the current repo and make sure they all have a corresponding
 	// PackageRevision. The ones that doesn't is removed.
 	for _, prm := range existingPkgRevCRs {
-		if _, found := newPackageRevisionNames[prm.Name]; !found {
+		pr, found := newPackageRevisionNames[prm.Name]
+		if !found {
 			if _, err := r.metadataStore.Delete(ctx, types.NamespacedName{
 				Name:      prm.Name,
 				Namespace: prm.Namespace,
@@ -405,16 +587,28 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 						prm.Name, prm.Namespace, err)
 				}
 			}
+			continue
+		}
+		// PackageRev CRs created before Porch recorded lifecycle times get them
+		// backfilled once.
+		if prm.LifecycleTimes.IsZero() {
+			if prm.LifecycleTimes = backfillLifecycleTimes(ctx, pr); !prm.LifecycleTimes.IsZero() {
+				if _, err := r.metadataStore.Update(ctx, prm); err != nil {
+					klog.Warningf("unable to backfill lifecycle times of PackageRev CR %s/%s: %v",
+						prm.Namespace, prm.Name, err)
+				}
+			}
 		}
 	}
 
 	// We go through all the PackageRevisions and make sure they have
 	// a corresponding PackageRev CR.
-	for pkgRevName := range newPackageRevisionNames {
+	for pkgRevName, pr := range newPackageRevisionNames {
 		if _, found := existingPkgRevCRsMap[pkgRevName]; !found {
 			pkgRevMeta := meta.PackageRevisionMeta{
-				Name:      pkgRevName,
-				Namespace: r.repoSpec.Namespace,
+				Name:           pkgRevName,
+				Namespace:      r.repoSpec.Namespace,
+				LifecycleTimes: backfillLifecycleTimes(ctx, pr),
 			}
 			if _, err := r.metadataStore.Create(ctx, pkgRevMeta, r.repoSpec); err != nil {
 				// TODO: We should try to find a way to make these errors available through
@@ -446,3 +640,24 @@ func (r *cachedRepository) refreshAllCachedPackages(ctx context.Context) (map[re
 
 	return newPackageMap, newPackageRevisionMap, nil
 }
+
+// backfillLifecycleTimes estimates the lifecycle times of a package revision which
+// Porch didn't record, from the commit timestamps in the repository. This is best
+// effort: the time of the last change of a draft or proposed package revision stands
+// in for when it was created or proposed.
+func backfillLifecycleTimes(ctx context.Context, pr repository.PackageRevision) meta.LifecycleTimes {
+	apiPr, err := pr.GetPackageRevision(ctx)
+	if err != nil || apiPr == nil {
+		return meta.LifecycleTimes{}
+	}
+	switch pr.Lifecycle() {
+	case v1alpha1.PackageRevisionLifecycleDraft:
+		return meta.LifecycleTimes{DraftCreatedAt: apiPr.CreationTimestamp}
+	case v1alpha1.PackageRevisionLifecycleProposed:
+		return meta.LifecycleTimes{ProposedAt: apiPr.CreationTimestamp}
+	case v1alpha1.PackageRevisionLifecyclePublished:
+		return meta.LifecycleTimes{PublishedAt: apiPr.Status.PublishedAt}
+	default:
+		return meta.LifecycleTimes{}
+	}
+}
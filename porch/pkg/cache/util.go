@@ -15,8 +15,10 @@
 package cache
 
 import (
+	"context"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
@@ -44,8 +46,11 @@ func identifyLatestRevisions(result map[repository.PackageRevisionKey]*cachedPac
 			previousKey := previous.Key()
 			switch cmp := semver.Compare(currentKey.Revision, previousKey.Revision); {
 			case cmp == 0:
-				// Same revision.
+				// Same revision; the one published last wins.
 				klog.Warningf("Encountered package revisions whose versions compare equal: %q, %q", currentKey, previousKey)
+				if publishedAfter(current, previous) {
+					latest[currentKey.Package] = current
+				}
 			case cmp < 0:
 				// currentKey.Revision < previousKey.Revision; no change
 			case cmp > 0:
@@ -63,6 +68,26 @@ func identifyLatestRevisions(result map[repository.PackageRevisionKey]*cachedPac
 	}
 }
 
+// publishedAfter returns true if package revision a was published after b, by the commit
+// times the git repository reports as their publish times. Package revisions published at
+// the same time are ordered by name, so that the result doesn't depend on map order.
+func publishedAfter(a, b *cachedPackageRevision) bool {
+	ta, tb := publishedAt(a), publishedAt(b)
+	if !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	return a.KubeObjectName() > b.KubeObjectName()
+}
+
+func publishedAt(pr *cachedPackageRevision) time.Time {
+	apiPr, err := pr.PackageRevision.GetPackageRevision(context.Background())
+	if err != nil {
+		klog.Warningf("Cannot get publish time of package revision %q: %v", pr.KubeObjectName(), err)
+		return time.Time{}
+	}
+	return apiPr.Status.PublishedAt.Time
+}
+
 func toPackageRevisionSlice(cached map[repository.PackageRevisionKey]*cachedPackageRevision, filter repository.ListPackageRevisionFilter) []repository.PackageRevision {
 	result := make([]repository.PackageRevision, 0, len(cached))
 	for _, p := range cached {
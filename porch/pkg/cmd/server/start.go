@@ -20,6 +20,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -28,7 +29,10 @@ import (
 	informers "github.com/GoogleContainerTools/kpt/porch/api/generated/informers/externalversions"
 	sampleopenapi "github.com/GoogleContainerTools/kpt/porch/api/generated/openapi"
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/apiserver"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/admission"
@@ -50,6 +54,40 @@ type PorchServerOptions struct {
 	CacheDirectory           string
 	CoreAPIKubeconfigPath    string
 	FunctionRunnerAddress    string
+	StrictTaskValidation     bool
+	RenderConcurrency        int
+	DeferRender              bool
+	ForceRender              bool
+	RenderChangeReport       bool
+	StrictTasks              bool
+	StampDeploymentNamespace bool
+	UpstreamFallback         bool
+	AllowedFunctionImages    []string
+	RestrictExternalSources  bool
+	FunctionSecretNamespaces []string
+	EvalConflictPolicy       string
+	WorkspaceNameTemplate    string
+	DescriptionTemplate      string
+	UpstreamVerificationKeys string
+	StarterResources         string
+	ProposalWebhook          string
+	ProposalDiffBytes        int
+	FluxArtifactRegistry     string
+	FetchTimeout             time.Duration
+	RenderTimeout            time.Duration
+	CloseTimeout             time.Duration
+	StagingTTL               time.Duration
+	FunctionLogTTL           time.Duration
+	DraftBufferBytes         int64
+	AutoProposeDelay         time.Duration
+	RenderToolchain          string
+	ToolchainFunctionRunners map[string]string
+	TraceReadSampleRate      float64
+	ReadinessSyncedFraction  float64
+	ReadinessSyncThreshold   time.Duration
+	RepositorySyncWait       time.Duration
+	MetadataStoreBackend     string
+	MigrateMetadataFrom      string
 
 	SharedInformerFactory informers.SharedInformerFactory
 	StdOut                io.Writer
@@ -128,6 +166,12 @@ func (o *PorchServerOptions) Complete() error {
 		}
 	}
 
+	if o.RecommendedOptions.Authorization != nil {
+		// The kube-apiserver calls admission webhooks without credentials, and Git providers
+		// call push webhooks without credentials; they are authenticated by a shared secret.
+		o.RecommendedOptions.Authorization.AlwaysAllowPaths = append(o.RecommendedOptions.Authorization.AlwaysAllowPaths, apiserver.RepositoryValidationPath, apiserver.PushWebhookPath)
+	}
+
 	if o.CacheDirectory == "" {
 		cache, err := os.UserCacheDir()
 		if err != nil {
@@ -179,9 +223,47 @@ func (o *PorchServerOptions) Config() (*apiserver.Config, error) {
 	config := &apiserver.Config{
 		GenericConfig: serverConfig,
 		ExtraConfig: apiserver.ExtraConfig{
-			CoreAPIKubeconfigPath: o.CoreAPIKubeconfigPath,
-			CacheDirectory:        o.CacheDirectory,
-			FunctionRunnerAddress: o.FunctionRunnerAddress,
+			CoreAPIKubeconfigPath:    o.CoreAPIKubeconfigPath,
+			CacheDirectory:           o.CacheDirectory,
+			FunctionRunnerAddress:    o.FunctionRunnerAddress,
+			StrictTaskValidation:     o.StrictTaskValidation,
+			RenderConcurrency:        o.RenderConcurrency,
+			DeferRender:              o.DeferRender,
+			ForceRender:              o.ForceRender,
+			RenderChangeReport:       o.RenderChangeReport,
+			StrictTasks:              o.StrictTasks,
+			StampDeploymentNamespace: o.StampDeploymentNamespace,
+			UpstreamFallback:         o.UpstreamFallback,
+			AllowedFunctionImages:    o.AllowedFunctionImages,
+			RestrictExternalSources:  o.RestrictExternalSources,
+			FunctionSecretNamespaces: o.FunctionSecretNamespaces,
+			EvalConflictPolicy:       o.EvalConflictPolicy,
+			WorkspaceNameTemplate:    o.WorkspaceNameTemplate,
+			DescriptionTemplate:      o.DescriptionTemplate,
+			UpstreamVerificationKeys: o.UpstreamVerificationKeys,
+			StarterResources:         o.StarterResources,
+			ProposalWebhook:          o.ProposalWebhook,
+			ProposalDiffBytes:        o.ProposalDiffBytes,
+			FluxArtifactRegistry:     o.FluxArtifactRegistry,
+			PhaseTimeouts: engine.PhaseTimeouts{
+				Fetch:  o.FetchTimeout,
+				Render: o.RenderTimeout,
+				Close:  o.CloseTimeout,
+			},
+			StagingTTL:               o.StagingTTL,
+			FunctionLogTTL:           o.FunctionLogTTL,
+			DraftBufferBytes:         o.DraftBufferBytes,
+			AutoProposeDelay:         o.AutoProposeDelay,
+			RenderToolchain:          o.RenderToolchain,
+			ToolchainFunctionRunners: o.ToolchainFunctionRunners,
+			TraceReadSampleRate:      o.TraceReadSampleRate,
+			Readiness: apiserver.ReadinessPolicy{
+				MinSyncedFraction: o.ReadinessSyncedFraction,
+				SyncThreshold:     o.ReadinessSyncThreshold,
+			},
+			RepositorySyncWait:   o.RepositorySyncWait,
+			MetadataStoreBackend: meta.Backend(o.MetadataStoreBackend),
+			MigrateMetadataFrom:  meta.Backend(o.MigrateMetadataFrom),
 		},
 	}
 	return config, nil
@@ -226,4 +308,60 @@ func (o *PorchServerOptions) AddFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&o.FunctionRunnerAddress, "function-runner", "", "Address of the function runner gRPC service.")
 	fs.StringVar(&o.CacheDirectory, "cache-directory", "", "Directory where Porch server stores repository and package caches.")
+	fs.BoolVar(&o.StrictTaskValidation, "strict-task-validation", false, "Reject package revisions whose spec.tasks contain unknown fields instead of silently dropping them.")
+	fs.IntVar(&o.RenderConcurrency, "render-concurrency", 4, "Maximum number of independent subpackages rendered concurrently. Values below 2 render subpackages sequentially.")
+	fs.BoolVar(&o.DeferRender, "defer-render", false, "Store the resources of draft package revisions without rendering them; drafts are marked with the "+
+		porchv1alpha1.RenderedConditionType+" condition and rendered when they are proposed or published.")
+	fs.BoolVar(&o.ForceRender, "force-render", false, "Render packages after their tasks even if their Kptfiles declare no pipeline, to normalize the formatting of their resources.")
+	fs.BoolVar(&o.RenderChangeReport, "render-change-report", false, "Record which pipeline function changed which resources on every render task, rather than only on render tasks that request it.")
+	fs.BoolVar(&o.StrictTasks, "strict-tasks", false, "Reject package revisions whose tasks don't start with an init or clone task instead of inserting an init task. "+
+		"Can be enabled for a single repository with the "+configapi.StrictTasksAnnotation+" annotation.")
+	fs.BoolVar(&o.StampDeploymentNamespace, "stamp-deployment-namespace", false, "Set the namespace of the namespaced resources of packages cloned into deployment repositories "+
+		"to the package name from the package context. The namespace can be set for a single repository with the "+configapi.DeploymentNamespaceAnnotation+" annotation.")
+	fs.BoolVar(&o.UpstreamFallback, "upstream-fallback", false, "When the upstream revision a package was cloned from has been deleted, update the package "+
+		"using the latest earlier revision of the upstream package as the base instead of failing.")
+	fs.StringSliceVar(&o.AllowedFunctionImages, "allowed-function-images", nil, "Function images package revisions may run in eval tasks and Kptfile pipelines. "+
+		"Entries ending with * match any image with the prefix. If unset, all images are allowed.")
+	fs.BoolVar(&o.RestrictExternalSources, "restrict-external-sources", false, "Forbid package revisions from fetching from outside the registered repositories: "+
+		"git repositories and OCI images of clone and update tasks must be stored in repositories registered in the namespace, and functions must match --allowed-function-images, which is then required.")
+	fs.StringSliceVar(&o.FunctionSecretNamespaces, "function-secret-namespaces", nil, "Namespaces whose package revisions may reference Secrets in the function config of eval tasks. "+
+		"The porch service account must be able to read the Secrets of these namespaces.")
+	fs.StringVar(&o.EvalConflictPolicy, "eval-conflict-policy", string(engine.EvalConflictPolicyError), "How to resolve eval tasks setting the same resource field to different values: error, first-wins or last-wins.")
+	fs.StringVar(&o.WorkspaceNameTemplate, "workspace-name-template", "", "Go template generating the workspace name of package revisions created without a revision, "+
+		"using {{.Package}}, {{.PackagePath}}, {{.User}}, {{.Timestamp}} and {{.Key}} (from the "+porchv1alpha1.WorkspaceKeyAnnotation+" annotation).")
+	fs.StringVar(&o.DescriptionTemplate, "description-template", "", "Go template generating the description of packages created without one, "+
+		"using {{.Package}}, {{.PackagePath}} and {{.Repository}}. The "+configapi.DescriptionTemplateAnnotation+" annotation of a repository takes precedence.")
+	fs.StringVar(&o.UpstreamVerificationKeys, "upstream-verification-keys", "", "Path to a file with PEM-encoded public keys. If set, clone rejects upstream packages "+
+		"without a "+engine.UpstreamSignatureFile+" signature made by one of the keys.")
+	fs.StringVar(&o.StarterResources, "starter-resources", "", "Directory of YAML files init tasks can add to packages as starter resources, selected by file name without the extension. "+
+		"The files are Go templates using {{.Package}} and {{.PackagePath}}. If unset, the namespace and resourcequota starter resources are available.")
+	fs.StringVar(&o.ProposalWebhook, "proposal-webhook", "", "URL to post an event to when a package revision is proposed. The event includes the changed files "+
+		"and their unified diffs against the latest published revision of the package.")
+	fs.IntVar(&o.ProposalDiffBytes, "proposal-diff-bytes", engine.DefaultProposalDiffBytes, "Maximum total size of the diffs in proposal events. The diffs of files beyond it are left out and marked as truncated.")
+	fs.StringVar(&o.FluxArtifactRegistry, "flux-artifact-registry", "", "Registry path, e.g. ghcr.io/example/packages, to push published package revisions to as Flux OCI artifacts. "+
+		"Artifacts are pushed to <registry>/<repository>/<package>:<revision> and recorded in status.artifacts.")
+	fs.DurationVar(&o.FetchTimeout, "fetch-timeout", 0, "Maximum duration of fetching the upstream package of a clone or update task. Zero means unlimited.")
+	fs.DurationVar(&o.RenderTimeout, "render-timeout", 0, "Maximum duration of rendering a package or evaluating the function of an eval task. Zero means unlimited.")
+	fs.DurationVar(&o.CloseTimeout, "close-timeout", 0, "Maximum duration of writing a package revision to its repository. Zero means unlimited.")
+	fs.DurationVar(&o.StagingTTL, "staging-ttl", time.Hour, "How long the staged chunks of an interrupted resumable upload are kept without activity. Staged chunks are stored in the cache directory.")
+	fs.DurationVar(&o.FunctionLogTTL, "function-log-ttl", engine.DefaultFunctionLogTTL, "How long the logs of the functions run by the last create or update of a package revision "+
+		"are kept in memory for the logs subresource. Zero disables keeping them.")
+	fs.Int64Var(&o.DraftBufferBytes, "draft-buffer-bytes", engine.DefaultDraftBufferBytes, "Package size up to which the contents of the tasks applied to a package revision "+
+		"are kept in memory and written to the repository together when the package revision is stored. Zero writes the contents of each task right away.")
+	fs.DurationVar(&o.AutoProposeDelay, "auto-propose-delay", 30*time.Second, "How long the readiness gates of a draft annotated with porch.kpt.dev/auto-propose must have true conditions before the draft is proposed. Zero disables automatic proposals.")
+	fs.StringVar(&o.RenderToolchain, "render-toolchain", "", "Version of the render toolchain, recorded on new package revisions with the "+
+		porchv1alpha1.RenderToolchainAnnotation+" annotation.")
+	fs.StringToStringVar(&o.ToolchainFunctionRunners, "toolchain-function-runners", nil, "Addresses of the function runner gRPC services rendering package revisions "+
+		"pinned to earlier render toolchain versions, as version=address pairs.")
+	fs.Float64Var(&o.TraceReadSampleRate, "trace-read-sample-rate", 1, "Rate, between 0 and 1, at which the traces of reads such as list and watch requests are sampled. "+
+		"Traces of mutating requests are always sampled.")
+	fs.Float64Var(&o.ReadinessSyncedFraction, "readiness-synced-fraction", 0, "Fraction, between 0 and 1, of the repositories which must have synced within the readiness sync threshold for the server to be ready. "+
+		"By default readiness only requires the metadata store to be reachable.")
+	fs.DurationVar(&o.ReadinessSyncThreshold, "readiness-sync-threshold", 5*time.Minute, "How recently a repository must have synced to count towards readiness.")
+	fs.DurationVar(&o.RepositorySyncWait, "repository-sync-wait", 0, "How long reads of a repository wait for its initial sync to complete. "+
+		"Zero makes such reads fail right away with a ServiceUnavailable status, asking clients to retry later.")
+	fs.StringVar(&o.MetadataStoreBackend, "metadata-store", string(meta.CRDBackend), "Where the metadata of package revisions, such as their labels and annotations, is kept: "+
+		"crd, in PackageRev custom resources, or configmap, in ConfigMaps of the namespaces of the repositories, for clusters where the PackageRev CRD can't be installed.")
+	fs.StringVar(&o.MigrateMetadataFrom, "migrate-metadata-from", "", "Metadata store backend, crd or configmap, to copy the metadata of the package revisions of all repositories from "+
+		"into the --metadata-store backend when the server starts. The source is left unchanged.")
 }
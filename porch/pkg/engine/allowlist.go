@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// FunctionNotAllowedError is returned when a package revision would run a function whose
+// image isn't allowed by AllowedFunctionImages.
+type FunctionNotAllowedError struct {
+	Image string
+	// Source is where the function is declared: an eval task, the pipeline of a Kptfile,
+	// or the pipeline-append annotation of the package revision.
+	Source string
+}
+
+func (e *FunctionNotAllowedError) Error() string {
+	return fmt.Sprintf("function %q of %s is not in the allowed function images", e.Image, e.Source)
+}
+
+// AllowedFunctionImages restricts the function images package revisions can run, both
+// in eval tasks and in the Kptfile pipelines run when rendering. An entry matches an image
+// exactly, or, if it ends with "*", any image starting with the rest of the entry.
+// Short catalog images such as "set-labels:v0.1" are matched as "gcr.io/kpt-fn/set-labels:v0.1".
+// An empty list allows all images.
+type AllowedFunctionImages []string
+
+// allows returns true if the image matches an entry of the list.
+func (a AllowedFunctionImages) allows(ctx context.Context, image string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	image, _ = fnruntime.ResolveToImageForCLI(ctx, image)
+	for _, allowed := range a {
+		if prefix := strings.TrimSuffix(allowed, "*"); prefix != allowed {
+			if strings.HasPrefix(image, prefix) {
+				return true
+			}
+		} else if image == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEval returns an error if the image of an eval task isn't allowed.
+func (a AllowedFunctionImages) checkEval(ctx context.Context, image string) error {
+	if !a.allows(ctx, image) {
+		return &FunctionNotAllowedError{Image: image, Source: "eval task"}
+	}
+	return nil
+}
+
+// checkPipelines returns an error naming the first function of a Kptfile pipeline of the
+// package which isn't allowed. Exec functions are never allowed if the list is set, since
+// they aren't identified by an image.
+func (a AllowedFunctionImages) checkPipelines(ctx context.Context, resources repository.PackageResources) error {
+	if len(a) == 0 {
+		return nil
+	}
+	var kptfiles []string
+	for name := range resources.Contents {
+		if path.Base(name) == kptfile.KptFileName {
+			kptfiles = append(kptfiles, name)
+		}
+	}
+	sort.Strings(kptfiles)
+
+	for _, name := range kptfiles {
+		kf, err := internalpkg.DecodeKptfile(strings.NewReader(resources.Contents[name]))
+		if err != nil {
+			return fmt.Errorf("cannot read pipeline of %s: %w", name, err)
+		}
+		if kf.Pipeline == nil {
+			continue
+		}
+		source := "the pipeline of " + name
+		for _, functions := range [][]kptfile.Function{kf.Pipeline.Mutators, kf.Pipeline.Validators} {
+			if err := a.checkFunctions(ctx, functions, source); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFunctions returns an error naming the first of the functions declared in source
+// which isn't allowed.
+func (a AllowedFunctionImages) checkFunctions(ctx context.Context, functions []kptfile.Function, source string) error {
+	if len(a) == 0 {
+		return nil
+	}
+	for _, function := range functions {
+		if function.Image == "" {
+			return &FunctionNotAllowedError{Image: "exec: " + function.Exec, Source: source}
+		}
+		if !a.allows(ctx, function.Image) {
+			return &FunctionNotAllowedError{Image: function.Image, Source: source}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func TestAllowedFunctionImages(t *testing.T) {
+	ctx := context.Background()
+	allowed := AllowedFunctionImages{"gcr.io/kpt-fn/*", "example.com/fns/audit:v1"}
+
+	const kf = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+pipeline:
+  mutators:
+  - image: set-namespace:v0.4.1
+  validators:
+  - image: example.com/fns/audit:v1
+`
+	const smuggling = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: db
+pipeline:
+  mutators:
+  - image: example.com/fns/mine-bitcoin:v1
+`
+
+	t.Run("render", func(t *testing.T) {
+		renderer := &countingRenderer{}
+		render := &renderPackageMutation{renderer: renderer, allowedImages: allowed}
+
+		if _, _, err := render.Apply(ctx, repository.PackageResources{Contents: map[string]string{"Kptfile": kf}}); err != nil {
+			t.Fatalf("render of a package with allowed functions failed: %v", err)
+		}
+
+		// A disallowed function in the pipeline of a subpackage.
+		_, _, err := render.Apply(ctx, repository.PackageResources{Contents: map[string]string{
+			"Kptfile":    kf,
+			"db/Kptfile": smuggling,
+		}})
+		var notAllowed *FunctionNotAllowedError
+		if !errors.As(err, &notAllowed) {
+			t.Fatalf("render returned %v; want a FunctionNotAllowedError", err)
+		}
+		if got, want := notAllowed.Image, "example.com/fns/mine-bitcoin:v1"; got != want {
+			t.Errorf("unexpected disallowed function: got %q, want %q", got, want)
+		}
+		if got, want := notAllowed.Source, "the pipeline of db/Kptfile"; got != want {
+			t.Errorf("unexpected source of the disallowed function: got %q, want %q", got, want)
+		}
+		if renderer.renders != 1 {
+			t.Errorf("package with a disallowed function was rendered")
+		}
+	})
+
+	t.Run("eval", func(t *testing.T) {
+		eval := &evalFunctionMutation{
+			task:          &api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: "example.com/fns/mine-bitcoin:v1"}},
+			allowedImages: allowed,
+		}
+		_, _, err := eval.Apply(ctx, repository.PackageResources{})
+		var notAllowed *FunctionNotAllowedError
+		if !errors.As(err, &notAllowed) {
+			t.Fatalf("eval returned %v; want a FunctionNotAllowedError", err)
+		}
+	})
+
+	t.Run("unrestricted", func(t *testing.T) {
+		if err := AllowedFunctionImages(nil).checkPipelines(ctx, repository.PackageResources{Contents: map[string]string{"Kptfile": smuggling}}); err != nil {
+			t.Errorf("empty allowlist rejected a function: %v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppendTasks(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	initTask := api.Task{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}}
+	eval := func(image string) api.Task {
+		return api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{
+			Image:     image,
+			ConfigMap: map[string]string{"namespace": "prod"},
+		}}
+	}
+	createFile := api.Task{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+		File:      "README.md",
+		PatchType: api.PatchTypeCreateFile,
+		Contents:  "# app\n",
+	}}}}
+
+	update := func(t *testing.T, cad *cadEngine, appended ...api.Task) (*countingRepository, error) {
+		t.Helper()
+		oldObj := &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-1234", Namespace: "default"},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "app",
+				Revision:    "v1",
+				Lifecycle:   api.PackageRevisionLifecycleDraft,
+				Tasks:       []api.Task{initTask},
+			},
+		}
+		newObj := oldObj.DeepCopy()
+		newObj.Spec.Tasks = append(newObj.Spec.Tasks, appended...)
+
+		repoRev := &fake.PackageRevision{
+			Name:             oldObj.Name,
+			Namespace:        oldObj.Namespace,
+			PackageLifecycle: oldObj.Spec.Lifecycle,
+			PackageRevision:  oldObj,
+			Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+				kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+				"configmap.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+			}}},
+		}
+		pkgRevMeta := meta.PackageRevisionMeta{Name: oldObj.Name, Namespace: oldObj.Namespace}
+		cad.metadataStore = &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+		repo := &countingRepository{}
+		oldPackage := &PackageRevision{repoPackageRevision: repoRev, packageRevisionMeta: pkgRevMeta}
+		_, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, nil)
+		return repo, err
+	}
+
+	t.Run("in order", func(t *testing.T) {
+		cad := &cadEngine{renderer: &countingRenderer{}, runtime: kpt.NewSimpleFunctionRuntime()}
+		repo, err := update(t, cad, eval("gcr.io/kpt-fn/set-namespace:v0.4.1"), createFile)
+		if err != nil {
+			t.Fatalf("updatePackageRevision failed: %v", err)
+		}
+
+		var tasks []api.TaskType
+		for _, task := range repo.draft.tasks {
+			tasks = append(tasks, task.Type)
+		}
+		if diff := cmp.Diff([]api.TaskType{api.TaskTypeEval, api.TaskTypePatch}, tasks); diff != "" {
+			t.Errorf("unexpected tasks applied to the draft (-want, +got): %s", diff)
+		}
+		if got := repo.draft.resources["configmap.yaml"]; !strings.Contains(got, "namespace: prod") {
+			t.Errorf("eval task wasn't applied:\n%s", got)
+		}
+		if _, found := repo.draft.resources["README.md"]; !found {
+			t.Errorf("patch task wasn't applied after the eval task")
+		}
+	})
+
+	t.Run("failed task", func(t *testing.T) {
+		cad := &cadEngine{renderer: &countingRenderer{}, runtime: kpt.NewSimpleFunctionRuntime(), draftBufferBytes: DefaultDraftBufferBytes}
+		repo, err := update(t, cad, eval("gcr.io/kpt-fn/set-namespace:v0.4.1"), eval("gcr.io/kpt-fn/no-such-function:v0.1"))
+		if err == nil {
+			t.Fatalf("updatePackageRevision succeeded despite a failing task")
+		}
+		if len(repo.draft.tasks) != 0 {
+			t.Errorf("tasks before the failed task were written to the draft: %v", repo.draft.tasks)
+		}
+	})
+
+	t.Run("creation task", func(t *testing.T) {
+		cad := &cadEngine{renderer: &countingRenderer{}, runtime: kpt.NewSimpleFunctionRuntime()}
+		repo, err := update(t, cad, createFile, initTask)
+		if err == nil || !strings.Contains(err.Error(), "appended task 2") {
+			t.Errorf("appending an init task returned %v; want an error naming task 2", err)
+		}
+		if repo.draft != nil {
+			t.Errorf("draft was opened for invalid tasks")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// Archiving a package is recorded in the metadata of its package revisions only; the
+// contents of the repository are left untouched, so archiving and restoring are
+// instantaneous and lossless. A package is archived if all of its package revisions are.
+
+// IsArchived returns true if the package of the package revision is archived.
+func (p *PackageRevision) IsArchived() bool {
+	return p.packageRevisionMeta.IsArchived()
+}
+
+// IsArchived returns true if the package is archived.
+func (p *Package) IsArchived() bool {
+	return p.archived
+}
+
+// archivedPackages returns the names of the archived packages among the package
+// revisions.
+func archivedPackages(revisions []*PackageRevision) map[string]bool {
+	archived := map[string]bool{}
+	for _, rev := range revisions {
+		name := rev.repoPackageRevision.Key().Package
+		if all, found := archived[name]; !found || all {
+			archived[name] = rev.IsArchived()
+		}
+	}
+	for name, all := range archived {
+		if !all {
+			delete(archived, name)
+		}
+	}
+	return archived
+}
+
+// setPackageArchived archives or restores all package revisions of the package.
+func (cad *cadEngine) setPackageArchived(ctx context.Context, repositoryObj *configapi.Repository, packageName string, archived bool) error {
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		if rev.IsArchived() == archived {
+			continue
+		}
+		pkgRevMeta := rev.packageRevisionMeta
+		pkgRevMeta.Archived = &archived
+		// Leave the status of the PackageRev alone.
+		pkgRevMeta.Artifacts = nil
+		pkgRevMeta.DeploymentStatus = nil
+		pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+		if _, err := cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return fmt.Errorf("cannot update metadata of package revision %q: %w", rev.KubeObjectName(), err)
+		}
+	}
+	return nil
+}
+
+// checkPackageNotArchived returns an error if the package is archived.
+func (cad *cadEngine) checkPackageNotArchived(ctx context.Context, repositoryObj *configapi.Repository, packageName string) error {
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return err
+	}
+	if archivedPackages(revisions)[packageName] {
+		return fmt.Errorf("package %q in repository %q is archived; restore it to create new revisions", packageName, repositoryObj.Name)
+	}
+	return nil
+}
+
+// isSafeDelete returns true if packages in the repository must be archived before
+// they can be deleted.
+func isSafeDelete(repositoryObj *configapi.Repository) bool {
+	return repositoryObj.Annotations[configapi.SafeDeleteAnnotation] == "true"
+}
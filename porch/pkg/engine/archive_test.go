@@ -0,0 +1,74 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchivedPackages(t *testing.T) {
+	revision := func(pkg, rev string, archived *bool) *PackageRevision {
+		return &PackageRevision{
+			repoPackageRevision: &fake.PackageRevision{
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: pkg, Revision: rev},
+			},
+			packageRevisionMeta: meta.PackageRevisionMeta{Archived: archived},
+		}
+	}
+	yes, no := true, false
+
+	revisions := []*PackageRevision{
+		revision("archived", "v1", &yes),
+		revision("archived", "v2", &yes),
+		// A package is only archived if all of its revisions are.
+		revision("partial", "v1", &yes),
+		revision("partial", "v2", &no),
+		revision("partial", "v3", &yes),
+		revision("active", "v1", nil),
+	}
+	if diff := cmp.Diff(map[string]bool{"archived": true}, archivedPackages(revisions)); diff != "" {
+		t.Errorf("unexpected archived packages (-want, +got): %s", diff)
+	}
+	if got := archivedPackages(nil); len(got) != 0 {
+		t.Errorf("unexpected archived packages without package revisions: %v", got)
+	}
+}
+
+func TestSafeDeleteOfUnarchivedPackageConflicts(t *testing.T) {
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+		Name:        "blueprints",
+		Namespace:   "default",
+		Annotations: map[string]string{configapi.SafeDeleteAnnotation: "true"},
+	}}
+	pkg := &Package{repoPackage: &fake.Package{
+		Name:       "blueprints-app",
+		PackageKey: repository.PackageKey{Repository: "blueprints", Package: "app"},
+	}}
+
+	// The package is refused before the repository is opened.
+	err := (&cadEngine{}).DeletePackage(context.Background(), repositoryObj, pkg)
+	if !apierrors.IsConflict(err) {
+		t.Errorf("deleting an unarchived package returned %v; want a conflict", err)
+	}
+}
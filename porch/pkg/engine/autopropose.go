@@ -0,0 +1,173 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+// autoProposeActor is the actor of the timeline events of automatic proposals.
+const autoProposeActor = "porch:auto-propose"
+
+// autoProposer proposes drafts with the api.AutoProposeAnnotation annotation once all
+// their readiness gates have true conditions. A draft is only proposed after its
+// conditions stayed true for the delay, so conditions flapping between updates don't
+// propose it.
+type autoProposer struct {
+	delay   time.Duration
+	propose func(repositoryObj *configapi.Repository, name string)
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+}
+
+func newAutoProposer(delay time.Duration, propose func(repositoryObj *configapi.Repository, name string)) *autoProposer {
+	return &autoProposer{
+		delay:   delay,
+		propose: propose,
+		pending: map[string]*time.Timer{},
+	}
+}
+
+// observe schedules the proposal of rev, as updated, if it is ready to be proposed, and
+// cancels its scheduled proposal otherwise. Every observation of a ready draft restarts
+// the delay.
+func (p *autoProposer) observe(repositoryObj *configapi.Repository, rev *api.PackageRevision) {
+	if p == nil {
+		return
+	}
+	key := rev.Namespace + "/" + rev.Name
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if timer, found := p.pending[key]; found {
+		timer.Stop()
+		delete(p.pending, key)
+	}
+	if !readyToAutoPropose(rev) {
+		return
+	}
+
+	repositoryObj = repositoryObj.DeepCopy()
+	name := rev.Name
+	var timer *time.Timer
+	timer = time.AfterFunc(p.delay, func() {
+		p.mu.Lock()
+		current := p.pending[key] == timer
+		if current {
+			delete(p.pending, key)
+		}
+		p.mu.Unlock()
+		// A stopped timer may already have fired; only the latest observation proposes.
+		if current {
+			p.propose(repositoryObj, name)
+		}
+	})
+	p.pending[key] = timer
+}
+
+// readyToAutoPropose returns true if rev is a draft with the api.AutoProposeAnnotation
+// annotation whose readiness gates all have true conditions.
+func readyToAutoPropose(rev *api.PackageRevision) bool {
+	return rev.Spec.Lifecycle == api.PackageRevisionLifecycleDraft &&
+		rev.Annotations[api.AutoProposeAnnotation] == "true" &&
+		len(rev.Spec.ReadinessGates) != 0 &&
+		len(checkReadinessGates(rev)) == 0
+}
+
+// observeAutoPropose lets the auto proposer observe the updated package revision.
+func (cad *cadEngine) observeAutoPropose(ctx context.Context, repositoryObj *configapi.Repository, updated *PackageRevision) {
+	if cad.autoProposer == nil {
+		return
+	}
+	rev, err := updated.GetPackageRevision(ctx)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "Cannot check whether package revision can be proposed automatically", "name", updated.KubeObjectName())
+		return
+	}
+	cad.autoProposer.observe(repositoryObj, rev)
+}
+
+// autoPropose proposes the draft name of the repository, if it is still ready to be
+// proposed. It runs when the delay of the auto proposer expires, outside of any request.
+func (cad *cadEngine) autoPropose(repositoryObj *configapi.Repository, name string) {
+	ctx, span := tracer.Start(context.Background(), "cadEngine::autoPropose", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+	logger := klog.FromContext(ctx)
+
+	pkgRev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		logger.Error(err, "Cannot propose package revision automatically", "name", name)
+		return
+	}
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		logger.Error(err, "Cannot propose package revision automatically", "name", name)
+		return
+	}
+	proposed, err := cad.proposeReadyDraft(ctx, repo, repositoryObj, pkgRev)
+	if err != nil {
+		logger.Error(err, "Cannot propose package revision automatically", "name", name)
+		return
+	}
+	if proposed != nil {
+		logger.Info("Proposed package revision automatically", "name", name)
+		cad.runProposeHooks(ctx, repositoryObj, proposed.repoPackageRevision)
+	}
+}
+
+// proposeReadyDraft proposes pkgRev if it is ready to be proposed, and returns nil if it
+// isn't. The api.AutoProposeAnnotation annotation is replaced by the
+// api.AutoProposedAnnotation annotation recording the readiness gates, so a draft whose
+// proposal is rejected isn't proposed again unless it is annotated again.
+//
+// Drafts are only ever proposed: approval is left to the approvers of the package.
+func (cad *cadEngine) proposeReadyDraft(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, pkgRev *PackageRevision) (*PackageRevision, error) {
+	oldObj, err := pkgRev.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The package revision may have changed since it was observed.
+	if !readyToAutoPropose(oldObj) {
+		return nil, nil
+	}
+
+	var gates []string
+	for _, gate := range oldObj.Spec.ReadinessGates {
+		gates = append(gates, gate.ConditionType)
+	}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Lifecycle = api.PackageRevisionLifecycleProposed
+	delete(newObj.Annotations, api.AutoProposeAnnotation)
+	newObj.Annotations[api.AutoProposedAnnotation] = strings.Join(gates, ",")
+
+	proposed, err := cad.updatePackageRevision(ctx, repo, repositoryObj, pkgRev, oldObj, newObj, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot propose package revision %q: %w", oldObj.Name, err)
+	}
+	return proposed, nil
+}
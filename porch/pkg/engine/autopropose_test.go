@@ -0,0 +1,215 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const testAutoProposeDelay = 50 * time.Millisecond
+
+// autoProposeDraft returns a draft annotated for automatic proposal, with the readiness
+// gates Tested, Reviewed and Scanned and the given true conditions.
+func autoProposeDraft(trueConditions ...string) *api.PackageRevision {
+	rev := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "blueprints-1234",
+			Namespace:   "default",
+			Annotations: map[string]string{api.AutoProposeAnnotation: "true"},
+		},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Lifecycle:   api.PackageRevisionLifecycleDraft,
+			ReadinessGates: []api.ReadinessGate{
+				{ConditionType: "Tested"},
+				{ConditionType: "Reviewed"},
+				{ConditionType: "Scanned"},
+			},
+		},
+	}
+	for _, c := range trueConditions {
+		rev.Status.Conditions = append(rev.Status.Conditions, api.Condition{Type: c, Status: api.ConditionTrue})
+	}
+	return rev
+}
+
+// recordProposals returns an auto proposer sending the names of the drafts it proposes
+// to the returned channel.
+func recordProposals() (*autoProposer, chan string) {
+	proposals := make(chan string, 10)
+	p := newAutoProposer(testAutoProposeDelay, func(repositoryObj *configapi.Repository, name string) {
+		proposals <- name
+	})
+	return p, proposals
+}
+
+func expectProposals(t *testing.T, proposals chan string, want int) {
+	t.Helper()
+	// Wait long enough for any scheduled proposal to run.
+	time.Sleep(4 * testAutoProposeDelay)
+	if got := len(proposals); got != want {
+		t.Errorf("draft was proposed %d times; want %d", got, want)
+	}
+}
+
+func TestAutoProposer(t *testing.T) {
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	t.Run("conditions turn true one by one", func(t *testing.T) {
+		p, proposals := recordProposals()
+		p.observe(repositoryObj, autoProposeDraft())
+		p.observe(repositoryObj, autoProposeDraft("Tested"))
+		expectProposals(t, proposals, 0)
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed"))
+		expectProposals(t, proposals, 0)
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		expectProposals(t, proposals, 1)
+		if got := <-proposals; got != "blueprints-1234" {
+			t.Errorf("proposed %q; want blueprints-1234", got)
+		}
+	})
+
+	t.Run("flapping condition", func(t *testing.T) {
+		p, proposals := recordProposals()
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Scanned"))
+		expectProposals(t, proposals, 0)
+
+		// Conditions becoming true again restart the delay.
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		time.Sleep(testAutoProposeDelay / 2)
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		time.Sleep(testAutoProposeDelay / 2)
+		if got := len(proposals); got != 0 {
+			t.Errorf("draft was proposed before its conditions stayed true for the delay")
+		}
+		expectProposals(t, proposals, 1)
+	})
+
+	t.Run("annotation removed", func(t *testing.T) {
+		p, proposals := recordProposals()
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		removed := autoProposeDraft("Tested", "Reviewed", "Scanned")
+		removed.Annotations = nil
+		p.observe(repositoryObj, removed)
+		expectProposals(t, proposals, 0)
+	})
+
+	t.Run("not a draft", func(t *testing.T) {
+		p, proposals := recordProposals()
+		proposed := autoProposeDraft("Tested", "Reviewed", "Scanned")
+		proposed.Spec.Lifecycle = api.PackageRevisionLifecycleProposed
+		p.observe(repositoryObj, proposed)
+		expectProposals(t, proposals, 0)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var p *autoProposer
+		p.observe(repositoryObj, autoProposeDraft("Tested", "Reviewed", "Scanned"))
+	})
+}
+
+func TestProposeReadyDraft(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	setup := func(rev *api.PackageRevision) (*cadEngine, *countingRepository, *PackageRevision, *metafake.MemoryMetadataStore) {
+		repoRev := &fake.PackageRevision{
+			Name:             rev.Name,
+			Namespace:        rev.Namespace,
+			PackageLifecycle: rev.Spec.Lifecycle,
+			PackageRevision:  rev,
+			Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+				kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			}}},
+		}
+		pkgRevMeta := meta.PackageRevisionMeta{Name: rev.Name, Namespace: rev.Namespace, Annotations: rev.Annotations}
+		store := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+		cad := &cadEngine{renderer: &countingRenderer{}, metadataStore: store}
+		return cad, &countingRepository{}, &PackageRevision{repoPackageRevision: repoRev, packageRevisionMeta: pkgRevMeta}, store
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		cad, repo, pkgRev, store := setup(autoProposeDraft("Tested", "Reviewed", "Scanned"))
+		proposed, err := cad.proposeReadyDraft(ctx, repo, repositoryObj, pkgRev)
+		if err != nil {
+			t.Fatalf("proposeReadyDraft failed: %v", err)
+		}
+		if proposed == nil {
+			t.Fatalf("ready draft wasn't proposed")
+		}
+
+		stored, err := store.Get(ctx, types.NamespacedName{Namespace: "default", Name: "blueprints-1234"})
+		if err != nil {
+			t.Fatalf("cannot get metadata: %v", err)
+		}
+		want := map[string]string{api.AutoProposedAnnotation: "Tested,Reviewed,Scanned"}
+		if diff := cmp.Diff(want, stored.Annotations); diff != "" {
+			t.Errorf("unexpected annotations (-want, +got): %s", diff)
+		}
+		if stored.LifecycleTimes.ProposedAt.IsZero() {
+			t.Errorf("proposal time wasn't recorded")
+		}
+	})
+
+	t.Run("no longer ready", func(t *testing.T) {
+		cad, repo, pkgRev, _ := setup(autoProposeDraft("Tested", "Reviewed"))
+		proposed, err := cad.proposeReadyDraft(ctx, repo, repositoryObj, pkgRev)
+		if err != nil {
+			t.Fatalf("proposeReadyDraft failed: %v", err)
+		}
+		if proposed != nil || repo.draft != nil {
+			t.Errorf("draft with a false readiness gate was proposed")
+		}
+	})
+}
+
+func TestAutoProposeTimeline(t *testing.T) {
+	created := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	proposed := metav1.NewTime(time.Date(2022, 8, 2, 10, 0, 0, 0, time.UTC))
+	rev := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "repo-v1",
+			CreationTimestamp: created,
+			Annotations:       map[string]string{api.AutoProposedAnnotation: "Tested,Reviewed"},
+		},
+		Spec:   api.PackageRevisionSpec{PackageName: "pkg", Revision: "v1", Lifecycle: api.PackageRevisionLifecycleProposed},
+		Status: api.PackageRevisionStatus{ProposedAt: proposed},
+	}
+
+	events := buildPackageTimeline([]*api.PackageRevision{rev}, nil, nil)
+	want := api.TimelineEvent{
+		Time:     proposed,
+		Type:     api.TimelineEventLifecycleChanged,
+		Revision: "repo-v1",
+		Actor:    autoProposeActor,
+		Message:  "lifecycle changed to Proposed automatically: readiness gates Tested,Reviewed are true",
+	}
+	if diff := cmp.Diff(want, events[len(events)-1]); diff != "" {
+		t.Errorf("unexpected timeline event (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+const (
+	// DefaultMaxPackageFiles is the default maximum number of files accepted from an upstream package.
+	DefaultMaxPackageFiles = 10000
+	// DefaultMaxPackageBytes is the default maximum total size of the files accepted from an upstream package.
+	DefaultMaxPackageBytes = 128 * 1024 * 1024
+)
+
+// PackageSizeBudget limits the size of upstream packages fetched by clone, update and edit.
+// A zero value for either limit means that dimension is unlimited.
+type PackageSizeBudget struct {
+	MaxFiles int
+	MaxBytes int64
+}
+
+// PackageSizeBudgetExceededError is returned when an upstream package exceeds the configured size budget.
+type PackageSizeBudgetExceededError struct {
+	Package string
+	Budget  PackageSizeBudget
+	Files   int
+	Bytes   int64
+}
+
+func (e *PackageSizeBudgetExceededError) Error() string {
+	if e.Budget.MaxFiles > 0 && e.Files > e.Budget.MaxFiles {
+		return fmt.Sprintf("package %q exceeds the size budget: more than %d files", e.Package, e.Budget.MaxFiles)
+	}
+	return fmt.Sprintf("package %q exceeds the size budget: more than %d bytes", e.Package, e.Budget.MaxBytes)
+}
+
+func defaultPackageSizeBudget() PackageSizeBudget {
+	return PackageSizeBudget{
+		MaxFiles: DefaultMaxPackageFiles,
+		MaxBytes: DefaultMaxPackageBytes,
+	}
+}
+
+// limitReads returns a context in which repositories stop reading the package as soon as
+// it exceeds the budget, so that an oversized package isn't read in full.
+func (b *PackageSizeBudget) limitReads(ctx context.Context) context.Context {
+	if b == nil || (b.MaxFiles <= 0 && b.MaxBytes <= 0) {
+		return ctx
+	}
+	return repository.WithReadLimit(ctx, repository.ReadLimit(*b))
+}
+
+// exceeded returns a PackageSizeBudgetExceededError for package name if reading it was
+// stopped by the limit set by limitReads, and err otherwise.
+func (b *PackageSizeBudget) exceeded(name string, err error) error {
+	var limitErr *repository.ReadLimitExceededError
+	if b == nil || !errors.As(err, &limitErr) {
+		return err
+	}
+	return &PackageSizeBudgetExceededError{
+		Package: name,
+		Budget:  *b,
+		Files:   limitErr.Files,
+		Bytes:   limitErr.Bytes,
+	}
+}
+
+// check accounts the package contents against the budget, stopping at the
+// first file that takes the package over either limit.
+func (b *PackageSizeBudget) check(name string, contents map[string]string) error {
+	if b == nil || (b.MaxFiles <= 0 && b.MaxBytes <= 0) {
+		return nil
+	}
+
+	var files int
+	var bytes int64
+	for _, v := range contents {
+		files++
+		bytes += int64(len(v))
+
+		if (b.MaxFiles > 0 && files > b.MaxFiles) || (b.MaxBytes > 0 && bytes > b.MaxBytes) {
+			return &PackageSizeBudgetExceededError{
+				Package: name,
+				Budget:  *b,
+				Files:   files,
+				Bytes:   bytes,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func TestCloneSizeBudget(t *testing.T) {
+	packageName := "upstream-1234567890"
+
+	contents := map[string]string{
+		kptfile.KptFileName: strings.TrimSpace(`
+apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: upstream
+`),
+	}
+	for i := 0; i < 20; i++ {
+		contents[fmt.Sprintf("file-%d.yaml", i)] = strings.Repeat("x", 100)
+	}
+
+	repoOpener := &fakeRepositoryOpener{
+		repository: &fake.Repository{
+			PackageRevisions: []repository.PackageRevision{
+				&fake.PackageRevision{
+					Name: packageName,
+					Resources: &v1alpha1.PackageRevisionResources{
+						Spec: v1alpha1.PackageRevisionResourcesSpec{
+							Resources: contents,
+						},
+					},
+					Kptfile: kptfile.KptFile{
+						Upstream:     &kptfile.Upstream{},
+						UpstreamLock: &kptfile.UpstreamLock{},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		budget  PackageSizeBudget
+		wantErr bool
+	}{
+		"unlimited": {
+			budget: PackageSizeBudget{},
+		},
+		"within budget": {
+			budget: PackageSizeBudget{MaxFiles: 21, MaxBytes: 3000},
+		},
+		"too many files": {
+			budget:  PackageSizeBudget{MaxFiles: 10},
+			wantErr: true,
+		},
+		"too many bytes": {
+			budget:  PackageSizeBudget{MaxBytes: 1000},
+			wantErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cpm := clonePackageMutation{
+				task: &v1alpha1.Task{
+					Type: v1alpha1.TaskTypeClone,
+					Clone: &v1alpha1.PackageCloneTaskSpec{
+						Upstream: v1alpha1.UpstreamPackage{
+							UpstreamRef: &v1alpha1.PackageRevisionRef{
+								Name: packageName,
+							},
+						},
+					},
+				},
+				namespace:         "test-namespace",
+				name:              "downstream",
+				repoOpener:        repoOpener,
+				referenceResolver: &fakeReferenceResolver{},
+				sizeBudget:        &tc.budget,
+			}
+
+			_, _, err := cpm.Apply(context.Background(), repository.PackageResources{})
+			var budgetErr *PackageSizeBudgetExceededError
+			if tc.wantErr {
+				if !errors.As(err, &budgetErr) {
+					t.Fatalf("expected size budget error, got %v", err)
+				}
+				if got, want := budgetErr.Package, packageName; got != want {
+					t.Errorf("unexpected package in error: got %q, want %q", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// streamingPackageRevision reads its resources one file at a time, stopping at the read
+// limit of the context like the git and OCI repositories do.
+type streamingPackageRevision struct {
+	*fake.PackageRevision
+	read int
+}
+
+func (p *streamingPackageRevision) GetResources(ctx context.Context) (*v1alpha1.PackageRevisionResources, error) {
+	counter := repository.NewReadCounter(ctx)
+	resources := map[string]string{}
+	for name, contents := range p.Resources.Spec.Resources {
+		if err := counter.Add(int64(len(contents))); err != nil {
+			return nil, err
+		}
+		p.read++
+		resources[name] = contents
+	}
+	return &v1alpha1.PackageRevisionResources{Spec: v1alpha1.PackageRevisionResourcesSpec{Resources: resources}}, nil
+}
+
+func TestSizeBudgetStopsFetch(t *testing.T) {
+	packageName := "upstream-1234567890"
+	contents := map[string]string{}
+	for i := 0; i < 20; i++ {
+		contents[fmt.Sprintf("file-%d.yaml", i)] = strings.Repeat("x", 100)
+	}
+	revision := &streamingPackageRevision{
+		PackageRevision: &fake.PackageRevision{
+			Name: packageName,
+			Resources: &v1alpha1.PackageRevisionResources{
+				Spec: v1alpha1.PackageRevisionResourcesSpec{Resources: contents},
+			},
+		},
+	}
+
+	fetcher := &PackageFetcher{sizeBudget: &PackageSizeBudget{MaxBytes: 500}}
+	_, err := fetcher.GetResources(context.Background(), revision)
+	var budgetErr *PackageSizeBudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected size budget error, got %v", err)
+	}
+	if got, want := budgetErr.Package, packageName; got != want {
+		t.Errorf("unexpected package in error: got %q, want %q", got, want)
+	}
+	// Reading stops at the file which exceeds the budget.
+	if got, want := revision.read, 5; got != want {
+		t.Errorf("fetch read %d files; want %d", got, want)
+	}
+}
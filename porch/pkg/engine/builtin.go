@@ -22,6 +22,7 @@ import (
 	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
 	"github.com/GoogleContainerTools/kpt/pkg/fn"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -52,30 +53,22 @@ func (m *builtinEvalMutation) Apply(ctx context.Context, resources repository.Pa
 		Results: &yaml.RNode{},
 	}
 
-	pr := &packageReader{
-		input: resources,
-		extra: map[string]string{},
-	}
-
-	result := repository.PackageResources{
-		Contents: map[string]string{},
-	}
-
+	other := pkgcontents.Contents{}
+	output := pkgcontents.Contents{}
 	pipeline := kio.Pipeline{
-		Inputs:  []kio.Reader{pr},
+		Inputs:  []kio.Reader{&pkgcontents.Reader{Contents: resources.Contents, Other: other}},
 		Filters: []kio.Filter{ff},
-		Outputs: []kio.Writer{&packageWriter{
-			output: result,
-		}},
+		Outputs: []kio.Writer{&pkgcontents.Writer{Contents: output}},
 	}
 
 	if err := pipeline.Execute(); err != nil {
 		return repository.PackageResources{}, nil, fmt.Errorf("failed to evaluate function %q: %w", m.function, err)
 	}
 
-	for k, v := range pr.extra {
-		result.Contents[k] = v
+	for k, v := range other {
+		output[k] = v
 	}
+	result := repository.PackageResources{Contents: output}
 
 	return result, nil, nil
 }
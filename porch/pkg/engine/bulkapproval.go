@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+)
+
+// maxConcurrentApprovals bounds the number of package revisions ApprovePackageRevisions
+// publishes concurrently.
+const maxConcurrentApprovals = 4
+
+// ApprovalRequest is a package revision to approve, with its repository.
+type ApprovalRequest struct {
+	Repository      *configapi.Repository
+	PackageRevision *PackageRevision
+}
+
+// packageApprover runs the steps of approvals which need the repositories.
+type packageApprover interface {
+	// checkNotFrozen returns a PackageFrozenError if the package is frozen.
+	checkNotFrozen(ctx context.Context, repositoryObj *configapi.Repository, packageName string) error
+	// publish publishes the proposed package revision.
+	publish(ctx context.Context, req ApprovalRequest, oldObj *api.PackageRevision) (*PackageRevision, error)
+}
+
+// ApprovePackageRevisions approves the proposed package revisions. The preconditions of
+// all package revisions are checked first: they must be proposed, their readiness gates
+// must be met, and their packages must not be frozen. The package revisions which meet
+// them are then published, up to maxConcurrentApprovals at a time. A package revision
+// which can't be approved doesn't stop the others; its result reports the error. The
+// results are in the order of the requests.
+func (cad *cadEngine) ApprovePackageRevisions(ctx context.Context, requests []ApprovalRequest) []api.BulkApprovalResult {
+	ctx, span := tracer.Start(ctx, "cadEngine::ApprovePackageRevisions", trace.WithAttributes())
+	defer span.End()
+
+	return approvePackageRevisions(ctx, &cadPackageApprover{cad: cad}, requests)
+}
+
+func approvePackageRevisions(ctx context.Context, approver packageApprover, requests []ApprovalRequest) []api.BulkApprovalResult {
+	results := make([]api.BulkApprovalResult, len(requests))
+	approved := make([]*api.PackageRevision, len(requests))
+	for i, req := range requests {
+		results[i].Name = req.PackageRevision.KubeObjectName()
+		rev, err := checkApproval(ctx, approver, req)
+		if err != nil {
+			results[i] = approvalError(results[i].Name, api.BulkApprovalOutcomeRejected, err)
+			continue
+		}
+		approved[i] = rev
+	}
+
+	slots := make(chan struct{}, maxConcurrentApprovals)
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		if approved[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, req ApprovalRequest) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			ctx := withPackageLogger(ctx, req.PackageRevision.repoPackageRevision.Key())
+			published, err := approver.publish(ctx, req, approved[i])
+			if err != nil {
+				klog.FromContext(ctx).Error(err, "Failed to publish package revision", "name", results[i].Name)
+				results[i] = approvalError(results[i].Name, api.BulkApprovalOutcomeFailed, err)
+				return
+			}
+			results[i] = api.BulkApprovalResult{
+				Name:     results[i].Name,
+				Outcome:  api.BulkApprovalOutcomePublished,
+				Revision: published.repoPackageRevision.Key().Revision,
+			}
+		}(i, req)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkApproval returns the package revision of the request if it can be approved.
+func checkApproval(ctx context.Context, approver packageApprover, req ApprovalRequest) (*api.PackageRevision, error) {
+	rev, err := req.PackageRevision.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lifecycle := rev.Spec.Lifecycle; lifecycle != api.PackageRevisionLifecycleProposed {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), rev.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "lifecycle"), lifecycle, "only Proposed package revisions can be approved"),
+		})
+	}
+	if gates := checkReadinessGates(rev); len(gates) != 0 {
+		var unmet []string
+		for _, gate := range gates {
+			unmet = append(unmet, fmt.Sprintf("%s (%s)", gate.Name, gate.Reason))
+		}
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), rev.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "readinessGates"), strings.Join(unmet, ", "), "readiness gates must be met to approve the package revision"),
+		})
+	}
+	if err := approver.checkNotFrozen(ctx, req.Repository, rev.Spec.PackageName); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// approvalError returns the result of a package revision which wasn't published because
+// of err.
+func approvalError(name string, outcome api.BulkApprovalOutcome, err error) api.BulkApprovalResult {
+	reason := apierrors.ReasonForError(err)
+	if reason == metav1.StatusReasonUnknown {
+		reason = metav1.StatusReasonInternalError
+	}
+	return api.BulkApprovalResult{Name: name, Outcome: outcome, Reason: reason, Message: err.Error()}
+}
+
+// cadPackageApprover approves package revisions with the engine.
+type cadPackageApprover struct {
+	cad *cadEngine
+}
+
+var _ packageApprover = &cadPackageApprover{}
+
+func (a *cadPackageApprover) checkNotFrozen(ctx context.Context, repositoryObj *configapi.Repository, packageName string) error {
+	return a.cad.checkPackageNotFrozen(ctx, repositoryObj, packageName)
+}
+
+func (a *cadPackageApprover) publish(ctx context.Context, req ApprovalRequest, oldObj *api.PackageRevision) (*PackageRevision, error) {
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Lifecycle = api.PackageRevisionLifecyclePublished
+	return a.cad.UpdatePackageRevision(ctx, req.Repository, req.PackageRevision, oldObj, newObj, nil)
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeApprover struct {
+	frozen  map[string]bool
+	failing map[string]bool
+
+	mutex     sync.Mutex
+	published []string
+}
+
+func (a *fakeApprover) checkNotFrozen(ctx context.Context, repositoryObj *configapi.Repository, packageName string) error {
+	if a.frozen[packageName] {
+		return &PackageFrozenError{Repository: repositoryObj.Name, Package: packageName, Freeze: meta.PackageFreeze{Reason: "release"}}
+	}
+	return nil
+}
+
+func (a *fakeApprover) publish(ctx context.Context, req ApprovalRequest, oldObj *api.PackageRevision) (*PackageRevision, error) {
+	if a.failing[oldObj.Spec.PackageName] {
+		return nil, errors.New("push rejected")
+	}
+	a.mutex.Lock()
+	a.published = append(a.published, oldObj.Name)
+	a.mutex.Unlock()
+
+	key := req.PackageRevision.repoPackageRevision.Key()
+	key.Revision = "v1"
+	return &PackageRevision{repoPackageRevision: &fake.PackageRevision{PackageRevisionKey: key}}, nil
+}
+
+func TestApprovePackageRevisions(t *testing.T) {
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "deployments", Namespace: "default"}}
+	request := func(pkg string, lifecycle api.PackageRevisionLifecycle, gates []api.ReadinessGate, conditions []api.Condition) ApprovalRequest {
+		name := "deployments-" + pkg
+		return ApprovalRequest{
+			Repository: repositoryObj,
+			PackageRevision: &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+				Name:               name,
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "deployments", Package: pkg},
+				PackageLifecycle:   lifecycle,
+				PackageRevision: &api.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec: api.PackageRevisionSpec{
+						PackageName:    pkg,
+						RepositoryName: "deployments",
+						Lifecycle:      lifecycle,
+						ReadinessGates: gates,
+					},
+					Status: api.PackageRevisionStatus{Conditions: conditions},
+				},
+			}},
+		}
+	}
+	gates := []api.ReadinessGate{{ConditionType: "Validated"}}
+
+	approver := &fakeApprover{
+		frozen:  map[string]bool{"frozen": true},
+		failing: map[string]bool{"failing": true},
+	}
+	requests := []ApprovalRequest{
+		request("ready", api.PackageRevisionLifecycleProposed, nil, nil),
+		request("draft", api.PackageRevisionLifecycleDraft, nil, nil),
+		request("unvalidated", api.PackageRevisionLifecycleProposed, gates, []api.Condition{{Type: "Validated", Status: api.ConditionFalse}}),
+		request("validated", api.PackageRevisionLifecycleProposed, gates, []api.Condition{{Type: "Validated", Status: api.ConditionTrue}}),
+		request("frozen", api.PackageRevisionLifecycleProposed, nil, nil),
+		request("failing", api.PackageRevisionLifecycleProposed, nil, nil),
+	}
+
+	results := approvePackageRevisions(context.Background(), approver, requests)
+
+	type outcome struct {
+		Name     string
+		Outcome  api.BulkApprovalOutcome
+		Revision string
+		Reason   metav1.StatusReason
+	}
+	var got []outcome
+	for _, result := range results {
+		if result.Outcome != api.BulkApprovalOutcomePublished && result.Message == "" {
+			t.Errorf("result of %s doesn't explain why it wasn't published", result.Name)
+		}
+		got = append(got, outcome{result.Name, result.Outcome, result.Revision, result.Reason})
+	}
+	want := []outcome{
+		{"deployments-ready", api.BulkApprovalOutcomePublished, "v1", ""},
+		{"deployments-draft", api.BulkApprovalOutcomeRejected, "", metav1.StatusReasonInvalid},
+		{"deployments-unvalidated", api.BulkApprovalOutcomeRejected, "", metav1.StatusReasonInvalid},
+		{"deployments-validated", api.BulkApprovalOutcomePublished, "v1", ""},
+		{"deployments-frozen", api.BulkApprovalOutcomeRejected, "", metav1.StatusReasonConflict},
+		{"deployments-failing", api.BulkApprovalOutcomeFailed, "", metav1.StatusReasonInternalError},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected results (-want, +got): %s", diff)
+	}
+
+	sort.Strings(approver.published)
+	if diff := cmp.Diff([]string{"deployments-ready", "deployments-validated"}, approver.published); diff != "" {
+		t.Errorf("unexpected published package revisions (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StatusClientClosedRequest is the status code of operations cancelled because the
+	// client went away, as used by nginx.
+	StatusClientClosedRequest = 499
+	// StatusReasonClientClosedRequest is the status reason of operations cancelled because
+	// the client went away.
+	StatusReasonClientClosedRequest metav1.StatusReason = "ClientClosedRequest"
+)
+
+// CancelledError is returned when a package revision operation stops because its request
+// was cancelled or exceeded its deadline. Changes not yet written to the repository are
+// discarded.
+//
+// CancelledError is an API status error: a cancelled request is reported as
+// ClientClosedRequest (499), and an exceeded deadline as a Timeout.
+type CancelledError struct {
+	Err error
+}
+
+var _ apierrors.APIStatus = &CancelledError{}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("operation cancelled: %v", e.Err)
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// Status implements apierrors.APIStatus.
+func (e *CancelledError) Status() metav1.Status {
+	code, reason := int32(StatusClientClosedRequest), StatusReasonClientClosedRequest
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		code, reason = http.StatusGatewayTimeout, metav1.StatusReasonTimeout
+	}
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    code,
+		Reason:  reason,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisions",
+		},
+	}
+}
+
+// checkCancelled returns a CancelledError if ctx is done.
+func checkCancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &CancelledError{Err: err}
+	}
+	return nil
+}
+
+// recordCancellation records the status of a cancelled operation on the span of ctx.
+func recordCancellation(ctx context.Context, err error) {
+	var cancelled *CancelledError
+	if !errors.As(err, &cancelled) {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("http.status_code", int(cancelled.Status().Code)))
+	span.SetStatus(codes.Error, cancelled.Error())
+}
+
+// withoutCancel returns a context with the values of ctx which isn't cancelled with it,
+// for cleaning up after a cancelled operation.
+func withoutCancel(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// cancellableRuntime doesn't start functions once the context they are run for is done,
+// so a cancelled render stops before its next function.
+type cancellableRuntime struct {
+	runtime fn.FunctionRuntime
+}
+
+var _ fn.FunctionRuntime = &cancellableRuntime{}
+
+func (r *cancellableRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	if err := checkCancelled(ctx); err != nil {
+		return nil, err
+	}
+	runner, err := r.runtime.GetRunner(ctx, function)
+	if err != nil || runner == nil {
+		return runner, err
+	}
+	return &cancellableRunner{ctx: ctx, runner: runner}, nil
+}
+
+type cancellableRunner struct {
+	ctx    context.Context
+	runner fn.FunctionRunner
+}
+
+func (r *cancellableRunner) Run(in io.Reader, out io.Writer) error {
+	if err := checkCancelled(r.ctx); err != nil {
+		return err
+	}
+	return r.runner.Run(in, out)
+}
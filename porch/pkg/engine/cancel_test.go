@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// cancellingMutation cancels the request when it is applied.
+type cancellingMutation struct {
+	cancel  context.CancelFunc
+	applied int
+}
+
+func (m *cancellingMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	m.applied++
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return resources, &api.Task{Type: api.TaskTypePatch}, nil
+}
+
+// cancellingRenderer cancels the request when it renders.
+type cancellingRenderer struct {
+	cancel context.CancelFunc
+}
+
+func (r *cancellingRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	r.cancel()
+	return nil
+}
+
+func TestApplyResourceMutationsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &cancellingMutation{cancel: cancel}
+	second := &cancellingMutation{}
+	draft := &recordingDraft{}
+	cad := &cadEngine{}
+	err := cad.applyResourceMutations(ctx, draft, repository.PackageResources{}, []mutation{first, second})
+
+	var cancelled *CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("applyResourceMutations returned %v; want a CancelledError", err)
+	}
+	if got := cancelled.Status().Code; got != StatusClientClosedRequest {
+		t.Errorf("unexpected status code: got %d, want %d", got, StatusClientClosedRequest)
+	}
+	// The mutation which was running when the request was cancelled isn't committed.
+	if len(draft.tasks) != 0 {
+		t.Errorf("cancelled mutations were committed: %v", draft.tasks)
+	}
+	if second.applied != 0 {
+		t.Errorf("mutation was applied after the request was cancelled")
+	}
+}
+
+func TestCreatePackageRevisionCancelled(t *testing.T) {
+	newObj := func() *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "app",
+				Lifecycle:   api.PackageRevisionLifecycleDraft,
+				Tasks:       []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}}},
+			},
+		}
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The request is cancelled by the render following the init task.
+		cad := &cadEngine{renderer: &cancellingRenderer{cancel: cancel}, forceRender: true}
+		repo := &creatingRepository{}
+		_, err := cad.createPackageRevision(ctx, repo, &configapi.Repository{}, newObj(), nil)
+
+		var cancelled *CancelledError
+		if !errors.As(err, &cancelled) {
+			t.Fatalf("createPackageRevision returned %v; want a CancelledError", err)
+		}
+		if got := apierrors.APIStatus(cancelled).Status().Code; got != StatusClientClosedRequest {
+			t.Errorf("unexpected status code: got %d, want %d", got, StatusClientClosedRequest)
+		}
+		if got, want := len(repo.draft.tasks), 1; got != want {
+			t.Errorf("unexpected number of commits: got %d, want %d", got, want)
+		}
+		if draft := repo.closingDraft; draft.closed {
+			t.Errorf("draft of cancelled request was closed")
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		cad := &cadEngine{renderer: &countingRenderer{}}
+		repo := &creatingRepository{}
+		_, err := cad.createPackageRevision(ctx, repo, &configapi.Repository{}, newObj(), nil)
+
+		var status apierrors.APIStatus
+		if !errors.As(err, &status) {
+			t.Fatalf("createPackageRevision returned %v; want an API status error", err)
+		}
+		if got := status.Status().Code; got != http.StatusGatewayTimeout {
+			t.Errorf("unexpected status code: got %d, want %d", got, http.StatusGatewayTimeout)
+		}
+		if len(repo.draft.tasks) != 0 {
+			t.Errorf("expired request committed %v", repo.draft.tasks)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ChangeSummary summarizes the changes of a package revision relative to the prior
+// published revision of its package, for example for the description of a pull request.
+type ChangeSummary struct {
+	// Base is the name of the prior published revision, or empty if there is none and
+	// all files of the package revision are new.
+	Base string
+
+	// FilesAdded, FilesModified and FilesDeleted are the changed files, sorted by path.
+	FilesAdded    []string
+	FilesModified []string
+	FilesDeleted  []string
+
+	// Resources are the changed resources, sorted by resource identity.
+	Resources []ResourceChange
+
+	// Notable are the kind-level changes worth calling out, such as changed images or
+	// replica counts.
+	Notable []string
+}
+
+// String formats the summary as Markdown.
+func (s *ChangeSummary) String() string {
+	var b strings.Builder
+	if s.Base == "" {
+		fmt.Fprintf(&b, "Changes of the first published revision of the package.\n\n")
+	} else {
+		fmt.Fprintf(&b, "Changes since %s.\n\n", s.Base)
+	}
+
+	fmt.Fprintf(&b, "Files: %d added, %d modified, %d deleted\n", len(s.FilesAdded), len(s.FilesModified), len(s.FilesDeleted))
+	counts := map[ResourceChangeType]int{}
+	for _, c := range s.Resources {
+		counts[c.Type]++
+	}
+	fmt.Fprintf(&b, "Resources: %d created, %d modified, %d deleted\n", counts[ResourceCreated], counts[ResourceModified], counts[ResourceDeleted])
+
+	if len(s.Notable) > 0 {
+		fmt.Fprintf(&b, "\nNotable changes:\n")
+		for _, n := range s.Notable {
+			fmt.Fprintf(&b, "- %s\n", n)
+		}
+	}
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "- `%s`\n", item)
+		}
+	}
+	writeList("Added files", s.FilesAdded)
+	writeList("Modified files", s.FilesModified)
+	writeList("Deleted files", s.FilesDeleted)
+	return b.String()
+}
+
+// GenerateChangeSummary summarizes the changes of the package revision relative to the
+// latest published revision of its package which precedes it.
+func (cad *cadEngine) GenerateChangeSummary(ctx context.Context, repositoryObj *configapi.Repository, name string) (*ChangeSummary, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::GenerateChangeSummary", trace.WithAttributes())
+	defer span.End()
+
+	rev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return nil, err
+	}
+	key := rev.repoPackageRevision.Key()
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: key.Package})
+	if err != nil {
+		return nil, err
+	}
+	repoRevisions := make([]repository.PackageRevision, 0, len(revisions))
+	for _, r := range revisions {
+		repoRevisions = append(repoRevisions, r.repoPackageRevision)
+	}
+
+	resources, err := rev.GetResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get resources of package revision %q: %w", name, err)
+	}
+	var base string
+	var baseContents map[string]string
+	if prior := latestPublishedBefore(repoRevisions, key); prior != nil {
+		priorResources, err := prior.GetResources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get resources of package revision %q: %w", prior.KubeObjectName(), err)
+		}
+		base = fmt.Sprintf("%s (%s)", prior.KubeObjectName(), prior.Key().Revision)
+		baseContents = priorResources.Spec.Resources
+	}
+
+	summary := summarizeChanges(baseContents, resources.Spec.Resources)
+	summary.Base = base
+	return summary, nil
+}
+
+// summarizeChanges compares the files, and the resources in them, of two versions of
+// a package.
+func summarizeChanges(old, new map[string]string) *ChangeSummary {
+	summary := &ChangeSummary{}
+	for k, newV := range new {
+		if oldV, found := old[k]; !found {
+			summary.FilesAdded = append(summary.FilesAdded, k)
+		} else if oldV != newV {
+			summary.FilesModified = append(summary.FilesModified, k)
+		}
+	}
+	for k := range old {
+		if _, found := new[k]; !found {
+			summary.FilesDeleted = append(summary.FilesDeleted, k)
+		}
+	}
+	sort.Strings(summary.FilesAdded)
+	sort.Strings(summary.FilesModified)
+	sort.Strings(summary.FilesDeleted)
+
+	before := indexPackageResources(old)
+	after := indexPackageResources(new)
+	for id, a := range after {
+		b, found := before[id]
+		if !found {
+			summary.Resources = append(summary.Resources, ResourceChange{Resource: id, Type: ResourceCreated})
+			continue
+		}
+		if b.MustString() != a.MustString() {
+			summary.Resources = append(summary.Resources, ResourceChange{Resource: id, Type: ResourceModified})
+		}
+	}
+	for id := range before {
+		if _, found := after[id]; !found {
+			summary.Resources = append(summary.Resources, ResourceChange{Resource: id, Type: ResourceDeleted})
+		}
+	}
+	sort.Slice(summary.Resources, func(i, j int) bool {
+		return summary.Resources[i].Resource < summary.Resources[j].Resource
+	})
+
+	for _, c := range summary.Resources {
+		if c.Type == ResourceModified {
+			summary.Notable = append(summary.Notable, notableChanges(before[c.Resource], after[c.Resource])...)
+		}
+	}
+	return summary
+}
+
+// indexPackageResources returns the resources in the YAML files of a package, keyed by
+// resource identity. The summary is informational only, so files which cannot be parsed
+// are compared as files but not as resources.
+func indexPackageResources(contents map[string]string) map[string]*yaml.RNode {
+	index := map[string]*yaml.RNode{}
+	for k, v := range contents {
+		if ext := path.Ext(k); ext != ".yaml" && ext != ".yml" && path.Base(k) != "Kptfile" {
+			continue
+		}
+		items, err := (&kio.ByteReader{Reader: bytes.NewBufferString(v), OmitReaderAnnotations: true}).Read()
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			id := strings.Join([]string{item.GetApiVersion(), item.GetKind(), item.GetNamespace(), item.GetName()}, "/")
+			index[id] = item
+		}
+	}
+	return index
+}
+
+// podSpecPaths are the paths of the pod specs in the workload kinds.
+var podSpecPaths = [][]string{
+	{"spec"},
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// notableChanges describes the changes of the container images and the replica count
+// of a modified resource.
+func notableChanges(before, after *yaml.RNode) []string {
+	resource := after.GetKind() + " " + after.GetName()
+	if namespace := after.GetNamespace(); namespace != "" {
+		resource = after.GetKind() + " " + namespace + "/" + after.GetName()
+	}
+
+	var changes []string
+	oldImages, newImages := containerImages(before), containerImages(after)
+	var containers []string
+	for container := range newImages {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+	for _, container := range containers {
+		if oldImage, found := oldImages[container]; found && oldImage != newImages[container] {
+			changes = append(changes, fmt.Sprintf("%s: image of container %s changed from %s to %s", resource, container, oldImage, newImages[container]))
+		}
+	}
+	if oldReplicas, newReplicas := fieldValue(before, "spec", "replicas"), fieldValue(after, "spec", "replicas"); oldReplicas != newReplicas {
+		changes = append(changes, fmt.Sprintf("%s: replicas changed from %s to %s", resource, orUnset(oldReplicas), orUnset(newReplicas)))
+	}
+	return changes
+}
+
+// containerImages returns the images of the containers and init containers of a
+// workload, by container name.
+func containerImages(node *yaml.RNode) map[string]string {
+	images := map[string]string{}
+	for _, podSpec := range podSpecPaths {
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, err := node.Pipe(yaml.Lookup(append(append([]string{}, podSpec...), field)...))
+			if err != nil || containers == nil {
+				continue
+			}
+			elements, err := containers.Elements()
+			if err != nil {
+				continue
+			}
+			for _, container := range elements {
+				if name := fieldValue(container, "name"); name != "" {
+					images[name] = fieldValue(container, "image")
+				}
+			}
+		}
+	}
+	return images
+}
+
+func fieldValue(node *yaml.RNode, fieldPath ...string) string {
+	field, err := node.Pipe(yaml.Lookup(fieldPath...))
+	if err != nil || field == nil {
+		return ""
+	}
+	return field.YNode().Value
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+const summaryDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: REPLICAS
+  template:
+    spec:
+      containers:
+      - name: app
+        image: IMAGE
+      - name: sidecar
+        image: envoy:1.24
+`
+
+func deployment(replicas, image string) string {
+	return strings.NewReplacer("REPLICAS", replicas, "IMAGE", image).Replace(summaryDeployment)
+}
+
+func TestSummarizeChanges(t *testing.T) {
+	old := map[string]string{
+		"Kptfile":         "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"deployment.yaml": deployment("1", "nginx:1.21"),
+		"service.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n",
+		"README.md":       "# app\n",
+	}
+	new := map[string]string{
+		"Kptfile":         old["Kptfile"],
+		"deployment.yaml": deployment("3", "nginx:1.22"),
+		"configmap.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+		"README.md":       "# app\n\nServes requests.\n",
+	}
+
+	summary := summarizeChanges(old, new)
+	summary.Base = "blueprints-1234 (v1)"
+
+	want := &ChangeSummary{
+		Base:          "blueprints-1234 (v1)",
+		FilesAdded:    []string{"configmap.yaml"},
+		FilesModified: []string{"README.md", "deployment.yaml"},
+		FilesDeleted:  []string{"service.yaml"},
+		Resources: []ResourceChange{
+			{Resource: "apps/v1/Deployment/default/app", Type: ResourceModified},
+			{Resource: "v1/ConfigMap//app", Type: ResourceCreated},
+			{Resource: "v1/Service//app", Type: ResourceDeleted},
+		},
+		Notable: []string{
+			"Deployment default/app: image of container app changed from nginx:1.21 to nginx:1.22",
+			"Deployment default/app: replicas changed from 1 to 3",
+		},
+	}
+	if diff := cmp.Diff(want, summary); diff != "" {
+		t.Errorf("unexpected summary (-want, +got): %s", diff)
+	}
+
+	text := summary.String()
+	for _, s := range []string{
+		"Changes since blueprints-1234 (v1).",
+		"Files: 1 added, 2 modified, 1 deleted",
+		"Resources: 1 created, 1 modified, 1 deleted",
+		"- Deployment default/app: image of container app changed from nginx:1.21 to nginx:1.22",
+		"- `service.yaml`",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("summary doesn't contain %q:\n%s", s, text)
+		}
+	}
+}
+
+func TestLatestPublishedBefore(t *testing.T) {
+	revision := func(revision string, lifecycle api.PackageRevisionLifecycle) repository.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               "blueprints-" + revision,
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: revision},
+			PackageLifecycle:   lifecycle,
+		}
+	}
+	revisions := []repository.PackageRevision{
+		revision("v1", api.PackageRevisionLifecyclePublished),
+		revision("v3", api.PackageRevisionLifecyclePublished),
+		revision("v2", api.PackageRevisionLifecyclePublished),
+		revision("v4", api.PackageRevisionLifecycleProposed),
+	}
+
+	for _, tc := range []struct {
+		revision string
+		want     string
+	}{
+		{revision: "v4", want: "blueprints-v3"},
+		{revision: "v3", want: "blueprints-v2"},
+		{revision: "", want: "blueprints-v3"},
+		{revision: "v1", want: ""},
+	} {
+		got := latestPublishedBefore(revisions, repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: tc.revision})
+		name := ""
+		if got != nil {
+			name = got.KubeObjectName()
+		}
+		if name != tc.want {
+			t.Errorf("latest published revision before %q: got %q, want %q", tc.revision, name, tc.want)
+		}
+	}
+}
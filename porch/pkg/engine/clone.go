@@ -47,17 +47,78 @@ type clonePackageMutation struct {
 
 	// packageConfig contains the package configuration.
 	packageConfig *builtins.PackageConfig
+
+	// sizeBudget limits the size of the upstream package.
+	sizeBudget *PackageSizeBudget
+
+	// upstreamVerifier, if set, must accept the upstream package before it is cloned.
+	upstreamVerifier UpstreamVerifier
+
+	// allowedUpstreams, if not empty, restricts the upstreams the package can be cloned from.
+	allowedUpstreams []configapi.AllowedUpstream
+
+	// externalSources, if set, restricts git and OCI upstreams to the registered repositories.
+	externalSources *externalSourcePolicy
+
+	// upstreamRevision is the package revision of a registered repository the upstream
+	// reference was resolved to when the mutation was validated.
+	upstreamRevision repository.PackageRevision
+
+	// stampNamespace sets the namespace of the namespaced resources of a deployable
+	// package to targetNamespace, or if empty, to the name in its package context.
+	stampNamespace  bool
+	targetNamespace string
+}
+
+var _ validatingMutation = &clonePackageMutation{}
+
+func (m *clonePackageMutation) sourceTask() *api.Task {
+	return m.task
+}
+
+// validate checks that the upstream is allowed and, for an upstream of a registered
+// repository, that the referenced package revision exists.
+func (m *clonePackageMutation) validate(ctx context.Context) error {
+	if err := checkAllowedUpstream(m.allowedUpstreams, &m.task.Clone.Upstream); err != nil {
+		return err
+	}
+	if err := m.externalSources.checkUpstream(ctx, &m.task.Clone.Upstream, "clone task"); err != nil {
+		return err
+	}
+	upstream := m.task.Clone.Upstream
+	if upstream.UpstreamRef == nil && upstream.Git == nil && upstream.Oci == nil {
+		return errors.New("invalid clone source (neither of git, oci, nor upstream were specified)")
+	}
+	if ref := upstream.UpstreamRef; ref != nil {
+		if ref.Name == "" && ref.Package == "" {
+			return fmt.Errorf("upstreamRef.name or upstreamRef.package is required")
+		}
+		revision, fetchRef, err := m.fetchUpstreamRevision(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to fetch package revision %q: %w", describePackageRevisionRef(fetchRef), err)
+		}
+		m.upstreamRevision = revision
+	}
+	return nil
 }
 
 func (m *clonePackageMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
 	ctx, span := tracer.Start(ctx, "clonePackageMutation::Apply", trace.WithAttributes())
 	defer span.End()
 
+	if err := checkAllowedUpstream(m.allowedUpstreams, &m.task.Clone.Upstream); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	if err := m.externalSources.checkUpstream(ctx, &m.task.Clone.Upstream, "clone task"); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+
 	var cloned repository.PackageResources
+	var resolved *api.PackageRevisionRef
 	var err error
 
 	if ref := m.task.Clone.Upstream.UpstreamRef; ref != nil {
-		cloned, err = m.cloneFromRegisteredRepository(ctx, ref)
+		cloned, resolved, err = m.cloneFromRegisteredRepository(ctx, ref)
 	} else if git := m.task.Clone.Upstream.Git; git != nil {
 		cloned, err = m.cloneFromGit(ctx, git)
 	} else if oci := m.task.Clone.Upstream.Oci; oci != nil {
@@ -88,6 +149,18 @@ func (m *clonePackageMutation) Apply(ctx context.Context, resources repository.P
 		if err != nil {
 			return repository.PackageResources{}, nil, fmt.Errorf("failed to generate deployment context: %w", err)
 		}
+
+		if m.stampNamespace {
+			namespace := m.targetNamespace
+			if namespace == "" {
+				if namespace, err = packageContextNamespace(cloned); err != nil {
+					return repository.PackageResources{}, nil, fmt.Errorf("cannot derive target namespace: %w", err)
+				}
+			}
+			if cloned, err = stampNamespace(ctx, cloned, namespace); err != nil {
+				return repository.PackageResources{}, nil, err
+			}
+		}
 	}
 
 	// ensure merge-key comment is added to newly added resources.
@@ -96,46 +169,130 @@ func (m *clonePackageMutation) Apply(ctx context.Context, resources repository.P
 	// but shouldn't result in overall clone operation.
 	result, err := ensureMergeKey(ctx, cloned)
 	if err != nil {
-		klog.Infof("failed to add merge-key to resources %v", err)
+		klog.FromContext(ctx).Info("Failed to add merge-key to resources", "err", err)
 	}
 
-	return result, m.task, nil
+	task := m.task
+	if resolved != nil {
+		task = m.task.DeepCopy()
+		task.Clone.ResolvedUpstreamRef = resolved
+	}
+	return result, task, nil
 }
 
-func (m *clonePackageMutation) cloneFromRegisteredRepository(ctx context.Context, ref *api.PackageRevisionRef) (repository.PackageResources, error) {
+// cloneFromRegisteredRepository clones the referenced package revision. If the reference names a
+// package rather than a package revision, it also returns the package revision it was resolved to.
+func (m *clonePackageMutation) cloneFromRegisteredRepository(ctx context.Context, ref *api.PackageRevisionRef) (repository.PackageResources, *api.PackageRevisionRef, error) {
+	if ref.Name == "" && ref.Package == "" {
+		return repository.PackageResources{}, nil, fmt.Errorf("upstreamRef.name or upstreamRef.package is required")
+	}
+
+	fetcher := m.fetcher()
+
+	upstreamRevision := m.upstreamRevision
+	if upstreamRevision == nil {
+		revision, fetchRef, err := m.fetchUpstreamRevision(ctx, ref)
+		if err != nil {
+			return repository.PackageResources{}, nil, fmt.Errorf("failed to fetch package revision %q: %w", describePackageRevisionRef(fetchRef), err)
+		}
+		upstreamRevision = revision
+	}
+	name := upstreamRevision.KubeObjectName()
+
+	resources, err := fetcher.GetResources(ctx, upstreamRevision)
+	if err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	contents := resources.Spec.Resources
+	if m.upstreamVerifier != nil {
+		contents = withoutSignature(contents)
+	}
+
+	upstream, lock, err := upstreamRevision.GetLock()
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("cannot determine upstream lock for package %q: %w", name, err)
+	}
+
+	// Update Kptfile
+	if err := kpt.UpdateKptfileUpstream(m.name, contents, upstream, lock); err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("failed to apply upstream lock to package %q: %w", name, err)
+	}
+
+	var resolved *api.PackageRevisionRef
 	if ref.Name == "" {
-		return repository.PackageResources{}, fmt.Errorf("upstreamRef.name is required")
+		resolved = &api.PackageRevisionRef{Name: name, Namespace: ref.Namespace}
 	}
+	return repository.PackageResources{
+		Contents:  contents,
+		FileModes: resources.Spec.FileModes,
+	}, resolved, nil
+}
 
-	upstreamRevision, err := (&PackageFetcher{
+func (m *clonePackageMutation) fetcher() *PackageFetcher {
+	return &PackageFetcher{
 		repoOpener:        m.repoOpener,
 		referenceResolver: m.referenceResolver,
-	}).FetchRevision(ctx, ref, m.namespace)
-	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("failed to fetch package revision %q: %w", ref.Name, err)
+		sizeBudget:        m.sizeBudget,
+		verifier:          m.upstreamVerifier,
 	}
+}
 
-	resources, err := upstreamRevision.GetResources(ctx)
-	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot read contents of package %q: %w", ref.Name, err)
+// fetchUpstreamRevision fetches the package revision ref refers to, and returns the
+// reference it was fetched by.
+func (m *clonePackageMutation) fetchUpstreamRevision(ctx context.Context, ref *api.PackageRevisionRef) (repository.PackageRevision, *api.PackageRevisionRef, error) {
+	// Reuse an earlier resolution so that recloning does not pick up newer revisions.
+	fetchRef := ref
+	if resolved := m.task.Clone.ResolvedUpstreamRef; ref.Name == "" && resolved != nil && resolved.Name != "" {
+		fetchRef = resolved
 	}
+	revision, err := m.fetcher().FetchRevision(ctx, fetchRef, m.namespace)
+	return revision, fetchRef, err
+}
 
-	upstream, lock, err := upstreamRevision.GetLock()
+func describePackageRevisionRef(ref *api.PackageRevisionRef) string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	revision := ref.Revision
+	if revision == "" {
+		revision = api.LatestPublishedRevision
+	}
+	return fmt.Sprintf("%s/%s@%s", ref.Repository, ref.Package, revision)
+}
+
+func (m *clonePackageMutation) cloneFromGit(ctx context.Context, gitPackage *api.GitPackage) (repository.PackageResources, error) {
+	name := fmt.Sprintf("%s@%s", gitPackage.Directory, gitPackage.Ref)
+	fetched, lock, err := fetchGitPackage(m.sizeBudget.limitReads(ctx), m.credentialResolver, gitPackage)
 	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot determine upstream lock for package %q: %w", ref.Name, err)
+		return repository.PackageResources{}, m.sizeBudget.exceeded(name, err)
+	}
+	contents := fetched.Contents
+
+	if err := m.sizeBudget.check(name, contents); err != nil {
+		return repository.PackageResources{}, err
+	}
+	if m.upstreamVerifier != nil {
+		if err := m.upstreamVerifier.VerifyUpstream(ctx, name, contents); err != nil {
+			return repository.PackageResources{}, err
+		}
+		contents = withoutSignature(contents)
 	}
 
 	// Update Kptfile
-	if err := kpt.UpdateKptfileUpstream(m.name, resources.Spec.Resources, upstream, lock); err != nil {
-		return repository.PackageResources{}, fmt.Errorf("failed to apply upstream lock to package %q: %w", ref.Name, err)
+	upstream, upstreamLock := gitUpstream(lock)
+	if err := kpt.UpdateKptfileUpstream(m.name, contents, upstream, upstreamLock); err != nil {
+		return repository.PackageResources{}, fmt.Errorf("failed to clone package %s@%s: %w", gitPackage.Directory, gitPackage.Ref, err)
 	}
 
 	return repository.PackageResources{
-		Contents: resources.Spec.Resources,
+		Contents:  contents,
+		FileModes: fetched.FileModes,
 	}, nil
 }
 
-func (m *clonePackageMutation) cloneFromGit(ctx context.Context, gitPackage *api.GitPackage) (repository.PackageResources, error) {
+// fetchGitPackage reads the package from a git repository which isn't registered with
+// Porch, and returns its resources and the lock of the commit it was read from.
+func fetchGitPackage(ctx context.Context, credentialResolver repository.CredentialResolver, gitPackage *api.GitPackage) (repository.PackageResources, v1.GitLock, error) {
 	// TODO: Cache unregistered repositories with appropriate cache eviction policy.
 	// TODO: Separate low-level repository access from Repository abstraction?
 
@@ -149,48 +306,41 @@ func (m *clonePackageMutation) cloneFromGit(ctx context.Context, gitPackage *api
 
 	dir, err := os.MkdirTemp("", "clone-git-package-*")
 	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot create temporary directory to clone Git repository: %w", err)
+		return repository.PackageResources{}, v1.GitLock{}, fmt.Errorf("cannot create temporary directory to clone Git repository: %w", err)
 	}
 	defer os.RemoveAll(dir)
 
 	r, err := git.OpenRepository(ctx, "", "", &spec, false, dir, git.GitRepositoryOptions{
-		CredentialResolver: m.credentialResolver,
+		CredentialResolver: credentialResolver,
 		MainBranchStrategy: git.SkipVerification, // We are only reading so we don't need the main branch to exist.
 	})
 	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot clone Git repository: %w", err)
+		return repository.PackageResources{}, v1.GitLock{}, fmt.Errorf("cannot clone Git repository: %w", err)
 	}
 
 	revision, lock, err := r.GetPackageRevision(ctx, gitPackage.Ref, gitPackage.Directory)
 	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot find package %s@%s: %w", gitPackage.Directory, gitPackage.Ref, err)
+		return repository.PackageResources{}, v1.GitLock{}, fmt.Errorf("cannot find package %s@%s: %w", gitPackage.Directory, gitPackage.Ref, err)
 	}
 
 	resources, err := revision.GetResources(ctx)
 	if err != nil {
-		return repository.PackageResources{}, fmt.Errorf("cannot read package resources: %w", err)
+		return repository.PackageResources{}, v1.GitLock{}, fmt.Errorf("cannot read package resources: %w", err)
 	}
+	return repository.PackageResources{Contents: resources.Spec.Resources, FileModes: resources.Spec.FileModes}, lock, nil
+}
 
-	contents := resources.Spec.Resources
-
-	// Update Kptfile
-	if err := kpt.UpdateKptfileUpstream(m.name, contents, v1.Upstream{
+// gitUpstream returns the Kptfile upstream and upstream lock of a package read from git.
+func gitUpstream(lock v1.GitLock) (v1.Upstream, v1.UpstreamLock) {
+	upstream := v1.Upstream{
 		Type: v1.GitOrigin,
 		Git: &v1.Git{
 			Repo:      lock.Repo,
 			Directory: lock.Directory,
 			Ref:       lock.Ref,
 		},
-	}, v1.UpstreamLock{
-		Type: v1.GitOrigin,
-		Git:  &lock,
-	}); err != nil {
-		return repository.PackageResources{}, fmt.Errorf("failed to clone package %s@%s: %w", gitPackage.Directory, gitPackage.Ref, err)
 	}
-
-	return repository.PackageResources{
-		Contents: contents,
-	}, nil
+	return upstream, v1.UpstreamLock{Type: v1.GitOrigin, Git: &lock}
 }
 
 func (m *clonePackageMutation) cloneFromOci(ctx context.Context, ociPackage *api.OciPackage) (repository.PackageResources, error) {
@@ -24,11 +24,16 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/go-git/go-billy/v5/memfs"
@@ -38,6 +43,9 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 )
 
 func createRepoWithContents(t *testing.T, contentDir string) *gogit.Repository {
@@ -259,3 +267,255 @@ func TestCloneGitBasicAuth(t *testing.T) {
 
 	t.Logf("%v", r)
 }
+
+func TestCloneLatestPublishedRevision(t *testing.T) {
+	revision := func(name, rev string, lifecycle v1alpha1.PackageRevisionLifecycle) repository.PackageRevision {
+		return &fake.PackageRevision{
+			Name: name,
+			PackageRevisionKey: repository.PackageRevisionKey{
+				Repository: "blueprints",
+				Package:    "bucket",
+				Revision:   rev,
+			},
+			PackageLifecycle: lifecycle,
+			Resources: &v1alpha1.PackageRevisionResources{
+				Spec: v1alpha1.PackageRevisionResourcesSpec{
+					Resources: map[string]string{
+						kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: bucket\n",
+						"revision.txt":      rev,
+					},
+				},
+			},
+			Kptfile: kptfile.KptFile{
+				Upstream:     &kptfile.Upstream{},
+				UpstreamLock: &kptfile.UpstreamLock{},
+			},
+		}
+	}
+
+	published := &fakeRepositoryOpener{
+		repository: &fake.Repository{
+			PackageRevisions: []repository.PackageRevision{
+				revision("blueprints-v1", "v1", v1alpha1.PackageRevisionLifecyclePublished),
+				revision("blueprints-v3", "v3", v1alpha1.PackageRevisionLifecycleDraft),
+				revision("blueprints-v2", "v2", v1alpha1.PackageRevisionLifecyclePublished),
+			},
+		},
+	}
+	unpublished := &fakeRepositoryOpener{
+		repository: &fake.Repository{
+			PackageRevisions: []repository.PackageRevision{
+				revision("blueprints-v1", "v1", v1alpha1.PackageRevisionLifecycleProposed),
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		repoOpener   RepositoryOpener
+		revision     string
+		resolved     *v1alpha1.PackageRevisionRef
+		wantRevision string
+		wantErr      bool
+	}{
+		"latest": {
+			repoOpener:   published,
+			revision:     v1alpha1.LatestPublishedRevision,
+			wantRevision: "v2",
+		},
+		"revision omitted": {
+			repoOpener:   published,
+			wantRevision: "v2",
+		},
+		"explicit revision": {
+			repoOpener:   published,
+			revision:     "v1",
+			wantRevision: "v1",
+		},
+		"recorded resolution": {
+			repoOpener:   published,
+			resolved:     &v1alpha1.PackageRevisionRef{Name: "blueprints-v1"},
+			wantRevision: "v1",
+		},
+		"no published revision": {
+			repoOpener: unpublished,
+			wantErr:    true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cpm := clonePackageMutation{
+				task: &v1alpha1.Task{
+					Type: v1alpha1.TaskTypeClone,
+					Clone: &v1alpha1.PackageCloneTaskSpec{
+						Upstream: v1alpha1.UpstreamPackage{
+							UpstreamRef: &v1alpha1.PackageRevisionRef{
+								Repository: "blueprints",
+								Package:    "bucket",
+								Revision:   tc.revision,
+							},
+						},
+						ResolvedUpstreamRef: tc.resolved,
+					},
+				},
+				namespace:         "test-namespace",
+				name:              "downstream",
+				repoOpener:        tc.repoOpener,
+				referenceResolver: &fakeReferenceResolver{},
+			}
+
+			result, task, err := cpm.Apply(context.Background(), repository.PackageResources{})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected clone to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clone failed: %v", err)
+			}
+			if got, want := result.Contents["revision.txt"], tc.wantRevision; got != want {
+				t.Errorf("cloned wrong revision: got %q, want %q", got, want)
+			}
+			want := "blueprints-" + tc.wantRevision
+			if got := task.Clone.ResolvedUpstreamRef; got == nil || got.Name != want {
+				t.Errorf("unexpected resolved upstream: got %v, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPreserveUpstreamResolution(t *testing.T) {
+	cloneTask := func(revision string, resolved *v1alpha1.PackageRevisionRef) *v1alpha1.PackageRevision {
+		return &v1alpha1.PackageRevision{
+			Spec: v1alpha1.PackageRevisionSpec{
+				Tasks: []v1alpha1.Task{{
+					Type: v1alpha1.TaskTypeClone,
+					Clone: &v1alpha1.PackageCloneTaskSpec{
+						Upstream: v1alpha1.UpstreamPackage{
+							UpstreamRef: &v1alpha1.PackageRevisionRef{Repository: "blueprints", Package: "bucket", Revision: revision},
+						},
+						ResolvedUpstreamRef: resolved,
+					},
+				}},
+			},
+		}
+	}
+
+	oldObj := cloneTask("", &v1alpha1.PackageRevisionRef{Name: "blueprints-v1"})
+
+	unchanged := cloneTask("", nil)
+	unchanged.Spec.Tasks[0].Clone.Strategy = v1alpha1.FastForward
+	preserveUpstreamResolution(oldObj, unchanged)
+	if got := unchanged.Spec.Tasks[0].Clone.ResolvedUpstreamRef; got == nil || got.Name != "blueprints-v1" {
+		t.Errorf("expected resolution to be preserved, got %v", got)
+	}
+
+	changed := cloneTask("v2", &v1alpha1.PackageRevisionRef{Name: "blueprints-v1"})
+	preserveUpstreamResolution(oldObj, changed)
+	if got := changed.Spec.Tasks[0].Clone.ResolvedUpstreamRef; got != nil {
+		t.Errorf("expected resolution to be dropped when upstream changes, got %v", got)
+	}
+}
+
+func TestCloneDeploymentNamespace(t *testing.T) {
+	opener := &fakeRepositoryOpener{
+		repository: &fake.Repository{
+			PackageRevisions: []repository.PackageRevision{&fake.PackageRevision{
+				Name: "blueprints-app-v1",
+				PackageRevisionKey: repository.PackageRevisionKey{
+					Repository: "blueprints",
+					Package:    "app",
+					Revision:   "v1",
+				},
+				PackageLifecycle: v1alpha1.PackageRevisionLifecyclePublished,
+				Resources: &v1alpha1.PackageRevisionResources{
+					Spec: v1alpha1.PackageRevisionResourcesSpec{
+						Resources: map[string]string{
+							kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+							"configmap.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: example\n",
+							"rbac.yaml":         "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: app\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: Role\nmetadata:\n  name: app\n",
+						},
+					},
+				},
+				Kptfile: kptfile.KptFile{
+					Upstream:     &kptfile.Upstream{},
+					UpstreamLock: &kptfile.UpstreamLock{},
+				},
+			}},
+		},
+	}
+
+	namespaces := func(t *testing.T, resources repository.PackageResources) map[string]string {
+		t.Helper()
+		result := map[string]string{}
+		for _, file := range []string{"configmap.yaml", "rbac.yaml", builtins.PkgContextFile} {
+			nodes, err := (&kio.ByteReader{Reader: strings.NewReader(resources.Contents[file])}).Read()
+			if err != nil {
+				t.Fatalf("cannot parse %s: %v", file, err)
+			}
+			for _, node := range nodes {
+				result[node.GetKind()+"/"+node.GetName()] = node.GetNamespace()
+			}
+		}
+		return result
+	}
+
+	testCases := map[string]struct {
+		repository *configapi.Repository
+		stamp      bool
+		want       map[string]string
+	}{
+		"disabled": {
+			repository: &configapi.Repository{Spec: configapi.RepositorySpec{Deployment: true}},
+			want:       map[string]string{"ConfigMap/app": "example", "ClusterRole/app": "", "Role/app": "", "ConfigMap/kptfile.kpt.dev": ""},
+		},
+		"blueprint repository": {
+			repository: &configapi.Repository{},
+			stamp:      true,
+			want:       map[string]string{"ConfigMap/app": "example", "ClusterRole/app": "", "Role/app": ""},
+		},
+		"derived from package context": {
+			repository: &configapi.Repository{Spec: configapi.RepositorySpec{Deployment: true}},
+			stamp:      true,
+			want:       map[string]string{"ConfigMap/app": "app-prod", "ClusterRole/app": "", "Role/app": "app-prod", "ConfigMap/kptfile.kpt.dev": ""},
+		},
+		"configured by repository": {
+			repository: &configapi.Repository{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{configapi.DeploymentNamespaceAnnotation: "prod"}},
+				Spec:       configapi.RepositorySpec{Deployment: true},
+			},
+			want: map[string]string{"ConfigMap/app": "prod", "ClusterRole/app": "", "Role/app": "prod", "ConfigMap/kptfile.kpt.dev": ""},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cad := &cadEngine{stampDeploymentNamespace: tc.stamp}
+			cpm := clonePackageMutation{
+				task: &v1alpha1.Task{
+					Type: v1alpha1.TaskTypeClone,
+					Clone: &v1alpha1.PackageCloneTaskSpec{
+						Upstream: v1alpha1.UpstreamPackage{
+							UpstreamRef: &v1alpha1.PackageRevisionRef{Name: "blueprints-app-v1"},
+						},
+					},
+				},
+				namespace:         "test-namespace",
+				name:              "app-prod",
+				isDeployment:      tc.repository.Spec.Deployment,
+				repoOpener:        opener,
+				referenceResolver: &fakeReferenceResolver{},
+			}
+			cpm.targetNamespace, cpm.stampNamespace = cad.deploymentNamespace(tc.repository)
+
+			result, _, err := cpm.Apply(context.Background(), repository.PackageResources{})
+			if err != nil {
+				t.Fatalf("clone failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, namespaces(t, result)); diff != "" {
+				t.Errorf("unexpected namespaces of resources (-want, +got): %s", diff)
+			}
+		})
+	}
+}
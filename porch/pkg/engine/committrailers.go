@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// trailerKeyPattern matches the keys of git trailers, such as `Reviewed-By`.
+var trailerKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// validateCommitTrailers checks the commit trailers of the repository spec.
+func validateCommitTrailers(spec *configapi.RepositorySpec) error {
+	if len(spec.CommitTrailers) != 0 && spec.Type != configapi.RepositoryTypeGit {
+		return &RepositoryConfigError{Field: "spec.commitTrailers", Reason: "only supported by git repositories"}
+	}
+	for i, trailer := range spec.CommitTrailers {
+		field := fmt.Sprintf("spec.commitTrailers[%d]", i)
+		if !trailerKeyPattern.MatchString(trailer.Trailer) {
+			return &RepositoryConfigError{Field: field + ".trailer", Reason: fmt.Sprintf("invalid trailer key %q; must consist of alphanumeric characters and '-'", trailer.Trailer)}
+		}
+		// The kpt key holds the annotations Porch reads back from the commits.
+		if strings.EqualFold(trailer.Trailer, "kpt") {
+			return &RepositoryConfigError{Field: field + ".trailer", Reason: "the trailer key is reserved by Porch"}
+		}
+		if (trailer.Label == "") == (trailer.Annotation == "") {
+			return &RepositoryConfigError{Field: field, Reason: "exactly one of label or annotation is required"}
+		}
+	}
+	return nil
+}
+
+// commitTrailers returns the trailers the repository adds to the commit publishing a
+// package revision with the labels and annotations. Line breaks in the values are
+// replaced by spaces, since a trailer is a single line.
+func commitTrailers(repositoryObj *configapi.Repository, labels, annotations map[string]string) []repository.CommitTrailer {
+	var trailers []repository.CommitTrailer
+	for _, rule := range repositoryObj.Spec.CommitTrailers {
+		var value string
+		var found bool
+		if rule.Label != "" {
+			value, found = labels[rule.Label]
+		} else {
+			value, found = annotations[rule.Annotation]
+		}
+		if !found {
+			continue
+		}
+		trailers = append(trailers, repository.CommitTrailer{
+			Key:   rule.Trailer,
+			Value: strings.Join(strings.Fields(value), " "),
+		})
+	}
+	return trailers
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateCommitTrailers(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		repoType  configapi.RepositoryType
+		trailers  []configapi.CommitTrailer
+		wantField string
+	}{
+		{name: "none", repoType: configapi.RepositoryTypeOCI},
+		{
+			name:     "valid",
+			repoType: configapi.RepositoryTypeGit,
+			trailers: []configapi.CommitTrailer{
+				{Trailer: "Reviewed-By", Annotation: "example.com/reviewer"},
+				{Trailer: "Team", Label: "team"},
+			},
+		},
+		{
+			name:      "oci repository",
+			repoType:  configapi.RepositoryTypeOCI,
+			trailers:  []configapi.CommitTrailer{{Trailer: "Team", Label: "team"}},
+			wantField: "spec.commitTrailers",
+		},
+		{
+			name:      "invalid key",
+			repoType:  configapi.RepositoryTypeGit,
+			trailers:  []configapi.CommitTrailer{{Trailer: "Reviewed By", Label: "team"}},
+			wantField: "spec.commitTrailers[0].trailer",
+		},
+		{
+			name:      "reserved key",
+			repoType:  configapi.RepositoryTypeGit,
+			trailers:  []configapi.CommitTrailer{{Trailer: "KPT", Label: "team"}},
+			wantField: "spec.commitTrailers[0].trailer",
+		},
+		{
+			name:     "label and annotation",
+			repoType: configapi.RepositoryTypeGit,
+			trailers: []configapi.CommitTrailer{
+				{Trailer: "Team", Label: "team"},
+				{Trailer: "Owner", Label: "owner", Annotation: "example.com/owner"},
+			},
+			wantField: "spec.commitTrailers[1]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCommitTrailers(&configapi.RepositorySpec{Type: tc.repoType, CommitTrailers: tc.trailers})
+			if tc.wantField == "" {
+				if err != nil {
+					t.Errorf("validateCommitTrailers failed: %v", err)
+				}
+				return
+			}
+			var configErr *RepositoryConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("got error %v; want a RepositoryConfigError", err)
+			}
+			if configErr.Field != tc.wantField {
+				t.Errorf("error of field %q; want %q", configErr.Field, tc.wantField)
+			}
+		})
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	repositoryObj := &configapi.Repository{
+		Spec: configapi.RepositorySpec{
+			Type: configapi.RepositoryTypeGit,
+			CommitTrailers: []configapi.CommitTrailer{
+				{Trailer: "Reviewed-By", Annotation: "example.com/reviewer"},
+				{Trailer: "Ticket", Annotation: "example.com/ticket"},
+				{Trailer: "Team", Label: "team"},
+			},
+		},
+	}
+	labels := map[string]string{"team": "platform"}
+	annotations := map[string]string{"example.com/reviewer": "someone@example.com\nKpt: injected"}
+
+	// Missing values are skipped; line breaks can't start another trailer.
+	want := []repository.CommitTrailer{
+		{Key: "Reviewed-By", Value: "someone@example.com Kpt: injected"},
+		{Key: "Team", Value: "platform"},
+	}
+	if diff := cmp.Diff(want, commitTrailers(repositoryObj, labels, annotations)); diff != "" {
+		t.Errorf("unexpected trailers (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// The package revisions of a repository are recorded in three places: the repository
+// itself, their metadata, and the cache. Operations keep them consistent, but incidents
+// like restoring etcd from a backup or force pushing to git don't go through Porch.
+
+// CheckConsistency compares the package revisions of the repository with their metadata
+// and the cache, and returns the inconsistencies found.
+func (cad *cadEngine) CheckConsistency(ctx context.Context, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::CheckConsistency", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.checkConsistency(ctx, repo, repositoryObj)
+}
+
+// RepairConsistency checks the consistency of the repository like CheckConsistency and
+// applies the fixes which can't lose data: the cache is refreshed from the repository,
+// and, once the cache is known to be current, orphaned metadata is deleted, missing
+// metadata is created and system labels stored with metadata are removed. The findings
+// resolved by the fixes are marked as repaired.
+func (cad *cadEngine) RepairConsistency(ctx context.Context, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::RepairConsistency", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.repairConsistency(ctx, repo, repositoryObj)
+}
+
+// cachedRepository is a repository whose cache can be checked and refreshed.
+type cachedRepository interface {
+	repository.Repository
+	repository.CacheChecker
+}
+
+func (cad *cadEngine) repairConsistency(ctx context.Context, repo cachedRepository, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error) {
+	findings, err := cad.checkConsistency(ctx, repo, repositoryObj)
+	if err != nil || len(findings) == 0 {
+		return findings, err
+	}
+
+	// Metadata is only judged against a current cache: a package revision missing from
+	// a stale cache would have its metadata deleted as orphaned.
+	if err := repo.RefreshCache(ctx); err != nil {
+		return nil, fmt.Errorf("cannot refresh cache: %w", err)
+	}
+	refreshed, err := cad.checkConsistency(ctx, repo, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	for _, finding := range refreshed {
+		if err := cad.repairFinding(ctx, repositoryObj, finding); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining, err := cad.checkConsistency(ctx, repo, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	unresolved := map[api.ConsistencyFinding]bool{}
+	for _, finding := range remaining {
+		unresolved[finding] = true
+	}
+	for i := range findings {
+		findings[i].Repaired = !unresolved[findings[i]]
+		delete(unresolved, findings[i])
+	}
+	// Report inconsistencies that appeared while repairing, too.
+	for _, finding := range remaining {
+		if unresolved[finding] {
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// repairFinding applies the fix of the finding, if it has a safe one.
+func (cad *cadEngine) repairFinding(ctx context.Context, repositoryObj *configapi.Repository, finding api.ConsistencyFinding) error {
+	namespacedName := types.NamespacedName{Name: finding.PackageRevision, Namespace: repositoryObj.Namespace}
+	switch finding.Type {
+	case api.ConsistencyFindingOrphanedMetadata:
+		if _, err := cad.metadataStore.Delete(ctx, namespacedName); err != nil {
+			// Deleted concurrently, for example by the refresh of the cache.
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("cannot delete metadata of package revision %q: %w", finding.PackageRevision, err)
+		}
+		klog.FromContext(ctx).Info("Deleted orphaned metadata of package revision", "name", finding.PackageRevision)
+
+	case api.ConsistencyFindingUnindexedPackageRevision:
+		pkgRevMeta := meta.PackageRevisionMeta{Name: namespacedName.Name, Namespace: namespacedName.Namespace}
+		if _, err := cad.metadataStore.Create(ctx, pkgRevMeta, repositoryObj); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return nil
+			}
+			return fmt.Errorf("cannot create metadata of package revision %q: %w", finding.PackageRevision, err)
+		}
+		klog.FromContext(ctx).Info("Created missing metadata of package revision", "name", finding.PackageRevision)
+
+	case api.ConsistencyFindingLatestLabel:
+		pkgRevMeta, err := cad.metadataStore.Get(ctx, namespacedName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if _, stored := pkgRevMeta.Labels[api.LatestPackageRevisionKey]; !stored {
+			return nil
+		}
+		pkgRevMeta.Labels = userLabels(pkgRevMeta.Labels)
+		// Leave the status of the PackageRev alone.
+		pkgRevMeta.Artifacts = nil
+		pkgRevMeta.DeploymentStatus = nil
+		pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+		if _, err := cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return fmt.Errorf("cannot update metadata of package revision %q: %w", finding.PackageRevision, err)
+		}
+		klog.FromContext(ctx).Info("Removed stored latest label from metadata of package revision", "name", finding.PackageRevision)
+	}
+	return nil
+}
+
+func (cad *cadEngine) checkConsistency(ctx context.Context, repo cachedRepository, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error) {
+	var findings []api.ConsistencyFinding
+
+	discrepancies, err := repo.CheckCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range discrepancies {
+		finding := api.ConsistencyFinding{Type: api.ConsistencyFindingCacheMismatch, PackageRevision: d.Name, Message: d.Message}
+		if d.Latest {
+			finding.Type = api.ConsistencyFindingLatestLabel
+		}
+		findings = append(findings, finding)
+	}
+
+	pkgRevs, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	metas, err := cad.metadataStore.List(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[string]bool{}
+	for _, m := range metas {
+		indexed[m.Name] = true
+	}
+	existing := map[string]bool{}
+	for _, pr := range pkgRevs {
+		existing[pr.KubeObjectName()] = true
+		if !indexed[pr.KubeObjectName()] {
+			findings = append(findings, api.ConsistencyFinding{
+				Type:            api.ConsistencyFindingUnindexedPackageRevision,
+				PackageRevision: pr.KubeObjectName(),
+				Message:         "package revision has no metadata",
+			})
+		}
+	}
+	for _, m := range metas {
+		if !existing[m.Name] {
+			findings = append(findings, api.ConsistencyFinding{
+				Type:            api.ConsistencyFindingOrphanedMetadata,
+				PackageRevision: m.Name,
+				Message:         "metadata has no package revision in the repository",
+			})
+			continue
+		}
+		// The latest label is computed by Porch; a stored one is ignored, but shows
+		// metadata written by an older Porch or by hand.
+		if _, stored := m.Labels[api.LatestPackageRevisionKey]; stored {
+			findings = append(findings, api.ConsistencyFinding{
+				Type:            api.ConsistencyFindingLatestLabel,
+				PackageRevision: m.Name,
+				Message:         fmt.Sprintf("metadata stores the %s label, which is computed by Porch", api.LatestPackageRevisionKey),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Type != findings[j].Type {
+			return findings[i].Type < findings[j].Type
+		}
+		return findings[i].PackageRevision < findings[j].PackageRevision
+	})
+	return findings, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// staleCache is a repository whose cache lags behind git: it lists the cached package
+// revisions and reports the discrepancies until it is refreshed.
+type staleCache struct {
+	fake.Repository
+	git           []repository.PackageRevision
+	discrepancies []repository.CacheDiscrepancy
+}
+
+var _ cachedRepository = &staleCache{}
+
+func (r *staleCache) CheckCache(context.Context) ([]repository.CacheDiscrepancy, error) {
+	return r.discrepancies, nil
+}
+
+func (r *staleCache) RefreshCache(context.Context) error {
+	r.PackageRevisions = r.git
+	r.discrepancies = nil
+	return nil
+}
+
+func TestConsistency(t *testing.T) {
+	ctx := context.Background()
+	git := []repository.PackageRevision{
+		&fake.PackageRevision{Name: "blueprints-1111", Namespace: "default"},
+		&fake.PackageRevision{Name: "blueprints-2222", Namespace: "default"},
+		// Pushed to git after the last sync of the cache.
+		&fake.PackageRevision{Name: "blueprints-3333", Namespace: "default"},
+	}
+	repo := &staleCache{
+		Repository: fake.Repository{PackageRevisions: git[:2]},
+		git:        git,
+		discrepancies: []repository.CacheDiscrepancy{
+			{Name: "blueprints-2222", Latest: true, Message: "not cached as the latest revision of its package, but it is"},
+			{Name: "blueprints-3333", Message: "package revision is missing from the cache"},
+		},
+	}
+	store := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{
+		// Written by hand.
+		{Name: "blueprints-1111", Namespace: "default", Labels: map[string]string{api.LatestPackageRevisionKey: "true", "team": "payments"}},
+		// Created by Porch, unlike the package revision, which only the stale cache is missing.
+		{Name: "blueprints-3333", Namespace: "default"},
+		// Restored from an etcd backup taken before the package revision was deleted.
+		{Name: "blueprints-4444", Namespace: "default"},
+	}}
+	cad := &cadEngine{metadataStore: store}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	findings, err := cad.checkConsistency(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("checkConsistency failed: %v", err)
+	}
+	want := []api.ConsistencyFinding{
+		{Type: api.ConsistencyFindingCacheMismatch, PackageRevision: "blueprints-3333", Message: "package revision is missing from the cache"},
+		{Type: api.ConsistencyFindingLatestLabel, PackageRevision: "blueprints-1111", Message: "metadata stores the kpt.dev/latest-revision label, which is computed by Porch"},
+		{Type: api.ConsistencyFindingLatestLabel, PackageRevision: "blueprints-2222", Message: "not cached as the latest revision of its package, but it is"},
+		{Type: api.ConsistencyFindingOrphanedMetadata, PackageRevision: "blueprints-3333", Message: "metadata has no package revision in the repository"},
+		{Type: api.ConsistencyFindingOrphanedMetadata, PackageRevision: "blueprints-4444", Message: "metadata has no package revision in the repository"},
+		{Type: api.ConsistencyFindingUnindexedPackageRevision, PackageRevision: "blueprints-2222", Message: "package revision has no metadata"},
+	}
+	if diff := cmp.Diff(want, findings); diff != "" {
+		t.Errorf("unexpected findings (-want, +got): %s", diff)
+	}
+	if len(store.Metas) != 3 {
+		t.Errorf("checking consistency changed metadata: %v", store.Metas)
+	}
+
+	repaired, err := cad.repairConsistency(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("repairConsistency failed: %v", err)
+	}
+	for i := range want {
+		want[i].Repaired = true
+	}
+	if diff := cmp.Diff(want, repaired); diff != "" {
+		t.Errorf("unexpected repaired findings (-want, +got): %s", diff)
+	}
+
+	// The metadata of the package revision missing from the stale cache was kept.
+	got := map[string]map[string]string{}
+	for _, m := range store.Metas {
+		got[m.Name] = m.Labels
+	}
+	wantMetas := map[string]map[string]string{
+		"blueprints-1111": {"team": "payments"},
+		"blueprints-2222": nil,
+		"blueprints-3333": nil,
+	}
+	if diff := cmp.Diff(wantMetas, got); diff != "" {
+		t.Errorf("unexpected metadata after repair (-want, +got): %s", diff)
+	}
+
+	findings, err = cad.checkConsistency(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("checkConsistency failed: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("inconsistencies left after repair: %v", findings)
+	}
+}
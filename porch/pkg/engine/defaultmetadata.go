@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+)
+
+// isPorchManagedKey returns true if the label or annotation key is managed by Porch: the
+// key of a system label, or a key in the kpt.dev domain or its subdomains.
+func isPorchManagedKey(key string) bool {
+	if isSystemLabel(key) {
+		return true
+	}
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	return domain == "kpt.dev" || strings.HasSuffix(domain, ".kpt.dev")
+}
+
+// validateDefaultMetadata checks the default metadata of the repository spec. Keys
+// managed by Porch can't be defaulted.
+func validateDefaultMetadata(spec *configapi.RepositorySpec) error {
+	defaults := spec.DefaultMetadata
+	if defaults == nil {
+		return nil
+	}
+	fieldPath := field.NewPath("spec", "defaultMetadata")
+	for _, f := range []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "labels", values: defaults.Labels},
+		{name: "annotations", values: defaults.Annotations},
+	} {
+		keys := make([]string, 0, len(f.values))
+		for k := range f.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if isPorchManagedKey(k) {
+				return &RepositoryConfigError{
+					Field:  fieldPath.Child(f.name).Key(k).String(),
+					Reason: "the key is managed by Porch and can't be defaulted",
+				}
+			}
+		}
+	}
+	if errs := metav1validation.ValidateLabels(defaults.Labels, fieldPath.Child("labels")); len(errs) != 0 {
+		return &RepositoryConfigError{Field: errs[0].Field, Reason: errs[0].ErrorBody()}
+	}
+	if errs := apivalidation.ValidateAnnotations(defaults.Annotations, fieldPath.Child("annotations")); len(errs) != 0 {
+		return &RepositoryConfigError{Field: errs[0].Field, Reason: errs[0].ErrorBody()}
+	}
+	return nil
+}
+
+// withDefaultMetadata returns the labels and annotations of a package revision created in
+// the repository: labels and annotations merged with the default metadata of the
+// repository. Values of labels and annotations take precedence over the defaults. Keys
+// managed by Porch are never defaulted, even if the repository wasn't validated.
+func withDefaultMetadata(ctx context.Context, repositoryObj *configapi.Repository, labels, annotations map[string]string) (map[string]string, map[string]string) {
+	defaults := repositoryObj.Spec.DefaultMetadata
+	if defaults == nil {
+		return labels, annotations
+	}
+	return mergeDefaults(ctx, "label", defaults.Labels, labels), mergeDefaults(ctx, "annotation", defaults.Annotations, annotations)
+}
+
+func mergeDefaults(ctx context.Context, kind string, defaults, values map[string]string) map[string]string {
+	var merged map[string]string
+	for k, v := range defaults {
+		if _, found := values[k]; found {
+			continue
+		}
+		if isPorchManagedKey(k) {
+			klog.FromContext(ctx).Info("Ignoring default metadata of repository: the key is managed by Porch", "kind", kind, "key", k)
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]string, len(defaults)+len(values))
+			for k, v := range values {
+				merged[k] = v
+			}
+		}
+		merged[k] = v
+	}
+	if merged == nil {
+		return values
+	}
+	return merged
+}
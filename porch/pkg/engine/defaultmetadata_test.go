@@ -0,0 +1,133 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePackageRevisionDefaultMetadata(t *testing.T) {
+	repositoryObj := &configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"},
+		Spec: configapi.RepositorySpec{
+			DefaultMetadata: &configapi.DefaultMetadata{
+				Labels: map[string]string{
+					"team":                       "platform",
+					"cost-center":                "1234",
+					api.LatestPackageRevisionKey: "true",
+				},
+				Annotations: map[string]string{
+					"example.com/owner":           "platform@example.com",
+					api.RenderToolchainAnnotation: "v0",
+				},
+			},
+		},
+	}
+	obj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "blueprints-app-v1",
+			Namespace:   "default",
+			Labels:      map[string]string{"team": "apps"},
+			Annotations: map[string]string{"example.com/reviewer": "someone@example.com"},
+		},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Lifecycle:   api.PackageRevisionLifecycleDraft,
+		},
+	}
+
+	metadataStore := &metafake.MemoryMetadataStore{}
+	cad := &cadEngine{renderer: &countingRenderer{}, metadataStore: metadataStore}
+	if _, err := cad.createPackageRevision(context.Background(), &creatingRepository{}, repositoryObj, obj, nil); err != nil {
+		t.Fatalf("createPackageRevision failed: %v", err)
+	}
+
+	if len(metadataStore.Metas) != 1 {
+		t.Fatalf("created %d package revision metadata; want 1", len(metadataStore.Metas))
+	}
+	got := metadataStore.Metas[0]
+	// Labels of the package revision win; keys managed by Porch are never defaulted.
+	wantLabels := map[string]string{"team": "apps", "cost-center": "1234"}
+	if diff := cmp.Diff(wantLabels, got.Labels); diff != "" {
+		t.Errorf("unexpected labels (-want, +got): %s", diff)
+	}
+	wantAnnotations := map[string]string{
+		"example.com/owner":    "platform@example.com",
+		"example.com/reviewer": "someone@example.com",
+	}
+	if diff := cmp.Diff(wantAnnotations, got.Annotations); diff != "" {
+		t.Errorf("unexpected annotations (-want, +got): %s", diff)
+	}
+	if _, found := obj.Labels["cost-center"]; found {
+		t.Errorf("defaulting changed the labels of the request")
+	}
+}
+
+func TestValidateDefaultMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		defaults  *configapi.DefaultMetadata
+		wantField string
+	}{
+		{name: "none"},
+		{
+			name: "valid",
+			defaults: &configapi.DefaultMetadata{
+				Labels:      map[string]string{"team": "platform"},
+				Annotations: map[string]string{"example.com/owner": "platform"},
+			},
+		},
+		{
+			name:      "system label",
+			defaults:  &configapi.DefaultMetadata{Labels: map[string]string{api.LatestPackageRevisionKey: "true"}},
+			wantField: "spec.defaultMetadata.labels[kpt.dev/latest-revision]",
+		},
+		{
+			name:      "porch annotation",
+			defaults:  &configapi.DefaultMetadata{Annotations: map[string]string{api.AutoProposeAnnotation: "true"}},
+			wantField: "spec.defaultMetadata.annotations[porch.kpt.dev/auto-propose]",
+		},
+		{
+			name:      "invalid label value",
+			defaults:  &configapi.DefaultMetadata{Labels: map[string]string{"team": "platform team"}},
+			wantField: "spec.defaultMetadata.labels",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDefaultMetadata(&configapi.RepositorySpec{DefaultMetadata: tc.defaults})
+			if tc.wantField == "" {
+				if err != nil {
+					t.Errorf("validateDefaultMetadata failed: %v", err)
+				}
+				return
+			}
+			var configErr *RepositoryConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("got error %v; want a RepositoryConfigError", err)
+			}
+			if configErr.Field != tc.wantField {
+				t.Errorf("error of field %q; want %q", configErr.Field, tc.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// renderDeferredReason is the reason of the api.RenderedConditionType condition of drafts
+// stored without rendering.
+const renderDeferredReason = "RenderDeferred"
+
+// renderMutation returns a mutation rendering the package with the engine's settings, and
+// the toolchain version and pipeline overlay of the annotations of the package revision.
+func (cad *cadEngine) renderMutation(annotations map[string]string) *renderPackageMutation {
+	return &renderPackageMutation{
+		renderer:       cad.renderer,
+		runtime:        cad.renderRuntime(pinnedToolchain(annotations)),
+		recordChanges:  cad.recordRenderChanges,
+		maxConcurrency: cad.renderConcurrency,
+		allowedImages:  cad.allowedFunctionImages,
+		pipelineAppend: annotations[api.PipelineAppendAnnotation],
+	}
+}
+
+// replaceResourcesMutations returns the mutations storing new resources in a draft. The
+// resources are rendered, unless rendering is deferred; then the draft is marked as
+// unrendered instead.
+func (cad *cadEngine) replaceResourcesMutations(repositoryObj *configapi.Repository, old, new *api.PackageRevisionResources, annotations map[string]string) []mutation {
+	mutations := []mutation{
+		&mutationReplaceResources{
+			newResources:          new,
+			oldResources:          old,
+			protectPackageContext: protectsPackageContext(repositoryObj),
+		},
+	}
+	if cad.deferRender {
+		return append(mutations, &renderedConditionMutation{rendered: false})
+	}
+	mutations = append(mutations, cad.renderMutation(annotations))
+	if isRenderDeferred(repository.PackageResources{Contents: new.Spec.Resources}) {
+		mutations = append(mutations, &renderedConditionMutation{rendered: true})
+	}
+	return mutations
+}
+
+// completeDeferredRender renders a draft marked as unrendered, and removes the mark, when
+// the draft leaves the Draft lifecycle. A draft which stays a draft only has the mark
+// removed if the mutations render it anyway.
+func (cad *cadEngine) completeDeferredRender(resources repository.PackageResources, newLifecycle api.PackageRevisionLifecycle, mutations []mutation, annotations map[string]string) []mutation {
+	if !isRenderDeferred(resources) {
+		return mutations
+	}
+	rendered := len(mutations) > 0
+	if rendered {
+		_, rendered = mutations[len(mutations)-1].(*renderPackageMutation)
+	}
+	if !rendered {
+		if newLifecycle == api.PackageRevisionLifecycleDraft {
+			return mutations
+		}
+		mutations = append(mutations, cad.renderMutation(annotations))
+	}
+	return append(mutations, &renderedConditionMutation{rendered: true})
+}
+
+// isRenderDeferred returns true if the root Kptfile of the package has the
+// api.RenderedConditionType condition set by deferring the render.
+func isRenderDeferred(resources repository.PackageResources) bool {
+	kf, err := yaml.Parse(resources.Contents[kptfile.KptFileName])
+	if err != nil {
+		return false
+	}
+	condition, err := kf.Pipe(yaml.Lookup("status", "conditions"), yaml.MatchElement("type", api.RenderedConditionType))
+	return err == nil && condition != nil
+}
+
+// renderedConditionMutation adds the api.RenderedConditionType condition to the root Kptfile
+// of the package if rendered is false, and removes it if rendered is true. The rest of the
+// Kptfile, including comments, is kept as is.
+type renderedConditionMutation struct {
+	rendered bool
+}
+
+var _ mutation = &renderedConditionMutation{}
+
+func (m *renderedConditionMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	_, span := tracer.Start(ctx, "renderedConditionMutation::Apply", trace.WithAttributes())
+	defer span.End()
+
+	contents, found := resources.Contents[kptfile.KptFileName]
+	if !found {
+		return resources, nil, nil
+	}
+	kf, err := yaml.Parse(contents)
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("cannot parse %s: %w", kptfile.KptFileName, err)
+	}
+
+	if m.rendered {
+		conditions, err := kf.Pipe(yaml.Lookup("status", "conditions"))
+		if err != nil || conditions == nil {
+			return resources, nil, err
+		}
+		if _, err := conditions.Pipe(yaml.ElementSetter{Keys: []string{"type"}, Values: []string{api.RenderedConditionType}}); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+		// Don't leave behind an empty status.
+		if len(conditions.Content()) == 0 {
+			if err := kf.PipeE(yaml.Lookup("status"), yaml.Clear("conditions")); err != nil {
+				return repository.PackageResources{}, nil, err
+			}
+		}
+		if status := kf.Field("status"); status != nil && yaml.IsEmptyMap(status.Value) {
+			if _, err := kf.Pipe(yaml.Clear("status")); err != nil {
+				return repository.PackageResources{}, nil, err
+			}
+		}
+	} else {
+		condition := yaml.NewMapRNode(nil)
+		for _, field := range [][2]string{
+			{"type", api.RenderedConditionType},
+			{"status", string(kptfile.ConditionFalse)},
+			{"reason", renderDeferredReason},
+			{"message", "Package resources were stored without rendering; the package is rendered when it is proposed or published"},
+		} {
+			if err := condition.PipeE(yaml.SetField(field[0], yaml.NewStringRNode(field[1]))); err != nil {
+				return repository.PackageResources{}, nil, err
+			}
+		}
+		if err := kf.PipeE(
+			yaml.LookupCreate(yaml.SequenceNode, "status", "conditions"),
+			yaml.ElementSetter{Element: condition.YNode(), Keys: []string{"type"}, Values: []string{api.RenderedConditionType}},
+		); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+	}
+
+	updated, err := kf.String()
+	if err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	result := repository.PackageResources{Contents: map[string]string{}}
+	for k, v := range resources.Contents {
+		result.Contents[k] = v
+	}
+	result.Contents[kptfile.KptFileName] = updated
+	return result, nil, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type countingRenderer struct {
+	renders int
+}
+
+func (r *countingRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	r.renders++
+	return nil
+}
+
+func TestDeferredRender(t *testing.T) {
+	ctx := context.Background()
+
+	const kf = `# The blueprint.
+apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+`
+	apply := func(t *testing.T, resources repository.PackageResources, mutations []mutation) repository.PackageResources {
+		t.Helper()
+		for _, m := range mutations {
+			applied, _, err := m.Apply(ctx, resources)
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			resources = applied
+		}
+		return resources
+	}
+	edit := func(t *testing.T, cad *cadEngine, resources repository.PackageResources, i int) repository.PackageResources {
+		t.Helper()
+		newResources := map[string]string{
+			kptfile.KptFileName: resources.Contents[kptfile.KptFileName],
+			"configmap.yaml":    fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  edit: %q\n", fmt.Sprint(i)),
+		}
+		return apply(t, resources, cad.replaceResourcesMutations(
+			&configapi.Repository{},
+			&api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: resources.Contents}},
+			&api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: newResources}},
+			nil,
+		))
+	}
+
+	t.Run("deferred", func(t *testing.T) {
+		renderer := &countingRenderer{}
+		cad := &cadEngine{renderer: renderer, deferRender: true}
+
+		resources := repository.PackageResources{Contents: map[string]string{kptfile.KptFileName: kf}}
+		for i := 0; i < 3; i++ {
+			resources = edit(t, cad, resources, i)
+		}
+		if renderer.renders != 0 {
+			t.Errorf("edits of the draft rendered the package %d times; want none", renderer.renders)
+		}
+		if !isRenderDeferred(resources) {
+			t.Fatalf("draft isn't marked as unrendered:\n%s", resources.Contents[kptfile.KptFileName])
+		}
+		if got := strings.Count(resources.Contents[kptfile.KptFileName], api.RenderedConditionType); got != 1 {
+			t.Errorf("Kptfile has %d %s conditions; want 1:\n%s", got, api.RenderedConditionType, resources.Contents[kptfile.KptFileName])
+		}
+
+		// Staying a draft doesn't render.
+		resources = apply(t, resources, cad.completeDeferredRender(resources, api.PackageRevisionLifecycleDraft, nil, nil))
+		if renderer.renders != 0 {
+			t.Errorf("updating the draft rendered the package %d times; want none", renderer.renders)
+		}
+
+		resources = apply(t, resources, cad.completeDeferredRender(resources, api.PackageRevisionLifecycleProposed, nil, nil))
+		if renderer.renders != 1 {
+			t.Errorf("proposing the draft rendered the package %d times; want once", renderer.renders)
+		}
+		if isRenderDeferred(resources) {
+			t.Errorf("proposed package is still marked as unrendered")
+		}
+		if got, want := resources.Contents[kptfile.KptFileName], kf; got != want {
+			t.Errorf("unexpected Kptfile after rendering: got\n%s\nwant\n%s", got, want)
+		}
+		if got, want := len(cad.completeDeferredRender(resources, api.PackageRevisionLifecyclePublished, nil, nil)), 0; got != want {
+			t.Errorf("rendered package would be rendered again when published")
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		renderer := &countingRenderer{}
+		cad := &cadEngine{renderer: renderer}
+
+		resources := repository.PackageResources{Contents: map[string]string{kptfile.KptFileName: kf}}
+		for i := 0; i < 3; i++ {
+			resources = edit(t, cad, resources, i)
+		}
+		if renderer.renders != 3 {
+			t.Errorf("edits of the draft rendered the package %d times; want 3", renderer.renders)
+		}
+		if isRenderDeferred(resources) {
+			t.Errorf("rendered draft is marked as unrendered")
+		}
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExternalDependency is a host outside of Porch that packages of a repository
+// depend on through their upstreams.
+type ExternalDependency struct {
+	// Host is the host name, and port if any, of the Git server or OCI registry.
+	Host string `json:"host"`
+	// URLs are the Git repositories and OCI images on the host, sorted.
+	URLs []string `json:"urls"`
+}
+
+// ListExternalDependencies returns the external Git repositories and OCI images the
+// packages of the repository ultimately depend on, grouped by host and sorted.
+//
+// Upstreams referring to package revisions in registered repositories are followed
+// to the root of the chain: a package cloned from Git or OCI directly, in which case
+// its source is the dependency, or a package without upstream, in which case the
+// registered repository holding it is.
+func (cad *cadEngine) ListExternalDependencies(ctx context.Context, repositoryObj *configapi.Repository) ([]ExternalDependency, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ListExternalDependencies", trace.WithAttributes())
+	defer span.End()
+
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	w := &dependencyWalker{
+		fetcher: &PackageFetcher{
+			repoOpener:        cad,
+			referenceResolver: cad.referenceResolver,
+		},
+		referenceResolver: cad.referenceResolver,
+		namespace:         repositoryObj.Namespace,
+		visited:           map[string]bool{},
+		urls:              map[string]bool{},
+	}
+	for _, rev := range revisions {
+		apiRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.walk(ctx, apiRev, nil); err != nil {
+			return nil, err
+		}
+	}
+	return groupExternalDependencies(w.urls), nil
+}
+
+type dependencyWalker struct {
+	fetcher           *PackageFetcher
+	referenceResolver ReferenceResolver
+	namespace         string
+
+	// visited holds the names of the upstream package revisions already walked.
+	visited map[string]bool
+	// urls holds the external dependencies found so far.
+	urls map[string]bool
+}
+
+// walk follows the upstream of rev, which is stored in repositoryObj. repositoryObj
+// is nil for the package revisions of the repository the walk starts from.
+func (w *dependencyWalker) walk(ctx context.Context, rev *api.PackageRevision, repositoryObj *configapi.Repository) error {
+	upstream, resolved := currentUpstream(rev)
+	switch {
+	case upstream == nil:
+		if repositoryObj != nil {
+			w.addRepository(repositoryObj)
+		}
+		return nil
+	case upstream.Git != nil:
+		w.urls[upstream.Git.Repo] = true
+		return nil
+	case upstream.Oci != nil:
+		w.urls[upstream.Oci.Image] = true
+		return nil
+	case upstream.UpstreamRef == nil:
+		return nil
+	}
+
+	ref := upstream.UpstreamRef
+	if ref.Name == "" && resolved != nil && resolved.Name != "" {
+		ref = resolved
+	}
+	repositoryName := ref.Repository
+	if ref.Name != "" {
+		var err error
+		if repositoryName, err = parseUpstreamRepository(ref.Name); err != nil {
+			return err
+		}
+	}
+	var upstreamRepository configapi.Repository
+	if err := w.referenceResolver.ResolveReference(ctx, w.namespace, repositoryName, &upstreamRepository); err != nil {
+		return fmt.Errorf("cannot find upstream repository %s/%s of package revision %q: %w", w.namespace, repositoryName, rev.Name, err)
+	}
+	upstreamRevision, err := w.fetcher.FetchRevision(ctx, ref, w.namespace)
+	if err != nil {
+		return fmt.Errorf("cannot find upstream %q of package revision %q: %w", describePackageRevisionRef(ref), rev.Name, err)
+	}
+
+	name := upstreamRevision.KubeObjectName()
+	if w.visited[name] {
+		return nil
+	}
+	w.visited[name] = true
+
+	apiUpstream, err := upstreamRevision.GetPackageRevision(ctx)
+	if err != nil {
+		return err
+	}
+	return w.walk(ctx, apiUpstream, &upstreamRepository)
+}
+
+func (w *dependencyWalker) addRepository(repositoryObj *configapi.Repository) {
+	switch {
+	case repositoryObj.Spec.Git != nil:
+		w.urls[repositoryObj.Spec.Git.Repo] = true
+	case repositoryObj.Spec.Oci != nil:
+		w.urls[repositoryObj.Spec.Oci.Registry] = true
+	}
+}
+
+// currentUpstream returns the upstream set by the last clone or update task of rev,
+// and the package revision a clone resolved its upstream reference to, if any.
+func currentUpstream(rev *api.PackageRevision) (*api.UpstreamPackage, *api.PackageRevisionRef) {
+	var upstream *api.UpstreamPackage
+	var resolved *api.PackageRevisionRef
+	for i := range rev.Spec.Tasks {
+		task := &rev.Spec.Tasks[i]
+		switch {
+		case task.Type == api.TaskTypeClone && task.Clone != nil:
+			upstream, resolved = &task.Clone.Upstream, task.Clone.ResolvedUpstreamRef
+		case task.Type == api.TaskTypeUpdate && task.Update != nil:
+			upstream, resolved = &task.Update.Upstream, nil
+		}
+	}
+	return upstream, resolved
+}
+
+func groupExternalDependencies(urls map[string]bool) []ExternalDependency {
+	byHost := map[string][]string{}
+	for u := range urls {
+		host := externalHost(u)
+		byHost[host] = append(byHost[host], u)
+	}
+
+	dependencies := make([]ExternalDependency, 0, len(byHost))
+	for host, hostURLs := range byHost {
+		sort.Strings(hostURLs)
+		dependencies = append(dependencies, ExternalDependency{Host: host, URLs: hostURLs})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Host < dependencies[j].Host
+	})
+	return dependencies
+}
+
+// externalHost returns the host of a Git repository address, such as
+// https://github.com/org/repo.git or git@github.com:org/repo.git, or of an OCI
+// image or registry, such as us-docker.pkg.dev/project/repository/image:v1.
+func externalHost(address string) string {
+	if strings.Contains(address, "://") {
+		if u, err := url.Parse(address); err == nil {
+			return u.Host
+		}
+	}
+	at, colon, slash := strings.Index(address, "@"), strings.Index(address, ":"), strings.Index(address, "/")
+	if at >= 0 && colon > at && (slash < 0 || slash > colon) {
+		// scp-like syntax of Git over SSH: user@host:path
+		return address[at+1 : colon]
+	}
+	host, _, _ := strings.Cut(address, "/")
+	return host
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeRepositories resolves and opens repositories by name.
+type fakeRepositories struct {
+	objects      map[string]*configapi.Repository
+	repositories map[string]*fake.Repository
+}
+
+func (f *fakeRepositories) ResolveReference(ctx context.Context, namespace, name string, result Object) error {
+	repositoryObj, found := f.objects[name]
+	if !found {
+		return fmt.Errorf("repository %q not found", name)
+	}
+	repositoryObj.DeepCopyInto(result.(*configapi.Repository))
+	return nil
+}
+
+func (f *fakeRepositories) OpenRepository(ctx context.Context, repositoryObj *configapi.Repository) (repository.Repository, error) {
+	return f.repositories[repositoryObj.Name], nil
+}
+
+func (f *fakeRepositories) add(name, address string, revisions ...*api.PackageRevision) {
+	f.objects[name] = &configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: configapi.RepositorySpec{
+			Type: configapi.RepositoryTypeGit,
+			Git:  &configapi.GitRepository{Repo: address},
+		},
+	}
+	repo := &fake.Repository{}
+	for _, rev := range revisions {
+		repo.PackageRevisions = append(repo.PackageRevisions, &fake.PackageRevision{
+			Name: rev.Name,
+			PackageRevisionKey: repository.PackageRevisionKey{
+				Repository: name,
+				Package:    rev.Spec.PackageName,
+				Revision:   rev.Spec.Revision,
+			},
+			PackageLifecycle: api.PackageRevisionLifecyclePublished,
+			PackageRevision:  rev,
+		})
+	}
+	f.repositories[name] = repo
+}
+
+func TestListExternalDependencies(t *testing.T) {
+	revision := func(name, pkg string, tasks ...api.Task) *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       api.PackageRevisionSpec{PackageName: pkg, Revision: "v1", Tasks: tasks},
+		}
+	}
+	initTask := api.Task{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}}
+	clone := func(upstream api.UpstreamPackage) api.Task {
+		return api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: upstream}}
+	}
+
+	repos := &fakeRepositories{objects: map[string]*configapi.Repository{}, repositories: map[string]*fake.Repository{}}
+	repos.add("blueprints", "https://github.com/example/blueprints.git",
+		revision("blueprints-base", "base", initTask),
+		revision("blueprints-bucket", "bucket", clone(api.UpstreamPackage{Git: &api.GitPackage{Repo: "git@gitlab.example.com:team/bucket.git"}})),
+	)
+	repos.add("platform", "https://github.com/example/platform.git",
+		revision("platform-app", "app", clone(api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-base"}})),
+	)
+
+	deployments := []*api.PackageRevision{
+		// Chain of registered repositories rooted at a package without upstream.
+		revision("deployments-app", "app", clone(api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "platform-app"}})),
+		// Upstream referenced by package rather than package revision, cloned from Git.
+		revision("deployments-bucket", "bucket", clone(api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Repository: "blueprints", Package: "bucket"}})),
+		// The update task replaces the upstream of the clone task.
+		revision("deployments-db", "db",
+			clone(api.UpstreamPackage{Git: &api.GitPackage{Repo: "https://github.com/example/old-db.git"}}),
+			api.Task{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{Upstream: api.UpstreamPackage{
+				Oci: &api.OciPackage{Image: "us-docker.pkg.dev/example/packages/db@sha256:0123"},
+			}}},
+		),
+		revision("deployments-local", "local", initTask),
+	}
+
+	w := &dependencyWalker{
+		fetcher:           &PackageFetcher{repoOpener: repos, referenceResolver: repos},
+		referenceResolver: repos,
+		namespace:         "default",
+		visited:           map[string]bool{},
+		urls:              map[string]bool{},
+	}
+	for _, rev := range deployments {
+		if err := w.walk(context.Background(), rev, nil); err != nil {
+			t.Fatalf("walk of %q failed: %v", rev.Name, err)
+		}
+	}
+
+	want := []ExternalDependency{
+		{Host: "github.com", URLs: []string{"https://github.com/example/blueprints.git"}},
+		{Host: "gitlab.example.com", URLs: []string{"git@gitlab.example.com:team/bucket.git"}},
+		{Host: "us-docker.pkg.dev", URLs: []string{"us-docker.pkg.dev/example/packages/db@sha256:0123"}},
+	}
+	if diff := cmp.Diff(want, groupExternalDependencies(w.urls)); diff != "" {
+		t.Errorf("unexpected external dependencies (-want, +got): %s", diff)
+	}
+
+	missing := revision("deployments-missing", "missing", clone(api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "unknown-app"}}))
+	if err := w.walk(context.Background(), missing, nil); err == nil {
+		t.Errorf("walk of a package revision with a missing upstream repository succeeded")
+	}
+}
+
+func TestExternalHost(t *testing.T) {
+	for address, want := range map[string]string{
+		"https://github.com/example/blueprints.git":         "github.com",
+		"https://user@git.example.com:8443/blueprints":      "git.example.com:8443",
+		"ssh://git@github.com/example/blueprints.git":       "github.com",
+		"git@github.com:example/blueprints.git":             "github.com",
+		"us-docker.pkg.dev/example/packages/db:v1":          "us-docker.pkg.dev",
+		"us-docker.pkg.dev/example/packages/db@sha256:0123": "us-docker.pkg.dev",
+		"localhost:5000/packages":                           "localhost:5000",
+	} {
+		if got := externalHost(address); got != want {
+			t.Errorf("externalHost(%q): got %q, want %q", address, got, want)
+		}
+	}
+}
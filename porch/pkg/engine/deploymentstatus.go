@@ -0,0 +1,68 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentStatusTTL is how long a reported deployment status is current. Agents are
+// expected to report more often; a status reported earlier is displayed as unknown.
+const DeploymentStatusTTL = 15 * time.Minute
+
+// UpdateDeploymentStatus records the deployment status reported for the package revision,
+// or clears it if status is nil, with the time Porch received the report. Like
+// artifacts, the status is kept in the metadata of the package revision; it is only
+// reported and doesn't change the lifecycle of the package revision.
+func (cad *cadEngine) UpdateDeploymentStatus(ctx context.Context, rev *PackageRevision, status *api.DeploymentStatus) (*PackageRevision, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::UpdateDeploymentStatus", trace.WithAttributes())
+	defer span.End()
+	ctx = withPackageLogger(ctx, rev.repoPackageRevision.Key())
+
+	pkgRevMeta := rev.packageRevisionMeta
+	pkgRevMeta.DeploymentStatus = &api.DeploymentStatus{}
+	if status != nil {
+		pkgRevMeta.DeploymentStatus = status.DeepCopy()
+		pkgRevMeta.DeploymentStatus.ReportedTime = metav1.Now().Rfc3339Copy()
+	}
+	// Leave the rest of the status of the PackageRev alone.
+	pkgRevMeta.Artifacts = nil
+	pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+	updated, err := cad.metadataStore.Update(ctx, pkgRevMeta)
+	if err != nil {
+		return nil, fmt.Errorf("cannot update deployment status of package revision %q: %w", rev.KubeObjectName(), err)
+	}
+	return &PackageRevision{
+		repoPackageRevision: rev.repoPackageRevision,
+		packageRevisionMeta: updated,
+		sharedFrom:          rev.sharedFrom,
+	}, nil
+}
+
+// CurrentSyncStatus returns the sync status of the deployment status, or
+// SyncStatusUnknown if none was reported within DeploymentStatusTTL of now.
+func CurrentSyncStatus(status *api.DeploymentStatus, now time.Time) api.SyncStatus {
+	if status == nil || status.SyncStatus == "" || now.Sub(status.ReportedTime.Time) > DeploymentStatusTTL {
+		return api.SyncStatusUnknown
+	}
+	return status.SyncStatus
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateDeploymentStatus(t *testing.T) {
+	ctx := context.Background()
+	pkgRevMeta := meta.PackageRevisionMeta{
+		Name:      "deployments-1234",
+		Namespace: "default",
+		Labels:    map[string]string{"team": "a"},
+		Artifacts: []api.Artifact{{Type: api.ArtifactTypeFluxOCI, URL: "oci://registry/app:v1"}},
+	}
+	store := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+	cad := &cadEngine{metadataStore: store}
+	rev := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{
+			Name:               "deployments-1234",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "deployments", Package: "app", Revision: "v1"},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+			PackageRevision: &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployments-1234", Namespace: "default"},
+				Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: "v1", Lifecycle: api.PackageRevisionLifecyclePublished},
+			},
+		},
+		packageRevisionMeta: pkgRevMeta,
+	}
+
+	syncedTime := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	reported := &api.DeploymentStatus{
+		Cluster:        "us-east1/prod",
+		ObservedCommit: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		SyncStatus:     api.SyncStatusSynced,
+		SyncedTime:     syncedTime,
+	}
+	updated, err := cad.UpdateDeploymentStatus(ctx, rev, reported)
+	if err != nil {
+		t.Fatalf("UpdateDeploymentStatus failed: %v", err)
+	}
+	obj, err := updated.GetPackageRevision(ctx)
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	got := obj.Status.DeploymentStatus
+	if got == nil || got.ReportedTime.IsZero() {
+		t.Fatalf("the time of the report wasn't recorded: %v", got)
+	}
+	want := reported.DeepCopy()
+	want.ReportedTime = got.ReportedTime
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected deployment status (-want, +got): %s", diff)
+	}
+	if lifecycle := obj.Spec.Lifecycle; lifecycle != api.PackageRevisionLifecyclePublished {
+		t.Errorf("lifecycle changed to %s", lifecycle)
+	}
+	if diff := cmp.Diff(pkgRevMeta.Artifacts, store.Metas[0].Artifacts); diff != "" {
+		t.Errorf("artifacts changed (-want, +got): %s", diff)
+	}
+
+	// Clearing the status removes it.
+	cleared, err := cad.UpdateDeploymentStatus(ctx, updated, nil)
+	if err != nil {
+		t.Fatalf("UpdateDeploymentStatus failed: %v", err)
+	}
+	if status := cleared.packageRevisionMeta.DeploymentStatus; status != nil {
+		t.Errorf("deployment status wasn't cleared: %v", status)
+	}
+}
+
+func TestCurrentSyncStatus(t *testing.T) {
+	now := time.Now()
+	reportedAt := func(ago time.Duration, status api.SyncStatus) *api.DeploymentStatus {
+		return &api.DeploymentStatus{Cluster: "prod", SyncStatus: status, ReportedTime: metav1.NewTime(now.Add(-ago))}
+	}
+	for name, tc := range map[string]struct {
+		status *api.DeploymentStatus
+		want   api.SyncStatus
+	}{
+		"not reported": {nil, api.SyncStatusUnknown},
+		"recent":       {reportedAt(time.Minute, api.SyncStatusOutOfSync), api.SyncStatusOutOfSync},
+		"stale":        {reportedAt(DeploymentStatusTTL+time.Minute, api.SyncStatusSynced), api.SyncStatusUnknown},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := CurrentSyncStatus(tc.status, now); got != tc.want {
+				t.Errorf("CurrentSyncStatus returned %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+)
+
+// DescriptionVars are the variables available to a package description template.
+type DescriptionVars struct {
+	// Package is the last segment of the package name.
+	Package string
+	// PackagePath is the full package name, including parent directories.
+	PackagePath string
+	// Repository is the name of the repository the package is created in.
+	Repository string
+}
+
+// ParseDescriptionTemplate parses a text/template generating package descriptions from
+// DescriptionVars, for example "{{.Package}}, maintained by the platform team".
+func ParseDescriptionTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("description").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// templateDescription generates the description of a package created without one from
+// the template of the repository, or else the template of the engine. It returns false
+// if neither is configured.
+func (cad *cadEngine) templateDescription(repositoryObj *configapi.Repository, obj *api.PackageRevision) (string, bool, error) {
+	tmpl := cad.descriptionTemplate
+	if text, found := repositoryObj.Annotations[configapi.DescriptionTemplateAnnotation]; found {
+		var err error
+		if tmpl, err = ParseDescriptionTemplate(text); err != nil {
+			return "", false, fmt.Errorf("repository %q: %w", repositoryObj.Name, err)
+		}
+	}
+	if tmpl == nil {
+		return "", false, nil
+	}
+
+	vars := DescriptionVars{
+		Package:     path.Base(obj.Spec.PackageName),
+		PackagePath: obj.Spec.PackageName,
+		Repository:  repositoryObj.Name,
+	}
+	var description strings.Builder
+	if err := tmpl.Execute(&description, vars); err != nil {
+		return "", false, fmt.Errorf("cannot generate package description: %w", err)
+	}
+	return description.String(), true, nil
+}
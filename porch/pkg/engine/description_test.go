@@ -0,0 +1,120 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDescriptionTemplate(t *testing.T) {
+	engineTemplate, err := ParseDescriptionTemplate("{{.PackagePath}} (engine)")
+	if err != nil {
+		t.Fatalf("ParseDescriptionTemplate failed: %v", err)
+	}
+	templatedRepository := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+		Name:        "blueprints",
+		Annotations: map[string]string{configapi.DescriptionTemplateAnnotation: "{{.Package}} from {{.Repository}}"},
+	}}
+	plainRepository := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "catalog"}}
+
+	for _, tc := range []struct {
+		name        string
+		cad         *cadEngine
+		repository  *configapi.Repository
+		description string // of an explicit init task; implicit if empty
+		want        string
+	}{
+		{
+			name:        "task description",
+			cad:         &cadEngine{descriptionTemplate: engineTemplate},
+			repository:  templatedRepository,
+			description: "my app",
+			want:        "my app",
+		},
+		{
+			name:       "repository template",
+			cad:        &cadEngine{descriptionTemplate: engineTemplate},
+			repository: templatedRepository,
+			want:       "app from blueprints",
+		},
+		{
+			name:       "engine template",
+			cad:        &cadEngine{descriptionTemplate: engineTemplate},
+			repository: plainRepository,
+			want:       "apps/app (engine)",
+		},
+		{
+			name:       "built-in",
+			cad:        &cadEngine{},
+			repository: plainRepository,
+			want:       "apps/app description",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, explicit := range []bool{false, true} {
+				obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "apps/app"}}
+				if explicit {
+					obj.Spec.Tasks = []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: tc.description}}}
+				} else if tc.description != "" {
+					continue
+				}
+				if err := tc.cad.ensureCreationTask(tc.repository, obj); err != nil {
+					t.Fatalf("ensureCreationTask failed: %v", err)
+				}
+				want := tc.want
+				if explicit && tc.description == "" && tc.cad.descriptionTemplate == nil {
+					// An explicit init task isn't given the built-in description.
+					want = ""
+				}
+				if got := obj.Spec.Tasks[0].Init.Description; got != want {
+					t.Errorf("unexpected description (explicit init task: %t): got %q, want %q", explicit, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid repository template", func(t *testing.T) {
+		repository := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{configapi.DescriptionTemplateAnnotation: "{{.Owner}}"},
+		}}
+		obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "app"}}
+		if err := (&cadEngine{}).ensureCreationTask(repository, obj); err == nil {
+			t.Errorf("template with an unknown variable was accepted")
+		}
+	})
+}
+
+func TestDescriptionTemplateCreatesPackage(t *testing.T) {
+	cad := &cadEngine{renderer: &countingRenderer{}}
+	repository := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+		Name:        "blueprints",
+		Annotations: map[string]string{configapi.DescriptionTemplateAnnotation: "{{.Package}}, owned by the platform team"},
+	}}
+	obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "app"}}
+	draft := &recordingDraft{}
+	if err := cad.applyTasks(context.Background(), draft, repository, obj, nil); err != nil {
+		t.Fatalf("applyTasks failed: %v", err)
+	}
+	if got := draft.resources[kptfile.KptFileName]; !strings.Contains(got, "description: app, owned by the platform team") {
+		t.Errorf("Kptfile doesn't have the templated description:\n%s", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultDraftBufferBytes is the package size up to which the task contents of drafts
+// are buffered in memory unless another threshold is configured.
+const DefaultDraftBufferBytes = 1024 * 1024
+
+// DraftWriteError is returned when the task contents buffered by a draft can't be
+// stored when the draft is closed. The buffered contents are written to the underlying
+// draft one task at a time, so the contents of the tasks before the failed one are
+// written already. The draft isn't closed after a failed write, so the package revision
+// is left unchanged, but repositories may have stored the written contents: OCI drafts
+// upload a layer per task, which no package revision then refers to.
+type DraftWriteError struct {
+	// Tasks are the types of the buffered tasks, in the order they were applied.
+	Tasks []api.TaskType
+	// Failed is the index in Tasks of the task whose contents couldn't be written, or
+	// -1 if all contents were written but closing the draft failed.
+	Failed int
+	Err    error
+}
+
+func (e *DraftWriteError) Error() string {
+	if e.Failed >= 0 {
+		return fmt.Sprintf("cannot write the contents of task %d (%s) of %d buffered tasks: %v", e.Failed, e.Tasks[e.Failed], len(e.Tasks), e.Err)
+	}
+	types := make([]string, len(e.Tasks))
+	for i, t := range e.Tasks {
+		types[i] = string(t)
+	}
+	return fmt.Sprintf("cannot store the contents of buffered tasks %s: %v", strings.Join(types, ", "), e.Err)
+}
+
+func (e *DraftWriteError) Unwrap() error {
+	return e.Err
+}
+
+// bufferedDraft keeps the contents of the tasks applied to a draft in memory and
+// writes them to the underlying draft, one update per task, when the draft is closed.
+// Requests which fail or are cancelled before they close the draft then write nothing
+// to the underlying draft. Once the package exceeds maxBytes, the buffered contents are
+// written and later updates go to the underlying draft right away.
+type bufferedDraft struct {
+	repository.PackageDraft
+	maxBytes int64
+
+	pending      []bufferedUpdate
+	writeThrough bool
+}
+
+type bufferedUpdate struct {
+	resources map[string]string
+	fileModes map[string]api.FileMode
+	task      *api.Task
+}
+
+var _ repository.PackageDraft = &bufferedDraft{}
+
+// bufferDraft returns the draft buffering task contents, unless buffering is disabled.
+func (cad *cadEngine) bufferDraft(draft repository.PackageDraft) repository.PackageDraft {
+	if cad.draftBufferBytes <= 0 {
+		return draft
+	}
+	return &bufferedDraft{PackageDraft: draft, maxBytes: cad.draftBufferBytes}
+}
+
+func (d *bufferedDraft) UpdateResources(ctx context.Context, new *api.PackageRevisionResources, task *api.Task) error {
+	if d.writeThrough {
+		return d.PackageDraft.UpdateResources(ctx, new, task)
+	}
+	if resourcesSize(new.Spec.Resources) > d.maxBytes {
+		if err := d.flush(ctx); err != nil {
+			return err
+		}
+		d.writeThrough = true
+		return d.PackageDraft.UpdateResources(ctx, new, task)
+	}
+
+	resources := make(map[string]string, len(new.Spec.Resources))
+	for k, v := range new.Spec.Resources {
+		resources[k] = v
+	}
+	d.pending = append(d.pending, bufferedUpdate{resources: resources, fileModes: copyFileModes(new.Spec.FileModes), task: task})
+	return nil
+}
+
+// Close writes the buffered task contents to the underlying draft and closes it.
+func (d *bufferedDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	ctx, span := tracer.Start(ctx, "bufferedDraft::Close", trace.WithAttributes())
+	defer span.End()
+
+	tasks := d.pendingTasks()
+	if err := d.flush(ctx); err != nil {
+		return nil, err
+	}
+	rev, err := d.PackageDraft.Close(ctx)
+	if err != nil {
+		if len(tasks) == 0 {
+			return nil, err
+		}
+		return nil, &DraftWriteError{Tasks: tasks, Failed: -1, Err: err}
+	}
+	return rev, nil
+}
+
+// flush writes the buffered task contents to the underlying draft. It stops at the
+// first failed write, or when the request is cancelled; the contents written before
+// are kept in the underlying draft.
+func (d *bufferedDraft) flush(ctx context.Context) error {
+	tasks := d.pendingTasks()
+	for i, update := range d.pending {
+		if err := checkCancelled(ctx); err != nil {
+			return err
+		}
+		if err := d.PackageDraft.UpdateResources(ctx, &api.PackageRevisionResources{
+			Spec: api.PackageRevisionResourcesSpec{Resources: update.resources, FileModes: update.fileModes},
+		}, update.task); err != nil {
+			return &DraftWriteError{Tasks: tasks, Failed: i, Err: err}
+		}
+	}
+	d.pending = nil
+	return nil
+}
+
+func (d *bufferedDraft) pendingTasks() []api.TaskType {
+	tasks := make([]api.TaskType, 0, len(d.pending))
+	for _, update := range d.pending {
+		if update.task != nil {
+			tasks = append(tasks, update.task.Type)
+		} else {
+			tasks = append(tasks, "")
+		}
+	}
+	return tasks
+}
+
+// resourcesSize returns the total size of the files of a package.
+func resourcesSize(resources map[string]string) int64 {
+	var size int64
+	for _, contents := range resources {
+		size += int64(len(contents))
+	}
+	return size
+}
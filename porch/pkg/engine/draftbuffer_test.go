@@ -0,0 +1,223 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+// writingDraft records the contents written for each task. Writes take latency, like
+// those of repositories which write each task to a remote.
+type writingDraft struct {
+	latency  time.Duration
+	failTask int
+	closeErr error
+
+	writes []map[string]string
+	closed bool
+}
+
+var _ repository.PackageDraft = &writingDraft{}
+
+func (d *writingDraft) UpdateResources(ctx context.Context, new *api.PackageRevisionResources, task *api.Task) error {
+	time.Sleep(d.latency)
+	if d.failTask > 0 && len(d.writes)+1 == d.failTask {
+		return errors.New("write failed")
+	}
+	d.writes = append(d.writes, new.Spec.Resources)
+	return nil
+}
+
+func (d *writingDraft) UpdateLifecycle(ctx context.Context, new api.PackageRevisionLifecycle) error {
+	return nil
+}
+
+func (d *writingDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	time.Sleep(d.latency)
+	d.closed = true
+	return nil, d.closeErr
+}
+
+func taskContents(i int) *api.PackageRevisionResources {
+	return &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"configmap.yaml": fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  task: \"%d\"\n", i),
+	}}}
+}
+
+func TestBufferedDraft(t *testing.T) {
+	ctx := context.Background()
+	tasks := []*api.Task{{Type: api.TaskTypeInit}, {Type: api.TaskTypePatch}, {Type: api.TaskTypeEval}}
+
+	t.Run("writes at close", func(t *testing.T) {
+		underlying := &writingDraft{}
+		draft := (&cadEngine{draftBufferBytes: DefaultDraftBufferBytes}).bufferDraft(underlying)
+		for i, task := range tasks {
+			if err := draft.UpdateResources(ctx, taskContents(i), task); err != nil {
+				t.Fatalf("UpdateResources failed: %v", err)
+			}
+		}
+		if len(underlying.writes) != 0 {
+			t.Fatalf("buffered draft wrote %d tasks before it was closed", len(underlying.writes))
+		}
+		if _, err := draft.Close(ctx); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		// Each task is still written separately, so it gets its own commit.
+		var want []map[string]string
+		for i := range tasks {
+			want = append(want, taskContents(i).Spec.Resources)
+		}
+		if diff := cmp.Diff(want, underlying.writes); diff != "" {
+			t.Errorf("unexpected writes (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("large package", func(t *testing.T) {
+		underlying := &writingDraft{}
+		draft := &bufferedDraft{PackageDraft: underlying, maxBytes: resourcesSize(taskContents(0).Spec.Resources)}
+		if err := draft.UpdateResources(ctx, taskContents(0), tasks[0]); err != nil {
+			t.Fatalf("UpdateResources failed: %v", err)
+		}
+		large := taskContents(1)
+		large.Spec.Resources["data.yaml"] = strings.Repeat("#", 100)
+		if err := draft.UpdateResources(ctx, large, tasks[1]); err != nil {
+			t.Fatalf("UpdateResources failed: %v", err)
+		}
+		// The package outgrew the buffer, so the buffered and the new task are written.
+		if got := len(underlying.writes); got != 2 {
+			t.Errorf("got %d writes after the package outgrew the buffer; want 2", got)
+		}
+		if err := draft.UpdateResources(ctx, taskContents(2), tasks[2]); err != nil {
+			t.Fatalf("UpdateResources failed: %v", err)
+		}
+		if got := len(underlying.writes); got != 3 {
+			t.Errorf("got %d writes after writing through; want 3", got)
+		}
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		underlying := &writingDraft{failTask: 2}
+		draft := (&cadEngine{draftBufferBytes: DefaultDraftBufferBytes}).bufferDraft(underlying)
+		for i, task := range tasks {
+			if err := draft.UpdateResources(ctx, taskContents(i), task); err != nil {
+				t.Fatalf("UpdateResources failed: %v", err)
+			}
+		}
+		_, err := draft.Close(ctx)
+		var writeErr *DraftWriteError
+		if !errors.As(err, &writeErr) {
+			t.Fatalf("Close returned %v; want a DraftWriteError", err)
+		}
+		if writeErr.Failed != 1 || writeErr.Tasks[writeErr.Failed] != api.TaskTypePatch {
+			t.Errorf("DraftWriteError reports task %d of %v as failed; want task 1 (patch)", writeErr.Failed, writeErr.Tasks)
+		}
+		// The tasks before the failed one are written to the underlying draft.
+		if diff := cmp.Diff([]map[string]string{taskContents(0).Spec.Resources}, underlying.writes); diff != "" {
+			t.Errorf("unexpected writes (-want, +got): %s", diff)
+		}
+		if underlying.closed {
+			t.Errorf("draft was closed after a failed write")
+		}
+	})
+
+	t.Run("rejected push", func(t *testing.T) {
+		underlying := &writingDraft{closeErr: errors.New("push rejected: non-fast-forward")}
+		draft := (&cadEngine{draftBufferBytes: DefaultDraftBufferBytes}).bufferDraft(underlying)
+		for i, task := range tasks {
+			if err := draft.UpdateResources(ctx, taskContents(i), task); err != nil {
+				t.Fatalf("UpdateResources failed: %v", err)
+			}
+		}
+		_, err := draft.Close(ctx)
+		want := "cannot store the contents of buffered tasks init, patch, eval: push rejected: non-fast-forward"
+		if err == nil || err.Error() != want {
+			t.Errorf("Close returned %v; want %q", err, want)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		underlying := &writingDraft{}
+		draft := (&cadEngine{draftBufferBytes: DefaultDraftBufferBytes}).bufferDraft(underlying)
+		if err := draft.UpdateResources(ctx, taskContents(0), tasks[0]); err != nil {
+			t.Fatalf("UpdateResources failed: %v", err)
+		}
+		cancel()
+		_, err := draft.Close(ctx)
+		var cancelled *CancelledError
+		if !errors.As(err, &cancelled) {
+			t.Fatalf("Close returned %v; want a CancelledError", err)
+		}
+		if len(underlying.writes) != 0 || underlying.closed {
+			t.Errorf("draft of a cancelled request was written")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		underlying := &writingDraft{}
+		if draft := (&cadEngine{}).bufferDraft(underlying); draft != underlying {
+			t.Errorf("draft was buffered with buffering disabled")
+		}
+	})
+}
+
+// BenchmarkDraftBuffering measures the latency of applying tasks to a draft whose
+// writes each take a remote round trip, with and without buffering, for requests which
+// succeed and for requests whose last task fails.
+func BenchmarkDraftBuffering(b *testing.B) {
+	const tasks = 5
+	ctx := context.Background()
+	for _, bc := range []struct {
+		name        string
+		bufferBytes int64
+		fail        bool
+	}{
+		{name: "write-through", bufferBytes: 0},
+		{name: "buffered", bufferBytes: DefaultDraftBufferBytes},
+		{name: "write-through-failed", bufferBytes: 0, fail: true},
+		{name: "buffered-failed", bufferBytes: DefaultDraftBufferBytes, fail: true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			cad := &cadEngine{draftBufferBytes: bc.bufferBytes}
+			for n := 0; n < b.N; n++ {
+				draft := cad.bufferDraft(&writingDraft{latency: time.Millisecond})
+				for i := 0; i < tasks; i++ {
+					if bc.fail && i == tasks-1 {
+						// The failed request abandons the draft.
+						break
+					}
+					if err := draft.UpdateResources(ctx, taskContents(i), &api.Task{Type: api.TaskTypePatch}); err != nil {
+						b.Fatalf("UpdateResources failed: %v", err)
+					}
+				}
+				if !bc.fail {
+					if _, err := draft.Close(ctx); err != nil {
+						b.Fatalf("Close failed: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context in which package revisions are created as dry runs: the
+// tasks are applied, and the package revision rendered, in memory, and nothing is
+// stored in the repository or the metadata store.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// isDryRun returns true if ctx was returned by WithDryRun.
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// createDryRun creates the package revision obj as a dry run. The tasks are applied, and
+// fail, as when the package revision is created, but the draft is kept in memory and no
+// metadata is stored.
+func (cad *cadEngine) createDryRun(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, packageConfig *builtins.PackageConfig) (*PackageRevision, error) {
+	draft := newDryRunDraft(repositoryObj.Name, obj)
+	if err := cad.applyTasks(ctx, draft, repositoryObj, obj, packageConfig); err != nil {
+		return nil, err
+	}
+	repoPkgRev, err := draft.Close(ctx)
+	if err != nil {
+		return nil, userError(err)
+	}
+	labels, annotations := withDefaultMetadata(ctx, repositoryObj, obj.Labels, obj.Annotations)
+	return &PackageRevision{
+		repoPackageRevision: repoPkgRev,
+		packageRevisionMeta: meta.PackageRevisionMeta{
+			Name:           repoPkgRev.KubeObjectName(),
+			Namespace:      repoPkgRev.KubeObjectNamespace(),
+			Labels:         userLabels(labels),
+			Annotations:    annotations,
+			LifecycleTimes: creationLifecycleTimes(obj.Spec.Lifecycle, metav1.Now().Rfc3339Copy(), cad.requestUser(ctx)),
+			TaskResults:    recordedTaskResults(ctx),
+		},
+	}, nil
+}
+
+// dryRunDraft is a draft of a package revision created as a dry run, which keeps the
+// resources and tasks of the package revision in memory.
+type dryRunDraft struct {
+	obj       *api.PackageRevision
+	key       repository.PackageRevisionKey
+	resources *api.PackageRevisionResources
+	tasks     []api.Task
+	lifecycle api.PackageRevisionLifecycle
+}
+
+var _ repository.PackageDraft = &dryRunDraft{}
+
+func newDryRunDraft(repositoryName string, obj *api.PackageRevision) *dryRunDraft {
+	return &dryRunDraft{
+		obj:       obj,
+		key:       repository.PackageRevisionKey{Repository: repositoryName, Package: obj.Spec.PackageName, Revision: obj.Spec.Revision},
+		resources: &api.PackageRevisionResources{},
+		lifecycle: obj.Spec.Lifecycle,
+	}
+}
+
+func (d *dryRunDraft) UpdateResources(ctx context.Context, new *api.PackageRevisionResources, task *api.Task) error {
+	d.resources = new.DeepCopy()
+	d.tasks = append(d.tasks, *task)
+	return nil
+}
+
+func (d *dryRunDraft) UpdateLifecycle(ctx context.Context, new api.PackageRevisionLifecycle) error {
+	d.lifecycle = new
+	return nil
+}
+
+func (d *dryRunDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	var kf kptfile.KptFile
+	if contents, found := d.resources.Spec.Resources[kptfile.KptFileName]; found {
+		decoded, err := internalpkg.DecodeKptfile(strings.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode the Kptfile of the package: %w", err)
+		}
+		kf = *decoded
+	}
+	return &dryRunPackageRevision{draft: d, kptfile: kf}, nil
+}
+
+// dryRunPackageRevision is a package revision created as a dry run. It isn't stored
+// anywhere.
+type dryRunPackageRevision struct {
+	draft   *dryRunDraft
+	kptfile kptfile.KptFile
+}
+
+var _ repository.PackageRevision = &dryRunPackageRevision{}
+
+func (p *dryRunPackageRevision) KubeObjectName() string {
+	return p.draft.obj.Name
+}
+
+func (p *dryRunPackageRevision) KubeObjectNamespace() string {
+	return p.draft.obj.Namespace
+}
+
+func (p *dryRunPackageRevision) Key() repository.PackageRevisionKey {
+	return p.draft.key
+}
+
+func (p *dryRunPackageRevision) Lifecycle() api.PackageRevisionLifecycle {
+	return p.draft.lifecycle
+}
+
+func (p *dryRunPackageRevision) GetPackageRevision(ctx context.Context) (*api.PackageRevision, error) {
+	key := p.Key()
+	status := api.PackageRevisionStatus{
+		Conditions: repository.ToApiConditions(p.kptfile),
+	}
+	if lock := p.kptfile.UpstreamLock; lock != nil && lock.Git != nil {
+		status.UpstreamLock = &api.UpstreamLock{
+			Type: api.OriginType(lock.Type),
+			Git: &api.GitLock{
+				Repo:      lock.Git.Repo,
+				Directory: lock.Git.Directory,
+				Commit:    lock.Git.Commit,
+				Ref:       lock.Git.Ref,
+			},
+		}
+	}
+	return &api.PackageRevision{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PackageRevision",
+			APIVersion: api.SchemeGroupVersion.Identifier(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.KubeObjectName(),
+			Namespace: p.KubeObjectNamespace(),
+		},
+		Spec: api.PackageRevisionSpec{
+			PackageName:    key.Package,
+			Revision:       key.Revision,
+			RepositoryName: key.Repository,
+
+			Lifecycle:      p.Lifecycle(),
+			Tasks:          append([]api.Task(nil), p.draft.tasks...),
+			ReadinessGates: repository.ToApiReadinessGates(p.kptfile),
+		},
+		Status: status,
+	}, nil
+}
+
+func (p *dryRunPackageRevision) GetResources(ctx context.Context) (*api.PackageRevisionResources, error) {
+	resources := p.draft.resources.DeepCopy()
+	resources.TypeMeta = metav1.TypeMeta{
+		Kind:       "PackageRevisionResources",
+		APIVersion: api.SchemeGroupVersion.Identifier(),
+	}
+	resources.ObjectMeta = metav1.ObjectMeta{
+		Name:      p.KubeObjectName(),
+		Namespace: p.KubeObjectNamespace(),
+	}
+	resources.Spec.PackageName = p.draft.key.Package
+	resources.Spec.Revision = p.draft.key.Revision
+	resources.Spec.RepositoryName = p.draft.key.Repository
+	return resources, nil
+}
+
+func (p *dryRunPackageRevision) GetKptfile(ctx context.Context) (kptfile.KptFile, error) {
+	return p.kptfile, nil
+}
+
+func (p *dryRunPackageRevision) GetUpstreamLock(ctx context.Context) (kptfile.Upstream, kptfile.UpstreamLock, error) {
+	if p.kptfile.Upstream == nil || p.kptfile.UpstreamLock == nil {
+		return kptfile.Upstream{}, kptfile.UpstreamLock{}, fmt.Errorf("package %s has no upstream", p.draft.key.Package)
+	}
+	return *p.kptfile.Upstream, *p.kptfile.UpstreamLock, nil
+}
+
+// GetLock returns the lock of the package revision as an upstream. A package revision
+// created as a dry run isn't stored, so it can't be an upstream.
+func (p *dryRunPackageRevision) GetLock() (kptfile.Upstream, kptfile.UpstreamLock, error) {
+	return kptfile.Upstream{}, kptfile.UpstreamLock{}, fmt.Errorf("package revision %s was created as a dry run and can't be an upstream", p.KubeObjectName())
+}
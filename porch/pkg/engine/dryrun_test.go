@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// failingRenderer fails to render packages.
+type failingRenderer struct{}
+
+func (r *failingRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	return &fnruntime.ExecError{OriginalErr: errors.New("function failed")}
+}
+
+func TestCreatePackageRevisionDryRun(t *testing.T) {
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	newObj := func() *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default", Labels: map[string]string{"team": "platform"}},
+			Spec: api.PackageRevisionSpec{
+				PackageName:    "app",
+				Revision:       "v1",
+				RepositoryName: "blueprints",
+				Lifecycle:      api.PackageRevisionLifecycleDraft,
+				Tasks:          []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "The app"}}},
+			},
+		}
+	}
+
+	t.Run("created", func(t *testing.T) {
+		ctx := WithDryRun(context.Background())
+		renderer := &countingRenderer{}
+		store := &metafake.MemoryMetadataStore{}
+		cad := &cadEngine{renderer: renderer, metadataStore: store, forceRender: true}
+		repo := &creatingRepository{}
+
+		created, err := cad.createPackageRevision(ctx, repo, repositoryObj, newObj(), nil)
+		if err != nil {
+			t.Fatalf("createPackageRevision failed: %v", err)
+		}
+		if repo.draft != nil {
+			t.Errorf("a draft was created in the repository")
+		}
+		if len(store.Metas) != 0 {
+			t.Errorf("metadata was stored: %v", store.Metas)
+		}
+		if renderer.renders != 1 {
+			t.Errorf("package was rendered %d times; want once", renderer.renders)
+		}
+
+		obj, err := created.GetPackageRevision(ctx)
+		if err != nil {
+			t.Fatalf("GetPackageRevision failed: %v", err)
+		}
+		if obj.Name != "blueprints-app-v1" || obj.Spec.Lifecycle != api.PackageRevisionLifecycleDraft || obj.Labels["team"] != "platform" {
+			t.Errorf("unexpected package revision: %+v", obj)
+		}
+		var types []api.TaskType
+		for _, task := range obj.Spec.Tasks {
+			types = append(types, task.Type)
+		}
+		if len(types) == 0 || types[0] != api.TaskTypeInit {
+			t.Errorf("unexpected tasks %v; want the init task first", types)
+		}
+
+		resources, err := created.GetResources(ctx)
+		if err != nil {
+			t.Fatalf("GetResources failed: %v", err)
+		}
+		if _, found := resources.Spec.Resources[kptfile.KptFileName]; !found {
+			t.Errorf("the package has no Kptfile: %v", resources.Spec.Resources)
+		}
+	})
+
+	t.Run("render failure", func(t *testing.T) {
+		// The dry run fails like the package revision creation.
+		var errs []error
+		for _, ctx := range []context.Context{context.Background(), WithDryRun(context.Background())} {
+			cad := &cadEngine{renderer: &failingRenderer{}, metadataStore: &metafake.MemoryMetadataStore{}, forceRender: true}
+			_, err := cad.createPackageRevision(ctx, &creatingRepository{}, repositoryObj, newObj(), nil)
+			var taskErr *TaskError
+			if !errors.As(err, &taskErr) {
+				t.Fatalf("createPackageRevision returned %v; want a TaskError", err)
+			}
+			errs = append(errs, err)
+		}
+		if created, dryRun := errs[0].(*TaskError), errs[1].(*TaskError); created.Class != dryRun.Class || created.Error() != dryRun.Error() {
+			t.Errorf("dry run failed with %s error %q; want %s error %q", dryRun.Class, dryRun, created.Class, created)
+		}
+	})
+}
@@ -23,11 +23,17 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// editPackageMutation copies the resources of an existing package revision, including
+// those of its subpackages, into the draft. The resources are copied verbatim, keyed by
+// their path in the package. Repositories store files but not directories, so empty
+// directories of the source aren't copied; neither are symlinks, which repositories
+// refuse to load.
 type editPackageMutation struct {
 	task              *api.Task
 	namespace         string
 	repoOpener        RepositoryOpener
 	referenceResolver ReferenceResolver
+	sizeBudget        *PackageSizeBudget
 }
 
 var _ mutation = &editPackageMutation{}
@@ -41,12 +47,24 @@ func (m *editPackageMutation) Apply(ctx context.Context, resources repository.Pa
 	sourceResources, err := (&PackageFetcher{
 		repoOpener:        m.repoOpener,
 		referenceResolver: m.referenceResolver,
+		sizeBudget:        m.sizeBudget,
 	}).FetchResources(ctx, sourceRef, m.namespace)
 	if err != nil {
 		return repository.PackageResources{}, nil, fmt.Errorf("failed to fetch resources for package %q: %w", sourceRef.Name, err)
 	}
 
+	// Copy the contents so later mutations of the draft can't change the resources of
+	// the source.
+	contents := make(map[string]string, len(sourceResources.Spec.Resources))
+	for p, content := range sourceResources.Spec.Resources {
+		if err := validateResourcePath(p); err != nil {
+			return repository.PackageResources{}, nil, fmt.Errorf("cannot copy package %q: %w", sourceRef.Name, err)
+		}
+		contents[p] = content
+	}
+
 	return repository.PackageResources{
-		Contents: sourceResources.Spec.Resources,
+		Contents:  contents,
+		FileModes: copyFileModes(sourceResources.Spec.FileModes),
 	}, &api.Task{}, nil
 }
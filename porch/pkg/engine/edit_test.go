@@ -95,6 +95,78 @@ info:
 	}
 }
 
+func TestEditCopiesSubpackages(t *testing.T) {
+	kptfileOf := func(name string) string {
+		return "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: " + name + "\n"
+	}
+	source := map[string]string{
+		kptfile.KptFileName:                        kptfileOf("root"),
+		"config.yaml":                              "kind: ConfigMap\n",
+		"apps/" + kptfile.KptFileName:              kptfileOf("apps"),
+		"apps/config.yaml":                         "kind: ConfigMap # apps\n",
+		"apps/web/" + kptfile.KptFileName:          kptfileOf("web"),
+		"apps/web/config.yaml":                     "kind: ConfigMap # web\n",
+		"apps/web/frontend/" + kptfile.KptFileName: kptfileOf("frontend"),
+		"apps/web/frontend/config.yaml":            "kind: ConfigMap # frontend\n",
+		"apps/web/frontend/static/index.html":      "<html>\n\t<body></body>\n</html>",
+		"db/" + kptfile.KptFileName:                kptfileOf("db"),
+		"db/config.yaml":                           "kind: ConfigMap # db\r\n",
+		"db/empty.txt":                             "",
+		"db/no-newline.yaml":                       "kind: Service",
+	}
+	packageRevision := &fake.PackageRevision{
+		Name: "foo-1234567890",
+		Resources: &v1alpha1.PackageRevisionResources{
+			Spec: v1alpha1.PackageRevisionResourcesSpec{
+				PackageName:    "foo",
+				Revision:       "v1",
+				RepositoryName: "repo",
+				Resources:      source,
+			},
+		},
+	}
+	epm := editPackageMutation{
+		task: &v1alpha1.Task{
+			Type: "edit",
+			Edit: &v1alpha1.PackageEditTaskSpec{
+				Source: &v1alpha1.PackageRevisionRef{Name: packageRevision.Name},
+			},
+		},
+		namespace:         "test-namespace",
+		referenceResolver: &fakeReferenceResolver{},
+		repoOpener:        &fakeRepositoryOpener{repository: &fake.Repository{PackageRevisions: []repository.PackageRevision{packageRevision}}},
+	}
+
+	want := map[string]string{}
+	for k, v := range source {
+		want[k] = v
+	}
+
+	res, _, err := epm.Apply(context.Background(), repository.PackageResources{})
+	if err != nil {
+		t.Fatalf("task apply failed: %v", err)
+	}
+	if diff := cmp.Diff(want, res.Contents); diff != "" {
+		t.Errorf("copied resources differ from the source (-want +got):\n%s", diff)
+	}
+
+	// Changes to the draft mustn't change the source.
+	res.Contents["apps/config.yaml"] = "changed"
+	delete(res.Contents, "db/empty.txt")
+	if diff := cmp.Diff(want, source); diff != "" {
+		t.Errorf("changing the draft changed the source (-want +got):\n%s", diff)
+	}
+
+	t.Run("invalid path", func(t *testing.T) {
+		source["../escape.yaml"] = "kind: ConfigMap\n"
+		defer delete(source, "../escape.yaml")
+
+		if _, _, err := epm.Apply(context.Background(), repository.PackageResources{}); err == nil {
+			t.Errorf("copying a package with a path outside the package succeeded; want error")
+		}
+	})
+}
+
 // Implementation of the ReferenceResolver interface for testing.
 type fakeReferenceResolver struct{}
 
@@ -15,8 +15,8 @@
 package engine
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -24,28 +24,33 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
 	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	"github.com/GoogleContainerTools/kpt/pkg/fn"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
-	"sigs.k8s.io/kustomize/kyaml/comments"
-	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -57,24 +62,75 @@ type CaDEngine interface {
 
 	UpdatePackageResources(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, old, new *api.PackageRevisionResources) (*PackageRevision, error)
 	ListFunctions(ctx context.Context, repositoryObj *configapi.Repository) ([]*Function, error)
+	ValidateRepositoryConfig(ctx context.Context, repositorySpec *configapi.Repository) error
+	ValidateRepositoryChange(ctx context.Context, oldRepo, newRepo *configapi.Repository) (*RepositoryChangeImpact, error)
 
-	ListPackageRevisions(ctx context.Context, repositorySpec *configapi.Repository, filter repository.ListPackageRevisionFilter) ([]*PackageRevision, error)
+	// ListPackageRevisions lists the package revisions of the repository matching the
+	// filter, and returns the token to list the next page from, if the filter limits the
+	// size of the pages and there are more package revisions.
+	ListPackageRevisions(ctx context.Context, repositorySpec *configapi.Repository, filter repository.ListPackageRevisionFilter) ([]*PackageRevision, string, error)
 	CreatePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, parent *PackageRevision) (*PackageRevision, error)
 	UpdatePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, old, new *api.PackageRevision, parent *PackageRevision) (*PackageRevision, error)
 	DeletePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, obj *PackageRevision) error
+	GetPackageTimeline(ctx context.Context, repositoryObj *configapi.Repository, packageName string) ([]api.TimelineEvent, error)
+	ExportPipeline(ctx context.Context, repositoryObj *configapi.Repository, name string) (*TaskPipeline, error)
+	ListExternalDependencies(ctx context.Context, repositoryObj *configapi.Repository) ([]ExternalDependency, error)
+	CheckPublishReadiness(ctx context.Context, repositoryObj *configapi.Repository, name string) (*PublishReadiness, error)
+	RerenderAllDrafts(ctx context.Context, repositoryObj *configapi.Repository) ([]RerenderResult, error)
+	EnforceRetention(ctx context.Context, repositoryObj *configapi.Repository, namespaceRepositories []configapi.Repository) ([]string, error)
+	SetRevisionMetadataDoc(ctx context.Context, repositoryObj *configapi.Repository, name, key string, obj interface{}) error
+	GetRevisionMetadataDoc(ctx context.Context, repositoryObj *configapi.Repository, name, key string, obj interface{}) (bool, error)
+	GenerateChangeSummary(ctx context.Context, repositoryObj *configapi.Repository, name string) (*ChangeSummary, error)
+	ListOrphanedMetadata(ctx context.Context, repositoryObj *configapi.Repository) ([]string, error)
+	PruneOrphanedMetadata(ctx context.Context, repositoryObj *configapi.Repository) ([]string, error)
+	CheckConsistency(ctx context.Context, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error)
+	RepairConsistency(ctx context.Context, repositoryObj *configapi.Repository) ([]api.ConsistencyFinding, error)
+	ExportRepository(ctx context.Context, repositoryObj *configapi.Repository) (*RepositoryExport, error)
+	ImportRepository(ctx context.Context, repositoryObj *configapi.Repository, export *RepositoryExport, dryRun bool) ([]ImportResult, error)
+	StagedUploader(pkgRev *PackageRevision) (staging.Uploader, error)
+	FinalizeStagedResources(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, manifest staging.Manifest) (*PackageRevision, error)
+	PreviewUpstreamUpdateImpact(ctx context.Context, namespace string, upstreamRef *api.PackageRevisionRef, newRevision string, namespaceRepositories []configapi.Repository) ([]UpdateImpact, error)
+	PlanPackageRevision(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, parent *PackageRevision) (*MutationPlan, error)
+	PlanPackageRevisionUpdate(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, old, new *api.PackageRevision, parent *PackageRevision) (*MutationPlan, error)
+	RenameFile(ctx context.Context, repositoryObj *configapi.Repository, name, from, to string) (*PackageRevision, error)
+	GetFunctionLogs(ctx context.Context, repositoryObj *configapi.Repository, name string) ([]api.TaskLog, error)
+	MovePackage(ctx context.Context, srcRepo, dstRepo *configapi.Repository, packageName string, opts MoveOptions) (*api.PackageMoveStatus, error)
+	ApprovePackageRevisions(ctx context.Context, requests []ApprovalRequest) []api.BulkApprovalResult
+	UpdateDeploymentStatus(ctx context.Context, rev *PackageRevision, status *api.DeploymentStatus) (*PackageRevision, error)
 
 	ListPackages(ctx context.Context, repositorySpec *configapi.Repository, filter repository.ListPackageFilter) ([]*Package, error)
 	CreatePackage(ctx context.Context, repositoryObj *configapi.Repository, obj *api.Package) (*Package, error)
 	UpdatePackage(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *Package, old, new *api.Package) (*Package, error)
 	DeletePackage(ctx context.Context, repositoryObj *configapi.Repository, obj *Package) error
+	UpdatePackageFreeze(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *Package, freeze *api.PackageFreeze) (*Package, error)
 }
 
 type Package struct {
 	repoPackage repository.Package
+	archived    bool
+	freeze      *meta.PackageFreeze
+	metadata    *meta.PackageMetadata
 }
 
 func (p *Package) GetPackage() *api.Package {
-	return p.repoPackage.GetPackage()
+	pkg := p.repoPackage.GetPackage()
+	if p.archived || p.freeze != nil || p.metadata != nil {
+		pkg = pkg.DeepCopy()
+		pkg.Spec.Archived = p.archived
+	}
+	if p.metadata != nil {
+		pkg.Labels = p.metadata.Labels
+		pkg.Annotations = p.metadata.Annotations
+		pkg.Spec.Description = p.metadata.Description
+	}
+	if p.freeze != nil {
+		pkg.Spec.Freeze = &api.PackageFreeze{
+			Reason:   p.freeze.Reason,
+			FrozenBy: p.freeze.FrozenBy,
+			FrozenAt: p.freeze.FrozenAt,
+		}
+	}
+	return pkg
 }
 
 func (p *Package) KubeObjectName() string {
@@ -84,6 +140,10 @@ func (p *Package) KubeObjectName() string {
 type PackageRevision struct {
 	repoPackageRevision repository.PackageRevision
 	packageRevisionMeta meta.PackageRevisionMeta
+
+	// sharedFrom is the namespace of the repository of a package revision listed in
+	// another namespace the repository is shared with.
+	sharedFrom string
 }
 
 func (p *PackageRevision) GetPackageRevision(ctx context.Context) (*api.PackageRevision, error) {
@@ -91,21 +151,46 @@ func (p *PackageRevision) GetPackageRevision(ctx context.Context) (*api.PackageR
 	if err != nil {
 		return nil, err
 	}
-	var isLatest bool
-	if val, found := repoPkgRev.Labels[api.LatestPackageRevisionKey]; found && val == api.LatestPackageRevisionValue {
-		isLatest = true
-	}
-	repoPkgRev.Labels = p.packageRevisionMeta.Labels
-	if isLatest {
-		if repoPkgRev.Labels == nil {
-			repoPkgRev.Labels = make(map[string]string)
-		}
-		repoPkgRev.Labels[api.LatestPackageRevisionKey] = api.LatestPackageRevisionValue
-	}
-	repoPkgRev.Annotations = p.packageRevisionMeta.Annotations
+	repoPkgRev.Labels = mergeLabels(ctx, repoPkgRev.Name, repoPkgRev.Labels, p.packageRevisionMeta.Labels)
+	repoPkgRev.Annotations = mergeAnnotations(ctx, repoPkgRev.Name, repoPkgRev.Annotations, p.packageRevisionMeta.Annotations)
+	repoPkgRev.Status.Artifacts = p.packageRevisionMeta.Artifacts
+	repoPkgRev.Status.Archived = p.packageRevisionMeta.IsArchived()
+	repoPkgRev.Status.DeploymentStatus = p.packageRevisionMeta.DeploymentStatus.DeepCopy()
+	times := p.packageRevisionMeta.LifecycleTimes
+	repoPkgRev.Status.DraftCreatedAt = times.DraftCreatedAt
+	repoPkgRev.Status.ProposedAt = times.ProposedAt
+	repoPkgRev.Status.ProposedBy = times.ProposedBy
+	commitTime := repoPkgRev.CreationTimestamp
+	created, published := authoritativeTimes(p.repoPackageRevision.Lifecycle(), commitTime, times)
+	if !created.IsZero() {
+		repoPkgRev.CreationTimestamp = created
+	}
+	if !published.IsZero() {
+		repoPkgRev.Status.PublishedAt = published
+	}
+	repoPkgRev.Status.Timestamps = &api.PackageRevisionTimestamps{
+		CommitTime:          commitTime,
+		RecordedCreatedAt:   times.DraftCreatedAt,
+		RecordedPublishedAt: times.PublishedAt,
+	}
+	repoPkgRev.Status.SharedFrom = p.sharedFrom
+	repoPkgRev.Status.TaskResults = p.packageRevisionMeta.TaskResults
 	return repoPkgRev, nil
 }
 
+// Shared returns the package revision as listed in another namespace its repository is
+// shared with.
+func (p *PackageRevision) Shared() *PackageRevision {
+	shared := *p
+	shared.sharedFrom = p.repoPackageRevision.KubeObjectNamespace()
+	return &shared
+}
+
+// Lifecycle returns the lifecycle of the package revision.
+func (p *PackageRevision) Lifecycle() api.PackageRevisionLifecycle {
+	return p.repoPackageRevision.Lifecycle()
+}
+
 func (p *PackageRevision) KubeObjectName() string {
 	return p.repoPackageRevision.KubeObjectName()
 }
@@ -127,12 +212,26 @@ func (f *Function) GetFunction() (*api.Function, error) {
 }
 
 func NewCaDEngine(opts ...EngineOption) (CaDEngine, error) {
-	engine := &cadEngine{}
+	starters, err := parseStarterResources(DefaultStarterResources())
+	if err != nil {
+		return nil, err
+	}
+	engine := &cadEngine{
+		sizeBudget:         defaultPackageSizeBudget(),
+		evalConflictPolicy: EvalConflictPolicyError,
+		starterResources:   starters,
+		proposalDiffBytes:  DefaultProposalDiffBytes,
+		functionLogs:       newFunctionLogStore(DefaultFunctionLogTTL),
+		draftBufferBytes:   DefaultDraftBufferBytes,
+	}
 	for _, opt := range opts {
 		if err := opt.apply(engine); err != nil {
 			return nil, err
 		}
 	}
+	if engine.restrictExternalSources && len(engine.allowedFunctionImages) == 0 {
+		return nil, fmt.Errorf("restricting external sources requires the allowed function images, which functions are pulled from")
+	}
 	return engine, nil
 }
 
@@ -142,8 +241,47 @@ type cadEngine struct {
 	runtime            fn.FunctionRuntime
 	credentialResolver repository.CredentialResolver
 	referenceResolver  ReferenceResolver
+	repositoryLister   RepositoryLister
 	userInfoProvider   repository.UserInfoProvider
 	metadataStore      meta.MetadataStore
+	sizeBudget         PackageSizeBudget
+
+	recordRenderChanges      bool
+	renderConcurrency        int
+	deferRender              bool
+	forceRender              bool
+	strictTasks              bool
+	strictTaskValidation     bool
+	stampDeploymentNamespace bool
+	upstreamFallback         bool
+	evalConflictPolicy       EvalConflictPolicy
+	allowedFunctionImages    AllowedFunctionImages
+	restrictExternalSources  bool
+	functionSecretNamespaces []string
+	taskHandlers             map[api.TaskType]MutationFactory
+
+	workspaceNameTemplate *template.Template
+	descriptionTemplate   *template.Template
+	starterResources      map[string]*template.Template
+	upstreamVerifier      UpstreamVerifier
+	publishHooks          []PublishHook
+	proposeHooks          []ProposeHook
+	proposalDiffBytes     int
+	phaseTimeouts         PhaseTimeouts
+	stagingStore          *staging.Store
+	autoProposer          *autoProposer
+	functionLogs          *functionLogStore
+	// draftBufferBytes is the package size up to which task contents are buffered
+	// until the draft is closed; zero writes them to the repository right away.
+	draftBufferBytes int64
+	// syncWaitTimeout is how long reads wait for the initial sync of a repository. Reads
+	// of a repository which isn't synced fail right away if it is zero.
+	syncWaitTimeout time.Duration
+
+	// renderToolchain is the version of the current render toolchain, recorded on new
+	// package revisions; toolchainRuntimes are the function runtimes of other versions.
+	renderToolchain   string
+	toolchainRuntimes map[string]fn.FunctionRuntime
 }
 
 var _ CaDEngine = &cadEngine{}
@@ -152,6 +290,33 @@ type mutation interface {
 	Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error)
 }
 
+// validatingMutation is a mutation which can check, before any of the tasks of the
+// package revision is applied, that it can be applied; for example that the upstream
+// package it references exists.
+type validatingMutation interface {
+	mutation
+	validate(ctx context.Context) error
+	// sourceTask returns the task the mutation applies.
+	sourceTask() *api.Task
+}
+
+// validateMutations validates the mutations of the tasks of obj, so that an invalid
+// task is found before any of the tasks is applied and leaves no partly applied
+// package revision behind.
+func validateMutations(ctx context.Context, obj *api.PackageRevision, mutations []mutation) error {
+	for _, m := range mutations {
+		v, ok := m.(validatingMutation)
+		if !ok {
+			continue
+		}
+		if err := v.validate(ctx); err != nil {
+			task := v.sourceTask()
+			return invalidTask(obj, task, err)
+		}
+	}
+	return nil
+}
+
 // ObjectCache is a cache of all our objects.
 func (cad *cadEngine) ObjectCache() cache.ObjectCache {
 	return cad.cache.ObjectCache()
@@ -164,39 +329,18 @@ func (cad *cadEngine) OpenRepository(ctx context.Context, repositorySpec *config
 	return cad.cache.OpenRepository(ctx, repositorySpec)
 }
 
-func (cad *cadEngine) ListPackageRevisions(ctx context.Context, repositorySpec *configapi.Repository, filter repository.ListPackageRevisionFilter) ([]*PackageRevision, error) {
+func (cad *cadEngine) ListPackageRevisions(ctx context.Context, repositorySpec *configapi.Repository, filter repository.ListPackageRevisionFilter) ([]*PackageRevision, string, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::ListPackageRevisions", trace.WithAttributes())
 	defer span.End()
 
 	repo, err := cad.cache.OpenRepository(ctx, repositorySpec)
 	if err != nil {
-		return nil, err
-	}
-	pkgRevs, err := repo.ListPackageRevisions(ctx, filter)
-	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	var packageRevisions []*PackageRevision
-	for _, pr := range pkgRevs {
-		pkgRevMeta, err := cad.metadataStore.Get(ctx, types.NamespacedName{
-			Name:      pr.KubeObjectName(),
-			Namespace: pr.KubeObjectNamespace(),
-		})
-		if err != nil {
-			// If a PackageRev CR doesn't exist, we treat the
-			// Packagerevision as not existing.
-			if apierrors.IsNotFound(err) {
-				continue
-			}
-			return nil, err
-		}
-		packageRevisions = append(packageRevisions, &PackageRevision{
-			repoPackageRevision: pr,
-			packageRevisionMeta: pkgRevMeta,
-		})
+	if err := cad.checkRepositorySynced(ctx, repositorySpec, repo); err != nil {
+		return nil, "", err
 	}
-	return packageRevisions, nil
+	return cad.listPackageRevisions(ctx, repositorySpec, repo, filter)
 }
 
 func buildPackageConfig(ctx context.Context, obj *api.PackageRevision, parent *PackageRevision) (*builtins.PackageConfig, error) {
@@ -246,6 +390,7 @@ func buildPackageConfig(ctx context.Context, obj *api.PackageRevision, parent *P
 func (cad *cadEngine) CreatePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, parent *PackageRevision) (*PackageRevision, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::CreatePackageRevision", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: obj.Spec.PackageName, Revision: obj.Spec.Revision})
 
 	packageConfig, err := buildPackageConfig(ctx, obj, parent)
 	if err != nil {
@@ -260,43 +405,117 @@ func (cad *cadEngine) CreatePackageRevision(ctx context.Context, repositoryObj *
 	case api.PackageRevisionLifecycleDraft, api.PackageRevisionLifecycleProposed:
 		// These values are ok
 	case api.PackageRevisionLifecyclePublished:
-		// TODO: generate errors that can be translated to correct HTTP responses
-		return nil, fmt.Errorf("cannot create a package revision with lifecycle value 'Final'")
+		return nil, invalidPackageRevision(obj, field.Forbidden(field.NewPath("spec", "lifecycle"),
+			"package revisions cannot be created Published; create a Draft or Proposed package revision and approve it"))
 	default:
-		return nil, fmt.Errorf("unsupported lifecycle value: %s", obj.Spec.Lifecycle)
+		return nil, unsupportedLifecycle(obj)
+	}
+
+	if err := cad.validateTaskFields(obj); err != nil {
+		return nil, err
+	}
+	if err := validateWorkspaceMetadata(obj); err != nil {
+		return nil, err
+	}
+	if err := validatePipelineAppend(obj); err != nil {
+		return nil, err
+	}
+	if err := cad.checkExternalSources(ctx, obj.Namespace, obj.Spec.Tasks, obj.Annotations); err != nil {
+		return nil, err
+	}
+	if err := cad.validateInitStarters(obj); err != nil {
+		return nil, err
+	}
+	cad.recordToolchain(obj)
+
+	if err := cad.checkPackageNotArchived(ctx, repositoryObj, obj.Spec.PackageName); err != nil {
+		return nil, err
+	}
+	if err := cad.checkPackageNotFrozen(ctx, repositoryObj, obj.Spec.PackageName); err != nil {
+		return nil, err
+	}
+
+	if err := cad.generateWorkspaceName(ctx, obj); err != nil {
+		return nil, err
+	}
+	if err := validateNewRevision(obj); err != nil {
+		return nil, err
 	}
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
 	if err != nil {
 		return nil, err
 	}
-	draft, err := repo.CreatePackageRevision(ctx, obj)
+	if err := checkPackagePath(ctx, repo, repositoryObj.Name, obj.Spec.PackageName); err != nil {
+		return nil, err
+	}
+	ctx, logs := withFunctionLogs(ctx)
+	created, err := cad.createPackageRevision(ctx, repo, repositoryObj, obj, packageConfig)
+	recordCancellation(ctx, err)
 	if err != nil {
 		return nil, err
 	}
+	if isDryRun(ctx) {
+		return created, nil
+	}
+	cad.functionLogs.put(repositoryObj.Namespace, created.KubeObjectName(), logs)
+	if obj.Spec.Lifecycle == api.PackageRevisionLifecycleProposed {
+		cad.runProposeHooks(ctx, repositoryObj, created.repoPackageRevision)
+	}
+	return created, nil
+}
+
+// createPackageRevision creates the package revision in repo. Failures are returned as
+// a TaskError. Nothing is stored in the repository until the draft is closed, so a
+// failed draft is dropped as is; if the package revision is stored but its metadata
+// can't be created, the package revision is deleted again.
+func (cad *cadEngine) createPackageRevision(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, obj *api.PackageRevision, packageConfig *builtins.PackageConfig) (*PackageRevision, error) {
+	ctx = withTaskResults(ctx)
+	if isDryRun(ctx) {
+		return cad.createDryRun(ctx, repositoryObj, obj, packageConfig)
+	}
+	draft, err := repo.CreatePackageRevision(ctx, obj)
+	if err != nil {
+		return nil, systemError(err)
+	}
+	draft = cad.bufferDraft(draft)
 
 	if err := cad.applyTasks(ctx, draft, repositoryObj, obj, packageConfig); err != nil {
 		return nil, err
 	}
 
 	if err := draft.UpdateLifecycle(ctx, obj.Spec.Lifecycle); err != nil {
-		return nil, err
+		return nil, systemError(err)
 	}
 
 	// Updates are done.
-	repoPkgRev, err := draft.Close(ctx)
+	repoPkgRev, err := cad.closeDraft(ctx, draft)
 	if err != nil {
-		return nil, err
+		return nil, systemError(err)
+	}
+	if err := checkCancelled(ctx); err != nil {
+		// The client won't see the package revision, so it isn't left behind.
+		cad.deleteCreatedPackageRevision(ctx, repo, repoPkgRev)
+		return nil, systemError(err)
 	}
+	labels, annotations := withDefaultMetadata(ctx, repositoryObj, obj.Labels, obj.Annotations)
 	pkgRevMeta := meta.PackageRevisionMeta{
-		Name:        repoPkgRev.KubeObjectName(),
-		Namespace:   repoPkgRev.KubeObjectNamespace(),
-		Labels:      obj.Labels,
-		Annotations: obj.Annotations,
+		Name:           repoPkgRev.KubeObjectName(),
+		Namespace:      repoPkgRev.KubeObjectNamespace(),
+		Labels:         userLabels(labels),
+		Annotations:    annotations,
+		LifecycleTimes: creationLifecycleTimes(obj.Spec.Lifecycle, metav1.Now().Rfc3339Copy(), cad.requestUser(ctx)),
+		TaskResults:    recordedTaskResults(ctx),
 	}
 	pkgRevMeta, err = cad.metadataStore.Create(ctx, pkgRevMeta, repositoryObj)
 	if err != nil {
-		return nil, err
+		// A package revision without metadata isn't listed, and would block the
+		// retry of the request.
+		cad.deleteCreatedPackageRevision(ctx, repo, repoPkgRev)
+		if ctx.Err() != nil {
+			return nil, systemError(&CancelledError{Err: ctx.Err()})
+		}
+		return nil, systemError(err)
 	}
 	return &PackageRevision{
 		repoPackageRevision: repoPkgRev,
@@ -304,41 +523,65 @@ func (cad *cadEngine) CreatePackageRevision(ctx context.Context, repositoryObj *
 	}, nil
 }
 
+// deleteCreatedPackageRevision deletes a package revision whose creation failed after
+// it was stored, even if the request was cancelled.
+func (cad *cadEngine) deleteCreatedPackageRevision(ctx context.Context, repo repository.Repository, repoPkgRev repository.PackageRevision) {
+	if err := repo.DeletePackageRevision(withoutCancel(ctx), repoPkgRev); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to delete package revision after failing to create it", "name", repoPkgRev.KubeObjectName())
+	}
+}
+
 func (cad *cadEngine) applyTasks(ctx context.Context, draft repository.PackageDraft, repositoryObj *configapi.Repository, obj *api.PackageRevision, packageConfig *builtins.PackageConfig) error {
+	mutations, err := cad.creationMutations(ctx, repositoryObj, obj, packageConfig)
+	if err != nil {
+		return userError(err)
+	}
+	if err := validateMutations(ctx, obj, mutations); err != nil {
+		return userError(err)
+	}
+
+	baseResources := repository.PackageResources{}
+	if err := cad.applyResourceMutations(ctx, draft, baseResources, mutations); err != nil {
+		return mutationError(err)
+	}
+
+	return nil
+}
+
+// creationMutations returns the mutations creating the package revision obj from its
+// tasks, in the order they are applied. All the tasks are mapped before any is applied,
+// so that an invalid task leaves no partly created package revision.
+func (cad *cadEngine) creationMutations(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, packageConfig *builtins.PackageConfig) ([]mutation, error) {
 	var mutations []mutation
 
 	// Unless first task is Init or Clone, insert Init to create an empty package.
-	tasks := obj.Spec.Tasks
-	if len(tasks) == 0 || (tasks[0].Type != api.TaskTypeInit && tasks[0].Type != api.TaskTypeClone) {
-		mutations = append(mutations, &initPackageMutation{
-			name: obj.Spec.PackageName,
-			task: &api.Task{
-				Init: &api.PackageInitTaskSpec{
-					Subpackage:  "",
-					Description: fmt.Sprintf("%s description", obj.Spec.PackageName),
-				},
-			},
-		})
+	if err := cad.ensureCreationTask(repositoryObj, obj); err != nil {
+		return nil, err
 	}
 
+	tasks := obj.Spec.Tasks
+	conflicts := newEvalFieldTracker(cad.evalConflictPolicy)
 	for i := range tasks {
 		task := &tasks[i]
 		mutation, err := cad.mapTaskToMutation(ctx, obj, task, repositoryObj.Spec.Deployment, packageConfig)
 		if err != nil {
-			return err
+			return nil, invalidTask(obj, task, err)
+		}
+		if eval, ok := mutation.(*evalFunctionMutation); ok {
+			eval.conflicts = conflicts
+		}
+		switch m := mutation.(type) {
+		case *clonePackageMutation:
+			m.targetNamespace, m.stampNamespace = cad.deploymentNamespace(repositoryObj)
+			m.allowedUpstreams = repositoryObj.Spec.AllowedUpstreams
+		case *updatePackageMutation:
+			m.allowedUpstreams = repositoryObj.Spec.AllowedUpstreams
 		}
 		mutations = append(mutations, mutation)
 	}
 
 	// Render package after creation.
-	mutations = cad.conditionalAddRender(mutations)
-
-	baseResources := repository.PackageResources{}
-	if err := applyResourceMutations(ctx, draft, baseResources, mutations); err != nil {
-		return err
-	}
-
-	return nil
+	return cad.conditionalAddRender(mutations, obj.Annotations), nil
 }
 
 type RepositoryOpener interface {
@@ -349,15 +592,16 @@ func (cad *cadEngine) mapTaskToMutation(ctx context.Context, obj *api.PackageRev
 	switch task.Type {
 	case api.TaskTypeInit:
 		if task.Init == nil {
-			return nil, fmt.Errorf("init not set for task of type %q", task.Type)
+			return nil, missingTaskSpec(obj, task, "init")
 		}
 		return &initPackageMutation{
-			name: obj.Spec.PackageName,
-			task: task,
+			name:     obj.Spec.PackageName,
+			task:     task,
+			starters: cad.starterResources,
 		}, nil
 	case api.TaskTypeClone:
 		if task.Clone == nil {
-			return nil, fmt.Errorf("clone not set for task of type %q", task.Type)
+			return nil, missingTaskSpec(obj, task, "clone")
 		}
 		return &clonePackageMutation{
 			task:               task,
@@ -368,70 +612,124 @@ func (cad *cadEngine) mapTaskToMutation(ctx context.Context, obj *api.PackageRev
 			credentialResolver: cad.credentialResolver,
 			referenceResolver:  cad.referenceResolver,
 			packageConfig:      packageConfig,
+			sizeBudget:         &cad.sizeBudget,
+			upstreamVerifier:   cad.upstreamVerifier,
+			externalSources:    cad.externalSourcePolicy(obj.Namespace),
 		}, nil
 
 	case api.TaskTypeUpdate:
 		if task.Update == nil {
-			return nil, fmt.Errorf("update not set for task of type %q", task.Type)
+			return nil, missingTaskSpec(obj, task, "update")
 		}
 		cloneTask := findCloneTask(obj)
 		if cloneTask == nil {
 			return nil, fmt.Errorf("upstream source not found for package rev %q; only cloned packages can be updated", obj.Spec.PackageName)
 		}
 		return &updatePackageMutation{
-			cloneTask:         cloneTask,
-			updateTask:        task,
-			namespace:         obj.Namespace,
-			repoOpener:        cad,
-			referenceResolver: cad.referenceResolver,
-			pkgName:           obj.Spec.PackageName,
+			cloneTask:          cloneTask,
+			updateTask:         task,
+			namespace:          obj.Namespace,
+			repoOpener:         cad,
+			credentialResolver: cad.credentialResolver,
+			referenceResolver:  cad.referenceResolver,
+			ociOpener:          registryPackageOpener{},
+			pkgName:            obj.Spec.PackageName,
+			sizeBudget:         &cad.sizeBudget,
+			upstreamFallback:   cad.upstreamFallback,
+			previousUpstream:   upstreamBefore(obj, task),
+			externalSources:    cad.externalSourcePolicy(obj.Namespace),
 		}, nil
 
 	case api.TaskTypePatch:
+		if task.Patch == nil {
+			return nil, missingTaskSpec(obj, task, "patch")
+		}
 		return buildPatchMutation(ctx, task)
 
 	case api.TaskTypeEdit:
 		if task.Edit == nil {
-			return nil, fmt.Errorf("edit not set for task of type %q", task.Type)
+			return nil, missingTaskSpec(obj, task, "edit")
 		}
 		return &editPackageMutation{
 			task:              task,
 			namespace:         obj.Namespace,
 			repoOpener:        cad,
 			referenceResolver: cad.referenceResolver,
+			sizeBudget:        &cad.sizeBudget,
 		}, nil
 
+	case api.TaskTypeRender:
+		render := cad.renderMutation(obj.Annotations)
+		if task.Render != nil {
+			render.recordChanges = render.recordChanges || task.Render.RecordChanges
+			render.recordTaskChanges = task.Render.RecordChanges
+		}
+		return render, nil
+
 	case api.TaskTypeEval:
 		if task.Eval == nil {
-			return nil, fmt.Errorf("eval not set for task of type %q", task.Type)
-		}
-		// TODO: We should find a different way to do this. Probably a separate
-		// task for render.
-		if task.Eval.Image == "render" {
-			return &renderPackageMutation{
-				renderer: cad.renderer,
-				runtime:  cad.runtime,
-			}, nil
-		} else {
-			return &evalFunctionMutation{
-				runtime: cad.runtime,
-				task:    task,
-			}, nil
+			return nil, missingTaskSpec(obj, task, "eval")
 		}
+		// Eval tasks of the render image render the package, as before there was a
+		// render task type; the render is recorded as a render task.
+		if isLegacyRenderTask(task) {
+			return cad.renderMutation(obj.Annotations), nil
+		}
+		return &evalFunctionMutation{
+			runtime:       cad.runtime,
+			task:          task,
+			allowedImages: cad.allowedFunctionImages,
+			secrets:       cad.configSecretResolver(obj.Namespace),
+		}, nil
 
 	default:
-		return nil, fmt.Errorf("task of type %q not supported", task.Type)
+		return cad.mapCustomTaskToMutation(ctx, obj, task)
 	}
 }
 
 func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, oldObj, newObj *api.PackageRevision, parent *PackageRevision) (*PackageRevision, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::UpdatePackageRevision", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, oldPackage.repoPackageRevision.Key())
+
+	if isFreezingTransition(oldObj, newObj) {
+		if err := cad.checkPackageNotFrozen(ctx, repositoryObj, oldPackage.repoPackageRevision.Key().Package); err != nil {
+			return nil, err
+		}
+	}
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
 	if err != nil {
 		return nil, err
 	}
+	// The logs of failed updates are kept too, as they are most needed then.
+	ctx, logs := withFunctionLogs(ctx)
+	updated, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, parent)
+	cad.functionLogs.put(repositoryObj.Namespace, oldPackage.KubeObjectName(), logs)
+	recordCancellation(ctx, err)
+	if err != nil {
+		return nil, err
+	}
+	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecycleDraft && newObj.Spec.Lifecycle == api.PackageRevisionLifecycleProposed {
+		cad.runProposeHooks(ctx, repositoryObj, updated.repoPackageRevision)
+	}
+	cad.observeAutoPropose(ctx, repositoryObj, updated)
+	return updated, nil
+}
+
+func (cad *cadEngine) updatePackageRevision(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, oldPackage *PackageRevision, oldObj, newObj *api.PackageRevision, parent *PackageRevision) (*PackageRevision, error) {
+	// The steps of the update share one read of the resources of the old package revision.
+	snapshot := newResourceSnapshot(oldPackage.repoPackageRevision)
+
+	if err := normalizeRevisionUpdate(oldObj, newObj); err != nil {
+		return nil, err
+	}
+	if err := cad.validateTaskFields(newObj); err != nil {
+		return nil, err
+	}
+	if err := validateConditions(newObj); err != nil {
+		return nil, err
+	}
 
 	// Validate package lifecycle. Can only update a draft.
 	switch lifecycle := oldObj.Spec.Lifecycle; lifecycle {
@@ -441,29 +739,38 @@ func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *
 		// Draft or proposed can be updated.
 	case api.PackageRevisionLifecyclePublished:
 		// Only metadata (currently labels and annotations) can be updated for published packages.
-		repoPkgRev := oldPackage.repoPackageRevision
-
-		pkgRevMeta := meta.PackageRevisionMeta{
-			Name:        repoPkgRev.KubeObjectName(),
-			Namespace:   repoPkgRev.KubeObjectNamespace(),
-			Labels:      newObj.Labels,
-			Annotations: newObj.Annotations,
+		if err := checkWorkspaceMetadataUnchanged(oldObj, newObj); err != nil {
+			return nil, err
 		}
-		cad.metadataStore.Update(ctx, pkgRevMeta)
-
-		return &PackageRevision{
-			repoPackageRevision: repoPkgRev,
-			packageRevisionMeta: pkgRevMeta,
-		}, nil
+		if lifecycle := newObj.Spec.Lifecycle; lifecycle != "" && lifecycle != api.PackageRevisionLifecyclePublished {
+			return nil, apierrors.NewConflict(api.PackageRevisionGVR.GroupResource(), oldObj.Name,
+				fmt.Errorf("package revision is already Published; its lifecycle cannot be changed to %q", lifecycle))
+		}
+		return cad.updateMetadata(ctx, oldPackage, newObj)
 	}
 	switch lifecycle := newObj.Spec.Lifecycle; lifecycle {
 	default:
-		return nil, fmt.Errorf("invalid desired lifecycle value: %q", lifecycle)
+		return nil, unsupportedLifecycle(newObj)
 	case api.PackageRevisionLifecycleDraft, api.PackageRevisionLifecycleProposed, api.PackageRevisionLifecyclePublished:
 		// These values are ok
 	}
+	ctx = withTaskResults(ctx)
+	if err := validateWorkspaceMetadata(newObj); err != nil {
+		return nil, err
+	}
+	if err := validatePipelineAppend(newObj); err != nil {
+		return nil, err
+	}
 
-	if isRecloneAndReplay(oldObj, newObj) {
+	preserveUpstreamResolution(oldObj, newObj)
+	if err := validateTaskReplay(oldObj, newObj); err != nil {
+		return nil, err
+	}
+	// Removed or changed tasks are dropped by replaying the new tasks, like a reclone.
+	if isRecloneAndReplay(oldObj, newObj) || isTaskReplay(oldObj, newObj) {
+		if err := cad.checkExternalSources(ctx, newObj.Namespace, newObj.Spec.Tasks, newObj.Annotations); err != nil {
+			return nil, err
+		}
 		packageConfig, err := buildPackageConfig(ctx, newObj, parent)
 		if err != nil {
 			return nil, err
@@ -472,88 +779,59 @@ func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *
 		if err != nil {
 			return nil, err
 		}
+		// The replayed package revision keeps its metadata, including the workspace
+		// description and reference, and gets the default metadata of the repository.
+		labels, annotations := withDefaultMetadata(ctx, repositoryObj, newObj.Labels, newObj.Annotations)
+		pkgRevMeta := meta.PackageRevisionMeta{
+			Name:        repoPkgRev.KubeObjectName(),
+			Namespace:   repoPkgRev.KubeObjectNamespace(),
+			Labels:      userLabels(labels),
+			Annotations: annotations,
+			LifecycleTimes: lifecycleTransition(ctx, repositoryObj, oldObj.Spec.Lifecycle, newObj.Spec.Lifecycle,
+				oldPackage.packageRevisionMeta.LifecycleTimes, metav1.Now().Rfc3339Copy(), cad.requestUser(ctx)),
+			TaskResults: recordedTaskResults(ctx),
+		}
+		pkgRevMeta, err = cad.metadataStore.Update(ctx, pkgRevMeta)
+		if err != nil {
+			return nil, err
+		}
 		return &PackageRevision{
 			repoPackageRevision: repoPkgRev,
+			packageRevisionMeta: pkgRevMeta,
 		}, nil
 	}
 
-	var mutations []mutation
-	if len(oldObj.Spec.Tasks) > len(newObj.Spec.Tasks) {
-		return nil, fmt.Errorf("removing tasks is not yet supported")
-	}
-	for i := range oldObj.Spec.Tasks {
-		oldTask := &oldObj.Spec.Tasks[i]
-		newTask := &newObj.Spec.Tasks[i]
-		if oldTask.Type != newTask.Type {
-			return nil, fmt.Errorf("changing task types is not yet supported")
-		}
+	// Proposing a proposed package revision again changes only its metadata; the
+	// proposal isn't pushed again.
+	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecycleProposed && newObj.Spec.Lifecycle == api.PackageRevisionLifecycleProposed {
+		return cad.updateMetadata(ctx, oldPackage, newObj)
 	}
-	if len(newObj.Spec.Tasks) > len(oldObj.Spec.Tasks) {
-		if len(newObj.Spec.Tasks) > len(oldObj.Spec.Tasks)+1 {
-			return nil, fmt.Errorf("can only append one task at a time")
-		}
-
-		newTask := newObj.Spec.Tasks[len(newObj.Spec.Tasks)-1]
-		if newTask.Type != api.TaskTypeUpdate {
-			return nil, fmt.Errorf("appended task is type %q, must be type %q", newTask.Type, api.TaskTypeUpdate)
-		}
-		if newTask.Update == nil {
-			return nil, fmt.Errorf("update not set for updateTask of type %q", newTask.Type)
-		}
 
-		cloneTask := findCloneTask(oldObj)
-		if cloneTask == nil {
-			return nil, fmt.Errorf("upstream source not found for package rev %q; only cloned packages can be updated", oldObj.Spec.PackageName)
-		}
-
-		mutation := &updatePackageMutation{
-			cloneTask:         cloneTask,
-			updateTask:        &newTask,
-			repoOpener:        cad,
-			referenceResolver: cad.referenceResolver,
-			namespace:         repositoryObj.Namespace,
-			pkgName:           oldObj.GetName(),
-		}
-		mutations = append(mutations, mutation)
+	if err := cad.checkExternalSources(ctx, newObj.Namespace, newObj.Spec.Tasks[len(oldObj.Spec.Tasks):], newObj.Annotations); err != nil {
+		return nil, err
 	}
-
-	// Re-render if we are making changes.
-	mutations = cad.conditionalAddRender(mutations)
-
-	draft, err := repo.UpdatePackageRevision(ctx, oldPackage.repoPackageRevision)
+	mutations, err := cad.updateMutations(ctx, snapshot, repositoryObj, oldObj, newObj)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateMutations(ctx, newObj, mutations); err != nil {
+		return nil, err
+	}
 
-	// If any of the fields in the API that are projections from the Kptfile
-	// must be updated in the Kptfile as well.
-	kfPatchTask, created, err := createKptfilePatchTask(ctx, oldPackage.repoPackageRevision, newObj)
+	draft, err := repo.UpdatePackageRevision(ctx, oldPackage.repoPackageRevision)
 	if err != nil {
 		return nil, err
 	}
-	if created {
-		kfPatchMutation, err := buildPatchMutation(ctx, kfPatchTask)
-		if err != nil {
-			return nil, err
-		}
-		mutations = append(mutations, kfPatchMutation)
-	}
-
-	// Re-render if we are making changes.
-	mutations = cad.conditionalAddRender(mutations)
+	draft = cad.bufferDraft(draft)
 
 	// TODO: Handle the case if alongside lifecycle change, tasks are changed too.
 	// Update package contents only if the package is in draft state
 	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecycleDraft {
-		apiResources, err := oldPackage.GetResources(ctx)
+		resources, err := snapshot.Resources(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get package resources: %w", err)
-		}
-		resources := repository.PackageResources{
-			Contents: apiResources.Spec.Resources,
+			return nil, err
 		}
-
-		if err := applyResourceMutations(ctx, draft, resources, mutations); err != nil {
+		if err := cad.applyResourceMutations(ctx, draft, resources, mutations); err != nil {
 			return nil, err
 		}
 	}
@@ -561,9 +839,12 @@ func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *
 	if err := draft.UpdateLifecycle(ctx, newObj.Spec.Lifecycle); err != nil {
 		return nil, err
 	}
+	if newObj.Spec.Lifecycle == api.PackageRevisionLifecyclePublished {
+		ctx = repository.WithCommitTrailers(ctx, commitTrailers(repositoryObj, newObj.Labels, newObj.Annotations))
+	}
 
 	// Updates are done.
-	repoPkgRev, err := draft.Close(ctx)
+	repoPkgRev, err := cad.closeDraft(ctx, draft)
 	if err != nil {
 		return nil, err
 	}
@@ -571,10 +852,24 @@ func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *
 	pkgRevMeta := meta.PackageRevisionMeta{
 		Name:        repoPkgRev.KubeObjectName(),
 		Namespace:   repoPkgRev.KubeObjectNamespace(),
-		Labels:      newObj.Labels,
+		Labels:      userLabels(newObj.Labels),
 		Annotations: newObj.Annotations,
+		LifecycleTimes: lifecycleTransition(ctx, repositoryObj, oldObj.Spec.Lifecycle, newObj.Spec.Lifecycle,
+			oldPackage.packageRevisionMeta.LifecycleTimes, metav1.Now().Rfc3339Copy(), cad.requestUser(ctx)),
+		TaskResults: recordedTaskResults(ctx),
+	}
+	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecycleDraft {
+		// The conditions are only updated along with the resources of drafts.
+		pkgRevMeta.ConditionChanges = conditionChanges(oldPackage.packageRevisionMeta.ConditionChanges, oldObj, newObj,
+			cad.requestUser(ctx), metav1.Now().Rfc3339Copy())
+	}
+	if newObj.Spec.Lifecycle == api.PackageRevisionLifecyclePublished {
+		pkgRevMeta.Artifacts = cad.runPublishHooks(ctx, repositoryObj, repoPkgRev)
+	}
+	pkgRevMeta, err = cad.metadataStore.Update(ctx, pkgRevMeta)
+	if err != nil {
+		return nil, err
 	}
-	cad.metadataStore.Update(ctx, pkgRevMeta)
 
 	return &PackageRevision{
 		repoPackageRevision: repoPkgRev,
@@ -582,20 +877,34 @@ func (cad *cadEngine) UpdatePackageRevision(ctx context.Context, repositoryObj *
 	}, nil
 }
 
-func createKptfilePatchTask(ctx context.Context, oldPackage repository.PackageRevision, newObj *api.PackageRevision) (*api.Task, bool, error) {
-	kf, err := oldPackage.GetKptfile(ctx)
+// updateMetadata updates only the labels and annotations of oldPackage to the ones of
+// newObj, leaving its contents and lifecycle in the repository unchanged.
+func (cad *cadEngine) updateMetadata(ctx context.Context, oldPackage *PackageRevision, newObj *api.PackageRevision) (*PackageRevision, error) {
+	repoPkgRev := oldPackage.repoPackageRevision
+
+	pkgRevMeta := meta.PackageRevisionMeta{
+		Name:        repoPkgRev.KubeObjectName(),
+		Namespace:   repoPkgRev.KubeObjectNamespace(),
+		Labels:      userLabels(newObj.Labels),
+		Annotations: newObj.Annotations,
+	}
+	pkgRevMeta, err := cad.metadataStore.Update(ctx, pkgRevMeta)
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 
-	var orgKfString string
-	{
-		var buf bytes.Buffer
-		d := yaml.NewEncoder(&buf)
-		if err := d.Encode(kf); err != nil {
-			return nil, false, err
-		}
-		orgKfString = buf.String()
+	return &PackageRevision{
+		repoPackageRevision: repoPkgRev,
+		packageRevisionMeta: pkgRevMeta,
+	}, nil
+}
+
+// createKptfilePatchTask returns a patch task updating the projections of the API fields
+// in kf, the Kptfile of the old package revision, and whether a patch is needed.
+func createKptfilePatchTask(kf kptfile.KptFile, newObj *api.PackageRevision) (*api.Task, bool, error) {
+	orgKfString, err := pkgcontents.EncodeKptfile(kf)
+	if err != nil {
+		return nil, false, err
 	}
 
 	var readinessGates []kptfile.ReadinessGate
@@ -605,14 +914,9 @@ func createKptfilePatchTask(ctx context.Context, oldPackage repository.PackageRe
 		})
 	}
 
-	var conditions []kptfile.Condition
-	for _, c := range newObj.Status.Conditions {
-		conditions = append(conditions, kptfile.Condition{
-			Type:    c.Type,
-			Status:  convertStatusToKptfile(c.Status),
-			Reason:  c.Reason,
-			Message: c.Message,
-		})
+	conditions, err := convertConditionsToKptfile(newObj.Status.Conditions)
+	if err != nil {
+		return nil, false, err
 	}
 
 	if kf.Info == nil && len(readinessGates) > 0 {
@@ -629,14 +933,9 @@ func createKptfilePatchTask(ctx context.Context, oldPackage repository.PackageRe
 		kf.Status.Conditions = conditions
 	}
 
-	var newKfString string
-	{
-		var buf bytes.Buffer
-		d := yaml.NewEncoder(&buf)
-		if err := d.Encode(kf); err != nil {
-			return nil, false, err
-		}
-		newKfString = buf.String()
+	newKfString, err := pkgcontents.EncodeKptfile(kf)
+	if err != nil {
+		return nil, false, err
 	}
 
 	patchSpec, err := GeneratePatch(kptfile.KptFileName, orgKfString, newKfString)
@@ -658,22 +957,109 @@ func createKptfilePatchTask(ctx context.Context, oldPackage repository.PackageRe
 	}, true, nil
 }
 
-func convertStatusToKptfile(s api.ConditionStatus) kptfile.ConditionStatus {
+func convertConditionsToKptfile(apiConditions []api.Condition) ([]kptfile.Condition, error) {
+	var conditions []kptfile.Condition
+	for _, c := range apiConditions {
+		status, err := convertStatusToKptfile(c.Status)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", c.Type, err)
+		}
+		conditions = append(conditions, kptfile.Condition{
+			Type:    c.Type,
+			Status:  status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		})
+	}
+	return conditions, nil
+}
+
+// convertStatusToKptfile returns the Kptfile condition status of s. Condition statuses
+// are validated before they are converted; the error guards against statuses which
+// weren't.
+func convertStatusToKptfile(s api.ConditionStatus) (kptfile.ConditionStatus, error) {
 	switch s {
 	case api.ConditionTrue:
-		return kptfile.ConditionTrue
+		return kptfile.ConditionTrue, nil
 	case api.ConditionFalse:
-		return kptfile.ConditionFalse
+		return kptfile.ConditionFalse, nil
 	case api.ConditionUnknown:
-		return kptfile.ConditionUnknown
+		return kptfile.ConditionUnknown, nil
 	default:
-		panic(fmt.Errorf("unknown condition status: %v", s))
+		return "", fmt.Errorf("unknown condition status: %q", s)
 	}
 }
 
+// updateMutations returns the mutations updating the package revision oldObj to newObj,
+// in the order they are applied. The mutations are only applied to drafts.
+func (cad *cadEngine) updateMutations(ctx context.Context, snapshot *resourceSnapshot, repositoryObj *configapi.Repository, oldObj, newObj *api.PackageRevision) ([]mutation, error) {
+	var mutations []mutation
+	if isTaskReplay(oldObj, newObj) {
+		// Removed or changed tasks can't be undone by mutations; the new tasks are replayed instead.
+		return nil, fmt.Errorf("removed or changed tasks must be replayed rather than applied as mutations")
+	}
+	// Appended tasks are applied in order. The draft is only written when it is closed,
+	// so a failing task leaves none of the tasks before it in the package revision.
+	conflicts := newEvalFieldTracker(cad.evalConflictPolicy)
+	for i := len(oldObj.Spec.Tasks); i < len(newObj.Spec.Tasks); i++ {
+		task := &newObj.Spec.Tasks[i]
+		switch task.Type {
+		case api.TaskTypeInit, api.TaskTypeClone:
+			return nil, invalidPackageRevision(newObj, field.Forbidden(taskPath(newObj, task).Child("type"),
+				fmt.Sprintf("appended task %d is type %q; only the first task can create the package", i, task.Type)))
+		}
+		mutation, err := cad.mapTaskToMutation(ctx, newObj, task, repositoryObj.Spec.Deployment, nil)
+		if err != nil {
+			return nil, invalidTask(newObj, task, err)
+		}
+		switch m := mutation.(type) {
+		case *evalFunctionMutation:
+			m.conflicts = conflicts
+		case *updatePackageMutation:
+			m.allowedUpstreams = repositoryObj.Spec.AllowedUpstreams
+		}
+		mutations = append(mutations, mutation)
+	}
+
+	// Re-render if we are making changes.
+	mutations = cad.conditionalAddRender(mutations, newObj.Annotations)
+
+	// If any of the fields in the API that are projections from the Kptfile
+	// must be updated in the Kptfile as well.
+	kf, err := snapshot.Kptfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kfPatchTask, created, err := createKptfilePatchTask(kf, newObj)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		kfPatchMutation, err := buildPatchMutation(ctx, kfPatchTask)
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, kfPatchMutation)
+	}
+
+	// Re-render if we are making changes.
+	mutations = cad.conditionalAddRender(mutations, newObj.Annotations)
+
+	// A draft stored without rendering is rendered before it is proposed or published.
+	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecycleDraft {
+		resources, err := snapshot.Resources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mutations = cad.completeDeferredRender(resources, newObj.Spec.Lifecycle, mutations, newObj.Annotations)
+	}
+	return mutations, nil
+}
+
 // conditionalAddRender adds a render mutation to the end of the mutations slice if the last
-// entry is not already a render mutation.
-func (cad *cadEngine) conditionalAddRender(mutations []mutation) []mutation {
+// entry is not already a render mutation. The package is rendered with the toolchain
+// version and pipeline overlay of the annotations of the package revision.
+func (cad *cadEngine) conditionalAddRender(mutations []mutation, annotations map[string]string) []mutation {
 	if len(mutations) == 0 {
 		return mutations
 	}
@@ -684,15 +1070,21 @@ func (cad *cadEngine) conditionalAddRender(mutations []mutation) []mutation {
 		return mutations
 	}
 
-	return append(mutations, &renderPackageMutation{
-		renderer: cad.renderer,
-		runtime:  cad.runtime,
-	})
+	// Unless render is forced, the implicit render skips packages without a pipeline
+	// rather than starting the function runtime for nothing.
+	render := cad.renderMutation(annotations)
+	render.skipWithoutPipeline = !cad.forceRender
+	return append(mutations, render)
 }
 
 func (cad *cadEngine) DeletePackageRevision(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision) error {
 	ctx, span := tracer.Start(ctx, "cadEngine::DeletePackageRevision", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, oldPackage.repoPackageRevision.Key())
+
+	if err := cad.checkPackageNotFrozen(ctx, repositoryObj, oldPackage.repoPackageRevision.Key().Package); err != nil {
+		return err
+	}
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
 	if err != nil {
@@ -710,6 +1102,7 @@ func (cad *cadEngine) DeletePackageRevision(ctx context.Context, repositoryObj *
 	if _, err := cad.metadataStore.Delete(ctx, namespacedName); err != nil {
 		return err
 	}
+	cad.recordRevisionDeletion(ctx, repositoryObj, oldPackage)
 
 	return nil
 }
@@ -722,15 +1115,33 @@ func (cad *cadEngine) ListPackages(ctx context.Context, repositorySpec *configap
 	if err != nil {
 		return nil, err
 	}
+	if err := cad.checkRepositorySynced(ctx, repositorySpec, repo); err != nil {
+		return nil, err
+	}
+	return cad.listPackages(ctx, repositorySpec, repo, filter)
+}
 
+// listPackages lists the packages of the repository, with the archiving, freeze and
+// metadata recorded in the metadata of their package revisions.
+func (cad *cadEngine) listPackages(ctx context.Context, repositorySpec *configapi.Repository, repo repository.Repository, filter repository.ListPackageFilter) ([]*Package, error) {
 	pkgs, err := repo.ListPackages(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
+	revisions, _, err := cad.listPackageRevisions(ctx, repositorySpec, repo, repository.ListPackageRevisionFilter{Package: filter.Package})
+	if err != nil {
+		return nil, err
+	}
+	archived := archivedPackages(revisions)
+	freezes := packageFreezes(revisions)
+	metadatas := packageMetadatas(revisions)
 	var packages []*Package
 	for _, p := range pkgs {
 		packages = append(packages, &Package{
 			repoPackage: p,
+			archived:    archived[p.Key().Package],
+			freeze:      freezes[p.Key().Package],
+			metadata:    metadatas[p.Key().Package],
 		})
 	}
 
@@ -740,11 +1151,15 @@ func (cad *cadEngine) ListPackages(ctx context.Context, repositorySpec *configap
 func (cad *cadEngine) CreatePackage(ctx context.Context, repositoryObj *configapi.Repository, obj *api.Package) (*Package, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::CreatePackage", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: obj.Spec.PackageName})
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkPackagePath(ctx, repo, repositoryObj.Name, obj.Spec.PackageName); err != nil {
+		return nil, err
+	}
 	pkg, err := repo.CreatePackage(ctx, obj)
 	if err != nil {
 		return nil, err
@@ -758,15 +1173,24 @@ func (cad *cadEngine) CreatePackage(ctx context.Context, repositoryObj *configap
 func (cad *cadEngine) UpdatePackage(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *Package, oldObj, newObj *api.Package) (*Package, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::UpdatePackage", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: oldPackage.repoPackage.Key().Package})
 
-	// TODO
-	var pkg *Package
-	return pkg, fmt.Errorf("Updating packages is not yet supported")
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.updatePackage(ctx, repo, repositoryObj, oldPackage, oldObj, newObj)
 }
 
 func (cad *cadEngine) DeletePackage(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *Package) error {
 	ctx, span := tracer.Start(ctx, "cadEngine::DeletePackage", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: oldPackage.repoPackage.Key().Package})
+
+	if isSafeDelete(repositoryObj) && !oldPackage.IsArchived() {
+		return apierrors.NewConflict(api.PackageGVR.GroupResource(), oldPackage.KubeObjectName(),
+			fmt.Errorf("package must be archived before it can be deleted from repository %q", repositoryObj.Name))
+	}
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
 	if err != nil {
@@ -783,6 +1207,7 @@ func (cad *cadEngine) DeletePackage(ctx context.Context, repositoryObj *configap
 func (cad *cadEngine) UpdatePackageResources(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, old, new *api.PackageRevisionResources) (*PackageRevision, error) {
 	ctx, span := tracer.Start(ctx, "cadEngine::UpdatePackageResources", trace.WithAttributes())
 	defer span.End()
+	ctx = withPackageLogger(ctx, oldPackage.repoPackageRevision.Key())
 
 	rev, err := oldPackage.repoPackageRevision.GetPackageRevision(ctx)
 	if err != nil {
@@ -796,8 +1221,7 @@ func (cad *cadEngine) UpdatePackageResources(ctx context.Context, repositoryObj
 	case api.PackageRevisionLifecycleDraft:
 		// Only draf can be updated.
 	case api.PackageRevisionLifecycleProposed, api.PackageRevisionLifecyclePublished:
-		// TODO: generate errors that can be translated to correct HTTP responses
-		return nil, fmt.Errorf("cannot update a package revision with lifecycle value %q; package must be Draft", lifecycle)
+		return nil, notDraftConflict(oldPackage.KubeObjectName(), lifecycle, "update")
 	}
 
 	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
@@ -809,32 +1233,28 @@ func (cad *cadEngine) UpdatePackageResources(ctx context.Context, repositoryObj
 	if err != nil {
 		return nil, err
 	}
+	draft = cad.bufferDraft(draft)
 
-	mutations := []mutation{
-		&mutationReplaceResources{
-			newResources: new,
-			oldResources: old,
-		},
-		&renderPackageMutation{
-			renderer: cad.renderer,
-			runtime:  cad.runtime,
-		},
-	}
+	mutations := cad.replaceResourcesMutations(repositoryObj, old, new, oldPackage.packageRevisionMeta.Annotations)
 
 	apiResources, err := oldPackage.repoPackageRevision.GetResources(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get package resources: %w", err)
 	}
 	resources := repository.PackageResources{
-		Contents: apiResources.Spec.Resources,
+		Contents:  apiResources.Spec.Resources,
+		FileModes: apiResources.Spec.FileModes,
 	}
 
-	if err := applyResourceMutations(ctx, draft, resources, mutations); err != nil {
+	ctx, logs := withFunctionLogs(ctx)
+	err = cad.applyResourceMutations(ctx, draft, resources, mutations)
+	cad.functionLogs.put(repositoryObj.Namespace, oldPackage.KubeObjectName(), logs)
+	if err != nil {
 		return nil, err
 	}
 
 	// No lifecycle change when updating package resources; updates are done.
-	repoPkgRev, err := draft.Close(ctx)
+	repoPkgRev, err := cad.closeDraft(ctx, draft)
 	if err != nil {
 		return nil, err
 	}
@@ -843,19 +1263,40 @@ func (cad *cadEngine) UpdatePackageResources(ctx context.Context, repositoryObj
 	}, nil
 }
 
-func applyResourceMutations(ctx context.Context, draft repository.PackageDraft, baseResources repository.PackageResources, mutations []mutation) error {
-	for _, m := range mutations {
-		applied, task, err := m.Apply(ctx, baseResources)
+func (cad *cadEngine) applyResourceMutations(ctx context.Context, draft repository.PackageDraft, baseResources repository.PackageResources, mutations []mutation) error {
+	ctx = withRequestSecrets(ctx)
+	for i, m := range mutations {
+		if err := checkCancelled(ctx); err != nil {
+			return err
+		}
+		start := time.Now()
+		functions := &functionResults{}
+		taskCtx := withTaskFunctionResults(withTaskFunctionLogs(withTaskLogger(ctx, i), i), functions)
+		applied, task, err := cad.applyMutation(taskCtx, m, baseResources)
 		if err != nil {
+			if ctx.Err() != nil {
+				return &CancelledError{Err: ctx.Err()}
+			}
+			return err
+		}
+		// The draft is abandoned, rather than updated, once the request is cancelled.
+		if err := checkCancelled(ctx); err != nil {
 			return err
 		}
+		if task == nil {
+			// The mutation was skipped.
+			continue
+		}
+		applied = keepFileModes(baseResources, applied)
 		if err := draft.UpdateResources(ctx, &api.PackageRevisionResources{
 			Spec: api.PackageRevisionResourcesSpec{
 				Resources: applied.Contents,
+				FileModes: applied.FileModes,
 			},
 		}, task); err != nil {
-			return err
+			return systemError(err)
 		}
+		recordTaskResult(ctx, i, task, start, baseResources, applied, functions.list())
 		baseResources = applied
 	}
 
@@ -887,93 +1328,264 @@ func (cad *cadEngine) ListFunctions(ctx context.Context, repositoryObj *configap
 }
 
 type updatePackageMutation struct {
-	cloneTask         *api.Task
-	updateTask        *api.Task
-	repoOpener        RepositoryOpener
-	referenceResolver ReferenceResolver
-	namespace         string
-	pkgName           string
+	cloneTask          *api.Task
+	updateTask         *api.Task
+	repoOpener         RepositoryOpener
+	credentialResolver repository.CredentialResolver
+	referenceResolver  ReferenceResolver
+	ociOpener          ociPackageOpener
+	namespace          string
+	pkgName            string
+	sizeBudget         *PackageSizeBudget
+
+	// previousUpstream is the upstream set by the last clone or update task before the
+	// update, if any.
+	previousUpstream *api.UpstreamPackage
+
+	// upstreamFallback uses the latest earlier revision of the upstream package as the
+	// original if the revision the package was cloned from no longer exists.
+	upstreamFallback bool
+
+	// allowedUpstreams, if not empty, restricts the upstreams the package can be updated to.
+	allowedUpstreams []configapi.AllowedUpstream
+
+	// externalSources, if set, restricts git and OCI upstreams to the registered repositories.
+	externalSources *externalSourcePolicy
+
+	// targetRevision is the package revision of a registered repository the upstream
+	// reference of the update was resolved to when the mutation was validated.
+	targetRevision repository.PackageRevision
+}
+
+var _ validatingMutation = &updatePackageMutation{}
+
+func (m *updatePackageMutation) sourceTask() *api.Task {
+	return m.updateTask
+}
+
+// validate checks that the package has an upstream, that the target upstream is
+// allowed and, for a target of a registered repository, that the referenced package
+// revision exists.
+func (m *updatePackageMutation) validate(ctx context.Context) error {
+	if _, err := m.currUpstream(); err != nil {
+		return err
+	}
+	target := m.updateTask.Update.Upstream
+	if err := checkAllowedUpstream(m.allowedUpstreams, &target); err != nil {
+		return err
+	}
+	if err := m.externalSources.checkUpstream(ctx, &target, "update task"); err != nil {
+		return err
+	}
+	if target.UpstreamRef == nil && target.Git == nil && target.Oci == nil {
+		return errors.New("invalid update target (neither of git, oci, nor upstream were specified)")
+	}
+	if ref := target.UpstreamRef; ref != nil {
+		revision, err := m.fetcher().FetchRevision(ctx, ref, m.namespace)
+		if err != nil {
+			return fmt.Errorf("error fetching revision for target upstream %s: %w", ref.Name, err)
+		}
+		m.targetRevision = revision
+	}
+	return nil
+}
+
+func (m *updatePackageMutation) fetcher() *PackageFetcher {
+	return &PackageFetcher{
+		repoOpener:        m.repoOpener,
+		referenceResolver: m.referenceResolver,
+		sizeBudget:        m.sizeBudget,
+	}
 }
 
 func (m *updatePackageMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
 	ctx, span := tracer.Start(ctx, "updatePackageMutation::Apply", trace.WithAttributes())
 	defer span.End()
 
-	currUpstreamPkgRef, err := m.currUpstream()
+	currUpstream, err := m.currUpstream()
 	if err != nil {
 		return repository.PackageResources{}, nil, err
 	}
 
 	targetUpstream := m.updateTask.Update.Upstream
-	if targetUpstream.Type == api.RepositoryTypeGit || targetUpstream.Type == api.RepositoryTypeOCI {
-		return repository.PackageResources{}, nil, fmt.Errorf("update is not supported for non-porch upstream packages")
+	if err := checkAllowedUpstream(m.allowedUpstreams, &targetUpstream); err != nil {
+		return repository.PackageResources{}, nil, err
 	}
-
-	originalResources, err := (&PackageFetcher{
-		repoOpener:        m.repoOpener,
-		referenceResolver: m.referenceResolver,
-	}).FetchResources(ctx, currUpstreamPkgRef, m.namespace)
-	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("error fetching the resources for package %s with ref %+v",
-			m.pkgName, *currUpstreamPkgRef)
+	if err := m.externalSources.checkUpstream(ctx, &targetUpstream, "update task"); err != nil {
+		return repository.PackageResources{}, nil, err
 	}
 
-	upstreamRevision, err := (&PackageFetcher{
-		repoOpener:        m.repoOpener,
-		referenceResolver: m.referenceResolver,
-	}).FetchRevision(ctx, targetUpstream.UpstreamRef, m.namespace)
+	fetcher := m.fetcher()
+
+	upstream, err := m.fetchTargetUpstream(ctx, fetcher, &targetUpstream)
 	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("error fetching revision for target upstream %s", targetUpstream.UpstreamRef.Name)
+		return repository.PackageResources{}, nil, err
 	}
-	upstreamResources, err := upstreamRevision.GetResources(ctx)
+
+	originalResources, err := m.fetchOriginalUpstream(ctx, fetcher, resources, currUpstream, upstream.revision)
 	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("error fetching resources for target upstream %s", targetUpstream.UpstreamRef.Name)
+		return repository.PackageResources{}, nil, err
 	}
 
 	klog.Infof("performing pkg upgrade operation for pkg %s resource counts local[%d] original[%d] upstream[%d]",
-		m.pkgName, len(resources.Contents), len(originalResources.Spec.Resources), len(upstreamResources.Spec.Resources))
+		m.pkgName, len(resources.Contents), len(originalResources.Contents), len(upstream.resources.Contents))
 
 	// May be have packageUpdater part of engine to make it easy for testing ?
-	updatedResources, err := (&defaultPackageUpdater{}).Update(ctx,
-		resources,
-		repository.PackageResources{
-			Contents: originalResources.Spec.Resources,
-		},
-		repository.PackageResources{
-			Contents: upstreamResources.Spec.Resources,
-		})
+	updatedResources, err := (&defaultPackageUpdater{}).Update(ctx, resources, originalResources, upstream.resources)
 	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("error updating the package to revision %s", targetUpstream.UpstreamRef.Name)
+		return repository.PackageResources{}, nil, fmt.Errorf("error updating the package to revision %s", upstream.name)
 	}
 
-	newUpstream, newUpstreamLock, err := upstreamRevision.GetLock()
-	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("error fetching the resources for package revisions %s", targetUpstream.UpstreamRef.Name)
+	if upstream.revision != nil {
+		if upstream.upstream, upstream.lock, err = upstream.revision.GetLock(); err != nil {
+			return repository.PackageResources{}, nil, fmt.Errorf("error fetching the resources for package revisions %s", upstream.name)
+		}
 	}
-	if err := kpt.UpdateKptfileUpstream("", updatedResources.Contents, newUpstream, newUpstreamLock); err != nil {
+	task := m.updateTask
+	if upstream.ociImage != "" {
+		// The Kptfile can't record OCI upstreams; the task records the digest the
+		// package was updated to instead, so that it is the original of the next update.
+		task = m.updateTask.DeepCopy()
+		task.Update.Upstream.Oci.Image = upstream.ociImage
+	} else if err := kpt.UpdateKptfileUpstream("", updatedResources.Contents, upstream.upstream, upstream.lock); err != nil {
 		return repository.PackageResources{}, nil, fmt.Errorf("failed to apply upstream lock to package %q: %w", m.pkgName, err)
 	}
 
 	// ensure merge-key comment is added to newly added resources.
 	result, err := ensureMergeKey(ctx, updatedResources)
 	if err != nil {
-		klog.Infof("failed to add merge key comments: %v", err)
+		klog.FromContext(ctx).Info("Failed to add merge key comments", "err", err)
+	}
+	return result, task, nil
+}
+
+// fetchedUpstream is the upstream package a package is updated to.
+type fetchedUpstream struct {
+	// name describes the upstream package in errors.
+	name      string
+	resources repository.PackageResources
+	upstream  kptfile.Upstream
+	lock      kptfile.UpstreamLock
+
+	// revision is the package revision of an upstream package of a registered
+	// repository, and nil for other upstream packages. The upstream and lock of a
+	// package revision are read from it once the package is updated.
+	revision repository.PackageRevision
+
+	// ociImage is the image of an OCI upstream package, referenced by its digest.
+	ociImage string
+}
+
+// fetchTargetUpstream fetches the upstream package the package is updated to.
+func (m *updatePackageMutation) fetchTargetUpstream(ctx context.Context, fetcher *PackageFetcher, target *api.UpstreamPackage) (*fetchedUpstream, error) {
+	switch {
+	case target.UpstreamRef != nil:
+		name := target.UpstreamRef.Name
+		revision := m.targetRevision
+		if revision == nil {
+			var err error
+			if revision, err = fetcher.FetchRevision(ctx, target.UpstreamRef, m.namespace); err != nil {
+				return nil, fmt.Errorf("error fetching revision for target upstream %s: %w", name, err)
+			}
+		}
+		resources, err := fetcher.GetResources(ctx, revision)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching resources for target upstream %s: %w", name, err)
+		}
+		return &fetchedUpstream{
+			name:      name,
+			resources: repository.PackageResources{Contents: resources.Spec.Resources, FileModes: resources.Spec.FileModes},
+			revision:  revision,
+		}, nil
+
+	case target.Git != nil:
+		name := fmt.Sprintf("%s@%s", target.Git.Directory, target.Git.Ref)
+		resources, lock, err := fetchGitPackage(m.sizeBudget.limitReads(ctx), m.credentialResolver, target.Git)
+		if err != nil {
+			return nil, m.sizeBudget.exceeded(name, fmt.Errorf("error fetching target upstream %s: %w", name, err))
+		}
+		if err := m.sizeBudget.check(name, resources.Contents); err != nil {
+			return nil, err
+		}
+		upstream, upstreamLock := gitUpstream(lock)
+		return &fetchedUpstream{name: name, resources: resources, upstream: upstream, lock: upstreamLock}, nil
+
+	case target.Oci != nil:
+		name := target.Oci.Image
+		resources, image, err := fetchOciPackage(m.sizeBudget.limitReads(ctx), m.ociOpener, target.Oci)
+		if err != nil {
+			return nil, m.sizeBudget.exceeded(name, fmt.Errorf("error fetching target upstream %s: %w", name, err))
+		}
+		if err := m.sizeBudget.check(name, resources.Contents); err != nil {
+			return nil, err
+		}
+		return &fetchedUpstream{name: name, resources: resources, ociImage: ociImageName(image)}, nil
+
+	default:
+		return nil, errors.New("invalid update target (neither of git, oci, nor upstream were specified)")
+	}
+}
+
+// fetchOriginalUpstream fetches the upstream package the package was cloned from. A package
+// cloned from git is at the commit recorded in its Kptfile, which moves with every update.
+func (m *updatePackageMutation) fetchOriginalUpstream(ctx context.Context, fetcher *PackageFetcher, resources repository.PackageResources, original *api.UpstreamPackage, target repository.PackageRevision) (repository.PackageResources, error) {
+	switch {
+	case original.UpstreamRef != nil:
+		originalResources, err := m.fetchOriginalResources(ctx, fetcher, original.UpstreamRef, target)
+		if err != nil {
+			return repository.PackageResources{}, err
+		}
+		return repository.PackageResources{Contents: originalResources.Spec.Resources, FileModes: originalResources.Spec.FileModes}, nil
+
+	case original.Git != nil:
+		gitPackage := lockedGitPackage(resources, original.Git)
+		originalResources, _, err := fetchGitPackage(ctx, m.credentialResolver, gitPackage)
+		if err != nil {
+			return repository.PackageResources{}, fmt.Errorf("error fetching the original upstream %s@%s of package %s: %w", gitPackage.Directory, gitPackage.Ref, m.pkgName, err)
+		}
+		return originalResources, nil
+
+	case original.Oci != nil:
+		originalResources, _, err := fetchOciPackage(ctx, m.ociOpener, original.Oci)
+		if err != nil {
+			return repository.PackageResources{}, fmt.Errorf("error fetching the original upstream %s of package %s: %w", original.Oci.Image, m.pkgName, err)
+		}
+		return originalResources, nil
+
+	default:
+		return repository.PackageResources{}, fmt.Errorf("package %s does not have original upstream info", m.pkgName)
 	}
-	return result, m.updateTask, nil
 }
 
-// Currently assumption is that downstream packages will be forked from a porch package.
-// As per current implementation, upstream package ref is stored in a new update task but this may
+// lockedGitPackage returns the git package at the commit of the upstream lock of the
+// Kptfile, if the lock refers to the package; otherwise it returns the package.
+func lockedGitPackage(resources repository.PackageResources, gitPackage *api.GitPackage) *api.GitPackage {
+	kf, err := internalpkg.DecodeKptfile(strings.NewReader(resources.Contents[kptfile.KptFileName]))
+	if err != nil || kf.UpstreamLock == nil || kf.UpstreamLock.Git == nil {
+		return gitPackage
+	}
+	lock := kf.UpstreamLock.Git
+	if lock.Commit == "" || lock.Repo != gitPackage.Repo || strings.Trim(lock.Directory, "/") != strings.Trim(gitPackage.Directory, "/") {
+		return gitPackage
+	}
+	locked := gitPackage.DeepCopy()
+	locked.Ref = lock.Commit
+	return locked
+}
+
+// currUpstream returns the upstream the package was cloned from. As per current
+// implementation, upstream package ref is stored in a new update task but this may
 // change so the logic of figuring out current upstream will live in this function.
-func (m *updatePackageMutation) currUpstream() (*api.PackageRevisionRef, error) {
+// A package last updated from an OCI image is at the image its update task recorded,
+// since its Kptfile has no upstream lock.
+func (m *updatePackageMutation) currUpstream() (*api.UpstreamPackage, error) {
+	if m.previousUpstream != nil && m.previousUpstream.Oci != nil {
+		return m.previousUpstream, nil
+	}
 	if m.cloneTask == nil || m.cloneTask.Clone == nil {
 		return nil, fmt.Errorf("package %s does not have original upstream info", m.pkgName)
 	}
-	upstream := m.cloneTask.Clone.Upstream
-	if upstream.Type == api.RepositoryTypeGit || upstream.Type == api.RepositoryTypeOCI {
-		return nil, fmt.Errorf("upstream package must be porch native package. Found it to be %s", upstream.Type)
-	}
-	return upstream.UpstreamRef, nil
+	return &m.cloneTask.Clone.Upstream, nil
 }
 
 func findCloneTask(pr *api.PackageRevision) *api.Task {
@@ -994,7 +1606,7 @@ func writeResourcesToDirectory(dir string, resources repository.PackageResources
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create directory %q: %w", dir, err)
 		}
-		if err := os.WriteFile(p, []byte(v), 0644); err != nil {
+		if err := os.WriteFile(p, []byte(v), fsFileMode(resources.FileModes[k])); err != nil {
 			return fmt.Errorf("failed to write file %q: %w", dir, err)
 		}
 	}
@@ -1004,7 +1616,8 @@ func writeResourcesToDirectory(dir string, resources repository.PackageResources
 func loadResourcesFromDirectory(dir string) (repository.PackageResources, error) {
 	// TODO: return abstraction instead of loading everything
 	result := repository.PackageResources{
-		Contents: map[string]string{},
+		Contents:  map[string]string{},
+		FileModes: map[string]api.FileMode{},
 	}
 	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -1023,6 +1636,14 @@ func loadResourcesFromDirectory(dir string) (repository.PackageResources, error)
 			return fmt.Errorf("cannot read file %q: %w", dir, err)
 		}
 		result.Contents[rel] = string(contents)
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("cannot stat file %q: %w", path, err)
+		}
+		if info.Mode()&0111 != 0 {
+			result.FileModes[rel] = api.FileModeExecutable
+		}
 		return nil
 	}); err != nil {
 		return repository.PackageResources{}, err
@@ -1034,6 +1655,9 @@ func loadResourcesFromDirectory(dir string) (repository.PackageResources, error)
 type mutationReplaceResources struct {
 	newResources *api.PackageRevisionResources
 	oldResources *api.PackageRevisionResources
+
+	// protectPackageContext rejects, rather than logs, the removal of the package context.
+	protectPackageContext bool
 }
 
 func (m *mutationReplaceResources) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
@@ -1042,100 +1666,70 @@ func (m *mutationReplaceResources) Apply(ctx context.Context, resources reposito
 
 	patch := &api.PackagePatchTaskSpec{}
 
-	old := resources.Contents
-	new, err := healConfig(old, m.newResources.Spec.Resources)
+	old := pkgcontents.Contents(resources.Contents)
+	if err := checkRequiredFiles(ctx, old, m.newResources.Spec.Resources, m.protectPackageContext); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	// Restore the comments clients drop when they don't preserve them.
+	new, err := pkgcontents.CopyComments(old, m.newResources.Spec.Resources)
 	if err != nil {
 		return repository.PackageResources{}, nil, fmt.Errorf("failed to heal resources: %w", err)
 	}
 
-	for k, newV := range new {
-		oldV, ok := old[k]
-		// New config or changed config
-		if !ok {
-			patchSpec := api.PatchSpec{
-				File:      k,
-				PatchType: api.PatchTypeCreateFile,
-				Contents:  newV,
-			}
-			patch.Patches = append(patch.Patches, patchSpec)
-		} else if newV != oldV {
-			patchSpec, err := GeneratePatch(k, oldV, newV)
-			if err != nil {
-				return repository.PackageResources{}, nil, fmt.Errorf("error generating patch: %w", err)
-			}
-
-			patch.Patches = append(patch.Patches, patchSpec)
-		}
+	changes := pkgcontents.Diff(old, new)
+	for _, k := range changes.Added {
+		patch.Patches = append(patch.Patches, api.PatchSpec{
+			File:      k,
+			PatchType: api.PatchTypeCreateFile,
+			Contents:  new[k],
+		})
 	}
-	for k := range old {
-		// Deleted config
-		if _, ok := new[k]; !ok {
-			patchSpec := api.PatchSpec{
-				File:      k,
-				PatchType: api.PatchTypeDeleteFile,
-			}
-			patch.Patches = append(patch.Patches, patchSpec)
+	for _, k := range changes.Modified {
+		patchSpec, err := GeneratePatch(k, old[k], new[k])
+		if err != nil {
+			return repository.PackageResources{}, nil, fmt.Errorf("error generating patch: %w", err)
 		}
+		patch.Patches = append(patch.Patches, patchSpec)
 	}
-	task := &api.Task{
-		Type:  api.TaskTypePatch,
-		Patch: patch,
-	}
-
-	return repository.PackageResources{Contents: new}, task, nil
-}
-
-func healConfig(old, new map[string]string) (map[string]string, error) {
-	// Copy comments from old config to new
-	oldResources, err := (&packageReader{
-		input: repository.PackageResources{Contents: old},
-		extra: map[string]string{},
-	}).Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read old packge resources: %w", err)
+	for _, k := range changes.Deleted {
+		patch.Patches = append(patch.Patches, api.PatchSpec{
+			File:      k,
+			PatchType: api.PatchTypeDeleteFile,
+		})
 	}
 
-	var filter kio.FilterFunc = func(r []*yaml.RNode) ([]*yaml.RNode, error) {
-		for _, n := range r {
-			for _, original := range oldResources {
-				if n.GetNamespace() == original.GetNamespace() &&
-					n.GetName() == original.GetName() &&
-					n.GetApiVersion() == original.GetApiVersion() &&
-					n.GetKind() == original.GetKind() {
-					comments.CopyComments(original, n)
-				}
+	// Files keep their modes unless new ones are set. Mode changes are recorded as patches
+	// too, so that replaying the tasks sets them again.
+	var modes map[string]api.FileMode
+	if newModes := m.newResources.Spec.FileModes; len(newModes) > 0 {
+		if errs := validateFileModes(newModes, new); len(errs) > 0 {
+			return repository.PackageResources{}, nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevisionResources").GroupKind(), m.newResources.Name, errs)
+		}
+		modes = map[string]api.FileMode{}
+		for k, mode := range resources.FileModes {
+			if _, found := new[k]; found {
+				modes[k] = mode
+			}
+		}
+		for _, k := range sortedFileModePaths(newModes) {
+			// Files created with a mode are created as regular files first.
+			oldMode := api.FileModeRegular
+			if _, found := old[k]; found && modes[k] != "" {
+				oldMode = modes[k]
+			}
+			setFileMode(modes, k, newModes[k])
+			if oldMode != newModes[k] {
+				patch.Patches = append(patch.Patches, fileModePatch(k, oldMode, newModes[k]))
 			}
 		}
-		return r, nil
-	}
-
-	out := &packageWriter{
-		output: repository.PackageResources{
-			Contents: map[string]string{},
-		},
-	}
-
-	extra := map[string]string{}
-
-	if err := (kio.Pipeline{
-		Inputs: []kio.Reader{&packageReader{
-			input: repository.PackageResources{Contents: new},
-			extra: extra,
-		}},
-		Filters:               []kio.Filter{filter},
-		Outputs:               []kio.Writer{out},
-		ContinueOnEmptyResult: true,
-	}).Execute(); err != nil {
-		return nil, err
 	}
 
-	healed := out.output.Contents
-
-	for k, v := range extra {
-		healed[k] = v
+	task := &api.Task{
+		Type:  api.TaskTypePatch,
+		Patch: patch,
 	}
 
-	return healed, nil
+	return repository.PackageResources{Contents: new, FileModes: modes}, task, nil
 }
 
 // isRecloneAndReplay determines if an update should be handled using reclone-and-replay semantics.
@@ -1158,6 +1752,27 @@ func isRecloneAndReplay(oldObj, newObj *api.PackageRevision) bool {
 	return true
 }
 
+// preserveUpstreamResolution carries the resolved upstream of the clone task over
+// to the new object as long as the upstream reference is unchanged, so that
+// recloning uses the recorded package revision instead of resolving it again.
+// The server owns the resolution; it is dropped when the upstream changes.
+func preserveUpstreamResolution(oldObj, newObj *api.PackageRevision) {
+	oldTasks := oldObj.Spec.Tasks
+	newTasks := newObj.Spec.Tasks
+	if len(oldTasks) == 0 || len(newTasks) == 0 {
+		return
+	}
+	oldClone, newClone := oldTasks[0].Clone, newTasks[0].Clone
+	if newTasks[0].Type != api.TaskTypeClone || newClone == nil {
+		return
+	}
+	if oldTasks[0].Type == api.TaskTypeClone && oldClone != nil && reflect.DeepEqual(oldClone.Upstream, newClone.Upstream) {
+		newClone.ResolvedUpstreamRef = oldClone.ResolvedUpstreamRef.DeepCopy()
+	} else {
+		newClone.ResolvedUpstreamRef = nil
+	}
+}
+
 // recloneAndReplay performs an update by recloning the upstream package and replaying all tasks.
 // This is more like a git rebase operation than the "classic" kpt update algorithm, which is more like a git merge.
 func (cad *cadEngine) recloneAndReplay(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, newObj *api.PackageRevision, packageConfig *builtins.PackageConfig) (repository.PackageRevision, error) {
@@ -1170,6 +1785,7 @@ func (cad *cadEngine) recloneAndReplay(ctx context.Context, repo repository.Repo
 	if err != nil {
 		return nil, err
 	}
+	draft = cad.bufferDraft(draft)
 
 	if err := cad.applyTasks(ctx, draft, repositoryObj, newObj, packageConfig); err != nil {
 		return nil, err
@@ -1179,7 +1795,7 @@ func (cad *cadEngine) recloneAndReplay(ctx context.Context, repo repository.Repo
 		return nil, err
 	}
 
-	return draft.Close(ctx)
+	return cad.closeDraft(ctx, draft)
 }
 
 // ExtractContextConfigMap returns the package-context configmap, if found
@@ -15,20 +15,18 @@
 package engine
 
 import (
-	"context"
 	"strings"
 	"testing"
 
 	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
-	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/google/go-cmp/cmp"
 )
 
 func TestSomething(t *testing.T) {
 	testCases := map[string]struct {
-		repoPkgRev   repository.PackageRevision
+		repoPkgRev   *fake.PackageRevision
 		newApiPkgRev *api.PackageRevision
 		hasPatch     bool
 		patch        api.PatchSpec
@@ -294,7 +292,7 @@ func TestSomething(t *testing.T) {
 	for tn := range testCases {
 		tc := testCases[tn]
 		t.Run(tn, func(t *testing.T) {
-			task, hasPatch, err := createKptfilePatchTask(context.Background(), tc.repoPkgRev, tc.newApiPkgRev)
+			task, hasPatch, err := createKptfilePatchTask(tc.repoPkgRev.Kptfile, tc.newApiPkgRev)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -17,6 +17,7 @@ package engine
 import (
 	"context"
 
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -29,3 +30,8 @@ type Object interface {
 type ReferenceResolver interface {
 	ResolveReference(ctx context.Context, namespace, name string, result Object) error
 }
+
+// RepositoryLister lists the repositories registered in a namespace.
+type RepositoryLister interface {
+	ListRepositories(ctx context.Context, namespace string) ([]configapi.Repository, error)
+}
@@ -32,27 +32,79 @@ import (
 type evalFunctionMutation struct {
 	runtime fn.FunctionRuntime
 	task    *api.Task
+
+	// conflicts, if set, tracks the fields changed by the eval tasks of the
+	// package revision and resolves conflicts between them.
+	conflicts *evalFieldTracker
+
+	// allowedImages, if set, restricts the function images eval tasks can run.
+	allowedImages AllowedFunctionImages
+
+	// secrets resolves the Secrets referenced by the function config of the task.
+	secrets *configSecretResolver
 }
 
 func (m *evalFunctionMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
 	ctx, span := tracer.Start(ctx, "evalFunctionMutation::Apply", trace.WithAttributes())
 	defer span.End()
 
+	e := m.task.Eval
+	if err := m.allowedImages.checkEval(ctx, e.Image); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+
+	// Secret values are only set in the function config passed to the function;
+	// the task keeps the references.
+	secrets, err := m.secrets.resolve(ctx, e.ConfigSecretRefs)
+	if err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	setFunctionLogSecrets(ctx, secrets)
+	addRequestSecrets(ctx, secrets)
+	result, err := m.apply(ctx, resources, secrets)
+	if err != nil {
+		return repository.PackageResources{}, nil, redactSecrets(err, secrets)
+	}
+	if err := checkSecretsNotLeaked(resources, result, secrets); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+
+	if m.conflicts != nil {
+		merged, err := m.conflicts.merge(e.Image, resources, result)
+		if err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+		result = merged
+	}
+
+	return result, m.task, nil
+}
+
+func (m *evalFunctionMutation) apply(ctx context.Context, resources repository.PackageResources, secrets map[string]string) (repository.PackageResources, error) {
 	e := m.task.Eval
 
 	// TODO: Apply should accept filesystem instead of PackageResources
 
-	runner, err := m.runtime.GetRunner(ctx, &v1.Function{
+	results := &functionResults{}
+	runtime := &resultRecordingRuntime{runtime: &cancellableRuntime{runtime: m.runtime}, results: results}
+	runner, err := runtime.GetRunner(ctx, &v1.Function{
 		Image: e.Image,
 	})
 	if err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("failed to create function runner: %w", err)
+		return repository.PackageResources{}, fmt.Errorf("failed to create function runner: %w", err)
 	}
 
 	var functionConfig *yaml.RNode
-	if m.task.Eval.ConfigMap != nil {
-		if cm, err := fnruntime.NewConfigMap(m.task.Eval.ConfigMap); err != nil {
-			return repository.PackageResources{}, nil, fmt.Errorf("failed to create function config: %w", err)
+	if m.task.Eval.ConfigMap != nil || (len(m.task.Eval.Config.Raw) == 0 && len(secrets) != 0) {
+		data := map[string]string{}
+		for k, v := range m.task.Eval.ConfigMap {
+			data[k] = v
+		}
+		for k, v := range secrets {
+			data[k] = v
+		}
+		if cm, err := fnruntime.NewConfigMap(data); err != nil {
+			return repository.PackageResources{}, fmt.Errorf("failed to create function config: %w", err)
 		} else {
 			functionConfig = cm
 		}
@@ -60,7 +112,10 @@ func (m *evalFunctionMutation) Apply(ctx context.Context, resources repository.P
 		// raw is JSON (we expect), but we take advantage of the fact that YAML is a superset of JSON
 		config, err := yaml.Parse(string(m.task.Eval.Config.Raw))
 		if err != nil {
-			return repository.PackageResources{}, nil, fmt.Errorf("error parsing function config: %w", err)
+			return repository.PackageResources{}, fmt.Errorf("error parsing function config: %w", err)
+		}
+		if err := setConfigSecrets(config, secrets); err != nil {
+			return repository.PackageResources{}, err
 		}
 		functionConfig = config
 	}
@@ -95,8 +150,11 @@ func (m *evalFunctionMutation) Apply(ctx context.Context, resources repository.P
 		}},
 	}
 
-	if err := pipeline.Execute(); err != nil {
-		return repository.PackageResources{}, nil, fmt.Errorf("failed to evaluate function: %w", err)
+	err = pipeline.Execute()
+	// The results are stored in the status of the package revision.
+	captureFunctionResults(ctx, redactFunctionResults(ctx, results.list(), secrets))
+	if err != nil {
+		return repository.PackageResources{}, fmt.Errorf("failed to evaluate function: %w", err)
 	}
 
 	// Return extras. TODO: Apply should accept FS.
@@ -104,5 +162,5 @@ func (m *evalFunctionMutation) Apply(ctx context.Context, resources repository.P
 		result.Contents[k] = v
 	}
 
-	return result, m.task, nil
+	return result, nil
 }
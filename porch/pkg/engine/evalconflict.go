@@ -0,0 +1,262 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// EvalConflictPolicy decides what happens when the eval tasks of a package revision
+// set the same resource field to different values.
+//
+// Eval tasks are applied in task order, each to the output of the previous one.
+// A field conflicts when it was changed by an earlier eval task and a later eval
+// task changes it again to a different value (removing a field counts as a change).
+// Changes made by other tasks, such as clone or render, are not considered.
+type EvalConflictPolicy string
+
+const (
+	// EvalConflictPolicyError fails the later eval task. This is the default.
+	EvalConflictPolicyError EvalConflictPolicy = "error"
+	// EvalConflictPolicyFirstWins keeps the value set by the earliest eval task.
+	EvalConflictPolicyFirstWins EvalConflictPolicy = "first-wins"
+	// EvalConflictPolicyLastWins keeps the value set by the latest eval task.
+	EvalConflictPolicyLastWins EvalConflictPolicy = "last-wins"
+)
+
+// ParseEvalConflictPolicy validates the name of an eval conflict policy.
+func ParseEvalConflictPolicy(s string) (EvalConflictPolicy, error) {
+	switch p := EvalConflictPolicy(s); p {
+	case EvalConflictPolicyError, EvalConflictPolicyFirstWins, EvalConflictPolicyLastWins:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown eval conflict policy %q; must be one of %q, %q or %q",
+			s, EvalConflictPolicyError, EvalConflictPolicyFirstWins, EvalConflictPolicyLastWins)
+	}
+}
+
+// EvalConflictError is returned when two eval tasks set a resource field to different values
+// and the conflict policy is EvalConflictPolicyError.
+type EvalConflictError struct {
+	Resource         string
+	Field            string
+	Function         string
+	PreviousFunction string
+}
+
+func (e *EvalConflictError) Error() string {
+	return fmt.Sprintf("function %q conflicts with function %q: both change field %s of resource %s",
+		e.Function, e.PreviousFunction, e.Field, e.Resource)
+}
+
+// evalFieldTracker records the resource fields changed by the eval tasks of a
+// package revision and resolves conflicts between them.
+type evalFieldTracker struct {
+	policy EvalConflictPolicy
+	fields map[string]evalFieldChange
+}
+
+type evalFieldChange struct {
+	function string
+	resource string
+	path     []string
+	value    string
+	present  bool
+}
+
+func newEvalFieldTracker(policy EvalConflictPolicy) *evalFieldTracker {
+	if policy == "" {
+		policy = EvalConflictPolicyError
+	}
+	return &evalFieldTracker{
+		policy: policy,
+		fields: map[string]evalFieldChange{},
+	}
+}
+
+// merge records the changes made by the function between input and output and
+// returns the output with conflicts resolved according to the policy.
+func (t *evalFieldTracker) merge(function string, input, output repository.PackageResources) (repository.PackageResources, error) {
+	before, err := flattenPackageResources(input)
+	if err != nil {
+		return repository.PackageResources{}, err
+	}
+	after, err := flattenPackageResources(output)
+	if err != nil {
+		return repository.PackageResources{}, err
+	}
+
+	var changes []evalFieldChange
+	for k, a := range after {
+		if b, found := before[k]; !found || b.value != a.value {
+			changes = append(changes, a)
+		}
+	}
+	for k, b := range before {
+		if _, found := after[k]; !found {
+			b.value, b.present = "", false
+			changes = append(changes, b)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return fieldKey(changes[i].resource, changes[i].path) < fieldKey(changes[j].resource, changes[j].path)
+	})
+
+	var reverts []evalFieldChange
+	for _, change := range changes {
+		change.function = function
+		key := fieldKey(change.resource, change.path)
+		previous, found := t.fields[key]
+		if !found || (previous.present == change.present && previous.value == change.value) {
+			t.fields[key] = change
+			continue
+		}
+
+		switch t.policy {
+		case EvalConflictPolicyLastWins:
+			t.fields[key] = change
+		case EvalConflictPolicyFirstWins:
+			reverts = append(reverts, previous)
+		default:
+			return repository.PackageResources{}, &EvalConflictError{
+				Resource:         change.resource,
+				Field:            strings.Join(change.path, "."),
+				Function:         function,
+				PreviousFunction: previous.function,
+			}
+		}
+	}
+
+	if len(reverts) == 0 {
+		return output, nil
+	}
+	return revertFields(output, reverts)
+}
+
+// revertFields sets the given fields of the resources back to the recorded values.
+func revertFields(resources repository.PackageResources, fields []evalFieldChange) (repository.PackageResources, error) {
+	pr := &packageReader{
+		input: resources,
+		extra: map[string]string{},
+	}
+	nodes, err := pr.Read()
+	if err != nil {
+		return repository.PackageResources{}, err
+	}
+	byID := map[string]*yaml.RNode{}
+	for _, node := range nodes {
+		byID[resourceID(node)] = node
+	}
+
+	for _, f := range fields {
+		node, found := byID[f.resource]
+		if !found {
+			return repository.PackageResources{}, fmt.Errorf("cannot keep field %s of resource %s set by function %q: the resource was removed",
+				strings.Join(f.path, "."), f.resource, f.function)
+		}
+		parentPath, field := f.path[:len(f.path)-1], f.path[len(f.path)-1]
+		if !f.present {
+			if _, err := node.Pipe(yaml.Lookup(parentPath...), yaml.Clear(field)); err != nil {
+				return repository.PackageResources{}, err
+			}
+			continue
+		}
+		value, err := yaml.Parse(f.value)
+		if err != nil {
+			return repository.PackageResources{}, err
+		}
+		if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, parentPath...), yaml.SetField(field, value)); err != nil {
+			return repository.PackageResources{}, err
+		}
+	}
+
+	result := repository.PackageResources{
+		Contents: map[string]string{},
+	}
+	if err := (&packageWriter{output: result}).Write(nodes); err != nil {
+		return repository.PackageResources{}, err
+	}
+	for k, v := range pr.extra {
+		result.Contents[k] = v
+	}
+	return result, nil
+}
+
+// flattenPackageResources returns the leaf fields of all resources of the package,
+// keyed by resource and field path. Sequences are treated as leaves.
+func flattenPackageResources(resources repository.PackageResources) (map[string]evalFieldChange, error) {
+	pr := &packageReader{
+		input: resources,
+		extra: map[string]string{},
+	}
+	nodes, err := pr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]evalFieldChange{}
+	for _, node := range nodes {
+		id := resourceID(node)
+		node = node.Copy()
+		for _, a := range bookkeepingAnnotations {
+			if _, err := node.Pipe(yaml.ClearAnnotation(a)); err != nil {
+				return nil, err
+			}
+		}
+		if err := flattenNode(id, nil, node, fields); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func flattenNode(id string, path []string, node *yaml.RNode, fields map[string]evalFieldChange) error {
+	if node.YNode().Kind == yaml.MappingNode {
+		return node.VisitFields(func(field *yaml.MapNode) error {
+			childPath := append(append([]string{}, path...), field.Key.YNode().Value)
+			return flattenNode(id, childPath, field.Value, fields)
+		})
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	value, err := node.String()
+	if err != nil {
+		return err
+	}
+	fields[fieldKey(id, path)] = evalFieldChange{
+		resource: id,
+		path:     path,
+		value:    value,
+		present:  true,
+	}
+	return nil
+}
+
+// resourceID identifies a resource by file and name. The namespace is not part
+// of the identity because functions commonly change it.
+func resourceID(node *yaml.RNode) string {
+	return pkgcontents.PathOf(node) + ": " + strings.Join([]string{node.GetApiVersion(), node.GetKind(), node.GetName()}, "/")
+}
+
+func fieldKey(resource string, path []string) string {
+	return resource + "\x00" + strings.Join(path, "\x00")
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func TestEvalConflictPolicy(t *testing.T) {
+	input := repository.PackageResources{
+		Contents: map[string]string{
+			"configmap.yaml": strings.TrimSpace(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: original
+data:
+  key: value
+`),
+		},
+	}
+
+	setNamespace := func(namespace string, conflicts *evalFieldTracker) *evalFunctionMutation {
+		return &evalFunctionMutation{
+			runtime: kpt.NewSimpleFunctionRuntime(),
+			task: &api.Task{
+				Type: api.TaskTypeEval,
+				Eval: &api.FunctionEvalTaskSpec{
+					Image:     "gcr.io/kpt-fn/set-namespace:v0.4.1",
+					ConfigMap: map[string]string{"namespace": namespace},
+				},
+			},
+			conflicts: conflicts,
+		}
+	}
+
+	testCases := map[string]struct {
+		policy        EvalConflictPolicy
+		wantNamespace string
+		wantConflict  bool
+	}{
+		"error": {
+			policy:       EvalConflictPolicyError,
+			wantConflict: true,
+		},
+		"first wins": {
+			policy:        EvalConflictPolicyFirstWins,
+			wantNamespace: "namespace: first",
+		},
+		"last wins": {
+			policy:        EvalConflictPolicyLastWins,
+			wantNamespace: "namespace: second",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			conflicts := newEvalFieldTracker(tc.policy)
+
+			first, _, err := setNamespace("first", conflicts).Apply(context.Background(), input)
+			if err != nil {
+				t.Fatalf("first eval failed: %v", err)
+			}
+			second, _, err := setNamespace("second", conflicts).Apply(context.Background(), first)
+
+			if tc.wantConflict {
+				var conflictErr *EvalConflictError
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("expected conflict error, got %v", err)
+				}
+				if got, want := conflictErr.Field, "metadata.namespace"; got != want {
+					t.Errorf("unexpected conflicting field: got %q, want %q", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("second eval failed: %v", err)
+			}
+			if got := second.Contents["configmap.yaml"]; !strings.Contains(got, tc.wantNamespace) {
+				t.Errorf("expected %q in result, got:\n%s", tc.wantNamespace, got)
+			}
+		})
+	}
+}
+
+func TestEvalConflictPolicySameValue(t *testing.T) {
+	input := repository.PackageResources{
+		Contents: map[string]string{
+			"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+		},
+	}
+	output := repository.PackageResources{
+		Contents: map[string]string{
+			"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: same\n",
+		},
+	}
+
+	conflicts := newEvalFieldTracker(EvalConflictPolicyError)
+	if _, err := conflicts.merge("first", input, output); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := conflicts.merge("second", input, output); err != nil {
+		t.Errorf("setting a field to the same value must not conflict: %v", err)
+	}
+}
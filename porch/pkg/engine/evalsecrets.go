@@ -0,0 +1,241 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// redactedSecret replaces secret values in the errors of eval tasks.
+const redactedSecret = "<redacted>"
+
+// ConfigSecretError is returned when a Secret referenced by the function config of an
+// eval task cannot be resolved.
+type ConfigSecretError struct {
+	// Key is the function config key the Secret is referenced for.
+	Key    string
+	Secret string
+	Reason string
+}
+
+func (e *ConfigSecretError) Error() string {
+	return fmt.Sprintf("cannot resolve secret %q for function config key %q: %s", e.Secret, e.Key, e.Reason)
+}
+
+// SecretLeakError is returned when the output of a function contains the value of a
+// Secret referenced by its function config. The output isn't stored.
+type SecretLeakError struct {
+	File string
+	// Key is the function config key of the leaked Secret value.
+	Key string
+}
+
+func (e *SecretLeakError) Error() string {
+	return fmt.Sprintf("function output %q contains the secret value of function config key %q", e.File, e.Key)
+}
+
+// configSecretResolver resolves the Secrets referenced by eval tasks of package
+// revisions in namespace.
+type configSecretResolver struct {
+	resolver  ReferenceResolver
+	namespace string
+	// allowedNamespaces are the namespaces whose package revisions may reference Secrets.
+	allowedNamespaces []string
+}
+
+func (cad *cadEngine) configSecretResolver(namespace string) *configSecretResolver {
+	return &configSecretResolver{
+		resolver:          cad.referenceResolver,
+		namespace:         namespace,
+		allowedNamespaces: cad.functionSecretNamespaces,
+	}
+}
+
+// resolve returns the secret values of the refs, by function config key.
+func (r *configSecretResolver) resolve(ctx context.Context, refs map[string]api.SecretKeyRef) (map[string]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if r == nil || r.resolver == nil {
+		return nil, fmt.Errorf("function config secrets are not supported")
+	}
+	allowed := false
+	for _, namespace := range r.allowedNamespaces {
+		if namespace == r.namespace {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("package revisions of namespace %q may not reference secrets in function config", r.namespace)
+	}
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := map[string]string{}
+	for _, key := range keys {
+		ref := refs[key]
+		var secret corev1.Secret
+		if err := r.resolver.ResolveReference(ctx, r.namespace, ref.Name, &secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, &ConfigSecretError{Key: key, Secret: ref.Name, Reason: "secret not found"}
+			}
+			return nil, fmt.Errorf("cannot read secret %q for function config key %q: %w", ref.Name, key, err)
+		}
+		value, found := secret.Data[ref.Key]
+		if !found {
+			return nil, &ConfigSecretError{Key: key, Secret: ref.Name, Reason: fmt.Sprintf("secret has no key %q", ref.Key)}
+		}
+		if len(value) == 0 {
+			return nil, &ConfigSecretError{Key: key, Secret: ref.Name, Reason: fmt.Sprintf("value of key %q is empty", ref.Key)}
+		}
+		values[key] = string(value)
+	}
+	return values, nil
+}
+
+// setConfigSecrets sets the secret values at their dot-separated field paths in the
+// function config.
+func setConfigSecrets(config *yaml.RNode, values map[string]string) error {
+	for key, value := range values {
+		fields := strings.Split(key, ".")
+		parent, err := config.Pipe(yaml.LookupCreate(yaml.MappingNode, fields[:len(fields)-1]...))
+		if err != nil {
+			return fmt.Errorf("cannot set function config key %q: %w", key, err)
+		}
+		if err := parent.PipeE(yaml.SetField(fields[len(fields)-1], yaml.NewStringRNode(value))); err != nil {
+			return fmt.Errorf("cannot set function config key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// checkSecretsNotLeaked fails if a secret value is found in a file of the function
+// output that didn't already contain it before.
+func checkSecretsNotLeaked(input, output repository.PackageResources, values map[string]string) error {
+	files := make([]string, 0, len(output.Contents))
+	for file := range output.Contents {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		for key, value := range values {
+			if strings.Contains(output.Contents[file], value) && !strings.Contains(input.Contents[file], value) {
+				return &SecretLeakError{File: file, Key: key}
+			}
+		}
+	}
+	return nil
+}
+
+// redactSecrets removes the secret values from err, which may be reported to the
+// client or stored in a task result.
+func redactSecrets(err error, values map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	redacted := message
+	for _, value := range values {
+		redacted = strings.ReplaceAll(redacted, value, redactedSecret)
+	}
+	if redacted == message {
+		return err
+	}
+	return errors.New(redacted)
+}
+
+type requestSecretsKey struct{}
+
+// requestSecrets collects the secret values resolved for the eval tasks of a request.
+// They are redacted from the results of every function the request runs, including the
+// render which follows the tasks, as the results are stored in the package revision.
+type requestSecrets struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+// withRequestSecrets returns a context collecting the secret values resolved for the
+// tasks of the request.
+func withRequestSecrets(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(requestSecretsKey{}).(*requestSecrets); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, requestSecretsKey{}, &requestSecrets{values: map[string]bool{}})
+}
+
+// addRequestSecrets records secret values resolved for a task of the request.
+func addRequestSecrets(ctx context.Context, values map[string]string) {
+	secrets, ok := ctx.Value(requestSecretsKey{}).(*requestSecrets)
+	if !ok {
+		return
+	}
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	for _, value := range values {
+		secrets.values[value] = true
+	}
+}
+
+// redactFunctionResults returns the results with the secret values, and the secret
+// values resolved for the tasks of the request, replaced.
+func redactFunctionResults(ctx context.Context, results []api.FunctionResult, values map[string]string) []api.FunctionResult {
+	var all []string
+	for _, value := range values {
+		all = append(all, value)
+	}
+	if secrets, ok := ctx.Value(requestSecretsKey{}).(*requestSecrets); ok {
+		secrets.mu.Lock()
+		for value := range secrets.values {
+			all = append(all, value)
+		}
+		secrets.mu.Unlock()
+	}
+	if len(all) == 0 || len(results) == 0 {
+		return results
+	}
+
+	redact := func(s string) string {
+		for _, value := range all {
+			s = strings.ReplaceAll(s, value, redactedSecret)
+		}
+		return s
+	}
+	redacted := make([]api.FunctionResult, len(results))
+	for i, result := range results {
+		result.Message = redact(result.Message)
+		result.Resource = redact(result.Resource)
+		result.Field = redact(result.Field)
+		result.File = redact(result.File)
+		redacted[i] = result
+	}
+	return redacted
+}
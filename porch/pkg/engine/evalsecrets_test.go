@@ -0,0 +1,229 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// fakeSecrets resolves Secrets of the default namespace.
+type fakeSecrets map[string]map[string]string
+
+func (f fakeSecrets) ResolveReference(ctx context.Context, namespace, name string, result Object) error {
+	data, found := f[name]
+	if namespace != "default" || !found {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	secret := result.(*corev1.Secret)
+	secret.Data = map[string][]byte{}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return nil
+}
+
+// configRecordingRuntime runs functions that record their function config. Functions
+// with the image "leak" add the function config to their output; functions with the
+// image "fail" fail with the function config in their error; functions with the image
+// "echo" report the function config in the message of a result.
+type configRecordingRuntime struct {
+	configs []string
+}
+
+func (r *configRecordingRuntime) GetRunner(ctx context.Context, fn *v1.Function) (fn.FunctionRunner, error) {
+	return &configRecordingRunner{recorder: r, image: fn.Image}, nil
+}
+
+type configRecordingRunner struct {
+	recorder *configRecordingRuntime
+	image    string
+}
+
+func (r *configRecordingRunner) Run(in io.Reader, out io.Writer) error {
+	input, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	rl, err := yaml.Parse(string(input))
+	if err != nil {
+		return err
+	}
+	config := rl.Field("functionConfig").Value
+	r.recorder.configs = append(r.recorder.configs, config.MustString())
+
+	switch r.image {
+	case "fail":
+		return fmt.Errorf("bad config: %s", config.MustString())
+	case "leak":
+		items := rl.Field("items").Value
+		if err := items.PipeE(yaml.Append(config.YNode())); err != nil {
+			return err
+		}
+	case "echo":
+		result := yaml.NewMapRNode(&map[string]string{"message": "config: " + config.MustString(), "severity": "info"})
+		if err := rl.PipeE(yaml.SetField("results", yaml.NewListRNode())); err != nil {
+			return err
+		}
+		if err := rl.Field("results").Value.PipeE(yaml.Append(result.YNode())); err != nil {
+			return err
+		}
+	}
+	_, err = out.Write([]byte(rl.MustString()))
+	return err
+}
+
+func TestEvalConfigSecrets(t *testing.T) {
+	ctx := context.Background()
+	secrets := fakeSecrets{"generator": {"token": "s3cr3t-t0ken"}}
+	resources := repository.PackageResources{Contents: map[string]string{
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: value\n",
+	}}
+
+	newEval := func(image string, namespaces []string, spec api.FunctionEvalTaskSpec) (*evalFunctionMutation, *configRecordingRuntime) {
+		recorder := &configRecordingRuntime{}
+		spec.Image = image
+		cad := &cadEngine{referenceResolver: secrets, functionSecretNamespaces: namespaces}
+		return &evalFunctionMutation{
+			runtime: recorder,
+			task:    &api.Task{Type: api.TaskTypeEval, Eval: &spec},
+			secrets: cad.configSecretResolver("default"),
+		}, recorder
+	}
+	tokenRef := map[string]api.SecretKeyRef{"token": {Name: "generator", Key: "token"}}
+
+	t.Run("config map", func(t *testing.T) {
+		eval, recorder := newEval("generate", []string{"default"}, api.FunctionEvalTaskSpec{
+			ConfigMap:        map[string]string{"name": "app"},
+			ConfigSecretRefs: tokenRef,
+		})
+		_, task, err := eval.Apply(ctx, resources)
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if len(recorder.configs) != 1 || !strings.Contains(recorder.configs[0], "token: s3cr3t-t0ken") {
+			t.Errorf("secret wasn't set in the function config: %v", recorder.configs)
+		}
+		if _, found := task.Eval.ConfigMap["token"]; found {
+			t.Errorf("secret was stored in the task: %v", task.Eval.ConfigMap)
+		}
+	})
+
+	t.Run("config field", func(t *testing.T) {
+		eval, recorder := newEval("generate", []string{"default"}, api.FunctionEvalTaskSpec{
+			Config:           runtime.RawExtension{Raw: []byte(`{"apiVersion":"fn.kpt.dev/v1alpha1","kind":"Generator","spec":{"name":"app"}}`)},
+			ConfigSecretRefs: map[string]api.SecretKeyRef{"spec.auth.token": {Name: "generator", Key: "token"}},
+		})
+		_, task, err := eval.Apply(ctx, resources)
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if len(recorder.configs) != 1 {
+			t.Fatalf("function was evaluated %d times; want 1", len(recorder.configs))
+		}
+		config := yaml.MustParse(recorder.configs[0])
+		if token, err := config.Pipe(yaml.Lookup("spec", "auth", "token")); err != nil || token == nil || yaml.GetValue(token) != "s3cr3t-t0ken" {
+			t.Errorf("secret wasn't set in the function config:\n%s", recorder.configs[0])
+		}
+		if strings.Contains(string(task.Eval.Config.Raw), "s3cr3t") {
+			t.Errorf("secret was stored in the task: %s", task.Eval.Config.Raw)
+		}
+	})
+
+	t.Run("leaked to output", func(t *testing.T) {
+		eval, _ := newEval("leak", []string{"default"}, api.FunctionEvalTaskSpec{ConfigSecretRefs: tokenRef})
+		_, _, err := eval.Apply(ctx, resources)
+		var leak *SecretLeakError
+		if !errors.As(err, &leak) {
+			t.Fatalf("Apply returned %v; want a SecretLeakError", err)
+		}
+		if leak.Key != "token" {
+			t.Errorf("unexpected leaked key %q", leak.Key)
+		}
+	})
+
+	t.Run("redacted error", func(t *testing.T) {
+		eval, _ := newEval("fail", []string{"default"}, api.FunctionEvalTaskSpec{ConfigSecretRefs: tokenRef})
+		_, _, err := eval.Apply(ctx, resources)
+		if err == nil {
+			t.Fatalf("Apply of a failing function succeeded")
+		}
+		if strings.Contains(err.Error(), "s3cr3t-t0ken") || !strings.Contains(err.Error(), redactedSecret) {
+			t.Errorf("secret wasn't redacted from the error: %v", err)
+		}
+	})
+
+	t.Run("redacted results", func(t *testing.T) {
+		eval, _ := newEval("echo", []string{"default"}, api.FunctionEvalTaskSpec{ConfigSecretRefs: tokenRef})
+		functions := &functionResults{}
+		requestCtx := withRequestSecrets(ctx)
+		if _, _, err := eval.Apply(withTaskFunctionResults(requestCtx, functions), resources); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		results := functions.list()
+		if len(results) != 1 {
+			t.Fatalf("got %d function results; want 1", len(results))
+		}
+		if message := results[0].Message; strings.Contains(message, "s3cr3t-t0ken") || !strings.Contains(message, redactedSecret) {
+			t.Errorf("secret wasn't redacted from the function result: %q", message)
+		}
+
+		// The render following the task redacts the secret values of the request too.
+		rendered := redactFunctionResults(requestCtx, []api.FunctionResult{{Function: "render", Message: "token is s3cr3t-t0ken"}}, nil)
+		if message := rendered[0].Message; message != "token is "+redactedSecret {
+			t.Errorf("secret wasn't redacted from the render result: %q", message)
+		}
+	})
+
+	for _, tc := range []struct {
+		name       string
+		namespaces []string
+		refs       map[string]api.SecretKeyRef
+		wantSecret bool
+	}{
+		{"missing secret", []string{"default"}, map[string]api.SecretKeyRef{"token": {Name: "missing", Key: "token"}}, true},
+		{"missing key", []string{"default"}, map[string]api.SecretKeyRef{"token": {Name: "generator", Key: "password"}}, true},
+		{"namespace not allowed", []string{"platform"}, tokenRef, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			eval, recorder := newEval("generate", tc.namespaces, api.FunctionEvalTaskSpec{ConfigSecretRefs: tc.refs})
+			_, _, err := eval.Apply(ctx, resources)
+			if err == nil {
+				t.Fatalf("Apply succeeded")
+			}
+			var secretErr *ConfigSecretError
+			if got := errors.As(err, &secretErr); got != tc.wantSecret {
+				t.Errorf("Apply returned %v; want a ConfigSecretError: %t", err, tc.wantSecret)
+			}
+			if len(recorder.configs) != 0 {
+				t.Errorf("function was evaluated")
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// When external sources are restricted, package revisions can only be built from the
+// registered repositories and the configured function image mirrors: the git and OCI
+// upstreams of clone and update tasks must be stored in repositories registered in the
+// namespace of the package revision, and every function must be one of the allowed
+// function images.
+
+// PolicyViolationError is returned when a package revision would fetch from a source
+// the policies of the Porch server forbid.
+//
+// PolicyViolationError is an API status error: it is returned to clients as a Forbidden
+// status.
+type PolicyViolationError struct {
+	// Policy is the name of the violated policy.
+	Policy string
+	// Source describes the forbidden source.
+	Source string
+	// Reason explains why the source is forbidden.
+	Reason string
+}
+
+// ExternalSourcesPolicy is the policy restricting package revisions to the registered
+// repositories and function image mirrors.
+const ExternalSourcesPolicy = "restrict-external-sources"
+
+var _ apierrors.APIStatus = &PolicyViolationError{}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("%s is forbidden by the %s policy: %s", e.Source, e.Policy, e.Reason)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PolicyViolationError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisions",
+		},
+	}
+}
+
+// checkExternalSources returns a PolicyViolationError if external sources are restricted
+// and one of the tasks, or the functions of the api.PipelineAppendAnnotation annotation,
+// would fetch from outside the repositories registered in the namespace and the function
+// image mirrors.
+func (cad *cadEngine) checkExternalSources(ctx context.Context, namespace string, tasks []api.Task, annotations map[string]string) error {
+	policy := cad.externalSourcePolicy(namespace)
+	if policy == nil {
+		return nil
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		var upstream *api.UpstreamPackage
+		switch {
+		case task.Type == api.TaskTypeClone && task.Clone != nil:
+			upstream = &task.Clone.Upstream
+		case task.Type == api.TaskTypeUpdate && task.Update != nil:
+			upstream = &task.Update.Upstream
+		case task.Type == api.TaskTypeEval && task.Eval != nil && !isLegacyRenderTask(task):
+			if err := cad.checkMirroredImage(ctx, task.Eval.Image, fmt.Sprintf("eval task %d", i)); err != nil {
+				return err
+			}
+		}
+		if upstream == nil {
+			continue
+		}
+		if err := policy.checkUpstream(ctx, upstream, fmt.Sprintf("%s task %d", task.Type, i)); err != nil {
+			return err
+		}
+	}
+
+	if value, found := annotations[api.PipelineAppendAnnotation]; found {
+		functions, err := parsePipelineAppend(value)
+		if err != nil {
+			return err
+		}
+		for _, function := range functions {
+			if err := cad.checkMirroredImage(ctx, function.Image, "the "+api.PipelineAppendAnnotation+" annotation"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkMirroredImage returns a PolicyViolationError if the function image isn't one of
+// the allowed function images, which are the mirrors functions are pulled from.
+func (cad *cadEngine) checkMirroredImage(ctx context.Context, image, source string) error {
+	if cad.allowedFunctionImages.allows(ctx, image) {
+		return nil
+	}
+	return &PolicyViolationError{
+		Policy: ExternalSourcesPolicy,
+		Source: fmt.Sprintf("function %q of %s", image, source),
+		Reason: "functions must be pulled from the allowed function images",
+	}
+}
+
+// externalSourcePolicy restricts the git and OCI upstreams of package revisions to the
+// repositories registered in their namespace. A nil policy allows all upstreams.
+type externalSourcePolicy struct {
+	repositoryLister RepositoryLister
+	namespace        string
+}
+
+// externalSourcePolicy returns the policy for upstreams of package revisions of the
+// namespace, or nil if external sources aren't restricted.
+func (cad *cadEngine) externalSourcePolicy(namespace string) *externalSourcePolicy {
+	if !cad.restrictExternalSources {
+		return nil
+	}
+	return &externalSourcePolicy{repositoryLister: cad.repositoryLister, namespace: namespace}
+}
+
+// checkUpstream returns a PolicyViolationError if the upstream is a git repository or OCI
+// image which isn't stored in one of the registered repositories. Upstream references
+// are package revisions of registered repositories, and are always allowed.
+func (p *externalSourcePolicy) checkUpstream(ctx context.Context, upstream *api.UpstreamPackage, source string) error {
+	if p == nil || (upstream.Git == nil && upstream.Oci == nil) {
+		return nil
+	}
+	var repositories []configapi.Repository
+	if p.repositoryLister != nil {
+		var err error
+		if repositories, err = p.repositoryLister.ListRepositories(ctx, p.namespace); err != nil {
+			return fmt.Errorf("cannot list the repositories of namespace %q: %w", p.namespace, err)
+		}
+	}
+	for i := range repositories {
+		if storesUpstream(&repositories[i], upstream) {
+			return nil
+		}
+	}
+	var description string
+	if upstream.Git != nil {
+		description = fmt.Sprintf("git repository %q of %s", upstream.Git.Repo, source)
+	} else {
+		description = fmt.Sprintf("oci image %q of %s", upstream.Oci.Image, source)
+	}
+	return &PolicyViolationError{
+		Policy: ExternalSourcesPolicy,
+		Source: description,
+		Reason: fmt.Sprintf("upstreams must be stored in repositories registered in namespace %q", p.namespace),
+	}
+}
+
+// storesUpstream returns true if the git or OCI upstream is stored in the repository: a
+// git upstream must be in the directory of the repository, and an OCI image in its
+// registry.
+func storesUpstream(repositoryObj *configapi.Repository, upstream *api.UpstreamPackage) bool {
+	switch {
+	case upstream.Git != nil && repositoryObj.Spec.Git != nil:
+		return git.NormalizeRepositoryURL(upstream.Git.Repo) == git.NormalizeRepositoryURL(repositoryObj.Spec.Git.Repo) &&
+			git.PackageInDirectory(strings.Trim(upstream.Git.Directory, "/"), repositoryObj.Spec.Git.Directory)
+	case upstream.Oci != nil && repositoryObj.Spec.Oci != nil:
+		return hasAddressPrefix(upstream.Oci.Image, repositoryObj.Spec.Oci.Registry)
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// fakeRepositoryLister lists the repositories by namespace.
+type fakeRepositoryLister map[string][]configapi.Repository
+
+func (f fakeRepositoryLister) ListRepositories(ctx context.Context, namespace string) ([]configapi.Repository, error) {
+	return f[namespace], nil
+}
+
+func TestRestrictedExternalSources(t *testing.T) {
+	ctx := context.Background()
+	const mirror = "mirror.example.com/kpt-fn/*"
+
+	if _, err := NewCaDEngine(WithRestrictedExternalSources()); err == nil {
+		t.Errorf("restricting external sources without allowed function images succeeded")
+	}
+	lister := fakeRepositoryLister{"default": {
+		{Spec: configapi.RepositorySpec{Git: &configapi.GitRepository{Repo: "https://github.com/example/blueprints.git", Directory: "/catalog"}}},
+		{Spec: configapi.RepositorySpec{Oci: &configapi.OciRepository{Registry: "registry.example.com/blueprints"}}},
+	}}
+	engine, err := NewCaDEngine(WithRestrictedExternalSources(), WithAllowedFunctionImages(AllowedFunctionImages{mirror}), WithRepositoryLister(lister))
+	if err != nil {
+		t.Fatalf("NewCaDEngine failed: %v", err)
+	}
+	cad := engine.(*cadEngine)
+
+	eval := func(image string) api.Task {
+		return api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: image}}
+	}
+	gitUpstream := func(repo, directory string) api.UpstreamPackage {
+		return api.UpstreamPackage{Type: api.RepositoryTypeGit, Git: &api.GitPackage{Repo: repo, Ref: "main", Directory: directory}}
+	}
+	ociUpstream := func(image string) api.UpstreamPackage {
+		return api.UpstreamPackage{Type: api.RepositoryTypeOCI, Oci: &api.OciPackage{Image: image}}
+	}
+	clone := func(upstream api.UpstreamPackage) []api.Task {
+		return []api.Task{{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: upstream}}}
+	}
+	testCases := map[string]struct {
+		namespace   string
+		tasks       []api.Task
+		annotations map[string]string
+		wantBlocked bool
+	}{
+		"unregistered git clone task": {
+			tasks:       clone(gitUpstream("https://github.com/other/blueprints.git", "catalog/app")),
+			wantBlocked: true,
+		},
+		"git clone task outside the registered directory": {
+			tasks:       clone(gitUpstream("https://github.com/example/blueprints.git", "app")),
+			wantBlocked: true,
+		},
+		"registered git clone task": {
+			tasks: clone(gitUpstream("git@github.com:example/blueprints", "/catalog/app")),
+		},
+		"git clone task registered in another namespace": {
+			namespace:   "other",
+			tasks:       clone(gitUpstream("https://github.com/example/blueprints.git", "catalog/app")),
+			wantBlocked: true,
+		},
+		"unregistered oci clone task": {
+			tasks:       clone(ociUpstream("registry.example.com/blueprints-fork/app:v1")),
+			wantBlocked: true,
+		},
+		"registered oci clone task": {
+			tasks: clone(ociUpstream("registry.example.com/blueprints/app:v1")),
+		},
+		"unregistered git update task": {
+			tasks: []api.Task{{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{
+				Upstream: gitUpstream("https://github.com/other/blueprints.git", "catalog/app"),
+			}}},
+			wantBlocked: true,
+		},
+		"registered git update task": {
+			tasks: []api.Task{{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{
+				Upstream: gitUpstream("https://github.com/example/blueprints.git", "catalog/app"),
+			}}},
+		},
+		"unmirrored eval image": {
+			tasks:       []api.Task{eval("gcr.io/kpt-fn/set-labels:v0.1.5")},
+			wantBlocked: true,
+		},
+		"unmirrored appended function": {
+			annotations: map[string]string{api.PipelineAppendAnnotation: "- image: gcr.io/kpt-fn/kubeval:v0.3"},
+			wantBlocked: true,
+		},
+		"registered upstream and mirrored function": {
+			tasks: []api.Task{
+				{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: api.UpstreamPackage{
+					UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-app-v1"},
+				}}},
+				eval("mirror.example.com/kpt-fn/set-labels:v0.1.5"),
+			},
+			annotations: map[string]string{api.PipelineAppendAnnotation: "- image: mirror.example.com/kpt-fn/kubeval:v0.3"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			namespace := tc.namespace
+			if namespace == "" {
+				namespace = "default"
+			}
+			err := cad.checkExternalSources(ctx, namespace, tc.tasks, tc.annotations)
+			if !tc.wantBlocked {
+				if err != nil {
+					t.Errorf("checkExternalSources failed: %v", err)
+				}
+				return
+			}
+			var violation *PolicyViolationError
+			if !errors.As(err, &violation) {
+				t.Fatalf("checkExternalSources returned %v; want a PolicyViolationError", err)
+			}
+			if violation.Policy != ExternalSourcesPolicy {
+				t.Errorf("unexpected policy %q", violation.Policy)
+			}
+			if !apierrors.IsForbidden(err) {
+				t.Errorf("policy violation isn't reported as Forbidden")
+			}
+		})
+	}
+}
+
+func TestRestrictedExternalSourcesAtFetch(t *testing.T) {
+	policy := &externalSourcePolicy{
+		repositoryLister: fakeRepositoryLister{"default": {
+			{Spec: configapi.RepositorySpec{Git: &configapi.GitRepository{Repo: "https://github.com/example/blueprints.git"}}},
+		}},
+		namespace: "default",
+	}
+	upstream := api.UpstreamPackage{Type: api.RepositoryTypeGit, Git: &api.GitPackage{Repo: "https://github.com/other/blueprints.git", Ref: "main"}}
+
+	// The mutations have no repository opener or reference resolver; fetching would panic.
+	clone := &clonePackageMutation{
+		task:            &api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: upstream}},
+		namespace:       "default",
+		externalSources: policy,
+	}
+	if _, _, err := clone.Apply(context.Background(), repository.PackageResources{}); !apierrors.IsForbidden(err) {
+		t.Errorf("clone: expected a forbidden error, got %v", err)
+	}
+
+	update := &updatePackageMutation{
+		cloneTask: &api.Task{
+			Type:  api.TaskTypeClone,
+			Clone: &api.PackageCloneTaskSpec{Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-0123456789abcdef"}}},
+		},
+		updateTask:      &api.Task{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{Upstream: upstream}},
+		namespace:       "default",
+		externalSources: policy,
+	}
+	if _, _, err := update.Apply(context.Background(), repository.PackageResources{}); !apierrors.IsForbidden(err) {
+		t.Errorf("update: expected a forbidden error, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// Implementation of the repository.Package interface for testing.
+type Package struct {
+	Name           string
+	PackageKey     repository.PackageKey
+	Package        *v1alpha1.Package
+	LatestRevision string
+}
+
+var _ repository.Package = &Package{}
+
+func (p *Package) KubeObjectName() string {
+	return p.Name
+}
+
+func (p *Package) Key() repository.PackageKey {
+	return p.PackageKey
+}
+
+func (p *Package) GetPackage() *v1alpha1.Package {
+	return p.Package
+}
+
+func (p *Package) GetLatestRevision() string {
+	return p.LatestRevision
+}
@@ -54,6 +54,10 @@ func (r *Repository) CreatePackage(_ context.Context, pr *v1alpha1.Package) (rep
 	return nil, nil
 }
 
+func (r *Repository) UpdatePackage(_ context.Context, old repository.Package, new *v1alpha1.Package) (repository.Package, error) {
+	return nil, nil
+}
+
 func (r *Repository) DeletePackage(_ context.Context, pr repository.Package) error {
 	return nil
 }
@@ -0,0 +1,134 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// keepFileModes returns the applied resources with the file modes of the base resources
+// if the mutation which produced them didn't set any. Functions and the other mutations
+// which only change contents don't know about file modes; the files they keep keep their
+// modes.
+func keepFileModes(base, applied repository.PackageResources) repository.PackageResources {
+	if applied.FileModes != nil {
+		return applied
+	}
+	for path, mode := range base.FileModes {
+		if _, found := applied.Contents[path]; !found {
+			continue
+		}
+		if applied.FileModes == nil {
+			applied.FileModes = map[string]api.FileMode{}
+		}
+		applied.FileModes[path] = mode
+	}
+	return applied
+}
+
+// copyFileModes returns a copy of the modes which is never nil, so that the result of a
+// mutation starting from it keeps its modes as they are.
+func copyFileModes(modes map[string]api.FileMode) map[string]api.FileMode {
+	result := make(map[string]api.FileMode, len(modes))
+	for path, mode := range modes {
+		result[path] = mode
+	}
+	return result
+}
+
+// validateFileModes checks that the modes are known and are modes of files of the contents.
+func validateFileModes(modes map[string]api.FileMode, contents map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec", "fileModes")
+	for _, path := range sortedFileModePaths(modes) {
+		mode := modes[path]
+		if mode != api.FileModeRegular && mode != api.FileModeExecutable {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key(path), mode, []string{string(api.FileModeRegular), string(api.FileModeExecutable)}))
+			continue
+		}
+		if _, found := contents[path]; !found {
+			allErrs = append(allErrs, field.NotFound(fldPath.Key(path), path))
+		}
+	}
+	return allErrs
+}
+
+// setFileMode sets the mode of the file; regular files aren't listed.
+func setFileMode(modes map[string]api.FileMode, path string, mode api.FileMode) {
+	if mode == api.FileModeRegular {
+		delete(modes, path)
+		return
+	}
+	modes[path] = mode
+}
+
+// fileModePatch returns a patch changing the mode of the file without changing its
+// contents, in the format of git diff.
+func fileModePatch(path string, oldMode, newMode api.FileMode) api.PatchSpec {
+	return api.PatchSpec{
+		File:      path,
+		PatchType: api.PatchTypePatchFile,
+		Contents:  fmt.Sprintf("diff --git a/%s b/%s\nold mode %o\nnew mode %o\n", path, path, gitFileMode(oldMode), gitFileMode(newMode)),
+	}
+}
+
+const (
+	gitRegularFileMode    os.FileMode = 0100644
+	gitExecutableFileMode os.FileMode = 0100755
+)
+
+// gitFileMode returns the mode of a file in git diff.
+func gitFileMode(mode api.FileMode) os.FileMode {
+	if mode == api.FileModeExecutable {
+		return gitExecutableFileMode
+	}
+	return gitRegularFileMode
+}
+
+// fileModeOf returns the mode of a file from its mode in git diff.
+func fileModeOf(mode os.FileMode) (api.FileMode, error) {
+	switch mode {
+	case gitRegularFileMode:
+		return api.FileModeRegular, nil
+	case gitExecutableFileMode:
+		return api.FileModeExecutable, nil
+	default:
+		return "", fmt.Errorf("unsupported file mode %o", mode)
+	}
+}
+
+// fsFileMode returns the permissions a file of the mode is written with.
+func fsFileMode(mode api.FileMode) fs.FileMode {
+	if mode == api.FileModeExecutable {
+		return 0755
+	}
+	return 0644
+}
+
+func sortedFileModePaths(modes map[string]api.FileMode) []string {
+	paths := make([]string, 0, len(modes))
+	for path := range modes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
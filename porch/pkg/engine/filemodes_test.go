@@ -0,0 +1,252 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const setupScript = "hooks/setup.sh"
+
+func TestFileModeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	gogitRepo, err := gogit.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize in-memory git repository: %v", err)
+	}
+	commitPackage(t, gogitRepo, "app", repository.PackageResources{
+		Contents: map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			"configmap.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  replicas: \"1\"\n",
+			setupScript:         "#!/bin/sh\necho setup\n",
+		},
+		FileModes: map[string]api.FileMode{setupScript: api.FileModeExecutable},
+	}, "app/v1")
+	server, err := git.NewRepo(gogitRepo)
+	if err != nil {
+		t.Fatalf("NewRepo failed: %v", err)
+	}
+	addr := startGitServer(t, server)
+
+	repo, err := git.OpenRepository(ctx, "deployments", "default", &configapi.GitRepository{Repo: addr, Branch: "main"}, false, t.TempDir(), git.GitRepositoryOptions{
+		CredentialResolver: &credentialResolver{},
+	})
+	if err != nil {
+		t.Fatalf("OpenRepository failed: %v", err)
+	}
+
+	engine, err := NewCaDEngine()
+	if err != nil {
+		t.Fatalf("NewCaDEngine failed: %v", err)
+	}
+	cad := engine.(*cadEngine)
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+	render := &renderPackageMutation{
+		renderer: kpt.NewRenderer(runnerOptions),
+		runtime:  kpt.NewSimpleFunctionRuntime(),
+	}
+
+	// Clone the package from git, edit its resources and render it.
+	clone := &clonePackageMutation{
+		task: &api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: api.UpstreamPackage{
+			Type: api.RepositoryTypeGit,
+			Git:  &api.GitPackage{Repo: addr, Ref: "app/v1", Directory: "app"},
+		}}},
+		namespace:          "default",
+		name:               "app-copy",
+		credentialResolver: &credentialResolver{},
+	}
+	cloned, _, err := clone.Apply(ctx, repository.PackageResources{})
+	if err != nil {
+		t.Fatalf("Failed to clone package: %v", err)
+	}
+	edited := copyContents(cloned.Contents)
+	edited["configmap.yaml"] = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  replicas: \"3\"\n"
+	edited["hooks/teardown.sh"] = "#!/bin/sh\necho teardown\n"
+	edit := &mutationReplaceResources{newResources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: edited}}}
+
+	draft, err := repo.CreatePackageRevision(ctx, &api.PackageRevision{Spec: api.PackageRevisionSpec{
+		PackageName:    "app-copy",
+		Revision:       "v1",
+		RepositoryName: "deployments",
+		Lifecycle:      api.PackageRevisionLifecycleDraft,
+	}})
+	if err != nil {
+		t.Fatalf("CreatePackageRevision failed: %v", err)
+	}
+	draft = cad.bufferDraft(draft)
+	if err := cad.applyResourceMutations(ctx, draft, repository.PackageResources{}, []mutation{clone, edit, render}); err != nil {
+		t.Fatalf("Failed to apply mutations: %v", err)
+	}
+	rev, err := draft.Close(ctx)
+	if err != nil {
+		t.Fatalf("Failed to close draft: %v", err)
+	}
+
+	// Publish the package revision.
+	draft, err = repo.UpdatePackageRevision(ctx, rev)
+	if err != nil {
+		t.Fatalf("UpdatePackageRevision failed: %v", err)
+	}
+	if err := draft.UpdateLifecycle(ctx, api.PackageRevisionLifecyclePublished); err != nil {
+		t.Fatalf("UpdateLifecycle failed: %v", err)
+	}
+	published, err := draft.Close(ctx)
+	if err != nil {
+		t.Fatalf("Failed to publish package revision: %v", err)
+	}
+
+	resources, err := published.GetResources(ctx)
+	if err != nil {
+		t.Fatalf("GetResources failed: %v", err)
+	}
+	want := map[string]api.FileMode{setupScript: api.FileModeExecutable}
+	if diff := cmp.Diff(want, resources.Spec.FileModes); diff != "" {
+		t.Errorf("unexpected file modes (-want, +got): %s", diff)
+	}
+	if got := resources.Spec.Resources["configmap.yaml"]; !strings.Contains(got, `replicas: "3"`) {
+		t.Errorf("edit wasn't kept:\n%s", got)
+	}
+
+	// Clients reading the repository with git see the executable script.
+	tag, err := gogitRepo.Tag("app-copy/v1")
+	if err != nil {
+		t.Fatalf("Failed to find tag of the published package revision: %v", err)
+	}
+	commit, err := gogitRepo.CommitObject(tag.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read commit of the published package revision: %v", err)
+	}
+	for name, mode := range map[string]filemode.FileMode{
+		"app-copy/" + setupScript:    filemode.Executable,
+		"app-copy/hooks/teardown.sh": filemode.Regular,
+		"app-copy/configmap.yaml":    filemode.Regular,
+	} {
+		file, err := commit.File(name)
+		if err != nil {
+			t.Fatalf("Failed to find %s: %v", name, err)
+		}
+		if file.Mode != mode {
+			t.Errorf("%s has mode %s; want %s", name, file.Mode, mode)
+		}
+	}
+}
+
+func TestReplaceResourcesFileModes(t *testing.T) {
+	ctx := context.Background()
+
+	base := repository.PackageResources{
+		Contents: map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			setupScript:         "#!/bin/sh\necho setup\n",
+			"run.sh":            "#!/bin/sh\necho run\n",
+		},
+		FileModes: map[string]api.FileMode{setupScript: api.FileModeExecutable},
+	}
+	newContents := copyContents(base.Contents)
+	newContents["hooks/teardown.sh"] = "#!/bin/sh\necho teardown\n"
+	replace := &mutationReplaceResources{newResources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{
+		Resources: newContents,
+		FileModes: map[string]api.FileMode{
+			setupScript:         api.FileModeRegular,
+			"run.sh":            api.FileModeExecutable,
+			"hooks/teardown.sh": api.FileModeExecutable,
+		},
+	}}}
+	replaced, task, err := replace.Apply(ctx, base)
+	if err != nil {
+		t.Fatalf("mutationReplaceResources.Apply failed: %v", err)
+	}
+	want := map[string]api.FileMode{"run.sh": api.FileModeExecutable, "hooks/teardown.sh": api.FileModeExecutable}
+	if diff := cmp.Diff(want, replaced.FileModes); diff != "" {
+		t.Errorf("unexpected file modes (-want, +got): %s", diff)
+	}
+
+	// Replaying the recorded patches sets the modes again.
+	replayed, _, err := (&applyPatchMutation{patchTask: task.Patch, task: task}).Apply(ctx, base)
+	if err != nil {
+		t.Fatalf("Failed to replay patches: %v", err)
+	}
+	if diff := cmp.Diff(replaced, replayed); diff != "" {
+		t.Errorf("replayed resources differ (-want, +got): %s", diff)
+	}
+
+	invalid := &mutationReplaceResources{newResources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{
+		Resources: base.Contents,
+		FileModes: map[string]api.FileMode{"missing.sh": api.FileModeExecutable, "run.sh": "0777"},
+	}}}
+	if _, _, err := invalid.Apply(ctx, base); !apierrors.IsInvalid(err) {
+		t.Errorf("replacing resources with invalid file modes returned %v; want Invalid", err)
+	}
+}
+
+func TestFileModesInDirectory(t *testing.T) {
+	resources := repository.PackageResources{
+		Contents: map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			setupScript:         "#!/bin/sh\necho setup\n",
+		},
+		FileModes: map[string]api.FileMode{setupScript: api.FileModeExecutable},
+	}
+	dir := t.TempDir()
+	if err := writeResourcesToDirectory(dir, resources); err != nil {
+		t.Fatalf("writeResourcesToDirectory failed: %v", err)
+	}
+	for name, want := range map[string]os.FileMode{setupScript: 0755, kptfile.KptFileName: 0644} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if got := info.Mode().Perm(); got&0111 != want&0111 {
+			t.Errorf("%s was written with mode %o; want %o", name, got, want)
+		}
+	}
+
+	loaded, err := loadResourcesFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("loadResourcesFromDirectory failed: %v", err)
+	}
+	if diff := cmp.Diff(resources, loaded); diff != "" {
+		t.Errorf("unexpected resources (-want, +got): %s", diff)
+	}
+}
+
+func copyContents(contents map[string]string) map[string]string {
+	result := make(map[string]string, len(contents))
+	for k, v := range contents {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Like archiving, freezing a package is recorded in the metadata of its package
+// revisions. A package is frozen if any of its package revisions is, so a package
+// revision whose metadata failed to update doesn't thaw the package.
+
+// PackageFrozenError is returned when a package revision of a frozen package is created,
+// proposed, published or deleted.
+//
+// PackageFrozenError is an API status error: it is returned to clients as a Conflict
+// status.
+type PackageFrozenError struct {
+	Repository string
+	Package    string
+	Freeze     meta.PackageFreeze
+}
+
+var _ apierrors.APIStatus = &PackageFrozenError{}
+
+func (e *PackageFrozenError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %q of repository %q is frozen", e.Package, e.Repository)
+	if e.Freeze.FrozenBy != "" {
+		fmt.Fprintf(&b, " by %s", e.Freeze.FrozenBy)
+	}
+	if !e.Freeze.FrozenAt.IsZero() {
+		fmt.Fprintf(&b, " since %s", e.Freeze.FrozenAt.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	fmt.Fprintf(&b, ": %s", e.Freeze.Reason)
+	return b.String()
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PackageFrozenError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusConflict,
+		Reason:  metav1.StatusReasonConflict,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packages",
+			Name:  e.Package,
+		},
+	}
+}
+
+// Freeze returns the freeze of the package, or nil if it isn't frozen.
+func (p *Package) Freeze() *meta.PackageFreeze {
+	return p.freeze
+}
+
+// packageFreezes returns the freezes of the frozen packages among the package
+// revisions, by package name.
+func packageFreezes(revisions []*PackageRevision) map[string]*meta.PackageFreeze {
+	freezes := map[string]*meta.PackageFreeze{}
+	for _, rev := range revisions {
+		if !rev.packageRevisionMeta.IsFrozen() {
+			continue
+		}
+		name := rev.repoPackageRevision.Key().Package
+		// The most recent freeze wins, in case updating the metadata of the package
+		// revisions was interrupted.
+		if f := freezes[name]; f == nil || f.FrozenAt.Before(&rev.packageRevisionMeta.Freeze.FrozenAt) {
+			freezes[name] = rev.packageRevisionMeta.Freeze
+		}
+	}
+	return freezes
+}
+
+// checkPackageNotFrozen returns a PackageFrozenError if the package is frozen.
+func (cad *cadEngine) checkPackageNotFrozen(ctx context.Context, repositoryObj *configapi.Repository, packageName string) error {
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return err
+	}
+	if freeze := packageFreezes(revisions)[packageName]; freeze != nil {
+		return &PackageFrozenError{Repository: repositoryObj.Name, Package: packageName, Freeze: *freeze}
+	}
+	return nil
+}
+
+// isFreezingTransition returns true if the update of the package revision changes its
+// lifecycle to one frozen packages can't move to.
+func isFreezingTransition(oldObj, newObj *api.PackageRevision) bool {
+	if oldObj.Spec.Lifecycle == newObj.Spec.Lifecycle {
+		return false
+	}
+	switch newObj.Spec.Lifecycle {
+	case api.PackageRevisionLifecycleProposed, api.PackageRevisionLifecyclePublished:
+		return true
+	default:
+		return false
+	}
+}
+
+// UpdatePackageFreeze freezes the package with the freeze, or thaws it if the freeze is
+// nil. The user freezing the package and the time are recorded with the reason of the
+// freeze.
+func (cad *cadEngine) UpdatePackageFreeze(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *Package, freeze *api.PackageFreeze) (*Package, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::UpdatePackageFreeze", trace.WithAttributes())
+	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: oldPackage.repoPackage.Key().Package})
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := cad.checkRepositorySynced(ctx, repositoryObj, repo); err != nil {
+		return nil, err
+	}
+	return cad.updatePackageFreeze(ctx, repo, repositoryObj, oldPackage, freeze)
+}
+
+// updatePackageFreeze records the freeze in the metadata of the package revisions of the
+// package. The other metadata of the package is kept.
+func (cad *cadEngine) updatePackageFreeze(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, oldPackage *Package, freeze *api.PackageFreeze) (*Package, error) {
+	var newFreeze *meta.PackageFreeze
+	if freeze != nil {
+		if strings.TrimSpace(freeze.Reason) == "" {
+			return nil, apierrors.NewBadRequest("the reason of a package freeze is required")
+		}
+		newFreeze = cad.newPackageFreeze(ctx, freeze.Reason)
+	}
+
+	packageName := oldPackage.repoPackage.Key().Package
+	revisions, _, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range revisions {
+		if newFreeze == nil && !rev.packageRevisionMeta.IsFrozen() {
+			continue
+		}
+		pkgRevMeta := rev.packageRevisionMeta
+		pkgRevMeta.Freeze = newFreeze
+		if newFreeze == nil {
+			pkgRevMeta.Freeze = &meta.PackageFreeze{}
+		}
+		// Leave the status of the PackageRev alone.
+		pkgRevMeta.Artifacts = nil
+		pkgRevMeta.DeploymentStatus = nil
+		pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+		if _, err := cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return nil, fmt.Errorf("cannot update metadata of package revision %q: %w", rev.KubeObjectName(), err)
+		}
+	}
+
+	return &Package{
+		repoPackage: oldPackage.repoPackage,
+		archived:    oldPackage.archived,
+		freeze:      newFreeze,
+		metadata:    oldPackage.metadata,
+	}, nil
+}
+
+// newPackageFreeze returns a freeze with the reason by the user of the request.
+func (cad *cadEngine) newPackageFreeze(ctx context.Context, reason string) *meta.PackageFreeze {
+	return &meta.PackageFreeze{
+		Reason:   reason,
+		FrozenBy: cad.requestUser(ctx),
+		FrozenAt: metav1.Now().Rfc3339Copy(),
+	}
+}
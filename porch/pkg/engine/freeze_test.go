@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPackageFreezes(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	revision := func(pkg, rev string, freeze *meta.PackageFreeze) *PackageRevision {
+		return &PackageRevision{
+			repoPackageRevision: &fake.PackageRevision{
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: pkg, Revision: rev},
+			},
+			packageRevisionMeta: meta.PackageRevisionMeta{Freeze: freeze},
+		}
+	}
+
+	revisions := []*PackageRevision{
+		revision("frozen", "v1", &meta.PackageFreeze{Reason: "incident", FrozenBy: "alice", FrozenAt: earlier}),
+		revision("frozen", "v2", nil),
+		// The most recent freeze of a package wins.
+		revision("refrozen", "v1", &meta.PackageFreeze{Reason: "old incident", FrozenBy: "alice", FrozenAt: earlier}),
+		revision("refrozen", "v2", &meta.PackageFreeze{Reason: "new incident", FrozenBy: "bob", FrozenAt: later}),
+		revision("active", "v1", nil),
+	}
+	want := map[string]*meta.PackageFreeze{
+		"frozen":   {Reason: "incident", FrozenBy: "alice", FrozenAt: earlier},
+		"refrozen": {Reason: "new incident", FrozenBy: "bob", FrozenAt: later},
+	}
+	if diff := cmp.Diff(want, packageFreezes(revisions)); diff != "" {
+		t.Errorf("unexpected package freezes (-want, +got): %s", diff)
+	}
+}
+
+func TestUpdatePackageFreeze(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	repo := &fake.Repository{}
+	store := &metafake.MemoryMetadataStore{}
+	for _, rev := range []string{"v1", "v2"} {
+		name := "blueprints-app-" + rev
+		repo.PackageRevisions = append(repo.PackageRevisions, &fake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: rev},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+		})
+		store.Metas = append(store.Metas, meta.PackageRevisionMeta{Name: name, Namespace: "default"})
+	}
+	metadata := &meta.PackageMetadata{
+		Labels:      map[string]string{"team": "platform"},
+		Annotations: map[string]string{"owner": "alice@example.com"},
+		Description: "The app blueprint",
+	}
+	oldPackage := &Package{
+		repoPackage: &fake.Package{
+			Name:       "blueprints-app",
+			PackageKey: repository.PackageKey{Repository: "blueprints", Package: "app"},
+			Package: &api.Package{
+				ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app", Namespace: "default"},
+				Spec:       api.PackageSpec{PackageName: "app", RepositoryName: "blueprints"},
+			},
+		},
+		metadata: metadata,
+	}
+	cad := &cadEngine{metadataStore: store}
+
+	frozen, err := cad.updatePackageFreeze(ctx, repo, repositoryObj, oldPackage, &api.PackageFreeze{Reason: "incident"})
+	if err != nil {
+		t.Fatalf("updatePackageFreeze failed: %v", err)
+	}
+	got := frozen.GetPackage()
+	if got.Spec.Freeze == nil || got.Spec.Freeze.Reason != "incident" {
+		t.Errorf("unexpected freeze of the frozen package: %v", got.Spec.Freeze)
+	}
+	if diff := cmp.Diff(metadata.Labels, got.Labels); diff != "" {
+		t.Errorf("unexpected labels of the frozen package (-want, +got): %s", diff)
+	}
+	if diff := cmp.Diff(metadata.Annotations, got.Annotations); diff != "" {
+		t.Errorf("unexpected annotations of the frozen package (-want, +got): %s", diff)
+	}
+	if got.Spec.Description != metadata.Description {
+		t.Errorf("description of the frozen package is %q; want %q", got.Spec.Description, metadata.Description)
+	}
+	for _, pkgRevMeta := range store.Metas {
+		if !pkgRevMeta.IsFrozen() {
+			t.Errorf("the freeze wasn't recorded in the metadata of %s", pkgRevMeta.Name)
+		}
+	}
+
+	thawed, err := cad.updatePackageFreeze(ctx, repo, repositoryObj, frozen, nil)
+	if err != nil {
+		t.Fatalf("updatePackageFreeze failed: %v", err)
+	}
+	got = thawed.GetPackage()
+	if got.Spec.Freeze != nil {
+		t.Errorf("thawed package is still frozen: %v", got.Spec.Freeze)
+	}
+	if diff := cmp.Diff(metadata.Labels, got.Labels); diff != "" {
+		t.Errorf("unexpected labels of the thawed package (-want, +got): %s", diff)
+	}
+}
+
+func TestPackageFrozenError(t *testing.T) {
+	err := &PackageFrozenError{
+		Repository: "blueprints",
+		Package:    "basens",
+		Freeze: meta.PackageFreeze{
+			Reason:   "INC-1234: rollout paused",
+			FrozenBy: "alice@example.com",
+			FrozenAt: metav1.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)),
+		},
+	}
+	if !apierrors.IsConflict(err) {
+		t.Errorf("PackageFrozenError isn't a Conflict: %v", err.Status())
+	}
+	want := `package "basens" of repository "blueprints" is frozen by alice@example.com since 2022-09-01T12:00:00Z: INC-1234: rollout paused`
+	if got := err.Error(); got != want {
+		t.Errorf("unexpected error message:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestIsFreezingTransition(t *testing.T) {
+	for _, tc := range []struct {
+		old, new api.PackageRevisionLifecycle
+		want     bool
+	}{
+		{old: api.PackageRevisionLifecycleDraft, new: api.PackageRevisionLifecycleDraft, want: false},
+		{old: api.PackageRevisionLifecycleDraft, new: api.PackageRevisionLifecycleProposed, want: true},
+		{old: api.PackageRevisionLifecycleProposed, new: api.PackageRevisionLifecyclePublished, want: true},
+		{old: api.PackageRevisionLifecycleProposed, new: api.PackageRevisionLifecycleDraft, want: false},
+		{old: api.PackageRevisionLifecyclePublished, new: api.PackageRevisionLifecyclePublished, want: false},
+	} {
+		oldObj := &api.PackageRevision{Spec: api.PackageRevisionSpec{Lifecycle: tc.old}}
+		newObj := &api.PackageRevision{Spec: api.PackageRevisionSpec{Lifecycle: tc.new}}
+		if got := isFreezingTransition(oldObj, newObj); got != tc.want {
+			t.Errorf("isFreezingTransition(%s, %s): got %t, want %t", tc.old, tc.new, got, tc.want)
+		}
+	}
+}
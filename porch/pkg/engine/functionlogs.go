@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultFunctionLogTTL is how long the function logs of a package revision are kept
+// unless another TTL is configured.
+const DefaultFunctionLogTTL = time.Hour
+
+// functionLogBytes bounds the log kept per function; the end of the log is kept.
+const functionLogBytes = 64 * 1024
+
+type functionLogsKey struct{}
+type taskLogKey struct{}
+
+// functionLogs collects the logs of the functions run by the tasks of a request.
+type functionLogs struct {
+	mu    sync.Mutex
+	tasks map[int][]api.FunctionLog
+}
+
+// taskLog captures the logs of the functions run by one task.
+type taskLog struct {
+	logs  *functionLogs
+	index int
+	// secrets are the secret values injected into the function config of the task,
+	// redacted from its logs.
+	secrets map[string]string
+}
+
+// withFunctionLogs returns a context capturing the logs of the functions run by the
+// tasks of the request.
+func withFunctionLogs(ctx context.Context) (context.Context, *functionLogs) {
+	logs := &functionLogs{tasks: map[int][]api.FunctionLog{}}
+	return context.WithValue(ctx, functionLogsKey{}, logs), logs
+}
+
+// withTaskFunctionLogs returns a context capturing the function logs of the task with
+// the index, if the context captures function logs.
+func withTaskFunctionLogs(ctx context.Context, index int) context.Context {
+	logs, ok := ctx.Value(functionLogsKey{}).(*functionLogs)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, taskLogKey{}, &taskLog{logs: logs, index: index})
+}
+
+// setFunctionLogSecrets redacts the secret values from the function logs captured for
+// the task of the context.
+func setFunctionLogSecrets(ctx context.Context, secrets map[string]string) {
+	if task, ok := ctx.Value(taskLogKey{}).(*taskLog); ok {
+		task.secrets = secrets
+	}
+}
+
+// captureFunctionLog records the log of a function run by the task of the context.
+func captureFunctionLog(ctx context.Context, image string, log string) {
+	task, ok := ctx.Value(taskLogKey{}).(*taskLog)
+	if !ok || log == "" {
+		return
+	}
+	for _, value := range task.secrets {
+		log = strings.ReplaceAll(log, value, redactedSecret)
+	}
+	entry := api.FunctionLog{Image: image, Log: log}
+	if len(log) > functionLogBytes {
+		start := len(log) - functionLogBytes
+		for start < len(log) && !utf8.RuneStart(log[start]) {
+			start++
+		}
+		entry.Log = log[start:]
+		entry.Truncated = true
+	}
+
+	task.logs.mu.Lock()
+	defer task.logs.mu.Unlock()
+	task.logs.tasks[task.index] = append(task.logs.tasks[task.index], entry)
+}
+
+// taskLogs returns the captured logs ordered by task index.
+func (l *functionLogs) taskLogs() []api.TaskLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make([]api.TaskLog, 0, len(l.tasks))
+	for index, functions := range l.tasks {
+		result = append(result, api.TaskLog{Task: index, Functions: append([]api.FunctionLog(nil), functions...)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Task < result[j].Task })
+	return result
+}
+
+// functionLogStore keeps the function logs of the last create or update of package
+// revisions in memory for a limited time.
+type functionLogStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]functionLogEntry
+}
+
+type functionLogEntry struct {
+	tasks   []api.TaskLog
+	expires time.Time
+}
+
+func newFunctionLogStore(ttl time.Duration) *functionLogStore {
+	return &functionLogStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[types.NamespacedName]functionLogEntry{},
+	}
+}
+
+// put stores the logs captured for the package revision, replacing its earlier logs.
+// Requests which ran no functions leave the earlier logs in place.
+func (s *functionLogStore) put(namespace, name string, logs *functionLogs) {
+	tasks := logs.taskLogs()
+	if len(tasks) == 0 || s.ttl == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.expire(now)
+	s.entries[types.NamespacedName{Namespace: namespace, Name: name}] = functionLogEntry{tasks: tasks, expires: now.Add(s.ttl)}
+}
+
+// get returns the logs stored for the package revision.
+func (s *functionLogStore) get(namespace, name string) []api.TaskLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now())
+	return s.entries[types.NamespacedName{Namespace: namespace, Name: name}].tasks
+}
+
+func (s *functionLogStore) expire(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// GetFunctionLogs returns the logs the functions of the package revision wrote during
+// its last create or update which ran functions, unless they have expired.
+func (cad *cadEngine) GetFunctionLogs(ctx context.Context, repositoryObj *configapi.Repository, name string) ([]api.TaskLog, error) {
+	_, span := tracer.Start(ctx, "cadEngine::GetFunctionLogs", trace.WithAttributes())
+	defer span.End()
+
+	return cad.functionLogs.get(repositoryObj.Namespace, name), nil
+}
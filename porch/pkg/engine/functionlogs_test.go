@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/func/evaluator"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+)
+
+type fakeEvaluatorClient struct {
+	log []byte
+	err error
+}
+
+func (c *fakeEvaluatorClient) EvaluateFunction(ctx context.Context, in *evaluator.EvaluateFunctionRequest, opts ...grpc.CallOption) (*evaluator.EvaluateFunctionResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &evaluator.EvaluateFunctionResponse{ResourceList: in.ResourceList, Log: c.log}, nil
+}
+
+func TestFunctionLogCapture(t *testing.T) {
+	ctx, logs := withFunctionLogs(context.Background())
+
+	run := func(ctx context.Context, image string, client *fakeEvaluatorClient) {
+		runner := &grpcRunner{ctx: ctx, client: client, image: image}
+		_ = runner.Run(strings.NewReader("{}"), &bytes.Buffer{})
+	}
+
+	task0 := withTaskFunctionLogs(ctx, 0)
+	setFunctionLogSecrets(task0, map[string]string{"password": "s3cr3t"})
+	run(task0, "set-password", &fakeEvaluatorClient{log: []byte("using password s3cr3t\n")})
+	run(withTaskFunctionLogs(ctx, 2), "kubeval", &fakeEvaluatorClient{err: errors.New("exit status 1: invalid Deployment")})
+	run(withTaskFunctionLogs(ctx, 2), "quiet", &fakeEvaluatorClient{})
+
+	want := []api.TaskLog{
+		{Task: 0, Functions: []api.FunctionLog{{Image: "set-password", Log: "using password <redacted>\n"}}},
+		{Task: 2, Functions: []api.FunctionLog{{Image: "kubeval", Log: "exit status 1: invalid Deployment"}}},
+	}
+	if diff := cmp.Diff(want, logs.taskLogs()); diff != "" {
+		t.Errorf("unexpected function logs (-want, +got): %s", diff)
+	}
+
+	// The end of long logs is kept.
+	ctx, logs = withFunctionLogs(context.Background())
+	long := strings.Repeat("x", functionLogBytes) + "last line\n"
+	captureFunctionLog(withTaskFunctionLogs(ctx, 0), "chatty", long)
+	got := logs.taskLogs()[0].Functions[0]
+	if !got.Truncated || len(got.Log) != functionLogBytes || !strings.HasSuffix(got.Log, "last line\n") {
+		t.Errorf("long log was kept as %d bytes, truncated %t; want the last %d bytes", len(got.Log), got.Truncated, functionLogBytes)
+	}
+
+	// Without capture, logs are dropped.
+	captureFunctionLog(context.Background(), "chatty", "dropped")
+}
+
+func TestFunctionLogStore(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := newFunctionLogStore(time.Hour)
+	store.now = func() time.Time { return now }
+
+	ctx, logs := withFunctionLogs(context.Background())
+	captureFunctionLog(withTaskFunctionLogs(ctx, 1), "render", "rendered")
+	store.put("default", "blueprints-1111", logs)
+
+	// Requests which ran no functions keep the earlier logs.
+	_, empty := withFunctionLogs(context.Background())
+	store.put("default", "blueprints-1111", empty)
+
+	want := []api.TaskLog{{Task: 1, Functions: []api.FunctionLog{{Image: "render", Log: "rendered"}}}}
+	if diff := cmp.Diff(want, store.get("default", "blueprints-1111")); diff != "" {
+		t.Errorf("unexpected stored logs (-want, +got): %s", diff)
+	}
+	if got := store.get("other", "blueprints-1111"); got != nil {
+		t.Errorf("logs of another namespace were returned: %v", got)
+	}
+
+	now = now.Add(time.Hour)
+	if got := store.get("default", "blueprints-1111"); got != nil {
+		t.Errorf("expired logs were returned: %v", got)
+	}
+}
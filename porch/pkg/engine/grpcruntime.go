@@ -92,8 +92,11 @@ func (gr *grpcRunner) Run(r io.Reader, w io.Writer) error {
 		Image:        gr.image,
 	})
 	if err != nil {
+		// The error of a failed function includes its stderr.
+		captureFunctionLog(gr.ctx, gr.image, err.Error())
 		return fmt.Errorf("func eval %q failed: %w", gr.image, err)
 	}
+	captureFunctionLog(gr.ctx, gr.image, string(res.Log))
 	if _, err := w.Write(res.ResourceList); err != nil {
 		return fmt.Errorf("failed to write function runner output: %w", err)
 	}
@@ -0,0 +1,88 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// isStrictTasks reports whether package revisions created in the repository must
+// start their tasks with an init or clone task, either because the engine was
+// configured WithStrictTasks or because the repository opts in with the
+// configapi.StrictTasksAnnotation.
+func (cad *cadEngine) isStrictTasks(repositoryObj *configapi.Repository) bool {
+	return cad.strictTasks || repositoryObj.Annotations[configapi.StrictTasksAnnotation] == "true"
+}
+
+// ensureCreationTask makes sure the tasks of obj start with a task creating the
+// package. In strict mode, a task list starting with any other task is rejected.
+// Otherwise an init task creating an empty package is inserted into obj.Spec.Tasks,
+// so that the tasks of the package revision match the commits of its draft.
+//
+// The description of an init task without one is generated from the description
+// template of the repository, or else of the engine; an inserted init task falls back
+// to a built-in description.
+func (cad *cadEngine) ensureCreationTask(repositoryObj *configapi.Repository, obj *api.PackageRevision) error {
+	tasks := obj.Spec.Tasks
+	if len(tasks) > 0 && tasks[0].Type == api.TaskTypeClone {
+		return nil
+	}
+	if len(tasks) > 0 && tasks[0].Type == api.TaskTypeInit {
+		// An init task without a description gets one from the configured templates.
+		if init := tasks[0].Init; init != nil && init.Description == "" {
+			description, found, err := cad.templateDescription(repositoryObj, obj)
+			if err != nil {
+				return err
+			}
+			if found {
+				init.Description = description
+			}
+		}
+		return nil
+	}
+
+	if cad.isStrictTasks(repositoryObj) {
+		path := field.NewPath("spec", "tasks")
+		var fieldErr *field.Error
+		if len(tasks) == 0 {
+			fieldErr = field.Required(path, "the first task must be an init or clone task creating the package")
+		} else {
+			fieldErr = field.Invalid(path.Index(0).Child("type"), tasks[0].Type, "the first task must be an init or clone task creating the package")
+		}
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, field.ErrorList{fieldErr})
+	}
+
+	description, found, err := cad.templateDescription(repositoryObj, obj)
+	if err != nil {
+		return err
+	}
+	if !found {
+		description = fmt.Sprintf("%s description", obj.Spec.PackageName)
+	}
+	initTask := api.Task{
+		Type: api.TaskTypeInit,
+		Init: &api.PackageInitTaskSpec{
+			Subpackage:  "",
+			Description: description,
+		},
+	}
+	obj.Spec.Tasks = append([]api.Task{initTask}, tasks...)
+	return nil
+}
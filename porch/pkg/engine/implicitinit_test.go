@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recordingDraft records the task of every commit made to the draft.
+type recordingDraft struct {
+	tasks     []api.Task
+	resources map[string]string
+}
+
+var _ repository.PackageDraft = &recordingDraft{}
+
+func (d *recordingDraft) UpdateResources(ctx context.Context, new *api.PackageRevisionResources, task *api.Task) error {
+	d.tasks = append(d.tasks, *task)
+	d.resources = new.Spec.Resources
+	return nil
+}
+
+func (d *recordingDraft) UpdateLifecycle(ctx context.Context, new api.PackageRevisionLifecycle) error {
+	return nil
+}
+
+func (d *recordingDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	return nil, nil
+}
+
+func TestImplicitInitTask(t *testing.T) {
+	ctx := context.Background()
+	patch := api.Task{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+		File:      "configmap.yaml",
+		PatchType: api.PatchTypeCreateFile,
+		Contents:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+	}}}}
+	newObj := func() *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1"},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "app",
+				Tasks:       []api.Task{*patch.DeepCopy()},
+			},
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		cad := &cadEngine{renderer: &countingRenderer{}}
+		obj := newObj()
+		draft := &recordingDraft{}
+		if err := cad.applyTasks(ctx, draft, &configapi.Repository{}, obj, nil); err != nil {
+			t.Fatalf("applyTasks failed: %v", err)
+		}
+
+		if got, want := len(obj.Spec.Tasks), 2; got != want {
+			t.Fatalf("unexpected number of tasks: got %d, want %d", got, want)
+		}
+		if got := obj.Spec.Tasks[0]; got.Type != api.TaskTypeInit || got.Init == nil {
+			t.Errorf("inserted init task isn't recorded in spec.tasks: %+v", got)
+		}
+		// Every task has a commit of its own. The package has no pipeline, so the
+		// implicit render is skipped.
+		if got, want := len(draft.tasks), len(obj.Spec.Tasks); got != want {
+			t.Fatalf("unexpected number of commits: got %d, want %d", got, want)
+		}
+		if diff := cmp.Diff(obj.Spec.Tasks, draft.tasks[:len(obj.Spec.Tasks)]); diff != "" {
+			t.Errorf("spec.tasks don't match the commits (-want, +got): %s", diff)
+		}
+		if _, found := draft.resources[kptfile.KptFileName]; !found {
+			t.Errorf("inserted init task didn't create the package")
+		}
+	})
+
+	t.Run("explicit init", func(t *testing.T) {
+		cad := &cadEngine{renderer: &countingRenderer{}, strictTasks: true}
+		obj := newObj()
+		obj.Spec.Tasks = append([]api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}}}, obj.Spec.Tasks...)
+		want := append([]api.Task{}, obj.Spec.Tasks...)
+		draft := &recordingDraft{}
+		if err := cad.applyTasks(ctx, draft, &configapi.Repository{}, obj, nil); err != nil {
+			t.Fatalf("applyTasks failed: %v", err)
+		}
+		if diff := cmp.Diff(want, obj.Spec.Tasks); diff != "" {
+			t.Errorf("spec.tasks changed (-want, +got): %s", diff)
+		}
+		if diff := cmp.Diff(want, draft.tasks[:len(want)]); diff != "" {
+			t.Errorf("spec.tasks don't match the commits (-want, +got): %s", diff)
+		}
+	})
+
+	for _, tc := range []struct {
+		name       string
+		cad        *cadEngine
+		repository *configapi.Repository
+	}{
+		{
+			name:       "strict engine",
+			cad:        &cadEngine{renderer: &countingRenderer{}, strictTasks: true},
+			repository: &configapi.Repository{},
+		},
+		{
+			name: "strict repository",
+			cad:  &cadEngine{renderer: &countingRenderer{}},
+			repository: &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{configapi.StrictTasksAnnotation: "true"},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, obj := range []*api.PackageRevision{newObj(), {Spec: api.PackageRevisionSpec{PackageName: "empty"}}} {
+				draft := &recordingDraft{}
+				err := tc.cad.applyTasks(ctx, draft, tc.repository, obj, nil)
+				if !apierrors.IsInvalid(err) {
+					t.Fatalf("expected a validation error for tasks %v, got %v", obj.Spec.Tasks, err)
+				}
+				if len(draft.tasks) != 0 {
+					t.Errorf("rejected package revision has %d commits", len(draft.tasks))
+				}
+			}
+		})
+	}
+}
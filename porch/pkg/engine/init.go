@@ -17,6 +17,8 @@ package engine
 import (
 	"context"
 	"fmt"
+	"path"
+	"text/template"
 
 	"github.com/GoogleContainerTools/kpt/internal/printer"
 	"github.com/GoogleContainerTools/kpt/internal/printer/fake"
@@ -31,6 +33,8 @@ type initPackageMutation struct {
 	kptpkg.DefaultInitializer
 	name string
 	task *api.Task
+	// starters are the templates of the starter resources the task can select.
+	starters map[string]*template.Template
 }
 
 var _ mutation = &initPackageMutation{}
@@ -55,11 +59,22 @@ func (m *initPackageMutation) Apply(ctx context.Context, resources repository.Pa
 		Desc:     m.task.Init.Description,
 		Keywords: m.task.Init.Keywords,
 		Site:     m.task.Init.Site,
+		License:  m.task.Init.License,
 	})
 	if err != nil {
 		return repository.PackageResources{}, nil, fmt.Errorf("failed to initialize pkg %q: %w", m.name, err)
 	}
 
+	starters, err := renderStarters(m.starters, m.task.Init.Starters, m.name)
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("failed to initialize pkg %q: %w", m.name, err)
+	}
+	for name, contents := range starters {
+		if err := fs.WriteFile(path.Join(pkgPath, name), []byte(contents)); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+	}
+
 	result, err := readResources(fs)
 	if err != nil {
 		return repository.PackageResources{}, nil, err
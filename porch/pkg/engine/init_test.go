@@ -18,11 +18,15 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func TestInit(t *testing.T) {
@@ -66,3 +70,105 @@ func TestInit(t *testing.T) {
 	}
 
 }
+
+func TestInitStarters(t *testing.T) {
+	ctx := context.Background()
+	starters, err := parseStarterResources(DefaultStarterResources())
+	if err != nil {
+		t.Fatalf("parseStarterResources failed: %v", err)
+	}
+	init := &initPackageMutation{
+		name: "teams/payments",
+		task: &api.Task{
+			Init: &api.PackageInitTaskSpec{
+				Description: "payments team",
+				Site:        "http://kpt.dev/payments",
+				License:     "Apache-2.0",
+				Starters:    []string{"namespace", "resourcequota"},
+			},
+		},
+		starters: starters,
+	}
+
+	initializedPkg, _, err := init.Apply(ctx, repository.PackageResources{})
+	if err != nil {
+		t.Fatalf("package init failed: %v", err)
+	}
+	if got := initializedPkg.Contents["Kptfile"]; !strings.Contains(got, "license: Apache-2.0") {
+		t.Errorf("Kptfile has no license:\n%s", got)
+	}
+	want := map[string]string{
+		"namespace.yaml":     "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: payments\n",
+		"resourcequota.yaml": "apiVersion: v1\nkind: ResourceQuota\nmetadata:\n  name: default\n  namespace: payments\nspec:\n  hard:\n    cpu: \"40\"\n    memory: 40G\n",
+	}
+	for name, contents := range want {
+		if diff := cmp.Diff(contents, initializedPkg.Contents[name]); diff != "" {
+			t.Errorf("unexpected %s (-want, +got): %s", name, diff)
+		}
+	}
+
+	// The package has no pipeline, but renders cleanly.
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+	render := &renderPackageMutation{
+		renderer: kpt.NewRenderer(runnerOptions),
+		runtime:  kpt.NewSimpleFunctionRuntime(),
+	}
+	rendered, _, err := render.Apply(ctx, initializedPkg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for name, contents := range want {
+		if diff := cmp.Diff(contents, rendered.Contents[name]); diff != "" {
+			t.Errorf("render changed %s (-want, +got): %s", name, diff)
+		}
+	}
+}
+
+func TestValidateInitStarters(t *testing.T) {
+	starters, err := parseStarterResources(DefaultStarterResources())
+	if err != nil {
+		t.Fatalf("parseStarterResources failed: %v", err)
+	}
+	cad := &cadEngine{starterResources: starters}
+	obj := func(names ...string) *api.PackageRevision {
+		return &api.PackageRevision{Spec: api.PackageRevisionSpec{Tasks: []api.Task{
+			{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Starters: names}},
+		}}}
+	}
+
+	if err := cad.validateInitStarters(obj("namespace", "resourcequota")); err != nil {
+		t.Errorf("configured starter resources were rejected: %v", err)
+	}
+	err = cad.validateInitStarters(obj("namespace", "limitrange"))
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("validateInitStarters returned %v; want Invalid error", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"limitrange"`) || !strings.Contains(msg, `"namespace", "resourcequota"`) {
+		t.Errorf("error doesn't list the available starter resources: %s", msg)
+	}
+}
+
+func TestLoadStarterResources(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"limitrange.yaml": "apiVersion: v1\nkind: LimitRange\nmetadata:\n  name: {{.Package}}\n",
+		"README.md":       "Starter resources of the platform team.\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	starters, err := LoadStarterResources(dir)
+	if err != nil {
+		t.Fatalf("LoadStarterResources failed: %v", err)
+	}
+	want := StarterResources{"limitrange": "apiVersion: v1\nkind: LimitRange\nmetadata:\n  name: {{.Package}}\n"}
+	if diff := cmp.Diff(want, starters); diff != "" {
+		t.Errorf("unexpected starter resources (-want, +got): %s", diff)
+	}
+	if err := WithStarterResources(StarterResources{"broken": "{{.Package"}).apply(&cadEngine{}); err == nil {
+		t.Errorf("invalid starter resource template was accepted")
+	}
+}
@@ -15,17 +15,14 @@
 package engine
 
 import (
-	"bytes"
-	"fmt"
-	"path"
-	"strings"
-
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"sigs.k8s.io/kustomize/kyaml/kio"
-	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// packageReader reads the KRM resources of package resources; the files which aren't KRM
+// files are collected in extra.
 type packageReader struct {
 	input repository.PackageResources
 	extra map[string]string
@@ -34,35 +31,10 @@ type packageReader struct {
 var _ kio.Reader = &packageReader{}
 
 func (r *packageReader) Read() ([]*yaml.RNode, error) {
-	results := []*yaml.RNode{}
-	for k, v := range r.input.Contents {
-		base := path.Base(k)
-		ext := path.Ext(base)
-
-		// TODO: use authoritative kpt filtering
-		if ext != ".yaml" && ext != ".yml" && base != "Kptfile" {
-			r.extra[k] = v
-			continue
-		}
-
-		var reader kio.Reader = &kio.ByteReader{
-			Reader: strings.NewReader(v),
-			SetAnnotations: map[string]string{
-				kioutil.PathAnnotation: k,
-			},
-			DisableUnwrapping: true,
-		}
-		nodes, err := reader.Read()
-		if err != nil {
-			// TODO: fail, or bypass this file too?
-			return nil, err
-		}
-		results = append(results, nodes...)
-	}
-
-	return results, nil
+	return (&pkgcontents.Reader{Contents: r.input.Contents, Other: r.extra}).Read()
 }
 
+// packageWriter writes KRM resources into the files of output.
 type packageWriter struct {
 	output repository.PackageResources
 }
@@ -70,47 +42,7 @@ type packageWriter struct {
 var _ kio.Writer = &packageWriter{}
 
 func (w *packageWriter) Write(nodes []*yaml.RNode) error {
-	paths := map[string][]*yaml.RNode{}
-	for _, node := range nodes {
-		path := getPath(node)
-		paths[path] = append(paths[path], node)
-	}
-
-	// TODO: write directly into the package resources abstraction.
-	// For now serializing into memory.
-	buf := &bytes.Buffer{}
-	for path, nodes := range paths {
-		bw := kio.ByteWriter{
-			Writer: buf,
-			ClearAnnotations: []string{
-				kioutil.PathAnnotation,
-				kioutil.LegacyPathAnnotation,
-			},
-		}
-		if err := bw.Write(nodes); err != nil {
-			return err
-		}
-		w.output.Contents[path] = buf.String()
-		buf.Reset()
-	}
-	return nil
-}
-
-func getPath(node *yaml.RNode) string {
-	ann := node.GetAnnotations()
-	if path, ok := ann[kioutil.PathAnnotation]; ok {
-		return path
-	}
-	ns := node.GetNamespace()
-	if ns == "" {
-		ns = "non-namespaced"
-	}
-	name := node.GetName()
-	if name == "" {
-		name = "unnamed"
-	}
-	// TODO: harden for escaping etc.
-	return path.Join(ns, fmt.Sprintf("%s.yaml", name))
+	return (&pkgcontents.Writer{Contents: w.output.Contents}).Write(nodes)
 }
 
 type NodeToMapWriter struct {
@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	meter = metric.Must(global.Meter("engine"))
+
+	draftDurationHistogram = meter.NewFloat64ValueRecorder("porch_package_revision_draft_duration_seconds",
+		metric.WithDescription("Time package revisions spent in the Draft lifecycle before being proposed or published"))
+	proposedDurationHistogram = meter.NewFloat64ValueRecorder("porch_package_revision_proposed_duration_seconds",
+		metric.WithDescription("Time package revisions spent in the Proposed lifecycle before being published or rejected"))
+)
+
+// lifecycleTransition returns the lifecycle times to record for a package revision
+// changing from the old to the new lifecycle at the given time by the user, and records
+// how long the package revision spent in the old lifecycle, if its start is known.
+//
+// The time in Draft is measured from the creation of the draft; after a rejected
+// proposal, it includes the time the package revision was proposed before.
+func lifecycleTransition(ctx context.Context, repositoryObj *configapi.Repository, oldLifecycle, newLifecycle api.PackageRevisionLifecycle, previous meta.LifecycleTimes, now metav1.Time, user string) meta.LifecycleTimes {
+	var times meta.LifecycleTimes
+	if oldLifecycle == newLifecycle {
+		return times
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("repository", repositoryObj.Namespace+"/"+repositoryObj.Name)}
+	switch oldLifecycle {
+	case api.PackageRevisionLifecycleDraft:
+		if !previous.DraftCreatedAt.IsZero() {
+			draftDurationHistogram.Record(ctx, now.Sub(previous.DraftCreatedAt.Time).Seconds(), attrs...)
+		}
+	case api.PackageRevisionLifecycleProposed:
+		if !previous.ProposedAt.IsZero() {
+			proposedDurationHistogram.Record(ctx, now.Sub(previous.ProposedAt.Time).Seconds(), attrs...)
+		}
+	}
+
+	switch newLifecycle {
+	case api.PackageRevisionLifecycleProposed:
+		times.ProposedAt = now
+		times.ProposedBy = user
+	case api.PackageRevisionLifecyclePublished:
+		times.PublishedAt = now
+	}
+	return times
+}
+
+// creationLifecycleTimes returns the lifecycle times to record for a package revision
+// created in the given lifecycle at the given time by the user.
+func creationLifecycleTimes(lifecycle api.PackageRevisionLifecycle, now metav1.Time, user string) meta.LifecycleTimes {
+	times := meta.LifecycleTimes{DraftCreatedAt: now}
+	switch lifecycle {
+	case api.PackageRevisionLifecycleProposed:
+		times.ProposedAt = now
+		times.ProposedBy = user
+	case api.PackageRevisionLifecyclePublished:
+		times.PublishedAt = now
+	}
+	return times
+}
+
+// authoritativeTimes returns the creation and publish times of a package revision. The
+// commit time of a published package revision is authoritative, so that its age doesn't
+// depend on which clock recorded it; the times recorded by Porch are only used if it is
+// unknown. A draft or proposed package revision is committed again on each change, so
+// its recorded creation time is authoritative instead.
+func authoritativeTimes(lifecycle api.PackageRevisionLifecycle, commitTime metav1.Time, recorded meta.LifecycleTimes) (created, published metav1.Time) {
+	created = commitTime
+	if lifecycle == api.PackageRevisionLifecyclePublished {
+		published = commitTime
+		if published.IsZero() {
+			published = recorded.PublishedAt
+		}
+		return created, published
+	}
+	if !recorded.DraftCreatedAt.IsZero() {
+		created = recorded.DraftCreatedAt
+	}
+	return created, recorded.PublishedAt
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLifecycleTimes(t *testing.T) {
+	ctx := context.Background()
+	repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "blueprints"}}
+	created := metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	proposed := metav1.Date(2022, 9, 2, 10, 0, 0, 0, time.UTC)
+	published := metav1.Date(2022, 9, 3, 10, 0, 0, 0, time.UTC)
+
+	if diff := cmp.Diff(meta.LifecycleTimes{DraftCreatedAt: created}, creationLifecycleTimes(api.PackageRevisionLifecycleDraft, created, "alice@example.com")); diff != "" {
+		t.Errorf("unexpected times of created draft (-want, +got): %s", diff)
+	}
+
+	// Walk a package revision through its lifecycle, merging the recorded times like the metadata store.
+	times := creationLifecycleTimes(api.PackageRevisionLifecycleDraft, created, "alice@example.com")
+	for _, step := range []struct {
+		from, to api.PackageRevisionLifecycle
+		at       metav1.Time
+		want     meta.LifecycleTimes
+	}{
+		{from: api.PackageRevisionLifecycleDraft, to: api.PackageRevisionLifecycleDraft, at: proposed, want: meta.LifecycleTimes{}},
+		{from: api.PackageRevisionLifecycleDraft, to: api.PackageRevisionLifecycleProposed, at: proposed, want: meta.LifecycleTimes{ProposedAt: proposed, ProposedBy: "alice@example.com"}},
+		{from: api.PackageRevisionLifecycleProposed, to: api.PackageRevisionLifecyclePublished, at: published, want: meta.LifecycleTimes{PublishedAt: published}},
+	} {
+		got := lifecycleTransition(ctx, repo, step.from, step.to, times, step.at, "alice@example.com")
+		if diff := cmp.Diff(step.want, got); diff != "" {
+			t.Errorf("unexpected times of transition from %s to %s (-want, +got): %s", step.from, step.to, diff)
+		}
+		if !got.ProposedAt.IsZero() {
+			times.ProposedAt = got.ProposedAt
+			times.ProposedBy = got.ProposedBy
+		}
+		if !got.PublishedAt.IsZero() {
+			times.PublishedAt = got.PublishedAt
+		}
+	}
+
+	// The recorded times are reported in the status.
+	pr := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{
+			PackageRevision: &api.PackageRevision{
+				Status: api.PackageRevisionStatus{PublishedAt: metav1.Date(2022, 9, 4, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		packageRevisionMeta: meta.PackageRevisionMeta{LifecycleTimes: times},
+	}
+	apiPr, err := pr.GetPackageRevision(ctx)
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	got := meta.LifecycleTimes{
+		DraftCreatedAt: apiPr.Status.DraftCreatedAt,
+		ProposedAt:     apiPr.Status.ProposedAt,
+		ProposedBy:     apiPr.Status.ProposedBy,
+		PublishedAt:    apiPr.Status.PublishedAt,
+	}
+	if diff := cmp.Diff(meta.LifecycleTimes{DraftCreatedAt: created, ProposedAt: proposed, ProposedBy: "alice@example.com", PublishedAt: published}, got); diff != "" {
+		t.Errorf("unexpected lifecycle times in status (-want, +got): %s", diff)
+	}
+}
+
+func TestSkewedTimestamps(t *testing.T) {
+	ctx := context.Background()
+	// The clock of the API server runs three hours ahead of the clocks which committed
+	// to the repository.
+	at := func(hour int) metav1.Time { return metav1.Date(2022, 9, 1, hour, 0, 0, 0, time.UTC) }
+	skew := 3
+
+	revision := func(name, revision string, lifecycle api.PackageRevisionLifecycle, committed metav1.Time, recorded meta.LifecycleTimes) *PackageRevision {
+		var status api.PackageRevisionStatus
+		if lifecycle == api.PackageRevisionLifecyclePublished {
+			status.PublishedAt = committed
+		}
+		return &PackageRevision{
+			repoPackageRevision: &fake.PackageRevision{
+				Name:               name,
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "deployments", Package: "app", Revision: revision},
+				PackageLifecycle:   lifecycle,
+				PackageRevision: &api.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: committed},
+					Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: revision, Lifecycle: lifecycle},
+					Status:     status,
+				},
+			},
+			packageRevisionMeta: meta.PackageRevisionMeta{LifecycleTimes: recorded},
+		}
+	}
+	revisions := []*PackageRevision{
+		// Published through Porch at 10:00.
+		revision("deployments-main-a", "main-a", api.PackageRevisionLifecyclePublished, at(10),
+			meta.LifecycleTimes{DraftCreatedAt: at(9 + skew), PublishedAt: at(10 + skew)}),
+		// Pushed to the repository at 11:00, without times recorded by Porch.
+		revision("deployments-main-b", "main-b", api.PackageRevisionLifecyclePublished, at(11), meta.LifecycleTimes{}),
+		// Created at 12:00 and last edited at 14:00.
+		revision("deployments-main-c", "main-c", api.PackageRevisionLifecycleDraft, at(14),
+			meta.LifecycleTimes{DraftCreatedAt: at(12 + skew)}),
+	}
+
+	var apiRevisions []*api.PackageRevision
+	var candidates []retentionCandidate
+	for _, rev := range revisions {
+		apiRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			t.Fatalf("GetPackageRevision failed: %v", err)
+		}
+		apiRevisions = append(apiRevisions, apiRev)
+		if rev.Lifecycle() == api.PackageRevisionLifecyclePublished {
+			candidates = append(candidates, retentionCandidate{
+				name:        apiRev.Name,
+				key:         rev.repoPackageRevision.Key(),
+				publishedAt: apiRev.Status.PublishedAt.Time,
+			})
+		}
+	}
+
+	// The commit time of published revisions and the recorded creation time of drafts
+	// are authoritative; the raw times are reported alongside them.
+	type times struct {
+		Created, Published metav1.Time
+		Raw                api.PackageRevisionTimestamps
+	}
+	var got []times
+	for _, apiRev := range apiRevisions {
+		got = append(got, times{Created: apiRev.CreationTimestamp, Published: apiRev.Status.PublishedAt, Raw: *apiRev.Status.Timestamps})
+	}
+	want := []times{
+		{Created: at(10), Published: at(10), Raw: api.PackageRevisionTimestamps{CommitTime: at(10), RecordedCreatedAt: at(12), RecordedPublishedAt: at(13)}},
+		{Created: at(11), Published: at(11), Raw: api.PackageRevisionTimestamps{CommitTime: at(11)}},
+		{Created: at(15), Raw: api.PackageRevisionTimestamps{CommitTime: at(14), RecordedCreatedAt: at(15)}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected times (-want, +got): %s", diff)
+	}
+
+	// Keeping the newest revision deletes the one published first, even though Porch
+	// recorded its publication after the other was pushed.
+	expired := selectExpiredRevisions(&configapi.RetentionPolicy{KeepLast: 1}, candidates, downstreamIndex{}, at(20).Time)
+	if diff := cmp.Diff([]string{"deployments-main-a"}, expired); diff != "" {
+		t.Errorf("unexpected expired revisions (-want, +got): %s", diff)
+	}
+
+	var order []string
+	for _, event := range buildPackageTimeline(apiRevisions, nil, nil) {
+		if event.Type == api.TimelineEventRevisionCreated {
+			order = append(order, event.Revision)
+		}
+	}
+	if diff := cmp.Diff([]string{"deployments-main-a", "deployments-main-b", "deployments-main-c"}, order); diff != "" {
+		t.Errorf("unexpected order of creation in the timeline (-want, +got): %s", diff)
+	}
+}
@@ -34,7 +34,8 @@ func ensureMergeKey(ctx context.Context, resources repository.PackageResources)
 	}
 
 	result := repository.PackageResources{
-		Contents: map[string]string{},
+		Contents:  map[string]string{},
+		FileModes: resources.FileModes,
 	}
 
 	amc := &addmergecomment.AddMergeComment{}
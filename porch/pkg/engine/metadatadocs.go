@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Metadata documents are structured documents, such as test results or a cost estimate,
+// which integrations attach to a package revision. Like labels and annotations, they are
+// kept in the metadata store rather than in the repository, so they neither change the
+// package resources nor affect rendering.
+
+// SetRevisionMetadataDoc stores obj, encoded as JSON, as the metadata document with the
+// given key of the package revision, replacing any document stored with that key. obj
+// must encode as a JSON object; a nil obj removes the document.
+func (cad *cadEngine) SetRevisionMetadataDoc(ctx context.Context, repositoryObj *configapi.Repository, name, key string, obj interface{}) error {
+	ctx, span := tracer.Start(ctx, "cadEngine::SetRevisionMetadataDoc", trace.WithAttributes())
+	defer span.End()
+
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid metadata document key %q: %s", key, strings.Join(errs, "; "))
+	}
+	var document json.RawMessage
+	if obj != nil {
+		var err error
+		if document, err = json.Marshal(obj); err != nil {
+			return fmt.Errorf("cannot encode metadata document %q: %w", key, err)
+		}
+		if !bytes.HasPrefix(document, []byte("{")) {
+			return fmt.Errorf("metadata document %q must be a JSON object", key)
+		}
+	}
+
+	rev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return err
+	}
+	return cad.setMetadataDoc(ctx, rev, key, document)
+}
+
+// setMetadataDoc stores the encoded document with the given key in the metadata of the
+// package revision; a nil document removes it.
+func (cad *cadEngine) setMetadataDoc(ctx context.Context, rev *PackageRevision, key string, document json.RawMessage) error {
+	pkgRevMeta := rev.packageRevisionMeta
+	documents := make(map[string]json.RawMessage, len(pkgRevMeta.Documents)+1)
+	for k, v := range pkgRevMeta.Documents {
+		documents[k] = v
+	}
+	if document == nil {
+		if _, found := documents[key]; !found {
+			return nil
+		}
+		delete(documents, key)
+	} else {
+		documents[key] = document
+	}
+	pkgRevMeta.Documents = documents
+	// Leave the status of the PackageRev alone.
+	pkgRevMeta.Artifacts = nil
+	pkgRevMeta.DeploymentStatus = nil
+	pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+	updated, err := cad.metadataStore.Update(ctx, pkgRevMeta)
+	if err != nil {
+		return fmt.Errorf("cannot update metadata of package revision %q: %w", rev.KubeObjectName(), err)
+	}
+	rev.packageRevisionMeta = updated
+	return nil
+}
+
+// GetRevisionMetadataDoc decodes the metadata document with the given key of the package
+// revision into obj. It returns false if the package revision has no such document.
+func (cad *cadEngine) GetRevisionMetadataDoc(ctx context.Context, repositoryObj *configapi.Repository, name, key string, obj interface{}) (bool, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::GetRevisionMetadataDoc", trace.WithAttributes())
+	defer span.End()
+
+	rev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return false, err
+	}
+	return getMetadataDoc(rev, key, obj)
+}
+
+func getMetadataDoc(rev *PackageRevision, key string, obj interface{}) (bool, error) {
+	document, found := rev.packageRevisionMeta.Documents[key]
+	if !found {
+		return false, nil
+	}
+	if err := json.Unmarshal(document, obj); err != nil {
+		return false, fmt.Errorf("cannot decode metadata document %q of package revision %q: %w", key, rev.KubeObjectName(), err)
+	}
+	return true, nil
+}
+
+// findPackageRevision returns the package revision with the given name in the repository.
+func (cad *cadEngine) findPackageRevision(ctx context.Context, repositoryObj *configapi.Repository, name string) (*PackageRevision, error) {
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{KubeObjectName: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("package revision %q not found in repository %q", name, repositoryObj.Name)
+	}
+	return revisions[0], nil
+}
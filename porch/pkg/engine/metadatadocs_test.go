@@ -0,0 +1,80 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetadataDocs(t *testing.T) {
+	ctx := context.Background()
+	pkgRevMeta := meta.PackageRevisionMeta{Name: "blueprints-1234", Namespace: "default", Labels: map[string]string{"team": "a"}}
+	store := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+	cad := &cadEngine{metadataStore: store}
+	rev := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{Name: "blueprints-1234"},
+		packageRevisionMeta: pkgRevMeta,
+	}
+
+	type testResults struct {
+		Passed int      `json:"passed"`
+		Failed []string `json:"failed"`
+	}
+	results := testResults{Passed: 12, Failed: []string{"TestNamespace"}}
+	document, err := json.Marshal(results)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := cad.setMetadataDoc(ctx, rev, "test-results", document); err != nil {
+		t.Fatalf("setMetadataDoc failed: %v", err)
+	}
+	if err := cad.setMetadataDoc(ctx, rev, "cost", json.RawMessage(`{"monthly":"12.50"}`)); err != nil {
+		t.Fatalf("setMetadataDoc failed: %v", err)
+	}
+
+	// Read back the document as it is stored.
+	stored := &PackageRevision{repoPackageRevision: rev.repoPackageRevision, packageRevisionMeta: store.Metas[0]}
+	var got testResults
+	found, err := getMetadataDoc(stored, "test-results", &got)
+	if err != nil || !found {
+		t.Fatalf("getMetadataDoc failed: found %t, %v", found, err)
+	}
+	if diff := cmp.Diff(results, got); diff != "" {
+		t.Errorf("unexpected document (-want, +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"team": "a"}, store.Metas[0].Labels); diff != "" {
+		t.Errorf("labels changed (-want, +got): %s", diff)
+	}
+
+	// Removing a document leaves the others alone.
+	if err := cad.setMetadataDoc(ctx, rev, "test-results", nil); err != nil {
+		t.Fatalf("setMetadataDoc failed: %v", err)
+	}
+	stored.packageRevisionMeta = store.Metas[0]
+	if found, err := getMetadataDoc(stored, "test-results", &got); err != nil || found {
+		t.Errorf("removed document was found: found %t, %v", found, err)
+	}
+	var cost map[string]string
+	if found, err := getMetadataDoc(stored, "cost", &cost); err != nil || !found || cost["monthly"] != "12.50" {
+		t.Errorf("unexpected cost document: %v (found %t, %v)", cost, found, err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"k8s.io/klog/v2"
+)
+
+var metadataConflictCounter = meter.NewInt64Counter("porch_package_revision_metadata_conflicts_total",
+	metric.WithDescription("Labels and annotations of package revisions set to different values by their repository and their metadata"))
+
+// Winners of conflicting label and annotation values.
+const (
+	conflictWinnerRepository = "repository"
+	conflictWinnerMetadata   = "metadata"
+)
+
+// mergeMetadata merges the labels or annotations (the field) of the package revision
+// name derived by its repository with the ones stored in its metadata:
+//
+//   - System keys are managed by Porch and the repository: their values come from the
+//     repository, and values stored in the metadata are ignored.
+//   - Other keys are set by users: their values come from the metadata. Keys derived by
+//     the repository and not stored in the metadata are kept.
+//
+// A key set to different values by both is a conflict. It is resolved by the rules
+// above, and counted and logged.
+func mergeMetadata(ctx context.Context, name, field string, repoValues, metaValues map[string]string, isSystem func(key string) bool) map[string]string {
+	var merged map[string]string
+	set := func(k, v string) {
+		if merged == nil {
+			merged = make(map[string]string, len(repoValues)+len(metaValues))
+		}
+		merged[k] = v
+	}
+	for k, v := range repoValues {
+		set(k, v)
+	}
+	for k, v := range metaValues {
+		repoValue, found := repoValues[k]
+		if !found {
+			// System keys only come from the repository.
+			if !isSystem(k) {
+				set(k, v)
+			}
+			continue
+		}
+		if repoValue == v {
+			continue
+		}
+		winner := conflictWinnerMetadata
+		if isSystem(k) {
+			winner = conflictWinnerRepository
+		} else {
+			set(k, v)
+		}
+		metadataConflictCounter.Add(ctx, 1, attribute.String("field", field), attribute.String("winner", winner))
+		klog.FromContext(ctx).V(2).Info("Metadata of package revision differs in the repository and the metadata store",
+			"name", name, "field", field, "key", k, "repositoryValue", repoValue, "metadataValue", v, "winner", winner)
+	}
+	return merged
+}
+
+// mergeAnnotations returns the annotations of a package revision: the annotations of the
+// repository package revision merged with the annotations of the package revision
+// metadata. See mergeMetadata; no annotations are system annotations.
+func mergeAnnotations(ctx context.Context, name string, repoAnnotations, metaAnnotations map[string]string) map[string]string {
+	return mergeMetadata(ctx, name, "annotations", repoAnnotations, metaAnnotations, func(string) bool { return false })
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMergeLabels(t *testing.T) {
+	latest := api.LatestPackageRevisionKey
+
+	for _, tc := range []struct {
+		name string
+		repo map[string]string
+		meta map[string]string
+		want map[string]string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "disjoint keys",
+			repo: map[string]string{latest: "true", "repository.example.com/branch": "main"},
+			meta: map[string]string{"team": "a"},
+			want: map[string]string{latest: "true", "repository.example.com/branch": "main", "team": "a"},
+		},
+		{
+			name: "same values",
+			repo: map[string]string{latest: "true", "team": "a"},
+			meta: map[string]string{latest: "true", "team": "a"},
+			want: map[string]string{latest: "true", "team": "a"},
+		},
+		{
+			name: "conflicting system label",
+			repo: map[string]string{latest: "true"},
+			meta: map[string]string{latest: "false"},
+			want: map[string]string{latest: "true"},
+		},
+		{
+			name: "system label only in metadata",
+			meta: map[string]string{latest: "true", "team": "a"},
+			want: map[string]string{"team": "a"},
+		},
+		{
+			name: "conflicting user label",
+			repo: map[string]string{"team": "a"},
+			meta: map[string]string{"team": "b"},
+			want: map[string]string{"team": "b"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mergeLabels(context.Background(), "blueprints-app-v1", tc.repo, tc.meta)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected labels (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestGetPackageRevisionMergesAnnotations(t *testing.T) {
+	pkgRev := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{
+			PackageRevision: &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "blueprints-app-v1",
+					Annotations: map[string]string{"repository.example.com/commit": "abc123", "owner": "repository"},
+				},
+			},
+		},
+		packageRevisionMeta: meta.PackageRevisionMeta{
+			Annotations: map[string]string{"owner": "team-a", api.WorkspaceDescriptionAnnotation: "new blueprint"},
+		},
+	}
+
+	got, err := pkgRev.GetPackageRevision(context.Background())
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	want := map[string]string{
+		"repository.example.com/commit":    "abc123",
+		"owner":                            "team-a",
+		api.WorkspaceDescriptionAnnotation: "new blueprint",
+	}
+	if diff := cmp.Diff(want, got.Annotations); diff != "" {
+		t.Errorf("unexpected annotations (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// RepositoryExport is the state of a repository which Porch keeps outside of the
+// repository: the metadata of its package revisions. The package revisions themselves,
+// including their drafts and proposals, are kept in the repository and move with it;
+// the export refers to them by the remote of the repository.
+type RepositoryExport struct {
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	Remote    RepositoryRemote `json:"remote"`
+
+	PackageRevisions []PackageRevisionExport `json:"packageRevisions,omitempty"`
+}
+
+// RepositoryRemote identifies the storage of a repository.
+type RepositoryRemote struct {
+	Type configapi.RepositoryType `json:"type"`
+	// Address is the address of the git repository or of the OCI registry.
+	Address   string `json:"address"`
+	Branch    string `json:"branch,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+// PackageRevisionExport is the metadata of a package revision. The conditions are part
+// of the package revision in the repository; they are exported for reference, but
+// aren't imported.
+type PackageRevisionExport struct {
+	Name        string                       `json:"name"`
+	PackageName string                       `json:"packageName"`
+	Revision    string                       `json:"revision,omitempty"`
+	Lifecycle   api.PackageRevisionLifecycle `json:"lifecycle"`
+	Labels      map[string]string            `json:"labels,omitempty"`
+	Annotations map[string]string            `json:"annotations,omitempty"`
+	Conditions  []api.Condition              `json:"conditions,omitempty"`
+	Artifacts   []api.Artifact               `json:"artifacts,omitempty"`
+	Archived    bool                         `json:"archived,omitempty"`
+
+	DraftCreatedAt metav1.Time `json:"draftCreatedAt,omitempty"`
+	ProposedAt     metav1.Time `json:"proposedAt,omitempty"`
+	ProposedBy     string      `json:"proposedBy,omitempty"`
+	PublishedAt    metav1.Time `json:"publishedAt,omitempty"`
+
+	Documents map[string]json.RawMessage `json:"documents,omitempty"`
+}
+
+// ImportAction is what the import of a package revision does.
+type ImportAction string
+
+const (
+	// ImportActionCreated is reported for package revisions whose metadata is created.
+	ImportActionCreated ImportAction = "Created"
+	// ImportActionSkipped is reported for package revisions which are left unchanged.
+	ImportActionSkipped ImportAction = "Skipped"
+)
+
+// ImportResult reports the import of a single package revision.
+type ImportResult struct {
+	Name   string       `json:"name"`
+	Action ImportAction `json:"action"`
+	// Reason explains why the package revision was skipped.
+	Reason string `json:"reason,omitempty"`
+}
+
+// RepositoryRemoteMismatchError is returned when an export is imported into a
+// repository whose remote isn't the remote of the exported repository.
+//
+// RepositoryRemoteMismatchError is an API status error: it is returned to clients as a
+// Conflict status.
+type RepositoryRemoteMismatchError struct {
+	Repository string
+	Exported   RepositoryRemote
+	Actual     RepositoryRemote
+}
+
+var _ apierrors.APIStatus = &RepositoryRemoteMismatchError{}
+
+func (e *RepositoryRemoteMismatchError) Error() string {
+	return fmt.Sprintf("repository %s refers to %s; the export was made from %s", e.Repository, e.Actual, e.Exported)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *RepositoryRemoteMismatchError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusConflict,
+		Reason:  metav1.StatusReasonConflict,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: configapi.GroupVersion.Group,
+			Kind:  "repositories",
+			Name:  e.Repository,
+		},
+	}
+}
+
+func (r RepositoryRemote) String() string {
+	s := fmt.Sprintf("%s %s", r.Type, r.Address)
+	if r.Branch != "" {
+		s += " branch " + r.Branch
+	}
+	if r.Directory != "" {
+		s += " directory " + r.Directory
+	}
+	return s
+}
+
+// repositoryRemote returns the remote of the repository, normalized so remotes
+// written differently compare equal.
+func repositoryRemote(repositoryObj *configapi.Repository) RepositoryRemote {
+	switch {
+	case repositoryObj.Spec.Git != nil:
+		git := repositoryObj.Spec.Git
+		branch := git.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		return RepositoryRemote{
+			Type:      configapi.RepositoryTypeGit,
+			Address:   strings.TrimSuffix(strings.TrimSuffix(git.Repo, "/"), ".git"),
+			Branch:    branch,
+			Directory: strings.Trim(git.Directory, "/"),
+		}
+	case repositoryObj.Spec.Oci != nil:
+		return RepositoryRemote{
+			Type:    configapi.RepositoryTypeOCI,
+			Address: strings.TrimSuffix(repositoryObj.Spec.Oci.Registry, "/"),
+		}
+	default:
+		return RepositoryRemote{Type: repositoryObj.Spec.Type}
+	}
+}
+
+// ExportRepository exports the metadata of all package revisions of the repository.
+func (cad *cadEngine) ExportRepository(ctx context.Context, repositoryObj *configapi.Repository) (*RepositoryExport, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ExportRepository", trace.WithAttributes())
+	defer span.End()
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.exportRepository(ctx, repo, repositoryObj)
+}
+
+// ImportRepository recreates the package revision metadata of an export in the
+// repository, which must have the remote of the exported repository. Reading the
+// package revisions of the repository loads them into the cache.
+//
+// Metadata is only created for package revisions of the repository without metadata:
+// existing metadata is never overwritten. A dry run reports the results without
+// creating any metadata.
+func (cad *cadEngine) ImportRepository(ctx context.Context, repositoryObj *configapi.Repository, export *RepositoryExport, dryRun bool) ([]ImportResult, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ImportRepository", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.importRepository(ctx, repo, repositoryObj, export, dryRun)
+}
+
+func (cad *cadEngine) exportRepository(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository) (*RepositoryExport, error) {
+	pkgRevs, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	export := &RepositoryExport{
+		Name:      repositoryObj.Name,
+		Namespace: repositoryObj.Namespace,
+		Remote:    repositoryRemote(repositoryObj),
+	}
+	for _, pr := range pkgRevs {
+		rev, err := pr.GetPackageRevision(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export package revision %q: %w", pr.KubeObjectName(), err)
+		}
+		key := pr.Key()
+		exported := PackageRevisionExport{
+			Name:        pr.KubeObjectName(),
+			PackageName: key.Package,
+			Revision:    key.Revision,
+			Lifecycle:   pr.Lifecycle(),
+			Conditions:  rev.Status.Conditions,
+		}
+
+		namespacedName := types.NamespacedName{Name: pr.KubeObjectName(), Namespace: pr.KubeObjectNamespace()}
+		pkgRevMeta, err := cad.metadataStore.Get(ctx, namespacedName)
+		switch {
+		case err == nil:
+			exported.Labels = pkgRevMeta.Labels
+			exported.Annotations = pkgRevMeta.Annotations
+			exported.Artifacts = pkgRevMeta.Artifacts
+			exported.Archived = pkgRevMeta.IsArchived()
+			exported.DraftCreatedAt = pkgRevMeta.LifecycleTimes.DraftCreatedAt
+			exported.ProposedAt = pkgRevMeta.LifecycleTimes.ProposedAt
+			exported.ProposedBy = pkgRevMeta.LifecycleTimes.ProposedBy
+			exported.PublishedAt = pkgRevMeta.LifecycleTimes.PublishedAt
+			exported.Documents = pkgRevMeta.Documents
+		case apierrors.IsNotFound(err):
+			// Package revisions discovered in the repository have no metadata until it is
+			// first written.
+		default:
+			return nil, fmt.Errorf("cannot read metadata of package revision %q: %w", pr.KubeObjectName(), err)
+		}
+		export.PackageRevisions = append(export.PackageRevisions, exported)
+	}
+	sort.Slice(export.PackageRevisions, func(i, j int) bool {
+		return export.PackageRevisions[i].Name < export.PackageRevisions[j].Name
+	})
+	return export, nil
+}
+
+func (cad *cadEngine) importRepository(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, export *RepositoryExport, dryRun bool) ([]ImportResult, error) {
+	if actual := repositoryRemote(repositoryObj); actual != export.Remote {
+		return nil, &RepositoryRemoteMismatchError{
+			Repository: repositoryObj.Namespace + "/" + repositoryObj.Name,
+			Exported:   export.Remote,
+			Actual:     actual,
+		}
+	}
+
+	pkgRevs, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]repository.PackageRevision{}
+	for _, pr := range pkgRevs {
+		existing[pr.KubeObjectName()] = pr
+	}
+
+	var results []ImportResult
+	for _, exported := range export.PackageRevisions {
+		result, err := cad.importPackageRevision(ctx, repositoryObj, existing[exported.Name], exported, dryRun)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (cad *cadEngine) importPackageRevision(ctx context.Context, repositoryObj *configapi.Repository, pr repository.PackageRevision, exported PackageRevisionExport, dryRun bool) (ImportResult, error) {
+	result := ImportResult{Name: exported.Name, Action: ImportActionSkipped}
+	if pr == nil {
+		result.Reason = "package revision not found in the repository"
+		return result, nil
+	}
+	if lifecycle := pr.Lifecycle(); lifecycle != exported.Lifecycle {
+		klog.FromContext(ctx).Info("Package revision lifecycle differs from the export", "name", exported.Name, "exported", exported.Lifecycle, "lifecycle", lifecycle)
+	}
+
+	namespacedName := types.NamespacedName{Name: exported.Name, Namespace: repositoryObj.Namespace}
+	if _, err := cad.metadataStore.Get(ctx, namespacedName); err == nil {
+		result.Reason = "metadata already exists"
+		return result, nil
+	} else if !apierrors.IsNotFound(err) {
+		return result, fmt.Errorf("cannot read metadata of package revision %q: %w", exported.Name, err)
+	}
+
+	result.Action = ImportActionCreated
+	if dryRun {
+		return result, nil
+	}
+
+	archived := exported.Archived
+	pkgRevMeta := meta.PackageRevisionMeta{
+		Name:        exported.Name,
+		Namespace:   repositoryObj.Namespace,
+		Labels:      userLabels(exported.Labels),
+		Annotations: exported.Annotations,
+		Artifacts:   exported.Artifacts,
+		LifecycleTimes: meta.LifecycleTimes{
+			DraftCreatedAt: exported.DraftCreatedAt,
+			ProposedAt:     exported.ProposedAt,
+			ProposedBy:     exported.ProposedBy,
+			PublishedAt:    exported.PublishedAt,
+		},
+		Archived:  &archived,
+		Documents: exported.Documents,
+	}
+	if _, err := cad.metadataStore.Create(ctx, pkgRevMeta, repositoryObj); err != nil {
+		return result, fmt.Errorf("cannot create metadata of package revision %q: %w", exported.Name, err)
+	}
+	// The artifacts are only written by updates.
+	if len(pkgRevMeta.Artifacts) != 0 {
+		if _, err := cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return result, fmt.Errorf("cannot restore artifacts of package revision %q: %w", exported.Name, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func migrationRepository(repo string) *configapi.Repository {
+	return &configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"},
+		Spec: configapi.RepositorySpec{
+			Type: configapi.RepositoryTypeGit,
+			Git:  &configapi.GitRepository{Repo: repo},
+		},
+	}
+}
+
+func migrationPackageRevisions() *fake.Repository {
+	revision := func(name, revision string, lifecycle api.PackageRevisionLifecycle, conditions ...api.Condition) *fake.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: revision},
+			PackageLifecycle:   lifecycle,
+			PackageRevision:    &api.PackageRevision{Status: api.PackageRevisionStatus{Conditions: conditions}},
+		}
+	}
+	return &fake.Repository{PackageRevisions: []repository.PackageRevision{
+		revision("blueprints-2222", "v2", api.PackageRevisionLifecycleDraft, api.Condition{Type: "Tested", Status: api.ConditionTrue}),
+		revision("blueprints-1111", "v1", api.PackageRevisionLifecyclePublished),
+	}}
+}
+
+func TestExportImportRepository(t *testing.T) {
+	ctx := context.Background()
+	published := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	archived := true
+
+	source := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{
+		{
+			Name:           "blueprints-1111",
+			Namespace:      "default",
+			Labels:         map[string]string{"team": "platform"},
+			Annotations:    map[string]string{"owner": "alice"},
+			LifecycleTimes: meta.LifecycleTimes{PublishedAt: published},
+			Archived:       &archived,
+		},
+	}}
+	exporter := &cadEngine{metadataStore: source}
+	export, err := exporter.exportRepository(ctx, migrationPackageRevisions(), migrationRepository("https://github.com/example/blueprints.git"))
+	if err != nil {
+		t.Fatalf("exportRepository failed: %v", err)
+	}
+
+	want := &RepositoryExport{
+		Name:      "blueprints",
+		Namespace: "default",
+		Remote:    RepositoryRemote{Type: configapi.RepositoryTypeGit, Address: "https://github.com/example/blueprints", Branch: "main"},
+		PackageRevisions: []PackageRevisionExport{
+			{
+				Name:        "blueprints-1111",
+				PackageName: "app",
+				Revision:    "v1",
+				Lifecycle:   api.PackageRevisionLifecyclePublished,
+				Labels:      map[string]string{"team": "platform"},
+				Annotations: map[string]string{"owner": "alice"},
+				Archived:    true,
+				PublishedAt: published,
+			},
+			{
+				Name:        "blueprints-2222",
+				PackageName: "app",
+				Revision:    "v2",
+				Lifecycle:   api.PackageRevisionLifecycleDraft,
+				Conditions:  []api.Condition{{Type: "Tested", Status: api.ConditionTrue}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, export); diff != "" {
+		t.Errorf("unexpected export (-want, +got): %s", diff)
+	}
+
+	// The new installation refers to the same remote, written differently.
+	target := migrationRepository("https://github.com/example/blueprints/")
+	// A package revision which wasn't pushed to the remote before the export.
+	export.PackageRevisions = append(export.PackageRevisions, PackageRevisionExport{Name: "blueprints-3333", PackageName: "app"})
+	wantResults := []ImportResult{
+		{Name: "blueprints-1111", Action: ImportActionCreated},
+		{Name: "blueprints-2222", Action: ImportActionCreated},
+		{Name: "blueprints-3333", Action: ImportActionSkipped, Reason: "package revision not found in the repository"},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		store := &metafake.MemoryMetadataStore{}
+		importer := &cadEngine{metadataStore: store}
+		results, err := importer.importRepository(ctx, migrationPackageRevisions(), target, export, true)
+		if err != nil {
+			t.Fatalf("importRepository failed: %v", err)
+		}
+		if diff := cmp.Diff(wantResults, results); diff != "" {
+			t.Errorf("unexpected results (-want, +got): %s", diff)
+		}
+		if len(store.Metas) != 0 {
+			t.Errorf("dry run created metadata: %v", store.Metas)
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		store := &metafake.MemoryMetadataStore{}
+		importer := &cadEngine{metadataStore: store}
+		results, err := importer.importRepository(ctx, migrationPackageRevisions(), target, export, false)
+		if err != nil {
+			t.Fatalf("importRepository failed: %v", err)
+		}
+		if diff := cmp.Diff(wantResults, results); diff != "" {
+			t.Errorf("unexpected results (-want, +got): %s", diff)
+		}
+		imported, err := store.Get(ctx, types.NamespacedName{Namespace: "default", Name: "blueprints-1111"})
+		if err != nil {
+			t.Fatalf("cannot get imported metadata: %v", err)
+		}
+		if diff := cmp.Diff(source.Metas[0], imported); diff != "" {
+			t.Errorf("unexpected imported metadata (-want, +got): %s", diff)
+		}
+
+		// Importing again leaves the imported metadata alone.
+		results, err = importer.importRepository(ctx, migrationPackageRevisions(), target, export, false)
+		if err != nil {
+			t.Fatalf("importRepository failed: %v", err)
+		}
+		for _, result := range results[:2] {
+			if result.Action != ImportActionSkipped || result.Reason != "metadata already exists" {
+				t.Errorf("unexpected result of importing again: %+v", result)
+			}
+		}
+	})
+
+	t.Run("other remote", func(t *testing.T) {
+		importer := &cadEngine{metadataStore: &metafake.MemoryMetadataStore{}}
+		_, err := importer.importRepository(ctx, migrationPackageRevisions(), migrationRepository("https://github.com/example/other.git"), export, true)
+		var mismatch *RepositoryRemoteMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("got error %v; want a RepositoryRemoteMismatchError", err)
+		}
+		if !apierrors.IsConflict(err) {
+			t.Errorf("error isn't reported as a conflict: %v", err)
+		}
+	})
+}
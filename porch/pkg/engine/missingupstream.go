@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"golang.org/x/mod/semver"
+	"k8s.io/klog/v2"
+)
+
+// MissingUpstreamError is returned when a package is updated but the upstream package
+// revision it was cloned from no longer exists, so its changes to the upstream cannot be
+// determined.
+type MissingUpstreamError struct {
+	Package  string
+	Upstream string
+}
+
+func (e *MissingUpstreamError) Error() string {
+	return fmt.Sprintf("upstream package revision %q which package %q was cloned from no longer exists; "+
+		"clone the package again from an existing upstream revision", e.Upstream, e.Package)
+}
+
+// fetchOriginalResources fetches the resources of the upstream package revision the package
+// was cloned from. If it no longer exists and upstream fallback is enabled, the latest
+// published revision of the upstream package preceding the target revision is used instead.
+func (m *updatePackageMutation) fetchOriginalResources(ctx context.Context, fetcher *PackageFetcher, original *api.PackageRevisionRef, target repository.PackageRevision) (*api.PackageRevisionResources, error) {
+	resources, err := fetcher.FetchResources(ctx, original, m.namespace)
+	if err == nil {
+		return resources, nil
+	}
+	var notFound *PackageRevisionNotFoundError
+	if !errors.As(err, &notFound) {
+		return nil, fmt.Errorf("error fetching the resources for package %s with ref %+v: %w", m.pkgName, *original, err)
+	}
+	missing := &MissingUpstreamError{Package: m.pkgName, Upstream: upstreamRefName(original)}
+	// Only a package updated to a revision of a registered repository can fall back.
+	if !m.upstreamFallback || target == nil {
+		return nil, missing
+	}
+
+	// The fallback is a revision of the package of the target, in the namespace of the target.
+	fallback, err := latestPrecedingRevision(ctx, fetcher, m.namespace, m.updateTask.Update.Upstream.UpstreamRef.Namespace, target)
+	if err != nil {
+		return nil, err
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("%w; no earlier revision of the upstream package to fall back to", missing)
+	}
+	klog.FromContext(ctx).Info("Upstream revision of package no longer exists; using a fallback instead", "upstream", missing.Upstream, "fallback", fallback.KubeObjectName())
+	return fetcher.GetResources(ctx, fallback)
+}
+
+// latestPrecedingRevision returns the latest published revision of the package of target
+// whose revision precedes the revision of target, or nil if there is none.
+func latestPrecedingRevision(ctx context.Context, fetcher *PackageFetcher, namespace, repositoryNamespace string, target repository.PackageRevision) (repository.PackageRevision, error) {
+	key := target.Key()
+	repo, err := fetcher.openRepository(ctx, namespace, repositoryNamespace, key.Repository)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{Package: key.Package})
+	if err != nil {
+		return nil, err
+	}
+	return latestPublishedBefore(revisions, key), nil
+}
+
+// latestPublishedBefore returns the latest published revision among revisions of the
+// package of key whose revision precedes the revision of key, or nil if there is none.
+// If the revision of key isn't a semantic version, all published revisions precede it.
+func latestPublishedBefore(revisions []repository.PackageRevision, key repository.PackageRevisionKey) repository.PackageRevision {
+	var latest repository.PackageRevision
+	for _, rev := range revisions {
+		revision := rev.Key().Revision
+		if rev.Key().Package != key.Package || rev.Lifecycle() != api.PackageRevisionLifecyclePublished || !semver.IsValid(revision) {
+			continue
+		}
+		if semver.IsValid(key.Revision) && semver.Compare(revision, key.Revision) >= 0 {
+			continue
+		}
+		if latest == nil || semver.Compare(revision, latest.Key().Revision) > 0 {
+			latest = rev
+		}
+	}
+	return latest
+}
+
+func upstreamRefName(ref *api.PackageRevisionRef) string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	return fmt.Sprintf("%s/%s@%s", ref.Repository, ref.Package, ref.Revision)
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func TestUpdateWithDeletedUpstream(t *testing.T) {
+	ctx := context.Background()
+
+	revision := func(rev string, lifecycle api.PackageRevisionLifecycle) *fake.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               "blueprints-app-" + rev,
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: rev},
+			PackageLifecycle:   lifecycle,
+			Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{
+				Resources: map[string]string{"revision.txt": rev},
+			}},
+		}
+	}
+	// The package was cloned from v2, which has been deleted since.
+	repo := &fake.Repository{PackageRevisions: []repository.PackageRevision{
+		revision("v1", api.PackageRevisionLifecyclePublished),
+		revision("v3", api.PackageRevisionLifecyclePublished),
+		revision("v4", api.PackageRevisionLifecycleDraft),
+	}}
+
+	newMutation := func(fallback bool) *updatePackageMutation {
+		return &updatePackageMutation{
+			cloneTask: &api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{
+				Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-app-v2"}},
+			}},
+			updateTask: &api.Task{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{
+				Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-app-v3"}},
+			}},
+			repoOpener:        &fakeRepositoryOpener{repository: repo},
+			referenceResolver: &fakeReferenceResolver{},
+			namespace:         "default",
+			pkgName:           "deployment-app",
+			upstreamFallback:  fallback,
+		}
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, _, err := newMutation(false).Apply(ctx, repository.PackageResources{})
+		var missing *MissingUpstreamError
+		if !errors.As(err, &missing) {
+			t.Fatalf("update returned %v; want a MissingUpstreamError", err)
+		}
+		if got, want := missing.Upstream, "blueprints-app-v2"; got != want {
+			t.Errorf("unexpected missing upstream: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		m := newMutation(true)
+		fetcher := &PackageFetcher{repoOpener: m.repoOpener, referenceResolver: m.referenceResolver}
+		original, err := m.fetchOriginalResources(ctx, fetcher, m.cloneTask.Clone.Upstream.UpstreamRef, repo.PackageRevisions[1])
+		if err != nil {
+			t.Fatalf("fetchOriginalResources failed: %v", err)
+		}
+		if got, want := original.Spec.Resources["revision.txt"], "v1"; got != want {
+			t.Errorf("fell back to the wrong upstream revision: got %q, want %q", got, want)
+		}
+
+		// Without an earlier revision there's nothing to fall back to.
+		_, err = m.fetchOriginalResources(ctx, fetcher, m.cloneTask.Clone.Upstream.UpstreamRef, repo.PackageRevisions[0])
+		var missing *MissingUpstreamError
+		if !errors.As(err, &missing) {
+			t.Errorf("fallback without an earlier revision returned %v; want a MissingUpstreamError", err)
+		}
+	})
+}
@@ -0,0 +1,408 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/mod/semver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// A package is moved in steps which can each be repeated: a published revision is
+// copied unless the destination has its copy, and the source package is marked as moved
+// only once all revisions are copied. A move which failed part way is resumed by
+// running it again.
+
+// MoveOptions are the options of a package move.
+type MoveOptions struct {
+	// IncludeHistory moves all published revisions instead of the latest.
+	IncludeHistory bool
+	// UpdateDownstreams updates the drafts based on the moved revisions to their copies.
+	UpdateDownstreams bool
+	// DryRun reports the steps of the move without running them.
+	DryRun bool
+	// NamespaceRepositories are the repositories searched for downstreams.
+	NamespaceRepositories []configapi.Repository
+}
+
+// packageMover runs the steps of a package move which write to repositories.
+type packageMover interface {
+	// copyRevision creates a draft of the package in the repository, cloned from the
+	// source package revision with the same revision, and returns its name.
+	copyRevision(ctx context.Context, repositoryObj *configapi.Repository, packageName string, source repository.PackageRevision) (string, error)
+	// publish publishes the draft or proposed package revision.
+	publish(ctx context.Context, repositoryObj *configapi.Repository, name string) error
+	// updateDownstream updates the draft to the upstream package revision.
+	updateDownstream(ctx context.Context, repositoryObj *configapi.Repository, name, upstream string) error
+	// markMoved archives and freezes the package and sets the MovedToAnnotation on its
+	// package revisions. It returns false if the package was already marked.
+	markMoved(ctx context.Context, repositoryObj *configapi.Repository, packageName, movedTo string) (bool, error)
+}
+
+// MovePackage moves the package from the source to the destination repository. The
+// latest published revision, or all published revisions with IncludeHistory, are cloned
+// into the destination with the same revisions and published, so the upstream locks of
+// the copies point at the originals. Once all are copied, the source package is archived
+// and frozen, and its package revisions are annotated with the new location. With
+// UpdateDownstreams, the drafts based on the moved revisions are updated to the copies.
+//
+// A failed step is reported in the returned status; errors are returned for moves which
+// can't be started.
+func (cad *cadEngine) MovePackage(ctx context.Context, srcRepo, dstRepo *configapi.Repository, packageName string, opts MoveOptions) (*api.PackageMoveStatus, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::MovePackage", trace.WithAttributes())
+	defer span.End()
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: srcRepo.Name, Package: packageName})
+
+	return movePackage(ctx, cad, &cadPackageMover{cad: cad}, srcRepo, dstRepo, packageName, opts)
+}
+
+func movePackage(ctx context.Context, opener RepositoryOpener, mover packageMover, srcRepo, dstRepo *configapi.Repository, packageName string, opts MoveOptions) (*api.PackageMoveStatus, error) {
+	if srcRepo.Namespace != dstRepo.Namespace || srcRepo.Name == dstRepo.Name {
+		return nil, apierrors.NewBadRequest("a package can only be moved to another repository of the same namespace")
+	}
+
+	sources, err := listPackage(ctx, opener, srcRepo, packageName)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, apierrors.NewNotFound(api.PackageGVR.GroupResource(), packageName)
+	}
+	var published []repository.PackageRevision
+	for _, rev := range sources {
+		if rev.Lifecycle() == api.PackageRevisionLifecyclePublished {
+			published = append(published, rev)
+		}
+	}
+	if len(published) == 0 {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("package %q of repository %q has no published revisions to move", packageName, srcRepo.Name))
+	}
+	sort.Slice(published, func(i, j int) bool {
+		return olderRevision(published[i].Key().Revision, published[j].Key().Revision)
+	})
+	if !opts.IncludeHistory {
+		published = published[len(published)-1:]
+	}
+
+	copies, err := listCopies(ctx, opener, srcRepo, dstRepo, packageName, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &api.PackageMoveStatus{}
+	// moved maps the names of the moved package revisions to the names of their copies.
+	moved := map[string]string{}
+	failed := false
+	for _, source := range published {
+		result := api.MovedPackageRevision{Revision: source.Key().Revision, Source: source.KubeObjectName()}
+		existing := copies[source.Key().Revision]
+		switch {
+		case failed:
+			result.Outcome, result.Message = api.PackageMoveOutcomeSkipped, "an older revision failed to move"
+		case existing != nil && existing.Lifecycle() == api.PackageRevisionLifecyclePublished:
+			result.Destination, result.Outcome = existing.KubeObjectName(), api.PackageMoveOutcomeAlreadyDone
+		case opts.DryRun:
+			result.Outcome = api.PackageMoveOutcomePending
+			if existing != nil {
+				result.Destination = existing.KubeObjectName()
+			}
+		default:
+			destination, err := copyAndPublish(ctx, mover, dstRepo, packageName, source, existing)
+			result.Destination = destination
+			if err != nil {
+				result.Outcome, result.Message = api.PackageMoveOutcomeFailed, err.Error()
+				failed = true
+			} else {
+				result.Outcome = api.PackageMoveOutcomeDone
+			}
+		}
+		if result.Outcome != api.PackageMoveOutcomeSkipped && result.Outcome != api.PackageMoveOutcomeFailed {
+			moved[result.Source] = result.Destination
+		}
+		status.Revisions = append(status.Revisions, result)
+	}
+
+	if opts.UpdateDownstreams {
+		downstreams, err := moveDownstreams(ctx, opener, mover, srcRepo, dstRepo, packageName, sources, moved, opts)
+		if err != nil {
+			return nil, err
+		}
+		status.Downstreams = downstreams
+	}
+
+	movedTo := dstRepo.Name + "/" + packageName
+	switch {
+	case failed:
+		status.Source, status.SourceMessage = api.PackageMoveOutcomeSkipped, "not all revisions were moved"
+	case opts.DryRun:
+		status.Source = api.PackageMoveOutcomePending
+	default:
+		marked, err := mover.markMoved(ctx, srcRepo, packageName, movedTo)
+		switch {
+		case err != nil:
+			status.Source, status.SourceMessage = api.PackageMoveOutcomeFailed, err.Error()
+		case marked:
+			status.Source = api.PackageMoveOutcomeDone
+		default:
+			status.Source = api.PackageMoveOutcomeAlreadyDone
+		}
+	}
+	return status, nil
+}
+
+// copyAndPublish copies the source package revision to the destination repository and
+// publishes the copy. A copy left unpublished by an earlier move is published.
+func copyAndPublish(ctx context.Context, mover packageMover, dstRepo *configapi.Repository, packageName string, source, existing repository.PackageRevision) (string, error) {
+	var name string
+	if existing != nil {
+		name = existing.KubeObjectName()
+	} else {
+		created, err := mover.copyRevision(ctx, dstRepo, packageName, source)
+		if err != nil {
+			return "", fmt.Errorf("cannot copy package revision %q: %w", source.KubeObjectName(), err)
+		}
+		name = created
+	}
+	if err := mover.publish(ctx, dstRepo, name); err != nil {
+		return name, fmt.Errorf("cannot publish package revision %q: %w", name, err)
+	}
+	return name, nil
+}
+
+// listCopies returns the package revisions of the package in the destination repository
+// by revision. It returns a Conflict error if any of them isn't a copy of the package
+// revision with the same revision in the source repository.
+func listCopies(ctx context.Context, opener RepositoryOpener, srcRepo, dstRepo *configapi.Repository, packageName string, sources []repository.PackageRevision) (map[string]repository.PackageRevision, error) {
+	sourceNames := map[string]string{}
+	for _, rev := range sources {
+		sourceNames[rev.Key().Revision] = rev.KubeObjectName()
+	}
+	revisions, err := listPackage(ctx, opener, dstRepo, packageName)
+	if err != nil {
+		return nil, err
+	}
+	copies := map[string]repository.PackageRevision{}
+	for _, rev := range revisions {
+		apiRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+		revision := rev.Key().Revision
+		if upstream := clonedFrom(apiRev); upstream == "" || upstream != sourceNames[revision] {
+			return nil, apierrors.NewConflict(api.PackageGVR.GroupResource(), packageName,
+				fmt.Errorf("package revision %q of repository %q isn't a copy of revision %q of the package in repository %q", rev.KubeObjectName(), dstRepo.Name, revision, srcRepo.Name))
+		}
+		copies[revision] = rev
+	}
+	return copies, nil
+}
+
+// moveDownstreams updates the drafts based on the moved package revisions to their
+// copies. Other downstreams keep their upstream until their next revision is drafted.
+func moveDownstreams(ctx context.Context, opener RepositoryOpener, mover packageMover, srcRepo, dstRepo *configapi.Repository, packageName string, sources []repository.PackageRevision, moved map[string]string, opts MoveOptions) ([]api.MovedDownstream, error) {
+	upstreams := map[string]repository.PackageRevision{}
+	for _, rev := range sources {
+		upstreams[rev.KubeObjectName()] = rev
+	}
+	downstreams, err := listDownstreams(ctx, opener, srcRepo.Namespace, upstreams, opts.NamespaceRepositories)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []api.MovedDownstream
+	for _, d := range downstreams {
+		if d.repositoryObj.Name == dstRepo.Name && d.rev.Key().Package == packageName {
+			// The copies are clones of the moved package revisions.
+			continue
+		}
+		result := api.MovedDownstream{Name: d.rev.KubeObjectName(), Upstream: d.upstream}
+		newUpstream, found := moved[d.upstream]
+		switch {
+		case !found:
+			result.Outcome = api.PackageMoveOutcomeSkipped
+			result.Message = fmt.Sprintf("upstream %q isn't moved", d.upstream)
+		case d.rev.Lifecycle() != api.PackageRevisionLifecycleDraft:
+			result.Outcome = api.PackageMoveOutcomeSkipped
+			result.Message = fmt.Sprintf("only drafts are updated; the package revision is %s", d.rev.Lifecycle())
+		case opts.DryRun:
+			result.NewUpstream, result.Outcome = newUpstream, api.PackageMoveOutcomePending
+		default:
+			result.NewUpstream = newUpstream
+			if err := mover.updateDownstream(ctx, d.repositoryObj, result.Name, newUpstream); err != nil {
+				result.Outcome, result.Message = api.PackageMoveOutcomeFailed, err.Error()
+			} else {
+				result.Outcome = api.PackageMoveOutcomeDone
+			}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+// listPackage returns the package revisions of the package in the repository.
+func listPackage(ctx context.Context, opener RepositoryOpener, repositoryObj *configapi.Repository, packageName string) ([]repository.PackageRevision, error) {
+	repo, err := opener.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open repository %s:%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+	}
+	revisions, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list package revisions of repository %s:%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+	}
+	var result []repository.PackageRevision
+	for _, rev := range revisions {
+		if rev.Key().Package == packageName {
+			result = append(result, rev)
+		}
+	}
+	return result, nil
+}
+
+// clonedFrom returns the name of the package revision rev was cloned from, if any.
+func clonedFrom(rev *api.PackageRevision) string {
+	cloneTask := findCloneTask(rev)
+	if cloneTask == nil || cloneTask.Clone == nil {
+		return ""
+	}
+	if ref := cloneTask.Clone.Upstream.UpstreamRef; ref != nil && ref.Name != "" {
+		return ref.Name
+	}
+	if ref := cloneTask.Clone.ResolvedUpstreamRef; ref != nil {
+		return ref.Name
+	}
+	return ""
+}
+
+// olderRevision orders revisions by semantic version if both are, and by name otherwise.
+func olderRevision(a, b string) bool {
+	if semver.IsValid(a) && semver.IsValid(b) {
+		if c := semver.Compare(a, b); c != 0 {
+			return c < 0
+		}
+	}
+	return a < b
+}
+
+// cadPackageMover runs the steps of package moves with the engine, so they are
+// validated, rendered and recorded like the same requests of users.
+type cadPackageMover struct {
+	cad *cadEngine
+}
+
+var _ packageMover = &cadPackageMover{}
+
+func (m *cadPackageMover) copyRevision(ctx context.Context, repositoryObj *configapi.Repository, packageName string, source repository.PackageRevision) (string, error) {
+	obj := &api.PackageRevision{
+		Spec: api.PackageRevisionSpec{
+			PackageName:    packageName,
+			Revision:       source.Key().Revision,
+			RepositoryName: repositoryObj.Name,
+			Lifecycle:      api.PackageRevisionLifecycleDraft,
+			Tasks: []api.Task{{
+				Type: api.TaskTypeClone,
+				Clone: &api.PackageCloneTaskSpec{
+					Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: source.KubeObjectName()}},
+				},
+			}},
+		},
+	}
+	obj.Namespace = repositoryObj.Namespace
+	created, err := m.cad.CreatePackageRevision(ctx, repositoryObj, obj, nil)
+	if err != nil {
+		return "", err
+	}
+	return created.KubeObjectName(), nil
+}
+
+func (m *cadPackageMover) publish(ctx context.Context, repositoryObj *configapi.Repository, name string) error {
+	rev, err := m.cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return err
+	}
+	oldObj, err := rev.GetPackageRevision(ctx)
+	if err != nil {
+		return err
+	}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Lifecycle = api.PackageRevisionLifecyclePublished
+	_, err = m.cad.UpdatePackageRevision(ctx, repositoryObj, rev, oldObj, newObj, nil)
+	return err
+}
+
+func (m *cadPackageMover) updateDownstream(ctx context.Context, repositoryObj *configapi.Repository, name, upstream string) error {
+	rev, err := m.cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return err
+	}
+	oldObj, err := rev.GetPackageRevision(ctx)
+	if err != nil {
+		return err
+	}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Tasks = append(newObj.Spec.Tasks, api.Task{
+		Type: api.TaskTypeUpdate,
+		Update: &api.PackageUpdateTaskSpec{
+			Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: upstream}},
+		},
+	})
+	_, err = m.cad.UpdatePackageRevision(ctx, repositoryObj, rev, oldObj, newObj, nil)
+	return err
+}
+
+func (m *cadPackageMover) markMoved(ctx context.Context, repositoryObj *configapi.Repository, packageName, movedTo string) (bool, error) {
+	revisions, _, err := m.cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return false, err
+	}
+	freeze := m.cad.newPackageFreeze(ctx, fmt.Sprintf("moved to %s", movedTo))
+	archived := true
+	marked := false
+	for _, rev := range revisions {
+		pkgRevMeta := rev.packageRevisionMeta
+		if rev.IsArchived() && pkgRevMeta.IsFrozen() && pkgRevMeta.Annotations[api.MovedToAnnotation] == movedTo {
+			continue
+		}
+		annotations := map[string]string{}
+		for k, v := range pkgRevMeta.Annotations {
+			annotations[k] = v
+		}
+		annotations[api.MovedToAnnotation] = movedTo
+		pkgRevMeta.Annotations = annotations
+		pkgRevMeta.Archived = &archived
+		if !pkgRevMeta.IsFrozen() {
+			pkgRevMeta.Freeze = freeze
+		}
+		// Leave the status of the PackageRev alone.
+		pkgRevMeta.Artifacts = nil
+		pkgRevMeta.DeploymentStatus = nil
+		pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+		if _, err := m.cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return false, fmt.Errorf("cannot update metadata of package revision %q: %w", rev.KubeObjectName(), err)
+		}
+		marked = true
+	}
+	return marked, nil
+}
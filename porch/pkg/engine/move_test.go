@@ -0,0 +1,246 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeMover runs the steps of moves on fake repositories. Copying fails for the
+// revisions in failCopy.
+type fakeMover struct {
+	repos    *fakeRepositories
+	failCopy map[string]bool
+
+	updated map[string]string
+	marked  map[string]string
+}
+
+var _ packageMover = &fakeMover{}
+
+func (m *fakeMover) copyRevision(ctx context.Context, repositoryObj *configapi.Repository, packageName string, source repository.PackageRevision) (string, error) {
+	revision := source.Key().Revision
+	if m.failCopy[revision] {
+		return "", errors.New("clone failed")
+	}
+	name := fmt.Sprintf("%s-%s-%s", repositoryObj.Name, packageName, revision)
+	repo := m.repos.repositories[repositoryObj.Name]
+	repo.PackageRevisions = append(repo.PackageRevisions, moveRevision(name, repositoryObj.Name, packageName, revision, api.PackageRevisionLifecycleDraft, source.KubeObjectName()))
+	return name, nil
+}
+
+func (m *fakeMover) publish(ctx context.Context, repositoryObj *configapi.Repository, name string) error {
+	for _, rev := range m.repos.repositories[repositoryObj.Name].PackageRevisions {
+		if rev.KubeObjectName() == name {
+			rev.(*fake.PackageRevision).PackageLifecycle = api.PackageRevisionLifecyclePublished
+			return nil
+		}
+	}
+	return fmt.Errorf("package revision %q not found", name)
+}
+
+func (m *fakeMover) updateDownstream(ctx context.Context, repositoryObj *configapi.Repository, name, upstream string) error {
+	m.updated[name] = upstream
+	return nil
+}
+
+func (m *fakeMover) markMoved(ctx context.Context, repositoryObj *configapi.Repository, packageName, movedTo string) (bool, error) {
+	if m.marked[packageName] == movedTo {
+		return false, nil
+	}
+	m.marked[packageName] = movedTo
+	return true, nil
+}
+
+func moveRevision(name, repo, pkg, revision string, lifecycle api.PackageRevisionLifecycle, clonedFrom string) *fake.PackageRevision {
+	var tasks []api.Task
+	if clonedFrom != "" {
+		tasks = append(tasks, api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{
+			Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: clonedFrom}},
+		}})
+	}
+	return &fake.PackageRevision{
+		Name:               name,
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: repo, Package: pkg, Revision: revision},
+		PackageLifecycle:   lifecycle,
+		PackageRevision: &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       api.PackageRevisionSpec{PackageName: pkg, Revision: revision, Lifecycle: lifecycle, Tasks: tasks},
+		},
+	}
+}
+
+func TestMovePackage(t *testing.T) {
+	ctx := context.Background()
+	published := api.PackageRevisionLifecyclePublished
+	draft := api.PackageRevisionLifecycleDraft
+
+	setup := func() (*fakeRepositories, []configapi.Repository) {
+		repos := &fakeRepositories{objects: map[string]*configapi.Repository{}, repositories: map[string]*fake.Repository{}}
+		add := func(name string, revisions ...repository.PackageRevision) {
+			repos.objects[name] = &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+			repos.repositories[name] = &fake.Repository{PackageRevisions: revisions}
+		}
+		add("old",
+			moveRevision("old-app-v1", "old", "app", "v1", published, ""),
+			moveRevision("old-app-v2", "old", "app", "v2", published, ""),
+			moveRevision("old-app-v3", "old", "app", "v3", draft, ""),
+		)
+		add("new")
+		prod := moveRevision("deployments-prod-v1", "deployments", "prod", "v1", published, "old-app-v2")
+		prod.PackageRevision.Labels = map[string]string{api.LatestPackageRevisionKey: api.LatestPackageRevisionValue}
+		add("deployments",
+			moveRevision("deployments-dev-v1", "deployments", "dev", "v1", draft, "old-app-v2"),
+			prod,
+			moveRevision("deployments-test-v1", "deployments", "test", "v1", draft, "old-app-v1"),
+		)
+		var repositories []configapi.Repository
+		for _, name := range []string{"old", "new", "deployments"} {
+			repositories = append(repositories, *repos.objects[name])
+		}
+		return repos, repositories
+	}
+
+	t.Run("latest", func(t *testing.T) {
+		repos, repositories := setup()
+		mover := &fakeMover{repos: repos, updated: map[string]string{}, marked: map[string]string{}}
+		opts := MoveOptions{UpdateDownstreams: true, NamespaceRepositories: repositories}
+
+		// The dry run reports the steps without running them.
+		opts.DryRun = true
+		status, err := movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", opts)
+		if err != nil {
+			t.Fatalf("movePackage failed: %v", err)
+		}
+		want := &api.PackageMoveStatus{
+			Revisions: []api.MovedPackageRevision{{Revision: "v2", Source: "old-app-v2", Outcome: api.PackageMoveOutcomePending}},
+			Downstreams: []api.MovedDownstream{
+				{Name: "deployments-dev-v1", Upstream: "old-app-v2", Outcome: api.PackageMoveOutcomePending},
+				{Name: "deployments-prod-v1", Upstream: "old-app-v2", Outcome: api.PackageMoveOutcomeSkipped, Message: "only drafts are updated; the package revision is Published"},
+				{Name: "deployments-test-v1", Upstream: "old-app-v1", Outcome: api.PackageMoveOutcomeSkipped, Message: `upstream "old-app-v1" isn't moved`},
+			},
+			Source: api.PackageMoveOutcomePending,
+		}
+		if diff := cmp.Diff(want, status); diff != "" {
+			t.Errorf("unexpected dry run status (-want, +got): %s", diff)
+		}
+		if len(repos.repositories["new"].PackageRevisions) != 0 || len(mover.updated) != 0 || len(mover.marked) != 0 {
+			t.Fatalf("dry run moved the package")
+		}
+
+		opts.DryRun = false
+		status, err = movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", opts)
+		if err != nil {
+			t.Fatalf("movePackage failed: %v", err)
+		}
+		want.Revisions[0].Destination, want.Revisions[0].Outcome = "new-app-v2", api.PackageMoveOutcomeDone
+		want.Downstreams[0].NewUpstream, want.Downstreams[0].Outcome = "new-app-v2", api.PackageMoveOutcomeDone
+		want.Source = api.PackageMoveOutcomeDone
+		if diff := cmp.Diff(want, status); diff != "" {
+			t.Errorf("unexpected status (-want, +got): %s", diff)
+		}
+		if diff := cmp.Diff(map[string]string{"deployments-dev-v1": "new-app-v2"}, mover.updated); diff != "" {
+			t.Errorf("unexpected downstream updates (-want, +got): %s", diff)
+		}
+		if got := mover.marked["app"]; got != "new/app" {
+			t.Errorf("source package was marked as moved to %q; want new/app", got)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		repos, repositories := setup()
+		mover := &fakeMover{repos: repos, failCopy: map[string]bool{"v2": true}, updated: map[string]string{}, marked: map[string]string{}}
+		opts := MoveOptions{IncludeHistory: true, NamespaceRepositories: repositories}
+
+		status, err := movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", opts)
+		if err != nil {
+			t.Fatalf("movePackage failed: %v", err)
+		}
+		want := &api.PackageMoveStatus{
+			Revisions: []api.MovedPackageRevision{
+				{Revision: "v1", Source: "old-app-v1", Destination: "new-app-v1", Outcome: api.PackageMoveOutcomeDone},
+				{Revision: "v2", Source: "old-app-v2", Outcome: api.PackageMoveOutcomeFailed, Message: `cannot copy package revision "old-app-v2": clone failed`},
+			},
+			Source:        api.PackageMoveOutcomeSkipped,
+			SourceMessage: "not all revisions were moved",
+		}
+		if diff := cmp.Diff(want, status); diff != "" {
+			t.Errorf("unexpected status of the failed move (-want, +got): %s", diff)
+		}
+		if len(mover.marked) != 0 {
+			t.Errorf("source of a partial move was marked as moved")
+		}
+
+		// Running the move again resumes it.
+		mover.failCopy = nil
+		status, err = movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", opts)
+		if err != nil {
+			t.Fatalf("movePackage failed: %v", err)
+		}
+		want = &api.PackageMoveStatus{
+			Revisions: []api.MovedPackageRevision{
+				{Revision: "v1", Source: "old-app-v1", Destination: "new-app-v1", Outcome: api.PackageMoveOutcomeAlreadyDone},
+				{Revision: "v2", Source: "old-app-v2", Destination: "new-app-v2", Outcome: api.PackageMoveOutcomeDone},
+			},
+			Source: api.PackageMoveOutcomeDone,
+		}
+		if diff := cmp.Diff(want, status); diff != "" {
+			t.Errorf("unexpected status of the resumed move (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("unpublished copy", func(t *testing.T) {
+		repos, repositories := setup()
+		// An earlier move copied the revision but failed to publish it.
+		repos.repositories["new"].PackageRevisions = append(repos.repositories["new"].PackageRevisions,
+			moveRevision("new-app-v2", "new", "app", "v2", draft, "old-app-v2"))
+		mover := &fakeMover{repos: repos, marked: map[string]string{}}
+
+		status, err := movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", MoveOptions{NamespaceRepositories: repositories})
+		if err != nil {
+			t.Fatalf("movePackage failed: %v", err)
+		}
+		want := []api.MovedPackageRevision{{Revision: "v2", Source: "old-app-v2", Destination: "new-app-v2", Outcome: api.PackageMoveOutcomeDone}}
+		if diff := cmp.Diff(want, status.Revisions); diff != "" {
+			t.Errorf("unexpected moved revisions (-want, +got): %s", diff)
+		}
+		if got := len(repos.repositories["new"].PackageRevisions); got != 1 {
+			t.Errorf("destination has %d package revisions; want the published copy only", got)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		repos, repositories := setup()
+		repos.repositories["new"].PackageRevisions = append(repos.repositories["new"].PackageRevisions,
+			moveRevision("new-app-v1", "new", "app", "v1", published, ""))
+		mover := &fakeMover{repos: repos, marked: map[string]string{}}
+
+		_, err := movePackage(ctx, repos, mover, repos.objects["old"], repos.objects["new"], "app", MoveOptions{NamespaceRepositories: repositories})
+		if !apierrors.IsConflict(err) {
+			t.Errorf("moving to a repository with another package of the same name returned %v; want a Conflict", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// deploymentNamespace returns whether packages cloned into the repository get a
+// target namespace stamped on their resources, and the namespace configured with
+// the configapi.DeploymentNamespaceAnnotation. An empty namespace is derived from
+// the package context of each package.
+func (cad *cadEngine) deploymentNamespace(repositoryObj *configapi.Repository) (string, bool) {
+	if !repositoryObj.Spec.Deployment {
+		return "", false
+	}
+	namespace := repositoryObj.Annotations[configapi.DeploymentNamespaceAnnotation]
+	return namespace, cad.stampDeploymentNamespace || namespace != ""
+}
+
+// packageContextNamespace derives the target namespace of a deployment package from
+// the name in its package context.
+func packageContextNamespace(resources repository.PackageResources) (string, error) {
+	contents, found := resources.Contents[builtins.PkgContextFile]
+	if !found {
+		return "", fmt.Errorf("package context %s not found", builtins.PkgContextFile)
+	}
+	node, err := yaml.Parse(contents)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse package context: %w", err)
+	}
+	name := node.GetDataMap()["name"]
+	if name == "" {
+		return "", fmt.Errorf("package context %s doesn't specify a name", builtins.PkgContextFile)
+	}
+	return name, nil
+}
+
+// stampNamespace sets the namespace of all namespaced resources of the package.
+// Cluster-scoped resources, the Kptfile and local config resources are left alone.
+// Resources of kinds unknown to the built-in schema, such as custom resources, are
+// treated as namespaced.
+func stampNamespace(ctx context.Context, resources repository.PackageResources, namespace string) (repository.PackageResources, error) {
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return repository.PackageResources{}, fmt.Errorf("invalid target namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+
+	pr := &packageReader{
+		input: resources,
+		extra: map[string]string{},
+	}
+
+	result := repository.PackageResources{
+		Contents: map[string]string{},
+	}
+
+	setNamespace := kio.FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+		for _, node := range nodes {
+			if node.GetKind() == kptfile.KptFileKind {
+				continue
+			}
+			if _, local := node.GetAnnotations()[filters.LocalConfigAnnotation]; local {
+				continue
+			}
+			if openapi.IsCertainlyClusterScoped(yaml.TypeMeta{APIVersion: node.GetApiVersion(), Kind: node.GetKind()}) {
+				continue
+			}
+			if err := node.SetNamespace(namespace); err != nil {
+				return nil, err
+			}
+		}
+		return nodes, nil
+	})
+
+	pipeline := kio.Pipeline{
+		Inputs:  []kio.Reader{pr},
+		Filters: []kio.Filter{setNamespace},
+		Outputs: []kio.Writer{&packageWriter{
+			output: result,
+		}},
+	}
+
+	if err := pipeline.Execute(); err != nil {
+		return repository.PackageResources{}, fmt.Errorf("failed to set namespace %q: %w", namespace, err)
+	}
+
+	for k, v := range pr.extra {
+		result.Contents[k] = v
+	}
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	kptoci "github.com/GoogleContainerTools/kpt/pkg/oci"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/oci"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ociPackageOpener reads packages from OCI images which aren't in a registered repository.
+type ociPackageOpener interface {
+	// ResolveImage returns the digest of the image. An image referenced by tag is looked
+	// up in its registry; an image referenced by digest is returned as it is.
+	ResolveImage(ctx context.Context, image string) (kptoci.ImageDigestName, error)
+	// LoadResources returns the resources of the package in the image.
+	LoadResources(ctx context.Context, image kptoci.ImageDigestName) (repository.PackageResources, error)
+}
+
+// registryPackageOpener reads packages from their registries, authenticating like the
+// OCI repositories do.
+type registryPackageOpener struct{}
+
+var _ ociPackageOpener = registryPackageOpener{}
+
+func (registryPackageOpener) ResolveImage(ctx context.Context, image string) (kptoci.ImageDigestName, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return kptoci.ImageDigestName{}, fmt.Errorf("cannot parse image name %q: %w", image, err)
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		return kptoci.ImageDigestName{Image: digest.Context().Name(), Digest: digest.DigestStr()}, nil
+	}
+
+	var resolved *kptoci.ImageDigestName
+	if err := withTemporaryStorage(func(s *kptoci.Storage) error {
+		resolved, err = oci.LookupImageTag(ctx, s, kptoci.ImageTagName{Image: ref.Context().Name(), Tag: ref.Identifier()})
+		return err
+	}); err != nil {
+		return kptoci.ImageDigestName{}, fmt.Errorf("cannot resolve image %q: %w", image, err)
+	}
+	return *resolved, nil
+}
+
+func (registryPackageOpener) LoadResources(ctx context.Context, image kptoci.ImageDigestName) (repository.PackageResources, error) {
+	var resources *repository.PackageResources
+	if err := withTemporaryStorage(func(s *kptoci.Storage) error {
+		var err error
+		resources, err = oci.LoadResources(ctx, s, &image)
+		return err
+	}); err != nil {
+		return repository.PackageResources{}, fmt.Errorf("cannot load image %s: %w", ociImageName(image), err)
+	}
+	return *resources, nil
+}
+
+// withTemporaryStorage runs fn with OCI storage which is removed once fn returns.
+// TODO: Cache unregistered images with appropriate cache eviction policy.
+func withTemporaryStorage(fn func(s *kptoci.Storage) error) error {
+	dir, err := os.MkdirTemp("", "oci-package-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory to pull OCI image: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := kptoci.NewStorage(dir)
+	if err != nil {
+		return err
+	}
+	return fn(s)
+}
+
+// fetchOciPackage reads the package from an OCI image, and returns its resources and the
+// digest it was read from.
+func fetchOciPackage(ctx context.Context, opener ociPackageOpener, ociPackage *api.OciPackage) (repository.PackageResources, kptoci.ImageDigestName, error) {
+	image, err := opener.ResolveImage(ctx, ociPackage.Image)
+	if err != nil {
+		return repository.PackageResources{}, kptoci.ImageDigestName{}, err
+	}
+	resources, err := opener.LoadResources(ctx, image)
+	if err != nil {
+		return repository.PackageResources{}, kptoci.ImageDigestName{}, err
+	}
+	return resources, image, nil
+}
+
+// ociImageName returns the name of the image referenced by its digest.
+func ociImageName(image kptoci.ImageDigestName) string {
+	return image.Image + "@" + image.Digest
+}
+
+// upstreamBefore returns the upstream set by the last clone or update task of obj before
+// task, which must be one of its tasks.
+func upstreamBefore(obj *api.PackageRevision, task *api.Task) *api.UpstreamPackage {
+	var upstream *api.UpstreamPackage
+	for i := range obj.Spec.Tasks {
+		t := &obj.Spec.Tasks[i]
+		if t == task {
+			break
+		}
+		switch {
+		case t.Type == api.TaskTypeClone && t.Clone != nil:
+			upstream = &t.Clone.Upstream
+		case t.Type == api.TaskTypeUpdate && t.Update != nil:
+			upstream = &t.Update.Upstream
+		}
+	}
+	return upstream
+}
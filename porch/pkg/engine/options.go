@@ -16,12 +16,14 @@ package engine
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/pkg/fn"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
 )
 
 type EngineOption interface {
@@ -109,6 +111,15 @@ func WithReferenceResolver(resolver ReferenceResolver) EngineOption {
 	})
 }
 
+// WithRepositoryLister sets the lister of the registered repositories, which git and OCI
+// upstreams are matched against when external sources are restricted.
+func WithRepositoryLister(lister RepositoryLister) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.repositoryLister = lister
+		return nil
+	})
+}
+
 func WithUserInfoProvider(provider repository.UserInfoProvider) EngineOption {
 	return EngineOptionFunc(func(engine *cadEngine) error {
 		engine.userInfoProvider = provider
@@ -122,3 +133,348 @@ func WithMetadataStore(metadataStore meta.MetadataStore) EngineOption {
 		return nil
 	})
 }
+
+// WithPackageSizeBudget limits the number of files and total bytes accepted from
+// an upstream package during clone, update and edit. Zero means unlimited.
+func WithPackageSizeBudget(maxFiles int, maxBytes int64) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if maxFiles < 0 || maxBytes < 0 {
+			return fmt.Errorf("package size budget must not be negative")
+		}
+		engine.sizeBudget = PackageSizeBudget{
+			MaxFiles: maxFiles,
+			MaxBytes: maxBytes,
+		}
+		return nil
+	})
+}
+
+// WithRenderChangeReport makes every render record which pipeline function
+// changed which resources in the Changes of its render task.
+func WithRenderChangeReport() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.recordRenderChanges = true
+		return nil
+	})
+}
+
+// WithRenderConcurrency renders up to maxConcurrency independent subpackages
+// concurrently. Values below 2 render subpackages sequentially.
+func WithRenderConcurrency(maxConcurrency int) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.renderConcurrency = maxConcurrency
+		return nil
+	})
+}
+
+// WithDeferredRender makes updates of the resources of a draft store them without
+// rendering the package. The draft is marked with the api.RenderedConditionType
+// condition, and rendered once when it is proposed or published.
+func WithDeferredRender() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.deferRender = true
+		return nil
+	})
+}
+
+// WithForceRender renders packages after their tasks even if their Kptfiles declare
+// no pipeline, which normalizes the formatting of their resources.
+func WithForceRender() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.forceRender = true
+		return nil
+	})
+}
+
+// WithStrictTasks rejects package revisions whose tasks don't start with an init or
+// clone task, instead of creating an empty package with an inserted init task.
+func WithStrictTasks() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.strictTasks = true
+		return nil
+	})
+}
+
+// WithStrictTaskValidation rejects package revisions whose tasks have fields which are
+// not part of the API, such as misspelled fields, instead of dropping the fields.
+func WithStrictTaskValidation() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.strictTaskValidation = true
+		return nil
+	})
+}
+
+// WithDeploymentNamespace sets the namespace of the namespaced resources of packages
+// cloned into deployment repositories to the name in their package context, unless
+// the repository configures the namespace with configapi.DeploymentNamespaceAnnotation.
+func WithDeploymentNamespace() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.stampDeploymentNamespace = true
+		return nil
+	})
+}
+
+// WithUpstreamFallback makes updates of packages whose upstream revision was deleted use the
+// latest earlier revision of the upstream package as the base of the update, instead of
+// failing with a MissingUpstreamError.
+func WithUpstreamFallback() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.upstreamFallback = true
+		return nil
+	})
+}
+
+// WithAllowedFunctionImages restricts the functions package revisions can run, in eval
+// tasks and in Kptfile pipelines, to the allowed images. See AllowedFunctionImages.
+func WithAllowedFunctionImages(images AllowedFunctionImages) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.allowedFunctionImages = images
+		return nil
+	})
+}
+
+// WithRestrictedExternalSources forbids package revisions from fetching anything from
+// outside the registered repositories: clone and update tasks can only refer to git
+// repositories or OCI images stored in repositories registered in the namespace of the
+// package revision, and functions must be pulled from the allowed function images, which
+// must be set. Violations fail with a PolicyViolationError.
+func WithRestrictedExternalSources() EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.restrictExternalSources = true
+		return nil
+	})
+}
+
+// WithFunctionSecretNamespaces allows package revisions of the namespaces to reference
+// Secrets in the function config of eval tasks. The Secrets are read with the reference
+// resolver.
+func WithFunctionSecretNamespaces(namespaces []string) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.functionSecretNamespaces = namespaces
+		return nil
+	})
+}
+
+// WithEvalConflictPolicy sets how conflicting changes made by the eval tasks of a
+// package revision are resolved. See EvalConflictPolicy.
+func WithEvalConflictPolicy(policy EvalConflictPolicy) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if _, err := ParseEvalConflictPolicy(string(policy)); err != nil {
+			return err
+		}
+		engine.evalConflictPolicy = policy
+		return nil
+	})
+}
+
+// WithWorkspaceNameTemplate generates the workspace name of package revisions created
+// without an explicit revision from a template. See ParseWorkspaceNameTemplate.
+// An empty template disables workspace name generation.
+func WithWorkspaceNameTemplate(text string) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if text == "" {
+			engine.workspaceNameTemplate = nil
+			return nil
+		}
+		tmpl, err := ParseWorkspaceNameTemplate(text)
+		if err != nil {
+			return err
+		}
+		engine.workspaceNameTemplate = tmpl
+		return nil
+	})
+}
+
+// WithDescriptionTemplate generates the description of packages created without one
+// from a template. See ParseDescriptionTemplate. The configapi.DescriptionTemplateAnnotation
+// of a repository takes precedence over the template. An empty template restores the
+// built-in description.
+func WithDescriptionTemplate(text string) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if text == "" {
+			engine.descriptionTemplate = nil
+			return nil
+		}
+		tmpl, err := ParseDescriptionTemplate(text)
+		if err != nil {
+			return err
+		}
+		engine.descriptionTemplate = tmpl
+		return nil
+	})
+}
+
+// WithStarterResources replaces the starter resources init tasks can add to packages.
+// See StarterResources.
+func WithStarterResources(starters StarterResources) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		templates, err := parseStarterResources(starters)
+		if err != nil {
+			return err
+		}
+		engine.starterResources = templates
+		return nil
+	})
+}
+
+// WithUpstreamVerifier makes clone verify the upstream package with the verifier
+// before accepting its contents. A nil verifier disables verification.
+func WithUpstreamVerifier(verifier UpstreamVerifier) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.upstreamVerifier = verifier
+		return nil
+	})
+}
+
+// WithPublishHook adds a hook to run after a package revision is published.
+func WithPublishHook(hook PublishHook) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.publishHooks = append(engine.publishHooks, hook)
+		return nil
+	})
+}
+
+// WithProposeHook adds a hook to run after a package revision is proposed.
+func WithProposeHook(hook ProposeHook) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.proposeHooks = append(engine.proposeHooks, hook)
+		return nil
+	})
+}
+
+// WithProposalDiffBytes bounds the size of the diffs passed to propose hooks. See
+// ProposalDiff.
+func WithProposalDiffBytes(maxBytes int) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if maxBytes < 0 {
+			return fmt.Errorf("proposal diff size must not be negative")
+		}
+		engine.proposalDiffBytes = maxBytes
+		return nil
+	})
+}
+
+// WithFunctionLogTTL sets how long the logs of the functions run by the last create or
+// update of a package revision are kept for retrieval; zero disables keeping them.
+func WithFunctionLogTTL(ttl time.Duration) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if ttl < 0 {
+			return fmt.Errorf("function log TTL must not be negative")
+		}
+		engine.functionLogs = newFunctionLogStore(ttl)
+		return nil
+	})
+}
+
+// WithDraftBufferBytes sets the package size up to which the contents of the tasks
+// applied to a draft are kept in memory and only written to the repository when the
+// draft is closed; zero writes the contents of each task right away.
+func WithDraftBufferBytes(maxBytes int64) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if maxBytes < 0 {
+			return fmt.Errorf("draft buffer size must not be negative")
+		}
+		engine.draftBufferBytes = maxBytes
+		return nil
+	})
+}
+
+// WithPhaseTimeouts limits the duration of the fetch, render and close phases of
+// package revision operations. A phase exceeding its timeout fails with a
+// PhaseTimeoutError.
+func WithPhaseTimeouts(timeouts PhaseTimeouts) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if timeouts.Fetch < 0 || timeouts.Render < 0 || timeouts.Close < 0 {
+			return fmt.Errorf("phase timeouts must not be negative")
+		}
+		engine.phaseTimeouts = timeouts
+		return nil
+	})
+}
+
+// WithAutoProposeDelay proposes drafts with the api.AutoProposeAnnotation annotation
+// once all their readiness gates have had true conditions for the delay. Zero disables
+// automatic proposals.
+func WithAutoProposeDelay(delay time.Duration) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if delay < 0 {
+			return fmt.Errorf("auto-propose delay must not be negative")
+		}
+		engine.autoProposer = nil
+		if delay > 0 {
+			engine.autoProposer = newAutoProposer(delay, engine.autoPropose)
+		}
+		return nil
+	})
+}
+
+// WithStagingStore enables resumable uploads of package resources, staging their
+// chunks in the store until they are finalized.
+func WithStagingStore(store *staging.Store) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.stagingStore = store
+		return nil
+	})
+}
+
+// WithRenderToolchain sets the version of the render toolchain, recorded on new package
+// revisions with the api.RenderToolchainAnnotation annotation.
+func WithRenderToolchain(version string) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		engine.renderToolchain = version
+		return nil
+	})
+}
+
+// WithToolchainRuntime adds the function runtime rendering package revisions pinned to
+// an earlier version of the render toolchain.
+func WithToolchainRuntime(version string, runtime fn.FunctionRuntime) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if version == "" {
+			return fmt.Errorf("render toolchain version must not be empty")
+		}
+		if engine.toolchainRuntimes == nil {
+			engine.toolchainRuntimes = map[string]fn.FunctionRuntime{}
+		}
+		engine.toolchainRuntimes[version] = runtime
+		return nil
+	})
+}
+
+// WithToolchainFunctionRunner renders package revisions pinned to an earlier version of
+// the render toolchain with the builtin functions and the function runner gRPC service
+// at the address.
+func WithToolchainFunctionRunner(version, address string) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		runtime, err := newGRPCFunctionRuntime(address)
+		if err != nil {
+			return fmt.Errorf("failed to create function runtime of render toolchain %q: %w", version, err)
+		}
+		return WithToolchainRuntime(version, fn.NewMultiRuntime([]fn.FunctionRuntime{newBuiltinRuntime(), runtime})).apply(engine)
+	})
+}
+
+// WithTraceReadSampleRate sets the rate, between 0 and 1, at which DefaultTraceSampler
+// samples the traces of reads. Traces of mutating operations are always sampled.
+func WithTraceReadSampleRate(rate float64) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("trace read sample rate must be between 0 and 1")
+		}
+		DefaultTraceSampler.SetReadSampleRate(rate)
+		return nil
+	})
+}
+
+// WithRepositorySyncWait makes reads of a repository whose initial sync hasn't completed
+// wait for the sync up to the timeout, rather than fail right away with a
+// RepositorySyncPendingError.
+func WithRepositorySyncWait(timeout time.Duration) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		if timeout < 0 {
+			return fmt.Errorf("repository sync wait must not be negative")
+		}
+		engine.syncWaitTimeout = timeout
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// ListOrphanedMetadata returns the names of the metadata entries of the repository which
+// have no package revision in the repository. The package revision contents and their
+// metadata are written separately, so a failure in between, for example while deleting
+// a package revision, can leave the metadata behind.
+func (cad *cadEngine) ListOrphanedMetadata(ctx context.Context, repositoryObj *configapi.Repository) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ListOrphanedMetadata", trace.WithAttributes())
+	defer span.End()
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.listOrphanedMetadata(ctx, repo, repositoryObj)
+}
+
+// PruneOrphanedMetadata deletes the metadata entries of the repository which have no
+// package revision in the repository, and returns their names.
+func (cad *cadEngine) PruneOrphanedMetadata(ctx context.Context, repositoryObj *configapi.Repository) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::PruneOrphanedMetadata", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.pruneOrphanedMetadata(ctx, repo, repositoryObj)
+}
+
+func (cad *cadEngine) listOrphanedMetadata(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository) ([]string, error) {
+	pkgRevs, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	existing := map[types.NamespacedName]bool{}
+	for _, pr := range pkgRevs {
+		existing[types.NamespacedName{Name: pr.KubeObjectName(), Namespace: pr.KubeObjectNamespace()}] = true
+	}
+
+	metas, err := cad.metadataStore.List(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	var orphaned []string
+	for _, m := range metas {
+		if !existing[types.NamespacedName{Name: m.Name, Namespace: m.Namespace}] {
+			orphaned = append(orphaned, m.Name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
+
+func (cad *cadEngine) pruneOrphanedMetadata(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository) ([]string, error) {
+	orphaned, err := cad.listOrphanedMetadata(ctx, repo, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	var pruned []string
+	for _, name := range orphaned {
+		namespacedName := types.NamespacedName{Name: name, Namespace: repositoryObj.Namespace}
+		if _, err := cad.metadataStore.Delete(ctx, namespacedName); err != nil {
+			// Deleted concurrently, for example by a retried package revision deletion.
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return pruned, fmt.Errorf("cannot delete metadata of package revision %q: %w", name, err)
+		}
+		klog.FromContext(ctx).Info("Deleted orphaned metadata of package revision", "name", name)
+		pruned = append(pruned, name)
+	}
+	return pruned, nil
+}
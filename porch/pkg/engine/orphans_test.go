@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOrphanedMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo := &fake.Repository{PackageRevisions: []repository.PackageRevision{
+		&fake.PackageRevision{Name: "blueprints-1111", Namespace: "default"},
+		&fake.PackageRevision{Name: "blueprints-2222", Namespace: "default"},
+	}}
+	store := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{
+		{Name: "blueprints-1111", Namespace: "default"},
+		{Name: "blueprints-2222", Namespace: "default"},
+		// Left behind by a deletion which failed after deleting the package revision.
+		{Name: "blueprints-3333", Namespace: "default"},
+	}}
+	cad := &cadEngine{metadataStore: store}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	orphaned, err := cad.listOrphanedMetadata(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("listOrphanedMetadata failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"blueprints-3333"}, orphaned); diff != "" {
+		t.Errorf("unexpected orphaned metadata (-want, +got): %s", diff)
+	}
+	if len(store.Metas) != 3 {
+		t.Errorf("listing orphaned metadata deleted metadata: %v", store.Metas)
+	}
+
+	pruned, err := cad.pruneOrphanedMetadata(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("pruneOrphanedMetadata failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"blueprints-3333"}, pruned); diff != "" {
+		t.Errorf("unexpected pruned metadata (-want, +got): %s", diff)
+	}
+	var remaining []string
+	for _, m := range store.Metas {
+		remaining = append(remaining, m.Name)
+	}
+	if diff := cmp.Diff([]string{"blueprints-1111", "blueprints-2222"}, remaining); diff != "" {
+		t.Errorf("unexpected remaining metadata (-want, +got): %s", diff)
+	}
+
+	orphaned, err = cad.listOrphanedMetadata(ctx, repo, repositoryObj)
+	if err != nil {
+		t.Fatalf("listOrphanedMetadata failed: %v", err)
+	}
+	if len(orphaned) != 0 {
+		t.Errorf("orphaned metadata left after pruning: %v", orphaned)
+	}
+}
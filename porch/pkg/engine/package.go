@@ -21,24 +21,43 @@ import (
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"golang.org/x/mod/semver"
 )
 
+// PackageRevisionNotFoundError is returned when a referenced package revision doesn't
+// exist, for example because it was deleted.
+type PackageRevisionNotFoundError struct {
+	Ref api.PackageRevisionRef
+}
+
+func (e *PackageRevisionNotFoundError) Error() string {
+	if e.Ref.Name != "" {
+		return fmt.Sprintf("cannot find package revision %q", e.Ref.Name)
+	}
+	return fmt.Sprintf("cannot find revision %q of package %q in repository %q", e.Ref.Revision, e.Ref.Package, e.Ref.Repository)
+}
+
 type PackageFetcher struct {
 	repoOpener        RepositoryOpener
 	referenceResolver ReferenceResolver
+
+	// sizeBudget, if set, limits the size of the fetched package resources.
+	sizeBudget *PackageSizeBudget
+
+	// verifier, if set, verifies the fetched package resources.
+	verifier UpstreamVerifier
 }
 
 func (p *PackageFetcher) FetchRevision(ctx context.Context, packageRef *api.PackageRevisionRef, namespace string) (repository.PackageRevision, error) {
+	if packageRef.Name == "" {
+		return p.fetchPackageRevision(ctx, packageRef, namespace)
+	}
+
 	repositoryName, err := parseUpstreamRepository(packageRef.Name)
 	if err != nil {
 		return nil, err
 	}
-	var resolved configapi.Repository
-	if err := p.referenceResolver.ResolveReference(ctx, namespace, repositoryName, &resolved); err != nil {
-		return nil, fmt.Errorf("cannot find repository %s/%s: %w", namespace, repositoryName, err)
-	}
-
-	repo, err := p.repoOpener.OpenRepository(ctx, &resolved)
+	repo, err := p.openRepository(ctx, namespace, packageRef.Namespace, repositoryName)
 	if err != nil {
 		return nil, err
 	}
@@ -56,21 +75,124 @@ func (p *PackageFetcher) FetchRevision(ctx context.Context, packageRef *api.Pack
 		}
 	}
 	if revision == nil {
-		return nil, fmt.Errorf("cannot find package revision %q", packageRef.Name)
+		return nil, &PackageRevisionNotFoundError{Ref: *packageRef}
+	}
+	if err := checkSharedRevision(packageRef, namespace, revision); err != nil {
+		return nil, err
 	}
 
 	return revision, nil
 }
 
+// fetchPackageRevision finds the revision of a package referenced by repository and package name.
+// An empty or "latest" revision refers to the latest published revision of the package.
+func (p *PackageFetcher) fetchPackageRevision(ctx context.Context, packageRef *api.PackageRevisionRef, namespace string) (repository.PackageRevision, error) {
+	if packageRef.Repository == "" || packageRef.Package == "" {
+		return nil, fmt.Errorf("package revision reference must specify either name, or repository and package")
+	}
+	repo, err := p.openRepository(ctx, namespace, packageRef.Namespace, packageRef.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ListPackageRevisionFilter{Package: packageRef.Package}
+	latest := packageRef.Revision == "" || packageRef.Revision == api.LatestPublishedRevision
+	if !latest {
+		filter.Revision = packageRef.Revision
+	}
+	revisions, err := repo.ListPackageRevisions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision repository.PackageRevision
+	for _, rev := range revisions {
+		if !filter.Matches(rev) {
+			continue
+		}
+		if !latest {
+			revision = rev
+			break
+		}
+		// Same criteria as the cache uses to label the latest revision.
+		if rev.Lifecycle() != api.PackageRevisionLifecyclePublished || !semver.IsValid(rev.Key().Revision) {
+			continue
+		}
+		if revision == nil || semver.Compare(rev.Key().Revision, revision.Key().Revision) > 0 {
+			revision = rev
+		}
+	}
+	if revision == nil {
+		if latest {
+			return nil, fmt.Errorf("package %q in repository %q has no published revision", packageRef.Package, packageRef.Repository)
+		}
+		return nil, &PackageRevisionNotFoundError{Ref: *packageRef}
+	}
+	if err := checkSharedRevision(packageRef, namespace, revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// openRepository opens the repository repositoryName of repositoryNamespace for a package
+// of the namespace. An empty repositoryNamespace is the namespace of the package; a
+// repository of another namespace must be shared with it.
+func (p *PackageFetcher) openRepository(ctx context.Context, namespace, repositoryNamespace, repositoryName string) (repository.Repository, error) {
+	if repositoryNamespace == "" {
+		repositoryNamespace = namespace
+	}
+	var resolved configapi.Repository
+	if err := p.referenceResolver.ResolveReference(ctx, repositoryNamespace, repositoryName, &resolved); err != nil {
+		return nil, fmt.Errorf("cannot find repository %s/%s: %w", repositoryNamespace, repositoryName, err)
+	}
+	if repositoryNamespace != namespace {
+		if err := checkRepositoryShared(ctx, p.referenceResolver, &resolved, namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.repoOpener.OpenRepository(ctx, &resolved)
+}
+
+// checkSharedRevision checks that a package revision of a repository of another namespace,
+// which is shared read-only, is published.
+func checkSharedRevision(packageRef *api.PackageRevisionRef, namespace string, revision repository.PackageRevision) error {
+	if packageRef.Namespace == "" || packageRef.Namespace == namespace {
+		return nil
+	}
+	if lifecycle := revision.Lifecycle(); lifecycle != api.PackageRevisionLifecyclePublished {
+		return &RepositoryNotSharedError{
+			Repository: packageRef.Namespace + "/" + revision.Key().Repository,
+			Namespace:  namespace,
+			Reason:     fmt.Sprintf("package revision %q is %s; only published package revisions are shared", revision.KubeObjectName(), lifecycle),
+		}
+	}
+	return nil
+}
+
 func (p *PackageFetcher) FetchResources(ctx context.Context, packageRef *api.PackageRevisionRef, namespace string) (*api.PackageRevisionResources, error) {
 	revision, err := p.FetchRevision(ctx, packageRef, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	resources, err := revision.GetResources(ctx)
+	return p.GetResources(ctx, revision)
+}
+
+// GetResources reads the resources of a fetched package revision, enforcing the size budget
+// and verifying the resources if a verifier is set.
+func (p *PackageFetcher) GetResources(ctx context.Context, revision repository.PackageRevision) (*api.PackageRevisionResources, error) {
+	resources, err := revision.GetResources(p.sizeBudget.limitReads(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("cannot read contents of package %q: %w", packageRef.Name, err)
+		return nil, p.sizeBudget.exceeded(revision.KubeObjectName(), fmt.Errorf("cannot read contents of package %q: %w", revision.KubeObjectName(), err))
+	}
+	if err := p.sizeBudget.check(revision.KubeObjectName(), resources.Spec.Resources); err != nil {
+		return nil, err
+	}
+	if p.verifier != nil {
+		if err := p.verifier.VerifyUpstream(ctx, revision.KubeObjectName(), resources.Spec.Resources); err != nil {
+			return nil, err
+		}
 	}
 	return resources, nil
 }
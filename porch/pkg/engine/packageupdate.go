@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// PackageUpdateNotSupportedError is returned when a package is updated in a repository
+// which can't update packages.
+//
+// PackageUpdateNotSupportedError is an API status error: it is returned to clients as a
+// MethodNotAllowed status.
+type PackageUpdateNotSupportedError struct {
+	Repository string
+	Package    string
+}
+
+var _ apierrors.APIStatus = &PackageUpdateNotSupportedError{}
+
+func (e *PackageUpdateNotSupportedError) Error() string {
+	return fmt.Sprintf("package %q can't be updated: repository %q doesn't support updating packages", e.Package, e.Repository)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PackageUpdateNotSupportedError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusMethodNotAllowed,
+		Reason:  metav1.StatusReasonMethodNotAllowed,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packages",
+			Name:  e.Package,
+		},
+	}
+}
+
+// updatePackage updates the package. Archiving and the labels, annotations and
+// description of the package are recorded in the metadata of its package revisions, so
+// they can be changed in any repository, and packages are frozen through the freeze
+// subresource, so freezing can be authorized separately; the other changes to the spec of
+// the package are made by the repository.
+func (cad *cadEngine) updatePackage(ctx context.Context, repo repository.Repository, repositoryObj *configapi.Repository, oldPackage *Package, oldObj, newObj *api.Package) (*Package, error) {
+	if !equality.Semantic.DeepEqual(oldObj.Spec.Freeze, newObj.Spec.Freeze) {
+		return nil, apierrors.NewBadRequest("packages must be frozen and unfrozen through the freeze subresource")
+	}
+	var allErrs field.ErrorList
+	if newObj.Spec.PackageName != oldObj.Spec.PackageName {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "packageName"), newObj.Spec.PackageName, "field is immutable"))
+	}
+	if newObj.Spec.RepositoryName != oldObj.Spec.RepositoryName {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "repository"), newObj.Spec.RepositoryName, "field is immutable"))
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("Package").GroupKind(), oldObj.Name, allErrs)
+	}
+
+	repoPackage := oldPackage.repoPackage
+	if packageChanged(oldObj, newObj) {
+		updated, err := repo.UpdatePackage(ctx, oldPackage.repoPackage, newObj)
+		if errors.Is(err, repository.ErrPackageUpdateNotSupported) {
+			return nil, &PackageUpdateNotSupportedError{Repository: repositoryObj.Name, Package: repoPackage.Key().Package}
+		}
+		if err != nil {
+			return nil, err
+		}
+		repoPackage = updated
+	}
+
+	metadata := oldPackage.metadata
+	if packageMetadataChanged(oldObj, newObj) {
+		metadata = &meta.PackageMetadata{
+			Labels:      newObj.Labels,
+			Annotations: newObj.Annotations,
+			Description: newObj.Spec.Description,
+			UpdatedAt:   metav1.Now().Rfc3339Copy(),
+		}
+		if err := cad.setPackageMetadata(ctx, repositoryObj, repo, repoPackage.Key().Package, metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	if newObj.Spec.Archived != oldPackage.IsArchived() {
+		if err := cad.setPackageArchived(ctx, repositoryObj, repoPackage.Key().Package, newObj.Spec.Archived); err != nil {
+			return nil, err
+		}
+	}
+	return &Package{
+		repoPackage: repoPackage,
+		archived:    newObj.Spec.Archived,
+		freeze:      oldPackage.freeze,
+		metadata:    metadata,
+	}, nil
+}
+
+// packageChanged returns true if the update changes the package in its repository.
+func packageChanged(oldObj, newObj *api.Package) bool {
+	oldSpec, newSpec := oldObj.Spec, newObj.Spec
+	oldSpec.Archived, newSpec.Archived = false, false
+	oldSpec.Freeze, newSpec.Freeze = nil, nil
+	oldSpec.Description, newSpec.Description = "", ""
+	return !equality.Semantic.DeepEqual(oldSpec, newSpec)
+}
+
+// packageMetadataChanged returns true if the update changes the labels, annotations or
+// description of the package.
+func packageMetadataChanged(oldObj, newObj *api.Package) bool {
+	return oldObj.Spec.Description != newObj.Spec.Description ||
+		!equality.Semantic.DeepEqual(oldObj.Labels, newObj.Labels) ||
+		!equality.Semantic.DeepEqual(oldObj.Annotations, newObj.Annotations)
+}
+
+// packageMetadatas returns the metadata of the packages among the package revisions
+// whose metadata was set through the Package, by package name.
+func packageMetadatas(revisions []*PackageRevision) map[string]*meta.PackageMetadata {
+	metadatas := map[string]*meta.PackageMetadata{}
+	for _, rev := range revisions {
+		metadata := rev.packageRevisionMeta.PackageMetadata
+		if metadata == nil {
+			continue
+		}
+		name := rev.repoPackageRevision.Key().Package
+		// The most recent update wins, in case updating the metadata of the package
+		// revisions was interrupted, and for package revisions created since.
+		if m := metadatas[name]; m == nil || m.UpdatedAt.Before(&metadata.UpdatedAt) {
+			metadatas[name] = metadata
+		}
+	}
+	return metadatas
+}
+
+// setPackageMetadata records the metadata of the package in the metadata of all of its
+// package revisions.
+func (cad *cadEngine) setPackageMetadata(ctx context.Context, repositoryObj *configapi.Repository, repo repository.Repository, packageName string, metadata *meta.PackageMetadata) error {
+	revisions, _, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		pkgRevMeta := rev.packageRevisionMeta
+		pkgRevMeta.PackageMetadata = metadata
+		// Leave the status of the PackageRev alone.
+		pkgRevMeta.Artifacts = nil
+		pkgRevMeta.DeploymentStatus = nil
+		pkgRevMeta.LifecycleTimes = meta.LifecycleTimes{}
+		if _, err := cad.metadataStore.Update(ctx, pkgRevMeta); err != nil {
+			return fmt.Errorf("cannot update metadata of package revision %q: %w", rev.KubeObjectName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// updatingRepository records the package updates.
+type updatingRepository struct {
+	fake.Repository
+	unsupported bool
+
+	updates []*api.Package
+}
+
+func (r *updatingRepository) UpdatePackage(_ context.Context, old repository.Package, new *api.Package) (repository.Package, error) {
+	if r.unsupported {
+		return nil, fmt.Errorf("%w for test repos", repository.ErrPackageUpdateNotSupported)
+	}
+	r.updates = append(r.updates, new)
+	return &fake.Package{Name: old.KubeObjectName(), PackageKey: old.Key(), Package: new}, nil
+}
+
+func TestUpdatePackage(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	oldObj := &api.Package{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app", Namespace: "default", Labels: map[string]string{"team": "platform"}},
+		Spec:       api.PackageSpec{PackageName: "app", RepositoryName: "blueprints"},
+	}
+	oldPackage := &Package{repoPackage: &fake.Package{
+		Name:       "blueprints-app",
+		PackageKey: repository.PackageKey{Repository: "blueprints", Package: "app"},
+		Package:    oldObj,
+	}}
+	// newRepository returns a repository with two revisions of the package, which can't
+	// update packages itself, as Git and OCI repositories can't.
+	newRepository := func() (*updatingRepository, *metafake.MemoryMetadataStore) {
+		repo := &updatingRepository{unsupported: true}
+		repo.Packages = []repository.Package{oldPackage.repoPackage}
+		store := &metafake.MemoryMetadataStore{}
+		for _, rev := range []string{"v1", "v2"} {
+			name := "blueprints-app-" + rev
+			repo.PackageRevisions = append(repo.PackageRevisions, &fake.PackageRevision{
+				Name:               name,
+				Namespace:          "default",
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: rev},
+				PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+			})
+			store.Metas = append(store.Metas, meta.PackageRevisionMeta{Name: name, Namespace: "default"})
+		}
+		return repo, store
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		repo, store := newRepository()
+		cad := &cadEngine{metadataStore: store}
+		newObj := oldObj.DeepCopy()
+		newObj.Labels["tier"] = "frontend"
+		newObj.Annotations = map[string]string{"owner": "alice@example.com"}
+		newObj.Spec.Description = "The app blueprint"
+
+		updated, err := cad.updatePackage(ctx, repo, repositoryObj, oldPackage, oldObj, newObj)
+		if err != nil {
+			t.Fatalf("updatePackage failed: %v", err)
+		}
+		if len(repo.updates) != 0 {
+			t.Errorf("the metadata of the package was updated in the repository")
+		}
+		if diff := cmp.Diff(newObj, updated.GetPackage()); diff != "" {
+			t.Errorf("unexpected updated package (-want, +got): %s", diff)
+		}
+		for _, pkgRevMeta := range store.Metas {
+			if pkgRevMeta.PackageMetadata == nil {
+				t.Errorf("the package metadata wasn't recorded in the metadata of %s", pkgRevMeta.Name)
+			}
+		}
+
+		// The metadata is read back when the package is listed.
+		packages, err := cad.listPackages(ctx, repositoryObj, repo, repository.ListPackageFilter{})
+		if err != nil {
+			t.Fatalf("listPackages failed: %v", err)
+		}
+		if len(packages) != 1 {
+			t.Fatalf("listed %d packages; want 1", len(packages))
+		}
+		if diff := cmp.Diff(newObj, packages[0].GetPackage()); diff != "" {
+			t.Errorf("unexpected listed package (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		repo, store := newRepository()
+		cad := &cadEngine{metadataStore: store}
+		updated, err := cad.updatePackage(ctx, repo, repositoryObj, oldPackage, oldObj, oldObj.DeepCopy())
+		if err != nil {
+			t.Fatalf("updatePackage failed: %v", err)
+		}
+		if len(repo.updates) != 0 {
+			t.Errorf("unchanged package was updated in the repository")
+		}
+		if updated.repoPackage != oldPackage.repoPackage {
+			t.Errorf("unchanged package was replaced")
+		}
+		for _, pkgRevMeta := range store.Metas {
+			if pkgRevMeta.PackageMetadata != nil {
+				t.Errorf("the metadata of %s was updated for an unchanged package", pkgRevMeta.Name)
+			}
+		}
+	})
+
+	t.Run("renamed", func(t *testing.T) {
+		repo, store := newRepository()
+		cad := &cadEngine{metadataStore: store}
+		newObj := oldObj.DeepCopy()
+		newObj.Spec.PackageName = "other"
+
+		_, err := cad.updatePackage(ctx, repo, repositoryObj, oldPackage, oldObj, newObj)
+		if !apierrors.IsInvalid(err) {
+			t.Errorf("updatePackage returned %v; want an Invalid error", err)
+		}
+		if len(repo.updates) != 0 {
+			t.Errorf("renamed package was updated in the repository")
+		}
+	})
+}
+
+func TestPackageMetadatas(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	revision := func(pkg, rev string, metadata *meta.PackageMetadata) *PackageRevision {
+		return &PackageRevision{
+			repoPackageRevision: &fake.PackageRevision{
+				PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: pkg, Revision: rev},
+			},
+			packageRevisionMeta: meta.PackageRevisionMeta{PackageMetadata: metadata},
+		}
+	}
+
+	revisions := []*PackageRevision{
+		// The most recent update of a package wins.
+		revision("app", "v1", &meta.PackageMetadata{Description: "new", UpdatedAt: later}),
+		revision("app", "v2", &meta.PackageMetadata{Description: "old", UpdatedAt: earlier}),
+		revision("app", "v3", nil),
+		revision("db", "v1", nil),
+	}
+	want := map[string]*meta.PackageMetadata{
+		"app": {Description: "new", UpdatedAt: later},
+	}
+	if diff := cmp.Diff(want, packageMetadatas(revisions)); diff != "" {
+		t.Errorf("unexpected package metadata (-want, +got): %s", diff)
+	}
+}
+
+func TestPackageUpdateNotSupportedError(t *testing.T) {
+	err := &PackageUpdateNotSupportedError{Repository: "blueprints", Package: "app"}
+	if !apierrors.IsMethodNotSupported(err) {
+		t.Errorf("unsupported update isn't reported as MethodNotAllowed")
+	}
+}
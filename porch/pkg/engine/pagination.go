@@ -0,0 +1,185 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// continueToken is the position of the next page of a list of package revisions. The
+// package revisions are listed by name, so that the pages neither repeat nor skip
+// package revisions when others are added or deleted between pages.
+type continueToken struct {
+	// After is the name of the last package revision of the previous page.
+	After string `json:"after"`
+}
+
+func encodeContinueToken(after string) string {
+	data, _ := json.Marshal(continueToken{After: after})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeContinueToken returns the name of the package revision the page continues
+// after, or a BadRequest error if the token is invalid.
+func decodeContinueToken(token string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid continue token %q", token))
+	}
+	var t continueToken
+	if err := json.Unmarshal(data, &t); err != nil || t.After == "" {
+		return "", apierrors.NewBadRequest(fmt.Sprintf("invalid continue token %q", token))
+	}
+	return t.After, nil
+}
+
+// metadataGetThreshold is the most package revisions of a page whose metadata is read
+// one by one. The metadata of larger pages is listed for the whole repository at once.
+const metadataGetThreshold = 10
+
+// pageMetadata reads the metadata of the package revisions of a page. The metadata of
+// small pages, such as the lookup of a single package revision, is read per package
+// revision; once more than metadataGetThreshold reads would be needed, the metadata of
+// the repository is listed instead.
+type pageMetadata struct {
+	cad           *cadEngine
+	repositoryObj *configapi.Repository
+	gets          int
+	listed        map[types.NamespacedName]meta.PackageRevisionMeta
+}
+
+// get returns the metadata of the package revision, and whether it was found.
+func (p *pageMetadata) get(ctx context.Context, name types.NamespacedName) (meta.PackageRevisionMeta, bool, error) {
+	if p.listed == nil && p.gets < metadataGetThreshold {
+		p.gets++
+		pkgRevMeta, err := p.cad.metadataStore.Get(ctx, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return meta.PackageRevisionMeta{}, false, nil
+			}
+			return meta.PackageRevisionMeta{}, false, err
+		}
+		return pkgRevMeta, true, nil
+	}
+	if p.listed == nil {
+		if err := p.list(ctx); err != nil {
+			return meta.PackageRevisionMeta{}, false, err
+		}
+	}
+	pkgRevMeta, found := p.listed[name]
+	return pkgRevMeta, found, nil
+}
+
+// list lists the metadata of the repository.
+func (p *pageMetadata) list(ctx context.Context) error {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("metadata fetch started", trace.WithAttributes(attribute.Int("gets", p.gets)))
+	metas, err := p.cad.metadataStore.List(ctx, p.repositoryObj)
+	if err != nil {
+		return err
+	}
+	span.AddEvent("metadata fetch finished", trace.WithAttributes(attribute.Int("metas", len(metas))))
+	p.listed = make(map[types.NamespacedName]meta.PackageRevisionMeta, len(metas))
+	for _, pkgRevMeta := range metas {
+		p.listed[types.NamespacedName{Namespace: pkgRevMeta.Namespace, Name: pkgRevMeta.Name}] = pkgRevMeta
+	}
+	return nil
+}
+
+// listPackageRevisions lists the page of the package revisions of repo selected by the
+// filter, and returns the token of the next page, if any. The metadata of small pages is
+// read per package revision, and that of larger pages with a single list of the metadata
+// of the repository; package revisions without metadata are treated as not existing.
+func (cad *cadEngine) listPackageRevisions(ctx context.Context, repositoryObj *configapi.Repository, repo repository.Repository, filter repository.ListPackageRevisionFilter) ([]*PackageRevision, string, error) {
+	if filter.Limit < 0 {
+		return nil, "", apierrors.NewBadRequest(fmt.Sprintf("invalid limit %d; must not be negative", filter.Limit))
+	}
+	var after string
+	if filter.Continue != "" {
+		var err error
+		if after, err = decodeContinueToken(filter.Continue); err != nil {
+			return nil, "", err
+		}
+	}
+
+	listed, err := repo.ListPackageRevisions(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	// The slice may be shared with the repository, so a copy is sorted.
+	pkgRevs := append([]repository.PackageRevision(nil), listed...)
+	sort.Slice(pkgRevs, func(i, j int) bool {
+		return pkgRevs[i].KubeObjectName() < pkgRevs[j].KubeObjectName()
+	})
+	start := 0
+	if after != "" {
+		start = sort.Search(len(pkgRevs), func(i int) bool {
+			return pkgRevs[i].KubeObjectName() > after
+		})
+	}
+	pkgRevs = pkgRevs[start:]
+	if len(pkgRevs) == 0 {
+		return nil, "", nil
+	}
+
+	metas := &pageMetadata{cad: cad, repositoryObj: repositoryObj}
+	pageSize := len(pkgRevs)
+	if filter.Limit > 0 && filter.Limit < pageSize {
+		pageSize = filter.Limit
+	}
+	if pageSize > metadataGetThreshold {
+		if err := metas.list(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var packageRevisions []*PackageRevision
+	for i, pr := range pkgRevs {
+		if filter.Limit > 0 && len(packageRevisions) == filter.Limit {
+			// There are more package revisions; the next page starts after the last one
+			// of this page.
+			return packageRevisions, encodeContinueToken(pkgRevs[i-1].KubeObjectName()), nil
+		}
+		pkgRevMeta, found, err := metas.get(ctx, types.NamespacedName{
+			Name:      pr.KubeObjectName(),
+			Namespace: pr.KubeObjectNamespace(),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if !found {
+			// If a PackageRev CR doesn't exist, we treat the
+			// Packagerevision as not existing.
+			continue
+		}
+		packageRevisions = append(packageRevisions, &PackageRevision{
+			repoPackageRevision: pr,
+			packageRevisionMeta: pkgRevMeta,
+		})
+	}
+	return packageRevisions, "", nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// countingMetadataStore counts the metadata reads.
+type countingMetadataStore struct {
+	metafake.MemoryMetadataStore
+	gets  int
+	lists int
+}
+
+func (s *countingMetadataStore) Get(ctx context.Context, namespacedName types.NamespacedName) (meta.PackageRevisionMeta, error) {
+	s.gets++
+	return s.MemoryMetadataStore.Get(ctx, namespacedName)
+}
+
+func (s *countingMetadataStore) List(ctx context.Context, repo *configapi.Repository) ([]meta.PackageRevisionMeta, error) {
+	s.lists++
+	return s.MemoryMetadataStore.List(ctx, repo)
+}
+
+func TestListPackageRevisionsPages(t *testing.T) {
+	ctx := context.Background()
+	store := &countingMetadataStore{}
+	repo := &fake.Repository{}
+	add := func(name string, withMeta bool) {
+		repo.PackageRevisions = append(repo.PackageRevisions, &fake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: name, Revision: "v1"},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+		})
+		if withMeta {
+			store.Metas = append(store.Metas, meta.PackageRevisionMeta{Name: name, Namespace: "default"})
+		}
+	}
+	// The repository lists the package revisions in no particular order.
+	for _, name := range []string{"blueprints-e", "blueprints-b", "blueprints-d", "blueprints-a", "blueprints-c"} {
+		add(name, true)
+	}
+	cad := &cadEngine{metadataStore: store}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "blueprints"}}
+
+	list := func(t *testing.T, limit int, token string) ([]string, string) {
+		t.Helper()
+		revisions, next, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{Limit: limit, Continue: token})
+		if err != nil {
+			t.Fatalf("listPackageRevisions failed: %v", err)
+		}
+		var names []string
+		for _, r := range revisions {
+			names = append(names, r.KubeObjectName())
+		}
+		return names, next
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		store.gets, store.lists = 0, 0
+		var pages [][]string
+		token := ""
+		for {
+			names, next := list(t, 2, token)
+			pages = append(pages, names)
+			if next == "" {
+				break
+			}
+			token = next
+			if len(pages) == 1 {
+				// Package revisions added between pages are listed if they come after
+				// the previous page.
+				add("blueprints-0", true)
+				add("blueprints-f", true)
+			}
+		}
+		want := [][]string{{"blueprints-a", "blueprints-b"}, {"blueprints-c", "blueprints-d"}, {"blueprints-e", "blueprints-f"}}
+		if diff := cmp.Diff(want, pages); diff != "" {
+			t.Errorf("unexpected pages (-want, +got): %s", diff)
+		}
+		// The metadata of small pages is read per package revision.
+		if store.gets != 6 || store.lists != 0 {
+			t.Errorf("read the metadata with %d gets and %d lists; want 6 gets, one per package revision", store.gets, store.lists)
+		}
+	})
+
+	t.Run("page boundaries", func(t *testing.T) {
+		all, next := list(t, 0, "")
+		if next != "" || len(all) != 7 {
+			t.Fatalf("listing without a limit returned %v and token %q; want all package revisions and no token", all, next)
+		}
+		// A page holding the rest of the package revisions is the last one.
+		names, next := list(t, 7, "")
+		if next != "" || len(names) != 7 {
+			t.Errorf("listing with a limit of all package revisions returned %v and token %q; want no token", names, next)
+		}
+		names, next = list(t, 6, "")
+		if next == "" || len(names) != 6 {
+			t.Fatalf("listing with a limit of 6 returned %v and token %q; want a token", names, next)
+		}
+		if names, next = list(t, 6, next); next != "" || fmt.Sprint(names) != "[blueprints-f]" {
+			t.Errorf("last page is %v with token %q; want [blueprints-f] and no token", names, next)
+		}
+	})
+
+	t.Run("package revisions without metadata", func(t *testing.T) {
+		add("blueprints-aa", false)
+		names, next := list(t, 2, encodeContinueToken("blueprints-a"))
+		if diff := cmp.Diff([]string{"blueprints-b", "blueprints-c"}, names); diff != "" {
+			t.Errorf("unexpected page (-want, +got): %s", diff)
+		}
+		if next == "" {
+			t.Errorf("no token returned for the next page")
+		}
+	})
+
+	t.Run("large pages", func(t *testing.T) {
+		for i := 0; i < metadataGetThreshold; i++ {
+			add(fmt.Sprintf("blueprints-g%d", i), true)
+		}
+		store.gets, store.lists = 0, 0
+		if names, _ := list(t, 0, ""); len(names) != 17 {
+			t.Fatalf("listed %d package revisions; want 17", len(names))
+		}
+		// The metadata of a large page is listed once, rather than read per package revision.
+		if store.gets != 0 || store.lists != 1 {
+			t.Errorf("read the metadata with %d gets and %d lists; want a single list", store.gets, store.lists)
+		}
+	})
+
+	t.Run("small page of package revisions without metadata", func(t *testing.T) {
+		for i := 0; i < metadataGetThreshold; i++ {
+			add(fmt.Sprintf("blueprints-0%d", i), false)
+		}
+		store.gets, store.lists = 0, 0
+		names, _ := list(t, 2, "")
+		if diff := cmp.Diff([]string{"blueprints-0", "blueprints-a"}, names); diff != "" {
+			t.Errorf("unexpected page (-want, +got): %s", diff)
+		}
+		// Once the reads per package revision exceed the threshold, the metadata is listed.
+		if store.gets != metadataGetThreshold || store.lists != 1 {
+			t.Errorf("read the metadata with %d gets and %d lists; want %d gets and a single list", store.gets, store.lists, metadataGetThreshold)
+		}
+	})
+
+	t.Run("invalid continue token", func(t *testing.T) {
+		for _, token := range []string{"not a token", encodeContinueToken("")} {
+			_, _, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{Limit: 2, Continue: token})
+			if !apierrors.IsBadRequest(err) {
+				t.Errorf("listing with continue token %q returned %v; want a BadRequest error", token, err)
+			}
+		}
+	})
+}
+
+func TestListPackageRevisionsByName(t *testing.T) {
+	ctx := context.Background()
+	store := &countingMetadataStore{}
+	for i := 0; i < 100; i++ {
+		store.Metas = append(store.Metas, meta.PackageRevisionMeta{Name: fmt.Sprintf("blueprints-pkg-%02d", i), Namespace: "default"})
+	}
+	// The repository lists only the package revision with the name of the filter.
+	repo := &fake.Repository{PackageRevisions: []repository.PackageRevision{&fake.PackageRevision{
+		Name:               "blueprints-pkg-42",
+		Namespace:          "default",
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "pkg-42", Revision: "v1"},
+		PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+	}}}
+	cad := &cadEngine{metadataStore: store}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "blueprints"}}
+
+	revisions, _, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{KubeObjectName: "blueprints-pkg-42"})
+	if err != nil {
+		t.Fatalf("listPackageRevisions failed: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].KubeObjectName() != "blueprints-pkg-42" {
+		t.Fatalf("listed %v; want blueprints-pkg-42", revisions)
+	}
+	// The lookup of a single package revision doesn't list the metadata of the repository.
+	if store.gets != 1 || store.lists != 0 {
+		t.Errorf("read the metadata with %d gets and %d lists; want a single get", store.gets, store.lists)
+	}
+}
+
+// BenchmarkListPackageRevisionsMetadata lists the 1000 package revisions of a repository,
+// reporting the metadata store calls per list.
+func BenchmarkListPackageRevisionsMetadata(b *testing.B) {
+	ctx := context.Background()
+	store := &countingMetadataStore{}
+	repo := &fake.Repository{}
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("blueprints-pkg-%04d", i)
+		repo.PackageRevisions = append(repo.PackageRevisions, &fake.PackageRevision{
+			Name:               name,
+			Namespace:          "default",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: name, Revision: "v1"},
+			PackageLifecycle:   api.PackageRevisionLifecyclePublished,
+		})
+		store.Metas = append(store.Metas, meta.PackageRevisionMeta{Name: name, Namespace: "default"})
+	}
+	cad := &cadEngine{metadataStore: store}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "blueprints"}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		revisions, _, err := cad.listPackageRevisions(ctx, repositoryObj, repo, repository.ListPackageRevisionFilter{})
+		if err != nil {
+			b.Fatalf("listPackageRevisions failed: %v", err)
+		}
+		if len(revisions) != 1000 {
+			b.Fatalf("listed %d package revisions; want 1000", len(revisions))
+		}
+	}
+	b.ReportMetric(float64(store.gets+store.lists)/float64(b.N), "store-calls/op")
+}
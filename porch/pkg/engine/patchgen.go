@@ -57,75 +57,122 @@ func (m *applyPatchMutation) Apply(ctx context.Context, resources repository.Pac
 	defer span.End()
 
 	result := repository.PackageResources{
-		Contents: map[string]string{},
+		Contents:  map[string]string{},
+		FileModes: copyFileModes(resources.FileModes),
 	}
 
 	for k, v := range resources.Contents {
 		result.Contents[k] = v
 	}
 
-	for _, patchSpec := range m.patchTask.Patches {
-		switch patchSpec.PatchType {
-		case api.PatchTypeCreateFile:
-			if _, found := result.Contents[patchSpec.File]; found {
-				// TODO: We should be able to tolerate this.  Either do a merge or create as a different filename "-2"
-				return result, nil, fmt.Errorf("patch wants to create file %q but already exists", patchSpec.File)
-			}
-			result.Contents[patchSpec.File] = patchSpec.Contents
-		case api.PatchTypeDeleteFile:
-			if _, found := result.Contents[patchSpec.File]; !found {
-				// TODO: I don't think this should be an error, but maybe we should use object manipulation more than file manipulation.
-				// TODO: Support object based patches where we can.
-				klog.Warningf("patch wants to delete file %q, but already deleted", patchSpec.File)
-			}
-			delete(result.Contents, patchSpec.File)
-		case api.PatchTypePatchFile:
-			oldContents, found := result.Contents[patchSpec.File]
-			if !found {
-				return result, nil, fmt.Errorf("patch specifies file %q which does not exist", patchSpec.File)
-			}
+	// All patches are evaluated so that a failure reports every patch which needs to be
+	// fixed, but the result is only returned if all of them applied cleanly.
+	results := make([]PatchResult, 0, len(m.patchTask.Patches))
+	failedFiles := map[string]int{}
+	failed := false
+	for i, patchSpec := range m.patchTask.Patches {
+		if earlier, found := failedFiles[patchSpec.File]; found {
+			results = append(results, PatchResult{
+				File:   patchSpec.File,
+				Status: PatchStatusSkipped,
+				Reason: fmt.Sprintf("depends on patches[%d], which failed", earlier),
+			})
+			continue
+		}
+		if err := applyPatch(ctx, result.Contents, result.FileModes, patchSpec); err != nil {
+			failedFiles[patchSpec.File] = i
+			failed = true
+			results = append(results, PatchResult{File: patchSpec.File, Status: PatchStatusFailed, Reason: err.Error()})
+			continue
+		}
+		results = append(results, PatchResult{File: patchSpec.File, Status: PatchStatusApplied})
+	}
+	if failed {
+		return result, nil, &PatchTaskError{Results: results}
+	}
 
-			files, preamble, err := gitdiff.Parse(strings.NewReader(patchSpec.Contents))
-			if err != nil {
-				return result, nil, fmt.Errorf("error parsing patch: %w", err)
-			}
+	return result, m.task, nil
+}
 
-			if len(files) == 0 {
-				return result, nil, fmt.Errorf("patch did not specify any files")
-			}
-			if len(files) > 1 {
-				return result, nil, fmt.Errorf("patch specified multiple files")
-			}
-			if preamble != "" {
-				return result, nil, fmt.Errorf("patch had unexpected preamble %q", preamble)
-			}
+// applyPatch applies the patch to the contents and file modes in place. They are left
+// unchanged if the patch cannot be applied.
+func applyPatch(ctx context.Context, contents map[string]string, modes map[string]api.FileMode, patchSpec api.PatchSpec) error {
+	switch patchSpec.PatchType {
+	case api.PatchTypeCreateFile:
+		if _, found := contents[patchSpec.File]; found {
+			// TODO: We should be able to tolerate this.  Either do a merge or create as a different filename "-2"
+			return fmt.Errorf("patch wants to create file %q but already exists", patchSpec.File)
+		}
+		contents[patchSpec.File] = patchSpec.Contents
+	case api.PatchTypeDeleteFile:
+		if _, found := contents[patchSpec.File]; !found {
+			// TODO: I don't think this should be an error, but maybe we should use object manipulation more than file manipulation.
+			// TODO: Support object based patches where we can.
+			klog.FromContext(ctx).Info("Patch wants to delete file, but already deleted", "file", patchSpec.File)
+		}
+		delete(contents, patchSpec.File)
+		delete(modes, patchSpec.File)
+	case api.PatchTypePatchFile:
+		oldContents, found := contents[patchSpec.File]
+		if !found {
+			return fmt.Errorf("patch specifies file %q which does not exist", patchSpec.File)
+		}
 
-			if files[0].OldName != patchSpec.File {
-				return result, nil, fmt.Errorf("patch contained unexpected name; got %q, want %q", files[0].OldName, patchSpec.File)
-			}
+		files, preamble, err := gitdiff.Parse(strings.NewReader(patchSpec.Contents))
+		if err != nil {
+			return fmt.Errorf("error parsing patch: %w", err)
+		}
 
-			if files[0].IsBinary {
-				return result, nil, fmt.Errorf("patch was a binary diff; expected text diff")
-			}
-			if files[0].IsCopy || files[0].IsDelete || files[0].IsNew || files[0].IsRename {
-				return result, nil, fmt.Errorf("patch was of an unexpected type (copy/delete/new/rename)")
-			}
-			if files[0].OldMode != files[0].NewMode {
-				return result, nil, fmt.Errorf("patch contained file mode change")
+		if len(files) == 0 {
+			return fmt.Errorf("patch did not specify any files")
+		}
+		if len(files) > 1 {
+			return fmt.Errorf("patch specified multiple files")
+		}
+		if preamble != "" {
+			return fmt.Errorf("patch had unexpected preamble %q", preamble)
+		}
+
+		if files[0].OldName != patchSpec.File {
+			return fmt.Errorf("patch contained unexpected name; got %q, want %q", files[0].OldName, patchSpec.File)
+		}
+
+		if files[0].IsBinary {
+			return fmt.Errorf("patch was a binary diff; expected text diff")
+		}
+		if files[0].IsCopy || files[0].IsDelete || files[0].IsNew {
+			return fmt.Errorf("patch was of an unexpected type (copy/delete/new)")
+		}
+		newName := patchSpec.File
+		if files[0].IsRename {
+			newName = files[0].NewName
+			if _, found := contents[newName]; found {
+				return fmt.Errorf("patch wants to rename file %q to %q but it already exists", patchSpec.File, newName)
 			}
-			var output bytes.Buffer
-			if err := gitdiff.Apply(&output, strings.NewReader(oldContents), files[0]); err != nil {
-				return result, nil, fmt.Errorf("error applying patch: %w", err)
+		}
+		mode := modes[patchSpec.File]
+		if files[0].OldMode != files[0].NewMode {
+			newMode, err := fileModeOf(files[0].NewMode)
+			if err != nil {
+				return fmt.Errorf("patch contained file mode change: %w", err)
 			}
+			mode = newMode
+		}
+		var output bytes.Buffer
+		if err := gitdiff.Apply(&output, strings.NewReader(oldContents), files[0]); err != nil {
+			return fmt.Errorf("error applying patch: %w", err)
+		}
 
-			patched := output.String()
-			result.Contents[patchSpec.File] = patched
-		default:
-			return result, nil, fmt.Errorf("unhandled patch type %q", patchSpec.PatchType)
+		delete(contents, patchSpec.File)
+		delete(modes, patchSpec.File)
+		contents[newName] = output.String()
+		if mode != "" {
+			setFileMode(modes, newName, mode)
 		}
+	default:
+		return fmt.Errorf("unhandled patch type %q", patchSpec.PatchType)
 	}
-
-	return result, m.task, nil
+	return nil
 }
 
 func buildPatchMutation(ctx context.Context, task *api.Task) (mutation, error) {
@@ -16,16 +16,21 @@ package engine
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/bluekeyes/go-gitdiff/gitdiff"
 	"github.com/google/go-cmp/cmp"
 	"github.com/hexops/gotextdiff"
 	"github.com/hexops/gotextdiff/myers"
 	"github.com/hexops/gotextdiff/span"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
 	"sigs.k8s.io/yaml"
 )
@@ -166,3 +171,57 @@ data:
 		t.Errorf("unexpected result from CreateThreeWayJSONMergePatch: (-want,+got): %s", diff)
 	}
 }
+
+func TestApplyPatchResults(t *testing.T) {
+	resources := repository.PackageResources{Contents: map[string]string{
+		"a.yaml": "a: 1\n",
+		"b.yaml": "b: 1\n",
+	}}
+	patchA, err := GeneratePatch("a.yaml", "a: 1\n", "a: 2\n")
+	if err != nil {
+		t.Fatalf("GeneratePatch failed: %v", err)
+	}
+	// The patch of b.yaml doesn't match its contents.
+	patchB, err := GeneratePatch("b.yaml", "b: 0\n", "b: 2\n")
+	if err != nil {
+		t.Fatalf("GeneratePatch failed: %v", err)
+	}
+	patchB2, err := GeneratePatch("b.yaml", "b: 2\n", "b: 3\n")
+	if err != nil {
+		t.Fatalf("GeneratePatch failed: %v", err)
+	}
+
+	m := &applyPatchMutation{patchTask: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{
+		patchA,
+		patchB,
+		{File: "c.yaml", PatchType: api.PatchTypeCreateFile, Contents: "c: 1\n"},
+		patchB2,
+		{File: "a.yaml", PatchType: api.PatchTypeCreateFile, Contents: "a: 3\n"},
+	}}}
+	_, _, err = m.Apply(context.Background(), resources)
+
+	var patchErr *PatchTaskError
+	if !errors.As(err, &patchErr) {
+		t.Fatalf("Apply returned %v; want a PatchTaskError", err)
+	}
+	var got []PatchStatus
+	for _, result := range patchErr.Results {
+		got = append(got, result.Status)
+	}
+	want := []PatchStatus{PatchStatusApplied, PatchStatusFailed, PatchStatusApplied, PatchStatusSkipped, PatchStatusFailed}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected patch results (-want, +got): %s", diff)
+	}
+	if got, want := patchErr.Results[3].Reason, "depends on patches[1], which failed"; got != want {
+		t.Errorf("unexpected reason for skipped patch: got %q, want %q", got, want)
+	}
+	if !apierrors.IsInvalid(err) {
+		t.Errorf("PatchTaskError isn't an Invalid API status: %v", err)
+	}
+	if got, want := len(patchErr.Status().Details.Causes), len(want); got != want {
+		t.Errorf("unexpected number of causes: got %d, want %d", got, want)
+	}
+	if got := resources.Contents["a.yaml"]; got != "a: 1\n" {
+		t.Errorf("failed patch task modified the input resources: a.yaml is %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PatchStatus is the outcome of applying one patch of a patch task.
+type PatchStatus string
+
+const (
+	// PatchStatusApplied means the patch applied cleanly.
+	PatchStatusApplied PatchStatus = "PatchApplied"
+	// PatchStatusFailed means the patch could not be applied.
+	PatchStatusFailed PatchStatus = "PatchFailed"
+	// PatchStatusSkipped means the patch wasn't evaluated because an earlier patch of the
+	// same file failed.
+	PatchStatusSkipped PatchStatus = "PatchSkipped"
+)
+
+// PatchResult is the outcome of applying one patch of a patch task.
+type PatchResult struct {
+	File   string
+	Status PatchStatus
+	// Reason explains why the patch failed or was skipped.
+	Reason string
+}
+
+// PatchTaskError is returned when some patches of a patch task cannot be applied. None of
+// the patches are applied then; Results holds the outcome of every patch, in order, so
+// that all problems can be fixed at once.
+//
+// PatchTaskError is an API status error: it is returned to clients as an Invalid status
+// with one cause per patch, whose type is the PatchStatus, field the file and message the
+// reason.
+type PatchTaskError struct {
+	Results []PatchResult
+}
+
+var _ apierrors.APIStatus = &PatchTaskError{}
+
+func (e *PatchTaskError) Error() string {
+	var problems []string
+	for i, result := range e.Results {
+		if result.Status != PatchStatusApplied {
+			problems = append(problems, fmt.Sprintf("patches[%d] (%s): %s", i, result.File, result.Reason))
+		}
+	}
+	return fmt.Sprintf("%d of %d patches could not be applied: %s", len(problems), len(e.Results), strings.Join(problems, "; "))
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PatchTaskError) Status() metav1.Status {
+	causes := make([]metav1.StatusCause, 0, len(e.Results))
+	for _, result := range e.Results {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(result.Status),
+			Message: result.Reason,
+			Field:   result.File,
+		})
+	}
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusUnprocessableEntity,
+		Reason:  metav1.StatusReasonInvalid,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group:  api.SchemeGroupVersion.Group,
+			Kind:   string(api.TaskTypePatch),
+			Causes: causes,
+		},
+	}
+}
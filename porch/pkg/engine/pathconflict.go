@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PackagePathConflictError is returned when a package is created whose path differs
+// from the path of an existing package of the repository only by case or unicode
+// normalization. Such packages collide when the repository is checked out on a
+// case-insensitive filesystem.
+//
+// PackagePathConflictError is an API status error: it is returned to clients as a
+// Conflict status.
+type PackagePathConflictError struct {
+	Repository string
+	Package    string
+	// Existing is the path of the existing package the package collides with.
+	Existing string
+}
+
+var _ apierrors.APIStatus = &PackagePathConflictError{}
+
+func (e *PackagePathConflictError) Error() string {
+	return fmt.Sprintf("package %q conflicts with existing package %q of repository %q: their paths differ only by case or unicode normalization", e.Package, e.Existing, e.Repository)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PackagePathConflictError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusConflict,
+		Reason:  metav1.StatusReasonConflict,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packages",
+			Name:  e.Package,
+		},
+	}
+}
+
+// checkPackagePath returns a PackagePathConflictError if the package path collides with
+// the path of an existing package of the repository.
+func checkPackagePath(ctx context.Context, repo repository.Repository, repositoryName, packageName string) error {
+	pkgRevs, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return err
+	}
+	for _, pr := range pkgRevs {
+		existing := pr.Key().Package
+		if repository.PackagePathsCollide(packageName, existing) {
+			return &PackagePathConflictError{Repository: repositoryName, Package: packageName, Existing: existing}
+		}
+	}
+	return nil
+}
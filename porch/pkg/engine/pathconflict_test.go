@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestCheckPackagePath(t *testing.T) {
+	ctx := context.Background()
+	repo := &fake.Repository{PackageRevisions: []repository.PackageRevision{
+		&fake.PackageRevision{PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "apps/Foo", Revision: "v1"}},
+	}}
+
+	for _, tc := range []struct {
+		packageName string
+		existing    string
+	}{
+		{packageName: "apps/Foo"},
+		{packageName: "apps/bar"},
+		{packageName: "apps/foo", existing: "apps/Foo"},
+		{packageName: "APPS/bar", existing: "apps/Foo"},
+	} {
+		t.Run(tc.packageName, func(t *testing.T) {
+			err := checkPackagePath(ctx, repo, "blueprints", tc.packageName)
+			if tc.existing == "" {
+				if err != nil {
+					t.Errorf("checkPackagePath failed: %v", err)
+				}
+				return
+			}
+			var conflict *PackagePathConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("got error %v; want a PackagePathConflictError", err)
+			}
+			if conflict.Existing != tc.existing {
+				t.Errorf("conflict with %q; want %q", conflict.Existing, tc.existing)
+			}
+			if !apierrors.IsConflict(err) {
+				t.Errorf("error isn't reported as a conflict: %v", err)
+			}
+		})
+	}
+}
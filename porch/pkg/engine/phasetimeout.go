@@ -0,0 +1,168 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phase is a phase of a package revision operation with its own timeout.
+type Phase string
+
+const (
+	// PhaseFetch fetches the upstream package of clone and update tasks.
+	PhaseFetch Phase = "fetch"
+	// PhaseRender renders the package and evaluates the functions of eval tasks.
+	PhaseRender Phase = "render"
+	// PhaseClose writes the package revision to its repository.
+	PhaseClose Phase = "close"
+)
+
+// PhaseTimeouts limits the duration of each phase of a package revision operation.
+// Zero means unlimited.
+type PhaseTimeouts struct {
+	Fetch  time.Duration
+	Render time.Duration
+	Close  time.Duration
+}
+
+func (t PhaseTimeouts) timeout(phase Phase) time.Duration {
+	switch phase {
+	case PhaseFetch:
+		return t.Fetch
+	case PhaseRender:
+		return t.Render
+	case PhaseClose:
+		return t.Close
+	default:
+		return 0
+	}
+}
+
+// PhaseTimeoutError is returned when a phase of a package revision operation exceeds
+// its timeout.
+//
+// PhaseTimeoutError is an API status error: it is returned to clients as a Timeout
+// status.
+type PhaseTimeoutError struct {
+	Phase   Phase
+	Timeout time.Duration
+}
+
+var _ apierrors.APIStatus = &PhaseTimeoutError{}
+
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("%s phase timed out after %s", e.Phase, e.Timeout)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *PhaseTimeoutError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusGatewayTimeout,
+		Reason:  metav1.StatusReasonTimeout,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisions",
+		},
+	}
+}
+
+// mutationPhase returns the phase the mutation belongs to, or "" if it isn't limited.
+func mutationPhase(m mutation) Phase {
+	switch m.(type) {
+	case *clonePackageMutation, *updatePackageMutation:
+		return PhaseFetch
+	case *renderPackageMutation, *evalFunctionMutation:
+		return PhaseRender
+	default:
+		return ""
+	}
+}
+
+type mutationResult struct {
+	resources repository.PackageResources
+	task      *api.Task
+	err       error
+}
+
+// applyMutation applies the mutation within the timeout of its phase. A mutation which
+// times out is abandoned; mutations only return new resources, so its result is
+// discarded when it finishes.
+func (cad *cadEngine) applyMutation(ctx context.Context, m mutation, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	phase := mutationPhase(m)
+	timeout := cad.phaseTimeouts.timeout(phase)
+	if timeout <= 0 {
+		return m.Apply(ctx, resources)
+	}
+
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan mutationResult, 1)
+	go func() {
+		applied, task, err := m.Apply(phaseCtx, resources)
+		done <- mutationResult{resources: applied, task: task, err: err}
+	}()
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return repository.PackageResources{}, nil, phaseError(ctx, phaseCtx, phase, timeout, result.err)
+		}
+		return result.resources, result.task, nil
+	case <-phaseCtx.Done():
+		return repository.PackageResources{}, nil, phaseError(ctx, phaseCtx, phase, timeout, phaseCtx.Err())
+	}
+}
+
+// closeDraft closes the draft within the timeout of the close phase. The draft is
+// written to the repository, so unlike a mutation it isn't abandoned: the timeout
+// cancels the context of the write.
+func (cad *cadEngine) closeDraft(ctx context.Context, draft repository.PackageDraft) (repository.PackageRevision, error) {
+	// A draft of a cancelled request is abandoned before it is written.
+	if err := checkCancelled(ctx); err != nil {
+		return nil, err
+	}
+	timeout := cad.phaseTimeouts.Close
+	if timeout <= 0 {
+		return draft.Close(ctx)
+	}
+
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	rev, err := draft.Close(phaseCtx)
+	if err != nil {
+		return nil, phaseError(ctx, phaseCtx, PhaseClose, timeout, err)
+	}
+	return rev, nil
+}
+
+// phaseError returns a PhaseTimeoutError if err was caused by the timeout of the phase,
+// rather than the cancellation of the request, and err otherwise.
+func phaseError(ctx, phaseCtx context.Context, phase Phase, timeout time.Duration, err error) error {
+	if errors.Is(phaseCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return &PhaseTimeoutError{Phase: phase, Timeout: timeout}
+	}
+	return err
+}
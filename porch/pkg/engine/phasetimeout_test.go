@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// blockingRenderer renders once released, ignoring the context like a function that
+// doesn't check for cancellation.
+type blockingRenderer struct {
+	release chan struct{}
+}
+
+func (r *blockingRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	<-r.release
+	return nil
+}
+
+// blockingDraft closes once its context is done.
+type blockingDraft struct {
+	recordingDraft
+}
+
+func (d *blockingDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRenderPhaseTimeout(t *testing.T) {
+	renderer := &blockingRenderer{release: make(chan struct{})}
+	defer close(renderer.release)
+	cad := &cadEngine{renderer: renderer, forceRender: true, phaseTimeouts: PhaseTimeouts{Render: 10 * time.Millisecond}}
+
+	obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "app"}}
+	err := cad.applyTasks(context.Background(), &recordingDraft{}, &configapi.Repository{}, obj, nil)
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected a PhaseTimeoutError, got %v", err)
+	}
+	if timeoutErr.Phase != PhaseRender {
+		t.Errorf("timed out in phase %q; want %q", timeoutErr.Phase, PhaseRender)
+	}
+	if !apierrors.IsTimeout(err) {
+		t.Errorf("PhaseTimeoutError isn't a timeout status error: %v", err)
+	}
+}
+
+func TestClosePhaseTimeout(t *testing.T) {
+	cad := &cadEngine{phaseTimeouts: PhaseTimeouts{Close: 10 * time.Millisecond}}
+
+	_, err := cad.closeDraft(context.Background(), &blockingDraft{})
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != PhaseClose {
+		t.Fatalf("expected a PhaseTimeoutError of the close phase, got %v", err)
+	}
+
+	// A cancelled request isn't reported as a phase timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cad.closeDraft(ctx, &blockingDraft{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation of the request, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TaskPipeline is a portable sequence of eval and patch tasks, exported from one package
+// revision to be applied to other packages.
+type TaskPipeline struct {
+	// Source is the name of the package revision the pipeline was exported from.
+	Source string `json:"source,omitempty"`
+	// Tasks are the eval and patch tasks of the source, in order. Eval tasks carry their
+	// function config as a complete KRM resource.
+	Tasks []api.Task `json:"tasks"`
+}
+
+// ExportPipeline returns the eval and patch tasks of the package revision with the given
+// name as a TaskPipeline. Tasks which create or update the package from a specific source
+// (init, clone, edit and update), render tasks, and patches of the Kptfile are left out;
+// they only make sense for the package they were applied to.
+func (cad *cadEngine) ExportPipeline(ctx context.Context, repositoryObj *configapi.Repository, name string) (*TaskPipeline, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ExportPipeline", trace.WithAttributes())
+	defer span.End()
+
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{KubeObjectName: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("package revision %q not found in repository %q", name, repositoryObj.Name)
+	}
+	rev, err := revisions[0].GetPackageRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTaskPipeline(rev)
+}
+
+func buildTaskPipeline(rev *api.PackageRevision) (*TaskPipeline, error) {
+	pipeline := &TaskPipeline{
+		Source: rev.Name,
+		Tasks:  []api.Task{},
+	}
+	for i := range rev.Spec.Tasks {
+		task := rev.Spec.Tasks[i].DeepCopy()
+		switch task.Type {
+		case api.TaskTypeEval:
+			if task.Eval == nil || isLegacyRenderTask(task) {
+				continue
+			}
+			if task.Eval.ConfigMap != nil {
+				// Resolve the inline config to the ConfigMap the function receives.
+				cm, err := fnruntime.NewConfigMap(task.Eval.ConfigMap)
+				if err != nil {
+					return nil, fmt.Errorf("cannot resolve config of task %d: %w", i, err)
+				}
+				raw, err := cm.MarshalJSON()
+				if err != nil {
+					return nil, fmt.Errorf("cannot resolve config of task %d: %w", i, err)
+				}
+				task.Eval.ConfigMap = nil
+				task.Eval.Config = runtime.RawExtension{Raw: raw}
+			}
+		case api.TaskTypePatch:
+			if task.Patch == nil {
+				continue
+			}
+			var patches []api.PatchSpec
+			for _, patch := range task.Patch.Patches {
+				if patch.File != kptfile.KptFileName {
+					patches = append(patches, patch)
+				}
+			}
+			if len(patches) == 0 {
+				continue
+			}
+			task.Patch.Patches = patches
+		default:
+			continue
+		}
+		pipeline.Tasks = append(pipeline.Tasks, *task)
+	}
+	return pipeline, nil
+}
+
+// ImportPipeline appends the tasks of the pipeline to the tasks of obj. The tasks of obj
+// must start with the task creating the package, such as init or clone.
+func ImportPipeline(obj *api.PackageRevision, pipeline *TaskPipeline) error {
+	if len(obj.Spec.Tasks) == 0 {
+		return fmt.Errorf("cannot import pipeline %q into package revision %q without tasks", pipeline.Source, obj.Name)
+	}
+	for i := range pipeline.Tasks {
+		obj.Spec.Tasks = append(obj.Spec.Tasks, *pipeline.Tasks[i].DeepCopy())
+	}
+	return nil
+}
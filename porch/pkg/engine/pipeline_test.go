@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExportPipeline(t *testing.T) {
+	const setNamespace = "gcr.io/kpt-fn/set-namespace:v0.4.1"
+	configPatch := api.PatchSpec{File: "config.yaml", PatchType: api.PatchTypeCreateFile, Contents: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"}
+
+	source := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Revision:    "v1",
+			Tasks: []api.Task{
+				{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}},
+				{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: setNamespace, ConfigMap: map[string]string{"namespace": "prod"}}},
+				{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{
+					{File: kptfile.KptFileName, PatchType: api.PatchTypePatchFile, Contents: "--- Kptfile\n+++ Kptfile\n"},
+					configPatch,
+				}}},
+				{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{
+					{File: kptfile.KptFileName, PatchType: api.PatchTypePatchFile, Contents: "--- Kptfile\n+++ Kptfile\n"},
+				}}},
+				{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: "render"}},
+			},
+		},
+	}
+
+	pipeline, err := buildTaskPipeline(source)
+	if err != nil {
+		t.Fatalf("buildTaskPipeline failed: %v", err)
+	}
+	if got, want := pipeline.Source, "blueprints-app-v1"; got != want {
+		t.Errorf("unexpected source: got %q, want %q", got, want)
+	}
+	var types []api.TaskType
+	for _, task := range pipeline.Tasks {
+		types = append(types, task.Type)
+	}
+	if diff := cmp.Diff([]api.TaskType{api.TaskTypeEval, api.TaskTypePatch}, types); diff != "" {
+		t.Fatalf("unexpected exported tasks (-want, +got): %s", diff)
+	}
+	eval := pipeline.Tasks[0].Eval
+	if eval.ConfigMap != nil || !strings.Contains(string(eval.Config.Raw), `"kind":"ConfigMap"`) {
+		t.Errorf("inline config of the eval task wasn't resolved: configMap %v, config %s", eval.ConfigMap, eval.Config.Raw)
+	}
+	if diff := cmp.Diff([]api.PatchSpec{configPatch}, pipeline.Tasks[1].Patch.Patches); diff != "" {
+		t.Errorf("unexpected exported patches (-want, +got): %s", diff)
+	}
+	if got := source.Spec.Tasks[1].Eval.ConfigMap; got == nil {
+		t.Errorf("exporting modified the source package revision")
+	}
+
+	// Seed a new package with the pipeline.
+	target := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-service-v1"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "service",
+			Revision:    "v1",
+			Tasks:       []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "service"}}},
+		},
+	}
+	if err := ImportPipeline(target, pipeline); err != nil {
+		t.Fatalf("ImportPipeline failed: %v", err)
+	}
+	if got, want := len(target.Spec.Tasks), 3; got != want {
+		t.Fatalf("unexpected number of tasks after import: got %d, want %d", got, want)
+	}
+
+	resources := repository.PackageResources{Contents: map[string]string{
+		kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: service\n",
+		"service.yaml":      "apiVersion: v1\nkind: Service\nmetadata:\n  name: service\n",
+	}}
+	for i := 1; i < len(target.Spec.Tasks); i++ {
+		task := &target.Spec.Tasks[i]
+		var m mutation
+		switch task.Type {
+		case api.TaskTypeEval:
+			m = &evalFunctionMutation{runtime: newBuiltinRuntime(), task: task}
+		case api.TaskTypePatch:
+			m = &applyPatchMutation{patchTask: task.Patch}
+		}
+		if resources, _, err = m.Apply(context.Background(), resources); err != nil {
+			t.Fatalf("applying imported task %d failed: %v", i, err)
+		}
+	}
+	if got := resources.Contents["service.yaml"]; !strings.Contains(got, "namespace: prod") {
+		t.Errorf("imported eval task wasn't applied:\n%s", got)
+	}
+	if _, found := resources.Contents["config.yaml"]; !found {
+		t.Errorf("imported patch task wasn't applied")
+	}
+
+	if err := ImportPipeline(&api.PackageRevision{}, pipeline); err == nil {
+		t.Errorf("importing into a package revision without tasks succeeded")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// The functions of the api.PipelineAppendAnnotation annotation are added to the mutators
+// of the root Kptfile only for the duration of the render; the pipeline of the Kptfile
+// is restored afterwards, so the overlay never becomes part of the package resources.
+
+// parsePipelineAppend parses the value of the api.PipelineAppendAnnotation annotation.
+// Functions must be identified by an image.
+func parsePipelineAppend(value string) ([]kptfile.Function, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var functions []kptfile.Function
+	if err := sigsyaml.UnmarshalStrict([]byte(value), &functions); err != nil {
+		return nil, fmt.Errorf("must be a YAML list of Kptfile pipeline functions: %w", err)
+	}
+	for i, function := range functions {
+		if function.Image == "" {
+			return nil, fmt.Errorf("function %d has no image; only functions identified by an image can be appended", i)
+		}
+	}
+	return functions, nil
+}
+
+// validatePipelineAppend validates the api.PipelineAppendAnnotation annotation of a
+// package revision being created or updated.
+func validatePipelineAppend(obj *api.PackageRevision) error {
+	value, found := obj.Annotations[api.PipelineAppendAnnotation]
+	if !found {
+		return nil
+	}
+	if _, err := parsePipelineAppend(value); err != nil {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, field.ErrorList{
+			field.Invalid(field.NewPath("metadata", "annotations").Key(api.PipelineAppendAnnotation), value, err.Error()),
+		})
+	}
+	return nil
+}
+
+// appendedPipeline is the root Kptfile of a package whose pipeline has functions
+// appended for the render.
+type appendedPipeline struct {
+	// original is the root Kptfile before the functions were appended.
+	original string
+	// appended is the root Kptfile with the functions appended.
+	appended string
+	// pipeline is the original pipeline of the root Kptfile, or nil if it has none.
+	pipeline *yaml.RNode
+}
+
+// appendPipeline returns the resources with the functions appended to the mutators of
+// the pipeline of the root Kptfile.
+func appendPipeline(resources repository.PackageResources, functions []kptfile.Function) (repository.PackageResources, *appendedPipeline, error) {
+	ap := &appendedPipeline{original: resources.Contents[kptfile.KptFileName]}
+	kf, err := yaml.Parse(ap.original)
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("cannot parse %s: %w", kptfile.KptFileName, err)
+	}
+	if pipeline := kf.Field("pipeline"); pipeline != nil {
+		ap.pipeline = pipeline.Value.Copy()
+	}
+
+	mutators, err := kf.Pipe(yaml.LookupCreate(yaml.SequenceNode, "pipeline", "mutators"))
+	if err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	for _, function := range functions {
+		data, err := sigsyaml.Marshal(function)
+		if err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+		node, err := yaml.Parse(string(data))
+		if err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+		if err := mutators.PipeE(yaml.Append(node.YNode())); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+	}
+
+	result, err := withRootKptfile(resources, kf)
+	if err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	ap.appended = result.Contents[kptfile.KptFileName]
+	return result, ap, nil
+}
+
+// restore returns the resources with the original pipeline of the root Kptfile. The
+// rest of the Kptfile, like the status render left behind, is kept; a Kptfile render
+// didn't change is restored as is.
+func (ap *appendedPipeline) restore(resources repository.PackageResources) (repository.PackageResources, error) {
+	contents, found := resources.Contents[kptfile.KptFileName]
+	if !found {
+		return resources, nil
+	}
+	if contents == ap.appended {
+		result := repository.PackageResources{Contents: map[string]string{}}
+		for k, v := range resources.Contents {
+			result.Contents[k] = v
+		}
+		result.Contents[kptfile.KptFileName] = ap.original
+		return result, nil
+	}
+	kf, err := yaml.Parse(contents)
+	if err != nil {
+		return repository.PackageResources{}, fmt.Errorf("cannot parse %s: %w", kptfile.KptFileName, err)
+	}
+	if ap.pipeline == nil {
+		_, err = kf.Pipe(yaml.Clear("pipeline"))
+	} else {
+		err = kf.PipeE(yaml.SetField("pipeline", ap.pipeline))
+	}
+	if err != nil {
+		return repository.PackageResources{}, err
+	}
+	return withRootKptfile(resources, kf)
+}
+
+// withRootKptfile returns a copy of the resources with the root Kptfile replaced by kf.
+func withRootKptfile(resources repository.PackageResources, kf *yaml.RNode) (repository.PackageResources, error) {
+	updated, err := kf.String()
+	if err != nil {
+		return repository.PackageResources{}, err
+	}
+	result := repository.PackageResources{Contents: map[string]string{}}
+	for k, v := range resources.Contents {
+		result.Contents[k] = v
+	}
+	result.Contents[kptfile.KptFileName] = updated
+	return result, nil
+}
+
+// functionImages returns the comma separated images of the functions.
+func functionImages(functions []kptfile.Function) string {
+	images := make([]string, 0, len(functions))
+	for _, function := range functions {
+		images = append(images, function.Image)
+	}
+	return strings.Join(images, ",")
+}
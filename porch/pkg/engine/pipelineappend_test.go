@@ -0,0 +1,234 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// pipelineRecordingRenderer records the mutators of the root Kptfile pipeline it runs.
+type pipelineRecordingRenderer struct {
+	mutators []string
+}
+
+func (r *pipelineRecordingRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	data, err := pkg.ReadFile(path.Join(opts.PkgPath, kptfile.KptFileName))
+	if err != nil {
+		return err
+	}
+	kf, err := internalpkg.DecodeKptfile(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r.mutators = nil
+	if kf.Pipeline != nil {
+		for _, function := range kf.Pipeline.Mutators {
+			r.mutators = append(r.mutators, function.Image)
+		}
+	}
+	return nil
+}
+
+func TestParsePipelineAppend(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{
+			name:  "functions",
+			value: "- image: gcr.io/kpt-fn/set-labels:v0.1\n  configMap:\n    env: prod\n- image: gcr.io/kpt-fn/set-annotations:v0.1\n",
+			want:  []string{"gcr.io/kpt-fn/set-labels:v0.1", "gcr.io/kpt-fn/set-annotations:v0.1"},
+		},
+		{name: "not a list", value: "image: gcr.io/kpt-fn/set-labels:v0.1", wantErr: true},
+		{name: "unknown field", value: "- image: gcr.io/kpt-fn/set-labels:v0.1\n  config: prod\n", wantErr: true},
+		{name: "exec", value: "- exec: ./set-labels\n", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			functions, err := parsePipelineAppend(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parsePipelineAppend returned error %v; want error %t", err, tc.wantErr)
+			}
+			var images []string
+			for _, function := range functions {
+				images = append(images, function.Image)
+			}
+			if diff := cmp.Diff(tc.want, images); diff != "" {
+				t.Errorf("unexpected functions (-want, +got): %s", diff)
+			}
+
+			obj := &api.PackageRevision{ObjectMeta: metav1.ObjectMeta{
+				Name:        "deployment-app-v1",
+				Annotations: map[string]string{api.PipelineAppendAnnotation: tc.value},
+			}}
+			if err := validatePipelineAppend(obj); (err != nil) != tc.wantErr || (err != nil && !apierrors.IsInvalid(err)) {
+				t.Errorf("validatePipelineAppend returned %v; want Invalid error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderPipelineAppend(t *testing.T) {
+	ctx := context.Background()
+	const overlay = "- image: gcr.io/kpt-fn/set-annotations:v0.1\n  configMap:\n    team: payments\n"
+
+	for _, tc := range []struct {
+		name    string
+		kptfile string
+		want    []string
+	}{
+		{
+			name: "pipeline",
+			kptfile: `# The app.
+apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+pipeline:
+  mutators:
+  - image: gcr.io/kpt-fn/set-labels:v0.1 # Label everything.
+`,
+			want: []string{"gcr.io/kpt-fn/set-labels:v0.1", "gcr.io/kpt-fn/set-annotations:v0.1"},
+		},
+		{
+			name:    "no pipeline",
+			kptfile: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			want:    []string{"gcr.io/kpt-fn/set-annotations:v0.1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer := &pipelineRecordingRenderer{}
+			cad := &cadEngine{renderer: renderer}
+			render := cad.renderMutation(map[string]string{api.PipelineAppendAnnotation: overlay})
+			// The overlay is a pipeline, so the implicit render doesn't skip the package.
+			render.skipWithoutPipeline = true
+
+			rendered, task, err := render.Apply(ctx, repository.PackageResources{Contents: map[string]string{kptfile.KptFileName: tc.kptfile}})
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, renderer.mutators); diff != "" {
+				t.Errorf("unexpected mutators run (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.kptfile, rendered.Contents[kptfile.KptFileName]); diff != "" {
+				t.Errorf("render didn't restore the Kptfile (-want, +got): %s", diff)
+			}
+			if task == nil || task.Render == nil || !cmp.Equal(task.Render.AppendedFunctions, []string{"gcr.io/kpt-fn/set-annotations:v0.1"}) {
+				t.Errorf("render task %+v doesn't record the appended functions", task)
+			}
+		})
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		cad := &cadEngine{renderer: &pipelineRecordingRenderer{}, allowedFunctionImages: AllowedFunctionImages{"gcr.io/kpt-fn/set-labels:*"}}
+		_, _, err := cad.renderMutation(map[string]string{api.PipelineAppendAnnotation: overlay}).Apply(ctx, repository.PackageResources{Contents: map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		}})
+		var notAllowed *FunctionNotAllowedError
+		if !errors.As(err, &notAllowed) {
+			t.Fatalf("render returned %v; want FunctionNotAllowedError", err)
+		}
+		if !strings.Contains(notAllowed.Source, api.PipelineAppendAnnotation) {
+			t.Errorf("unexpected source of the function which isn't allowed: %q", notAllowed.Source)
+		}
+	})
+}
+
+func TestUpdateWithPipelineAppend(t *testing.T) {
+	ctx := context.Background()
+
+	testdata, err := filepath.Abs(filepath.Join(".", "testdata", "update"))
+	if err != nil {
+		t.Fatalf("Failed to find testdata: %v", err)
+	}
+	load := func(dir string) repository.PackageResources {
+		resources, err := loadResourcesFromDirectory(filepath.Join(testdata, dir))
+		if err != nil {
+			t.Fatalf("failed to read %s resources: %v", dir, err)
+		}
+		return resources
+	}
+	local, original, upstream := load("local"), load("original"), load("upstream")
+
+	// The deployment repository appends a function to the pipeline of the blueprint.
+	updated, err := (&defaultPackageUpdater{}).Update(ctx, local, original, upstream)
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	renderer := &pipelineRecordingRenderer{}
+	cad := &cadEngine{renderer: renderer}
+	rendered, _, err := cad.renderMutation(map[string]string{
+		api.PipelineAppendAnnotation: "- image: gcr.io/kpt-fn/set-annotations:v0.1\n",
+	}).Apply(ctx, updated)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	// The upstream change merged cleanly.
+	if got := rendered.Contents["resourcequota.yaml"]; !strings.Contains(got, "memory: 60G") {
+		t.Errorf("upstream change is missing from the updated package:\n%s", got)
+	}
+	// The overlay ran after the pipeline of the updated package.
+	want := []string{
+		"gcr.io/kpt-fn/set-namespace:v0.3.4",
+		"gcr.io/kpt-fn/apply-replacements:v0.1.0",
+		"gcr.io/kpt-fn/set-annotations:v0.1",
+	}
+	if diff := cmp.Diff(want, renderer.mutators); diff != "" {
+		t.Errorf("unexpected mutators run (-want, +got): %s", diff)
+	}
+	// The overlay didn't become part of the package.
+	if diff := cmp.Diff(updated.Contents[kptfile.KptFileName], rendered.Contents[kptfile.KptFileName]); diff != "" {
+		t.Errorf("render changed the Kptfile of the package (-want, +got): %s", diff)
+	}
+}
+
+func TestAppendedPipelineRestore(t *testing.T) {
+	const kf = "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n"
+	appended, ap, err := appendPipeline(repository.PackageResources{Contents: map[string]string{kptfile.KptFileName: kf}},
+		[]kptfile.Function{{Image: "gcr.io/kpt-fn/set-annotations:v0.1"}})
+	if err != nil {
+		t.Fatalf("appendPipeline failed: %v", err)
+	}
+
+	// Render changed the Kptfile: the pipeline is restored, the changes of render are kept.
+	rendered := appended.Contents[kptfile.KptFileName] + "status:\n  conditions:\n  - type: Ready\n    status: \"True\"\n"
+	restored, err := ap.restore(repository.PackageResources{Contents: map[string]string{kptfile.KptFileName: rendered}})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	want := kf + "status:\n  conditions:\n  - type: Ready\n    status: \"True\"\n"
+	if diff := cmp.Diff(want, restored.Contents[kptfile.KptFileName]); diff != "" {
+		t.Errorf("unexpected restored Kptfile (-want, +got): %s", diff)
+	}
+}
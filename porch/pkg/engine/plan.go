@@ -0,0 +1,191 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MutationType is the kind of change a planned mutation makes to a package.
+type MutationType string
+
+const (
+	MutationInit    MutationType = "init"
+	MutationClone   MutationType = "clone"
+	MutationUpdate  MutationType = "update"
+	MutationEdit    MutationType = "edit"
+	MutationPatch   MutationType = "patch"
+	MutationEval    MutationType = "eval"
+	MutationRender  MutationType = "render"
+	MutationReplace MutationType = "replace"
+	// MutationMarkUnrendered and MutationMarkRendered record in the Kptfile whether the
+	// render of a draft is deferred.
+	MutationMarkUnrendered MutationType = "mark-unrendered"
+	MutationMarkRendered   MutationType = "mark-rendered"
+)
+
+// MutationPlan is the ordered list of mutations creating or updating a package revision.
+// It serializes to JSON or YAML.
+type MutationPlan struct {
+	Steps []PlannedMutation `json:"steps"`
+}
+
+// PlannedMutation is one mutation of a MutationPlan, with its key inputs.
+type PlannedMutation struct {
+	Type MutationType `json:"type"`
+	// Package is the name of the package created by an init mutation.
+	Package string `json:"package,omitempty"`
+	// Upstream is the package cloned, updated to or copied by the mutation.
+	Upstream string                   `json:"upstream,omitempty"`
+	Strategy api.PackageMergeStrategy `json:"strategy,omitempty"`
+	// Files is the number of files patched or replaced.
+	Files int `json:"files,omitempty"`
+	// Image is the image of the function evaluated.
+	Image string `json:"image,omitempty"`
+	// Name is the name of the mutation of a custom task, whose type is the task type.
+	Name string `json:"name,omitempty"`
+}
+
+// PlanPackageRevision returns the mutations CreatePackageRevision would apply to create
+// obj, without applying them. obj isn't modified.
+func (cad *cadEngine) PlanPackageRevision(ctx context.Context, repositoryObj *configapi.Repository, obj *api.PackageRevision, parent *PackageRevision) (*MutationPlan, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::PlanPackageRevision", trace.WithAttributes())
+	defer span.End()
+
+	packageConfig, err := buildPackageConfig(ctx, obj, parent)
+	if err != nil {
+		return nil, err
+	}
+	obj = obj.DeepCopy()
+	cad.recordToolchain(obj)
+	mutations, err := cad.creationMutations(ctx, repositoryObj, obj, packageConfig)
+	if err != nil {
+		return nil, err
+	}
+	return buildMutationPlan(mutations)
+}
+
+// PlanPackageRevisionUpdate returns the mutations UpdatePackageRevision would apply to
+// update oldObj to newObj, without applying them. The plan of a package revision whose
+// contents aren't changed, such as a published package revision, has no steps.
+func (cad *cadEngine) PlanPackageRevisionUpdate(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, oldObj, newObj *api.PackageRevision, parent *PackageRevision) (*MutationPlan, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::PlanPackageRevisionUpdate", trace.WithAttributes())
+	defer span.End()
+
+	if oldObj.Spec.Lifecycle == api.PackageRevisionLifecyclePublished {
+		return buildMutationPlan(nil)
+	}
+	newObj = newObj.DeepCopy()
+	if err := normalizeRevisionUpdate(oldObj, newObj); err != nil {
+		return nil, err
+	}
+	preserveUpstreamResolution(oldObj, newObj)
+	if err := validateTaskReplay(oldObj, newObj); err != nil {
+		return nil, err
+	}
+	if isRecloneAndReplay(oldObj, newObj) || isTaskReplay(oldObj, newObj) {
+		packageConfig, err := buildPackageConfig(ctx, newObj, parent)
+		if err != nil {
+			return nil, err
+		}
+		mutations, err := cad.creationMutations(ctx, repositoryObj, newObj, packageConfig)
+		if err != nil {
+			return nil, err
+		}
+		return buildMutationPlan(mutations)
+	}
+
+	snapshot := newResourceSnapshot(oldPackage.repoPackageRevision)
+	mutations, err := cad.updateMutations(ctx, snapshot, repositoryObj, oldObj, newObj)
+	if err != nil {
+		return nil, err
+	}
+	if oldObj.Spec.Lifecycle != api.PackageRevisionLifecycleDraft {
+		mutations = nil
+	}
+	return buildMutationPlan(mutations)
+}
+
+func buildMutationPlan(mutations []mutation) (*MutationPlan, error) {
+	plan := &MutationPlan{Steps: []PlannedMutation{}}
+	for _, m := range mutations {
+		step, err := planMutation(m)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+func planMutation(m mutation) (PlannedMutation, error) {
+	switch m := m.(type) {
+	case *initPackageMutation:
+		return PlannedMutation{Type: MutationInit, Package: m.name}, nil
+	case *clonePackageMutation:
+		return PlannedMutation{
+			Type:     MutationClone,
+			Upstream: describeUpstream(&m.task.Clone.Upstream),
+			Strategy: m.task.Clone.Strategy,
+		}, nil
+	case *updatePackageMutation:
+		return PlannedMutation{Type: MutationUpdate, Upstream: describeUpstream(&m.updateTask.Update.Upstream)}, nil
+	case *editPackageMutation:
+		step := PlannedMutation{Type: MutationEdit}
+		if source := m.task.Edit.Source; source != nil {
+			step.Upstream = source.Name
+		}
+		return step, nil
+	case *applyPatchMutation:
+		return PlannedMutation{Type: MutationPatch, Files: len(m.patchTask.Patches)}, nil
+	case *evalFunctionMutation:
+		return PlannedMutation{Type: MutationEval, Image: m.task.Eval.Image}, nil
+	case *builtinEvalMutation:
+		return PlannedMutation{Type: MutationEval, Image: m.function}, nil
+	case *renderPackageMutation:
+		return PlannedMutation{Type: MutationRender}, nil
+	case *pluginMutation:
+		return PlannedMutation{Type: MutationType(m.task.Type), Name: m.mutation.Name()}, nil
+	case *mutationReplaceResources:
+		return PlannedMutation{Type: MutationReplace, Files: len(m.newResources.Spec.Resources)}, nil
+	case *renderedConditionMutation:
+		if !m.rendered {
+			return PlannedMutation{Type: MutationMarkUnrendered}, nil
+		}
+		return PlannedMutation{Type: MutationMarkRendered}, nil
+	default:
+		return PlannedMutation{}, fmt.Errorf("cannot plan mutation of type %T", m)
+	}
+}
+
+// describeUpstream returns the package revision name, git location or OCI image of the
+// upstream package.
+func describeUpstream(upstream *api.UpstreamPackage) string {
+	switch {
+	case upstream.UpstreamRef != nil:
+		return upstream.UpstreamRef.Name
+	case upstream.Git != nil:
+		return fmt.Sprintf("%s/%s@%s", upstream.Git.Repo, upstream.Git.Directory, upstream.Git.Ref)
+	case upstream.Oci != nil:
+		return upstream.Oci.Image
+	default:
+		return ""
+	}
+}
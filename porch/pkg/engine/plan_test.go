@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPlanPackageRevision(t *testing.T) {
+	renderer := &countingRenderer{}
+	cad := &cadEngine{renderer: renderer}
+	obj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployments-app-v1", Namespace: "default"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Tasks: []api.Task{
+				{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}},
+				{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{
+					Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-a1"}},
+					Strategy: api.ResourceMerge,
+				}},
+				{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{
+					{File: "a.yaml", PatchType: api.PatchTypeCreateFile},
+					{File: "b.yaml", PatchType: api.PatchTypeCreateFile},
+				}}},
+			},
+		},
+	}
+
+	plan, err := cad.PlanPackageRevision(context.Background(), &configapi.Repository{}, obj, nil)
+	if err != nil {
+		t.Fatalf("PlanPackageRevision failed: %v", err)
+	}
+	want := &MutationPlan{Steps: []PlannedMutation{
+		{Type: MutationInit, Package: "app"},
+		{Type: MutationClone, Upstream: "blueprints-a1", Strategy: api.ResourceMerge},
+		{Type: MutationPatch, Files: 2},
+		{Type: MutationRender},
+	}}
+	if diff := cmp.Diff(want, plan); diff != "" {
+		t.Errorf("unexpected plan (-want, +got): %s", diff)
+	}
+	if renderer.renders != 0 {
+		t.Errorf("planning rendered the package %d times", renderer.renders)
+	}
+	if len(obj.Spec.Tasks) != 3 {
+		t.Errorf("planning modified the tasks: %v", obj.Spec.Tasks)
+	}
+
+	out, err := yaml.Marshal(plan)
+	if err != nil {
+		t.Fatalf("cannot marshal plan: %v", err)
+	}
+	if !strings.Contains(string(out), "- files: 2\n  type: patch\n") {
+		t.Errorf("unexpected YAML plan:\n%s", out)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Mutation is a change to the resources of a package, made by a task of a custom task
+// type registered with WithTaskHandlers.
+type Mutation interface {
+	// Name identifies the mutation in traces and logs.
+	Name() string
+	// Apply returns the resources changed by the mutation, and the task recorded with
+	// them. If the returned task is nil, the task the mutation was created for is
+	// recorded.
+	Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error)
+}
+
+// MutationFactory returns the Mutation of a task of a custom task type, for the package
+// revision obj. It returns an error if the task is invalid; the error is reported to
+// the client like the errors of the built-in tasks.
+//
+// The api.Task of a custom task has no fields besides its type, so a MutationFactory is
+// configured when it is registered, or from the package revision.
+type MutationFactory func(ctx context.Context, obj *api.PackageRevision, task *api.Task) (Mutation, error)
+
+// builtinTaskTypes are the task types which can't be handled by custom task handlers.
+var builtinTaskTypes = map[api.TaskType]bool{
+	api.TaskTypeInit:   true,
+	api.TaskTypeClone:  true,
+	api.TaskTypePatch:  true,
+	api.TaskTypeEdit:   true,
+	api.TaskTypeEval:   true,
+	api.TaskTypeUpdate: true,
+	api.TaskTypeRender: true,
+}
+
+// WithTaskHandlers registers the mutations of custom task types. Tasks of other types
+// than the built-in and registered ones are rejected.
+func WithTaskHandlers(handlers map[api.TaskType]MutationFactory) EngineOption {
+	return EngineOptionFunc(func(engine *cadEngine) error {
+		for taskType, factory := range handlers {
+			if taskType == "" {
+				return fmt.Errorf("task handler has no task type")
+			}
+			if builtinTaskTypes[taskType] {
+				return fmt.Errorf("cannot register a handler of the built-in task type %q", taskType)
+			}
+			if factory == nil {
+				return fmt.Errorf("handler of task type %q is nil", taskType)
+			}
+			if engine.taskHandlers == nil {
+				engine.taskHandlers = map[api.TaskType]MutationFactory{}
+			}
+			engine.taskHandlers[taskType] = factory
+		}
+		return nil
+	})
+}
+
+// pluginMutation applies the Mutation of a custom task.
+type pluginMutation struct {
+	task     *api.Task
+	mutation Mutation
+}
+
+var _ mutation = &pluginMutation{}
+
+func (m *pluginMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	ctx, span := tracer.Start(ctx, "pluginMutation::Apply", trace.WithAttributes(
+		attribute.String("task.type", string(m.task.Type)),
+		attribute.String("mutation.name", m.mutation.Name()),
+	))
+	defer span.End()
+
+	applied, task, err := m.mutation.Apply(ctx, resources)
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("%s task %s failed: %w", m.task.Type, m.mutation.Name(), err)
+	}
+	if task == nil {
+		task = m.task
+	}
+	if task.Type != m.task.Type {
+		return repository.PackageResources{}, nil, fmt.Errorf("%s task %s recorded a task of type %q", m.task.Type, m.mutation.Name(), task.Type)
+	}
+	return applied, task, nil
+}
+
+// mapCustomTaskToMutation returns the mutation of a task of a registered custom task type.
+func (cad *cadEngine) mapCustomTaskToMutation(ctx context.Context, obj *api.PackageRevision, task *api.Task) (mutation, error) {
+	factory, found := cad.taskHandlers[task.Type]
+	if !found {
+		return nil, invalidPackageRevision(obj, field.NotSupported(taskPath(obj, task).Child("type"), task.Type, cad.supportedTaskTypes()))
+	}
+	m, err := factory(ctx, obj, task)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s task: %w", task.Type, err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("handler of task type %q returned no mutation", task.Type)
+	}
+	return &pluginMutation{task: task, mutation: m}, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+const taskTypeAddFile api.TaskType = "add-file"
+
+// addFileMutation adds a file to the package.
+type addFileMutation struct {
+	file string
+}
+
+func (m *addFileMutation) Name() string {
+	return "add-file"
+}
+
+func (m *addFileMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	contents := map[string]string{m.file: "# added\n"}
+	for k, v := range resources.Contents {
+		contents[k] = v
+	}
+	return repository.PackageResources{Contents: contents}, nil, nil
+}
+
+func addFileHandler(ctx context.Context, obj *api.PackageRevision, task *api.Task) (Mutation, error) {
+	file := obj.Annotations["example.com/file"]
+	if file == "" {
+		return nil, errors.New("no file")
+	}
+	return &addFileMutation{file: file}, nil
+}
+
+func TestTaskHandlers(t *testing.T) {
+	ctx := context.Background()
+	newObj := func(file string) *api.PackageRevision {
+		obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Tasks: []api.Task{
+				{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}},
+				{Type: taskTypeAddFile},
+			},
+		}}
+		if file != "" {
+			obj.Annotations = map[string]string{"example.com/file": file}
+		}
+		return obj
+	}
+
+	engine, err := NewCaDEngine(WithTaskHandlers(map[api.TaskType]MutationFactory{taskTypeAddFile: addFileHandler}))
+	if err != nil {
+		t.Fatalf("NewCaDEngine failed: %v", err)
+	}
+	cad := engine.(*cadEngine)
+	cad.renderer = &countingRenderer{}
+
+	t.Run("registered", func(t *testing.T) {
+		draft := &recordingDraft{}
+		if err := cad.applyTasks(ctx, draft, &configapi.Repository{}, newObj("added.yaml"), nil); err != nil {
+			t.Fatalf("applyTasks failed: %v", err)
+		}
+		if got, want := len(draft.tasks), 2; got != want {
+			t.Fatalf("unexpected number of commits: got %d, want %d", got, want)
+		}
+		if got := draft.tasks[1].Type; got != taskTypeAddFile {
+			t.Errorf("custom task recorded as %q; want %q", got, taskTypeAddFile)
+		}
+		if _, found := draft.resources["added.yaml"]; !found {
+			t.Errorf("custom task wasn't applied")
+		}
+
+		plan, err := cad.PlanPackageRevision(ctx, &configapi.Repository{}, newObj("added.yaml"), nil)
+		if err != nil {
+			t.Fatalf("PlanPackageRevision failed: %v", err)
+		}
+		if got, want := plan.Steps[1], (PlannedMutation{Type: MutationType(taskTypeAddFile), Name: "add-file"}); got != want {
+			t.Errorf("unexpected planned step %+v; want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := cad.applyTasks(ctx, &recordingDraft{}, &configapi.Repository{}, newObj(""), nil)
+		var taskErr *TaskError
+		if !errors.As(err, &taskErr) || taskErr.Class != UserError {
+			t.Errorf("invalid custom task returned %v; want a user error", err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		obj := newObj("added.yaml")
+		obj.Spec.Tasks[1].Type = "unknown"
+		if err := cad.applyTasks(ctx, &recordingDraft{}, &configapi.Repository{}, obj, nil); err == nil {
+			t.Errorf("task of unknown type was applied")
+		}
+	})
+
+	t.Run("built-in type", func(t *testing.T) {
+		if _, err := NewCaDEngine(WithTaskHandlers(map[api.TaskType]MutationFactory{api.TaskTypeEval: addFileHandler})); err == nil {
+			t.Errorf("handler of built-in task type was registered")
+		}
+	})
+}
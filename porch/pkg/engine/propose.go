@@ -0,0 +1,255 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+// DefaultProposalDiffBytes bounds the size of the diffs of a ProposalDiff unless another
+// bound is configured.
+const DefaultProposalDiffBytes = 256 * 1024
+
+// ProposeHook is invoked after a package revision has been proposed.
+type ProposeHook interface {
+	// OnPropose is called with the proposed package revision and its changes relative
+	// to the latest published revision of its package.
+	OnPropose(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision, diff *ProposalDiff) error
+}
+
+// FileChangeType is the type of change of a file of a proposal.
+type FileChangeType string
+
+const (
+	FileAdded    FileChangeType = "Added"
+	FileModified FileChangeType = "Modified"
+	FileDeleted  FileChangeType = "Deleted"
+)
+
+// ProposalDiff is the diff of a proposed package revision against the latest published
+// revision of its package.
+type ProposalDiff struct {
+	// Base is the name of the published revision the proposal is compared to, or empty
+	// if the package has no published revision and all files are added.
+	Base string `json:"base,omitempty"`
+	// Files are the changed files, sorted by path.
+	Files []FileDiff `json:"files"`
+	// Truncated is set if the diffs of some files were left out to bound the size of
+	// the diff.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// FileDiff is the change of a file of a proposal.
+type FileDiff struct {
+	Path   string         `json:"path"`
+	Change FileChangeType `json:"change"`
+	// Diff is the unified diff of the file; it is empty if it was left out.
+	Diff string `json:"diff,omitempty"`
+	// Truncated is set if the diff of the file was left out to bound the size of the
+	// diff.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// diffPackages returns the changes from base to the package contents. The list of files
+// is always complete; the unified diffs are included in the order of the files for as
+// long as their total size stays within maxBytes.
+func diffPackages(base, contents map[string]string, maxBytes int) *ProposalDiff {
+	paths := map[string]bool{}
+	for p := range base {
+		paths[p] = true
+	}
+	for p := range contents {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	result := &ProposalDiff{Files: []FileDiff{}}
+	remaining := maxBytes
+	for _, p := range sorted {
+		old, inBase := base[p]
+		current, inContents := contents[p]
+		file := FileDiff{Path: p}
+		switch {
+		case !inBase:
+			file.Change = FileAdded
+		case !inContents:
+			file.Change = FileDeleted
+		case old != current:
+			file.Change = FileModified
+		default:
+			continue
+		}
+
+		from, to := "a/"+p, "b/"+p
+		switch file.Change {
+		case FileAdded:
+			from = "/dev/null"
+		case FileDeleted:
+			to = "/dev/null"
+		}
+		edits := myers.ComputeEdits(span.URIFromPath(p), old, current)
+		diff := fmt.Sprint(gotextdiff.ToUnified(from, to, old, edits))
+		if len(diff) > remaining {
+			file.Truncated = true
+			result.Truncated = true
+		} else {
+			file.Diff = diff
+			remaining -= len(diff)
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result
+}
+
+// runProposeHooks runs the propose hooks for the proposed package revision. The package
+// revision is already proposed, so failures are logged rather than failing the proposal.
+func (cad *cadEngine) runProposeHooks(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) {
+	if len(cad.proposeHooks) == 0 {
+		return
+	}
+	ctx, span := tracer.Start(ctx, "cadEngine::runProposeHooks", trace.WithAttributes())
+	defer span.End()
+
+	diff, err := cad.proposalDiff(ctx, repositoryObj, pkgRev)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "Cannot compute the diff of proposed package revision", "name", pkgRev.KubeObjectName())
+		return
+	}
+	for _, hook := range cad.proposeHooks {
+		if err := hook.OnPropose(ctx, repositoryObj, pkgRev, diff); err != nil {
+			klog.FromContext(ctx).Error(err, "Propose hook failed for package revision", "name", pkgRev.KubeObjectName())
+		}
+	}
+}
+
+// proposalDiff returns the diff of the proposed package revision against the latest
+// published revision of its package which precedes it.
+func (cad *cadEngine) proposalDiff(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) (*ProposalDiff, error) {
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	key := pkgRev.Key()
+	revisions, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{Package: key.Package})
+	if err != nil {
+		return nil, err
+	}
+	return diffAgainstPublished(ctx, revisions, pkgRev, cad.proposalDiffBytes)
+}
+
+// diffAgainstPublished returns the diff of the package revision against the latest
+// published revision of the revisions which precedes it.
+func diffAgainstPublished(ctx context.Context, revisions []repository.PackageRevision, pkgRev repository.PackageRevision, maxBytes int) (*ProposalDiff, error) {
+	resources, err := pkgRev.GetResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get resources of package revision %q: %w", pkgRev.KubeObjectName(), err)
+	}
+	var base string
+	var baseContents map[string]string
+	if prior := latestPublishedBefore(revisions, pkgRev.Key()); prior != nil {
+		priorResources, err := prior.GetResources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get resources of package revision %q: %w", prior.KubeObjectName(), err)
+		}
+		base = prior.KubeObjectName()
+		baseContents = priorResources.Spec.Resources
+	}
+	diff := diffPackages(baseContents, resources.Spec.Resources, maxBytes)
+	diff.Base = base
+	return diff, nil
+}
+
+// ProposalEvent is the payload the proposal webhook posts when a package revision is
+// proposed.
+type ProposalEvent struct {
+	// Type is the lifecycle of the package revision, Proposed.
+	Type            api.PackageRevisionLifecycle `json:"type"`
+	Namespace       string                       `json:"namespace"`
+	Repository      string                       `json:"repository"`
+	PackageRevision string                       `json:"packageRevision"`
+	Package         string                       `json:"package"`
+	Revision        string                       `json:"revision"`
+	Diff            *ProposalDiff                `json:"diff"`
+}
+
+// proposalWebhook posts a ProposalEvent to a URL for each proposed package revision.
+type proposalWebhook struct {
+	url    string
+	client *http.Client
+}
+
+var _ ProposeHook = &proposalWebhook{}
+
+// NewProposalWebhook returns a ProposeHook which posts a ProposalEvent as JSON to the URL
+// for each proposed package revision, for example to trigger CI checks of the changed
+// files.
+func NewProposalWebhook(url string) ProposeHook {
+	return &proposalWebhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *proposalWebhook) OnPropose(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision, diff *ProposalDiff) error {
+	ctx, span := tracer.Start(ctx, "proposalWebhook::OnPropose", trace.WithAttributes())
+	defer span.End()
+
+	key := pkgRev.Key()
+	payload, err := json.Marshal(ProposalEvent{
+		Type:            api.PackageRevisionLifecycleProposed,
+		Namespace:       repositoryObj.Namespace,
+		Repository:      repositoryObj.Name,
+		PackageRevision: pkgRev.KubeObjectName(),
+		Package:         key.Package,
+		Revision:        key.Revision,
+		Diff:            diff,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot post proposal event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("proposal webhook returned %s", resp.Status)
+	}
+	return nil
+}
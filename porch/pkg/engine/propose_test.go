@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func fakeRevision(name, revision string, lifecycle api.PackageRevisionLifecycle, contents map[string]string) *fake.PackageRevision {
+	return &fake.PackageRevision{
+		Name:               name,
+		Namespace:          "default",
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: revision},
+		PackageLifecycle:   lifecycle,
+		Resources:          &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: contents}},
+	}
+}
+
+func TestDiffAgainstPublished(t *testing.T) {
+	ctx := context.Background()
+	v1 := fakeRevision("blueprints-1111", "v1", api.PackageRevisionLifecyclePublished, map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: \"1\"\n",
+		"service.yaml":   "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n",
+	})
+	v2 := fakeRevision("blueprints-2222", "v2", api.PackageRevisionLifecycleProposed, map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: \"3\"\n",
+		"ingress.yaml":   "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: app\n",
+	})
+
+	t.Run("modify, delete and add", func(t *testing.T) {
+		diff, err := diffAgainstPublished(ctx, []repository.PackageRevision{v1, v2}, v2, DefaultProposalDiffBytes)
+		if err != nil {
+			t.Fatalf("diffAgainstPublished failed: %v", err)
+		}
+		want := &ProposalDiff{
+			Base: "blueprints-1111",
+			Files: []FileDiff{
+				{Path: "configmap.yaml", Change: FileModified, Diff: "--- a/configmap.yaml\n+++ b/configmap.yaml\n@@ -3,4 +3,4 @@\n metadata:\n   name: app\n data:\n-  replicas: \"1\"\n+  replicas: \"3\"\n"},
+				{Path: "ingress.yaml", Change: FileAdded, Diff: "--- /dev/null\n+++ b/ingress.yaml\n@@ -1 +1,4 @@\n+apiVersion: networking.k8s.io/v1\n+kind: Ingress\n+metadata:\n+  name: app\n"},
+				{Path: "service.yaml", Change: FileDeleted, Diff: "--- a/service.yaml\n+++ /dev/null\n@@ -1,4 +1 @@\n-apiVersion: v1\n-kind: Service\n-metadata:\n-  name: app\n"},
+			},
+		}
+		if diff := cmp.Diff(want, diff); diff != "" {
+			t.Errorf("unexpected diff (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("first proposal", func(t *testing.T) {
+		diff, err := diffAgainstPublished(ctx, []repository.PackageRevision{v2}, v2, DefaultProposalDiffBytes)
+		if err != nil {
+			t.Fatalf("diffAgainstPublished failed: %v", err)
+		}
+		if diff.Base != "" {
+			t.Errorf("unexpected base %q of the first proposal", diff.Base)
+		}
+		var added []string
+		for _, file := range diff.Files {
+			if file.Change != FileAdded {
+				t.Errorf("file %s of the first proposal is %s; want %s", file.Path, file.Change, FileAdded)
+			}
+			added = append(added, file.Path)
+		}
+		if diff := cmp.Diff([]string{"Kptfile", "configmap.yaml", "ingress.yaml"}, added); diff != "" {
+			t.Errorf("unexpected added files (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("size bound", func(t *testing.T) {
+		// Room for the diff of configmap.yaml only.
+		diff, err := diffAgainstPublished(ctx, []repository.PackageRevision{v1, v2}, v2, 150)
+		if err != nil {
+			t.Fatalf("diffAgainstPublished failed: %v", err)
+		}
+		if !diff.Truncated {
+			t.Errorf("diff exceeding the bound isn't marked as truncated")
+		}
+		if len(diff.Files) != 3 {
+			t.Fatalf("truncated diff doesn't list all changed files: %v", diff.Files)
+		}
+		size := 0
+		for _, file := range diff.Files {
+			size += len(file.Diff)
+			if file.Truncated != (file.Diff == "") {
+				t.Errorf("file %s has diff %q but truncated %t", file.Path, file.Diff, file.Truncated)
+			}
+		}
+		if diff.Files[0].Diff == "" {
+			t.Errorf("diff of %s fits, but was left out", diff.Files[0].Path)
+		}
+		if size > 150 {
+			t.Errorf("diffs of %d bytes exceed the bound", size)
+		}
+	})
+}
+
+func TestProposalWebhook(t *testing.T) {
+	var got ProposalEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("cannot decode proposal event: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	pkgRev := fakeRevision("blueprints-2222", "v2", api.PackageRevisionLifecycleProposed, nil)
+	diff := &ProposalDiff{Base: "blueprints-1111", Files: []FileDiff{{Path: "service.yaml", Change: FileDeleted, Truncated: true}}, Truncated: true}
+	if err := NewProposalWebhook(server.URL).OnPropose(context.Background(), repositoryObj, pkgRev, diff); err != nil {
+		t.Fatalf("OnPropose failed: %v", err)
+	}
+
+	want := ProposalEvent{
+		Type:            api.PackageRevisionLifecycleProposed,
+		Namespace:       "default",
+		Repository:      "blueprints",
+		PackageRevision: "blueprints-2222",
+		Package:         "app",
+		Revision:        "v2",
+		Diff:            diff,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected proposal event (-want, +got): %s", diff)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	err := NewProposalWebhook(failing.URL).OnPropose(context.Background(), repositoryObj, pkgRev, diff)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("OnPropose returned %v; want error reporting the status", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-containerregistry/pkg/gcrane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+// PublishHook is invoked after a package revision has been published.
+type PublishHook interface {
+	// OnPublish is called with the published package revision. It may return an
+	// artifact exported from the package revision, which is recorded in its status.
+	OnPublish(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) (*api.Artifact, error)
+}
+
+// runPublishHooks runs the publish hooks and returns the artifacts they exported. The
+// package revision is already published, so a failing hook is logged rather than
+// failing the publish.
+func (cad *cadEngine) runPublishHooks(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) []api.Artifact {
+	ctx, span := tracer.Start(ctx, "cadEngine::runPublishHooks", trace.WithAttributes())
+	defer span.End()
+
+	var artifacts []api.Artifact
+	for _, hook := range cad.publishHooks {
+		artifact, err := hook.OnPublish(ctx, repositoryObj, pkgRev)
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "Publish hook failed for package revision", "name", pkgRev.KubeObjectName())
+			continue
+		}
+		if artifact != nil {
+			artifacts = append(artifacts, *artifact)
+		}
+	}
+	return artifacts
+}
+
+const (
+	// Media types of a Flux OCI artifact, as pushed by `flux push artifact`.
+	FluxContentMediaType types.MediaType = "application/vnd.cncf.flux.content.v1.tar+gzip"
+	FluxConfigMediaType  types.MediaType = "application/vnd.cncf.flux.config.v1+json"
+
+	// Annotations Flux reads for the provenance of an artifact.
+	OCICreatedAnnotation  = "org.opencontainers.image.created"
+	OCISourceAnnotation   = "org.opencontainers.image.source"
+	OCIRevisionAnnotation = "org.opencontainers.image.revision"
+
+	// PackageRevisionAnnotation is the name of the package revision an artifact was exported from.
+	PackageRevisionAnnotation = "porch.kpt.dev/package-revision"
+	// PackageDigestAnnotation is the digest (see PackageDigest) of the package contents
+	// an artifact was exported from.
+	PackageDigestAnnotation = "porch.kpt.dev/package-digest"
+)
+
+// invalidTagChars matches the characters not allowed in an OCI tag.
+var invalidTagChars = regexp.MustCompile(`[^\w.-]`)
+
+// fluxArtifactExporter exports published package revisions as Flux OCI artifacts,
+// consumable by a Flux OCIRepository.
+type fluxArtifactExporter struct {
+	registry string
+	options  []remote.Option
+}
+
+var _ PublishHook = &fluxArtifactExporter{}
+
+// NewFluxArtifactExporter returns a PublishHook which pushes each published package
+// revision to <registry>/<repository>/<package>:<revision> as a Flux OCI artifact.
+func NewFluxArtifactExporter(registry string, options ...remote.Option) PublishHook {
+	if len(options) == 0 {
+		options = []remote.Option{remote.WithAuthFromKeychain(gcrane.Keychain)}
+	}
+	return &fluxArtifactExporter{
+		registry: strings.TrimSuffix(registry, "/"),
+		options:  options,
+	}
+}
+
+func (e *fluxArtifactExporter) OnPublish(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) (*api.Artifact, error) {
+	ctx, span := tracer.Start(ctx, "fluxArtifactExporter::OnPublish", trace.WithAttributes())
+	defer span.End()
+
+	key := pkgRev.Key()
+	tag, err := name.NewTag(path.Join(e.registry, repositoryObj.Name, key.Package) + ":" + invalidTagChars.ReplaceAllString(key.Revision, "-"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact reference for package revision %s: %w", pkgRev.KubeObjectName(), err)
+	}
+
+	resources, err := pkgRev.GetResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get package resources: %w", err)
+	}
+	contents := resources.Spec.Resources
+	tgz, err := tarGzip(contents)
+	if err != nil {
+		return nil, fmt.Errorf("cannot archive package resources: %w", err)
+	}
+
+	source, revision := artifactProvenance(ctx, repositoryObj, pkgRev)
+	img, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), static.NewLayer(tgz, FluxContentMediaType))
+	if err != nil {
+		return nil, err
+	}
+	img = mutate.ConfigMediaType(img, FluxConfigMediaType)
+	img = mutate.Annotations(img, map[string]string{
+		OCICreatedAnnotation:      time.Now().UTC().Format(time.RFC3339),
+		OCISourceAnnotation:       source,
+		OCIRevisionAnnotation:     revision,
+		PackageRevisionAnnotation: pkgRev.KubeObjectName(),
+		PackageDigestAnnotation:   PackageDigest(contents),
+	}).(v1.Image)
+
+	if err := remote.Write(tag, img, append([]remote.Option{remote.WithContext(ctx)}, e.options...)...); err != nil {
+		return nil, fmt.Errorf("cannot push artifact %s: %w", tag, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Artifact{
+		Type:   api.ArtifactTypeFluxOCI,
+		URL:    "oci://" + tag.String(),
+		Digest: digest.String(),
+	}, nil
+}
+
+// artifactProvenance returns the source and revision annotations of the artifact
+// exported from the package revision. Like Flux, the revision pins the commit,
+// formatted as <revision>@sha1:<commit>, when the package revision is in git.
+func artifactProvenance(ctx context.Context, repositoryObj *configapi.Repository, pkgRev repository.PackageRevision) (string, string) {
+	var source string
+	switch {
+	case repositoryObj.Spec.Git != nil:
+		source = repositoryObj.Spec.Git.Repo
+	case repositoryObj.Spec.Oci != nil:
+		source = repositoryObj.Spec.Oci.Registry
+	}
+	revision := pkgRev.Key().Revision
+
+	upstream, lock, err := pkgRev.GetLock()
+	if err != nil {
+		klog.FromContext(ctx).V(2).Info("Cannot determine lock of package revision", "name", pkgRev.KubeObjectName(), "err", err)
+		return source, revision
+	}
+	if upstream.Git != nil && upstream.Git.Repo != "" {
+		source = upstream.Git.Repo
+	}
+	if lock.Git != nil && lock.Git.Commit != "" {
+		revision = revision + "@sha1:" + lock.Git.Commit
+	}
+	return source, revision
+}
+
+// tarGzip returns a gzipped tarball of the package contents. Files are sorted and
+// carry no timestamps, so the same contents always produce the same layer.
+func tarGzip(contents map[string]string) ([]byte, error) {
+	var paths []string
+	for p := range contents {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, p := range paths {
+		data := []byte(contents[p])
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     p,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			ModTime:  time.Unix(0, 0),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
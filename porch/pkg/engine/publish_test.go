@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFluxArtifactExporter(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	registryHost := strings.TrimPrefix(server.URL, "http://")
+
+	contents := upstreamContents()
+	repositoryObj := &configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "blueprints"},
+		Spec: configapi.RepositorySpec{
+			Git: &configapi.GitRepository{Repo: "https://example.com/blueprints.git"},
+		},
+	}
+	pkgRev := &fake.PackageRevision{
+		Name:               "blueprints-1234",
+		Namespace:          "default",
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "bucket", Revision: "v1"},
+		PackageLifecycle:   v1alpha1.PackageRevisionLifecyclePublished,
+		Resources: &v1alpha1.PackageRevisionResources{
+			Spec: v1alpha1.PackageRevisionResourcesSpec{Resources: contents},
+		},
+		Kptfile: kptfile.KptFile{
+			Upstream: &kptfile.Upstream{
+				Type: kptfile.GitOrigin,
+				Git:  &kptfile.Git{Repo: "https://example.com/blueprints.git", Directory: "bucket", Ref: "bucket/v1"},
+			},
+			UpstreamLock: &kptfile.UpstreamLock{
+				Type: kptfile.GitOrigin,
+				Git:  &kptfile.GitLock{Repo: "https://example.com/blueprints.git", Directory: "bucket", Ref: "bucket/v1", Commit: "0123456789abcdef"},
+			},
+		},
+	}
+
+	exporter := NewFluxArtifactExporter(registryHost+"/packages/", remote.WithAuth(authn.Anonymous))
+	artifact, err := exporter.OnPublish(context.Background(), repositoryObj, pkgRev)
+	if err != nil {
+		t.Fatalf("OnPublish failed: %v", err)
+	}
+
+	ref := registryHost + "/packages/blueprints/bucket:v1"
+	if got, want := artifact.URL, "oci://"+ref; got != want {
+		t.Errorf("unexpected artifact URL: got %q, want %q", got, want)
+	}
+	if artifact.Type != v1alpha1.ArtifactTypeFluxOCI {
+		t.Errorf("unexpected artifact type %q", artifact.Type)
+	}
+
+	tag, err := name.NewTag(ref)
+	if err != nil {
+		t.Fatalf("NewTag failed: %v", err)
+	}
+	img, err := remote.Image(tag)
+	if err != nil {
+		t.Fatalf("cannot pull artifact: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("Digest failed: %v", err)
+	}
+	if got, want := artifact.Digest, digest.String(); got != want {
+		t.Errorf("unexpected artifact digest: got %q, want %q", got, want)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest failed: %v", err)
+	}
+	if manifest.MediaType != types.OCIManifestSchema1 {
+		t.Errorf("unexpected manifest media type %q", manifest.MediaType)
+	}
+	if manifest.Config.MediaType != FluxConfigMediaType {
+		t.Errorf("unexpected config media type %q", manifest.Config.MediaType)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != FluxContentMediaType {
+		t.Fatalf("expected a single %s layer, got %v", FluxContentMediaType, manifest.Layers)
+	}
+
+	annotations := manifest.Annotations
+	if annotations[OCICreatedAnnotation] == "" {
+		t.Errorf("%s annotation is missing", OCICreatedAnnotation)
+	}
+	delete(annotations, OCICreatedAnnotation)
+	if diff := cmp.Diff(map[string]string{
+		OCISourceAnnotation:       "https://example.com/blueprints.git",
+		OCIRevisionAnnotation:     "v1@sha1:0123456789abcdef",
+		PackageRevisionAnnotation: "blueprints-1234",
+		PackageDigestAnnotation:   PackageDigest(contents),
+	}, annotations); diff != "" {
+		t.Errorf("unexpected annotations (-want, +got): %s", diff)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatalf("Layers failed: %v", err)
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		t.Fatalf("Compressed failed: %v", err)
+	}
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		t.Fatalf("layer isn't gzipped: %v", err)
+	}
+	got := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("cannot read layer: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("cannot read %s: %v", hdr.Name, err)
+		}
+		got[hdr.Name] = string(data)
+	}
+	if diff := cmp.Diff(contents, got); diff != "" {
+		t.Errorf("unexpected layer contents (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PublishReadiness is the result of checking whether a package revision would pass the
+// readiness gates and validators if it were published.
+type PublishReadiness struct {
+	// Ready is true if no readiness gate or validator failed.
+	Ready bool `json:"ready"`
+	// Gates are the readiness gates which aren't met.
+	Gates []ReadinessCheck `json:"gates,omitempty"`
+	// Validators are the validators which failed.
+	Validators []ReadinessCheck `json:"validators,omitempty"`
+}
+
+// ReadinessCheck is a failed readiness gate or validator.
+type ReadinessCheck struct {
+	// Name is the condition type of a readiness gate, or the function image of a validator.
+	Name string `json:"name"`
+	// Reason describes why the check failed.
+	Reason string `json:"reason"`
+}
+
+// CheckPublishReadiness checks whether the package revision with the given name would pass
+// the checks run when it is published, without changing the package revision: its readiness
+// gates must have a true condition, a draft stored without rendering must render, and the
+// validators of the repository must pass.
+func (cad *cadEngine) CheckPublishReadiness(ctx context.Context, repositoryObj *configapi.Repository, name string) (*PublishReadiness, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::CheckPublishReadiness", trace.WithAttributes())
+	defer span.End()
+
+	rev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return nil, err
+	}
+	return cad.publishReadiness(ctx, repositoryObj, rev)
+}
+
+func (cad *cadEngine) publishReadiness(ctx context.Context, repositoryObj *configapi.Repository, pkgRev *PackageRevision) (*PublishReadiness, error) {
+	rev, err := pkgRev.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Spec.Lifecycle == api.PackageRevisionLifecyclePublished {
+		return nil, fmt.Errorf("package revision %q is already published", rev.Name)
+	}
+
+	readiness := &PublishReadiness{Gates: checkReadinessGates(rev)}
+
+	apiResources, err := pkgRev.GetResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get package resources: %w", err)
+	}
+	resources := repository.PackageResources{Contents: apiResources.Spec.Resources, FileModes: apiResources.Spec.FileModes}
+	readiness.Validators = cad.runPublishValidators(ctx, repositoryObj, resources, rev.Annotations)
+
+	readiness.Ready = len(readiness.Gates) == 0 && len(readiness.Validators) == 0
+	return readiness, nil
+}
+
+// checkReadinessGates returns the readiness gates of rev without a true condition.
+func checkReadinessGates(rev *api.PackageRevision) []ReadinessCheck {
+	conditions := map[string]api.Condition{}
+	for _, c := range rev.Status.Conditions {
+		conditions[c.Type] = c
+	}
+
+	var failed []ReadinessCheck
+	for _, gate := range rev.Spec.ReadinessGates {
+		c, found := conditions[gate.ConditionType]
+		switch {
+		case !found:
+			failed = append(failed, ReadinessCheck{Name: gate.ConditionType, Reason: "condition is not set"})
+		case c.Status != api.ConditionTrue:
+			reason := fmt.Sprintf("condition status is %s", c.Status)
+			if c.Message != "" {
+				reason += ": " + c.Message
+			}
+			failed = append(failed, ReadinessCheck{Name: gate.ConditionType, Reason: reason})
+		}
+	}
+	return failed
+}
+
+// runPublishValidators runs the validators a package is checked with before it is published
+// on its resources, and returns the ones which failed. The resources aren't stored.
+func (cad *cadEngine) runPublishValidators(ctx context.Context, repositoryObj *configapi.Repository, resources repository.PackageResources, annotations map[string]string) []ReadinessCheck {
+	var failed []ReadinessCheck
+
+	// A draft stored without rendering is rendered, running the Kptfile pipeline, before it
+	// leaves the Draft lifecycle.
+	if isRenderDeferred(resources) {
+		rendered, _, err := cad.renderMutation(annotations).Apply(ctx, resources)
+		if err != nil {
+			return append(failed, ReadinessCheck{Name: "render", Reason: err.Error()})
+		}
+		resources = rendered
+	}
+
+	for _, validator := range repositoryObj.Spec.Validators {
+		if validator.Image == "" {
+			name := "<unknown>"
+			if validator.FunctionRef != nil {
+				name = validator.FunctionRef.Name
+			}
+			failed = append(failed, ReadinessCheck{Name: name, Reason: "validators referring to Function resources are not supported"})
+			continue
+		}
+		m := &evalFunctionMutation{
+			runtime: cad.runtime,
+			task: &api.Task{
+				Type: api.TaskTypeEval,
+				Eval: &api.FunctionEvalTaskSpec{Image: validator.Image, ConfigMap: validator.ConfigMap},
+			},
+			allowedImages: cad.allowedFunctionImages,
+		}
+		if _, _, err := m.Apply(ctx, resources); err != nil {
+			failed = append(failed, ReadinessCheck{Name: validator.Image, Reason: err.Error()})
+		}
+	}
+	return failed
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckPublishReadiness(t *testing.T) {
+	const setNamespace = "gcr.io/kpt-fn/set-namespace:v0.4.1"
+
+	pkgRev := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{
+			Name:             "deployments-app-v1",
+			PackageLifecycle: api.PackageRevisionLifecycleProposed,
+			PackageRevision: &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployments-app-v1"},
+				Spec: api.PackageRevisionSpec{
+					PackageName: "app",
+					Revision:    "v1",
+					Lifecycle:   api.PackageRevisionLifecycleProposed,
+					ReadinessGates: []api.ReadinessGate{
+						{ConditionType: "tested"},
+						{ConditionType: "approved-by-qa"},
+					},
+				},
+				Status: api.PackageRevisionStatus{
+					Conditions: []api.Condition{
+						{Type: "tested", Status: api.ConditionTrue},
+						{Type: "approved-by-qa", Status: api.ConditionFalse, Message: "waiting for sign-off"},
+					},
+				},
+			},
+			Resources: &api.PackageRevisionResources{
+				Spec: api.PackageRevisionResourcesSpec{
+					Resources: map[string]string{
+						kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+						"configmap.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+					},
+				},
+			},
+		},
+	}
+	repositoryObj := &configapi.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployments", Namespace: "default"},
+		Spec: configapi.RepositorySpec{
+			Validators: []configapi.FunctionEval{
+				{Image: setNamespace, ConfigMap: map[string]string{"namespace": "prod"}},
+			},
+		},
+	}
+	unmetGate := ReadinessCheck{Name: "approved-by-qa", Reason: "condition status is False: waiting for sign-off"}
+
+	t.Run("unmet gate", func(t *testing.T) {
+		cad := &cadEngine{runtime: newBuiltinRuntime()}
+		readiness, err := cad.publishReadiness(context.Background(), repositoryObj, pkgRev)
+		if err != nil {
+			t.Fatalf("publishReadiness failed: %v", err)
+		}
+		want := &PublishReadiness{Gates: []ReadinessCheck{unmetGate}}
+		if diff := cmp.Diff(want, readiness); diff != "" {
+			t.Errorf("unexpected readiness (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("failing validator", func(t *testing.T) {
+		cad := &cadEngine{runtime: newBuiltinRuntime(), allowedFunctionImages: AllowedFunctionImages{"gcr.io/kpt-fn/starlark:*"}}
+		readiness, err := cad.publishReadiness(context.Background(), repositoryObj, pkgRev)
+		if err != nil {
+			t.Fatalf("publishReadiness failed: %v", err)
+		}
+		if readiness.Ready {
+			t.Errorf("package revision with an unmet gate and a failing validator is ready")
+		}
+		if diff := cmp.Diff([]ReadinessCheck{unmetGate}, readiness.Gates); diff != "" {
+			t.Errorf("unexpected gates (-want, +got): %s", diff)
+		}
+		if len(readiness.Validators) != 1 || readiness.Validators[0].Name != setNamespace || !strings.Contains(readiness.Validators[0].Reason, "not in the allowed function images") {
+			t.Errorf("unexpected validators: %v", readiness.Validators)
+		}
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		ready := &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+			PackageRevision: pkgRev.repoPackageRevision.(*fake.PackageRevision).PackageRevision.DeepCopy(),
+			Resources:       pkgRev.repoPackageRevision.(*fake.PackageRevision).Resources,
+		}}
+		ready.repoPackageRevision.(*fake.PackageRevision).PackageRevision.Status.Conditions[1].Status = api.ConditionTrue
+
+		cad := &cadEngine{runtime: newBuiltinRuntime()}
+		readiness, err := cad.publishReadiness(context.Background(), repositoryObj, ready)
+		if err != nil {
+			t.Fatalf("publishReadiness failed: %v", err)
+		}
+		if diff := cmp.Diff(&PublishReadiness{Ready: true}, readiness); diff != "" {
+			t.Errorf("unexpected readiness (-want, +got): %s", diff)
+		}
+	})
+}
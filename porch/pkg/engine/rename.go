@@ -0,0 +1,214 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// RenameFile renames the file from of the draft package revision name to to. The rename
+// is recorded as a patch task renaming the file, rather than deleting and creating it,
+// and the function configs of the Kptfile pipelines referring to the file are updated.
+func (cad *cadEngine) RenameFile(ctx context.Context, repositoryObj *configapi.Repository, name, from, to string) (*PackageRevision, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::RenameFile", trace.WithAttributes())
+	defer span.End()
+
+	pkgRev, err := cad.findPackageRevision(ctx, repositoryObj, name)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withPackageLogger(ctx, pkgRev.repoPackageRevision.Key())
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	return cad.renameFile(ctx, repo, pkgRev, from, to)
+}
+
+func (cad *cadEngine) renameFile(ctx context.Context, repo repository.Repository, pkgRev *PackageRevision, from, to string) (*PackageRevision, error) {
+	if lifecycle := pkgRev.repoPackageRevision.Lifecycle(); lifecycle != api.PackageRevisionLifecycleDraft {
+		return nil, notDraftConflict(pkgRev.KubeObjectName(), lifecycle, "rename files of")
+	}
+
+	apiResources, err := pkgRev.GetResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get package resources: %w", err)
+	}
+	resources := repository.PackageResources{Contents: apiResources.Spec.Resources, FileModes: apiResources.Spec.FileModes}
+	task, err := renameFileTask(resources, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := repo.UpdatePackageRevision(ctx, pkgRev.repoPackageRevision)
+	if err != nil {
+		return nil, err
+	}
+	draft = cad.bufferDraft(draft)
+	mutations := []mutation{&applyPatchMutation{patchTask: task.Patch, task: task}}
+	if err := cad.applyResourceMutations(ctx, draft, resources, mutations); err != nil {
+		return nil, err
+	}
+	repoPkgRev, err := cad.closeDraft(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	return &PackageRevision{
+		repoPackageRevision: repoPkgRev,
+		packageRevisionMeta: pkgRev.packageRevisionMeta,
+	}, nil
+}
+
+// renameFileTask returns the patch task renaming the file from of resources to to, and
+// updating the references to the file in the Kptfile pipelines.
+func renameFileTask(resources repository.PackageResources, from, to string) (*api.Task, error) {
+	for _, p := range []string{from, to} {
+		if err := validateResourcePath(p); err != nil {
+			return nil, err
+		}
+	}
+	if _, found := resources.Contents[from]; !found {
+		return nil, fmt.Errorf("file %q not found", from)
+	}
+	if _, found := resources.Contents[to]; found {
+		return nil, fmt.Errorf("cannot rename %q to %q: file already exists", from, to)
+	}
+	if path.Base(from) == kptfile.KptFileName || path.Base(to) == kptfile.KptFileName {
+		return nil, fmt.Errorf("cannot rename %q to %q: Kptfiles can't be renamed", from, to)
+	}
+
+	patch := &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+		File:      from,
+		PatchType: api.PatchTypePatchFile,
+		Contents:  renamePatch(from, to),
+	}}}
+
+	var kptfiles []string
+	for name := range resources.Contents {
+		if path.Base(name) == kptfile.KptFileName {
+			kptfiles = append(kptfiles, name)
+		}
+	}
+	sort.Strings(kptfiles)
+	for _, name := range kptfiles {
+		old := resources.Contents[name]
+		updated, err := renameConfigPaths(name, old, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if updated == old {
+			continue
+		}
+		patchSpec, err := GeneratePatch(name, old, updated)
+		if err != nil {
+			return nil, fmt.Errorf("error generating patch: %w", err)
+		}
+		patch.Patches = append(patch.Patches, patchSpec)
+	}
+
+	return &api.Task{Type: api.TaskTypePatch, Patch: patch}, nil
+}
+
+// renamePatch returns a git diff renaming the file from to to without changing it.
+func renamePatch(from, to string) string {
+	return fmt.Sprintf("diff --git a/%s b/%s\nsimilarity index 100%%\nrename from %s\nrename to %s\n", from, to, from, to)
+}
+
+// validateResourcePath checks that p is a clean path relative to the package root.
+func validateResourcePath(p string) error {
+	if p == "" || path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") || strings.ContainsAny(p, "\n\t") {
+		return fmt.Errorf("invalid file path %q", p)
+	}
+	return nil
+}
+
+// renameConfigPaths returns the Kptfile name, with contents, with the function configs
+// of its pipeline at from moved to to.
+func renameConfigPaths(name, contents, from, to string) (string, error) {
+	dir := path.Dir(name)
+	relative := func(p string) (string, bool) {
+		if dir == "." {
+			return p, true
+		}
+		if !strings.HasPrefix(p, dir+"/") {
+			return "", false
+		}
+		return strings.TrimPrefix(p, dir+"/"), true
+	}
+	fromPath, found := relative(from)
+	if !found {
+		return contents, nil
+	}
+
+	kf, err := yaml.Parse(contents)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse %s: %w", name, err)
+	}
+	changed := false
+	for _, field := range []string{"mutators", "validators"} {
+		functions, err := kf.Pipe(yaml.Lookup("pipeline", field))
+		if err != nil {
+			return "", fmt.Errorf("cannot read pipeline of %s: %w", name, err)
+		}
+		if functions == nil {
+			continue
+		}
+		elements, err := functions.Elements()
+		if err != nil {
+			return "", fmt.Errorf("cannot read pipeline of %s: %w", name, err)
+		}
+		for _, function := range elements {
+			configPath := function.Field("configPath")
+			if configPath == nil || path.Clean(yaml.GetValue(configPath.Value)) != fromPath {
+				continue
+			}
+			toPath, found := relative(to)
+			if !found {
+				return "", fmt.Errorf("cannot rename %q to %q: it is a function config of the pipeline of %s, which can't refer to files outside of its package", from, to, name)
+			}
+			// The value is changed in place to keep its comments.
+			configPath.Value.YNode().Value = toPath
+			changed = true
+		}
+	}
+	if !changed {
+		return contents, nil
+	}
+	// The Kptfile keeps the indentation of its sequences, so the patch only changes the
+	// function configs.
+	var out bytes.Buffer
+	encoder := yaml.NewEncoderWithOptions(&out, &yaml.EncoderOptions{
+		SeqIndent: yaml.SequenceIndentStyle(yaml.DeriveSeqIndentStyle(contents)),
+	})
+	if err := encoder.Encode(kf.Document()); err != nil {
+		return "", fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func TestRenameFile(t *testing.T) {
+	const deployment = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n"
+	newPackage := func() *PackageRevision {
+		return &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+			Name:             "blueprints-1234",
+			Namespace:        "default",
+			PackageLifecycle: api.PackageRevisionLifecycleDraft,
+			Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+				kptfile.KptFileName: `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+pipeline:
+  mutators:
+    - image: gcr.io/kpt-fn/set-labels:v0.1.5
+      configPath: deploy.yaml # the labels
+`,
+				"deploy.yaml":  deployment,
+				"service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n",
+			}}},
+		}}
+	}
+
+	t.Run("renamed", func(t *testing.T) {
+		repo := &countingRepository{}
+		cad := &cadEngine{}
+		if _, err := cad.renameFile(context.Background(), repo, newPackage(), "deploy.yaml", "deployment.yaml"); err != nil {
+			t.Fatalf("renameFile failed: %v", err)
+		}
+
+		if _, found := repo.draft.resources["deploy.yaml"]; found {
+			t.Errorf("renamed file wasn't removed")
+		}
+		if got := repo.draft.resources["deployment.yaml"]; got != deployment {
+			t.Errorf("unexpected contents of renamed file: %q", got)
+		}
+		if got := repo.draft.resources[kptfile.KptFileName]; !strings.Contains(got, "configPath: deployment.yaml # the labels\n") {
+			t.Errorf("function config wasn't renamed in the Kptfile:\n%s", got)
+		}
+
+		// The history records one patch task renaming the file.
+		if len(repo.draft.tasks) != 1 {
+			t.Fatalf("unexpected commits: %v", repo.draft.tasks)
+		}
+		patches := repo.draft.tasks[0].Patch.Patches
+		if len(patches) != 2 {
+			t.Fatalf("unexpected patches: %v", patches)
+		}
+		if rename := patches[0]; rename.File != "deploy.yaml" || rename.PatchType != api.PatchTypePatchFile || !strings.Contains(rename.Contents, "rename to deployment.yaml\n") {
+			t.Errorf("rename isn't recorded as a rename: %+v", rename)
+		}
+		for _, patch := range patches {
+			if patch.PatchType == api.PatchTypeCreateFile || patch.PatchType == api.PatchTypeDeleteFile {
+				t.Errorf("rename recorded as %s of %s", patch.PatchType, patch.File)
+			}
+		}
+
+		// The recorded task replays the rename.
+		replayed, _, err := (&applyPatchMutation{patchTask: repo.draft.tasks[0].Patch}).Apply(context.Background(),
+			repository.PackageResources{Contents: newPackage().repoPackageRevision.(*fake.PackageRevision).Resources.Spec.Resources})
+		if err != nil {
+			t.Fatalf("cannot replay rename: %v", err)
+		}
+		if replayed.Contents["deployment.yaml"] != deployment {
+			t.Errorf("replayed rename didn't move the file: %v", replayed.Contents)
+		}
+	})
+
+	for _, tc := range []struct {
+		name     string
+		from, to string
+	}{
+		{name: "target exists", from: "deploy.yaml", to: "service.yaml"},
+		{name: "source missing", from: "missing.yaml", to: "deployment.yaml"},
+		{name: "outside package", from: "deploy.yaml", to: "../deployment.yaml"},
+		{name: "Kptfile", from: kptfile.KptFileName, to: "Kptfile.yaml"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &countingRepository{}
+			cad := &cadEngine{}
+			if _, err := cad.renameFile(context.Background(), repo, newPackage(), tc.from, tc.to); err == nil {
+				t.Errorf("renaming %q to %q succeeded", tc.from, tc.to)
+			}
+			if repo.draft != nil {
+				t.Errorf("draft was opened for an invalid rename")
+			}
+		})
+	}
+}
@@ -16,10 +16,13 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	iofs "io/fs"
 	"path"
 	"strings"
 
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	"github.com/GoogleContainerTools/kpt/pkg/fn"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
@@ -31,6 +34,43 @@ import (
 type renderPackageMutation struct {
 	renderer fn.Renderer
 	runtime  fn.FunctionRuntime
+
+	// maxConcurrency bounds the number of subpackage pipelines rendered concurrently.
+	maxConcurrency int
+
+	// recordChanges enables collecting which function changed which resources.
+	// The report of the last Apply is available in changes.
+	recordChanges bool
+	changes       *RenderChangeReport
+
+	// allowedImages, if set, restricts the functions the Kptfile pipelines can run.
+	allowedImages AllowedFunctionImages
+
+	// skipWithoutPipeline skips packages whose Kptfiles declare no functions. A skipped
+	// render returns no task, so nothing is committed for it.
+	skipWithoutPipeline bool
+
+	// pipelineAppend is the value of the api.PipelineAppendAnnotation annotation of the
+	// package revision: functions run after the mutators of the root Kptfile pipeline.
+	pipelineAppend string
+
+	// recordTaskChanges is set when the render task requested recording the changes, and
+	// is kept in the recorded task.
+	recordTaskChanges bool
+}
+
+// legacyRenderImage is the image of the eval tasks which rendered the package before
+// there was a render task type.
+const legacyRenderImage = "render"
+
+// isLegacyRenderTask returns true if the task is an eval task rendering the package.
+func isLegacyRenderTask(task *api.Task) bool {
+	return task.Type == api.TaskTypeEval && task.Eval != nil && task.Eval.Image == legacyRenderImage
+}
+
+// isRenderTask returns true if the task renders the package.
+func isRenderTask(task *api.Task) bool {
+	return task.Type == api.TaskTypeRender || isLegacyRenderTask(task)
 }
 
 var _ mutation = &renderPackageMutation{}
@@ -39,7 +79,36 @@ func (m *renderPackageMutation) Apply(ctx context.Context, resources repository.
 	ctx, span := tracer.Start(ctx, "renderPackageMutation::Apply", trace.WithAttributes())
 	defer span.End()
 
+	if err := m.allowedImages.checkPipelines(ctx, resources); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+
+	appended, err := parsePipelineAppend(m.pipelineAppend)
+	if err != nil {
+		return repository.PackageResources{}, nil, fmt.Errorf("invalid %s annotation: %w", api.PipelineAppendAnnotation, err)
+	}
+	if err := m.allowedImages.checkFunctions(ctx, appended, "the "+api.PipelineAppendAnnotation+" annotation"); err != nil {
+		return repository.PackageResources{}, nil, err
+	}
+	if _, found := resources.Contents[kptfile.KptFileName]; !found {
+		// Without a root Kptfile there's no pipeline to append to.
+		appended = nil
+	}
+	var overlay *appendedPipeline
+	if len(appended) > 0 {
+		klog.FromContext(ctx).V(2).Info("Appending functions to the pipeline of the package", "images", functionImages(appended))
+		if resources, overlay, err = appendPipeline(resources, appended); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+	}
+
+	if m.skipWithoutPipeline && !hasPipeline(resources) {
+		klog.FromContext(ctx).V(2).Info("Skipping render as the package has no pipeline")
+		return resources, nil, nil
+	}
+
 	fs := filesys.MakeFsInMemory()
+	results := &functionResults{}
 
 	pkgPath, err := writeResources(fs, resources)
 	if err != nil {
@@ -49,14 +118,24 @@ func (m *renderPackageMutation) Apply(ctx context.Context, resources repository.
 	if pkgPath == "" {
 		// We need this for the no-resources case
 		// TODO: we should handle this better
-		klog.Warningf("skipping render as no package was found")
+		klog.FromContext(ctx).Info("Skipping render as no package was found")
 	} else {
+		var runtime fn.FunctionRuntime = &cancellableRuntime{runtime: &tracingRuntime{runtime: m.runtime}}
+		runtime = &resultRecordingRuntime{runtime: runtime, results: results}
+		if m.recordChanges {
+			m.changes = &RenderChangeReport{}
+			runtime = &changeRecordingRuntime{runtime: runtime, report: m.changes}
+		}
 		if err := m.renderer.Render(ctx, fs, fn.RenderOptions{
-			PkgPath: pkgPath,
-			Runtime: m.runtime,
+			PkgPath:        pkgPath,
+			Runtime:        runtime,
+			MaxConcurrency: m.maxConcurrency,
 		}); err != nil {
 			return repository.PackageResources{}, nil, err
 		}
+		if m.changes != nil {
+			klog.FromContext(ctx).Info("Render of package changed resources", "path", pkgPath, "changes", m.changes.String())
+		}
 	}
 
 	result, err := readResources(fs)
@@ -64,14 +143,57 @@ func (m *renderPackageMutation) Apply(ctx context.Context, resources repository.
 		return repository.PackageResources{}, nil, err
 	}
 
-	// TODO: There are internal tasks not represented in the API; Update the Apply interface to enable them.
-	return result, &api.Task{
-		Type: "eval",
-		Eval: &api.FunctionEvalTaskSpec{
-			Image:     "render",
-			ConfigMap: nil,
-		},
-	}, nil
+	task := &api.Task{Type: api.TaskTypeRender}
+	if m.recordTaskChanges {
+		task.Render = &api.PackageRenderTaskSpec{RecordChanges: true}
+	}
+	if overlay != nil {
+		if result, err = overlay.restore(result); err != nil {
+			return repository.PackageResources{}, nil, err
+		}
+		// Record the overlay, since the functions it ran aren't in the package.
+		if task.Render == nil {
+			task.Render = &api.PackageRenderTaskSpec{}
+		}
+		for _, function := range appended {
+			task.Render.AppendedFunctions = append(task.Render.AppendedFunctions, function.Image)
+		}
+	}
+	// The results are stored in the task and in the status of the package revision.
+	if recorded := redactFunctionResults(ctx, results.list(), nil); len(recorded) > 0 {
+		captureFunctionResults(ctx, recorded)
+		// Record the results of the functions, which aren't kept in the package.
+		if task.Render == nil {
+			task.Render = &api.PackageRenderTaskSpec{}
+		}
+		task.Render.Results = recorded
+	}
+	if changes := m.changes.apiChanges(); len(changes) > 0 {
+		// Record the changes of the functions, so that clients can see them.
+		if task.Render == nil {
+			task.Render = &api.PackageRenderTaskSpec{}
+		}
+		task.Render.Changes = changes
+	}
+	return result, task, nil
+}
+
+// hasPipeline returns true if a Kptfile of the package or its subpackages declares
+// pipeline functions. Kptfiles which can't be read are left for render to report.
+func hasPipeline(resources repository.PackageResources) bool {
+	for name, contents := range resources.Contents {
+		if path.Base(name) != kptfile.KptFileName {
+			continue
+		}
+		kf, err := internalpkg.DecodeKptfile(strings.NewReader(contents))
+		if err != nil {
+			return true
+		}
+		if kf.Pipeline != nil && (len(kf.Pipeline.Mutators) != 0 || len(kf.Pipeline.Validators) != 0) {
+			return true
+		}
+	}
+	return false
 }
 
 // TODO: Implement filesystem abstraction directly rather than on top of PackageResources
@@ -16,12 +16,19 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
 	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/google/go-cmp/cmp"
@@ -79,3 +86,236 @@ func TestRender(t *testing.T) {
 		t.Errorf("Unexpected result (-want, +got): %s", diff)
 	}
 }
+
+// subpackagesFixture returns a package with a pipeline and the given number of
+// subpackages, each with its own pipeline. The first subpackage has a nested
+// subpackage of its own.
+func subpackagesFixture(subpackages int) repository.PackageResources {
+	kptfile := func(name string) string {
+		return fmt.Sprintf(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: %s
+pipeline:
+  mutators:
+    - image: gcr.io/kpt-fn/set-labels:v0.1.5
+      configMap:
+        %s: rendered
+`, name, name)
+	}
+	configMap := func(name string) string {
+		return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+data:
+  key: value
+`, name)
+	}
+
+	contents := map[string]string{
+		"Kptfile":        kptfile("root"),
+		"configmap.yaml": configMap("root"),
+	}
+	for i := 0; i < subpackages; i++ {
+		name := fmt.Sprintf("sub-%02d", i)
+		contents[name+"/Kptfile"] = kptfile(name)
+		contents[name+"/configmap.yaml"] = configMap(name)
+	}
+	if subpackages > 0 {
+		contents["sub-00/nested/Kptfile"] = kptfile("nested")
+		contents["sub-00/nested/configmap.yaml"] = configMap("nested")
+	}
+	return repository.PackageResources{Contents: contents}
+}
+
+func TestRenderConcurrentSubpackages(t *testing.T) {
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+
+	render := func(maxConcurrency int) repository.PackageResources {
+		m := &renderPackageMutation{
+			renderer:       kpt.NewRenderer(runnerOptions),
+			runtime:        kpt.NewSimpleFunctionRuntime(),
+			maxConcurrency: maxConcurrency,
+		}
+		rendered, _, err := m.Apply(context.Background(), subpackagesFixture(20))
+		if err != nil {
+			t.Fatalf("package render with concurrency %d failed: %v", maxConcurrency, err)
+		}
+		return rendered
+	}
+
+	sequential := render(0)
+	if got, want := sequential.Contents["sub-00/nested/configmap.yaml"], "root: rendered"; !strings.Contains(got, want) {
+		t.Errorf("root pipeline was not applied to nested subpackage; got:\n%s", got)
+	}
+	for _, maxConcurrency := range []int{2, 4, 32} {
+		if diff := cmp.Diff(sequential, render(maxConcurrency)); diff != "" {
+			t.Errorf("concurrent render (%d) differs from sequential render (-want, +got): %s", maxConcurrency, diff)
+		}
+	}
+}
+
+// slowRuntime simulates function runtimes whose functions take a while to
+// execute, such as those running functions in pods.
+type slowRuntime struct {
+	fn.FunctionRuntime
+	delay time.Duration
+}
+
+func (r *slowRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	runner, err := r.FunctionRuntime.GetRunner(ctx, function)
+	if err != nil {
+		return nil, err
+	}
+	return &slowRunner{FunctionRunner: runner, delay: r.delay}, nil
+}
+
+type slowRunner struct {
+	fn.FunctionRunner
+	delay time.Duration
+}
+
+func (r *slowRunner) Run(in io.Reader, out io.Writer) error {
+	time.Sleep(r.delay)
+	return r.FunctionRunner.Run(in, out)
+}
+
+func BenchmarkRenderSubpackages(b *testing.B) {
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+
+	resources := subpackagesFixture(20)
+	for _, maxConcurrency := range []int{0, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency-%d", maxConcurrency), func(b *testing.B) {
+			m := &renderPackageMutation{
+				renderer:       kpt.NewRenderer(runnerOptions),
+				runtime:        &slowRuntime{FunctionRuntime: kpt.NewSimpleFunctionRuntime(), delay: 5 * time.Millisecond},
+				maxConcurrency: maxConcurrency,
+			}
+			for i := 0; i < b.N; i++ {
+				if _, _, err := m.Apply(context.Background(), resources); err != nil {
+					b.Fatalf("package render failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestImplicitRenderWithoutPipeline(t *testing.T) {
+	newObj := func() *api.PackageRevision {
+		return &api.PackageRevision{
+			Spec: api.PackageRevisionSpec{
+				PackageName: "app",
+				Tasks: []api.Task{
+					{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}},
+					{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+						File:      "configmap.yaml",
+						PatchType: api.PatchTypeCreateFile,
+						Contents:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+					}}}},
+				},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		forceRender bool
+		wantRenders int
+	}{
+		{name: "skipped", forceRender: false, wantRenders: 0},
+		{name: "forced", forceRender: true, wantRenders: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer := &countingRenderer{}
+			cad := &cadEngine{renderer: renderer, forceRender: tc.forceRender}
+			draft := &recordingDraft{}
+			if err := cad.applyTasks(context.Background(), draft, &configapi.Repository{}, newObj(), nil); err != nil {
+				t.Fatalf("applyTasks failed: %v", err)
+			}
+
+			if renderer.renders != tc.wantRenders {
+				t.Errorf("unexpected number of renders: got %d, want %d", renderer.renders, tc.wantRenders)
+			}
+			if got, want := len(draft.tasks), 2+tc.wantRenders; got != want {
+				t.Errorf("unexpected number of commits: got %d, want %d", got, want)
+			}
+			for _, name := range []string{v1.KptFileName, "configmap.yaml"} {
+				if _, found := draft.resources[name]; !found {
+					t.Errorf("%s isn't committed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTask(t *testing.T) {
+	tasks := func(last ...api.Task) []api.Task {
+		return append([]api.Task{
+			{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}},
+			{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+				File:      "configmap.yaml",
+				PatchType: api.PatchTypeCreateFile,
+				Contents:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+			}}}},
+		}, last...)
+	}
+
+	for name, tc := range map[string]struct {
+		tasks []api.Task
+		want  api.Task
+	}{
+		"render task": {
+			tasks: tasks(api.Task{Type: api.TaskTypeRender, Render: &api.PackageRenderTaskSpec{RecordChanges: true}}),
+			want:  api.Task{Type: api.TaskTypeRender, Render: &api.PackageRenderTaskSpec{RecordChanges: true}},
+		},
+		"eval task of the render image": {
+			tasks: tasks(api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: "render"}}),
+			want:  api.Task{Type: api.TaskTypeRender},
+		},
+		"implicit render": {
+			tasks: tasks(),
+			want:  api.Task{Type: api.TaskTypeRender},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			renderer := &countingRenderer{}
+			cad := &cadEngine{renderer: renderer, forceRender: true}
+			draft := &recordingDraft{}
+			obj := &api.PackageRevision{Spec: api.PackageRevisionSpec{PackageName: "app", Tasks: tc.tasks}}
+			if err := cad.applyTasks(context.Background(), draft, &configapi.Repository{}, obj, nil); err != nil {
+				t.Fatalf("applyTasks failed: %v", err)
+			}
+
+			if renderer.renders != 1 {
+				t.Errorf("unexpected number of renders: got %d, want 1", renderer.renders)
+			}
+			if got, want := len(draft.tasks), 3; got != want {
+				t.Fatalf("unexpected number of commits: got %d, want %d", got, want)
+			}
+			if diff := cmp.Diff(tc.want, draft.tasks[2]); diff != "" {
+				t.Errorf("unexpected recorded render task (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestHasPipeline(t *testing.T) {
+	const noPipeline = "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n"
+	if hasPipeline(repository.PackageResources{Contents: map[string]string{v1.KptFileName: noPipeline}}) {
+		t.Errorf("package without pipeline has a pipeline")
+	}
+	if !hasPipeline(subpackagesFixture(1)) {
+		t.Errorf("package with pipeline has no pipeline")
+	}
+	// A pipeline of a subpackage is rendered too.
+	withSubpackage := repository.PackageResources{Contents: map[string]string{
+		v1.KptFileName:          noPipeline,
+		"sub/" + v1.KptFileName: subpackagesFixture(0).Contents[v1.KptFileName],
+	}}
+	if !hasPipeline(withSubpackage) {
+		t.Errorf("pipeline of subpackage isn't found")
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type ResourceChangeType string
+
+const (
+	ResourceCreated  ResourceChangeType = "Created"
+	ResourceModified ResourceChangeType = "Modified"
+	ResourceDeleted  ResourceChangeType = "Deleted"
+)
+
+// ResourceChange records that a resource changed, for example by a function of the
+// render pipeline.
+type ResourceChange struct {
+	// Function is the image (or exec path) of the function which made the change, if any.
+	Function string
+	// Resource identifies the resource as apiVersion/kind/namespace/name.
+	Resource string
+	Type     ResourceChangeType
+}
+
+// RenderChangeReport lists, in pipeline order, the changes each function made during a render.
+type RenderChangeReport struct {
+	mu      sync.Mutex
+	Changes []ResourceChange
+}
+
+func (r *RenderChangeReport) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "%s: %s %s\n", c.Function, strings.ToLower(string(c.Type)), c.Resource)
+	}
+	return b.String()
+}
+
+// apiChanges returns the changes of the report, as recorded in the render task.
+func (r *RenderChangeReport) apiChanges() []api.RenderChange {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []api.RenderChange
+	for _, c := range r.Changes {
+		changes = append(changes, api.RenderChange{Function: c.Function, Resource: c.Resource, Type: string(c.Type)})
+	}
+	return changes
+}
+
+func (r *RenderChangeReport) add(changes []ResourceChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Changes = append(r.Changes, changes...)
+}
+
+// changeRecordingRuntime wraps a function runtime and records the changes each
+// function run makes to the resources passing through it.
+type changeRecordingRuntime struct {
+	runtime fn.FunctionRuntime
+	report  *RenderChangeReport
+}
+
+var _ fn.FunctionRuntime = &changeRecordingRuntime{}
+
+func (r *changeRecordingRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	runner, err := r.runtime.GetRunner(ctx, function)
+	if err != nil || runner == nil {
+		return runner, err
+	}
+	name := function.Image
+	if name == "" {
+		name = function.Exec
+	}
+	return &changeRecordingRunner{
+		runner:   runner,
+		function: name,
+		report:   r.report,
+	}, nil
+}
+
+type changeRecordingRunner struct {
+	runner   fn.FunctionRunner
+	function string
+	report   *RenderChangeReport
+}
+
+var _ fn.FunctionRunner = &changeRecordingRunner{}
+
+func (r *changeRecordingRunner) Run(in io.Reader, out io.Writer) error {
+	var input, output bytes.Buffer
+	if err := r.runner.Run(io.TeeReader(in, &input), io.MultiWriter(out, &output)); err != nil {
+		return err
+	}
+
+	changes, err := diffResourceLists(r.function, input.Bytes(), output.Bytes())
+	if err != nil {
+		// The report is diagnostic only; it must not fail the render.
+		return nil
+	}
+	r.report.add(changes)
+	return nil
+}
+
+// annotations added by kpt and kio while a pipeline is running, which are not
+// changes made by the function itself.
+var bookkeepingAnnotations = []string{
+	kioutil.IndexAnnotation,
+	kioutil.PathAnnotation,
+	kioutil.IdAnnotation,
+	kioutil.LegacyIndexAnnotation,
+	kioutil.LegacyPathAnnotation,
+	kioutil.LegacyIdAnnotation,
+	"config.k8s.io/id",
+	"internal.config.kubernetes.io/annotations-migration-resource-id",
+}
+
+func diffResourceLists(function string, input, output []byte) ([]ResourceChange, error) {
+	before, err := indexResourceList(input)
+	if err != nil {
+		return nil, err
+	}
+	after, err := indexResourceList(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ResourceChange
+	for id, a := range after {
+		b, found := before[id]
+		switch {
+		case !found:
+			changes = append(changes, ResourceChange{Function: function, Resource: id, Type: ResourceCreated})
+		case a != b:
+			changes = append(changes, ResourceChange{Function: function, Resource: id, Type: ResourceModified})
+		}
+	}
+	for id := range before {
+		if _, found := after[id]; !found {
+			changes = append(changes, ResourceChange{Function: function, Resource: id, Type: ResourceDeleted})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Resource < changes[j].Resource
+	})
+	return changes, nil
+}
+
+// indexResourceList returns the items of a wire format ResourceList, keyed by
+// resource identity, with the bookkeeping annotations removed.
+func indexResourceList(data []byte) (map[string]string, error) {
+	items, err := (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]string{}
+	for _, item := range items {
+		id := strings.Join([]string{item.GetApiVersion(), item.GetKind(), item.GetNamespace(), item.GetName()}, "/")
+
+		node := item.Copy()
+		for _, a := range bookkeepingAnnotations {
+			if _, err := node.Pipe(yaml.ClearAnnotation(a)); err != nil {
+				return nil, err
+			}
+		}
+		s, err := node.String()
+		if err != nil {
+			return nil, err
+		}
+		index[id] = s
+	}
+	return index, nil
+}
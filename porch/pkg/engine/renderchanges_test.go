@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type filterRuntime map[string]kio.FilterFunc
+
+func (r filterRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	filter, ok := r[function.Image]
+	if !ok {
+		return nil, &fn.NotFoundError{Function: *function}
+	}
+	return &filterRunner{filter: filter}, nil
+}
+
+type filterRunner struct {
+	filter kio.FilterFunc
+}
+
+func (r *filterRunner) Run(in io.Reader, out io.Writer) error {
+	rw := &kio.ByteReadWriter{Reader: in, Writer: out, KeepReaderAnnotations: true}
+	return kio.Pipeline{Inputs: []kio.Reader{rw}, Filters: []kio.Filter{r.filter}, Outputs: []kio.Writer{rw}}.Execute()
+}
+
+func TestRenderChangeReport(t *testing.T) {
+	runtime := filterRuntime{
+		"example.com/annotate-deployments": func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				if n.GetKind() != "Deployment" {
+					continue
+				}
+				if err := n.PipeE(yaml.SetAnnotation("example.com/annotated", "true")); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		},
+		"example.com/generate-configmap": func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			cm, err := yaml.Parse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: generated\n  namespace: default\n  annotations:\n    config.kubernetes.io/path: generated.yaml\ndata:\n  key: value\n")
+			if err != nil {
+				return nil, err
+			}
+			return append(nodes, cm), nil
+		},
+	}
+
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+
+	render := &renderPackageMutation{
+		renderer:      kpt.NewRenderer(runnerOptions),
+		runtime:       runtime,
+		recordChanges: true,
+	}
+
+	resources := repository.PackageResources{
+		Contents: map[string]string{
+			"Kptfile": `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+pipeline:
+  mutators:
+  - image: example.com/annotate-deployments
+  - image: example.com/generate-configmap
+`,
+			"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+`,
+			"configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  key: value
+`,
+		},
+	}
+
+	rendered, task, err := render.Apply(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if _, found := rendered.Contents["generated.yaml"]; !found {
+		t.Errorf("Cannot find generated.yaml in rendered output %v", rendered.Contents)
+	}
+
+	want := []ResourceChange{
+		{Function: "example.com/annotate-deployments", Resource: "apps/v1/Deployment/default/app", Type: ResourceModified},
+		{Function: "example.com/generate-configmap", Resource: "v1/ConfigMap/default/generated", Type: ResourceCreated},
+	}
+	if diff := cmp.Diff(want, render.changes.Changes, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Unexpected change report (-want, +got): %s", diff)
+	}
+
+	wantTask := []api.RenderChange{
+		{Function: "example.com/annotate-deployments", Resource: "apps/v1/Deployment/default/app", Type: "Modified"},
+		{Function: "example.com/generate-configmap", Resource: "v1/ConfigMap/default/generated", Type: "Created"},
+	}
+	if task.Render == nil {
+		t.Fatalf("Render task %v doesn't record the changes", task)
+	}
+	if diff := cmp.Diff(wantTask, task.Render.Changes); diff != "" {
+		t.Errorf("Unexpected changes of the render task (-want, +got): %s", diff)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// functionResults collects, in pipeline order, the results the functions of a render
+// reported.
+type functionResults struct {
+	mu      sync.Mutex
+	results []api.FunctionResult
+}
+
+func (r *functionResults) add(results []api.FunctionResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, results...)
+}
+
+func (r *functionResults) list() []api.FunctionResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]api.FunctionResult(nil), r.results...)
+}
+
+// resultRecordingRuntime wraps a function runtime and records the results each
+// function run reports in its output ResourceList.
+type resultRecordingRuntime struct {
+	runtime fn.FunctionRuntime
+	results *functionResults
+}
+
+var _ fn.FunctionRuntime = &resultRecordingRuntime{}
+
+func (r *resultRecordingRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	runner, err := r.runtime.GetRunner(ctx, function)
+	if err != nil || runner == nil {
+		return runner, err
+	}
+	name := function.Image
+	if name == "" {
+		name = function.Exec
+	}
+	return &resultRecordingRunner{
+		runner:   runner,
+		function: name,
+		results:  r.results,
+	}, nil
+}
+
+type resultRecordingRunner struct {
+	runner   fn.FunctionRunner
+	function string
+	results  *functionResults
+}
+
+var _ fn.FunctionRunner = &resultRecordingRunner{}
+
+func (r *resultRecordingRunner) Run(in io.Reader, out io.Writer) error {
+	var output bytes.Buffer
+	err := r.runner.Run(in, io.MultiWriter(out, &output))
+
+	// Functions report results when they fail too, for example validation failures.
+	results, parseErr := parseFunctionResults(r.function, output.Bytes())
+	if parseErr == nil {
+		// The results are diagnostic only; they must not fail the render.
+		r.results.add(results)
+	}
+	return err
+}
+
+// parseFunctionResults returns the results of the wire format ResourceList output by
+// the function.
+func parseFunctionResults(function string, output []byte) ([]api.FunctionResult, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var resourceList struct {
+		Results framework.Results `yaml:"results,omitempty"`
+	}
+	if err := yaml.Unmarshal(output, &resourceList); err != nil {
+		return nil, err
+	}
+
+	var results []api.FunctionResult
+	for _, result := range resourceList.Results {
+		if result == nil {
+			continue
+		}
+		r := api.FunctionResult{
+			Function: function,
+			Message:  result.Message,
+			Severity: string(result.Severity),
+		}
+		if ref := result.ResourceRef; ref != nil {
+			r.Resource = strings.Join([]string{ref.APIVersion, ref.Kind, ref.Namespace, ref.Name}, "/")
+		}
+		if result.Field != nil {
+			r.Field = result.Field.Path
+		}
+		if result.File != nil {
+			r.File = result.File.Path
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// resultsRuntime runs functions which pass the resources through unchanged and report
+// the results, keyed by function image.
+type resultsRuntime map[string]string
+
+func (r resultsRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	results, ok := r[function.Image]
+	if !ok {
+		return nil, &fn.NotFoundError{Function: *function}
+	}
+	return &resultsRunner{results: results}, nil
+}
+
+type resultsRunner struct {
+	results string
+}
+
+func (r *resultsRunner) Run(in io.Reader, out io.Writer) error {
+	rw := &kio.ByteReadWriter{Reader: in, Writer: out, KeepReaderAnnotations: true}
+	nodes, err := rw.Read()
+	if err != nil {
+		return err
+	}
+	if r.results != "" {
+		if rw.Results, err = yaml.Parse(r.results); err != nil {
+			return err
+		}
+	}
+	return rw.Write(nodes)
+}
+
+func TestRenderFunctionResults(t *testing.T) {
+	runtime := resultsRuntime{
+		"example.com/check-replicas": `
+- message: replicas should be at least 2
+  severity: warning
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: app
+    namespace: default
+  field:
+    path: spec.replicas
+  file:
+    path: deployment.yaml
+`,
+		"example.com/quiet": "",
+	}
+
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+
+	render := &renderPackageMutation{
+		renderer: kpt.NewRenderer(runnerOptions),
+		runtime:  runtime,
+	}
+
+	resources := repository.PackageResources{
+		Contents: map[string]string{
+			"Kptfile": `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+pipeline:
+  mutators:
+  - image: example.com/quiet
+  validators:
+  - image: example.com/check-replicas
+`,
+			"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 1
+`,
+		},
+	}
+
+	_, task, err := render.Apply(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if task == nil || task.Render == nil {
+		t.Fatalf("render returned task %+v; want a render task with results", task)
+	}
+
+	want := []api.FunctionResult{
+		{
+			Function: "example.com/check-replicas",
+			Message:  "replicas should be at least 2",
+			Severity: "warning",
+			Resource: "apps/v1/Deployment/default/app",
+			Field:    "spec.replicas",
+			File:     "deployment.yaml",
+		},
+	}
+	if diff := cmp.Diff(want, task.Render.Results); diff != "" {
+		t.Errorf("unexpected results (-want, +got): %s", diff)
+	}
+}
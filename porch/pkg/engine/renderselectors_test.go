@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/fnruntime"
+	"github.com/GoogleContainerTools/kpt/internal/printer/fake"
+	"github.com/GoogleContainerTools/kpt/internal/util/render"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// TestRenderSelectorsParity checks that packages rendered by porch are identical to packages
+// rendered locally by kpt fn render, in particular that the selectors and exclusions of the
+// Kptfile pipelines are honored. The fixture in testdata/render-selectors has the package
+// to render in package/ and the expected result in expected/.
+func TestRenderSelectorsParity(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join(".", "testdata", "render-selectors"))
+	if err != nil {
+		t.Fatalf("Failed to find testdata: %v", err)
+	}
+	pkg, err := loadResourcesFromDirectory(filepath.Join(testdata, "package"))
+	if err != nil {
+		t.Fatalf("Failed to read package: %v", err)
+	}
+
+	runnerOptions := fnruntime.RunnerOptions{}
+	runnerOptions.InitDefaults()
+
+	// Render through the engine, sequentially and concurrently.
+	var engineResult repository.PackageResources
+	for _, concurrency := range []int{1, 4} {
+		m := &renderPackageMutation{
+			renderer:       kpt.NewRenderer(runnerOptions),
+			runtime:        newBuiltinRuntime(),
+			maxConcurrency: concurrency,
+		}
+		result, _, err := m.Apply(context.Background(), pkg)
+		if err != nil {
+			t.Fatalf("Engine render with concurrency %d failed: %v", concurrency, err)
+		}
+		if concurrency > 1 {
+			if diff := cmp.Diff(engineResult.Contents, result.Contents); diff != "" {
+				t.Errorf("Concurrent engine render differs from sequential render (-sequential, +concurrent): %s", diff)
+			}
+			continue
+		}
+		engineResult = result
+	}
+
+	// Render on disk, the way kpt fn render does.
+	dir := t.TempDir()
+	if err := writeResourcesToDirectory(dir, pkg); err != nil {
+		t.Fatalf("Failed to write package: %v", err)
+	}
+	r := render.Renderer{
+		PkgPath:       dir,
+		Runtime:       newBuiltinRuntime(),
+		FileSystem:    filesys.FileSystemOrOnDisk{},
+		RunnerOptions: runnerOptions,
+	}
+	if err := r.Execute(fake.CtxWithDefaultPrinter()); err != nil {
+		t.Fatalf("Local render failed: %v", err)
+	}
+	localResult, err := loadResourcesFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("Failed to read rendered package: %v", err)
+	}
+
+	if diff := cmp.Diff(localResult.Contents, engineResult.Contents); diff != "" {
+		t.Errorf("Engine render differs from local render (-local, +engine): %s", diff)
+	}
+
+	expectedDir := filepath.Join(testdata, "expected")
+	if os.Getenv(updateGoldenFiles) != "" {
+		if err := os.RemoveAll(expectedDir); err != nil {
+			t.Fatalf("Failed to update expected output: %v", err)
+		}
+		if err := writeResourcesToDirectory(expectedDir, engineResult); err != nil {
+			t.Fatalf("Failed to update expected output: %v", err)
+		}
+	}
+	expected, err := loadResourcesFromDirectory(expectedDir)
+	if err != nil {
+		t.Fatalf("Failed to read expected output: %v", err)
+	}
+	if diff := cmp.Diff(expected.Contents, engineResult.Contents); diff != "" {
+		t.Errorf("Unexpected render result (-want, +got): %s", diff)
+	}
+}
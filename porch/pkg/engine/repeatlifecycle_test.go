@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepeatedLifecycleUpdate(t *testing.T) {
+	proposedAt := metav1.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, lifecycle := range []api.PackageRevisionLifecycle{
+		api.PackageRevisionLifecycleProposed,
+		api.PackageRevisionLifecyclePublished,
+	} {
+		t.Run(string(lifecycle), func(t *testing.T) {
+			ctx := context.Background()
+			repoPkgRev := &fake.PackageRevision{
+				Name:             "blueprints-app-v1",
+				Namespace:        "default",
+				PackageLifecycle: lifecycle,
+				PackageRevision: &api.PackageRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+					Spec:       api.PackageRevisionSpec{Lifecycle: lifecycle},
+				},
+			}
+			pkgRevMeta := meta.PackageRevisionMeta{
+				Name:           repoPkgRev.Name,
+				Namespace:      repoPkgRev.Namespace,
+				LifecycleTimes: meta.LifecycleTimes{ProposedAt: proposedAt},
+			}
+			metadataStore := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+			cad := &cadEngine{metadataStore: metadataStore}
+			oldPackage := &PackageRevision{repoPackageRevision: repoPkgRev, packageRevisionMeta: pkgRevMeta}
+
+			for i := 0; i < 2; i++ {
+				oldObj, err := oldPackage.GetPackageRevision(ctx)
+				if err != nil {
+					t.Fatalf("GetPackageRevision failed: %v", err)
+				}
+				newObj := oldObj.DeepCopy()
+				newObj.Labels = map[string]string{"team": "a"}
+
+				// A nil repository fails the update if a draft of the package revision
+				// is opened to write it again.
+				updated, err := cad.updatePackageRevision(ctx, nil, &configapi.Repository{}, oldPackage, oldObj, newObj, nil)
+				if err != nil {
+					t.Fatalf("update %d failed: %v", i+1, err)
+				}
+				oldPackage = updated
+			}
+
+			if diff := cmp.Diff(map[string]string{"team": "a"}, metadataStore.Metas[0].Labels); diff != "" {
+				t.Errorf("unexpected stored labels (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(pkgRevMeta.LifecycleTimes, metadataStore.Metas[0].LifecycleTimes); diff != "" {
+				t.Errorf("lifecycle times changed (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPublishedLifecycleChangeConflicts(t *testing.T) {
+	ctx := context.Background()
+	repoPkgRev := &fake.PackageRevision{
+		Name:             "blueprints-app-v1",
+		Namespace:        "default",
+		PackageLifecycle: api.PackageRevisionLifecyclePublished,
+		PackageRevision: &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+			Spec:       api.PackageRevisionSpec{Lifecycle: api.PackageRevisionLifecyclePublished},
+		},
+	}
+	pkgRevMeta := meta.PackageRevisionMeta{Name: repoPkgRev.Name, Namespace: repoPkgRev.Namespace}
+	cad := &cadEngine{metadataStore: &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}}
+	oldPackage := &PackageRevision{repoPackageRevision: repoPkgRev, packageRevisionMeta: pkgRevMeta}
+
+	for _, lifecycle := range []api.PackageRevisionLifecycle{
+		api.PackageRevisionLifecycleDraft,
+		api.PackageRevisionLifecycleProposed,
+	} {
+		oldObj, err := oldPackage.GetPackageRevision(ctx)
+		if err != nil {
+			t.Fatalf("GetPackageRevision failed: %v", err)
+		}
+		newObj := oldObj.DeepCopy()
+		newObj.Spec.Lifecycle = lifecycle
+
+		_, err = cad.updatePackageRevision(ctx, nil, &configapi.Repository{}, oldPackage, oldObj, newObj, nil)
+		if !apierrors.IsConflict(err) {
+			t.Errorf("update of published package revision to %s: got error %v, want a conflict", lifecycle, err)
+		}
+	}
+}
@@ -17,14 +17,18 @@ package engine
 import (
 	"bytes"
 	"context"
+	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/google/go-cmp/cmp"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 func TestReplaceResources(t *testing.T) {
@@ -56,6 +60,66 @@ func TestReplaceResources(t *testing.T) {
 	}
 }
 
+func TestReplaceResourcesPatch(t *testing.T) {
+	ctx := context.Background()
+	testdata := filepath.Join("testdata", "replace-patch")
+
+	input := readPackage(t, filepath.Join("testdata", "replace"))
+	updated := removeComments(t, input)
+	updated.Contents["bucket.yaml"] = strings.Replace(updated.Contents["bucket.yaml"], "enabled: false", "enabled: true", 1)
+	updated.Contents["configmap.yaml"] = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings # kpt-set: ${name}-settings\ndata:\n  replicas: \"3\"\n"
+	delete(updated.Contents, "README.md")
+
+	replace := &mutationReplaceResources{
+		newResources: &v1alpha1.PackageRevisionResources{
+			Spec: v1alpha1.PackageRevisionResourcesSpec{
+				Resources: updated.Contents,
+			},
+		},
+		oldResources: &v1alpha1.PackageRevisionResources{
+			Spec: v1alpha1.PackageRevisionResourcesSpec{
+				Resources: input.Contents,
+			},
+		},
+	}
+
+	output, task, err := replace.Apply(ctx, input)
+	if err != nil {
+		t.Fatalf("mutationReplaceResources.Apply failed: %v", err)
+	}
+	sort.Slice(task.Patch.Patches, func(i, j int) bool {
+		return task.Patch.Patches[i].File < task.Patch.Patches[j].File
+	})
+	gotTask, err := sigsyaml.Marshal(task)
+	if err != nil {
+		t.Fatalf("Failed to marshal task: %v", err)
+	}
+
+	expectedPackage := filepath.Join(testdata, "expected")
+	expectedTask := filepath.Join(testdata, "task.yaml")
+	if os.Getenv(updateGoldenFiles) != "" {
+		if err := os.RemoveAll(expectedPackage); err != nil {
+			t.Fatalf("Failed to update golden files: %v", err)
+		}
+		writePackage(t, expectedPackage, output)
+		if err := os.WriteFile(expectedTask, gotTask, 0644); err != nil {
+			t.Fatalf("Failed to update golden files: %v", err)
+		}
+	}
+
+	want := readPackage(t, expectedPackage)
+	if !cmp.Equal(want, output) {
+		t.Errorf("Unexpected resources (-want, +got): %s", cmp.Diff(want, output))
+	}
+	wantTask, err := os.ReadFile(expectedTask)
+	if err != nil {
+		t.Fatalf("Failed to read golden task: %v", err)
+	}
+	if diff := cmp.Diff(string(wantTask), string(gotTask)); diff != "" {
+		t.Errorf("Unexpected task (-want, +got): %s", diff)
+	}
+}
+
 func removeComments(t *testing.T, r repository.PackageResources) repository.PackageResources {
 	t.Helper()
 
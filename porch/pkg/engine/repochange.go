@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RepositoryChangeSeverity is how disruptive a change of a Repository spec is.
+type RepositoryChangeSeverity string
+
+const (
+	// RepositoryChangeSafe means that the change doesn't affect the known package revisions.
+	RepositoryChangeSafe RepositoryChangeSeverity = "Safe"
+	// RepositoryChangeWarning means that some known package revisions would no longer be visible.
+	RepositoryChangeWarning RepositoryChangeSeverity = "Warning"
+	// RepositoryChangeBlocking means that the new spec is invalid, that its credentials don't
+	// work, or that every known package revision would no longer be visible.
+	RepositoryChangeBlocking RepositoryChangeSeverity = "Blocking"
+)
+
+// RepositoryChangeImpact is the impact of a change of a Repository spec, as reported by
+// ValidateRepositoryChange.
+type RepositoryChangeImpact struct {
+	Severity RepositoryChangeSeverity
+	// KnownRevisions is the number of package revisions currently known in the repository.
+	KnownRevisions int
+	// HiddenRevisions are the names of the known package revisions which would no longer be visible.
+	HiddenRevisions []string
+	// UnreachableDrafts are the names of the draft and proposed package revisions among
+	// HiddenRevisions, whose branches would no longer be reachable.
+	UnreachableDrafts []string
+	// Problems describe the impact for display to users.
+	Problems []string
+}
+
+// ValidateRepositoryChange reports the impact of changing the spec of an existing repository
+// from oldRepo to newRepo without applying the change. It checks the new spec, that its
+// credentials work against the new location and which of the currently known package
+// revisions would become invisible.
+func (cad *cadEngine) ValidateRepositoryChange(ctx context.Context, oldRepo, newRepo *configapi.Repository) (*RepositoryChangeImpact, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::ValidateRepositoryChange", trace.WithAttributes())
+	defer span.End()
+
+	if err := cad.ValidateRepositoryConfig(ctx, newRepo); err != nil {
+		var configErr *RepositoryConfigError
+		if !errors.As(err, &configErr) {
+			return nil, err
+		}
+		return &RepositoryChangeImpact{Severity: RepositoryChangeBlocking, Problems: []string{err.Error()}}, nil
+	}
+	if !repositoryLocationChanged(&oldRepo.Spec, &newRepo.Spec) {
+		return &RepositoryChangeImpact{Severity: RepositoryChangeSafe}, nil
+	}
+
+	repo, err := cad.cache.OpenRepository(ctx, oldRepo)
+	if err != nil {
+		return nil, err
+	}
+	known, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The refs of the new location are only needed if it points elsewhere; changing only
+	// the directory can be assessed from the known package revisions alone.
+	var remoteRefs []string
+	if oldGit, newGit := oldRepo.Spec.Git, newRepo.Spec.Git; oldGit != nil && newGit != nil &&
+		(oldGit.Repo != newGit.Repo || oldGit.Branch != newGit.Branch || oldGit.SecretRef != newGit.SecretRef) {
+		if remoteRefs, err = cad.listRemoteRefs(ctx, newRepo); err != nil {
+			return &RepositoryChangeImpact{
+				Severity:       RepositoryChangeBlocking,
+				KnownRevisions: len(known),
+				Problems:       []string{fmt.Sprintf("cannot access %s with the new settings: %v", newGit.Repo, err)},
+			}, nil
+		}
+	}
+
+	return assessRepositoryChange(oldRepo, newRepo, known, remoteRefs), nil
+}
+
+// listRemoteRefs lists the refs of the git repository of the spec, using its credentials.
+func (cad *cadEngine) listRemoteRefs(ctx context.Context, repositoryObj *configapi.Repository) ([]string, error) {
+	gitSpec := repositoryObj.Spec.Git
+	var auth transport.AuthMethod
+	if secret := gitSpec.SecretRef.Name; secret != "" {
+		// ValidateRepositoryConfig has checked that the credential resolves.
+		cred, err := cad.credentialResolver.ResolveCredential(ctx, repositoryObj.Namespace, secret)
+		if err != nil {
+			return nil, err
+		}
+		auth = cred.ToAuthMethod()
+	}
+	return git.ListRemoteRefs(ctx, gitSpec.Repo, auth)
+}
+
+// repositoryLocationChanged returns true if the change of the spec may change which
+// package revisions are found in the repository.
+func repositoryLocationChanged(oldSpec, newSpec *configapi.RepositorySpec) bool {
+	if oldSpec.Type != newSpec.Type || oldSpec.Content != newSpec.Content {
+		return true
+	}
+	switch {
+	case oldSpec.Git != nil && newSpec.Git != nil:
+		oldGit, newGit := oldSpec.Git, newSpec.Git
+		return oldGit.Repo != newGit.Repo || oldGit.Branch != newGit.Branch ||
+			strings.Trim(oldGit.Directory, "/") != strings.Trim(newGit.Directory, "/") ||
+			oldGit.SecretRef != newGit.SecretRef
+	case oldSpec.Oci != nil && newSpec.Oci != nil:
+		return oldSpec.Oci.Registry != newSpec.Oci.Registry || oldSpec.Oci.SecretRef != newSpec.Oci.SecretRef
+	default:
+		return (oldSpec.Git == nil) != (newSpec.Git == nil) || (oldSpec.Oci == nil) != (newSpec.Oci == nil)
+	}
+}
+
+// assessRepositoryChange determines which of the known package revisions would remain
+// visible after the change. remoteRefs are the refs found at the new git location, or
+// nil if the location (other than the directory) doesn't change.
+func assessRepositoryChange(oldRepo, newRepo *configapi.Repository, known []repository.PackageRevision, remoteRefs []string) *RepositoryChangeImpact {
+	impact := &RepositoryChangeImpact{KnownRevisions: len(known)}
+
+	refs := map[string]bool{}
+	for _, ref := range remoteRefs {
+		refs[ref] = true
+	}
+	visible := func(pr repository.PackageRevision) bool {
+		oldGit, newGit := oldRepo.Spec.Git, newRepo.Spec.Git
+		if oldGit == nil || newGit == nil {
+			// Package revisions of repositories changing type can't be found at the new location.
+			oldOci, newOci := oldRepo.Spec.Oci, newRepo.Spec.Oci
+			return oldOci != nil && newOci != nil && oldOci.Registry == newOci.Registry
+		}
+		if !git.PackageInDirectory(pr.Key().Package, newGit.Directory) {
+			return false
+		}
+		if remoteRefs == nil {
+			return true
+		}
+		_, lock, err := pr.GetLock()
+		if err != nil || lock.Git == nil {
+			return false
+		}
+		if lock.Git.Ref == oldGit.Branch {
+			// Revisions on the main branch are found on the new main branch.
+			return refs["refs/heads/"+newGit.Branch]
+		}
+		return refs["refs/heads/"+lock.Git.Ref] || refs["refs/tags/"+lock.Git.Ref]
+	}
+
+	for _, pr := range known {
+		if visible(pr) {
+			continue
+		}
+		impact.HiddenRevisions = append(impact.HiddenRevisions, pr.KubeObjectName())
+		switch pr.Lifecycle() {
+		case api.PackageRevisionLifecycleDraft, api.PackageRevisionLifecycleProposed:
+			impact.UnreachableDrafts = append(impact.UnreachableDrafts, pr.KubeObjectName())
+		}
+	}
+	sort.Strings(impact.HiddenRevisions)
+	sort.Strings(impact.UnreachableDrafts)
+
+	switch hidden := len(impact.HiddenRevisions); {
+	case hidden == 0:
+		impact.Severity = RepositoryChangeSafe
+	case hidden == impact.KnownRevisions:
+		impact.Severity = RepositoryChangeBlocking
+	default:
+		impact.Severity = RepositoryChangeWarning
+	}
+	if hidden := len(impact.HiddenRevisions); hidden > 0 {
+		impact.Problems = append(impact.Problems, fmt.Sprintf("%d of %d known package revisions would no longer be visible", hidden, impact.KnownRevisions))
+	}
+	if len(impact.UnreachableDrafts) > 0 {
+		impact.Problems = append(impact.Problems, fmt.Sprintf("branches of draft and proposed package revisions would no longer be reachable: %s", strings.Join(impact.UnreachableDrafts, ", ")))
+	}
+	if newGit := newRepo.Spec.Git; newGit != nil && remoteRefs != nil && !newGit.CreateBranch && !refs["refs/heads/"+newGit.Branch] {
+		impact.Problems = append(impact.Problems, fmt.Sprintf("branch %q does not exist in %s", newGit.Branch, newGit.Repo))
+		if impact.Severity == RepositoryChangeSafe {
+			impact.Severity = RepositoryChangeWarning
+		}
+	}
+	return impact
+}
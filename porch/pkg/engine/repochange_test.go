@@ -0,0 +1,166 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateRepositoryChange(t *testing.T) {
+	gitRepository := func(edit func(spec *configapi.RepositorySpec)) *configapi.Repository {
+		repo := &configapi.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"},
+			Spec: configapi.RepositorySpec{
+				Description: "Blueprints",
+				Type:        configapi.RepositoryTypeGit,
+				Content:     configapi.RepositoryContentPackage,
+				Git: &configapi.GitRepository{
+					Repo:      "https://github.com/example/blueprints.git",
+					Branch:    "main",
+					Directory: "/catalog",
+				},
+			},
+		}
+		if edit != nil {
+			edit(&repo.Spec)
+		}
+		return repo
+	}
+	packageRevision := func(name, pkg, revision, ref string, lifecycle v1alpha1.PackageRevisionLifecycle) repository.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               name,
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: pkg, Revision: revision},
+			PackageLifecycle:   lifecycle,
+			Kptfile: kptfile.KptFile{
+				Upstream:     &kptfile.Upstream{Type: kptfile.GitOrigin, Git: &kptfile.Git{Ref: ref}},
+				UpstreamLock: &kptfile.UpstreamLock{Type: kptfile.GitOrigin, Git: &kptfile.GitLock{Ref: ref}},
+			},
+		}
+	}
+	known := []repository.PackageRevision{
+		packageRevision("blueprints-bucket-main", "catalog/bucket", "main", "main", v1alpha1.PackageRevisionLifecyclePublished),
+		packageRevision("blueprints-bucket-v1", "catalog/bucket", "v1", "catalog/bucket/v1", v1alpha1.PackageRevisionLifecyclePublished),
+		packageRevision("blueprints-bucket-v2", "catalog/bucket", "v2", "drafts/catalog/bucket/v2", v1alpha1.PackageRevisionLifecycleDraft),
+		packageRevision("blueprints-network-v1", "catalog/team/network", "v1", "catalog/team/network/v1", v1alpha1.PackageRevisionLifecyclePublished),
+	}
+
+	t.Run("description change", func(t *testing.T) {
+		// The engine has no cache: a harmless change must not need to look at the repository.
+		cad := &cadEngine{}
+		impact, err := cad.ValidateRepositoryChange(context.Background(), gitRepository(nil), gitRepository(func(spec *configapi.RepositorySpec) {
+			spec.Description = "Curated blueprints"
+		}))
+		if err != nil {
+			t.Fatalf("ValidateRepositoryChange failed: %v", err)
+		}
+		if diff := cmp.Diff(&RepositoryChangeImpact{Severity: RepositoryChangeSafe}, impact); diff != "" {
+			t.Errorf("unexpected impact (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		cad := &cadEngine{}
+		impact, err := cad.ValidateRepositoryChange(context.Background(), gitRepository(nil), gitRepository(func(spec *configapi.RepositorySpec) {
+			spec.Git.Directory = "../other"
+		}))
+		if err != nil {
+			t.Fatalf("ValidateRepositoryChange failed: %v", err)
+		}
+		if impact.Severity != RepositoryChangeBlocking {
+			t.Errorf("expected an invalid spec to be blocking, got %s", impact.Severity)
+		}
+	})
+
+	testCases := map[string]struct {
+		edit         func(spec *configapi.RepositorySpec)
+		remoteRefs   []string
+		wantSeverity RepositoryChangeSeverity
+		wantHidden   []string
+		wantDrafts   []string
+	}{
+		"directory change hiding all packages": {
+			edit:         func(spec *configapi.RepositorySpec) { spec.Git.Directory = "/other" },
+			wantSeverity: RepositoryChangeBlocking,
+			wantHidden:   []string{"blueprints-bucket-main", "blueprints-bucket-v1", "blueprints-bucket-v2", "blueprints-network-v1"},
+			wantDrafts:   []string{"blueprints-bucket-v2"},
+		},
+		"directory change hiding some packages": {
+			edit:         func(spec *configapi.RepositorySpec) { spec.Git.Directory = "catalog/team/" },
+			wantSeverity: RepositoryChangeWarning,
+			wantHidden:   []string{"blueprints-bucket-main", "blueprints-bucket-v1", "blueprints-bucket-v2"},
+			wantDrafts:   []string{"blueprints-bucket-v2"},
+		},
+		"directory spelled differently": {
+			edit:         func(spec *configapi.RepositorySpec) { spec.Git.Directory = "catalog" },
+			wantSeverity: RepositoryChangeSafe,
+		},
+		"branch change": {
+			edit: func(spec *configapi.RepositorySpec) { spec.Git.Branch = "release" },
+			remoteRefs: []string{
+				"refs/heads/main",
+				"refs/heads/release",
+				"refs/heads/drafts/catalog/bucket/v2",
+				"refs/tags/catalog/bucket/v1",
+				"refs/tags/catalog/team/network/v1",
+			},
+			wantSeverity: RepositoryChangeSafe,
+		},
+		"mirror missing drafts": {
+			edit: func(spec *configapi.RepositorySpec) { spec.Git.Repo = "https://mirror.example.com/blueprints.git" },
+			remoteRefs: []string{
+				"refs/heads/main",
+				"refs/tags/catalog/bucket/v1",
+				"refs/tags/catalog/team/network/v1",
+			},
+			wantSeverity: RepositoryChangeWarning,
+			wantHidden:   []string{"blueprints-bucket-v2"},
+			wantDrafts:   []string{"blueprints-bucket-v2"},
+		},
+		"empty repository": {
+			edit:         func(spec *configapi.RepositorySpec) { spec.Git.Repo = "https://example.com/empty.git" },
+			remoteRefs:   []string{},
+			wantSeverity: RepositoryChangeBlocking,
+			wantHidden:   []string{"blueprints-bucket-main", "blueprints-bucket-v1", "blueprints-bucket-v2", "blueprints-network-v1"},
+			wantDrafts:   []string{"blueprints-bucket-v2"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			impact := assessRepositoryChange(gitRepository(nil), gitRepository(tc.edit), known, tc.remoteRefs)
+			if got, want := impact.Severity, tc.wantSeverity; got != want {
+				t.Errorf("unexpected severity: got %s, want %s (%v)", got, want, impact.Problems)
+			}
+			if got, want := impact.KnownRevisions, len(known); got != want {
+				t.Errorf("unexpected number of known revisions: got %d, want %d", got, want)
+			}
+			if diff := cmp.Diff(tc.wantHidden, impact.HiddenRevisions); diff != "" {
+				t.Errorf("unexpected hidden revisions (-want, +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDrafts, impact.UnreachableDrafts); diff != "" {
+				t.Errorf("unexpected unreachable drafts (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RepositoryConfigError describes a problem found in a Repository spec by ValidateRepositoryConfig.
+type RepositoryConfigError struct {
+	// Field is the path of the offending field, for example "spec.git.repo".
+	Field  string
+	Reason string
+}
+
+func (e *RepositoryConfigError) Error() string {
+	return fmt.Sprintf("invalid repository configuration: %s: %s", e.Field, e.Reason)
+}
+
+// ValidateRepositoryConfig checks that the repository spec is consistent and that its
+// credentials (if any) can be resolved. Unlike opening the repository, it does not
+// contact the git server or the OCI registry.
+func (cad *cadEngine) ValidateRepositoryConfig(ctx context.Context, repositorySpec *configapi.Repository) error {
+	ctx, span := tracer.Start(ctx, "cadEngine::ValidateRepositoryConfig", trace.WithAttributes())
+	defer span.End()
+
+	var secret, secretField string
+	switch repositoryType := repositorySpec.Spec.Type; repositoryType {
+	case configapi.RepositoryTypeGit:
+		gitSpec := repositorySpec.Spec.Git
+		if gitSpec == nil {
+			return &RepositoryConfigError{Field: "spec.git", Reason: "required when type is git"}
+		}
+		if gitSpec.Repo == "" {
+			return &RepositoryConfigError{Field: "spec.git.repo", Reason: "required"}
+		}
+		if err := validateGitAddress(gitSpec.Repo); err != nil {
+			return &RepositoryConfigError{Field: "spec.git.repo", Reason: err.Error()}
+		}
+		// Leading and trailing slashes are ignored; "/" is the root of the repository.
+		for _, elem := range strings.Split(gitSpec.Directory, "/") {
+			if elem == ".." {
+				return &RepositoryConfigError{Field: "spec.git.directory", Reason: fmt.Sprintf("must be a path within the repository; got %q", gitSpec.Directory)}
+			}
+		}
+		if repositorySpec.Spec.Content != configapi.RepositoryContentPackage {
+			return &RepositoryConfigError{Field: "spec.content", Reason: fmt.Sprintf("git repository supports Package content only; got %q", string(repositorySpec.Spec.Content))}
+		}
+		secret, secretField = gitSpec.SecretRef.Name, "spec.git.secretRef.name"
+
+	case configapi.RepositoryTypeOCI:
+		ociSpec := repositorySpec.Spec.Oci
+		if ociSpec == nil {
+			return &RepositoryConfigError{Field: "spec.oci", Reason: "required when type is oci"}
+		}
+		if ociSpec.Registry == "" {
+			return &RepositoryConfigError{Field: "spec.oci.registry", Reason: "required"}
+		}
+		switch repositorySpec.Spec.Content {
+		case configapi.RepositoryContentPackage, configapi.RepositoryContentFunction:
+		default:
+			return &RepositoryConfigError{Field: "spec.content", Reason: fmt.Sprintf("unsupported content %q", string(repositorySpec.Spec.Content))}
+		}
+		secret, secretField = ociSpec.SecretRef.Name, "spec.oci.secretRef.name"
+
+	default:
+		return &RepositoryConfigError{Field: "spec.type", Reason: fmt.Sprintf("unsupported repository type %q", repositoryType)}
+	}
+
+	if text, found := repositorySpec.Annotations[configapi.DescriptionTemplateAnnotation]; found {
+		if _, err := ParseDescriptionTemplate(text); err != nil {
+			return &RepositoryConfigError{Field: fmt.Sprintf("metadata.annotations[%s]", configapi.DescriptionTemplateAnnotation), Reason: err.Error()}
+		}
+	}
+
+	for i, allowed := range repositorySpec.Spec.AllowedUpstreams {
+		set := 0
+		for _, value := range []string{allowed.Repository, allowed.Git, allowed.Oci} {
+			if value != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return &RepositoryConfigError{Field: fmt.Sprintf("spec.allowedUpstreams[%d]", i), Reason: "exactly one of repository, git or oci is required"}
+		}
+	}
+	if err := validateRepositorySharing(&repositorySpec.Spec); err != nil {
+		return err
+	}
+	if err := validateDefaultMetadata(&repositorySpec.Spec); err != nil {
+		return err
+	}
+	if err := validateCommitTrailers(&repositorySpec.Spec); err != nil {
+		return err
+	}
+
+	if secret == "" {
+		return nil
+	}
+	if cad.credentialResolver == nil {
+		return &RepositoryConfigError{Field: secretField, Reason: "credentials are referenced but no credential resolver is configured"}
+	}
+	cred, err := cad.credentialResolver.ResolveCredential(ctx, repositorySpec.Namespace, secret)
+	if err != nil {
+		return &RepositoryConfigError{Field: secretField, Reason: fmt.Sprintf("cannot resolve secret %s/%s: %v", repositorySpec.Namespace, secret, err)}
+	}
+	if cred == nil || !cred.Valid() {
+		return &RepositoryConfigError{Field: secretField, Reason: fmt.Sprintf("secret %s/%s does not contain valid credentials", repositorySpec.Namespace, secret)}
+	}
+	return nil
+}
+
+// validateGitAddress accepts URLs with a scheme supported by go-git as well as
+// scp-like addresses such as git@github.com:org/repo.git.
+func validateGitAddress(address string) error {
+	if !strings.Contains(address, "://") {
+		if at := strings.Index(address, "@"); at > 0 && strings.Contains(address[at:], ":") {
+			return nil
+		}
+		return fmt.Errorf("%q is not a valid git address", address)
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid git address: %w", address, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ssh", "git", "file":
+	default:
+		return fmt.Errorf("unsupported scheme %q in git address %q", u.Scheme, address)
+	}
+	if u.Scheme != "file" && u.Host == "" {
+		return fmt.Errorf("git address %q has no host", address)
+	}
+	return nil
+}
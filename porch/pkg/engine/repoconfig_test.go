@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type missingSecretResolver struct{}
+
+func (r *missingSecretResolver) ResolveCredential(ctx context.Context, namespace, name string) (repository.Credential, error) {
+	if name == "git-auth" {
+		return &credential{username: "user", password: "password"}, nil
+	}
+	return nil, fmt.Errorf("secret %s/%s not found", namespace, name)
+}
+
+func TestValidateRepositoryConfig(t *testing.T) {
+	gitRepository := func(spec configapi.GitRepository) *configapi.Repository {
+		return &configapi.Repository{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default"},
+			Spec: configapi.RepositorySpec{
+				Type:    configapi.RepositoryTypeGit,
+				Content: configapi.RepositoryContentPackage,
+				Git:     &spec,
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		repository *configapi.Repository
+		wantField  string
+	}{
+		"valid without secret": {
+			repository: gitRepository(configapi.GitRepository{Repo: "https://github.com/example/blueprints.git"}),
+		},
+		"valid with secret": {
+			repository: gitRepository(configapi.GitRepository{
+				Repo:      "git@github.com:example/blueprints.git",
+				SecretRef: configapi.SecretRef{Name: "git-auth"},
+			}),
+		},
+		"root directory": {
+			repository: gitRepository(configapi.GitRepository{Repo: "https://github.com/example/blueprints.git", Directory: "/"}),
+		},
+		"directory outside repository": {
+			repository: gitRepository(configapi.GitRepository{Repo: "https://github.com/example/blueprints.git", Directory: "catalog/../.."}),
+			wantField:  "spec.git.directory",
+		},
+		"missing repo": {
+			repository: gitRepository(configapi.GitRepository{Branch: "main"}),
+			wantField:  "spec.git.repo",
+		},
+		"bad scheme": {
+			repository: gitRepository(configapi.GitRepository{Repo: "ftp://example.com/repo.git"}),
+			wantField:  "spec.git.repo",
+		},
+		"unresolvable secret": {
+			repository: gitRepository(configapi.GitRepository{
+				Repo:      "https://github.com/example/blueprints.git",
+				SecretRef: configapi.SecretRef{Name: "missing"},
+			}),
+			wantField: "spec.git.secretRef.name",
+		},
+		"missing oci registry": {
+			repository: &configapi.Repository{
+				Spec: configapi.RepositorySpec{
+					Type:    configapi.RepositoryTypeOCI,
+					Content: configapi.RepositoryContentFunction,
+					Oci:     &configapi.OciRepository{},
+				},
+			},
+			wantField: "spec.oci.registry",
+		},
+		"ambiguous allowed upstream": {
+			repository: &configapi.Repository{
+				Spec: configapi.RepositorySpec{
+					Type:    configapi.RepositoryTypeGit,
+					Content: configapi.RepositoryContentPackage,
+					Git:     &configapi.GitRepository{Repo: "https://github.com/example/blueprints.git"},
+					AllowedUpstreams: []configapi.AllowedUpstream{
+						{Repository: "blueprints"},
+						{Repository: "catalog", Git: "https://github.com/example"},
+					},
+				},
+			},
+			wantField: "spec.allowedUpstreams[1]",
+		},
+		"invalid description template": {
+			repository: &configapi.Repository{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{configapi.DescriptionTemplateAnnotation: "{{.Package"}},
+				Spec: configapi.RepositorySpec{
+					Type:    configapi.RepositoryTypeGit,
+					Content: configapi.RepositoryContentPackage,
+					Git:     &configapi.GitRepository{Repo: "https://github.com/example/blueprints.git"},
+				},
+			},
+			wantField: "metadata.annotations[" + configapi.DescriptionTemplateAnnotation + "]",
+		},
+		"unsupported type": {
+			repository: &configapi.Repository{Spec: configapi.RepositorySpec{Type: "svn"}},
+			wantField:  "spec.type",
+		},
+	}
+
+	cad := &cadEngine{credentialResolver: &missingSecretResolver{}}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := cad.ValidateRepositoryConfig(context.Background(), tc.repository)
+			if tc.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidateRepositoryConfig failed: %v", err)
+				}
+				return
+			}
+			var configErr *RepositoryConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("expected RepositoryConfigError, got %v", err)
+			}
+			if got, want := configErr.Field, tc.wantField; got != want {
+				t.Errorf("unexpected field in error %q: got %q, want %q", err, got, want)
+			}
+		})
+	}
+}
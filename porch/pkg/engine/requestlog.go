@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+// RequestIDHeader is the HTTP response header returning the request ID to clients, so
+// users can quote it in bug reports.
+const RequestIDHeader = "X-Porch-Request-Id"
+
+// requestIDLogKey is the key of the request ID in the structured log lines of a request.
+const requestIDLogKey = "requestID"
+
+type requestIDKey struct{}
+
+// NewRequestID returns a new ID correlating the log lines of a request. The ID is the
+// trace ID of the request if it is traced, so log lines and spans of a request can be
+// found with the same ID.
+func NewRequestID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// WithRequestID returns a context with the request ID, whose logger adds the request ID
+// to all log lines.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), requestIDLogKey, id))
+}
+
+// RequestIDFromContext returns the request ID of the context, or "" if it has none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// logScope is what the logger of a context identifies in all log lines.
+type logScope struct {
+	repository string
+	pkg        string
+	revision   string
+}
+
+type logScopeKey struct{}
+
+// withLogScope returns a context whose logger identifies the request, and the
+// repository, package and revision it acts on, in all log lines. Requests served over
+// HTTP have a request ID already; other requests, like those of background tasks, are
+// given a new one. Values already identified by the logger, like the repository of a
+// package revision deleted by a repository-wide operation, aren't repeated.
+func withLogScope(ctx context.Context, repositoryName, packageName, revision string) context.Context {
+	if RequestIDFromContext(ctx) == "" {
+		ctx = WithRequestID(ctx, NewRequestID(ctx))
+	}
+	scope, _ := ctx.Value(logScopeKey{}).(logScope)
+	var kv []interface{}
+	if repositoryName != "" && repositoryName != scope.repository {
+		scope.repository = repositoryName
+		kv = append(kv, "repository", repositoryName)
+	}
+	if packageName != "" && packageName != scope.pkg {
+		scope.pkg = packageName
+		kv = append(kv, "package", packageName)
+	}
+	if revision != "" && revision != scope.revision {
+		scope.revision = revision
+		kv = append(kv, "revision", revision)
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, logScopeKey{}, scope)
+	return klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), kv...))
+}
+
+// withRepositoryLogger returns a context whose logger identifies the request and the
+// repository in all log lines.
+func withRepositoryLogger(ctx context.Context, repositoryObj *configapi.Repository) context.Context {
+	return withLogScope(ctx, repositoryObj.Name, "", "")
+}
+
+// withPackageLogger returns a context whose logger identifies the request and the
+// package revision in all log lines.
+func withPackageLogger(ctx context.Context, key repository.PackageRevisionKey) context.Context {
+	return withLogScope(ctx, key.Repository, key.Package, key.Revision)
+}
+
+// withTaskLogger returns a context whose logger identifies the task in all log lines of
+// the mutation applying it.
+func withTaskLogger(ctx context.Context, index int) context.Context {
+	return klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), "task", index))
+}
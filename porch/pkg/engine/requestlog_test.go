@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-logr/logr/funcr"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// logCapture records the lines logged through its logger.
+type logCapture struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+func (c *logCapture) context(ctx context.Context) context.Context {
+	logger := funcr.New(func(prefix, args string) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.lines = append(c.lines, args)
+	}, funcr.Options{Verbosity: 10})
+	return klog.NewContext(ctx, logger)
+}
+
+func TestCreateLogFields(t *testing.T) {
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	obj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Revision:    "v1",
+			Lifecycle:   api.PackageRevisionLifecycleDraft,
+			Tasks: []api.Task{
+				{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}},
+				{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{
+					{File: "missing.yaml", PatchType: api.PatchTypeDeleteFile},
+				}}},
+			},
+		},
+	}
+
+	var capture logCapture
+	ctx := capture.context(context.Background())
+	ctx = withPackageLogger(ctx, repository.PackageRevisionKey{Repository: repositoryObj.Name, Package: obj.Spec.PackageName, Revision: obj.Spec.Revision})
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		t.Fatalf("no request ID was assigned")
+	}
+
+	cad := &cadEngine{renderer: &countingRenderer{}, metadataStore: &metafake.MemoryMetadataStore{}}
+	if _, err := cad.createPackageRevision(ctx, &creatingRepository{}, repositoryObj, obj, nil); err != nil {
+		t.Fatalf("createPackageRevision failed: %v", err)
+	}
+
+	// The patch and the render mutations log why they skipped part of their work.
+	if len(capture.lines) < 2 {
+		t.Fatalf("got %d log lines from mutations; want at least 2: %v", len(capture.lines), capture.lines)
+	}
+	for _, line := range capture.lines {
+		for _, field := range []string{
+			`"requestID"="` + requestID + `"`,
+			`"repository"="blueprints"`,
+			`"package"="app"`,
+			`"revision"="v1"`,
+			`"task"=`,
+		} {
+			if !strings.Contains(line, field) {
+				t.Errorf("log line %s is missing %s", line, field)
+			}
+		}
+	}
+}
+
+func TestWithLogScope(t *testing.T) {
+	var capture logCapture
+	ctx := WithRequestID(capture.context(context.Background()), "1234")
+
+	// Nested scopes add only the values which are new.
+	ctx = withLogScope(ctx, "blueprints", "", "")
+	ctx = withLogScope(ctx, "blueprints", "app", "v1")
+	klog.FromContext(ctx).Info("deleted")
+
+	want := `"requestID"="1234" "repository"="blueprints" "package"="app" "revision"="v1"`
+	if len(capture.lines) != 1 || !strings.Contains(capture.lines[0], want) {
+		t.Errorf("got log lines %v; want a line with %s", capture.lines, want)
+	}
+	if got := RequestIDFromContext(ctx); got != "1234" {
+		t.Errorf("request ID changed to %q", got)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	if got, want := NewRequestID(ctx), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+		t.Errorf("request ID of traced request is %q; want trace ID %q", got, want)
+	}
+
+	a, b := NewRequestID(context.Background()), NewRequestID(context.Background())
+	if len(a) != 32 || a == b {
+		t.Errorf("request IDs of untraced requests aren't unique: %q, %q", a, b)
+	}
+}
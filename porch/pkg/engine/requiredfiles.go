@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// RequiredFileError is returned when an update of the resources of a package revision
+// removes a file the package can't do without.
+//
+// RequiredFileError is an API status error: it is returned to clients as an Invalid
+// status whose cause field is the missing file.
+type RequiredFileError struct {
+	// File is the missing file.
+	File string
+	// RenamedTo is the file the required file was renamed to, if it was renamed.
+	RenamedTo string
+	// Reason explains why the file is required.
+	Reason string
+}
+
+var _ apierrors.APIStatus = &RequiredFileError{}
+
+func (e *RequiredFileError) Error() string {
+	if e.RenamedTo != "" {
+		return fmt.Sprintf("required file %s is missing: it was renamed to %s, but %s", e.File, e.RenamedTo, e.Reason)
+	}
+	return fmt.Sprintf("required file %s is missing: %s", e.File, e.Reason)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *RequiredFileError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusUnprocessableEntity,
+		Reason:  metav1.StatusReasonInvalid,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisionresources",
+			Causes: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: e.Reason,
+				Field:   e.File,
+			}},
+		},
+	}
+}
+
+// protectsPackageContext returns true if the repository rejects updates removing the
+// package context of its packages.
+func protectsPackageContext(repositoryObj *configapi.Repository) bool {
+	return repositoryObj != nil && repositoryObj.Annotations[configapi.ProtectPackageContextAnnotation] == "true"
+}
+
+// checkRequiredFiles checks that new, the resources replacing old, keep the root Kptfile
+// and, if old has one, the package context ConfigMap. The removal of the package context
+// is only logged unless protectPackageContext is set.
+func checkRequiredFiles(ctx context.Context, old, new map[string]string, protectPackageContext bool) error {
+	if kf, found := old[kptfile.KptFileName]; found {
+		if _, found := new[kptfile.KptFileName]; !found {
+			return &RequiredFileError{
+				File:      kptfile.KptFileName,
+				RenamedTo: renamedTo(kf, old, new),
+				Reason:    "the root Kptfile defines the package and can't be removed or renamed",
+			}
+		}
+	}
+
+	// A package context moved to another file is still found.
+	if !hasPackageContext(old) || hasPackageContext(new) {
+		return nil
+	}
+	missing := &RequiredFileError{
+		File:   builtins.PkgContextFile,
+		Reason: fmt.Sprintf("it holds the package context ConfigMap %s", builtins.PkgContextName),
+	}
+	if protectPackageContext {
+		return missing
+	}
+	klog.FromContext(ctx).Info("Update of package resources removes the package context", "reason", missing.Error())
+	return nil
+}
+
+// renamedTo returns the file of new with contents, which isn't in old.
+func renamedTo(contents string, old, new map[string]string) string {
+	for name, c := range new {
+		if _, found := old[name]; !found && c == contents {
+			return name
+		}
+	}
+	return ""
+}
+
+// hasPackageContext returns true if the resources contain the package context ConfigMap.
+func hasPackageContext(resources map[string]string) bool {
+	return len(pkgcontents.Contents(resources).FindResources(schema.GroupVersionKind{Kind: "ConfigMap"}, builtins.PkgContextName)) != 0
+}
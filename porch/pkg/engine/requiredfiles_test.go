@@ -0,0 +1,139 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/internal/builtins"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReplaceResourcesRequiredFiles(t *testing.T) {
+	const (
+		kf         = "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n"
+		pkgContext = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kptfile.kpt.dev\n  annotations:\n    config.kubernetes.io/local-config: \"true\"\ndata:\n  name: app\n"
+		configMap  = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"
+	)
+	old := map[string]string{
+		kptfile.KptFileName:     kf,
+		builtins.PkgContextFile: pkgContext,
+		"configmap.yaml":        configMap,
+	}
+	without := func(names ...string) map[string]string {
+		resources := map[string]string{}
+		for k, v := range old {
+			resources[k] = v
+		}
+		for _, name := range names {
+			delete(resources, name)
+		}
+		return resources
+	}
+	protected := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{configapi.ProtectPackageContextAnnotation: "true"},
+	}}
+
+	for _, tc := range []struct {
+		name       string
+		repository *configapi.Repository
+		new        map[string]string
+		wantFile   string
+		wantError  string
+	}{
+		{
+			name: "intact",
+			new:  without("configmap.yaml"),
+		},
+		{
+			name:       "intact in protected repository",
+			repository: protected,
+			new:        without("configmap.yaml"),
+		},
+		{
+			name:      "Kptfile removed",
+			new:       without(kptfile.KptFileName),
+			wantFile:  kptfile.KptFileName,
+			wantError: "required file Kptfile is missing",
+		},
+		{
+			name: "Kptfile renamed",
+			new: func() map[string]string {
+				resources := without(kptfile.KptFileName)
+				resources["Kptfile.bak"] = kf
+				return resources
+			}(),
+			wantFile:  kptfile.KptFileName,
+			wantError: "it was renamed to Kptfile.bak",
+		},
+		{
+			name: "package context removed",
+			new:  without(builtins.PkgContextFile),
+		},
+		{
+			name:       "package context removed from protected repository",
+			repository: protected,
+			new:        without(builtins.PkgContextFile),
+			wantFile:   builtins.PkgContextFile,
+			wantError:  "package context ConfigMap kptfile.kpt.dev",
+		},
+		{
+			name:       "package context moved in protected repository",
+			repository: protected,
+			new: func() map[string]string {
+				resources := without(builtins.PkgContextFile)
+				resources["context.yaml"] = pkgContext
+				return resources
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cad := &cadEngine{renderer: &countingRenderer{}}
+			mutations := cad.replaceResourcesMutations(tc.repository,
+				&api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: old}},
+				&api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: tc.new}},
+				nil)
+			_, _, err := mutations[0].Apply(context.Background(), repository.PackageResources{Contents: old})
+
+			if tc.wantError == "" {
+				if err != nil {
+					t.Fatalf("update failed: %v", err)
+				}
+				return
+			}
+			var requiredErr *RequiredFileError
+			if !errors.As(err, &requiredErr) {
+				t.Fatalf("update returned %v; want a RequiredFileError", err)
+			}
+			if requiredErr.File != tc.wantFile {
+				t.Errorf("unexpected missing file %q; want %q", requiredErr.File, tc.wantFile)
+			}
+			if !strings.Contains(err.Error(), tc.wantError) {
+				t.Errorf("error %q doesn't contain %q", err, tc.wantError)
+			}
+			if !apierrors.IsInvalid(err) {
+				t.Errorf("RequiredFileError isn't an Invalid status error")
+			}
+		})
+	}
+}
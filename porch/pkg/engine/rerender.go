@@ -0,0 +1,159 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+// maxConcurrentRerenders bounds the number of drafts RerenderAllDrafts renders concurrently.
+const maxConcurrentRerenders = 4
+
+// RerenderStatus is the outcome of re-rendering a draft.
+type RerenderStatus string
+
+const (
+	// RerenderChanged means rendering changed the resources, and the draft was updated.
+	RerenderChanged RerenderStatus = "Changed"
+	// RerenderUnchanged means rendering didn't change the resources.
+	RerenderUnchanged RerenderStatus = "Unchanged"
+	// RerenderFailed means the draft couldn't be rendered or updated.
+	RerenderFailed RerenderStatus = "Failed"
+)
+
+// RerenderResult is the outcome of re-rendering one draft.
+type RerenderResult struct {
+	// Name is the name of the draft package revision.
+	Name string `json:"name"`
+	// Status is the outcome of re-rendering the draft.
+	Status RerenderStatus `json:"status"`
+	// Error describes why re-rendering failed.
+	Error string `json:"error,omitempty"`
+}
+
+// RerenderAllDrafts renders every draft of the repository again, for example to pick up the
+// changed behavior of an upgraded function, and updates the drafts whose resources changed.
+// A failure to re-render one draft doesn't stop the others; it is reported in its result.
+// Proposed and published package revisions are left as they are.
+func (cad *cadEngine) RerenderAllDrafts(ctx context.Context, repositoryObj *configapi.Repository) ([]RerenderResult, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::RerenderAllDrafts", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	repo, err := cad.cache.OpenRepository(ctx, repositoryObj)
+	if err != nil {
+		return nil, err
+	}
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	repoRevisions := make([]repository.PackageRevision, 0, len(revisions))
+	annotations := map[string]map[string]string{}
+	for _, rev := range revisions {
+		repoRevisions = append(repoRevisions, rev.repoPackageRevision)
+		annotations[rev.KubeObjectName()] = rev.packageRevisionMeta.Annotations
+	}
+	return cad.rerenderDrafts(ctx, repo, repoRevisions, annotations), nil
+}
+
+// rerenderDrafts re-renders the drafts among revisions, up to maxConcurrentRerenders at a
+// time, and returns their results in the same order. Each draft is rendered with the
+// toolchain version and pipeline overlay of its annotations in annotations, by name.
+func (cad *cadEngine) rerenderDrafts(ctx context.Context, repo repository.Repository, revisions []repository.PackageRevision, annotations map[string]map[string]string) []RerenderResult {
+	var drafts []repository.PackageRevision
+	for _, rev := range revisions {
+		if rev.Lifecycle() == api.PackageRevisionLifecycleDraft {
+			drafts = append(drafts, rev)
+		}
+	}
+
+	results := make([]RerenderResult, len(drafts))
+	slots := make(chan struct{}, maxConcurrentRerenders)
+	var wg sync.WaitGroup
+	for i, draft := range drafts {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, draft repository.PackageRevision) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			ctx := withPackageLogger(ctx, draft.Key())
+			result := RerenderResult{Name: draft.KubeObjectName(), Status: RerenderUnchanged}
+			changed, err := cad.rerenderDraft(ctx, repo, draft, annotations[draft.KubeObjectName()])
+			switch {
+			case err != nil:
+				klog.FromContext(ctx).Error(err, "Failed to re-render draft", "name", result.Name)
+				result.Status, result.Error = RerenderFailed, err.Error()
+			case changed:
+				result.Status = RerenderChanged
+			}
+			results[i] = result
+		}(i, draft)
+	}
+	wg.Wait()
+	return results
+}
+
+// rerenderDraft renders the draft and, if its resources changed, stores them. It returns
+// whether the resources changed.
+func (cad *cadEngine) rerenderDraft(ctx context.Context, repo repository.Repository, rev repository.PackageRevision, annotations map[string]string) (bool, error) {
+	apiResources, err := rev.GetResources(ctx)
+	if err != nil {
+		return false, fmt.Errorf("cannot get package resources: %w", err)
+	}
+	resources := repository.PackageResources{Contents: apiResources.Spec.Resources, FileModes: apiResources.Spec.FileModes}
+
+	rendered, task, err := cad.applyMutation(ctx, cad.renderMutation(annotations), resources)
+	if err != nil {
+		return false, err
+	}
+	if isRenderDeferred(rendered) {
+		if rendered, _, err = (&renderedConditionMutation{rendered: true}).Apply(ctx, rendered); err != nil {
+			return false, err
+		}
+	}
+	if reflect.DeepEqual(rendered.Contents, resources.Contents) {
+		return false, nil
+	}
+	rendered = keepFileModes(resources, rendered)
+
+	draft, err := repo.UpdatePackageRevision(ctx, rev)
+	if err != nil {
+		return false, err
+	}
+	if err := draft.UpdateResources(ctx, &api.PackageRevisionResources{
+		Spec: api.PackageRevisionResourcesSpec{
+			Resources: rendered.Contents,
+			FileModes: rendered.FileModes,
+		},
+	}, task); err != nil {
+		return false, err
+	}
+	if _, err := cad.closeDraft(ctx, draft); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// upgradedRenderer renders like an upgraded function: it sets the output of configmap.yaml
+// to "new", and fails for packages containing fail.yaml.
+type upgradedRenderer struct {
+	mu                sync.Mutex
+	active, maxActive int
+}
+
+func (r *upgradedRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	r.mu.Lock()
+	r.active++
+	if r.active > r.maxActive {
+		r.maxActive = r.active
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.active--
+		r.mu.Unlock()
+	}()
+
+	if pkg.Exists(path.Join(opts.PkgPath, "fail.yaml")) {
+		return fmt.Errorf("function failed")
+	}
+	file := path.Join(opts.PkgPath, "configmap.yaml")
+	contents, err := pkg.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return pkg.WriteFile(file, []byte(strings.ReplaceAll(string(contents), "output: old", "output: new")))
+}
+
+// draftRepository opens a recordingDraft for every package revision it updates.
+type draftRepository struct {
+	fake.Repository
+
+	mu     sync.Mutex
+	drafts map[string]*recordingDraft
+}
+
+func (r *draftRepository) UpdatePackageRevision(ctx context.Context, rev repository.PackageRevision) (repository.PackageDraft, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	draft := &recordingDraft{}
+	r.drafts[rev.KubeObjectName()] = draft
+	return draft, nil
+}
+
+func TestRerenderAllDrafts(t *testing.T) {
+	revision := func(name string, lifecycle api.PackageRevisionLifecycle, output string, files ...string) repository.PackageRevision {
+		resources := map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		}
+		for _, file := range files {
+			resources[file] = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\ndata:\n  output: %s\n", name, output)
+		}
+		return &fake.PackageRevision{
+			Name:             name,
+			PackageLifecycle: lifecycle,
+			Resources:        &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: resources}},
+		}
+	}
+	revisions := []repository.PackageRevision{
+		revision("drafts-a-v1", api.PackageRevisionLifecycleDraft, "old", "configmap.yaml"),
+		revision("drafts-b-v1", api.PackageRevisionLifecycleDraft, "new", "configmap.yaml"),
+		revision("drafts-c-v1", api.PackageRevisionLifecycleDraft, "old", "configmap.yaml", "fail.yaml"),
+		revision("drafts-d-v1", api.PackageRevisionLifecycleDraft, "old", "configmap.yaml"),
+		revision("drafts-e-v1", api.PackageRevisionLifecycleDraft, "old", "configmap.yaml"),
+		revision("drafts-f-v1", api.PackageRevisionLifecycleDraft, "old", "configmap.yaml"),
+		revision("drafts-g-v1", api.PackageRevisionLifecyclePublished, "old", "configmap.yaml"),
+		revision("drafts-h-v1", api.PackageRevisionLifecycleProposed, "old", "configmap.yaml"),
+	}
+
+	renderer := &upgradedRenderer{}
+	cad := &cadEngine{renderer: renderer}
+	repo := &draftRepository{drafts: map[string]*recordingDraft{}}
+	results := cad.rerenderDrafts(context.Background(), repo, revisions, nil)
+
+	want := []RerenderResult{
+		{Name: "drafts-a-v1", Status: RerenderChanged},
+		{Name: "drafts-b-v1", Status: RerenderUnchanged},
+		{Name: "drafts-c-v1", Status: RerenderFailed, Error: "function failed"},
+		{Name: "drafts-d-v1", Status: RerenderChanged},
+		{Name: "drafts-e-v1", Status: RerenderChanged},
+		{Name: "drafts-f-v1", Status: RerenderChanged},
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Errorf("unexpected results (-want, +got): %s", diff)
+	}
+
+	if got, want := len(repo.drafts), 4; got != want {
+		t.Errorf("updated %d package revisions; want %d", got, want)
+	}
+	for _, name := range []string{"drafts-a-v1", "drafts-d-v1", "drafts-e-v1", "drafts-f-v1"} {
+		draft, found := repo.drafts[name]
+		if !found {
+			t.Errorf("changed draft %q wasn't updated", name)
+			continue
+		}
+		if got := draft.resources["configmap.yaml"]; !strings.Contains(got, "output: new") {
+			t.Errorf("draft %q wasn't updated with the rendered resources:\n%s", name, got)
+		}
+		if len(draft.tasks) != 1 || draft.tasks[0].Type != api.TaskTypeRender {
+			t.Errorf("unexpected tasks of draft %q: %v", name, draft.tasks)
+		}
+	}
+
+	if renderer.maxActive > maxConcurrentRerenders {
+		t.Errorf("rendered %d drafts concurrently; want at most %d", renderer.maxActive, maxConcurrentRerenders)
+	}
+}
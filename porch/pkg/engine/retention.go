@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/mod/semver"
+	"k8s.io/klog/v2"
+)
+
+// EnforceRetention deletes the published package revisions of the repository which its
+// retention policy doesn't keep, and returns their names. In a dry run, the names are
+// returned without deleting anything.
+//
+// Package revisions of the namespace repositories are searched for upstream references;
+// referenced package revisions are always kept.
+func (cad *cadEngine) EnforceRetention(ctx context.Context, repositoryObj *configapi.Repository, namespaceRepositories []configapi.Repository) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::EnforceRetention", trace.WithAttributes())
+	defer span.End()
+	ctx = withRepositoryLogger(ctx, repositoryObj)
+
+	policy := repositoryObj.Spec.Retention
+	if policy == nil {
+		return nil, nil
+	}
+
+	index, err := cad.buildDownstreamIndex(ctx, repositoryObj.Namespace, namespaceRepositories)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []retentionCandidate
+	byName := map[string]*PackageRevision{}
+	for _, rev := range revisions {
+		if rev.repoPackageRevision.Lifecycle() != api.PackageRevisionLifecyclePublished {
+			continue
+		}
+		apiRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, retentionCandidate{
+			name:        rev.KubeObjectName(),
+			key:         rev.repoPackageRevision.Key(),
+			publishedAt: apiRev.Status.PublishedAt.Time,
+			latest:      apiRev.Labels[api.LatestPackageRevisionKey] == api.LatestPackageRevisionValue,
+		})
+		byName[rev.KubeObjectName()] = rev
+	}
+
+	expired := selectExpiredRevisions(policy, candidates, index, time.Now())
+	if policy.DryRun {
+		return expired, nil
+	}
+	var deleted []string
+	for _, name := range expired {
+		if err := cad.DeletePackageRevision(ctx, repositoryObj, byName[name]); err != nil {
+			return deleted, fmt.Errorf("cannot delete package revision %q: %w", name, err)
+		}
+		klog.FromContext(ctx).Info("Deleted package revision per retention policy", "name", name)
+		deleted = append(deleted, name)
+	}
+	return deleted, nil
+}
+
+// retentionCandidate is a published package revision considered by the retention policy.
+type retentionCandidate struct {
+	name        string
+	key         repository.PackageRevisionKey
+	publishedAt time.Time
+	latest      bool
+}
+
+// downstreamIndex holds the package revisions referenced as upstream, by name and by
+// repository, package and revision.
+type downstreamIndex map[string]bool
+
+func (idx downstreamIndex) add(ref *api.PackageRevisionRef) {
+	if ref.Name != "" {
+		idx[ref.Name] = true
+	}
+	if ref.Repository != "" && ref.Package != "" && ref.Revision != "" {
+		idx[downstreamIndexKey(ref.Repository, ref.Package, ref.Revision)] = true
+	}
+}
+
+func (idx downstreamIndex) protects(c *retentionCandidate) bool {
+	return idx[c.name] || idx[downstreamIndexKey(c.key.Repository, c.key.Package, c.key.Revision)]
+}
+
+func downstreamIndexKey(repository, pkg, revision string) string {
+	return fmt.Sprintf("%s/%s@%s", repository, pkg, revision)
+}
+
+// buildDownstreamIndex collects the upstream references of all package revisions of the
+// repositories.
+func (cad *cadEngine) buildDownstreamIndex(ctx context.Context, namespace string, repositories []configapi.Repository) (downstreamIndex, error) {
+	index := downstreamIndex{}
+	for i := range repositories {
+		repositoryObj := &repositories[i]
+		if repositoryObj.Namespace != namespace {
+			continue
+		}
+		revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot list package revisions of repository %s:%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+		}
+		for _, rev := range revisions {
+			apiRev, err := rev.GetPackageRevision(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for j := range apiRev.Spec.Tasks {
+				task := &apiRev.Spec.Tasks[j]
+				switch {
+				case task.Type == api.TaskTypeClone && task.Clone != nil:
+					if task.Clone.Upstream.UpstreamRef != nil {
+						index.add(task.Clone.Upstream.UpstreamRef)
+					}
+					if task.Clone.ResolvedUpstreamRef != nil {
+						index.add(task.Clone.ResolvedUpstreamRef)
+					}
+				case task.Type == api.TaskTypeUpdate && task.Update != nil:
+					if task.Update.Upstream.UpstreamRef != nil {
+						index.add(task.Update.Upstream.UpstreamRef)
+					}
+				}
+			}
+		}
+	}
+	return index, nil
+}
+
+// selectExpiredRevisions returns the names of the published package revisions the policy
+// doesn't keep, sorted. Per package, the latest revision, the KeepLast most recent
+// revisions, revisions younger than MinAge and revisions protected by the index are kept.
+func selectExpiredRevisions(policy *configapi.RetentionPolicy, revisions []retentionCandidate, index downstreamIndex, now time.Time) []string {
+	byPackage := map[string][]*retentionCandidate{}
+	for i := range revisions {
+		c := &revisions[i]
+		byPackage[c.key.Package] = append(byPackage[c.key.Package], c)
+	}
+
+	var expired []string
+	for _, candidates := range byPackage {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return newerRevision(candidates[i], candidates[j])
+		})
+		for i, c := range candidates {
+			switch {
+			case i == 0 || c.latest:
+			case i < policy.KeepLast:
+			case c.publishedAt.IsZero():
+				// The age is unknown; don't delete what may be a recent revision.
+			case policy.MinAge != nil && now.Sub(c.publishedAt) < policy.MinAge.Duration:
+			case index.protects(c):
+			default:
+				expired = append(expired, c.name)
+			}
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+// newerRevision orders package revisions by revision if both are semantic versions, and
+// by publish time otherwise. Package revisions published at the same time are ordered by
+// name, so that the order doesn't depend on the order they are listed in.
+func newerRevision(a, b *retentionCandidate) bool {
+	if semver.IsValid(a.key.Revision) && semver.IsValid(b.key.Revision) {
+		if c := semver.Compare(a.key.Revision, b.key.Revision); c != 0 {
+			return c > 0
+		}
+	}
+	if !a.publishedAt.Equal(b.publishedAt) {
+		return a.publishedAt.After(b.publishedAt)
+	}
+	return a.name > b.name
+}
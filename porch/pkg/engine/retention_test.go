@@ -0,0 +1,127 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectExpiredRevisions(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// Revisions v1..v5 of "app", published a day apart; v5 is a day old.
+	var revisions []retentionCandidate
+	for i := 1; i <= 5; i++ {
+		revision := fmt.Sprintf("v%d", i)
+		revisions = append(revisions, retentionCandidate{
+			name:        "deployments-app-" + revision,
+			key:         repository.PackageRevisionKey{Repository: "deployments", Package: "app", Revision: revision},
+			publishedAt: now.Add(-time.Duration(6-i) * day),
+		})
+	}
+	db := retentionCandidate{
+		name:        "deployments-db-v1",
+		key:         repository.PackageRevisionKey{Repository: "deployments", Package: "db", Revision: "v1"},
+		publishedAt: now.Add(-30 * day),
+	}
+
+	testCases := map[string]struct {
+		policy    configapi.RetentionPolicy
+		revisions []retentionCandidate
+		index     downstreamIndex
+		want      []string
+	}{
+		"keep last": {
+			policy:    configapi.RetentionPolicy{KeepLast: 2},
+			revisions: revisions,
+			want:      []string{"deployments-app-v1", "deployments-app-v2", "deployments-app-v3"},
+		},
+		"keep none retains the latest": {
+			policy:    configapi.RetentionPolicy{},
+			revisions: append([]retentionCandidate{db}, revisions...),
+			want:      []string{"deployments-app-v1", "deployments-app-v2", "deployments-app-v3", "deployments-app-v4"},
+		},
+		"minimum age": {
+			policy:    configapi.RetentionPolicy{KeepLast: 1, MinAge: &metav1.Duration{Duration: 3*day + time.Hour}},
+			revisions: revisions,
+			want:      []string{"deployments-app-v1", "deployments-app-v2"},
+		},
+		"latest is kept regardless of age": {
+			policy:    configapi.RetentionPolicy{MinAge: &metav1.Duration{Duration: time.Hour}},
+			revisions: []retentionCandidate{db},
+		},
+		"referenced by name": {
+			policy:    configapi.RetentionPolicy{KeepLast: 2},
+			revisions: revisions,
+			index:     downstreamIndex{"deployments-app-v2": true},
+			want:      []string{"deployments-app-v1", "deployments-app-v3"},
+		},
+		"referenced by package and revision": {
+			policy:    configapi.RetentionPolicy{KeepLast: 2},
+			revisions: revisions,
+			index:     downstreamIndex{downstreamIndexKey("deployments", "app", "v1"): true},
+			want:      []string{"deployments-app-v2", "deployments-app-v3"},
+		},
+		"unknown publish time": {
+			policy: configapi.RetentionPolicy{},
+			revisions: append(revisions[3:5:5], retentionCandidate{
+				name: "deployments-app-v0",
+				key:  repository.PackageRevisionKey{Repository: "deployments", Package: "app", Revision: "v0"},
+			}),
+			want: []string{"deployments-app-v4"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			candidates := append([]retentionCandidate{}, tc.revisions...)
+			got := selectExpiredRevisions(&tc.policy, candidates, tc.index, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected expired revisions (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDownstreamIndex(t *testing.T) {
+	index := downstreamIndex{}
+	index.add(&api.PackageRevisionRef{Name: "blueprints-app-v1"})
+	index.add(&api.PackageRevisionRef{Repository: "blueprints", Package: "db", Revision: "v2"})
+	// References to the latest revision don't protect a specific revision.
+	index.add(&api.PackageRevisionRef{Repository: "blueprints", Package: "web"})
+
+	for _, tc := range []struct {
+		candidate retentionCandidate
+		want      bool
+	}{
+		{retentionCandidate{name: "blueprints-app-v1", key: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: "v1"}}, true},
+		{retentionCandidate{name: "blueprints-db-v2", key: repository.PackageRevisionKey{Repository: "blueprints", Package: "db", Revision: "v2"}}, true},
+		{retentionCandidate{name: "blueprints-db-v1", key: repository.PackageRevisionKey{Repository: "blueprints", Package: "db", Revision: "v1"}}, false},
+		{retentionCandidate{name: "blueprints-web-v1", key: repository.PackageRevisionKey{Repository: "blueprints", Package: "web", Revision: "v1"}}, false},
+	} {
+		if got := index.protects(&tc.candidate); got != tc.want {
+			t.Errorf("protects(%q): got %t, want %t", tc.candidate.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	v1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceSampler samples the traces of Porch. Traces of mutating operations are always
+// sampled, as they are rare and worth keeping; traces of reads, which include the high
+// volume list and watch operations, are sampled at a configurable rate. A read which is
+// part of a trace started by the client follows the sampling decision of the client.
+type TraceSampler struct {
+	// readRate holds the bits of the float64 sampling rate of reads.
+	readRate uint64
+}
+
+var _ sdktrace.Sampler = &TraceSampler{}
+
+// DefaultTraceSampler is the sampler of the tracer provider of the Porch server.
+var DefaultTraceSampler = NewTraceSampler(1)
+
+// NewTraceSampler returns a sampler sampling reads at the rate, between 0 and 1.
+func NewTraceSampler(readRate float64) *TraceSampler {
+	s := &TraceSampler{}
+	s.SetReadSampleRate(readRate)
+	return s
+}
+
+// SetReadSampleRate sets the rate, between 0 and 1, at which reads are sampled.
+func (s *TraceSampler) SetReadSampleRate(rate float64) {
+	atomic.StoreUint64(&s.readRate, math.Float64bits(rate))
+}
+
+// ReadSampleRate returns the rate at which reads are sampled.
+func (s *TraceSampler) ReadSampleRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.readRate))
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *TraceSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if !isReadSpan(p) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	if parent := trace.SpanContextFromContext(p.ParentContext); parent.IsValid() {
+		decision := sdktrace.Drop
+		if parent.IsSampled() {
+			decision = sdktrace.RecordAndSample
+		}
+		return sdktrace.SamplingResult{Decision: decision, Tracestate: parent.TraceState()}
+	}
+	return sdktrace.TraceIDRatioBased(s.ReadSampleRate()).ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler.
+func (s *TraceSampler) Description() string {
+	return fmt.Sprintf("PorchTraceSampler{readRate:%g}", s.ReadSampleRate())
+}
+
+// isReadSpan returns true if the span is a read: either a server span of a GET or HEAD
+// request, or a span of a List, Get or Watch operation.
+func isReadSpan(p sdktrace.SamplingParameters) bool {
+	for _, attr := range p.Attributes {
+		if attr.Key == semconv.HTTPMethodKey {
+			method := attr.Value.AsString()
+			return method == "GET" || method == "HEAD"
+		}
+	}
+	operation := p.Name
+	if i := strings.LastIndex(operation, "::"); i >= 0 {
+		operation = operation[i+2:]
+	}
+	for _, prefix := range []string{"List", "Get", "Watch"} {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tracingRuntime adds events marking the start and end of each function to the span of
+// the render, so expensive functions stand out in its trace.
+type tracingRuntime struct {
+	runtime fn.FunctionRuntime
+}
+
+var _ fn.FunctionRuntime = &tracingRuntime{}
+
+func (r *tracingRuntime) GetRunner(ctx context.Context, function *v1.Function) (fn.FunctionRunner, error) {
+	runner, err := r.runtime.GetRunner(ctx, function)
+	if err != nil || runner == nil {
+		return runner, err
+	}
+	return &tracingRunner{ctx: ctx, image: function.Image, runner: runner}, nil
+}
+
+type tracingRunner struct {
+	ctx    context.Context
+	image  string
+	runner fn.FunctionRunner
+}
+
+func (r *tracingRunner) Run(in io.Reader, out io.Writer) error {
+	span := trace.SpanFromContext(r.ctx)
+	image := attribute.String("image", r.image)
+	span.AddEvent("function started", trace.WithAttributes(image))
+	err := r.runner.Run(in, out)
+	span.AddEvent("function finished", trace.WithAttributes(image, attribute.Bool("failed", err != nil)))
+	return err
+}
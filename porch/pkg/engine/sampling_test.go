@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceSampler(t *testing.T) {
+	const spans = 200
+
+	for _, tc := range []struct {
+		name      string
+		rate      float64
+		wantReads func(n int) bool
+	}{
+		{name: "never", rate: 0, wantReads: func(n int) bool { return n == 0 }},
+		{name: "always", rate: 1, wantReads: func(n int) bool { return n == spans }},
+		// The trace IDs are random; allow for a wide margin.
+		{name: "half", rate: 0.5, wantReads: func(n int) bool { return n > spans/4 && n < spans*3/4 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(NewTraceSampler(tc.rate)),
+				sdktrace.WithSyncer(exporter),
+			)
+			tracer := provider.Tracer("test")
+
+			for i := 0; i < spans; i++ {
+				for _, name := range []string{
+					"cadEngine::ListPackageRevisions",
+					"cadEngine::CreatePackageRevision",
+				} {
+					_, span := tracer.Start(context.Background(), name)
+					span.End()
+				}
+				for _, method := range []string{"GET", "POST"} {
+					_, span := tracer.Start(context.Background(), "porch", trace.WithAttributes(semconv.HTTPMethodKey.String(method)))
+					span.End()
+				}
+			}
+
+			counts := map[string]int{}
+			for _, span := range exporter.GetSpans() {
+				name := span.Name
+				for _, attr := range span.Attributes {
+					if attr.Key == semconv.HTTPMethodKey {
+						name += " " + attr.Value.AsString()
+					}
+				}
+				counts[name]++
+			}
+
+			for _, mutation := range []string{"cadEngine::CreatePackageRevision", "porch POST"} {
+				if got := counts[mutation]; got != spans {
+					t.Errorf("sampled %d of %d %s spans; want all", got, spans, mutation)
+				}
+			}
+			for _, read := range []string{"cadEngine::ListPackageRevisions", "porch GET"} {
+				if got := counts[read]; !tc.wantReads(got) {
+					t.Errorf("sampled %d of %d %s spans at rate %g", got, spans, read, tc.rate)
+				}
+			}
+		})
+	}
+}
+
+func TestTraceSamplerFollowsParent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewTraceSampler(0)),
+		sdktrace.WithSyncer(exporter),
+	)
+	tracer := provider.Tracer("test")
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	_, span := tracer.Start(ctx, "cadEngine::ListPackageRevisions")
+	span.End()
+
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Errorf("sampled %d reads of a sampled client trace; want 1", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RepositoryNotSharedError is returned when a package refers to a package revision of a
+// repository in another namespace which isn't shared with the namespace of the package.
+//
+// RepositoryNotSharedError is an API status error: it is returned to clients as a
+// Forbidden status.
+type RepositoryNotSharedError struct {
+	// Repository is the namespace and name of the referenced repository.
+	Repository string
+	// Namespace is the namespace of the referencing package.
+	Namespace string
+	// Reason describes why the reference is rejected.
+	Reason string
+}
+
+var _ apierrors.APIStatus = &RepositoryNotSharedError{}
+
+func (e *RepositoryNotSharedError) Error() string {
+	return fmt.Sprintf("repository %s is not shared with namespace %q: %s", e.Repository, e.Namespace, e.Reason)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *RepositoryNotSharedError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: configapi.GroupVersion.Group,
+			Kind:  "repositories",
+			Name:  e.Repository,
+		},
+	}
+}
+
+// IsRepositorySharedWith returns true if the published package revisions of repositoryObj
+// are shared with the namespace. If the repository selects the namespaces it is shared
+// with by their labels, the namespace is read with the resolver.
+//
+// A repository isn't shared with its own namespace, where its packages are available
+// without sharing.
+func IsRepositorySharedWith(ctx context.Context, resolver ReferenceResolver, repositoryObj *configapi.Repository, namespace string) (bool, error) {
+	sharedWith := repositoryObj.Spec.SharedWith
+	if repositoryObj.Spec.Shared != configapi.RepositorySharingReadOnly || sharedWith == nil {
+		return false, nil
+	}
+	for _, ns := range sharedWith.Namespaces {
+		if ns == "*" || ns == namespace {
+			return true, nil
+		}
+	}
+	if sharedWith.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sharedWith.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector of repository %s/%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+	}
+	var namespaceObj corev1.Namespace
+	if err := resolver.ResolveReference(ctx, "", namespace, &namespaceObj); err != nil {
+		return false, fmt.Errorf("cannot get namespace %q: %w", namespace, err)
+	}
+	return selector.Matches(labels.Set(namespaceObj.Labels)), nil
+}
+
+// checkRepositoryShared returns a RepositoryNotSharedError unless repositoryObj, of
+// another namespace, is shared with the namespace.
+func checkRepositoryShared(ctx context.Context, resolver ReferenceResolver, repositoryObj *configapi.Repository, namespace string) error {
+	shared, err := IsRepositorySharedWith(ctx, resolver, repositoryObj, namespace)
+	if err != nil {
+		return err
+	}
+	if !shared {
+		return &RepositoryNotSharedError{
+			Repository: repositoryObj.Namespace + "/" + repositoryObj.Name,
+			Namespace:  namespace,
+			Reason:     "the repository doesn't share its package revisions with the namespace",
+		}
+	}
+	return nil
+}
+
+// validateRepositorySharing checks the sharing fields of the repository spec.
+func validateRepositorySharing(spec *configapi.RepositorySpec) error {
+	switch spec.Shared {
+	case "":
+		if spec.SharedWith != nil {
+			return &RepositoryConfigError{Field: "spec.sharedWith", Reason: "requires spec.shared to be set"}
+		}
+		return nil
+	case configapi.RepositorySharingReadOnly:
+	default:
+		return &RepositoryConfigError{Field: "spec.shared", Reason: fmt.Sprintf("unsupported sharing %q; must be %q", spec.Shared, configapi.RepositorySharingReadOnly)}
+	}
+	if spec.SharedWith == nil || (len(spec.SharedWith.Namespaces) == 0 && spec.SharedWith.NamespaceSelector == nil) {
+		return &RepositoryConfigError{Field: "spec.sharedWith", Reason: "namespaces or namespaceSelector is required when the repository is shared"}
+	}
+	if selector := spec.SharedWith.NamespaceSelector; selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return &RepositoryConfigError{Field: "spec.sharedWith.namespaceSelector", Reason: err.Error()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespacedResolver resolves repositories by namespace and name, and namespaces by name.
+type namespacedResolver struct {
+	repositories map[string]*configapi.Repository
+	namespaces   map[string]map[string]string
+}
+
+func (f *namespacedResolver) ResolveReference(ctx context.Context, namespace, name string, result Object) error {
+	switch result := result.(type) {
+	case *configapi.Repository:
+		repositoryObj, found := f.repositories[namespace+"/"+name]
+		if !found {
+			return fmt.Errorf("repository %s/%s not found", namespace, name)
+		}
+		repositoryObj.DeepCopyInto(result)
+	case *corev1.Namespace:
+		labels, found := f.namespaces[name]
+		if !found {
+			return fmt.Errorf("namespace %q not found", name)
+		}
+		result.Name, result.Labels = name, labels
+	default:
+		return fmt.Errorf("unexpected object %T", result)
+	}
+	return nil
+}
+
+func TestFetchSharedRevision(t *testing.T) {
+	ctx := context.Background()
+	catalog := func(sharedWith *configapi.RepositorySharedWith) *configapi.Repository {
+		repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "platform"}}
+		if sharedWith != nil {
+			repositoryObj.Spec.Shared = configapi.RepositorySharingReadOnly
+			repositoryObj.Spec.SharedWith = sharedWith
+		}
+		return repositoryObj
+	}
+	revision := func(name string, lifecycle api.PackageRevisionLifecycle) *fake.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               name,
+			Namespace:          "platform",
+			PackageRevisionKey: repository.PackageRevisionKey{Repository: "catalog", Package: "app", Revision: "v1"},
+			PackageLifecycle:   lifecycle,
+		}
+	}
+	repo := &fake.Repository{PackageRevisions: []repository.PackageRevision{
+		revision("catalog-v1", api.PackageRevisionLifecyclePublished),
+		revision("catalog-draft", api.PackageRevisionLifecycleDraft),
+	}}
+	published := &api.PackageRevisionRef{Name: "catalog-v1", Namespace: "platform"}
+
+	for _, tc := range []struct {
+		name       string
+		sharedWith *configapi.RepositorySharedWith
+		ref        *api.PackageRevisionRef
+		forbidden  bool
+	}{
+		{
+			name:       "allowed namespace",
+			sharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"team-a"}},
+			ref:        published,
+		},
+		{
+			name:       "wildcard",
+			sharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"*"}},
+			ref:        published,
+		},
+		{
+			name: "namespace selector",
+			sharedWith: &configapi.RepositorySharedWith{NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"platform.example.com/consumer": "true"},
+			}},
+			ref: published,
+		},
+		{
+			name:      "not shared",
+			ref:       published,
+			forbidden: true,
+		},
+		{
+			name:       "other namespace",
+			sharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"team-b"}},
+			ref:        published,
+			forbidden:  true,
+		},
+		{
+			name: "namespace not selected",
+			sharedWith: &configapi.RepositorySharedWith{NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"platform.example.com/consumer": "false"},
+			}},
+			ref:       published,
+			forbidden: true,
+		},
+		{
+			name:       "draft",
+			sharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"*"}},
+			ref:        &api.PackageRevisionRef{Name: "catalog-draft", Namespace: "platform"},
+			forbidden:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := &PackageFetcher{
+				repoOpener: &fakeRepositoryOpener{repository: repo},
+				referenceResolver: &namespacedResolver{
+					repositories: map[string]*configapi.Repository{"platform/catalog": catalog(tc.sharedWith)},
+					namespaces:   map[string]map[string]string{"team-a": {"platform.example.com/consumer": "true"}},
+				},
+			}
+			rev, err := fetcher.FetchRevision(ctx, tc.ref, "team-a")
+			if !tc.forbidden {
+				if err != nil {
+					t.Fatalf("FetchRevision failed: %v", err)
+				}
+				if got := rev.KubeObjectName(); got != tc.ref.Name {
+					t.Errorf("fetched %q; want %q", got, tc.ref.Name)
+				}
+				return
+			}
+
+			var notShared *RepositoryNotSharedError
+			if !errors.As(err, &notShared) {
+				t.Fatalf("got error %v; want a RepositoryNotSharedError", err)
+			}
+			if !apierrors.IsForbidden(err) || notShared.Status().Code != http.StatusForbidden {
+				t.Errorf("error isn't reported as forbidden: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRepositorySharing(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		spec  configapi.RepositorySpec
+		field string
+	}{
+		{
+			name: "not shared",
+		},
+		{
+			name: "shared",
+			spec: configapi.RepositorySpec{
+				Shared:     configapi.RepositorySharingReadOnly,
+				SharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"team-a"}},
+			},
+		},
+		{
+			name:  "unsupported sharing",
+			spec:  configapi.RepositorySpec{Shared: "readWrite", SharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"*"}}},
+			field: "spec.shared",
+		},
+		{
+			name:  "no namespaces",
+			spec:  configapi.RepositorySpec{Shared: configapi.RepositorySharingReadOnly},
+			field: "spec.sharedWith",
+		},
+		{
+			name:  "namespaces without sharing",
+			spec:  configapi.RepositorySpec{SharedWith: &configapi.RepositorySharedWith{Namespaces: []string{"*"}}},
+			field: "spec.sharedWith",
+		},
+		{
+			name: "invalid selector",
+			spec: configapi.RepositorySpec{
+				Shared: configapi.RepositorySharingReadOnly,
+				SharedWith: &configapi.RepositorySharedWith{NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Near"}},
+				}},
+			},
+			field: "spec.sharedWith.namespaceSelector",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRepositorySharing(&tc.spec)
+			if tc.field == "" {
+				if err != nil {
+					t.Errorf("validateRepositorySharing failed: %v", err)
+				}
+				return
+			}
+			var configErr *RepositoryConfigError
+			if !errors.As(err, &configErr) || configErr.Field != tc.field {
+				t.Errorf("got error %v; want an error of field %s", err, tc.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/internal/pkgcontents"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+// resourceSnapshot reads the resources of a package revision at most once per request,
+// so that the steps of the request share one consistent view of the package. Reading
+// the resources is a full content read from the repository.
+type resourceSnapshot struct {
+	rev repository.PackageRevision
+
+	loaded    bool
+	resources repository.PackageResources
+	err       error
+}
+
+func newResourceSnapshot(rev repository.PackageRevision) *resourceSnapshot {
+	return &resourceSnapshot{rev: rev}
+}
+
+// Resources returns the resources of the package revision, reading them on first use.
+func (s *resourceSnapshot) Resources(ctx context.Context) (repository.PackageResources, error) {
+	if !s.loaded {
+		s.loaded = true
+		apiResources, err := s.rev.GetResources(ctx)
+		if err != nil {
+			s.err = fmt.Errorf("cannot get package resources: %w", err)
+		} else {
+			s.resources = repository.PackageResources{Contents: apiResources.Spec.Resources, FileModes: apiResources.Spec.FileModes}
+		}
+	}
+	return s.resources, s.err
+}
+
+// Kptfile returns the root Kptfile of the package revision, decoded from its resources.
+func (s *resourceSnapshot) Kptfile(ctx context.Context) (kptfile.KptFile, error) {
+	resources, err := s.Resources(ctx)
+	if err != nil {
+		return kptfile.KptFile{}, err
+	}
+	return pkgcontents.Contents(resources.Contents).GetKptfile()
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// countingPackageRevision counts the reads of its contents.
+type countingPackageRevision struct {
+	*fake.PackageRevision
+	reads int
+}
+
+func (r *countingPackageRevision) GetResources(ctx context.Context) (*api.PackageRevisionResources, error) {
+	r.reads++
+	return r.PackageRevision.GetResources(ctx)
+}
+
+func (r *countingPackageRevision) GetKptfile(ctx context.Context) (kptfile.KptFile, error) {
+	r.reads++
+	return r.PackageRevision.GetKptfile(ctx)
+}
+
+// countingRepository opens drafts which close to the package revision they update, and
+// records them.
+type countingRepository struct {
+	fake.Repository
+	draft *recordingDraft
+}
+
+type closingDraft struct {
+	*recordingDraft
+	rev    repository.PackageRevision
+	closed bool
+}
+
+func (d *closingDraft) Close(ctx context.Context) (repository.PackageRevision, error) {
+	d.closed = true
+	return d.rev, nil
+}
+
+func (r *countingRepository) UpdatePackageRevision(ctx context.Context, rev repository.PackageRevision) (repository.PackageDraft, error) {
+	r.draft = &recordingDraft{}
+	return &closingDraft{recordingDraft: r.draft, rev: rev}, nil
+}
+
+func TestUpdatePackageRevisionReadsResourcesOnce(t *testing.T) {
+	ctx := context.Background()
+	rev := &countingPackageRevision{PackageRevision: &fake.PackageRevision{
+		Name:             "blueprints-1234",
+		Namespace:        "default",
+		PackageLifecycle: api.PackageRevisionLifecycleDraft,
+		Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+			kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+			"configmap.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+		}}},
+	}}
+	pkgRevMeta := meta.PackageRevisionMeta{Name: rev.Name, Namespace: rev.Namespace}
+	cad := &cadEngine{
+		renderer:      &countingRenderer{},
+		metadataStore: &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}},
+	}
+
+	// Adding a readiness gate patches the Kptfile and updates the draft contents.
+	oldObj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: rev.Name, Namespace: rev.Namespace},
+		Spec:       api.PackageRevisionSpec{PackageName: "app", Lifecycle: api.PackageRevisionLifecycleDraft},
+	}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.ReadinessGates = []api.ReadinessGate{{ConditionType: "Tested"}}
+
+	repo := &countingRepository{}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	oldPackage := &PackageRevision{repoPackageRevision: rev, packageRevisionMeta: pkgRevMeta}
+	if _, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, nil); err != nil {
+		t.Fatalf("updatePackageRevision failed: %v", err)
+	}
+
+	if rev.reads != 1 {
+		t.Errorf("read the contents of the package revision %d times; want 1", rev.reads)
+	}
+	if got := repo.draft.resources[kptfile.KptFileName]; !strings.Contains(got, "conditionType: Tested") {
+		t.Errorf("Kptfile wasn't patched:\n%s", got)
+	}
+	if _, found := repo.draft.resources["configmap.yaml"]; !found {
+		t.Errorf("draft lost the resources of the package revision: %v", repo.draft.resources)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// StagedUploader returns an uploader staging chunks of new contents of the draft package
+// revision, for FinalizeStagedResources.
+func (cad *cadEngine) StagedUploader(pkgRev *PackageRevision) (staging.Uploader, error) {
+	if cad.stagingStore == nil {
+		return nil, apierrors.NewMethodNotSupported(api.PackageRevisionResourcesGVR.GroupResource(), "staged upload")
+	}
+	if lifecycle := pkgRev.Lifecycle(); lifecycle != api.PackageRevisionLifecycleDraft {
+		return nil, notDraftConflict(pkgRev.KubeObjectName(), lifecycle, "stage resources of")
+	}
+	return cad.stagingStore.Uploader(stagingKey(pkgRev)), nil
+}
+
+// FinalizeStagedResources replaces the resources of the draft package revision with
+// the files of the manifest, assembled from the chunks staged for it. The staged chunks
+// are discarded once the resources are updated.
+func (cad *cadEngine) FinalizeStagedResources(ctx context.Context, repositoryObj *configapi.Repository, oldPackage *PackageRevision, manifest staging.Manifest) (*PackageRevision, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::FinalizeStagedResources", trace.WithAttributes())
+	defer span.End()
+
+	old, new, err := cad.stagedResources(ctx, oldPackage, manifest)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := cad.UpdatePackageResources(ctx, repositoryObj, oldPackage, old, new)
+	if err != nil {
+		return nil, err
+	}
+	if err := cad.stagingStore.Discard(stagingKey(oldPackage)); err != nil {
+		// The staged chunks are garbage-collected once their TTL expires.
+		klog.Warningf("cannot discard staged chunks of package revision %q: %v", oldPackage.KubeObjectName(), err)
+	}
+	return updated, nil
+}
+
+// stagedResources returns the current resources of the package revision, and the
+// resources assembled from the staged chunks of the manifest.
+func (cad *cadEngine) stagedResources(ctx context.Context, oldPackage *PackageRevision, manifest staging.Manifest) (*api.PackageRevisionResources, *api.PackageRevisionResources, error) {
+	if cad.stagingStore == nil {
+		return nil, nil, apierrors.NewMethodNotSupported(api.PackageRevisionResourcesGVR.GroupResource(), "staged upload")
+	}
+	files, err := cad.stagingStore.Assemble(stagingKey(oldPackage), manifest)
+	var missingErr *staging.MissingChunksError
+	if errors.As(err, &missingErr) {
+		// The client resumes the upload by staging the missing chunks.
+		return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("cannot finalize package revision %q: %v", oldPackage.KubeObjectName(), missingErr))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot assemble staged resources of package revision %q: %w", oldPackage.KubeObjectName(), err)
+	}
+	old, err := oldPackage.GetResources(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get package resources: %w", err)
+	}
+	new := old.DeepCopy()
+	new.Spec.Resources = files
+	return old, new, nil
+}
+
+func stagingKey(pkgRev *PackageRevision) string {
+	return pkgRev.repoPackageRevision.KubeObjectNamespace() + "/" + pkgRev.KubeObjectName()
+}
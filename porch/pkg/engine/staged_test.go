@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestStagedResources(t *testing.T) {
+	ctx := context.Background()
+	store, err := staging.NewStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	cad := &cadEngine{stagingStore: store}
+	pkgRev := &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+		Name:             "deployments-1234",
+		Namespace:        "default",
+		PackageLifecycle: api.PackageRevisionLifecycleDraft,
+		Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{
+			PackageName: "app",
+			Resources:   map[string]string{"Kptfile": "kind: Kptfile\n"},
+		}},
+	}}
+
+	files := map[string]string{
+		"Kptfile":        "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+	}
+	manifestFiles, chunks := api.SplitResources(files, 16)
+	manifest := staging.Manifest{Files: manifestFiles}
+
+	published := &PackageRevision{repoPackageRevision: &fake.PackageRevision{PackageLifecycle: api.PackageRevisionLifecyclePublished}}
+	if _, err := cad.StagedUploader(published); !apierrors.IsConflict(err) {
+		t.Errorf("StagedUploader returned %v for a published package revision; want a Conflict error", err)
+	}
+
+	uploader, err := cad.StagedUploader(pkgRev)
+	if err != nil {
+		t.Fatalf("StagedUploader failed: %v", err)
+	}
+	// Stage all chunks but one.
+	digests := manifest.Digests()
+	for _, digest := range digests[1:] {
+		if err := uploader.Put(ctx, digest, chunks[digest]); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	if _, _, err := cad.stagedResources(ctx, pkgRev, manifest); !apierrors.IsBadRequest(err) {
+		t.Fatalf("assembling resources with a missing chunk returned %v; want a BadRequest error", err)
+	}
+
+	if _, err := staging.Upload(ctx, uploader, manifest, chunks); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	old, new, err := cad.stagedResources(ctx, pkgRev, manifest)
+	if err != nil {
+		t.Fatalf("stagedResources failed: %v", err)
+	}
+	if diff := cmp.Diff(files, new.Spec.Resources); diff != "" {
+		t.Errorf("unexpected staged resources (-want, +got): %s", diff)
+	}
+	if new.Spec.PackageName != "app" || len(old.Spec.Resources) != 1 {
+		t.Errorf("staged resources don't replace the resources of the package revision: old %v, new %v", old.Spec, new.Spec)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// StarterResources are the starter resources init tasks can add to packages, by name.
+// Each is a text/template of a YAML file executed with StarterVars; it is added to the
+// package as <name>.yaml.
+type StarterResources map[string]string
+
+// StarterVars are the values available to the templates of StarterResources.
+type StarterVars struct {
+	// Package is the last segment of the package name.
+	Package string
+	// PackagePath is the package name.
+	PackagePath string
+}
+
+// DefaultStarterResources returns the starter resources available unless others are
+// configured: a namespace and a resource quota named after the package.
+func DefaultStarterResources() StarterResources {
+	return StarterResources{
+		"namespace": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{.Package}}
+`,
+		"resourcequota": `apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: default
+  namespace: {{.Package}}
+spec:
+  hard:
+    cpu: "40"
+    memory: 40G
+`,
+	}
+}
+
+// LoadStarterResources reads starter resources from the YAML files of a directory; the
+// name of a starter resource is the name of its file without the extension.
+func LoadStarterResources(dir string) (StarterResources, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	starters := StarterResources{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		starters[strings.TrimSuffix(entry.Name(), ext)] = string(data)
+	}
+	return starters, nil
+}
+
+// parseStarterResources parses the templates of the starter resources.
+func parseStarterResources(starters StarterResources) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(starters))
+	for name, text := range starters {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid starter resource %q: %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// validateInitStarters validates that the init tasks of a package revision being created
+// only select configured starter resources.
+func (cad *cadEngine) validateInitStarters(obj *api.PackageRevision) error {
+	var errs field.ErrorList
+	for i, task := range obj.Spec.Tasks {
+		if task.Type != api.TaskTypeInit || task.Init == nil {
+			continue
+		}
+		for j, name := range task.Init.Starters {
+			if _, found := cad.starterResources[name]; !found {
+				errs = append(errs, field.NotSupported(field.NewPath("spec", "tasks").Index(i).Child("init", "starters").Index(j), name, cad.starterResourceNames()))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, errs)
+	}
+	return nil
+}
+
+// starterResourceNames returns the sorted names of the configured starter resources.
+func (cad *cadEngine) starterResourceNames() []string {
+	names := make([]string, 0, len(cad.starterResources))
+	for name := range cad.starterResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderStarters generates the starter resources selected by name for the package.
+func renderStarters(templates map[string]*template.Template, names []string, packageName string) (map[string]string, error) {
+	vars := StarterVars{
+		Package:     path.Base(packageName),
+		PackagePath: packageName,
+	}
+	files := make(map[string]string, len(names))
+	for _, name := range names {
+		tmpl, found := templates[name]
+		if !found {
+			return nil, fmt.Errorf("unknown starter resource %q", name)
+		}
+		var contents strings.Builder
+		if err := tmpl.Execute(&contents, vars); err != nil {
+			return nil, fmt.Errorf("cannot generate starter resource %q: %w", name, err)
+		}
+		files[name+".yaml"] = contents.String()
+	}
+	return files, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// taskSpecFields are the fields of a task holding the specification of each task type.
+var taskSpecFields = map[api.TaskType]string{
+	api.TaskTypeInit:   "init",
+	api.TaskTypeClone:  "clone",
+	api.TaskTypePatch:  "patch",
+	api.TaskTypeEdit:   "edit",
+	api.TaskTypeEval:   "eval",
+	api.TaskTypeUpdate: "update",
+	api.TaskTypeRender: "render",
+}
+
+// validateTaskFields returns an Invalid error for the fields of the tasks of obj which
+// are not part of the API, and for the specifications of task types other than the
+// built-in type of their task, if the engine validates tasks strictly. The unknown
+// fields are recorded in the api.UnknownTaskFieldsAnnotation when obj is decoded; the
+// annotation is removed, as it is never stored.
+func (cad *cadEngine) validateTaskFields(obj *api.PackageRevision) error {
+	unknown := obj.Annotations[api.UnknownTaskFieldsAnnotation]
+	delete(obj.Annotations, api.UnknownTaskFieldsAnnotation)
+	if !cad.strictTaskValidation {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if unknown != "" {
+		for _, path := range strings.Split(unknown, ",") {
+			allErrs = append(allErrs, &field.Error{Type: field.ErrorTypeForbidden, Field: path, Detail: "unknown field"})
+		}
+	}
+	for i := range obj.Spec.Tasks {
+		task := &obj.Spec.Tasks[i]
+		specField, builtin := taskSpecFields[task.Type]
+		if !builtin {
+			// Custom task types define the fields they use themselves.
+			continue
+		}
+		for _, name := range setTaskSpecFields(task) {
+			if name != specField {
+				allErrs = append(allErrs, field.Forbidden(taskPath(obj, task).Child(name),
+					fmt.Sprintf("must not be set for task of type %q", task.Type)))
+			}
+		}
+	}
+	if len(allErrs) > 0 {
+		return invalidPackageRevision(obj, allErrs...)
+	}
+	return nil
+}
+
+// setTaskSpecFields returns the names of the task specification fields set in task.
+func setTaskSpecFields(task *api.Task) []string {
+	var names []string
+	for _, spec := range []struct {
+		name string
+		set  bool
+	}{
+		{"init", task.Init != nil},
+		{"clone", task.Clone != nil},
+		{"patch", task.Patch != nil},
+		{"edit", task.Edit != nil},
+		{"eval", task.Eval != nil},
+		{"update", task.Update != nil},
+		{"render", task.Render != nil},
+	} {
+		if spec.set {
+			names = append(names, spec.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// decodePackageRevision decodes a package revision as the API server does.
+func decodePackageRevision(t *testing.T, data string) *api.PackageRevision {
+	t.Helper()
+	var obj api.PackageRevision
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		t.Fatalf("cannot decode package revision: %v", err)
+	}
+	return &obj
+}
+
+// invalidFields returns the fields of an Invalid error, or nil for no error.
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || !apierrors.IsInvalid(err) {
+		t.Fatalf("got error %v; want an Invalid error", err)
+	}
+	var fields []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+func TestStrictTaskValidationOnCreate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		tasks string
+		want  []string
+	}{
+		"valid": {
+			tasks: `[
+				{"type": "clone", "clone": {"upstreamRef": {"upstreamRef": {"name": "blueprints-abc"}}}},
+				{"type": "eval", "eval": {"image": "render", "config": {"apiVersion": "v1", "kind": "ConfigMap", "anything": "goes"}}}
+			]`,
+		},
+		"misspelled clone field": {
+			tasks: `[{"type": "clone", "clone": {"upstreamRef": {"git": {"repo": "https://example.com/repo.git", "ref": "main", "directorry": "pkg"}}}}]`,
+			want:  []string{"spec.tasks[0].clone.upstreamRef.git.directorry"},
+		},
+		"misspelled eval field": {
+			tasks: `[
+				{"type": "init", "init": {}},
+				{"type": "eval", "eval": {"imge": "gcr.io/kpt-fn/set-namespace:v0.4", "configMap": {"namespace": "foo"}}}
+			]`,
+			want: []string{"spec.tasks[1].eval.imge"},
+		},
+		"misspelled update field": {
+			tasks: `[{"type": "update", "update": {"upstream": {"upstreamRef": {"name": "blueprints-abc"}}}}]`,
+			want:  []string{"spec.tasks[0].update.upstream"},
+		},
+		"spec of another task type": {
+			tasks: `[{"type": "init", "init": {}, "clone": {"upstreamRef": {"upstreamRef": {"name": "blueprints-abc"}}}}]`,
+			want:  []string{"spec.tasks[0].clone"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			obj := decodePackageRevision(t, `{"metadata": {"name": "blueprints-app-v1", "namespace": "default"},
+				"spec": {"packageName": "app", "repository": "blueprints", "tasks": `+tc.tasks+`}}`)
+
+			cad := &cadEngine{strictTaskValidation: true}
+			// The tasks are validated before the repository is opened.
+			err := cad.validateTaskFields(obj)
+			if diff := cmp.Diff(tc.want, invalidFields(t, err)); diff != "" {
+				t.Errorf("unexpected invalid fields (-want, +got): %s", diff)
+			}
+			if tc.want != nil {
+				if _, err := cad.CreatePackageRevision(context.Background(), &configapi.Repository{}, decodePackageRevision(t, `{"spec": {"tasks": `+tc.tasks+`}}`), nil); !apierrors.IsInvalid(err) {
+					t.Errorf("CreatePackageRevision returned %v; want an Invalid error", err)
+				}
+			}
+			if _, found := obj.Annotations[api.UnknownTaskFieldsAnnotation]; found {
+				t.Errorf("annotation %s wasn't removed", api.UnknownTaskFieldsAnnotation)
+			}
+		})
+	}
+}
+
+func TestStrictTaskValidationOnUpdate(t *testing.T) {
+	const oldJSON = `{"metadata": {"name": "blueprints-app-v1", "namespace": "default"},
+		"spec": {"packageName": "app", "lifecycle": "Draft", "tasks": [{"type": "init", "init": {}}]}}`
+
+	for name, tc := range map[string]struct {
+		// newObj returns the updated package revision, as decoded by the API server.
+		newObj func(t *testing.T) *api.PackageRevision
+		want   []string
+	}{
+		"update": {
+			newObj: func(t *testing.T) *api.PackageRevision {
+				return decodePackageRevision(t, `{"metadata": {"name": "blueprints-app-v1", "namespace": "default"},
+					"spec": {"packageName": "app", "lifecycle": "Draft", "tasks": [
+						{"type": "init", "init": {}},
+						{"type": "eval", "eval": {"imge": "gcr.io/kpt-fn/set-namespace:v0.4"}}
+					]}}`)
+			},
+			want: []string{"spec.tasks[1].eval.imge"},
+		},
+		"patch": {
+			newObj: func(t *testing.T) *api.PackageRevision {
+				// The API server decodes the patched object.
+				patched, err := strategicpatch.StrategicMergePatch([]byte(oldJSON),
+					[]byte(`{"spec": {"tasks": [{"type": "update", "update": {"upstreamRef": {"upstreamRf": {"name": "blueprints-abc"}}}}]}}`),
+					api.PackageRevision{})
+				if err != nil {
+					t.Fatalf("cannot patch package revision: %v", err)
+				}
+				return decodePackageRevision(t, string(patched))
+			},
+			want: []string{"spec.tasks[0].update.upstreamRef.upstreamRf"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			oldObj := decodePackageRevision(t, oldJSON)
+			oldPackage := &PackageRevision{}
+
+			cad := &cadEngine{strictTaskValidation: true}
+			_, err := cad.updatePackageRevision(context.Background(), nil, &configapi.Repository{}, oldPackage, oldObj, tc.newObj(t), nil)
+			if diff := cmp.Diff(tc.want, invalidFields(t, err)); diff != "" {
+				t.Errorf("unexpected invalid fields (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestLenientTaskValidation(t *testing.T) {
+	obj := decodePackageRevision(t, `{"metadata": {"annotations": {"team": "a"}},
+		"spec": {"tasks": [{"type": "eval", "eval": {"imge": "render"}}]}}`)
+	if got, want := obj.Annotations[api.UnknownTaskFieldsAnnotation], "spec.tasks[0].eval.imge"; got != want {
+		t.Fatalf("decoding recorded unknown fields %q; want %q", got, want)
+	}
+
+	if err := (&cadEngine{}).validateTaskFields(obj); err != nil {
+		t.Fatalf("validateTaskFields failed: %v", err)
+	}
+	// The unknown fields are dropped, and not stored in an annotation.
+	if diff := cmp.Diff(map[string]string{"team": "a"}, obj.Annotations); diff != "" {
+		t.Errorf("unexpected annotations (-want, +got): %s", diff)
+	}
+}
+
+func TestUnknownTaskFieldsAnnotationIsSetByDecodingOnly(t *testing.T) {
+	obj := decodePackageRevision(t, `{"metadata": {"annotations": {"`+api.UnknownTaskFieldsAnnotation+`": "spec.tasks[0].clone"}},
+		"spec": {"tasks": [{"type": "init", "init": {}}]}}`)
+	if err := (&cadEngine{strictTaskValidation: true}).validateTaskFields(obj); err != nil {
+		t.Errorf("validateTaskFields failed for an annotation set by the client: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	minSyncRetryAfter = 1 * time.Second
+	maxSyncRetryAfter = 30 * time.Second
+)
+
+// RepositorySyncPendingError is returned by reads of a repository whose initial sync
+// hasn't completed yet: until then, a package revision missing from the repository may
+// exist, but not be synced yet.
+//
+// RepositorySyncPendingError is an API status error: it is returned to clients as a
+// ServiceUnavailable status, asking them to retry after RetryAfter.
+type RepositorySyncPendingError struct {
+	Repository string
+	// RetryAfter is the estimated time until the initial sync completes.
+	RetryAfter time.Duration
+}
+
+var _ apierrors.APIStatus = &RepositorySyncPendingError{}
+
+func (e *RepositorySyncPendingError) Error() string {
+	return fmt.Sprintf("repository %q is not synced yet; retry after %s", e.Repository, e.RetryAfter)
+}
+
+// Status implements apierrors.APIStatus.
+func (e *RepositorySyncPendingError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusServiceUnavailable,
+		Reason:  metav1.StatusReasonServiceUnavailable,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group:             configapi.GroupVersion.Group,
+			Kind:              configapi.KindRepository.Resource.Resource,
+			Name:              e.Repository,
+			RetryAfterSeconds: int32(math.Ceil(e.RetryAfter.Seconds())),
+		},
+	}
+}
+
+// syncRetryAfter estimates how long the initial sync will take to complete from its
+// progress: a sync which has been running for some time is expected to need about as
+// long again. The estimate is bounded, so clients neither retry in a tight loop nor
+// wait much longer than needed.
+func syncRetryAfter(progress repository.SyncProgress, now time.Time) time.Duration {
+	if progress.Started.IsZero() {
+		return minSyncRetryAfter
+	}
+	retryAfter := now.Sub(progress.Started).Round(time.Second)
+	if retryAfter < minSyncRetryAfter {
+		return minSyncRetryAfter
+	}
+	if retryAfter > maxSyncRetryAfter {
+		return maxSyncRetryAfter
+	}
+	return retryAfter
+}
+
+// checkRepositorySynced returns a RepositorySyncPendingError if the repository syncs
+// its content in the background and its initial sync hasn't completed. If the engine
+// waits for syncs, it first waits for the initial sync up to the sync wait timeout.
+func (cad *cadEngine) checkRepositorySynced(ctx context.Context, repositoryObj *configapi.Repository, repo repository.Repository) error {
+	reporter, ok := repo.(repository.SyncReporter)
+	if !ok || reporter.SyncProgress().Synced {
+		return nil
+	}
+
+	if cad.syncWaitTimeout > 0 {
+		span := trace.SpanFromContext(ctx)
+		span.AddEvent("repository sync wait started")
+		waitCtx, cancel := context.WithTimeout(ctx, cad.syncWaitTimeout)
+		err := reporter.WaitForSync(waitCtx)
+		cancel()
+		span.AddEvent("repository sync wait finished")
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return &RepositorySyncPendingError{
+		Repository: repositoryObj.Name,
+		RetryAfter: syncRetryAfter(reporter.SyncProgress(), time.Now()),
+	}
+}
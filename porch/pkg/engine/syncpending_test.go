@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncingRepository is a slow repository syncing its content in the background.
+type syncingRepository struct {
+	fake.Repository
+
+	mutex   sync.Mutex
+	started time.Time
+	synced  chan struct{}
+}
+
+var _ repository.SyncReporter = &syncingRepository{}
+
+func newSyncingRepository() *syncingRepository {
+	return &syncingRepository{synced: make(chan struct{})}
+}
+
+func (r *syncingRepository) start(started time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.started = started
+}
+
+func (r *syncingRepository) SyncProgress() repository.SyncProgress {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	select {
+	case <-r.synced:
+		return repository.SyncProgress{Synced: true, Started: r.started}
+	default:
+		return repository.SyncProgress{Started: r.started}
+	}
+}
+
+func (r *syncingRepository) WaitForSync(ctx context.Context) error {
+	select {
+	case <-r.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestCheckRepositorySynced(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+	cad := &cadEngine{}
+	repo := newSyncingRepository()
+
+	// Before the sync starts, clients are asked to retry shortly.
+	err := cad.checkRepositorySynced(ctx, repositoryObj, repo)
+	var pending *RepositorySyncPendingError
+	if !errors.As(err, &pending) {
+		t.Fatalf("got error %v before sync; want RepositorySyncPendingError", err)
+	}
+	if got, want := pending.RetryAfter, time.Second; got != want {
+		t.Errorf("retry after %s before sync; want %s", got, want)
+	}
+	status := pending.Status()
+	if !apierrors.IsServiceUnavailable(err) || status.Details.RetryAfterSeconds != 1 {
+		t.Errorf("got status %+v; want ServiceUnavailable retrying after 1s", status)
+	}
+	if apierrors.IsNotFound(err) {
+		t.Errorf("a repository which isn't synced reported NotFound")
+	}
+
+	// During the sync, the estimate grows with the time the sync has been running.
+	repo.start(time.Now().Add(-10 * time.Second))
+	err = cad.checkRepositorySynced(ctx, repositoryObj, repo)
+	if !errors.As(err, &pending) {
+		t.Fatalf("got error %v during sync; want RepositorySyncPendingError", err)
+	}
+	if got, want := pending.RetryAfter, 10*time.Second; got != want {
+		t.Errorf("retry after %s during sync; want %s", got, want)
+	}
+
+	// Waiting for the sync gives up at the timeout.
+	cad.syncWaitTimeout = 50 * time.Millisecond
+	if err := cad.checkRepositorySynced(ctx, repositoryObj, repo); !errors.As(err, &pending) {
+		t.Errorf("got error %v after sync wait timeout; want RepositorySyncPendingError", err)
+	}
+
+	// Waiting for the sync succeeds once the sync completes.
+	cad.syncWaitTimeout = 10 * time.Second
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(repo.synced)
+	}()
+	if err := cad.checkRepositorySynced(ctx, repositoryObj, repo); err != nil {
+		t.Errorf("waiting for sync failed: %v", err)
+	}
+
+	// After the sync, reads proceed: a missing package revision is genuinely not found.
+	cad.syncWaitTimeout = 0
+	if err := cad.checkRepositorySynced(ctx, repositoryObj, repo); err != nil {
+		t.Errorf("check after sync failed: %v", err)
+	}
+
+	// Repositories which don't sync in the background are always synced.
+	if err := cad.checkRepositorySynced(ctx, repositoryObj, &fake.Repository{}); err != nil {
+		t.Errorf("check of repository without background sync failed: %v", err)
+	}
+}
+
+func TestSyncRetryAfter(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		started time.Time
+		want    time.Duration
+	}{
+		{name: "not started", want: time.Second},
+		{name: "just started", started: now.Add(-100 * time.Millisecond), want: time.Second},
+		{name: "running", started: now.Add(-12 * time.Second), want: 12 * time.Second},
+		{name: "long running", started: now.Add(-5 * time.Minute), want: 30 * time.Second},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := syncRetryAfter(repository.SyncProgress{Started: tc.started}, now); got != tc.want {
+				t.Errorf("syncRetryAfter: got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
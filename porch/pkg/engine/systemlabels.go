@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+)
+
+// systemLabelKeys are the keys of the labels set on package revisions by Porch or their
+// repository, rather than by users. System labels aren't stored with the labels of the
+// package revision metadata, and labels set by users can't override them.
+var systemLabelKeys = map[string]bool{
+	api.LatestPackageRevisionKey: true,
+}
+
+// RegisterSystemLabel protects the label key as a system label. It must be called
+// during initialization, before package revisions are served.
+func RegisterSystemLabel(key string) {
+	systemLabelKeys[key] = true
+}
+
+// isSystemLabel returns true if key is the key of a system label.
+func isSystemLabel(key string) bool {
+	return systemLabelKeys[key]
+}
+
+// mergeLabels returns the labels of a package revision: the labels of the repository
+// package revision merged with the user labels of the package revision metadata. See
+// mergeMetadata.
+func mergeLabels(ctx context.Context, name string, repoLabels, metaLabels map[string]string) map[string]string {
+	return mergeMetadata(ctx, name, "labels", repoLabels, metaLabels, isSystemLabel)
+}
+
+// userLabels returns labels without the system labels, to be stored with the package
+// revision metadata. Clients commonly send back the system labels they read.
+func userLabels(labels map[string]string) map[string]string {
+	var filtered map[string]string
+	for k, v := range labels {
+		if isSystemLabel(k) {
+			continue
+		}
+		if filtered == nil {
+			filtered = make(map[string]string, len(labels))
+		}
+		filtered[k] = v
+	}
+	return filtered
+}
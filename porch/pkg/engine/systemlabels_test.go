@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateLabelsPreservesSystemLabels(t *testing.T) {
+	const repositoryLabel = "example.com/repository-managed"
+	RegisterSystemLabel(repositoryLabel)
+	defer delete(systemLabelKeys, repositoryLabel)
+
+	ctx := context.Background()
+	systemLabels := map[string]string{
+		api.LatestPackageRevisionKey: api.LatestPackageRevisionValue,
+		repositoryLabel:              "blueprints",
+	}
+	repoPkgRev := &fake.PackageRevision{
+		Name:             "blueprints-app-v1",
+		Namespace:        "default",
+		PackageLifecycle: api.PackageRevisionLifecyclePublished,
+		PackageRevision: &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default", Labels: systemLabels},
+			Spec:       api.PackageRevisionSpec{Lifecycle: api.PackageRevisionLifecyclePublished},
+		},
+	}
+	pkgRevMeta := meta.PackageRevisionMeta{Name: repoPkgRev.Name, Namespace: repoPkgRev.Namespace, Labels: map[string]string{"team": "a"}}
+	metadataStore := &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}
+	cad := &cadEngine{metadataStore: metadataStore}
+	oldPackage := &PackageRevision{repoPackageRevision: repoPkgRev, packageRevisionMeta: pkgRevMeta}
+
+	oldObj, err := oldPackage.GetPackageRevision(ctx)
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	// The client changes the labels it read, dropping one of the system labels and
+	// overriding the other.
+	newObj := oldObj.DeepCopy()
+	newObj.Labels = map[string]string{
+		"team":                       "b",
+		api.LatestPackageRevisionKey: "false",
+	}
+
+	updated, err := cad.updatePackageRevision(ctx, nil, &configapi.Repository{}, oldPackage, oldObj, newObj, nil)
+	if err != nil {
+		t.Fatalf("updatePackageRevision failed: %v", err)
+	}
+	got, err := updated.GetPackageRevision(ctx)
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	want := map[string]string{
+		"team":                       "b",
+		api.LatestPackageRevisionKey: api.LatestPackageRevisionValue,
+		repositoryLabel:              "blueprints",
+	}
+	if diff := cmp.Diff(want, got.Labels); diff != "" {
+		t.Errorf("unexpected labels (-want, +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"team": "b"}, metadataStore.Metas[0].Labels); diff != "" {
+		t.Errorf("unexpected stored labels (-want, +got): %s", diff)
+	}
+}
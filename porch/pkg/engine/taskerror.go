@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrorClass tells whether a failed package revision operation can be fixed by the
+// client or is a failure of porch or of a service it depends on.
+type ErrorClass string
+
+const (
+	// UserError is caused by the request: an invalid task, a function that returned
+	// failure results or a merge conflict. Retrying the same request fails again.
+	UserError ErrorClass = "user"
+	// SystemError is caused by porch or its dependencies: an unavailable function
+	// runtime or a failure to store the package revision. The request may be retried.
+	SystemError ErrorClass = "system"
+)
+
+// TaskError is returned when the tasks of a new package revision cannot be applied
+// or the package revision cannot be stored.
+//
+// TaskError is an API status error: a user error is returned to clients as a
+// BadRequest status and a system error as an InternalError status, unless the
+// underlying error already has a status of its own.
+type TaskError struct {
+	Class ErrorClass
+	Err   error
+}
+
+var _ apierrors.APIStatus = &TaskError{}
+
+func (e *TaskError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Status implements apierrors.APIStatus.
+func (e *TaskError) Status() metav1.Status {
+	var apiStatus apierrors.APIStatus
+	if errors.As(e.Err, &apiStatus) {
+		return apiStatus.Status()
+	}
+	code, reason := int32(http.StatusBadRequest), metav1.StatusReasonBadRequest
+	if e.Class == SystemError {
+		code, reason = http.StatusInternalServerError, metav1.StatusReasonInternalError
+	}
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    code,
+		Reason:  reason,
+		Message: fmt.Sprintf("%s error: %v", e.Class, e.Err),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisions",
+		},
+	}
+}
+
+// userError classifies err as a user error, unless it is already classified.
+func userError(err error) error {
+	return classifiedError(err, UserError)
+}
+
+// systemError classifies err as a system error, unless it is already classified.
+func systemError(err error) error {
+	return classifiedError(err, SystemError)
+}
+
+func classifiedError(err error, class ErrorClass) error {
+	var taskErr *TaskError
+	if err == nil || errors.As(err, &taskErr) {
+		return err
+	}
+	return &TaskError{Class: class, Err: err}
+}
+
+// mutationError classifies an error returned by a mutation. Mutations fail because of
+// their task unless the error shows that a service they depend on, such as the
+// function runner or the upstream repository, couldn't be reached.
+func mutationError(err error) error {
+	var taskErr *TaskError
+	if err == nil || errors.As(err, &taskErr) {
+		return err
+	}
+	return &TaskError{Class: classifyMutationError(err), Err: err}
+}
+
+func classifyMutationError(err error) ErrorClass {
+	var apiStatus apierrors.APIStatus
+	if errors.As(err, &apiStatus) {
+		if apiStatus.Status().Code >= http.StatusInternalServerError {
+			return SystemError
+		}
+		return UserError
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Canceled:
+			return SystemError
+		}
+		// The function runner reports functions that failed as Internal errors.
+		return UserError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return SystemError
+	}
+	return UserError
+}
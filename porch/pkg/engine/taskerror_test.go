@@ -0,0 +1,145 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// creatingRepository stores every package revision it creates, and records the
+// package revisions it deletes.
+type creatingRepository struct {
+	fake.Repository
+
+	draft        *recordingDraft
+	closingDraft *closingDraft
+	deleted      []string
+}
+
+func (r *creatingRepository) CreatePackageRevision(ctx context.Context, obj *api.PackageRevision) (repository.PackageDraft, error) {
+	r.draft = &recordingDraft{}
+	r.closingDraft = &closingDraft{
+		recordingDraft: r.draft,
+		rev:            &fake.PackageRevision{Name: obj.Name, Namespace: obj.Namespace},
+	}
+	return r.closingDraft, nil
+}
+
+func (r *creatingRepository) DeletePackageRevision(ctx context.Context, rev repository.PackageRevision) error {
+	r.deleted = append(r.deleted, rev.KubeObjectName())
+	return nil
+}
+
+// unavailableMetadataStore fails to create metadata.
+type unavailableMetadataStore struct {
+	metafake.MemoryMetadataStore
+}
+
+func (s *unavailableMetadataStore) Create(ctx context.Context, pkgRevMeta meta.PackageRevisionMeta, repo *configapi.Repository) (meta.PackageRevisionMeta, error) {
+	return meta.PackageRevisionMeta{}, fmt.Errorf("connection refused")
+}
+
+func TestCreatePackageRevisionErrorClass(t *testing.T) {
+	newObj := func(tasks ...api.Task) *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "app",
+				Lifecycle:   api.PackageRevisionLifecycleDraft,
+				Tasks:       tasks,
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name          string
+		obj           *api.PackageRevision
+		metadataStore meta.MetadataStore
+		wantClass     ErrorClass
+		wantCode      int32
+		wantDeleted   []string
+	}{
+		{
+			name:          "unsupported task",
+			obj:           newObj(api.Task{Type: "Unknown"}),
+			metadataStore: &metafake.MemoryMetadataStore{},
+			wantClass:     UserError,
+			wantCode:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "metadata store unavailable",
+			obj:           newObj(),
+			metadataStore: &unavailableMetadataStore{},
+			wantClass:     SystemError,
+			wantCode:      http.StatusInternalServerError,
+			wantDeleted:   []string{"blueprints-app-v1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cad := &cadEngine{renderer: &countingRenderer{}, metadataStore: tc.metadataStore}
+			repo := &creatingRepository{}
+			_, err := cad.createPackageRevision(context.Background(), repo, &configapi.Repository{}, tc.obj, nil)
+
+			var taskErr *TaskError
+			if !errors.As(err, &taskErr) {
+				t.Fatalf("createPackageRevision returned %v; want a TaskError", err)
+			}
+			if taskErr.Class != tc.wantClass {
+				t.Errorf("unexpected error class: got %q, want %q", taskErr.Class, tc.wantClass)
+			}
+			if got := apierrors.APIStatus(taskErr).Status().Code; got != tc.wantCode {
+				t.Errorf("unexpected status code: got %d, want %d", got, tc.wantCode)
+			}
+			if fmt.Sprint(repo.deleted) != fmt.Sprint(tc.wantDeleted) {
+				t.Errorf("unexpected deleted package revisions: got %v, want %v", repo.deleted, tc.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestClassifyMutationError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"function failure", fmt.Errorf("func eval failed: %w", status.Error(codes.Internal, "function returned errors")), UserError},
+		{"function runner unavailable", fmt.Errorf("func eval failed: %w", status.Error(codes.Unavailable, "connection refused")), SystemError},
+		{"phase timeout", &PhaseTimeoutError{Phase: PhaseRender}, SystemError},
+		{"merge conflict", &EvalConflictError{}, UserError},
+		{"plain error", fmt.Errorf("invalid Kptfile"), UserError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyMutationError(tc.err); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Tasks are removed from a package revision, or changed to tasks of another type, by
+// replaying the new tasks from scratch, the same way reclone-and-replay rebuilds a
+// package revision, rather than by undoing the changes of the old tasks on the current
+// resources.
+
+// isTaskReplay determines if an update must be handled by replaying the tasks of the
+// package revision.
+func isTaskReplay(oldObj, newObj *api.PackageRevision) bool {
+	return isTaskRemoval(oldObj, newObj) || isTaskTypeChange(oldObj, newObj)
+}
+
+// validateTaskReplay validates an update replaying the tasks of the package revision.
+func validateTaskReplay(oldObj, newObj *api.PackageRevision) error {
+	switch {
+	case isTaskRemoval(oldObj, newObj):
+		return validateTaskRemoval(oldObj, newObj)
+	case isTaskTypeChange(oldObj, newObj):
+		return validateTaskTypeChange(oldObj, newObj)
+	}
+	return nil
+}
+
+// isTaskRemoval determines if an update removes tasks of the package revision.
+func isTaskRemoval(oldObj, newObj *api.PackageRevision) bool {
+	return len(newObj.Spec.Tasks) < len(oldObj.Spec.Tasks)
+}
+
+// validateTaskRemoval validates an update removing tasks of the package revision.
+// Tasks can only be removed from drafts, the remaining tasks must be unchanged and in
+// their original order, and the clone or init task creating the package can't be
+// removed.
+func validateTaskRemoval(oldObj, newObj *api.PackageRevision) error {
+	path := field.NewPath("spec", "tasks")
+	invalid := func(fieldErr *field.Error) error {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), newObj.Name, field.ErrorList{fieldErr})
+	}
+
+	if lifecycle := oldObj.Spec.Lifecycle; lifecycle != api.PackageRevisionLifecycleDraft {
+		return invalid(field.Forbidden(path, "tasks can only be removed from draft package revisions; the package revision is "+string(lifecycle)))
+	}
+
+	oldTasks, newTasks := oldObj.Spec.Tasks, newObj.Spec.Tasks
+	if first := oldTasks[0].Type; len(newTasks) == 0 || newTasks[0].Type != first {
+		switch first {
+		case api.TaskTypeClone:
+			return invalid(field.Forbidden(path.Index(0), "the clone task cannot be removed; the package would lose its upstream"))
+		case api.TaskTypeInit:
+			return invalid(field.Forbidden(path.Index(0), "the init task cannot be removed; it creates the package"))
+		}
+	}
+
+	// The remaining tasks must be a subsequence of the existing tasks.
+	next := 0
+	for i := range newTasks {
+		for next < len(oldTasks) && !reflect.DeepEqual(oldTasks[next], newTasks[i]) {
+			next++
+		}
+		if next == len(oldTasks) {
+			return invalid(field.Invalid(path.Index(i), newTasks[i].Type, "tasks cannot be changed or reordered while tasks are removed"))
+		}
+		next++
+	}
+	return nil
+}
+
+// isTaskTypeChange determines if an update changes the type of existing tasks of the
+// package revision.
+func isTaskTypeChange(oldObj, newObj *api.PackageRevision) bool {
+	if isTaskRemoval(oldObj, newObj) {
+		return false
+	}
+	for i := range oldObj.Spec.Tasks {
+		if oldObj.Spec.Tasks[i].Type != newObj.Spec.Tasks[i].Type {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTaskTypeChange validates an update changing the type of existing tasks of
+// the package revision. Only drafts can be changed, and the first task, which creates
+// the package, must keep its type.
+func validateTaskTypeChange(oldObj, newObj *api.PackageRevision) error {
+	path := field.NewPath("spec", "tasks")
+	if lifecycle := oldObj.Spec.Lifecycle; lifecycle != api.PackageRevisionLifecycleDraft {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), newObj.Name, field.ErrorList{
+			field.Forbidden(path, "task types can only be changed in draft package revisions; the package revision is "+string(lifecycle)),
+		})
+	}
+	if oldType, newType := oldObj.Spec.Tasks[0].Type, newObj.Spec.Tasks[0].Type; oldType != newType {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), newObj.Name, field.ErrorList{
+			field.Invalid(path.Index(0).Child("type"), newType, fmt.Sprintf("the first task creates the package and must stay type %q", oldType)),
+		})
+	}
+	return nil
+}
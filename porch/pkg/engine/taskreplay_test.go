@@ -0,0 +1,220 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/kpt"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// replayingRepository creates drafts which close to the package revision being
+// replayed, and records them.
+type replayingRepository struct {
+	countingRepository
+}
+
+func (r *replayingRepository) CreatePackageRevision(ctx context.Context, obj *api.PackageRevision) (repository.PackageDraft, error) {
+	r.draft = &recordingDraft{}
+	return &closingDraft{recordingDraft: r.draft, rev: &fake.PackageRevision{
+		Name:             obj.Name,
+		Namespace:        obj.Namespace,
+		PackageLifecycle: obj.Spec.Lifecycle,
+		PackageRevision:  obj,
+	}}, nil
+}
+
+func TestTaskReplay(t *testing.T) {
+	ctx := context.Background()
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "blueprints", Namespace: "default"}}
+
+	initTask := api.Task{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{Description: "app"}}
+	createConfigMap := api.Task{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+		File:      "configmap.yaml",
+		PatchType: api.PatchTypeCreateFile,
+		Contents:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+	}}}}
+	setNamespace := func(namespace string) api.Task {
+		return api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{
+			Image:     "gcr.io/kpt-fn/set-namespace:v0.4.1",
+			ConfigMap: map[string]string{"namespace": namespace},
+		}}
+	}
+	cloneTask := api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{
+		Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "upstream-app-v1"}},
+	}}
+
+	packageRevision := func(lifecycle api.PackageRevisionLifecycle, tasks ...api.Task) *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-1234", Namespace: "default"},
+			Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: "v1", Lifecycle: lifecycle, Tasks: tasks},
+		}
+	}
+	setup := func(oldObj *api.PackageRevision) (*cadEngine, *replayingRepository, *PackageRevision) {
+		pkgRevMeta := meta.PackageRevisionMeta{Name: oldObj.Name, Namespace: oldObj.Namespace}
+		cad := &cadEngine{
+			renderer:      &countingRenderer{},
+			runtime:       kpt.NewSimpleFunctionRuntime(),
+			metadataStore: &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}},
+		}
+		repoRev := &fake.PackageRevision{
+			Name:             oldObj.Name,
+			Namespace:        oldObj.Namespace,
+			PackageLifecycle: oldObj.Spec.Lifecycle,
+			PackageRevision:  oldObj,
+		}
+		return cad, &replayingRepository{}, &PackageRevision{repoPackageRevision: repoRev, packageRevisionMeta: pkgRevMeta}
+	}
+	// build returns the resources of a package revision freshly built from the tasks.
+	build := func(t *testing.T, tasks ...api.Task) map[string]string {
+		t.Helper()
+		cad := &cadEngine{renderer: &countingRenderer{}, runtime: kpt.NewSimpleFunctionRuntime()}
+		draft := &recordingDraft{}
+		if err := cad.applyTasks(ctx, draft, repositoryObj, packageRevision(api.PackageRevisionLifecycleDraft, tasks...), nil); err != nil {
+			t.Fatalf("applyTasks failed: %v", err)
+		}
+		return draft.resources
+	}
+
+	t.Run("trailing eval", func(t *testing.T) {
+		oldObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap, setNamespace("prod"))
+		newObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap)
+		cad, repo, oldPackage := setup(oldObj)
+
+		if _, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, nil); err != nil {
+			t.Fatalf("updatePackageRevision failed: %v", err)
+		}
+		if repo.draft == nil {
+			t.Fatalf("package revision wasn't replayed")
+		}
+		if got := repo.draft.resources["configmap.yaml"]; strings.Contains(got, "namespace: prod") {
+			t.Errorf("changes of the removed task were kept:\n%s", got)
+		}
+		if diff := cmp.Diff(build(t, initTask, createConfigMap), repo.draft.resources); diff != "" {
+			t.Errorf("resources don't match a fresh build (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("middle eval", func(t *testing.T) {
+		oldObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap, setNamespace("staging"), setNamespace("prod"))
+		newObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap, setNamespace("prod"))
+		cad, repo, oldPackage := setup(oldObj)
+
+		if _, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, nil); err != nil {
+			t.Fatalf("updatePackageRevision failed: %v", err)
+		}
+		// The remaining pipeline is replayed and rendered.
+		plan, err := cad.PlanPackageRevisionUpdate(ctx, repositoryObj, oldPackage, oldObj, newObj, nil)
+		if err != nil {
+			t.Fatalf("PlanPackageRevisionUpdate failed: %v", err)
+		}
+		var steps []MutationType
+		for _, step := range plan.Steps {
+			steps = append(steps, step.Type)
+		}
+		if diff := cmp.Diff([]MutationType{MutationInit, MutationPatch, MutationEval, MutationRender}, steps); diff != "" {
+			t.Errorf("unexpected plan (-want, +got): %s", diff)
+		}
+
+		if got := repo.draft.resources["configmap.yaml"]; !strings.Contains(got, "namespace: prod") {
+			t.Errorf("remaining task wasn't replayed:\n%s", got)
+		}
+		if diff := cmp.Diff(build(t, initTask, createConfigMap, setNamespace("prod")), repo.draft.resources); diff != "" {
+			t.Errorf("resources don't match a fresh build (-want, +got): %s", diff)
+		}
+	})
+
+	t.Run("patch to eval", func(t *testing.T) {
+		createNamespace := api.Task{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+			File:      "namespace.yaml",
+			PatchType: api.PatchTypeCreateFile,
+			Contents:  "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n",
+		}}}}
+		oldObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap, createNamespace)
+		newObj := packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap, setNamespace("prod"))
+		cad, repo, oldPackage := setup(oldObj)
+
+		if _, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, oldObj, newObj, nil); err != nil {
+			t.Fatalf("updatePackageRevision failed: %v", err)
+		}
+		if repo.draft == nil {
+			t.Fatalf("package revision wasn't replayed")
+		}
+		if _, found := repo.draft.resources["namespace.yaml"]; found {
+			t.Errorf("changes of the replaced patch task were kept")
+		}
+		if got := repo.draft.tasks[len(repo.draft.tasks)-1].Type; got != api.TaskTypeEval {
+			t.Errorf("last task applied to the draft is type %q; want %q", got, api.TaskTypeEval)
+		}
+		if diff := cmp.Diff(build(t, initTask, createConfigMap, setNamespace("prod")), repo.draft.resources); diff != "" {
+			t.Errorf("resources don't match a fresh build (-want, +got): %s", diff)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		oldObj, newObj *api.PackageRevision
+	}{
+		"clone task": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleDraft, cloneTask, setNamespace("prod")),
+			newObj: packageRevision(api.PackageRevisionLifecycleDraft, setNamespace("prod")),
+		},
+		"init task": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleDraft, initTask, createConfigMap),
+			newObj: packageRevision(api.PackageRevisionLifecycleDraft, createConfigMap),
+		},
+		"reordered tasks": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleDraft, initTask, setNamespace("staging"), setNamespace("prod")),
+			newObj: packageRevision(api.PackageRevisionLifecycleDraft, setNamespace("prod"), initTask),
+		},
+		"changed task": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleDraft, initTask, setNamespace("staging"), setNamespace("prod")),
+			newObj: packageRevision(api.PackageRevisionLifecycleDraft, initTask, setNamespace("test")),
+		},
+		"first task type": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleDraft, cloneTask, setNamespace("prod")),
+			newObj: packageRevision(api.PackageRevisionLifecycleDraft, initTask, setNamespace("prod")),
+		},
+		"changed type of proposed": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleProposed, initTask, createConfigMap),
+			newObj: packageRevision(api.PackageRevisionLifecycleProposed, initTask, setNamespace("prod")),
+		},
+		"proposed": {
+			oldObj: packageRevision(api.PackageRevisionLifecycleProposed, initTask, setNamespace("prod")),
+			newObj: packageRevision(api.PackageRevisionLifecycleProposed, initTask),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cad, repo, oldPackage := setup(tc.oldObj)
+			_, err := cad.updatePackageRevision(ctx, repo, repositoryObj, oldPackage, tc.oldObj, tc.newObj, nil)
+			if !apierrors.IsInvalid(err) {
+				t.Errorf("updatePackageRevision returned %v; want an Invalid error", err)
+			}
+			if repo.draft != nil {
+				t.Errorf("draft was opened for an invalid update")
+			}
+		})
+	}
+}
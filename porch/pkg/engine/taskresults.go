@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type taskResultsKey struct{}
+type taskFunctionResultsKey struct{}
+
+// taskResults collects the results of the tasks applied by a request.
+type taskResults struct {
+	mu      sync.Mutex
+	results []api.TaskResult
+}
+
+// withTaskResults returns a context collecting the results of the tasks applied by the
+// request.
+func withTaskResults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, taskResultsKey{}, &taskResults{})
+}
+
+// recordedTaskResults returns the results of the tasks applied in the context, in the
+// order they were applied, or nil if none were.
+func recordedTaskResults(ctx context.Context) []api.TaskResult {
+	results, ok := ctx.Value(taskResultsKey{}).(*taskResults)
+	if !ok {
+		return nil
+	}
+	results.mu.Lock()
+	defer results.mu.Unlock()
+	return append([]api.TaskResult(nil), results.results...)
+}
+
+// withTaskFunctionResults returns a context capturing the results of the functions run
+// by a task into results.
+func withTaskFunctionResults(ctx context.Context, results *functionResults) context.Context {
+	return context.WithValue(ctx, taskFunctionResultsKey{}, results)
+}
+
+// captureFunctionResults records the results of functions run by the task of the
+// context.
+func captureFunctionResults(ctx context.Context, results []api.FunctionResult) {
+	if captured, ok := ctx.Value(taskFunctionResultsKey{}).(*functionResults); ok {
+		captured.add(results)
+	}
+}
+
+// recordTaskResult records the result of the task with the index, which started at start
+// and changed the resources from before to after, if the context collects task results.
+func recordTaskResult(ctx context.Context, index int, task *api.Task, start time.Time, before, after repository.PackageResources, functions []api.FunctionResult) {
+	results, ok := ctx.Value(taskResultsKey{}).(*taskResults)
+	if !ok {
+		return
+	}
+	result := api.TaskResult{
+		Task:      index,
+		Type:      task.Type,
+		Duration:  metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)},
+		StartedAt: metav1.NewTime(start).Rfc3339Copy(),
+		Results:   functions,
+	}
+	for path, contents := range after.Contents {
+		previous, found := before.Contents[path]
+		switch {
+		case !found:
+			result.Added = append(result.Added, path)
+		case previous != contents:
+			result.Changed = append(result.Changed, path)
+		}
+	}
+	for path := range before.Contents {
+		if _, found := after.Contents[path]; !found {
+			result.Deleted = append(result.Deleted, path)
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Changed)
+	sort.Strings(result.Deleted)
+
+	results.mu.Lock()
+	defer results.mu.Unlock()
+	results.results = append(results.results, result)
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// replaceResourcesMutation replaces the resources of the package.
+type replaceResourcesMutation struct {
+	contents map[string]string
+	task     *api.Task
+}
+
+func (m *replaceResourcesMutation) Apply(ctx context.Context, resources repository.PackageResources) (repository.PackageResources, *api.Task, error) {
+	return repository.PackageResources{Contents: m.contents}, m.task, nil
+}
+
+func TestTaskResults(t *testing.T) {
+	const kptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: app
+`
+	const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`
+	mutations := []mutation{
+		&replaceResourcesMutation{
+			contents: map[string]string{"Kptfile": kptfile, "deployment.yaml": deployment},
+			task:     &api.Task{Type: api.TaskTypeInit},
+		},
+		&evalFunctionMutation{
+			runtime: resultsRuntime{"example.com/check-replicas": `
+- message: replicas should be at least 2
+  severity: warning
+`},
+			task: &api.Task{Type: api.TaskTypeEval, Eval: &api.FunctionEvalTaskSpec{Image: "example.com/check-replicas"}},
+		},
+		&replaceResourcesMutation{
+			contents: map[string]string{"Kptfile": kptfile + "info:\n  description: app\n", "README.md": "# app\n"},
+			task:     &api.Task{Type: api.TaskTypePatch},
+		},
+	}
+
+	ctx := withTaskResults(context.Background())
+	start := metav1.Now().Rfc3339Copy()
+	cad := &cadEngine{}
+	if err := cad.applyResourceMutations(ctx, &recordingDraft{}, repository.PackageResources{}, mutations); err != nil {
+		t.Fatalf("applyResourceMutations failed: %v", err)
+	}
+
+	want := []api.TaskResult{
+		{Task: 0, Type: api.TaskTypeInit, Added: []string{"Kptfile", "deployment.yaml"}},
+		{Task: 1, Type: api.TaskTypeEval, Results: []api.FunctionResult{{
+			Function: "example.com/check-replicas",
+			Message:  "replicas should be at least 2",
+			Severity: "warning",
+		}}},
+		{Task: 2, Type: api.TaskTypePatch, Added: []string{"README.md"}, Changed: []string{"Kptfile"}, Deleted: []string{"deployment.yaml"}},
+	}
+	ignoreDuration := cmpopts.IgnoreFields(api.TaskResult{}, "Duration", "StartedAt")
+	if diff := cmp.Diff(want, recordedTaskResults(ctx), ignoreDuration); diff != "" {
+		t.Errorf("unexpected task results (-want, +got): %s", diff)
+	}
+	for _, result := range recordedTaskResults(ctx) {
+		if result.Duration.Duration < 0 {
+			t.Errorf("task %d has negative duration %v", result.Task, result.Duration)
+		}
+		if result.StartedAt.Before(&start) {
+			t.Errorf("task %d started at %v, before the tasks were applied at %v", result.Task, result.StartedAt, start)
+		}
+	}
+
+	// The results surface in the status of the package revision.
+	pkgRev := &PackageRevision{
+		repoPackageRevision: &fake.PackageRevision{PackageRevision: &api.PackageRevision{}},
+	}
+	pkgRev.packageRevisionMeta.TaskResults = recordedTaskResults(ctx)
+	obj, err := pkgRev.GetPackageRevision(ctx)
+	if err != nil {
+		t.Fatalf("GetPackageRevision failed: %v", err)
+	}
+	if diff := cmp.Diff(want, obj.Status.TaskResults, ignoreDuration); diff != "" {
+		t.Errorf("unexpected task results in status (-want, +got): %s", diff)
+	}
+
+	// Without a collector, nothing is recorded.
+	if got := recordedTaskResults(context.Background()); got != nil {
+		t.Errorf("recorded task results %v without a collector", got)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTasksValidatedBeforeApply(t *testing.T) {
+	ctx := context.Background()
+	clone := api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{Upstream: api.UpstreamPackage{
+		Type: api.RepositoryTypeGit,
+		Git:  &api.GitPackage{Repo: "https://github.com/example/blueprints.git", Ref: "v1", Directory: "app"},
+	}}}
+	patch := api.Task{Type: api.TaskTypePatch, Patch: &api.PackagePatchTaskSpec{Patches: []api.PatchSpec{{
+		File:      "configmap.yaml",
+		PatchType: api.PatchTypeCreateFile,
+		Contents:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+	}}}}
+	update := api.Task{Type: api.TaskTypeUpdate, Update: &api.PackageUpdateTaskSpec{Upstream: api.UpstreamPackage{
+		Type: api.RepositoryTypeGit,
+		Git:  &api.GitPackage{Repo: "https://github.com/other/blueprints.git", Ref: "v2", Directory: "app"},
+	}}}
+	obj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployments-app-v1", Namespace: "default"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Lifecycle:   api.PackageRevisionLifecycleDraft,
+			Tasks:       []api.Task{clone, patch, update},
+		},
+	}
+	repositoryObj := &configapi.Repository{Spec: configapi.RepositorySpec{
+		AllowedUpstreams: []configapi.AllowedUpstream{{Git: "https://github.com/example"}},
+	}}
+
+	// The clone would fetch its upstream if it was applied; the update to an upstream
+	// that isn't allowed must be found first.
+	cad := &cadEngine{}
+	draft := &recordingDraft{}
+	err := cad.applyTasks(ctx, draft, repositoryObj, obj, nil)
+	var notAllowed *UpstreamNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("applyTasks returned %v; want an UpstreamNotAllowedError", err)
+	}
+	if !strings.Contains(err.Error(), `spec.tasks[2] (type "update")`) {
+		t.Errorf("error %q doesn't identify the failing task", err)
+	}
+	if len(draft.tasks) != 0 {
+		t.Errorf("tasks before the invalid task were written to the draft: %v", draft.tasks)
+	}
+}
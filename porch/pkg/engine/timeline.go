@@ -0,0 +1,277 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// GetPackageTimeline returns the events of all revisions of a package in chronological order.
+// The timeline is computed from the task results, lifecycle times, condition changes and
+// deletions recorded in the metadata of the package revisions; git history is not replayed.
+func (cad *cadEngine) GetPackageTimeline(ctx context.Context, repositoryObj *configapi.Repository, packageName string) ([]api.TimelineEvent, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::GetPackageTimeline", trace.WithAttributes())
+	defer span.End()
+
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: packageName})
+	if err != nil {
+		return nil, err
+	}
+
+	var apiRevisions []*api.PackageRevision
+	changes := map[string][]meta.ConditionChange{}
+	var deletions []meta.RevisionDeletion
+	for _, rev := range revisions {
+		apiRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+		apiRevisions = append(apiRevisions, apiRev)
+		changes[apiRev.Name] = rev.packageRevisionMeta.ConditionChanges
+		deletions = append(deletions, rev.packageRevisionMeta.Deletions...)
+	}
+	return buildPackageTimeline(apiRevisions, changes, deletions), nil
+}
+
+// buildPackageTimeline returns the events of the revisions, the recorded changes of their
+// conditions, by revision name, and the deletions in chronological order.
+func buildPackageTimeline(revisions []*api.PackageRevision, conditionChanges map[string][]meta.ConditionChange, deletions []meta.RevisionDeletion) []api.TimelineEvent {
+	var events []api.TimelineEvent
+	for _, rev := range revisions {
+		created := rev.CreationTimestamp
+		events = append(events, api.TimelineEvent{
+			Time:     created,
+			Type:     api.TimelineEventRevisionCreated,
+			Revision: rev.Name,
+			Message:  fmt.Sprintf("revision %q of package %q created", rev.Spec.Revision, rev.Spec.PackageName),
+		})
+		events = append(events, taskEvents(rev)...)
+		events = append(events, conditionEvents(rev, conditionChanges[rev.Name])...)
+
+		if !rev.Status.ProposedAt.IsZero() {
+			event := api.TimelineEvent{
+				Time:     rev.Status.ProposedAt,
+				Type:     api.TimelineEventLifecycleChanged,
+				Revision: rev.Name,
+				Actor:    rev.Status.ProposedBy,
+				Message:  fmt.Sprintf("lifecycle changed to %s", api.PackageRevisionLifecycleProposed),
+			}
+			if trigger, found := rev.Annotations[api.AutoProposedAnnotation]; found {
+				event.Actor = autoProposeActor
+				event.Message = fmt.Sprintf("lifecycle changed to %s automatically: readiness gates %s are true", api.PackageRevisionLifecycleProposed, trigger)
+			}
+			events = append(events, event)
+		}
+		if rev.Spec.Lifecycle == api.PackageRevisionLifecyclePublished {
+			published := rev.Status.PublishedAt
+			if published.IsZero() {
+				published = created
+			}
+			events = append(events, api.TimelineEvent{
+				Time:     published,
+				Type:     api.TimelineEventLifecycleChanged,
+				Revision: rev.Name,
+				Actor:    rev.Status.PublishedBy,
+				Message:  fmt.Sprintf("lifecycle changed to %s", api.PackageRevisionLifecyclePublished),
+			})
+		}
+	}
+
+	// Every remaining revision of the package records the deletions.
+	deleted := map[string]bool{}
+	for _, d := range deletions {
+		if deleted[d.Name] {
+			continue
+		}
+		deleted[d.Name] = true
+		events = append(events, api.TimelineEvent{
+			Time:     d.DeletedAt,
+			Type:     api.TimelineEventRevisionDeleted,
+			Revision: d.Name,
+			Actor:    d.DeletedBy,
+			Message:  fmt.Sprintf("revision %q deleted", d.Revision),
+		})
+	}
+
+	// Events with the same timestamp keep the order in which they were recorded for their revision.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(&events[j].Time)
+	})
+	return events
+}
+
+// taskEvents returns the events of the tasks applied by the last create or update of the
+// revision, from its task results. The tasks of revisions without task results, which were
+// created before they were recorded, are stamped with the creation of the revision.
+func taskEvents(rev *api.PackageRevision) []api.TimelineEvent {
+	var events []api.TimelineEvent
+	if len(rev.Status.TaskResults) == 0 {
+		for i, task := range rev.Spec.Tasks {
+			events = append(events, api.TimelineEvent{
+				Time:     rev.CreationTimestamp,
+				Type:     api.TimelineEventTaskApplied,
+				Revision: rev.Name,
+				Message:  fmt.Sprintf("task %d (%s) applied", i, task.Type),
+			})
+		}
+		return events
+	}
+
+	for _, result := range rev.Status.TaskResults {
+		started := result.StartedAt
+		if started.IsZero() {
+			started = rev.CreationTimestamp
+		}
+		duration := result.Duration
+		events = append(events, api.TimelineEvent{
+			Time:     started,
+			Type:     api.TimelineEventTaskApplied,
+			Revision: rev.Name,
+			Duration: &duration,
+			Message: fmt.Sprintf("task %d (%s) applied: %d added, %d changed, %d deleted", result.Task, result.Type,
+				len(result.Added), len(result.Changed), len(result.Deleted)),
+		})
+	}
+	return events
+}
+
+// conditionEvents returns the events of the changes of the conditions of the revision.
+// Conditions set when the revision was created or outside of Porch have no recorded
+// changes; they are stamped with the last commit of the revision, when they were
+// observed last.
+func conditionEvents(rev *api.PackageRevision, changes []meta.ConditionChange) []api.TimelineEvent {
+	var events []api.TimelineEvent
+	recorded := map[string]string{}
+	for _, c := range changes {
+		events = append(events, api.TimelineEvent{
+			Time:     c.ChangedAt,
+			Type:     api.TimelineEventCondition,
+			Revision: rev.Name,
+			Actor:    c.ChangedBy,
+			Message:  fmt.Sprintf("condition %s changed to %s", c.Type, c.Status),
+		})
+		recorded[c.Type] = c.Status
+	}
+
+	observed := rev.CreationTimestamp
+	if rev.Status.Timestamps != nil && !rev.Status.Timestamps.CommitTime.IsZero() {
+		observed = rev.Status.Timestamps.CommitTime
+	}
+	for _, c := range rev.Status.Conditions {
+		if status, found := recorded[c.Type]; found && status == string(c.Status) {
+			continue
+		}
+		events = append(events, api.TimelineEvent{
+			Time:     observed,
+			Type:     api.TimelineEventCondition,
+			Revision: rev.Name,
+			Message:  fmt.Sprintf("condition %s is %s", c.Type, c.Status),
+		})
+	}
+	return events
+}
+
+// conditionChanges returns the condition changes to record for the update of a package
+// revision from oldObj to newObj by the user, appended to the previously recorded ones,
+// or nil if the update changes no conditions. Conditions are only set, never removed, by
+// updates, so only the conditions of newObj can change.
+func conditionChanges(previous []meta.ConditionChange, oldObj, newObj *api.PackageRevision, user string, now metav1.Time) []meta.ConditionChange {
+	old := map[string]api.ConditionStatus{}
+	for _, c := range oldObj.Status.Conditions {
+		old[c.Type] = c.Status
+	}
+	var changes []meta.ConditionChange
+	for _, c := range newObj.Status.Conditions {
+		if status, found := old[c.Type]; found && status == c.Status {
+			continue
+		}
+		changes = append(changes, meta.ConditionChange{
+			Type:      c.Type,
+			Status:    string(c.Status),
+			ChangedBy: user,
+			ChangedAt: now,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return append(append([]meta.ConditionChange{}, previous...), changes...)
+}
+
+// recordRevisionDeletion records the deletion of the package revision in the metadata of
+// the remaining package revisions of its package, for its timeline. The deletion is
+// recorded on a best effort basis: the package revision is deleted already.
+func (cad *cadEngine) recordRevisionDeletion(ctx context.Context, repositoryObj *configapi.Repository, deleted *PackageRevision) {
+	key := deleted.repoPackageRevision.Key()
+	revisions, _, err := cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{Package: key.Package})
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "Cannot record deletion of package revision", "name", deleted.KubeObjectName())
+		return
+	}
+
+	deletion := meta.RevisionDeletion{
+		Name:      deleted.KubeObjectName(),
+		Revision:  key.Revision,
+		DeletedBy: cad.requestUser(ctx),
+		DeletedAt: metav1.Now().Rfc3339Copy(),
+	}
+	for _, rev := range revisions {
+		if rev.KubeObjectName() == deletion.Name {
+			continue
+		}
+		pkgRevMeta, err := cad.metadataStore.Get(ctx, types.NamespacedName{Name: rev.KubeObjectName(), Namespace: rev.repoPackageRevision.KubeObjectNamespace()})
+		if err != nil {
+			klog.FromContext(ctx).Error(err, "Cannot record deletion of package revision", "name", deletion.Name, "in", rev.KubeObjectName())
+			continue
+		}
+		update := meta.PackageRevisionMeta{
+			Name:        pkgRevMeta.Name,
+			Namespace:   pkgRevMeta.Namespace,
+			Labels:      pkgRevMeta.Labels,
+			Annotations: pkgRevMeta.Annotations,
+			Deletions:   append(pkgRevMeta.Deletions, deletion),
+		}
+		if _, err := cad.metadataStore.Update(ctx, update); err != nil {
+			klog.FromContext(ctx).Error(err, "Cannot record deletion of package revision", "name", deletion.Name, "in", rev.KubeObjectName())
+		}
+	}
+}
+
+// requestUser returns the user of the request, by email if it is known, or the empty
+// string.
+func (cad *cadEngine) requestUser(ctx context.Context) string {
+	if cad.userInfoProvider == nil {
+		return ""
+	}
+	userInfo := cad.userInfoProvider.GetUserInfo(ctx)
+	if userInfo == nil {
+		return ""
+	}
+	if userInfo.Email != "" {
+		return userInfo.Email
+	}
+	return userInfo.Name
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildPackageTimeline(t *testing.T) {
+	t1 := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	t2 := metav1.NewTime(time.Date(2022, 8, 2, 10, 0, 0, 0, time.UTC))
+	t3 := metav1.NewTime(time.Date(2022, 8, 3, 10, 0, 0, 0, time.UTC))
+	t4 := metav1.NewTime(time.Date(2022, 8, 4, 10, 0, 0, 0, time.UTC))
+	t5 := metav1.NewTime(time.Date(2022, 8, 5, 10, 0, 0, 0, time.UTC))
+	t6 := metav1.NewTime(time.Date(2022, 8, 6, 10, 0, 0, 0, time.UTC))
+	t3h := metav1.NewTime(t3.Add(time.Hour))
+
+	revisions := []*api.PackageRevision{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo-v2", CreationTimestamp: t3},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "pkg",
+				Revision:    "v2",
+				Lifecycle:   api.PackageRevisionLifecycleProposed,
+				Tasks:       []api.Task{{Type: api.TaskTypeClone}, {Type: api.TaskTypeEdit}},
+			},
+			Status: api.PackageRevisionStatus{
+				Conditions: []api.Condition{{Type: "Ready", Status: api.ConditionFalse}, {Type: "Approved", Status: api.ConditionTrue}},
+				Timestamps: &api.PackageRevisionTimestamps{CommitTime: t4},
+				ProposedAt: t5,
+				ProposedBy: "carol@example.com",
+				TaskResults: []api.TaskResult{
+					{Task: 0, Type: api.TaskTypeClone, StartedAt: t3, Duration: metav1.Duration{Duration: 2 * time.Second}, Added: []string{"Kptfile"}},
+					{Task: 1, Type: api.TaskTypeEdit, StartedAt: t4, Duration: metav1.Duration{Duration: time.Second}, Changed: []string{"Kptfile"}, Deleted: []string{"old.yaml"}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo-v1", CreationTimestamp: t1},
+			Spec: api.PackageRevisionSpec{
+				PackageName: "pkg",
+				Revision:    "v1",
+				Lifecycle:   api.PackageRevisionLifecyclePublished,
+				Tasks:       []api.Task{{Type: api.TaskTypeInit}},
+			},
+			Status: api.PackageRevisionStatus{
+				PublishedAt: t2,
+				PublishedBy: "alice@example.com",
+			},
+		},
+	}
+	// The Approved condition was set outside of Porch.
+	conditionChanges := map[string][]meta.ConditionChange{
+		"repo-v2": {
+			{Type: "Ready", Status: "True", ChangedBy: "carol@example.com", ChangedAt: t3h},
+			{Type: "Ready", Status: "False", ChangedBy: "dave@example.com", ChangedAt: t4},
+		},
+	}
+	// Every remaining revision records the deletion.
+	deletions := []meta.RevisionDeletion{
+		{Name: "repo-v0", Revision: "v0", DeletedBy: "bob@example.com", DeletedAt: t6},
+		{Name: "repo-v0", Revision: "v0", DeletedBy: "bob@example.com", DeletedAt: t6},
+	}
+
+	want := []api.TimelineEvent{
+		{Time: t1, Type: api.TimelineEventRevisionCreated, Revision: "repo-v1", Message: `revision "v1" of package "pkg" created`},
+		{Time: t1, Type: api.TimelineEventTaskApplied, Revision: "repo-v1", Message: "task 0 (init) applied"},
+		{Time: t2, Type: api.TimelineEventLifecycleChanged, Revision: "repo-v1", Actor: "alice@example.com", Message: "lifecycle changed to Published"},
+		{Time: t3, Type: api.TimelineEventRevisionCreated, Revision: "repo-v2", Message: `revision "v2" of package "pkg" created`},
+		{Time: t3, Type: api.TimelineEventTaskApplied, Revision: "repo-v2", Duration: &metav1.Duration{Duration: 2 * time.Second},
+			Message: "task 0 (clone) applied: 1 added, 0 changed, 0 deleted"},
+		{Time: t3h, Type: api.TimelineEventCondition, Revision: "repo-v2", Actor: "carol@example.com", Message: "condition Ready changed to True"},
+		{Time: t4, Type: api.TimelineEventTaskApplied, Revision: "repo-v2", Duration: &metav1.Duration{Duration: time.Second},
+			Message: "task 1 (edit) applied: 0 added, 1 changed, 1 deleted"},
+		{Time: t4, Type: api.TimelineEventCondition, Revision: "repo-v2", Actor: "dave@example.com", Message: "condition Ready changed to False"},
+		{Time: t4, Type: api.TimelineEventCondition, Revision: "repo-v2", Message: "condition Approved is True"},
+		{Time: t5, Type: api.TimelineEventLifecycleChanged, Revision: "repo-v2", Actor: "carol@example.com", Message: "lifecycle changed to Proposed"},
+		{Time: t6, Type: api.TimelineEventRevisionDeleted, Revision: "repo-v0", Actor: "bob@example.com", Message: `revision "v0" deleted`},
+	}
+
+	got := buildPackageTimeline(revisions, conditionChanges, deletions)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected timeline (-want, +got): %s", diff)
+	}
+}
+
+func TestConditionChanges(t *testing.T) {
+	t1 := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	t2 := metav1.NewTime(time.Date(2022, 8, 2, 10, 0, 0, 0, time.UTC))
+	revision := func(conditions ...api.Condition) *api.PackageRevision {
+		return &api.PackageRevision{Status: api.PackageRevisionStatus{Conditions: conditions}}
+	}
+	previous := []meta.ConditionChange{{Type: "Ready", Status: "False", ChangedBy: "alice@example.com", ChangedAt: t1}}
+
+	got := conditionChanges(previous,
+		revision(api.Condition{Type: "Ready", Status: api.ConditionFalse}, api.Condition{Type: "Tested", Status: api.ConditionTrue}),
+		revision(api.Condition{Type: "Ready", Status: api.ConditionTrue}, api.Condition{Type: "Tested", Status: api.ConditionTrue},
+			api.Condition{Type: "Approved", Status: api.ConditionTrue}),
+		"bob@example.com", t2)
+	want := []meta.ConditionChange{
+		{Type: "Ready", Status: "False", ChangedBy: "alice@example.com", ChangedAt: t1},
+		{Type: "Ready", Status: "True", ChangedBy: "bob@example.com", ChangedAt: t2},
+		{Type: "Approved", Status: "True", ChangedBy: "bob@example.com", ChangedAt: t2},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected condition changes (-want, +got): %s", diff)
+	}
+
+	// Updates which don't change the conditions leave the recorded changes alone.
+	unchanged := revision(api.Condition{Type: "Ready", Status: api.ConditionFalse})
+	if got := conditionChanges(previous, unchanged, unchanged, "bob@example.com", t2); got != nil {
+		t.Errorf("unchanged conditions recorded changes %v", got)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"k8s.io/klog/v2"
+)
+
+// pinnedToolchain returns the render toolchain version the package revision with the
+// annotations is pinned to, or "" if it isn't pinned.
+func pinnedToolchain(annotations map[string]string) string {
+	return annotations[api.RenderToolchainAnnotation]
+}
+
+// recordToolchain pins a new package revision to the current render toolchain, unless
+// it is already pinned.
+func (cad *cadEngine) recordToolchain(obj *api.PackageRevision) {
+	if cad.renderToolchain == "" || pinnedToolchain(obj.Annotations) != "" {
+		return
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[api.RenderToolchainAnnotation] = cad.renderToolchain
+}
+
+// renderRuntime returns the function runtime rendering package revisions pinned to the
+// toolchain version. If the version isn't available, the current runtime is used.
+func (cad *cadEngine) renderRuntime(toolchain string) fn.FunctionRuntime {
+	if toolchain == "" || toolchain == cad.renderToolchain {
+		return cad.runtime
+	}
+	if runtime, found := cad.toolchainRuntimes[toolchain]; found {
+		return runtime
+	}
+	klog.Warningf("Render toolchain %q pinned by the package revision is not available; rendering with the current toolchain %q instead, which may produce different results",
+		toolchain, cad.renderToolchain)
+	return cad.runtime
+}
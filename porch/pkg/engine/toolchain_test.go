@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/fn"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// versionedRuntime is the function runtime of a render toolchain version.
+type versionedRuntime struct {
+	version string
+}
+
+func (r *versionedRuntime) GetRunner(ctx context.Context, function *kptfile.Function) (fn.FunctionRunner, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// toolchainRenderer writes the version of the runtime it renders with to rendered.yaml.
+type toolchainRenderer struct{}
+
+func (r *toolchainRenderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
+	runtime := opts.Runtime.(*resultRecordingRuntime).runtime.(*cancellableRuntime).runtime.(*tracingRuntime).runtime.(*versionedRuntime)
+	return pkg.WriteFile(path.Join(opts.PkgPath, "rendered.yaml"), []byte(runtime.version))
+}
+
+func TestRenderToolchain(t *testing.T) {
+	cad := &cadEngine{
+		renderer:          &toolchainRenderer{},
+		runtime:           &versionedRuntime{version: "v2"},
+		renderToolchain:   "v2",
+		toolchainRuntimes: map[string]fn.FunctionRuntime{"v1": &versionedRuntime{version: "v1"}},
+	}
+
+	t.Run("records the current version", func(t *testing.T) {
+		obj := &api.PackageRevision{}
+		cad.recordToolchain(obj)
+		if got := obj.Annotations[api.RenderToolchainAnnotation]; got != "v2" {
+			t.Errorf("recorded toolchain %q; want %q", got, "v2")
+		}
+		pinned := &api.PackageRevision{}
+		pinned.Annotations = map[string]string{api.RenderToolchainAnnotation: "v1"}
+		cad.recordToolchain(pinned)
+		if got := pinned.Annotations[api.RenderToolchainAnnotation]; got != "v1" {
+			t.Errorf("recording replaced the pinned toolchain with %q", got)
+		}
+	})
+
+	t.Run("re-renders with the pinned runtime", func(t *testing.T) {
+		revision := func(name string) repository.PackageRevision {
+			return &fake.PackageRevision{
+				Name:             name,
+				PackageLifecycle: api.PackageRevisionLifecycleDraft,
+				Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+					kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+				}}},
+			}
+		}
+		revisions := []repository.PackageRevision{revision("pinned-v1"), revision("pinned-v2"), revision("pinned-v0"), revision("unpinned")}
+		annotations := map[string]map[string]string{
+			"pinned-v1": {api.RenderToolchainAnnotation: "v1"},
+			"pinned-v2": {api.RenderToolchainAnnotation: "v2"},
+			"pinned-v0": {api.RenderToolchainAnnotation: "v0"},
+		}
+
+		repo := &draftRepository{drafts: map[string]*recordingDraft{}}
+		for _, result := range cad.rerenderDrafts(context.Background(), repo, revisions, annotations) {
+			if result.Status != RerenderChanged {
+				t.Fatalf("unexpected rerender result: %+v", result)
+			}
+		}
+
+		for name, want := range map[string]string{
+			"pinned-v1": "v1",
+			// An unavailable version renders with the current runtime.
+			"pinned-v0": "v2",
+			"pinned-v2": "v2",
+			"unpinned":  "v2",
+		} {
+			if got := repo.drafts[name].resources["rendered.yaml"]; got != want {
+				t.Errorf("%s rendered with toolchain %q; want %q", name, got, want)
+			}
+		}
+	})
+}
@@ -16,9 +16,24 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	internalpkg "github.com/GoogleContainerTools/kpt/internal/pkg"
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptoci "github.com/GoogleContainerTools/kpt/pkg/oci"
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/git"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -63,3 +78,234 @@ func TestPkgUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateFromGit(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join(".", "testdata", "update"))
+	if err != nil {
+		t.Fatalf("Failed to find testdata: %v", err)
+	}
+	original, err := loadResourcesFromDirectory(filepath.Join(testdata, "original"))
+	if err != nil {
+		t.Fatalf("Failed to read original resources: %v", err)
+	}
+	upstream, err := loadResourcesFromDirectory(filepath.Join(testdata, "upstream"))
+	if err != nil {
+		t.Fatalf("Failed to read upstream resources: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		// cloneRef is the ref the package is cloned at, when v1 of the package is
+		// committed; updateRef is the ref it is updated to, once v2 is committed on top.
+		cloneRef, updateRef string
+	}{
+		{name: "tag", cloneRef: "v1", updateRef: "v2"},
+		{name: "moved branch", cloneRef: "main", updateRef: "main"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			gogitRepo, err := gogit.Init(memory.NewStorage(), memfs.New())
+			if err != nil {
+				t.Fatalf("Failed to initialize in-memory git repository: %v", err)
+			}
+			commitPackage(t, gogitRepo, "basens", original, "v1")
+			repo, err := git.NewRepo(gogitRepo)
+			if err != nil {
+				t.Fatalf("NewRepo failed: %v", err)
+			}
+			addr := startGitServer(t, repo)
+
+			gitUpstream := func(ref string) v1alpha1.UpstreamPackage {
+				return v1alpha1.UpstreamPackage{
+					Type: v1alpha1.RepositoryTypeGit,
+					Git:  &v1alpha1.GitPackage{Repo: addr, Ref: ref, Directory: "basens"},
+				}
+			}
+			cloneTask := &v1alpha1.Task{Type: v1alpha1.TaskTypeClone, Clone: &v1alpha1.PackageCloneTaskSpec{Upstream: gitUpstream(tc.cloneRef)}}
+			clone := &clonePackageMutation{
+				task:               cloneTask,
+				namespace:          "default",
+				name:               "basens",
+				credentialResolver: &credentialResolver{},
+			}
+			cloned, _, err := clone.Apply(ctx, repository.PackageResources{})
+			if err != nil {
+				t.Fatalf("Failed to clone package: %v", err)
+			}
+
+			// Local changes are kept by the update.
+			cloned.Contents["local.yaml"] = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\ndata:\n  team: platform\n"
+
+			// The upstream advances by one commit.
+			v2 := commitPackage(t, gogitRepo, "basens", upstream, "v2")
+
+			update := &updatePackageMutation{
+				cloneTask:          cloneTask,
+				updateTask:         &v1alpha1.Task{Type: v1alpha1.TaskTypeUpdate, Update: &v1alpha1.PackageUpdateTaskSpec{Upstream: gitUpstream(tc.updateRef)}},
+				namespace:          "default",
+				credentialResolver: &credentialResolver{},
+				pkgName:            "basens",
+			}
+			updated, _, err := update.Apply(ctx, cloned)
+			if err != nil {
+				t.Fatalf("Failed to update package: %v", err)
+			}
+
+			if got := updated.Contents["resourcequota.yaml"]; !strings.Contains(got, "memory: 60G") {
+				t.Errorf("upstream change wasn't merged into resourcequota.yaml:\n%s", got)
+			}
+			if got := updated.Contents["local.yaml"]; !strings.Contains(got, "team: platform") {
+				t.Errorf("local change wasn't kept:\n%s", got)
+			}
+
+			kf, err := internalpkg.DecodeKptfile(strings.NewReader(updated.Contents[kptfile.KptFileName]))
+			if err != nil {
+				t.Fatalf("Failed to decode updated Kptfile: %v", err)
+			}
+			wantUpstream := &kptfile.Upstream{Type: kptfile.GitOrigin, Git: &kptfile.Git{Repo: addr, Directory: "basens", Ref: tc.updateRef}}
+			if diff := cmp.Diff(wantUpstream, kf.Upstream); diff != "" {
+				t.Errorf("unexpected upstream (-want, +got): %s", diff)
+			}
+			wantLock := &kptfile.UpstreamLock{Type: kptfile.GitOrigin, Git: &kptfile.GitLock{Repo: addr, Directory: "basens", Ref: tc.updateRef, Commit: v2.String()}}
+			if diff := cmp.Diff(wantLock, kf.UpstreamLock); diff != "" {
+				t.Errorf("unexpected upstream lock (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateFromOci(t *testing.T) {
+	ctx := context.Background()
+	testdata, err := filepath.Abs(filepath.Join(".", "testdata", "update"))
+	if err != nil {
+		t.Fatalf("Failed to find testdata: %v", err)
+	}
+
+	// The registry has v1 and v2 of the package; the package was cloned from v1.
+	const image = "us-docker.pkg.dev/example/packages/basens"
+	opener := &fakeOciOpener{tags: map[string]string{}, images: map[string]repository.PackageResources{}}
+	for _, version := range []struct{ tag, dir string }{{"v1", "original"}, {"v2", "upstream"}} {
+		resources, err := loadResourcesFromDirectory(filepath.Join(testdata, version.dir))
+		if err != nil {
+			t.Fatalf("Failed to read %s resources: %v", version.dir, err)
+		}
+		digest := "sha256:" + strings.Repeat(version.tag, 32)
+		opener.tags[image+":"+version.tag] = digest
+		opener.images[digest] = resources
+	}
+	local, err := loadResourcesFromDirectory(filepath.Join(testdata, "original"))
+	if err != nil {
+		t.Fatalf("Failed to read local resources: %v", err)
+	}
+	local.Contents["local.yaml"] = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\ndata:\n  team: platform\n"
+
+	ociUpstream := func(image string) v1alpha1.UpstreamPackage {
+		return v1alpha1.UpstreamPackage{Type: v1alpha1.RepositoryTypeOCI, Oci: &v1alpha1.OciPackage{Image: image}}
+	}
+	cloneTask := &v1alpha1.Task{Type: v1alpha1.TaskTypeClone, Clone: &v1alpha1.PackageCloneTaskSpec{Upstream: ociUpstream(image + ":v1")}}
+	update := &updatePackageMutation{
+		cloneTask:  cloneTask,
+		updateTask: &v1alpha1.Task{Type: v1alpha1.TaskTypeUpdate, Update: &v1alpha1.PackageUpdateTaskSpec{Upstream: ociUpstream(image + ":v2")}},
+		ociOpener:  opener,
+		namespace:  "default",
+		pkgName:    "basens",
+	}
+	updated, task, err := update.Apply(ctx, local)
+	if err != nil {
+		t.Fatalf("Failed to update package: %v", err)
+	}
+
+	if got := updated.Contents["resourcequota.yaml"]; !strings.Contains(got, "memory: 60G") {
+		t.Errorf("upstream change wasn't merged into resourcequota.yaml:\n%s", got)
+	}
+	if got := updated.Contents["local.yaml"]; !strings.Contains(got, "team: platform") {
+		t.Errorf("local change wasn't kept:\n%s", got)
+	}
+	if diff := cmp.Diff([]string{image + ":v2", image + ":v1"}, opener.resolved); diff != "" {
+		t.Errorf("unexpected resolved images (-want, +got): %s", diff)
+	}
+
+	// The task records the digest the package was updated to, which is the original of
+	// the next update.
+	pinned := image + "@" + opener.tags[image+":v2"]
+	if got := task.Update.Upstream.Oci.Image; got != pinned {
+		t.Errorf("update task recorded image %q; want %q", got, pinned)
+	}
+	if got := update.updateTask.Update.Upstream.Oci.Image; got != image+":v2" {
+		t.Errorf("requested update task was changed to %q", got)
+	}
+	next := &updatePackageMutation{cloneTask: cloneTask, previousUpstream: &task.Update.Upstream}
+	current, err := next.currUpstream()
+	if err != nil {
+		t.Fatalf("currUpstream failed: %v", err)
+	}
+	if got := current.Oci.Image; got != pinned {
+		t.Errorf("current upstream is %q; want %q", got, pinned)
+	}
+}
+
+// fakeOciOpener reads packages from images in memory, by tag or by digest.
+type fakeOciOpener struct {
+	tags     map[string]string
+	images   map[string]repository.PackageResources
+	resolved []string
+}
+
+func (o *fakeOciOpener) ResolveImage(ctx context.Context, image string) (kptoci.ImageDigestName, error) {
+	o.resolved = append(o.resolved, image)
+	if name, digest, found := strings.Cut(image, "@"); found {
+		return kptoci.ImageDigestName{Image: name, Digest: digest}, nil
+	}
+	digest, found := o.tags[image]
+	if !found {
+		return kptoci.ImageDigestName{}, fmt.Errorf("image %q not found", image)
+	}
+	return kptoci.ImageDigestName{Image: image[:strings.LastIndex(image, ":")], Digest: digest}, nil
+}
+
+func (o *fakeOciOpener) LoadResources(ctx context.Context, image kptoci.ImageDigestName) (repository.PackageResources, error) {
+	resources, found := o.images[image.Digest]
+	if !found {
+		return repository.PackageResources{}, fmt.Errorf("image %s not found", ociImageName(image))
+	}
+	return resources, nil
+}
+
+// commitPackage replaces the package in the directory of the git repository with the
+// resources, and commits it to main with the tag.
+func commitPackage(t *testing.T, repo *gogit.Repository, dir string, resources repository.PackageResources, tag string) plumbing.Hash {
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get git repository worktree: %v", err)
+	}
+	for name, content := range resources.Contents {
+		path := filepath.Join(dir, name)
+		f, err := wt.Filesystem.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fsFileMode(resources.FileModes[name]))
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		f.Close()
+		if _, err := wt.Add(path); err != nil {
+			t.Fatalf("Failed to add %s: %v", path, err)
+		}
+	}
+
+	sig := &object.Signature{Name: "Porch Unit Test", Email: "porch-unit-test@kpt.dev", When: time.Now()}
+	hash, err := wt.Commit("Package "+tag, &gogit.CommitOptions{All: true, Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatalf("Failed to commit %s: %v", tag, err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", hash)); err != nil {
+		t.Fatalf("Failed to set refs/heads/main: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main")); err != nil {
+		t.Fatalf("Failed to set HEAD: %v", err)
+	}
+	if _, err := repo.CreateTag(tag, hash, nil); err != nil {
+		t.Fatalf("Failed to tag %s: %v", tag, err)
+	}
+	return hash
+}
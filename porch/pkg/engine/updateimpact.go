@@ -0,0 +1,276 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateImpactStatus is the predicted outcome of updating a downstream package revision
+// to a new upstream revision.
+type UpdateImpactStatus string
+
+const (
+	// UpdateImpactChanged means the update would change the resources of the downstream.
+	UpdateImpactChanged UpdateImpactStatus = "Changed"
+	// UpdateImpactUnchanged means the update wouldn't change the resources of the
+	// downstream, or the downstream is already based on the new upstream revision.
+	UpdateImpactUnchanged UpdateImpactStatus = "Unchanged"
+	// UpdateImpactConflict means the upstream and the downstream changed the same fields.
+	UpdateImpactConflict UpdateImpactStatus = "Conflict"
+	// UpdateImpactFailed means the update couldn't be previewed.
+	UpdateImpactFailed UpdateImpactStatus = "Failed"
+)
+
+// UpdateImpact is the predicted outcome of updating one downstream package revision.
+type UpdateImpact struct {
+	// Name is the name of the downstream package revision.
+	Name string `json:"name"`
+	// Upstream is the name of the upstream package revision the downstream is based on.
+	Upstream string             `json:"upstream"`
+	Status   UpdateImpactStatus `json:"status"`
+	// Conflicts are the conflicting fields, as "<resource> <field path>", sorted.
+	Conflicts []string `json:"conflicts,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// PreviewUpstreamUpdateImpact reports, for every downstream of the upstream package
+// referenced by upstreamRef, whether updating it to revision newRevision of the upstream
+// package would change it or conflict with its local changes. Nothing is written.
+//
+// The downstreams are the drafts, proposed and latest published package revisions of the
+// namespace repositories whose current upstream is a revision of the upstream package.
+// The update is previewed with the same three-way merge as update tasks use.
+func (cad *cadEngine) PreviewUpstreamUpdateImpact(ctx context.Context, namespace string, upstreamRef *api.PackageRevisionRef, newRevision string, namespaceRepositories []configapi.Repository) ([]UpdateImpact, error) {
+	ctx, span := tracer.Start(ctx, "cadEngine::PreviewUpstreamUpdateImpact", trace.WithAttributes())
+	defer span.End()
+
+	fetcher := &PackageFetcher{
+		repoOpener:        cad,
+		referenceResolver: cad.referenceResolver,
+		sizeBudget:        &cad.sizeBudget,
+	}
+	return previewUpstreamUpdateImpact(ctx, fetcher, cad, namespace, upstreamRef, newRevision, namespaceRepositories)
+}
+
+func previewUpstreamUpdateImpact(ctx context.Context, fetcher *PackageFetcher, opener RepositoryOpener, namespace string, upstreamRef *api.PackageRevisionRef, newRevision string, namespaceRepositories []configapi.Repository) ([]UpdateImpact, error) {
+	upstream, err := fetcher.FetchRevision(ctx, upstreamRef, namespace)
+	if err != nil {
+		return nil, err
+	}
+	key := upstream.Key()
+	target, err := fetcher.FetchRevision(ctx, &api.PackageRevisionRef{Repository: key.Repository, Package: key.Package, Revision: newRevision, Namespace: upstreamRef.Namespace}, namespace)
+	if err != nil {
+		return nil, err
+	}
+	targetResources, err := fetcher.GetResources(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamRepo, err := fetcher.openRepository(ctx, namespace, upstreamRef.Namespace, key.Repository)
+	if err != nil {
+		return nil, err
+	}
+	upstreamRevisions, err := upstreamRepo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{Package: key.Package})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]repository.PackageRevision{}
+	for _, rev := range upstreamRevisions {
+		if rev.Key().Package == key.Package {
+			byName[rev.KubeObjectName()] = rev
+		}
+	}
+
+	downstreams, err := listDownstreams(ctx, opener, namespace, byName, namespaceRepositories)
+	if err != nil {
+		return nil, err
+	}
+
+	impacts := make([]UpdateImpact, 0, len(downstreams))
+	for _, d := range downstreams {
+		impact := UpdateImpact{Name: d.rev.KubeObjectName(), Upstream: d.upstream, Status: UpdateImpactUnchanged}
+		if d.upstream != target.KubeObjectName() {
+			if err := previewUpdate(ctx, fetcher, d.rev, byName[d.upstream], targetResources, &impact); err != nil {
+				impact.Status, impact.Error = UpdateImpactFailed, err.Error()
+			}
+		}
+		impacts = append(impacts, impact)
+	}
+	sort.Slice(impacts, func(i, j int) bool {
+		return impacts[i].Name < impacts[j].Name
+	})
+	return impacts, nil
+}
+
+// downstream is a package revision based on a revision of an upstream package.
+type downstream struct {
+	rev           repository.PackageRevision
+	repositoryObj *configapi.Repository
+	// upstream is the name of the upstream package revision.
+	upstream string
+}
+
+// listDownstreams returns the drafts, proposed and latest published package revisions of
+// the repositories whose current upstream is one of the upstream package revisions.
+func listDownstreams(ctx context.Context, opener RepositoryOpener, namespace string, upstreams map[string]repository.PackageRevision, repositories []configapi.Repository) ([]downstream, error) {
+	var downstreams []downstream
+	for i := range repositories {
+		repositoryObj := &repositories[i]
+		if repositoryObj.Namespace != namespace {
+			continue
+		}
+		repo, err := opener.OpenRepository(ctx, repositoryObj)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open repository %s:%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+		}
+		revisions, err := repo.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+		if err != nil {
+			return nil, fmt.Errorf("cannot list package revisions of repository %s:%s: %w", repositoryObj.Namespace, repositoryObj.Name, err)
+		}
+		for _, rev := range revisions {
+			apiRev, err := rev.GetPackageRevision(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if rev.Lifecycle() == api.PackageRevisionLifecyclePublished && apiRev.Labels[api.LatestPackageRevisionKey] != api.LatestPackageRevisionValue {
+				continue
+			}
+			upstream, resolved := currentUpstream(apiRev)
+			if upstream == nil || upstream.UpstreamRef == nil {
+				continue
+			}
+			name := upstream.UpstreamRef.Name
+			if name == "" && resolved != nil {
+				name = resolved.Name
+			}
+			if _, found := upstreams[name]; found {
+				downstreams = append(downstreams, downstream{rev: rev, repositoryObj: repositoryObj, upstream: name})
+			}
+		}
+	}
+	return downstreams, nil
+}
+
+// previewUpdate merges the changes between the original upstream of rev and the target
+// resources into the resources of rev, and records the outcome in impact.
+func previewUpdate(ctx context.Context, fetcher *PackageFetcher, rev, original repository.PackageRevision, target *api.PackageRevisionResources, impact *UpdateImpact) error {
+	local, err := rev.GetResources(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read contents of package %q: %w", rev.KubeObjectName(), err)
+	}
+	originalResources, err := fetcher.GetResources(ctx, original)
+	if err != nil {
+		return err
+	}
+	localContents := repository.PackageResources{Contents: local.Spec.Resources}
+	originalContents := repository.PackageResources{Contents: originalResources.Spec.Resources}
+	targetContents := repository.PackageResources{Contents: target.Spec.Resources}
+
+	conflicts, err := updateConflicts(localContents, originalContents, targetContents)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) != 0 {
+		impact.Status, impact.Conflicts = UpdateImpactConflict, conflicts
+		return nil
+	}
+
+	updated, err := (&defaultPackageUpdater{}).Update(ctx, localContents, originalContents, targetContents)
+	if err != nil {
+		return fmt.Errorf("error merging the upstream changes: %w", err)
+	}
+	before, err := flattenPackageResources(localContents)
+	if err != nil {
+		return err
+	}
+	after, err := flattenPackageResources(updated)
+	if err != nil {
+		return err
+	}
+	if !equalFields(before, after) {
+		impact.Status = UpdateImpactChanged
+	}
+	return nil
+}
+
+// updateConflicts returns the fields, as "<resource> <field path>", that the local and
+// the target resources changed differently from the original resources. Kptfiles are
+// merged separately by the update and are not considered.
+func updateConflicts(local, original, target repository.PackageResources) ([]string, error) {
+	var flattened [3]map[string]evalFieldChange
+	for i, resources := range []repository.PackageResources{local, original, target} {
+		fields, err := flattenPackageResources(withoutKptfiles(resources))
+		if err != nil {
+			return nil, err
+		}
+		flattened[i] = fields
+	}
+	l, o, t := flattened[0], flattened[1], flattened[2]
+
+	keys := map[string]evalFieldChange{}
+	for _, fields := range flattened {
+		for k, f := range fields {
+			keys[k] = f
+		}
+	}
+	var conflicts []string
+	for k, f := range keys {
+		lf, of, tf := l[k], o[k], t[k]
+		if sameField(lf, of) || sameField(tf, of) || sameField(lf, tf) {
+			continue
+		}
+		conflicts = append(conflicts, f.resource+" "+strings.Join(f.path, "."))
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+func withoutKptfiles(resources repository.PackageResources) repository.PackageResources {
+	filtered := repository.PackageResources{Contents: map[string]string{}}
+	for file, contents := range resources.Contents {
+		if path.Base(file) != kptfile.KptFileName {
+			filtered.Contents[file] = contents
+		}
+	}
+	return filtered
+}
+
+func sameField(a, b evalFieldChange) bool {
+	return a.present == b.present && a.value == b.value
+}
+
+func equalFields(a, b map[string]evalFieldChange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, f := range a {
+		if !sameField(f, b[k]) {
+			return false
+		}
+	}
+	return true
+}
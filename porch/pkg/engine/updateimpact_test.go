@@ -0,0 +1,113 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreviewUpstreamUpdateImpact(t *testing.T) {
+	ctx := context.Background()
+	repos := &fakeRepositories{objects: map[string]*configapi.Repository{}, repositories: map[string]*fake.Repository{}}
+	addRepository := func(name string, revisions ...*fake.PackageRevision) {
+		repos.objects[name] = &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		repo := &fake.Repository{}
+		for _, rev := range revisions {
+			rev.PackageRevisionKey.Repository = name
+			repo.PackageRevisions = append(repo.PackageRevisions, rev)
+		}
+		repos.repositories[name] = repo
+	}
+	revision := func(name, pkg, revision string, lifecycle api.PackageRevisionLifecycle, color, size string, tasks ...api.Task) *fake.PackageRevision {
+		return &fake.PackageRevision{
+			Name:               name,
+			PackageRevisionKey: repository.PackageRevisionKey{Package: pkg, Revision: revision},
+			PackageLifecycle:   lifecycle,
+			PackageRevision: &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       api.PackageRevisionSpec{PackageName: pkg, Revision: revision, Lifecycle: lifecycle, Tasks: tasks},
+			},
+			Resources: &api.PackageRevisionResources{Spec: api.PackageRevisionResourcesSpec{Resources: map[string]string{
+				kptfile.KptFileName: fmt.Sprintf("apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: %s\n", pkg),
+				"configmap.yaml":    fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  color: %s\n  size: %s\n", color, size),
+			}}},
+		}
+	}
+	clone := func(upstream string) api.Task {
+		return api.Task{Type: api.TaskTypeClone, Clone: &api.PackageCloneTaskSpec{
+			Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: upstream}},
+		}}
+	}
+
+	published := api.PackageRevisionLifecyclePublished
+	draft := api.PackageRevisionLifecycleDraft
+	addRepository("blueprints",
+		revision("blueprints-a1", "app", "v1", published, "red", "small"),
+		revision("blueprints-a2", "app", "v2", published, "blue", "small"),
+		revision("blueprints-d1", "db", "v1", published, "red", "small"),
+	)
+	addRepository("deployments",
+		// Changed the size; takes the new color.
+		revision("deployments-resized-v1", "resized", "v1", draft, "red", "large", clone("blueprints-a1")),
+		// Changed the color, which the upstream changed as well.
+		revision("deployments-recolored-v1", "recolored", "v1", draft, "green", "small", clone("blueprints-a1")),
+		// Already based on the new revision.
+		revision("deployments-current-v1", "current", "v1", draft, "blue", "small", clone("blueprints-a2")),
+		// Downstream of another package.
+		revision("deployments-db-v1", "db", "v1", draft, "red", "large", clone("blueprints-d1")),
+	)
+
+	fetcher := &PackageFetcher{repoOpener: repos, referenceResolver: repos}
+	var repositories []configapi.Repository
+	for _, name := range []string{"blueprints", "deployments"} {
+		repositories = append(repositories, *repos.objects[name])
+	}
+	impacts, err := previewUpstreamUpdateImpact(ctx, fetcher, repos, "default", &api.PackageRevisionRef{Name: "blueprints-a1"}, "v2", repositories)
+	if err != nil {
+		t.Fatalf("previewUpstreamUpdateImpact failed: %v", err)
+	}
+
+	want := []UpdateImpact{
+		{Name: "deployments-current-v1", Upstream: "blueprints-a2", Status: UpdateImpactUnchanged},
+		{
+			Name:      "deployments-recolored-v1",
+			Upstream:  "blueprints-a1",
+			Status:    UpdateImpactConflict,
+			Conflicts: []string{"configmap.yaml: v1/ConfigMap/app data.color"},
+		},
+		{Name: "deployments-resized-v1", Upstream: "blueprints-a1", Status: UpdateImpactChanged},
+	}
+	if diff := cmp.Diff(want, impacts); diff != "" {
+		t.Errorf("unexpected impacts (-want, +got): %s", diff)
+	}
+
+	// Previewing doesn't update the downstreams.
+	for _, rev := range repos.repositories["deployments"].PackageRevisions {
+		resources, _ := rev.GetResources(ctx)
+		if rev.KubeObjectName() == "deployments-resized-v1" && resources.Spec.Resources["configmap.yaml"] != "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  color: red\n  size: large\n" {
+			t.Errorf("downstream was modified:\n%s", resources.Spec.Resources["configmap.yaml"])
+		}
+	}
+}
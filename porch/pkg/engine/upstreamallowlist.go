@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpstreamNotAllowedError is returned when a package would be cloned from or updated to an
+// upstream which isn't in the allowed upstreams of its repository.
+//
+// UpstreamNotAllowedError is an API status error: it is returned to clients as a Forbidden
+// status.
+type UpstreamNotAllowedError struct {
+	// Upstream describes the offending upstream.
+	Upstream string
+	// Allowed describes the allowed upstreams.
+	Allowed []string
+}
+
+var _ apierrors.APIStatus = &UpstreamNotAllowedError{}
+
+func (e *UpstreamNotAllowedError) Error() string {
+	return fmt.Sprintf("upstream %s is not allowed for the repository; allowed upstreams: %s", e.Upstream, strings.Join(e.Allowed, ", "))
+}
+
+// Status implements apierrors.APIStatus.
+func (e *UpstreamNotAllowedError) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: e.Error(),
+		Details: &metav1.StatusDetails{
+			Group: api.SchemeGroupVersion.Group,
+			Kind:  "packagerevisions",
+		},
+	}
+}
+
+// checkAllowedUpstream returns an UpstreamNotAllowedError if the upstream doesn't match any
+// of the allowed upstreams. An empty list allows all upstreams.
+func checkAllowedUpstream(allowed []configapi.AllowedUpstream, upstream *api.UpstreamPackage) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	var description string
+	var matches func(configapi.AllowedUpstream) bool
+	switch {
+	case upstream.UpstreamRef != nil:
+		ref := upstream.UpstreamRef
+		repositoryName := ref.Repository
+		if ref.Name != "" {
+			var err error
+			if repositoryName, err = parseUpstreamRepository(ref.Name); err != nil {
+				return err
+			}
+		}
+		// Repositories of other namespaces are allowed by their namespace and name.
+		if ref.Namespace != "" {
+			repositoryName = ref.Namespace + "/" + repositoryName
+		}
+		description = fmt.Sprintf("%q in repository %q", describePackageRevisionRef(ref), repositoryName)
+		matches = func(a configapi.AllowedUpstream) bool {
+			return a.Repository != "" && a.Repository == repositoryName
+		}
+	case upstream.Git != nil:
+		description = fmt.Sprintf("git repository %q", upstream.Git.Repo)
+		matches = func(a configapi.AllowedUpstream) bool {
+			return a.Git != "" && hasAddressPrefix(upstream.Git.Repo, a.Git)
+		}
+	case upstream.Oci != nil:
+		description = fmt.Sprintf("oci image %q", upstream.Oci.Image)
+		matches = func(a configapi.AllowedUpstream) bool {
+			return a.Oci != "" && hasAddressPrefix(upstream.Oci.Image, a.Oci)
+		}
+	default:
+		// Invalid upstreams are reported when they are fetched.
+		return nil
+	}
+
+	for _, a := range allowed {
+		if matches(a) {
+			return nil
+		}
+	}
+	return &UpstreamNotAllowedError{Upstream: description, Allowed: describeAllowedUpstreams(allowed)}
+}
+
+// hasAddressPrefix returns true if address is prefix, or continues it with a new path
+// element, tag or digest. https://github.com/example thus matches
+// https://github.com/example/blueprints.git but not https://github.com/example-fork.
+func hasAddressPrefix(address, prefix string) bool {
+	if !strings.HasPrefix(address, prefix) {
+		return false
+	}
+	if len(address) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return strings.ContainsRune("/:@", rune(address[len(prefix)]))
+}
+
+func describeAllowedUpstreams(allowed []configapi.AllowedUpstream) []string {
+	var descriptions []string
+	for _, a := range allowed {
+		switch {
+		case a.Repository != "":
+			descriptions = append(descriptions, fmt.Sprintf("repository %q", a.Repository))
+		case a.Git != "":
+			descriptions = append(descriptions, fmt.Sprintf("git %q", a.Git))
+		case a.Oci != "":
+			descriptions = append(descriptions, fmt.Sprintf("oci %q", a.Oci))
+		}
+	}
+	return descriptions
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestCheckAllowedUpstream(t *testing.T) {
+	allowed := []configapi.AllowedUpstream{
+		{Repository: "blueprints"},
+		{Git: "https://github.com/example"},
+		{Oci: "us-docker.pkg.dev/example/packages/"},
+	}
+
+	testCases := map[string]struct {
+		allowed  []configapi.AllowedUpstream
+		upstream api.UpstreamPackage
+		wantDeny string
+	}{
+		"porch ref by name": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-0123456789abcdef"}},
+		},
+		"porch ref by repository and package": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Repository: "blueprints", Package: "basens", Revision: "v1"}},
+		},
+		"porch ref of other repository": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-fork-0123456789abcdef"}},
+			wantDeny: `repository "blueprints-fork"`,
+		},
+		"porch ref of shared repository": {
+			allowed:  []configapi.AllowedUpstream{{Repository: "platform/blueprints"}},
+			upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-0123456789abcdef", Namespace: "platform"}},
+		},
+		"porch ref of repository with the name of a shared one": {
+			allowed:  []configapi.AllowedUpstream{{Repository: "platform/blueprints"}},
+			upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-0123456789abcdef"}},
+			wantDeny: `repository "blueprints"`,
+		},
+		"git prefix": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{Git: &api.GitPackage{Repo: "https://github.com/example/blueprints.git"}},
+		},
+		"git exact": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{Git: &api.GitPackage{Repo: "https://github.com/example"}},
+		},
+		"git prefix of other organization": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{Git: &api.GitPackage{Repo: "https://github.com/example-fork/blueprints.git"}},
+			wantDeny: `git repository "https://github.com/example-fork/blueprints.git"`,
+		},
+		"oci prefix": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{Oci: &api.OciPackage{Image: "us-docker.pkg.dev/example/packages/basens:v1"}},
+		},
+		"oci of other registry": {
+			allowed:  allowed,
+			upstream: api.UpstreamPackage{Oci: &api.OciPackage{Image: "docker.io/example/basens:v1"}},
+			wantDeny: `oci image "docker.io/example/basens:v1"`,
+		},
+		"git upstream with only porch repositories allowed": {
+			allowed:  []configapi.AllowedUpstream{{Repository: "blueprints"}},
+			upstream: api.UpstreamPackage{Git: &api.GitPackage{Repo: "https://github.com/example/blueprints.git"}},
+			wantDeny: `git repository "https://github.com/example/blueprints.git"`,
+		},
+		"empty list is unrestricted": {
+			upstream: api.UpstreamPackage{Oci: &api.OciPackage{Image: "docker.io/example/basens:v1"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := checkAllowedUpstream(tc.allowed, &tc.upstream)
+			if tc.wantDeny == "" {
+				if err != nil {
+					t.Fatalf("upstream was denied: %v", err)
+				}
+				return
+			}
+			if !apierrors.IsForbidden(err) {
+				t.Fatalf("expected a forbidden error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantDeny) {
+				t.Errorf("error %q doesn't name the upstream %s", err, tc.wantDeny)
+			}
+			for _, allowed := range describeAllowedUpstreams(tc.allowed) {
+				if !strings.Contains(err.Error(), allowed) {
+					t.Errorf("error %q doesn't name the allowed upstream %s", err, allowed)
+				}
+			}
+		})
+	}
+}
+
+func TestUpstreamDeniedBeforeFetch(t *testing.T) {
+	allowed := []configapi.AllowedUpstream{{Repository: "blueprints"}}
+	upstream := api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "untrusted-0123456789abcdef"}}
+
+	// The mutations have no repository opener or reference resolver; fetching would panic.
+	clone := &clonePackageMutation{
+		task: &api.Task{
+			Type:  api.TaskTypeClone,
+			Clone: &api.PackageCloneTaskSpec{Upstream: upstream},
+		},
+		namespace:        "default",
+		allowedUpstreams: allowed,
+	}
+	if _, _, err := clone.Apply(context.Background(), repository.PackageResources{}); !apierrors.IsForbidden(err) {
+		t.Errorf("clone: expected a forbidden error, got %v", err)
+	}
+
+	update := &updatePackageMutation{
+		cloneTask: &api.Task{
+			Type:  api.TaskTypeClone,
+			Clone: &api.PackageCloneTaskSpec{Upstream: api.UpstreamPackage{UpstreamRef: &api.PackageRevisionRef{Name: "blueprints-0123456789abcdef"}}},
+		},
+		updateTask: &api.Task{
+			Type:   api.TaskTypeUpdate,
+			Update: &api.PackageUpdateTaskSpec{Upstream: upstream},
+		},
+		namespace:        "default",
+		allowedUpstreams: allowed,
+	}
+	if _, _, err := update.Apply(context.Background(), repository.PackageResources{}); !apierrors.IsForbidden(err) {
+		t.Errorf("update: expected a forbidden error, got %v", err)
+	}
+}
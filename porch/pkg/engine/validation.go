@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The errors of invalid requests are API status errors, so that the apiserver returns
+// them to clients as client errors naming the offending field rather than as internal
+// errors.
+
+var supportedLifecycles = []string{
+	string(api.PackageRevisionLifecycleDraft),
+	string(api.PackageRevisionLifecycleProposed),
+	string(api.PackageRevisionLifecyclePublished),
+}
+
+var supportedConditionStatuses = []string{
+	string(api.ConditionTrue),
+	string(api.ConditionFalse),
+	string(api.ConditionUnknown),
+}
+
+// invalidPackageRevision returns an Invalid error for the package revision obj.
+func invalidPackageRevision(obj *api.PackageRevision, errs ...*field.Error) error {
+	return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, errs)
+}
+
+// unsupportedLifecycle returns an Invalid error for the lifecycle of obj, which isn't
+// one of the lifecycle values.
+func unsupportedLifecycle(obj *api.PackageRevision) error {
+	return invalidPackageRevision(obj, field.NotSupported(field.NewPath("spec", "lifecycle"), obj.Spec.Lifecycle, supportedLifecycles))
+}
+
+// notDraftConflict returns a Conflict error for a change of the resources of the
+// package revision name, which can only be made while it is a draft.
+func notDraftConflict(name string, lifecycle api.PackageRevisionLifecycle, change string) error {
+	return apierrors.NewConflict(api.PackageRevisionResourcesGVR.GroupResource(), name,
+		fmt.Errorf("cannot %s a package revision with lifecycle value %q; package must be Draft", change, lifecycle))
+}
+
+// validateConditions returns an Invalid error if a condition of obj has a status other
+// than True, False or Unknown; conditions are recorded in the Kptfile, which only has
+// those.
+func validateConditions(obj *api.PackageRevision) error {
+	var allErrs field.ErrorList
+	for i, c := range obj.Status.Conditions {
+		switch c.Status {
+		case api.ConditionTrue, api.ConditionFalse, api.ConditionUnknown:
+			// valid
+		default:
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("status", "conditions").Index(i).Child("status"), c.Status, supportedConditionStatuses))
+		}
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, allErrs)
+	}
+	return nil
+}
+
+// taskPath returns the path of task, which must be one of the tasks of obj.
+func taskPath(obj *api.PackageRevision, task *api.Task) *field.Path {
+	path := field.NewPath("spec", "tasks")
+	for i := range obj.Spec.Tasks {
+		if &obj.Spec.Tasks[i] == task {
+			return path.Index(i)
+		}
+	}
+	return path
+}
+
+// missingTaskSpec returns an Invalid error for task of obj, which lacks the field
+// holding the specification of its type.
+func missingTaskSpec(obj *api.PackageRevision, task *api.Task, fieldName string) error {
+	return invalidPackageRevision(obj, field.Required(taskPath(obj, task).Child(fieldName),
+		fmt.Sprintf("%s not set for task of type %q", fieldName, task.Type)))
+}
+
+// invalidTask returns err, the error of mapping or validating task of obj, identifying
+// the task by its index and type.
+func invalidTask(obj *api.PackageRevision, task *api.Task, err error) error {
+	return fmt.Errorf("%s (type %q): %w", taskPath(obj, task), task.Type, err)
+}
+
+// supportedTaskTypes returns the built-in task types and the types of the registered
+// custom tasks.
+func (cad *cadEngine) supportedTaskTypes() []string {
+	var types []string
+	for t := range builtinTaskTypes {
+		types = append(types, string(t))
+	}
+	for t := range cad.taskHandlers {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// assertInvalidField checks that err is an Invalid error of the field path.
+func assertInvalidField(t *testing.T, err error, path string) {
+	t.Helper()
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("got error %v; want an Invalid error", err)
+	}
+	var status apierrors.APIStatus
+	errors.As(err, &status)
+	details := status.Status().Details
+	if details == nil || len(details.Causes) != 1 || details.Causes[0].Field != path {
+		t.Errorf("got error details %+v; want an error of field %s", details, path)
+	}
+}
+
+func TestCreatePackageRevisionLifecycle(t *testing.T) {
+	for _, tc := range []struct {
+		lifecycle api.PackageRevisionLifecycle
+		wantField string
+	}{
+		{api.PackageRevisionLifecyclePublished, "spec.lifecycle"},
+		{"Final", "spec.lifecycle"},
+	} {
+		t.Run(string(tc.lifecycle), func(t *testing.T) {
+			cad := &cadEngine{}
+			obj := &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+				Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: "v1", Lifecycle: tc.lifecycle},
+			}
+			_, err := cad.CreatePackageRevision(context.Background(), &configapi.Repository{}, obj, nil)
+			assertInvalidField(t, err, tc.wantField)
+		})
+	}
+}
+
+func TestUpdatePackageResourcesNotDraft(t *testing.T) {
+	cad := &cadEngine{}
+	pkgRev := &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+		Name:               "blueprints-app-v1",
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: "v1"},
+		PackageLifecycle:   api.PackageRevisionLifecycleProposed,
+		PackageRevision: &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+			Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: "v1", Lifecycle: api.PackageRevisionLifecycleProposed},
+		},
+	}}
+
+	_, err := cad.UpdatePackageResources(context.Background(), &configapi.Repository{}, pkgRev, &api.PackageRevisionResources{}, &api.PackageRevisionResources{})
+	if !apierrors.IsConflict(err) {
+		t.Errorf("got error %v; want a Conflict error", err)
+	}
+}
+
+func TestUpdateMutationsAppendedCreationTask(t *testing.T) {
+	cad := &cadEngine{}
+	oldObj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Lifecycle:   api.PackageRevisionLifecycleDraft,
+			Tasks:       []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}}},
+		},
+	}
+	newObj := oldObj.DeepCopy()
+	newObj.Spec.Tasks = append(newObj.Spec.Tasks, api.Task{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}})
+
+	_, err := cad.updateMutations(context.Background(), nil, &configapi.Repository{}, oldObj, newObj)
+	assertInvalidField(t, err, "spec.tasks[1].type")
+}
+
+func TestUnsupportedTaskType(t *testing.T) {
+	cad := &cadEngine{}
+	obj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1"},
+		Spec: api.PackageRevisionSpec{
+			PackageName: "app",
+			Tasks:       []api.Task{{Type: api.TaskTypeInit, Init: &api.PackageInitTaskSpec{}}, {Type: "Unknown"}},
+		},
+	}
+	_, err := cad.mapTaskToMutation(context.Background(), obj, &obj.Spec.Tasks[1], false, nil)
+	assertInvalidField(t, err, "spec.tasks[1].type")
+
+	obj.Spec.Tasks[0].Init = nil
+	_, err = cad.mapTaskToMutation(context.Background(), obj, &obj.Spec.Tasks[0], false, nil)
+	assertInvalidField(t, err, "spec.tasks[0].init")
+}
+
+func TestUpdatePackageRevisionConditionStatus(t *testing.T) {
+	cad := &cadEngine{}
+	oldObj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "blueprints-app-v1", Namespace: "default"},
+		Spec:       api.PackageRevisionSpec{PackageName: "app", Revision: "v1", Lifecycle: api.PackageRevisionLifecycleDraft},
+	}
+	oldPackage := &PackageRevision{repoPackageRevision: &fake.PackageRevision{
+		Name:               "blueprints-app-v1",
+		PackageRevisionKey: repository.PackageRevisionKey{Repository: "blueprints", Package: "app", Revision: "v1"},
+		PackageLifecycle:   api.PackageRevisionLifecycleDraft,
+		PackageRevision:    oldObj,
+	}}
+	newObj := oldObj.DeepCopy()
+	newObj.Status.Conditions = []api.Condition{
+		{Type: "Ready", Status: api.ConditionTrue},
+		{Type: "Reviewed", Status: "Maybe"},
+	}
+
+	_, err := cad.updatePackageRevision(context.Background(), &fake.Repository{}, &configapi.Repository{}, oldPackage, oldObj, newObj, nil)
+	assertInvalidField(t, err, "status.conditions[1].status")
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpstreamSignatureFile is the file of an upstream package holding the base64-encoded
+// signature of the package digest (see PackageDigest). It isn't part of the digest,
+// and it is dropped from the cloned package once verified.
+const UpstreamSignatureFile = "package.sig"
+
+// UpstreamVerifier verifies the contents of an upstream package before they are
+// cloned, for example by checking a signature or attestation.
+type UpstreamVerifier interface {
+	// VerifyUpstream returns an error if the contents of the named upstream package
+	// cannot be verified.
+	VerifyUpstream(ctx context.Context, name string, contents map[string]string) error
+}
+
+// UpstreamVerificationError is returned when an upstream package fails verification.
+type UpstreamVerificationError struct {
+	Package string
+	Reason  string
+}
+
+func (e *UpstreamVerificationError) Error() string {
+	return fmt.Sprintf("upstream package %q failed verification: %s", e.Package, e.Reason)
+}
+
+// PackageDigest returns the digest of the package contents, "sha256:<hex>". It is the
+// SHA-256 of a sha256sum-style manifest listing the SHA-256 of each file, sorted by
+// path, excluding UpstreamSignatureFile.
+func PackageDigest(contents map[string]string) string {
+	var paths []string
+	for path := range contents {
+		if path != UpstreamSignatureFile {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	manifest := sha256.New()
+	for _, path := range paths {
+		sum := sha256.Sum256([]byte(contents[path]))
+		fmt.Fprintf(manifest, "%s  %s\n", hex.EncodeToString(sum[:]), path)
+	}
+	return "sha256:" + hex.EncodeToString(manifest.Sum(nil))
+}
+
+// signatureVerifier verifies the signature of the package digest in UpstreamSignatureFile
+// against a set of public keys, the way `cosign verify-blob` verifies a signed blob
+// containing the digest.
+type signatureVerifier struct {
+	keys []crypto.PublicKey
+}
+
+var _ UpstreamVerifier = &signatureVerifier{}
+
+// NewSignatureVerifier returns an UpstreamVerifier accepting packages signed by any of
+// the PEM-encoded public keys. ECDSA, Ed25519 and RSA (PKCS #1 v1.5) keys are supported.
+func NewSignatureVerifier(keysPEM []byte) (UpstreamVerifier, error) {
+	v := &signatureVerifier{}
+	for rest := keysPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse public key: %w", err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		v.keys = append(v.keys, key)
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no PEM-encoded public keys found")
+	}
+	return v, nil
+}
+
+func (v *signatureVerifier) VerifyUpstream(ctx context.Context, name string, contents map[string]string) error {
+	encoded, found := contents[UpstreamSignatureFile]
+	if !found {
+		return &UpstreamVerificationError{Package: name, Reason: fmt.Sprintf("package is not signed (%s not found)", UpstreamSignatureFile)}
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return &UpstreamVerificationError{Package: name, Reason: fmt.Sprintf("malformed signature: %v", err)}
+	}
+
+	message := []byte(PackageDigest(contents))
+	hash := sha256.Sum256(message)
+	for _, key := range v.keys {
+		var ok bool
+		switch key := key.(type) {
+		case *ecdsa.PublicKey:
+			ok = ecdsa.VerifyASN1(key, hash[:], sig)
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(key, message, sig)
+		case *rsa.PublicKey:
+			ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) == nil
+		}
+		if ok {
+			return nil
+		}
+	}
+	return &UpstreamVerificationError{Package: name, Reason: "signature does not match any trusted key"}
+}
+
+// withoutSignature returns the package contents without UpstreamSignatureFile.
+func withoutSignature(contents map[string]string) map[string]string {
+	if _, found := contents[UpstreamSignatureFile]; !found {
+		return contents
+	}
+	result := make(map[string]string, len(contents)-1)
+	for k, v := range contents {
+		if k != UpstreamSignatureFile {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+)
+
+func encodePublicKey(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey failed: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func upstreamContents() map[string]string {
+	return map[string]string{
+		kptfile.KptFileName: "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: bucket\n",
+		"bucket.yaml":       "apiVersion: storage.cnrm.cloud.google.com/v1beta1\nkind: StorageBucket\nmetadata:\n  name: bucket\n",
+	}
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	signECDSA := func(key *ecdsa.PrivateKey) func(contents map[string]string) {
+		return func(contents map[string]string) {
+			hash := sha256.Sum256([]byte(PackageDigest(contents)))
+			sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+			if err != nil {
+				t.Fatalf("SignASN1 failed: %v", err)
+			}
+			contents[UpstreamSignatureFile] = base64.StdEncoding.EncodeToString(sig) + "\n"
+		}
+	}
+	signEd25519 := func(contents map[string]string) {
+		sig := ed25519.Sign(edKey, []byte(PackageDigest(contents)))
+		contents[UpstreamSignatureFile] = base64.StdEncoding.EncodeToString(sig)
+	}
+
+	keys := append(encodePublicKey(t, &ecKey.PublicKey), encodePublicKey(t, edPublic)...)
+	verifier, err := NewSignatureVerifier(keys)
+	if err != nil {
+		t.Fatalf("NewSignatureVerifier failed: %v", err)
+	}
+
+	testCases := map[string]struct {
+		sign    func(contents map[string]string)
+		tamper  bool
+		wantErr bool
+	}{
+		"ecdsa":           {sign: signECDSA(ecKey)},
+		"ed25519":         {sign: signEd25519},
+		"unsigned":        {wantErr: true},
+		"untrusted key":   {sign: signECDSA(otherKey), wantErr: true},
+		"tampered":        {sign: signECDSA(ecKey), tamper: true, wantErr: true},
+		"malformed":       {sign: func(contents map[string]string) { contents[UpstreamSignatureFile] = "not base64!" }, wantErr: true},
+		"signature added": {sign: func(contents map[string]string) { signEd25519(contents); contents["extra.yaml"] = "" }, wantErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			contents := upstreamContents()
+			if tc.sign != nil {
+				tc.sign(contents)
+			}
+			if tc.tamper {
+				contents["bucket.yaml"] += "  namespace: other\n"
+			}
+			err := verifier.VerifyUpstream(context.Background(), "blueprints-v1", contents)
+			if tc.wantErr {
+				var verr *UpstreamVerificationError
+				if !errors.As(err, &verr) {
+					t.Errorf("expected verification error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("VerifyUpstream failed: %v", err)
+			}
+		})
+	}
+
+	if _, err := NewSignatureVerifier([]byte("no keys here")); err == nil {
+		t.Errorf("expected NewSignatureVerifier to fail without keys")
+	}
+}
+
+func TestCloneVerifiesUpstream(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	verifier, err := NewSignatureVerifier(encodePublicKey(t, &key.PublicKey))
+	if err != nil {
+		t.Fatalf("NewSignatureVerifier failed: %v", err)
+	}
+
+	signed := upstreamContents()
+	hash := sha256.Sum256([]byte(PackageDigest(signed)))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("SignASN1 failed: %v", err)
+	}
+	signed[UpstreamSignatureFile] = base64.StdEncoding.EncodeToString(sig)
+
+	testCases := map[string]struct {
+		contents map[string]string
+		verifier UpstreamVerifier
+		wantErr  bool
+	}{
+		"unsigned without verifier": {contents: upstreamContents()},
+		"unsigned with verifier":    {contents: upstreamContents(), verifier: verifier, wantErr: true},
+		"signed with verifier":      {contents: signed, verifier: verifier},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			contents := map[string]string{}
+			for k, v := range tc.contents {
+				contents[k] = v
+			}
+			cpm := clonePackageMutation{
+				task: &v1alpha1.Task{
+					Type: v1alpha1.TaskTypeClone,
+					Clone: &v1alpha1.PackageCloneTaskSpec{
+						Upstream: v1alpha1.UpstreamPackage{
+							UpstreamRef: &v1alpha1.PackageRevisionRef{Name: "blueprints-v1"},
+						},
+					},
+				},
+				namespace: "test-namespace",
+				name:      "downstream",
+				repoOpener: &fakeRepositoryOpener{
+					repository: &fake.Repository{
+						PackageRevisions: []repository.PackageRevision{&fake.PackageRevision{
+							Name:             "blueprints-v1",
+							PackageLifecycle: v1alpha1.PackageRevisionLifecyclePublished,
+							Resources: &v1alpha1.PackageRevisionResources{
+								Spec: v1alpha1.PackageRevisionResourcesSpec{Resources: contents},
+							},
+							Kptfile: kptfile.KptFile{
+								Upstream:     &kptfile.Upstream{},
+								UpstreamLock: &kptfile.UpstreamLock{},
+							},
+						}},
+					},
+				},
+				referenceResolver: &fakeReferenceResolver{},
+				upstreamVerifier:  tc.verifier,
+			}
+
+			result, _, err := cpm.Apply(context.Background(), repository.PackageResources{})
+			if tc.wantErr {
+				var verr *UpstreamVerificationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("expected verification error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clone failed: %v", err)
+			}
+			if _, found := result.Contents[UpstreamSignatureFile]; found {
+				t.Errorf("signature must not be cloned into the downstream package")
+			}
+			if _, found := result.Contents["bucket.yaml"]; !found {
+				t.Errorf("cloned package is missing bucket.yaml")
+			}
+		})
+	}
+}
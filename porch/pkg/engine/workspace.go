@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// WorkspaceNameVars are the variables available to a workspace name template.
+type WorkspaceNameVars struct {
+	// Package is the last segment of the package name.
+	Package string
+	// PackagePath is the full package name, including parent directories.
+	PackagePath string
+	// User is the name of the user creating the package revision, if known.
+	User string
+	// Timestamp is the creation time in UTC, formatted as 20060102-150405.
+	Timestamp string
+	// Key is the value of the api.WorkspaceKeyAnnotation annotation of the package revision.
+	Key string
+}
+
+// unsafeRefChars matches runs of characters that are not allowed, or not
+// advisable, in a Git branch name.
+var unsafeRefChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+var workspaceNameFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	// sanitize replaces characters that can't be used in a workspace name with '-'.
+	"sanitize": func(s string) string {
+		return strings.Trim(unsafeRefChars.ReplaceAllString(s, "-"), "-")
+	},
+}
+
+// ParseWorkspaceNameTemplate parses a text/template generating workspace names from
+// WorkspaceNameVars, for example "{{.Key}}-{{.Package}}-{{.Timestamp}}".
+func ParseWorkspaceNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("workspace-name").Funcs(workspaceNameFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace name template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// generateWorkspaceName sets the revision of a package revision being created from
+// the workspace name template. An explicitly set revision is kept.
+func (cad *cadEngine) generateWorkspaceName(ctx context.Context, obj *api.PackageRevision) error {
+	if cad.workspaceNameTemplate == nil || obj.Spec.Revision != "" {
+		return nil
+	}
+
+	vars := WorkspaceNameVars{
+		Package:     path.Base(obj.Spec.PackageName),
+		PackagePath: obj.Spec.PackageName,
+		Timestamp:   time.Now().UTC().Format("20060102-150405"),
+		Key:         obj.Annotations[api.WorkspaceKeyAnnotation],
+	}
+	if cad.userInfoProvider != nil {
+		if userInfo := cad.userInfoProvider.GetUserInfo(ctx); userInfo != nil {
+			vars.User = userInfo.Name
+		}
+	}
+
+	var name strings.Builder
+	if err := cad.workspaceNameTemplate.Execute(&name, vars); err != nil {
+		return fmt.Errorf("cannot generate workspace name: %w", err)
+	}
+	if err := validateWorkspaceName(name.String()); err != nil {
+		return fmt.Errorf("generated workspace name %q is invalid: %w", name.String(), err)
+	}
+	obj.Spec.Revision = name.String()
+	return nil
+}
+
+// validateWorkspaceName checks that the workspace name can be used as the last
+// component of a Git branch name, following the rules of git check-ref-format.
+func validateWorkspaceName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("workspace name must not be empty")
+	case name == "@":
+		return fmt.Errorf("workspace name must not be %q", name)
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "-"):
+		return fmt.Errorf("workspace name must not start with %q", name[:1])
+	case strings.HasSuffix(name, "."), strings.HasSuffix(name, ".lock"):
+		return fmt.Errorf("workspace name must not end with '.' or \".lock\"")
+	case strings.Contains(name, ".."), strings.Contains(name, "@{"):
+		return fmt.Errorf("workspace name must not contain \"..\" or \"@{\"")
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\/", r) {
+			return fmt.Errorf("workspace name must not contain %q", r)
+		}
+	}
+	return nil
+}
+
+// The revision of a package revision doubles as its workspace name: it names the
+// draft branch while the package revision is unpublished and the tag once it is
+// published. It is chosen, or generated, when the package revision is created and
+// never changes afterwards, including when the package revision is replayed.
+
+// validateNewRevision validates the revision of a package revision being created,
+// after the workspace name has been generated.
+func validateNewRevision(obj *api.PackageRevision) error {
+	if err := validateWorkspaceName(obj.Spec.Revision); err != nil {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "revision"), obj.Spec.Revision, err.Error()),
+		})
+	}
+	return nil
+}
+
+// normalizeRevisionUpdate carries the package name and revision of oldObj over to
+// newObj if they are omitted, and rejects updates changing them.
+func normalizeRevisionUpdate(oldObj, newObj *api.PackageRevision) error {
+	if newObj.Spec.PackageName == "" {
+		newObj.Spec.PackageName = oldObj.Spec.PackageName
+	}
+	if newObj.Spec.Revision == "" {
+		newObj.Spec.Revision = oldObj.Spec.Revision
+	}
+
+	var allErrs field.ErrorList
+	if newObj.Spec.PackageName != oldObj.Spec.PackageName {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "packageName"), newObj.Spec.PackageName, "field is immutable"))
+	}
+	if newObj.Spec.Revision != oldObj.Spec.Revision {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "revision"), newObj.Spec.Revision, "field is immutable"))
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), newObj.Name, allErrs)
+	}
+	return nil
+}
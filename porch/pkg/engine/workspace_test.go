@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type staticUserInfoProvider struct {
+	name string
+}
+
+func (p *staticUserInfoProvider) GetUserInfo(ctx context.Context) *repository.UserInfo {
+	return &repository.UserInfo{Name: p.name, Email: p.name}
+}
+
+func TestGenerateWorkspaceName(t *testing.T) {
+	testCases := map[string]struct {
+		template    string
+		revision    string
+		key         string
+		want        string
+		wantPattern string
+		wantErr     bool
+	}{
+		"key and package": {
+			template: "{{.Key}}-{{.Package}}",
+			key:      "TICKET-123",
+			want:     "TICKET-123-bucket",
+		},
+		"user and timestamp": {
+			template:    "{{.User | sanitize | lower}}-{{.Timestamp}}",
+			wantPattern: `^jane-example.com-[0-9]{8}-[0-9]{6}$`,
+		},
+		"explicit revision wins": {
+			template: "{{.Key}}-{{.Package}}",
+			revision: "v1",
+			key:      "TICKET-123",
+			want:     "v1",
+		},
+		"invalid ref name": {
+			template: "{{.PackagePath}}",
+			wantErr:  true,
+		},
+		"empty name": {
+			template: "{{.Key}}",
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cad, err := NewCaDEngine(
+				WithWorkspaceNameTemplate(tc.template),
+				WithUserInfoProvider(&staticUserInfoProvider{name: "Jane@example.com"}),
+			)
+			if err != nil {
+				t.Fatalf("NewCaDEngine failed: %v", err)
+			}
+
+			obj := &api.PackageRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{api.WorkspaceKeyAnnotation: tc.key},
+				},
+				Spec: api.PackageRevisionSpec{
+					PackageName: "infra/bucket",
+					Revision:    tc.revision,
+				},
+			}
+			err = cad.(*cadEngine).generateWorkspaceName(context.Background(), obj)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got workspace name %q", obj.Spec.Revision)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generateWorkspaceName failed: %v", err)
+			}
+
+			got := obj.Spec.Revision
+			if tc.wantPattern != "" {
+				if !regexp.MustCompile(tc.wantPattern).MatchString(got) {
+					t.Errorf("workspace name %q doesn't match %q", got, tc.wantPattern)
+				}
+			} else if got != tc.want {
+				t.Errorf("unexpected workspace name: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateWorkspaceName(t *testing.T) {
+	for _, name := range []string{"v1", "TICKET-123-bucket", "release_2022.10"} {
+		if err := validateWorkspaceName(name); err != nil {
+			t.Errorf("validateWorkspaceName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "@", ".hidden", "-v1", "v1.", "v1.lock", "a..b", "a@{b", "a b", "a:b", "a/b", "a~1", "a\x01"} {
+		if err := validateWorkspaceName(name); err == nil {
+			t.Errorf("validateWorkspaceName(%q) succeeded, expected error", name)
+		}
+	}
+}
+
+func TestRevisionInvariants(t *testing.T) {
+	newRevision := func(packageName, revision string) *api.PackageRevision {
+		return &api.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "blueprints-1234"},
+			Spec:       api.PackageRevisionSpec{PackageName: packageName, Revision: revision},
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		testCases := map[string]struct {
+			revision string
+			wantErr  bool
+		}{
+			"valid":   {revision: "v1"},
+			"missing": {wantErr: true},
+			"invalid": {revision: "v1/draft", wantErr: true},
+		}
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				err := validateNewRevision(newRevision("bucket", tc.revision))
+				if (err != nil) != tc.wantErr || (err != nil && !apierrors.IsInvalid(err)) {
+					t.Errorf("validateNewRevision returned %v; want Invalid error %t", err, tc.wantErr)
+				}
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		testCases := map[string]struct {
+			packageName, revision string
+			wantErr               bool
+		}{
+			"unchanged":        {packageName: "bucket", revision: "v1"},
+			"omitted":          {},
+			"changed revision": {packageName: "bucket", revision: "v2", wantErr: true},
+			"changed package":  {packageName: "database", revision: "v1", wantErr: true},
+		}
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				newObj := newRevision(tc.packageName, tc.revision)
+				err := normalizeRevisionUpdate(newRevision("bucket", "v1"), newObj)
+				if tc.wantErr {
+					if !apierrors.IsInvalid(err) {
+						t.Errorf("normalizeRevisionUpdate returned %v; want an Invalid error", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("normalizeRevisionUpdate failed: %v", err)
+				}
+				if newObj.Spec.PackageName != "bucket" || newObj.Spec.Revision != "v1" {
+					t.Errorf("unexpected package name and revision: %q, %q", newObj.Spec.PackageName, newObj.Spec.Revision)
+				}
+			})
+		}
+	})
+}
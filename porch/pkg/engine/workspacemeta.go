@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"net/url"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// maxWorkspaceDescriptionLength is the maximum length of the workspace description.
+	maxWorkspaceDescriptionLength = 1024
+	// maxWorkspaceReferenceLength is the maximum length of the workspace reference URL.
+	maxWorkspaceReferenceLength = 2048
+)
+
+var workspaceMetadataAnnotations = []string{api.WorkspaceDescriptionAnnotation, api.WorkspaceReferenceAnnotation}
+
+// validateWorkspaceMetadata validates the workspace description and reference
+// annotations of a package revision being created or updated.
+func validateWorkspaceMetadata(obj *api.PackageRevision) error {
+	path := field.NewPath("metadata", "annotations")
+	var allErrs field.ErrorList
+	if description, found := obj.Annotations[api.WorkspaceDescriptionAnnotation]; found && len(description) > maxWorkspaceDescriptionLength {
+		allErrs = append(allErrs, field.TooLong(path.Key(api.WorkspaceDescriptionAnnotation), description, maxWorkspaceDescriptionLength))
+	}
+	if reference, found := obj.Annotations[api.WorkspaceReferenceAnnotation]; found {
+		refPath := path.Key(api.WorkspaceReferenceAnnotation)
+		if len(reference) > maxWorkspaceReferenceLength {
+			allErrs = append(allErrs, field.TooLong(refPath, reference, maxWorkspaceReferenceLength))
+		} else if u, err := url.Parse(reference); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(refPath, reference, "must be an http or https URL"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), obj.Name, allErrs)
+	}
+	return nil
+}
+
+// checkWorkspaceMetadataUnchanged rejects changes of the workspace description and
+// reference of a published package revision.
+func checkWorkspaceMetadataUnchanged(oldObj, newObj *api.PackageRevision) error {
+	path := field.NewPath("metadata", "annotations")
+	var allErrs field.ErrorList
+	for _, annotation := range workspaceMetadataAnnotations {
+		oldValue, oldFound := oldObj.Annotations[annotation]
+		newValue, newFound := newObj.Annotations[annotation]
+		if oldFound != newFound || oldValue != newValue {
+			allErrs = append(allErrs, field.Forbidden(path.Key(annotation), "cannot be changed after the package revision is published"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), newObj.Name, allErrs)
+	}
+	return nil
+}
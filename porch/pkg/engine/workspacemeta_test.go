@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine/fake"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
+	metafake "github.com/GoogleContainerTools/kpt/porch/pkg/meta/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateWorkspaceMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "valid",
+			annotations: map[string]string{
+				api.WorkspaceDescriptionAnnotation: "Raise the memory limit of the frontend",
+				api.WorkspaceReferenceAnnotation:   "https://github.com/example/deployments/issues/42",
+			},
+		},
+		{
+			name:        "long description",
+			annotations: map[string]string{api.WorkspaceDescriptionAnnotation: strings.Repeat("a", maxWorkspaceDescriptionLength+1)},
+			wantErr:     true,
+		},
+		{
+			name:        "long reference",
+			annotations: map[string]string{api.WorkspaceReferenceAnnotation: "https://example.com/" + strings.Repeat("a", maxWorkspaceReferenceLength)},
+			wantErr:     true,
+		},
+		{
+			name:        "not a URL",
+			annotations: map[string]string{api.WorkspaceReferenceAnnotation: "TICKET-42"},
+			wantErr:     true,
+		},
+		{
+			name:        "not http",
+			annotations: map[string]string{api.WorkspaceReferenceAnnotation: "ftp://example.com/tickets/42"},
+			wantErr:     true,
+		},
+		{
+			name:        "no host",
+			annotations: map[string]string{api.WorkspaceReferenceAnnotation: "https:///tickets/42"},
+			wantErr:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &api.PackageRevision{ObjectMeta: metav1.ObjectMeta{Name: "deployments-1234", Annotations: tc.annotations}}
+			err := validateWorkspaceMetadata(obj)
+			if tc.wantErr {
+				if !apierrors.IsInvalid(err) {
+					t.Errorf("expected an invalid error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("validateWorkspaceMetadata failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestWorkspaceMetadataImmutableAfterPublish(t *testing.T) {
+	ctx := context.Background()
+	annotations := map[string]string{
+		api.WorkspaceDescriptionAnnotation: "Raise the memory limit of the frontend",
+		api.WorkspaceReferenceAnnotation:   "https://github.com/example/deployments/issues/42",
+	}
+	rev := &fake.PackageRevision{Name: "deployments-1234", Namespace: "default", PackageLifecycle: api.PackageRevisionLifecyclePublished}
+	pkgRevMeta := meta.PackageRevisionMeta{Name: rev.Name, Namespace: rev.Namespace, Annotations: annotations}
+	cad := &cadEngine{metadataStore: &metafake.MemoryMetadataStore{Metas: []meta.PackageRevisionMeta{pkgRevMeta}}}
+	repositoryObj := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Name: "deployments", Namespace: "default"}}
+	oldPackage := &PackageRevision{repoPackageRevision: rev, packageRevisionMeta: pkgRevMeta}
+	oldObj := &api.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: rev.Name, Namespace: rev.Namespace, Annotations: annotations},
+		Spec:       api.PackageRevisionSpec{PackageName: "frontend", Lifecycle: api.PackageRevisionLifecyclePublished},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		change  func(obj *api.PackageRevision)
+		wantErr bool
+	}{
+		{
+			name:   "other annotation",
+			change: func(obj *api.PackageRevision) { obj.Annotations["team"] = "frontend" },
+		},
+		{
+			name:    "description",
+			change:  func(obj *api.PackageRevision) { obj.Annotations[api.WorkspaceDescriptionAnnotation] = "Something else" },
+			wantErr: true,
+		},
+		{
+			name:    "removed reference",
+			change:  func(obj *api.PackageRevision) { delete(obj.Annotations, api.WorkspaceReferenceAnnotation) },
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			newObj := oldObj.DeepCopy()
+			tc.change(newObj)
+			_, err := cad.updatePackageRevision(ctx, &fake.Repository{}, repositoryObj, oldPackage, oldObj, newObj, nil)
+			if tc.wantErr {
+				if !apierrors.IsInvalid(err) {
+					t.Errorf("expected an invalid error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("updatePackageRevision failed: %v", err)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -73,3 +74,17 @@ func AnnotateCommitMessage(message string, annotation *gitAnnotation) (string, e
 
 	return message, nil
 }
+
+// AddCommitTrailers adds the trailers to the commit message, as its last paragraph.
+func AddCommitTrailers(message string, trailers []repository.CommitTrailer) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(message, "\n"))
+	sb.WriteString("\n\n")
+	for _, trailer := range trailers {
+		fmt.Fprintf(&sb, "%s: %s\n", trailer.Key, trailer.Value)
+	}
+	return sb.String()
+}
@@ -206,12 +206,17 @@ func removeTreeEntry(tree *object.Tree, name string) {
 
 // storeFile writes a blob with contents at the specified path
 func (h *commitHelper) storeFile(path, contents string) error {
+	return h.storeFileWithMode(path, contents, filemode.Regular)
+}
+
+// storeFileWithMode writes a blob with contents at the specified path, with the file mode.
+func (h *commitHelper) storeFileWithMode(path, contents string, mode filemode.FileMode) error {
 	hash, err := storeBlob(h.storer, contents)
 	if err != nil {
 		return err
 	}
 
-	if err := h.storeBlobHashInTrees(path, hash); err != nil {
+	if err := h.storeBlobHashInTrees(path, hash, mode); err != nil {
 		return err
 	}
 	return nil
@@ -367,7 +372,7 @@ added:
 }
 
 // storeBlobHashInTrees writes the (previously stored) blob hash at fullpath, marking all the directory trees as dirty.
-func (h *commitHelper) storeBlobHashInTrees(fullPath string, hash plumbing.Hash) error {
+func (h *commitHelper) storeBlobHashInTrees(fullPath string, hash plumbing.Hash, mode filemode.FileMode) error {
 	dir, file := split(fullPath)
 	if file == "" {
 		return fmt.Errorf("invalid resource path: %q; no file name", fullPath)
@@ -376,7 +381,7 @@ func (h *commitHelper) storeBlobHashInTrees(fullPath string, hash plumbing.Hash)
 	tree := h.ensureTree(dir)
 	setOrAddTreeEntry(tree, object.TreeEntry{
 		Name: file,
-		Mode: filemode.Regular,
+		Mode: mode,
 		Hash: hash,
 	})
 
@@ -16,14 +16,17 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -241,3 +244,94 @@ func TestCommitWithUser(t *testing.T) {
 		}
 	}
 }
+
+func TestGetResourcesRejectsSymlinks(t *testing.T) {
+	tempdir := t.TempDir()
+	repo := OpenGitRepositoryFromArchive(t, filepath.Join("testdata", "empty-repository.tar"), tempdir)
+
+	tree := &object.Tree{}
+	for _, entry := range []struct {
+		name     string
+		mode     filemode.FileMode
+		contents string
+	}{
+		{name: "Kptfile", mode: filemode.Regular, contents: "kind: Kptfile"},
+		{name: "link", mode: filemode.Symlink, contents: "../outside"},
+	} {
+		hash, err := storeBlob(repo.Storer, entry.contents)
+		if err != nil {
+			t.Fatalf("storeBlob(%q) failed: %v", entry.name, err)
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: entry.name, Mode: entry.mode, Hash: hash})
+	}
+	eo := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(eo); err != nil {
+		t.Fatalf("Failed to encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(eo)
+	if err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+
+	r := &gitRepository{repo: repo}
+	if _, _, err := r.getResources(context.Background(), treeHash); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Errorf("getResources of a package with a symlink returned %v; want symlink error", err)
+	}
+}
+
+func TestGetResourcesStopsAtReadLimit(t *testing.T) {
+	tempdir := t.TempDir()
+	repo := OpenGitRepositoryFromArchive(t, filepath.Join("testdata", "empty-repository.tar"), tempdir)
+
+	tree := &object.Tree{}
+	for _, name := range []string{"a.yaml", "b.yaml", "c.yaml", "d.yaml", "e.yaml"} {
+		hash, err := storeBlob(repo.Storer, strings.Repeat("x", 100))
+		if err != nil {
+			t.Fatalf("storeBlob(%q) failed: %v", name, err)
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: hash})
+	}
+	eo := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(eo); err != nil {
+		t.Fatalf("Failed to encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(eo)
+	if err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+
+	r := &gitRepository{repo: repo}
+	for name, tc := range map[string]struct {
+		limit repository.ReadLimit
+		want  repository.ReadLimitExceededError
+	}{
+		"files": {
+			limit: repository.ReadLimit{MaxFiles: 2},
+			want:  repository.ReadLimitExceededError{Limit: repository.ReadLimit{MaxFiles: 2}, Files: 3, Bytes: 300},
+		},
+		"bytes": {
+			limit: repository.ReadLimit{MaxBytes: 150},
+			want:  repository.ReadLimitExceededError{Limit: repository.ReadLimit{MaxBytes: 150}, Files: 2, Bytes: 200},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := r.getResources(repository.WithReadLimit(context.Background(), tc.limit), treeHash)
+			var limitErr *repository.ReadLimitExceededError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("getResources returned %v; want ReadLimitExceededError", err)
+			}
+			// The files after the one exceeding the limit aren't read.
+			if *limitErr != tc.want {
+				t.Errorf("getResources stopped at %+v; want %+v", *limitErr, tc.want)
+			}
+		})
+	}
+
+	resources, _, err := r.getResources(repository.WithReadLimit(context.Background(), repository.ReadLimit{MaxFiles: 5, MaxBytes: 500}), treeHash)
+	if err != nil {
+		t.Fatalf("getResources within the read limit failed: %v", err)
+	}
+	if got, want := len(resources), 5; got != want {
+		t.Errorf("getResources read %d files; want %d", got, want)
+	}
+}
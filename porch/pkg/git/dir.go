@@ -31,3 +31,9 @@ func packageInDirectory(pkg, dir string) bool {
 	}
 	return false
 }
+
+// PackageInDirectory determines whether a package specified by a path is visible
+// in a repository registered with the given Repository.spec.git.directory.
+func PackageInDirectory(pkg, directory string) bool {
+	return packageInDirectory(pkg, strings.Trim(directory, "/"))
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
@@ -57,7 +58,11 @@ func (d *gitPackageDraft) UpdateResources(ctx context.Context, new *v1alpha1.Pac
 	}
 
 	for k, v := range new.Spec.Resources {
-		ch.storeFile(path.Join(d.path, k), v)
+		mode := filemode.Regular
+		if new.Spec.FileModes[k] == v1alpha1.FileModeExecutable {
+			mode = filemode.Executable
+		}
+		ch.storeFileWithMode(path.Join(d.path, k), v, mode)
 	}
 
 	// Because we can't read the package back without a Kptfile, make sure one is present
@@ -265,6 +270,7 @@ func (r *gitRepository) commitPackageToMain(ctx context.Context, d *gitPackageDr
 	if err != nil {
 		return zero, zero, nil, fmt.Errorf("failed annotation commit message for package %s: %v", packagePath, err)
 	}
+	message = AddCommitTrailers(message, repository.CommitTrailersFrom(ctx))
 	commitHash, newPackageTreeHash, err = ch.commit(ctx, message, packagePath, d.commit)
 	if err != nil {
 		return zero, zero, nil, fmt.Errorf("failed to commit package %s to %s", packagePath, localRef)
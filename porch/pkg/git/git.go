@@ -183,6 +183,14 @@ func (r *gitRepository) CreatePackage(ctx context.Context, obj *v1alpha1.Package
 	return nil, fmt.Errorf("CreatePackage not yet supported for git repos")
 }
 
+func (r *gitRepository) UpdatePackage(ctx context.Context, old repository.Package, new *v1alpha1.Package) (repository.Package, error) {
+	ctx, span := tracer.Start(ctx, "gitRepository::UpdatePackage", trace.WithAttributes())
+	defer span.End()
+
+	// TODO: Store package metadata in the repository
+	return nil, fmt.Errorf("%w for git repos", repository.ErrPackageUpdateNotSupported)
+}
+
 func (r *gitRepository) DeletePackage(ctx context.Context, obj repository.Package) error {
 	ctx, span := tracer.Start(ctx, "gitRepository::DeletePackage", trace.WithAttributes())
 	defer span.End()
@@ -962,8 +970,13 @@ func (r *gitRepository) loadTasks(ctx context.Context, startCommit *object.Commi
 	return tasks, nil
 }
 
-func (r *gitRepository) getResources(hash plumbing.Hash) (map[string]string, error) {
+// getResources returns the contents of the files in the tree, and the modes of those
+// which aren't regular files. It stops before reading the file which exceeds the read
+// limit of ctx, if any.
+func (r *gitRepository) getResources(ctx context.Context, hash plumbing.Hash) (map[string]string, map[string]v1alpha1.FileMode, error) {
 	resources := map[string]string{}
+	var modes map[string]v1alpha1.FileMode
+	counter := repository.NewReadCounter(ctx)
 
 	tree, err := r.repo.TreeObject(hash)
 	if err == nil {
@@ -975,12 +988,30 @@ func (r *gitRepository) getResources(hash plumbing.Hash) (map[string]string, err
 			if err == io.EOF {
 				break
 			} else if err != nil {
-				return nil, fmt.Errorf("failed to load package resources: %w", err)
+				return nil, nil, fmt.Errorf("failed to load package resources: %w", err)
+			}
+
+			// The contents of a symlink would be read as its target path; packages
+			// cannot contain symlinks, as in OCI repositories.
+			if file.Mode == filemode.Symlink {
+				return nil, nil, fmt.Errorf("package cannot contain symlink (%q)", file.Name)
+			}
+
+			// The size of the blob is known without reading its contents.
+			if err := counter.Add(file.Size); err != nil {
+				return nil, nil, err
 			}
 
 			content, err := file.Contents()
 			if err != nil {
-				return nil, fmt.Errorf("failed to read package file contents: %q, %w", file.Name, err)
+				return nil, nil, fmt.Errorf("failed to read package file contents: %q, %w", file.Name, err)
+			}
+
+			if file.Mode == filemode.Executable {
+				if modes == nil {
+					modes = map[string]v1alpha1.FileMode{}
+				}
+				modes[file.Name] = v1alpha1.FileModeExecutable
 			}
 
 			// TODO: decide whether paths should include package directory or not.
@@ -988,7 +1019,7 @@ func (r *gitRepository) getResources(hash plumbing.Hash) (map[string]string, err
 			//resources[path.Join(p.path, file.Name)] = content
 		}
 	}
-	return resources, nil
+	return resources, modes, nil
 }
 
 // findLatestPackageCommit returns the latest commit from the history that pertains
@@ -700,6 +700,74 @@ func (g GitSuite) TestApproveDraft(t *testing.T) {
 	refMustExist(t, repo, finalReferenceName)
 }
 
+func (g GitSuite) TestApproveDraftCommitTrailers(t *testing.T) {
+	tempdir := t.TempDir()
+	tarfile := filepath.Join("testdata", "drafts-repository.tar")
+	repo, address := ServeGitRepositoryWithBranch(t, tarfile, tempdir, g.branch)
+
+	const (
+		repositoryName                            = "approve"
+		namespace                                 = "default"
+		finalReferenceName plumbing.ReferenceName = "refs/tags/bucket/v1"
+		deployment                                = true
+	)
+	ctx := context.Background()
+	git, err := OpenRepository(ctx, repositoryName, namespace, &configapi.GitRepository{
+		Repo:      address,
+		Branch:    g.branch,
+		Directory: "/",
+	}, deployment, tempdir, GitRepositoryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open Git repository loaded from %q: %v", tarfile, err)
+	}
+
+	revisions, err := git.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		t.Fatalf("ListPackageRevisions failed: %v", err)
+	}
+	bucket := findPackageRevision(t, revisions, repository.PackageRevisionKey{
+		Repository: repositoryName,
+		Package:    "bucket",
+		Revision:   "v1",
+	})
+
+	update, err := git.UpdatePackageRevision(ctx, bucket)
+	if err != nil {
+		t.Fatalf("UpdatePackageRevision failed: %v", err)
+	}
+	update.UpdateLifecycle(ctx, v1alpha1.PackageRevisionLifecyclePublished)
+
+	ctx = repository.WithCommitTrailers(ctx, []repository.CommitTrailer{
+		{Key: "Reviewed-By", Value: "someone@example.com"},
+		{Key: "Change-Id", Value: "I1234"},
+	})
+	if _, err := update.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	ref, err := repo.Reference(finalReferenceName, true)
+	if err != nil {
+		t.Fatalf("Reference %s not found: %v", finalReferenceName, err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("CommitObject(%s) failed: %v", ref.Hash(), err)
+	}
+
+	// The trailers are the last paragraph of the message, after the kpt annotation.
+	paragraphs := strings.Split(strings.TrimRight(commit.Message, "\n"), "\n\n")
+	if got, want := paragraphs[len(paragraphs)-1], "Reviewed-By: someone@example.com\nChange-Id: I1234"; got != want {
+		t.Errorf("commit trailers: got %q, want %q", got, want)
+	}
+	annotations, err := ExtractGitAnnotations(commit)
+	if err != nil {
+		t.Fatalf("ExtractGitAnnotations failed: %v", err)
+	}
+	if len(annotations) != 1 || annotations[0].PackagePath != "bucket" || annotations[0].Revision != "v1" {
+		t.Errorf("unexpected annotations of the approval commit: %+v", annotations)
+	}
+}
+
 func (g GitSuite) TestApproveDraftWithHistory(t *testing.T) {
 	tempdir := t.TempDir()
 	tarfile := filepath.Join("testdata", "drafts-repository.tar")
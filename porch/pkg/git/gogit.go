@@ -15,12 +15,16 @@
 package git
 
 import (
+	"context"
+
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // This file contains helpers for interacting with gogit.
@@ -74,3 +78,21 @@ func initializeOrigin(repo *git.Repository, address string) error {
 
 	return nil
 }
+
+// ListRemoteRefs lists the names of the refs in the git repository at address,
+// like `git ls-remote`, without cloning it.
+func ListRemoteRefs(ctx context.Context, address string, auth transport.AuthMethod) ([]string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: OriginName,
+		URLs: []string{address},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name().String())
+	}
+	return names, nil
+}
@@ -140,7 +140,7 @@ func (p *gitPackageRevision) GetPackageRevision(ctx context.Context) (*v1alpha1.
 }
 
 func (p *gitPackageRevision) GetResources(ctx context.Context) (*v1alpha1.PackageRevisionResources, error) {
-	resources, err := p.repo.getResources(p.tree)
+	resources, modes, err := p.repo.getResources(ctx, p.tree)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load package resources: %w", err)
 	}
@@ -168,12 +168,13 @@ func (p *gitPackageRevision) GetResources(ctx context.Context) (*v1alpha1.Packag
 			RepositoryName: key.Repository,
 
 			Resources: resources,
+			FileModes: modes,
 		},
 	}, nil
 }
 
 func (p *gitPackageRevision) GetKptfile(ctx context.Context) (kptfile.KptFile, error) {
-	resources, err := p.repo.getResources(p.tree)
+	resources, _, err := p.repo.getResources(ctx, p.tree)
 	if err != nil {
 		return kptfile.KptFile{}, fmt.Errorf("error loading package resources: %w", err)
 	}
@@ -0,0 +1,198 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+var (
+	meter = metric.Must(global.Meter("git"))
+
+	refsPrunedCounter = meter.NewInt64Counter("porch_git_refs_pruned",
+		metric.WithDescription("Number of stale draft and proposed branches deleted from git repositories"))
+	bytesPrunedCounter = meter.NewInt64Counter("porch_git_pruned_bytes",
+		metric.WithDescription("Size of the git objects reachable only from deleted stale branches"))
+)
+
+var _ repository.RefPruner = &gitRepository{}
+
+// PruneStaleRefs deletes the draft and proposed branches that no longer correspond
+// to a package revision: branches of revisions that have since been published (the
+// package revision is the tag now), and branches that no package revision is loaded
+// from, because they don't contain the package they are named after. Only branches of
+// packages in the registered directory are considered; tags and other branches are
+// never deleted.
+func (r *gitRepository) PruneStaleRefs(ctx context.Context, dryRun bool) (*repository.PruneResult, error) {
+	ctx, span := tracer.Start(ctx, "gitRepository::PruneStaleRefs", trace.WithAttributes())
+	defer span.End()
+
+	// Listing the package revisions fetches the repository.
+	revisions, err := r.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	stale, kept, err := r.findStaleRefs(revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &repository.PruneResult{}
+	if len(stale) == 0 {
+		return result, nil
+	}
+
+	var staleHashes []plumbing.Hash
+	for _, ref := range stale {
+		remote, err := refInRemoteFromRefInLocal(ref.Name())
+		if err != nil {
+			return nil, err
+		}
+		result.Refs = append(result.Refs, remote.String())
+		staleHashes = append(staleHashes, ref.Hash())
+	}
+	sort.Strings(result.Refs)
+
+	if result.Bytes, err = r.reachableSize(staleHashes, kept); err != nil {
+		return nil, fmt.Errorf("cannot compute size of stale refs: %w", err)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := r.deleteRefs(ctx, stale); err != nil {
+		return nil, fmt.Errorf("failed to delete stale refs: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("repository", r.namespace+"/"+r.name)}
+	refsPrunedCounter.Add(ctx, int64(len(stale)), attrs...)
+	bytesPrunedCounter.Add(ctx, result.Bytes, attrs...)
+	klog.Infof("pruned %d stale refs (%d bytes) from repository %s/%s", len(stale), result.Bytes, r.namespace, r.name)
+
+	return result, nil
+}
+
+// deleteRefs deletes the refs from the remote repository, and then locally. The hash of
+// each ref is the lease of its deletion: the push requires the ref to still point at it
+// on the remote, so that none of the refs is deleted if one of them was updated since.
+func (r *gitRepository) deleteRefs(ctx context.Context, refs []*plumbing.Reference) error {
+	refSpecs := newPushRefSpecBuilder()
+	for _, ref := range refs {
+		refSpecs.AddRefToDelete(ref)
+	}
+	if err := r.pushAndCleanup(ctx, refSpecs); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if err := r.repo.Storer.RemoveReference(ref.Name()); err != nil {
+			klog.Warningf("failed to remove local ref %s: %v", ref.Name(), err)
+		}
+	}
+	return nil
+}
+
+// findStaleRefs returns the stale draft and proposed branches, given the package
+// revisions of the repository, and the hashes of all other references.
+func (r *gitRepository) findStaleRefs(revisions []repository.PackageRevision) (stale []*plumbing.Reference, kept []plumbing.Hash, err error) {
+	// The branches package revisions are loaded from, and the published package revisions.
+	loaded := map[plumbing.ReferenceName]bool{}
+	published := map[repository.PackageRevisionKey]bool{}
+	for _, rev := range revisions {
+		if rev.Lifecycle() == v1alpha1.PackageRevisionLifecyclePublished {
+			published[rev.Key()] = true
+			continue
+		}
+		if gitRev, ok := rev.(*gitPackageRevision); ok && gitRev.ref != nil {
+			loaded[gitRev.ref.Name()] = true
+		}
+	}
+
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer refs.Close()
+
+	if err := refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if r.isStaleRef(ref, loaded, published) {
+			stale = append(stale, ref)
+		} else {
+			kept = append(kept, ref.Hash())
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	return stale, kept, nil
+}
+
+// isStaleRef returns true if ref is a draft or proposed branch of a package in the
+// registered directory which no package revision is loaded from, or whose package
+// revision is published.
+func (r *gitRepository) isStaleRef(ref *plumbing.Reference, loaded map[plumbing.ReferenceName]bool, published map[repository.PackageRevisionKey]bool) bool {
+	if !isDraftBranchNameInLocal(ref.Name()) && !isProposedBranchNameInLocal(ref.Name()) {
+		return false
+	}
+
+	name, revision, err := parseDraftName(ref)
+	if err != nil {
+		// Not named by Porch; leave it alone.
+		return false
+	}
+	if !packageInDirectory(name, r.directory) {
+		return false
+	}
+
+	// The revision was published; the tag represents it now.
+	if published[repository.PackageRevisionKey{Repository: r.name, Package: name, Revision: revision}] {
+		return true
+	}
+	return !loaded[ref.Name()]
+}
+
+// reachableSize returns the total size of the objects reachable from the hashes in
+// objs and not reachable from the hashes in ignore.
+func (r *gitRepository) reachableSize(objs, ignore []plumbing.Hash) (int64, error) {
+	hashes, err := revlist.Objects(r.repo.Storer, objs, ignore)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, h := range hashes {
+		size, err := r.repo.Storer.EncodedObjectSize(h)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func (g GitSuite) TestPruneStaleRefs(t *testing.T) {
+	tempdir := t.TempDir()
+	tarfile := filepath.Join("testdata", "drafts-repository.tar")
+	repo, address := ServeGitRepositoryWithBranch(t, tarfile, tempdir, g.branch)
+
+	resolve := func(name plumbing.ReferenceName) plumbing.Hash {
+		ref, err := repo.Reference(name, true)
+		if err != nil {
+			t.Fatalf("Reference(%q) failed: %v", name, err)
+		}
+		return ref.Hash()
+	}
+	setRef := func(name plumbing.ReferenceName, hash plumbing.Hash) {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+			t.Fatalf("SetReference(%q) failed: %v", name, err)
+		}
+	}
+
+	main := resolve(plumbing.NewBranchReferenceName(g.branch))
+	bucket := resolve("refs/heads/drafts/bucket/v1")
+
+	// A draft branch which doesn't contain its package; its commit is reachable only from the branch.
+	setRef("refs/heads/drafts/gone/v1", bucket)
+	if err := repo.Storer.RemoveReference("refs/heads/drafts/bucket/v1"); err != nil {
+		t.Fatalf("RemoveReference failed: %v", err)
+	}
+	// A proposed branch left behind after the package revision was published.
+	setRef("refs/heads/proposed/basens/v1", main)
+	// A branch not named by Porch.
+	setRef("refs/heads/release", main)
+
+	ctx := context.Background()
+	git, err := OpenRepository(ctx, "prune", "prune-namespace", &configapi.GitRepository{
+		Repo:   address,
+		Branch: g.branch,
+	}, true, tempdir, GitRepositoryOptions{})
+	if err != nil {
+		t.Fatalf("OpenRepository(%q) failed: %v", address, err)
+	}
+	pruner := git.(repository.RefPruner)
+
+	wantRefs := []string{
+		"refs/heads/drafts/gone/v1",
+		"refs/heads/proposed/basens/v1",
+	}
+
+	dryRun, err := pruner.PruneStaleRefs(ctx, true)
+	if err != nil {
+		t.Fatalf("PruneStaleRefs(dryRun) failed: %v", err)
+	}
+	if diff := cmp.Diff(wantRefs, dryRun.Refs); diff != "" {
+		t.Errorf("unexpected stale refs (-want, +got): %s", diff)
+	}
+	if dryRun.Bytes <= 0 {
+		t.Errorf("expected stale refs to hold objects, got %d bytes", dryRun.Bytes)
+	}
+	for _, name := range wantRefs {
+		refMustExist(t, repo, plumbing.ReferenceName(name))
+	}
+
+	pruned, err := pruner.PruneStaleRefs(ctx, false)
+	if err != nil {
+		t.Fatalf("PruneStaleRefs failed: %v", err)
+	}
+	if diff := cmp.Diff(dryRun, pruned); diff != "" {
+		t.Errorf("prune result differs from dry run (-want, +got): %s", diff)
+	}
+	for _, name := range wantRefs {
+		refMustNotExist(t, repo, plumbing.ReferenceName(name))
+	}
+	for _, name := range []plumbing.ReferenceName{
+		"refs/heads/drafts/none/v1",
+		"refs/heads/release",
+		"refs/tags/basens/v1",
+	} {
+		refMustExist(t, repo, name)
+	}
+	repositoryMustHavePackageRevision(t, git, repository.PackageRevisionKey{Repository: "prune", Package: "basens", Revision: "v1"})
+
+	again, err := pruner.PruneStaleRefs(ctx, true)
+	if err != nil {
+		t.Fatalf("PruneStaleRefs failed: %v", err)
+	}
+	if len(again.Refs) != 0 {
+		t.Errorf("expected no stale refs after pruning, got %v", again.Refs)
+	}
+}
+
+func (g GitSuite) TestPruneStaleRefsLease(t *testing.T) {
+	tempdir := t.TempDir()
+	tarfile := filepath.Join("testdata", "drafts-repository.tar")
+	repo, address := ServeGitRepositoryWithBranch(t, tarfile, tempdir, g.branch)
+
+	main, err := repo.Reference(plumbing.NewBranchReferenceName(g.branch), true)
+	if err != nil {
+		t.Fatalf("Reference(%q) failed: %v", g.branch, err)
+	}
+	// A proposed branch left behind after the package revision was published.
+	const proposed = plumbing.ReferenceName("refs/heads/proposed/basens/v1")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(proposed, main.Hash())); err != nil {
+		t.Fatalf("SetReference(%q) failed: %v", proposed, err)
+	}
+
+	ctx := context.Background()
+	opened, err := OpenRepository(ctx, "prune", "prune-namespace", &configapi.GitRepository{
+		Repo:   address,
+		Branch: g.branch,
+	}, true, tempdir, GitRepositoryOptions{})
+	if err != nil {
+		t.Fatalf("OpenRepository(%q) failed: %v", address, err)
+	}
+	git := opened.(*gitRepository)
+
+	revisions, err := git.ListPackageRevisions(ctx, repository.ListPackageRevisionFilter{})
+	if err != nil {
+		t.Fatalf("ListPackageRevisions failed: %v", err)
+	}
+	stale, _, err := git.findStaleRefs(revisions)
+	if err != nil {
+		t.Fatalf("findStaleRefs failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].Name() != "refs/remotes/origin/proposed/basens/v1" {
+		t.Fatalf("unexpected stale refs %v; want the proposed branch", stale)
+	}
+
+	// The branch is updated after it was found stale, so it must not be deleted.
+	bucket, err := repo.Reference("refs/heads/drafts/bucket/v1", true)
+	if err != nil {
+		t.Fatalf("Reference failed: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(proposed, bucket.Hash())); err != nil {
+		t.Fatalf("SetReference(%q) failed: %v", proposed, err)
+	}
+	if err := git.deleteRefs(ctx, stale); err == nil {
+		t.Errorf("deleteRefs deleted a ref updated since it was found stale")
+	}
+	ref, err := repo.Reference(proposed, false)
+	if err != nil {
+		t.Fatalf("updated ref was deleted: %v", err)
+	}
+	if ref.Hash() != bucket.Hash() {
+		t.Errorf("updated ref points at %s; want %s", ref.Hash(), bucket.Hash())
+	}
+}
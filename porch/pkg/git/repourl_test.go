@@ -0,0 +1,32 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import "testing"
+
+func TestNormalizeRepositoryURL(t *testing.T) {
+	want := "github.com/example/blueprints"
+	for _, url := range []string{
+		"https://github.com/example/blueprints.git",
+		"https://github.com/Example/blueprints/",
+		"git@github.com:example/blueprints.git",
+		"ssh://git@github.com/example/blueprints.git",
+		"https://user@github.com/example/blueprints",
+	} {
+		if got := NormalizeRepositoryURL(url); got != want {
+			t.Errorf("NormalizeRepositoryURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -41,10 +41,11 @@ var _ fn.Renderer = &renderer{}
 
 func (r *renderer) Render(ctx context.Context, pkg filesys.FileSystem, opts fn.RenderOptions) error {
 	rr := render.Renderer{
-		PkgPath:       opts.PkgPath,
-		Runtime:       opts.Runtime,
-		FileSystem:    pkg,
-		RunnerOptions: r.runnerOptions,
+		PkgPath:        opts.PkgPath,
+		Runtime:        opts.Runtime,
+		FileSystem:     pkg,
+		RunnerOptions:  r.runnerOptions,
+		MaxConcurrency: opts.MaxConcurrency,
 	}
 	return rr.Execute(printer.WithContext(ctx, &packagePrinter{}))
 }
@@ -0,0 +1,129 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"fmt"
+
+	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Backend selects where the metadata store keeps the metadata of package revisions.
+type Backend string
+
+const (
+	// CRDBackend keeps the metadata in PackageRev custom resources. This is the default.
+	CRDBackend Backend = "crd"
+	// ConfigMapBackend keeps the metadata in ConfigMaps, for installations which can't
+	// create the PackageRev CRD.
+	ConfigMapBackend Backend = "configmap"
+)
+
+// Backends are the supported backends.
+var Backends = []Backend{CRDBackend, ConfigMapBackend}
+
+// NewMetadataStore returns a metadata store keeping the metadata in the backend, with
+// the client.
+func NewMetadataStore(backend Backend, coreClient client.Client) (MetadataStore, error) {
+	storage, err := newPackageRevStorage(backend, coreClient)
+	if err != nil {
+		return nil, err
+	}
+	return &packageRevMetadataStore{storage: storage}, nil
+}
+
+// CheckReachable returns an error if the objects the backend keeps the metadata in
+// can't be listed with the client.
+func CheckReachable(ctx context.Context, backend Backend, c client.Reader) error {
+	switch backend {
+	case CRDBackend, "":
+		var list internalapi.PackageRevList
+		return c.List(ctx, &list, client.Limit(1))
+	case ConfigMapBackend:
+		var list corev1.ConfigMapList
+		return c.List(ctx, &list, client.Limit(1), client.HasLabels{PkgRevisionMetaLabel})
+	default:
+		return unknownBackend(backend)
+	}
+}
+
+func newPackageRevStorage(backend Backend, coreClient client.Client) (packageRevStorage, error) {
+	switch backend {
+	case CRDBackend, "":
+		return &crdStorage{coreClient: coreClient}, nil
+	case ConfigMapBackend:
+		return &configMapStorage{coreClient: coreClient}, nil
+	default:
+		return nil, unknownBackend(backend)
+	}
+}
+
+func unknownBackend(backend Backend) error {
+	return fmt.Errorf("unknown metadata store backend %q; must be one of %v", backend, Backends)
+}
+
+// packageRevStorage stores the PackageRevs holding the metadata of package revisions.
+// The PackageRevs of a repository are labelled with its name.
+//
+// Its methods have the semantics of the methods of the controller-runtime client for
+// PackageRevs: Update ignores the status, and UpdateStatus ignores all but the status.
+// Both fail with a Conflict error if the PackageRev changed since it was read.
+type packageRevStorage interface {
+	Get(ctx context.Context, key types.NamespacedName, obj *internalapi.PackageRev) error
+	List(ctx context.Context, namespace, repository string) ([]internalapi.PackageRev, error)
+	Create(ctx context.Context, obj *internalapi.PackageRev) error
+	Update(ctx context.Context, obj *internalapi.PackageRev) error
+	UpdateStatus(ctx context.Context, obj *internalapi.PackageRev) error
+	Delete(ctx context.Context, obj *internalapi.PackageRev) error
+}
+
+// crdStorage stores PackageRevs as custom resources.
+type crdStorage struct {
+	coreClient client.Client
+}
+
+var _ packageRevStorage = &crdStorage{}
+
+func (s *crdStorage) Get(ctx context.Context, key types.NamespacedName, obj *internalapi.PackageRev) error {
+	return s.coreClient.Get(ctx, key, obj)
+}
+
+func (s *crdStorage) List(ctx context.Context, namespace, repository string) ([]internalapi.PackageRev, error) {
+	var list internalapi.PackageRevList
+	if err := s.coreClient.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels(map[string]string{PkgRevisionRepoLabel: repository})); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (s *crdStorage) Create(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.coreClient.Create(ctx, obj)
+}
+
+func (s *crdStorage) Update(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.coreClient.Update(ctx, obj)
+}
+
+func (s *crdStorage) UpdateStatus(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.coreClient.Status().Update(ctx, obj)
+}
+
+func (s *crdStorage) Delete(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.coreClient.Delete(ctx, obj)
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PkgRevisionMetaLabel labels the ConfigMaps holding the metadata of package
+	// revisions.
+	PkgRevisionMetaLabel = "internal.porch.kpt.dev/package-revision-meta"
+
+	// configMapPrefix prefixes the names of the ConfigMaps holding the metadata of
+	// package revisions, so that they don't collide with the ConfigMaps of users.
+	configMapPrefix = "porch-packagerev-"
+	// configMapDataKey is the key of the PackageRev in the data of the ConfigMap.
+	configMapDataKey = "packagerev.json"
+)
+
+// configMapStorage stores each PackageRev as JSON in a ConfigMap of its namespace. The
+// ConfigMap has the owner references of the PackageRev, so it's garbage collected with
+// its repository, and its resource version, so that stale updates conflict.
+type configMapStorage struct {
+	coreClient client.Client
+}
+
+var _ packageRevStorage = &configMapStorage{}
+
+func (s *configMapStorage) Get(ctx context.Context, key types.NamespacedName, obj *internalapi.PackageRev) error {
+	var cm corev1.ConfigMap
+	if err := s.coreClient.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: configMapPrefix + key.Name}, &cm); err != nil {
+		return packageRevError(err, key.Name)
+	}
+	return fromConfigMap(&cm, obj)
+}
+
+func (s *configMapStorage) List(ctx context.Context, namespace, repository string) ([]internalapi.PackageRev, error) {
+	var list corev1.ConfigMapList
+	if err := s.coreClient.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels(map[string]string{
+		PkgRevisionMetaLabel: "true",
+		PkgRevisionRepoLabel: repository,
+	})); err != nil {
+		return nil, err
+	}
+	var pkgRevs []internalapi.PackageRev
+	for i := range list.Items {
+		var pkgRev internalapi.PackageRev
+		if err := fromConfigMap(&list.Items[i], &pkgRev); err != nil {
+			return nil, err
+		}
+		pkgRevs = append(pkgRevs, pkgRev)
+	}
+	return pkgRevs, nil
+}
+
+func (s *configMapStorage) Create(ctx context.Context, obj *internalapi.PackageRev) error {
+	cm, err := toConfigMap(obj)
+	if err != nil {
+		return err
+	}
+	if err := s.coreClient.Create(ctx, cm); err != nil {
+		return packageRevError(err, obj.Name)
+	}
+	setStoredMeta(obj, cm)
+	return nil
+}
+
+func (s *configMapStorage) Update(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.update(ctx, obj, func(stored *internalapi.PackageRev) {
+		status := stored.Status
+		obj.DeepCopyInto(stored)
+		stored.Status = status
+	})
+}
+
+func (s *configMapStorage) UpdateStatus(ctx context.Context, obj *internalapi.PackageRev) error {
+	return s.update(ctx, obj, func(stored *internalapi.PackageRev) {
+		obj.Status.DeepCopyInto(&stored.Status)
+	})
+}
+
+// update applies the changes of obj to the stored PackageRev with mutate. ConfigMaps
+// have no status subresource, so the stored PackageRev is read to keep the part obj
+// doesn't change.
+func (s *configMapStorage) update(ctx context.Context, obj *internalapi.PackageRev, mutate func(stored *internalapi.PackageRev)) error {
+	var stored internalapi.PackageRev
+	if err := s.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, &stored); err != nil {
+		return err
+	}
+	if obj.ResourceVersion != "" && obj.ResourceVersion != stored.ResourceVersion {
+		return apierrors.NewConflict(internalapi.KindRepository.GroupResource(), obj.Name,
+			fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+	mutate(&stored)
+
+	cm, err := toConfigMap(&stored)
+	if err != nil {
+		return err
+	}
+	if err := s.coreClient.Update(ctx, cm); err != nil {
+		return packageRevError(err, obj.Name)
+	}
+	stored.DeepCopyInto(obj)
+	setStoredMeta(obj, cm)
+	return nil
+}
+
+func (s *configMapStorage) Delete(ctx context.Context, obj *internalapi.PackageRev) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapPrefix + obj.Name,
+			Namespace: obj.Namespace,
+		},
+	}
+	if err := s.coreClient.Delete(ctx, cm); err != nil {
+		return packageRevError(err, obj.Name)
+	}
+	return nil
+}
+
+// toConfigMap returns the ConfigMap holding obj.
+func toConfigMap(obj *internalapi.PackageRev) (*corev1.ConfigMap, error) {
+	pkgRev := obj.DeepCopy()
+	// The ConfigMap keeps the identity and version of the object.
+	pkgRev.TypeMeta = metav1.TypeMeta{
+		APIVersion: internalapi.GroupVersion.String(),
+		Kind:       "PackageRev",
+	}
+	pkgRev.ResourceVersion = ""
+	pkgRev.UID = ""
+	pkgRev.CreationTimestamp = metav1.Time{}
+	pkgRev.ManagedFields = nil
+	data, err := json.Marshal(pkgRev)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the metadata of package revision %s: %w", obj.Name, err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapPrefix + obj.Name,
+			Namespace: obj.Namespace,
+			Labels: map[string]string{
+				PkgRevisionMetaLabel: "true",
+				PkgRevisionRepoLabel: obj.Labels[PkgRevisionRepoLabel],
+			},
+			OwnerReferences: obj.OwnerReferences,
+			ResourceVersion: obj.ResourceVersion,
+		},
+		Data: map[string]string{
+			configMapDataKey: string(data),
+		},
+	}, nil
+}
+
+// fromConfigMap decodes the PackageRev held by the ConfigMap into obj.
+func fromConfigMap(cm *corev1.ConfigMap, obj *internalapi.PackageRev) error {
+	data, found := cm.Data[configMapDataKey]
+	if !found {
+		return fmt.Errorf("ConfigMap %s/%s doesn't hold the metadata of a package revision", cm.Namespace, cm.Name)
+	}
+	*obj = internalapi.PackageRev{}
+	if err := json.Unmarshal([]byte(data), obj); err != nil {
+		return fmt.Errorf("cannot decode the metadata of package revision in ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	setStoredMeta(obj, cm)
+	return nil
+}
+
+// setStoredMeta sets the identity and version of obj to the ones of the ConfigMap
+// holding it.
+func setStoredMeta(obj *internalapi.PackageRev, cm *corev1.ConfigMap) {
+	obj.ResourceVersion = cm.ResourceVersion
+	obj.UID = cm.UID
+	obj.CreationTimestamp = cm.CreationTimestamp
+}
+
+// packageRevError returns err, an error of the ConfigMap holding the PackageRev name,
+// as the error of the PackageRev, as returned by the CRD backend.
+func packageRevError(err error, name string) error {
+	gr := internalapi.KindRepository.GroupResource()
+	switch {
+	case apierrors.IsNotFound(err):
+		return apierrors.NewNotFound(gr, name)
+	case apierrors.IsAlreadyExists(err):
+		return apierrors.NewAlreadyExists(gr, name)
+	case apierrors.IsConflict(err):
+		return apierrors.NewConflict(gr, name, err)
+	default:
+		return err
+	}
+}
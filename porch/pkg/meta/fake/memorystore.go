@@ -17,6 +17,7 @@ package fake
 import (
 	"context"
 
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -74,6 +75,38 @@ func (m *MemoryMetadataStore) Update(ctx context.Context, pkgRevMeta meta.Packag
 			pkgRevMeta.Name,
 		)
 	}
+	if pkgRevMeta.Artifacts == nil {
+		pkgRevMeta.Artifacts = m.Metas[i].Artifacts
+	}
+	if pkgRevMeta.Archived == nil {
+		pkgRevMeta.Archived = m.Metas[i].Archived
+	}
+	if pkgRevMeta.Documents == nil {
+		pkgRevMeta.Documents = m.Metas[i].Documents
+	}
+	if pkgRevMeta.Freeze == nil {
+		pkgRevMeta.Freeze = m.Metas[i].Freeze
+	} else if pkgRevMeta.Freeze.IsZero() {
+		pkgRevMeta.Freeze = nil
+	}
+	if pkgRevMeta.PackageMetadata == nil {
+		pkgRevMeta.PackageMetadata = m.Metas[i].PackageMetadata
+	}
+	if pkgRevMeta.DeploymentStatus == nil {
+		pkgRevMeta.DeploymentStatus = m.Metas[i].DeploymentStatus
+	} else if *pkgRevMeta.DeploymentStatus == (api.DeploymentStatus{}) {
+		pkgRevMeta.DeploymentStatus = nil
+	}
+	if pkgRevMeta.TaskResults == nil {
+		pkgRevMeta.TaskResults = m.Metas[i].TaskResults
+	}
+	if pkgRevMeta.Deletions == nil {
+		pkgRevMeta.Deletions = m.Metas[i].Deletions
+	}
+	if pkgRevMeta.ConditionChanges == nil {
+		pkgRevMeta.ConditionChanges = m.Metas[i].ConditionChanges
+	}
+	pkgRevMeta.LifecycleTimes = mergeLifecycleTimes(m.Metas[i].LifecycleTimes, pkgRevMeta.LifecycleTimes)
 	m.Metas[i] = pkgRevMeta
 	return pkgRevMeta, nil
 }
@@ -99,3 +132,19 @@ func (m *MemoryMetadataStore) Delete(ctx context.Context, namespacedName types.N
 	m.Metas = metas
 	return deletedMeta, nil
 }
+
+func mergeLifecycleTimes(current, update meta.LifecycleTimes) meta.LifecycleTimes {
+	if !update.DraftCreatedAt.IsZero() {
+		current.DraftCreatedAt = update.DraftCreatedAt
+	}
+	if !update.ProposedAt.IsZero() {
+		current.ProposedAt = update.ProposedAt
+	}
+	if update.ProposedBy != "" {
+		current.ProposedBy = update.ProposedBy
+	}
+	if !update.PublishedAt.IsZero() {
+		current.PublishedAt = update.PublishedAt
+	}
+	return current
+}
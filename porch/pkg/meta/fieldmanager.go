@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// FieldManagersAnnotation stores which field manager owns each label and
+	// annotation key of a PackageRevision.
+	FieldManagersAnnotation = "internal.porch.kpt.dev/field-managers"
+
+	// DefaultFieldManager is used for metadata writes that don't identify a field manager.
+	DefaultFieldManager = "porch-server"
+)
+
+// FieldManager identifies the actor (a user or a controller) writing the metadata
+// of a PackageRevision.
+type FieldManager struct {
+	// Name of the field manager.
+	Name string
+	// Force takes over ownership of keys owned by other field managers instead
+	// of rejecting the write.
+	Force bool
+}
+
+type fieldManagerKey struct{}
+
+// WithFieldManager returns a copy of ctx carrying the field manager for metadata writes.
+func WithFieldManager(ctx context.Context, manager FieldManager) context.Context {
+	return context.WithValue(ctx, fieldManagerKey{}, manager)
+}
+
+// FieldManagerFrom returns the field manager carried by ctx, using DefaultFieldManager
+// if there is none.
+func FieldManagerFrom(ctx context.Context) FieldManager {
+	manager, _ := ctx.Value(fieldManagerKey{}).(FieldManager)
+	if manager.Name == "" {
+		manager.Name = DefaultFieldManager
+	}
+	return manager
+}
+
+// fieldOwners records the field manager owning each label and annotation key.
+type fieldOwners struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// readFieldOwners extracts the field owners from the annotations of the stored
+// object and removes the bookkeeping annotation.
+func readFieldOwners(annotations map[string]string) (fieldOwners, error) {
+	owners := fieldOwners{}
+	if s, found := annotations[FieldManagersAnnotation]; found {
+		delete(annotations, FieldManagersAnnotation)
+		if err := json.Unmarshal([]byte(s), &owners); err != nil {
+			return fieldOwners{}, fmt.Errorf("cannot parse %s annotation: %w", FieldManagersAnnotation, err)
+		}
+	}
+	if owners.Labels == nil {
+		owners.Labels = map[string]string{}
+	}
+	if owners.Annotations == nil {
+		owners.Annotations = map[string]string{}
+	}
+	return owners, nil
+}
+
+// writeFieldOwners records the field owners in the annotations of the stored object.
+func writeFieldOwners(annotations map[string]string, owners fieldOwners) error {
+	if len(owners.Labels) == 0 && len(owners.Annotations) == 0 {
+		delete(annotations, FieldManagersAnnotation)
+		return nil
+	}
+	b, err := json.Marshal(owners)
+	if err != nil {
+		return err
+	}
+	annotations[FieldManagersAnnotation] = string(b)
+	return nil
+}
+
+// fieldConflict is a key changed by a field manager other than its owner.
+type fieldConflict struct {
+	field   string
+	key     string
+	manager string
+}
+
+// claimFields transfers ownership of the keys changed between current and desired
+// to the manager, recording a conflict for each changed key owned by a different
+// manager unless the write is forced.
+func claimFields(field string, owners map[string]string, current, desired map[string]string, manager FieldManager) []fieldConflict {
+	var conflicts []fieldConflict
+	claim := func(key string, present bool) {
+		if owner, found := owners[key]; found && owner != manager.Name && !manager.Force {
+			conflicts = append(conflicts, fieldConflict{field: field, key: key, manager: owner})
+			return
+		}
+		if present {
+			owners[key] = manager.Name
+		} else {
+			delete(owners, key)
+		}
+	}
+	for key, value := range desired {
+		if currentValue, found := current[key]; !found || currentValue != value {
+			claim(key, true)
+		}
+	}
+	for key := range current {
+		if _, found := desired[key]; !found {
+			claim(key, false)
+		}
+	}
+	return conflicts
+}
+
+// newFieldConflictError returns a Conflict error describing the keys the manager
+// cannot change without forcing the write.
+func newFieldConflictError(name string, manager FieldManager, conflicts []fieldConflict) error {
+	var messages []string
+	for _, c := range conflicts {
+		messages = append(messages, fmt.Sprintf("%s %q is managed by %q", c.field, c.key, c.manager))
+	}
+	sort.Strings(messages)
+	return apierrors.NewConflict(
+		schema.GroupResource{Group: "porch.kpt.dev", Resource: "packagerevisions"},
+		name,
+		fmt.Errorf("field manager %q conflicts with other managers: %s", manager.Name, strings.Join(messages, "; ")),
+	)
+}
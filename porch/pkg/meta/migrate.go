@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"fmt"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MigrateMetadata copies the metadata of the package revisions of repo from one backend
+// to another, for example before switching the backend of the server. The metadata,
+// including the owners of its labels and annotations, is copied as is, replacing the
+// metadata already in the destination; the source is left unchanged. It returns the
+// number of package revisions whose metadata was copied.
+func MigrateMetadata(ctx context.Context, coreClient client.Client, from, to Backend, repo *configapi.Repository) (int, error) {
+	ctx, span := tracer.Start(ctx, "MigrateMetadata", trace.WithAttributes())
+	defer span.End()
+
+	if from == to {
+		return 0, fmt.Errorf("cannot migrate metadata from backend %q to itself", from)
+	}
+	source, err := newPackageRevStorage(from, coreClient)
+	if err != nil {
+		return 0, err
+	}
+	destination, err := newPackageRevStorage(to, coreClient)
+	if err != nil {
+		return 0, err
+	}
+
+	pkgRevs, err := source.List(ctx, repo.Namespace, repo.Name)
+	if err != nil {
+		return 0, fmt.Errorf("cannot list the metadata of repository %s in backend %q: %w", repo.Name, from, err)
+	}
+	for i := range pkgRevs {
+		if err := copyPackageRev(ctx, destination, &pkgRevs[i]); err != nil {
+			return i, fmt.Errorf("cannot migrate the metadata of package revision %s to backend %q: %w", pkgRevs[i].Name, to, err)
+		}
+	}
+	return len(pkgRevs), nil
+}
+
+// copyPackageRev creates or replaces the PackageRev in the destination with pkgRev.
+func copyPackageRev(ctx context.Context, destination packageRevStorage, pkgRev *internalapi.PackageRev) error {
+	obj := pkgRev.DeepCopy()
+	obj.ResourceVersion = ""
+	obj.UID = ""
+	obj.CreationTimestamp = metav1.Time{}
+	obj.ManagedFields = nil
+
+	var existing internalapi.PackageRev
+	err := destination.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, &existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := destination.Create(ctx, obj); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		obj.ResourceVersion = existing.ResourceVersion
+		if err := destination.Update(ctx, obj); err != nil {
+			return err
+		}
+	}
+	// The status is written separately, as for the PackageRevs of the CRD backend.
+	pkgRev.Status.DeepCopyInto(&obj.Status)
+	return destination.UpdateStatus(ctx, obj)
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMigrateMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		from, to Backend
+	}{
+		{CRDBackend, ConfigMapBackend},
+		{ConfigMapBackend, CRDBackend},
+	} {
+		t.Run(string(tc.from)+"-to-"+string(tc.to), func(t *testing.T) {
+			ctx := context.Background()
+			c := newTestClient(t)
+			source, err := NewMetadataStore(tc.from, c)
+			if err != nil {
+				t.Fatalf("NewMetadataStore failed: %v", err)
+			}
+			destination, err := NewMetadataStore(tc.to, c)
+			if err != nil {
+				t.Fatalf("NewMetadataStore failed: %v", err)
+			}
+			repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+			name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+
+			alice := WithFieldManager(ctx, FieldManager{Name: "alice"})
+			created := metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+			if _, err := source.Create(alice, PackageRevisionMeta{
+				Name:           name.Name,
+				Namespace:      name.Namespace,
+				Labels:         map[string]string{"team": "a"},
+				LifecycleTimes: LifecycleTimes{DraftCreatedAt: created},
+			}, repo); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			artifacts := []api.Artifact{{Type: api.ArtifactTypeFluxOCI, URL: "oci://registry/repo/pkg:v1", Digest: "sha256:1234"}}
+			if _, err := source.Update(alice, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}, Artifacts: artifacts}); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+
+			// Migrating twice replaces the metadata migrated the first time.
+			for i := 0; i < 2; i++ {
+				n, err := MigrateMetadata(ctx, c, tc.from, tc.to, repo)
+				if err != nil {
+					t.Fatalf("MigrateMetadata failed: %v", err)
+				}
+				if n != 1 {
+					t.Errorf("migrated the metadata of %d package revisions; want 1", n)
+				}
+			}
+
+			want, err := source.Get(ctx, name)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			got, err := destination.Get(ctx, name)
+			if err != nil {
+				t.Fatalf("Get of migrated metadata failed: %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unexpected migrated metadata (-want, +got): %s", diff)
+			}
+
+			// The owners of the labels are migrated too.
+			bob := WithFieldManager(ctx, FieldManager{Name: "bob"})
+			if _, err := destination.Update(bob, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "b"}}); !apierrors.IsConflict(err) {
+				t.Errorf("Update of a label owned by another manager returned %v; want Conflict", err)
+			}
+		})
+	}
+}
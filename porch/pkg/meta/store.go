@@ -16,13 +16,17 @@ package meta
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
 
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -51,83 +55,237 @@ type PackageRevisionMeta struct {
 	Namespace   string
 	Labels      map[string]string
 	Annotations map[string]string
+
+	// Artifacts are the artifacts exported from the PackageRevision. They are kept in
+	// the status of the PackageRev; Update leaves them unchanged if Artifacts is nil.
+	Artifacts []api.Artifact
+
+	// LifecycleTimes are the times of the lifecycle transitions of the PackageRevision.
+	// They are kept in the status of the PackageRev; Create and Update only set the
+	// non-zero times.
+	LifecycleTimes LifecycleTimes
+
+	// Archived is true if the package of the PackageRevision is archived. It is kept
+	// in the spec of the PackageRev; Update leaves it unchanged if Archived is nil.
+	Archived *bool
+
+	// Documents are structured metadata documents attached to the PackageRevision, by
+	// key. They are kept in the spec of the PackageRev; Update leaves them unchanged if
+	// Documents is nil.
+	Documents map[string]json.RawMessage
+
+	// Freeze is the freeze of the package of the PackageRevision. It is kept in the spec
+	// of the PackageRev, and is nil when read if the package isn't frozen. Update leaves
+	// it unchanged if Freeze is nil, and clears it if Freeze is the zero PackageFreeze.
+	Freeze *PackageFreeze
+
+	// PackageMetadata is the metadata of the package of the PackageRevision which can be
+	// changed through the Package: its labels, annotations and description. It is kept
+	// in the spec of the PackageRev; Update leaves it unchanged if PackageMetadata is nil.
+	PackageMetadata *PackageMetadata
+
+	// DeploymentStatus is the deployment status last reported for the PackageRevision.
+	// It is kept in the status of the PackageRev, and is nil when read if none was
+	// reported. Update leaves it unchanged if DeploymentStatus is nil, and clears it if
+	// DeploymentStatus is the zero DeploymentStatus.
+	DeploymentStatus *api.DeploymentStatus
+
+	// TaskResults are the results of the tasks applied by the last create or update of
+	// the PackageRevision. They are kept in the status of the PackageRev; Update leaves
+	// them unchanged if TaskResults is nil.
+	TaskResults []api.TaskResult
+
+	// Deletions are the deleted package revisions of the package of the PackageRevision,
+	// recorded in the metadata of the package revisions of the package which remain.
+	// They are kept in the status of the PackageRev; Update leaves them unchanged if
+	// Deletions is nil.
+	Deletions []RevisionDeletion
+
+	// ConditionChanges are the changes of the conditions of the PackageRevision, in the
+	// order they were made. They are kept in the status of the PackageRev; Update leaves
+	// them unchanged if ConditionChanges is nil.
+	ConditionChanges []ConditionChange
+}
+
+// RevisionDeletion records the deletion of a package revision: which one, by whom and
+// when.
+type RevisionDeletion struct {
+	Name      string
+	Revision  string
+	DeletedBy string
+	DeletedAt metav1.Time
+}
+
+// ConditionChange records a change of a condition of a package revision: to which
+// status, by whom and when.
+type ConditionChange struct {
+	Type      string
+	Status    string
+	ChangedBy string
+	ChangedAt metav1.Time
+}
+
+// PackageFreeze is the freeze of a package: who froze it, when and why.
+type PackageFreeze struct {
+	Reason   string
+	FrozenBy string
+	FrozenAt metav1.Time
+}
+
+// PackageMetadata is the metadata of a package set through the Package, and when it was
+// last updated.
+type PackageMetadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Description string
+	UpdatedAt   metav1.Time
+}
+
+// IsZero returns true if the freeze is unset.
+func (f PackageFreeze) IsZero() bool {
+	return f.Reason == "" && f.FrozenBy == "" && f.FrozenAt.IsZero()
+}
+
+// IsArchived returns true if the package of the PackageRevision is archived.
+func (m PackageRevisionMeta) IsArchived() bool {
+	return m.Archived != nil && *m.Archived
+}
+
+// IsFrozen returns true if the package of the PackageRevision is frozen.
+func (m PackageRevisionMeta) IsFrozen() bool {
+	return m.Freeze != nil && !m.Freeze.IsZero()
+}
+
+// LifecycleTimes are the times when a PackageRevision was created as a draft, last
+// proposed and published, as recorded by Porch, and who last proposed it. Zero times
+// are unknown.
+type LifecycleTimes struct {
+	DraftCreatedAt metav1.Time
+	ProposedAt     metav1.Time
+	ProposedBy     string
+	PublishedAt    metav1.Time
+}
+
+// IsZero returns true if none of the times is known.
+func (t LifecycleTimes) IsZero() bool {
+	return t.DraftCreatedAt.IsZero() && t.ProposedAt.IsZero() && t.PublishedAt.IsZero()
 }
 
-var _ MetadataStore = &crdMetadataStore{}
+var _ MetadataStore = &packageRevMetadataStore{}
 
-func NewCrdMetadataStore(coreClient client.Client) *crdMetadataStore {
-	return &crdMetadataStore{
-		coreClient: coreClient,
+// NewCrdMetadataStore returns a metadata store which stores the metadata in PackageRev
+// custom resources.
+func NewCrdMetadataStore(coreClient client.Client) *packageRevMetadataStore {
+	return &packageRevMetadataStore{
+		storage: &crdStorage{coreClient: coreClient},
 	}
 }
 
-// crdMetadataStore is an implementation of the MetadataStore interface that
-// stores metadata in a CRD.
-type crdMetadataStore struct {
-	coreClient client.Client
+// packageRevMetadataStore is an implementation of the MetadataStore interface that
+// keeps the metadata of each PackageRevision in a PackageRev, stored by one of the
+// backends.
+type packageRevMetadataStore struct {
+	storage packageRevStorage
 }
 
-func (c *crdMetadataStore) Get(ctx context.Context, namespacedName types.NamespacedName) (PackageRevisionMeta, error) {
-	ctx, span := tracer.Start(ctx, "crdMetadataStore::Get", trace.WithAttributes())
+func (c *packageRevMetadataStore) Get(ctx context.Context, namespacedName types.NamespacedName) (PackageRevisionMeta, error) {
+	ctx, span := tracer.Start(ctx, "packageRevMetadataStore::Get", trace.WithAttributes())
 	defer span.End()
 
 	var internalPkgRev internalapi.PackageRev
-	err := c.coreClient.Get(ctx, namespacedName, &internalPkgRev)
+	err := c.storage.Get(ctx, namespacedName, &internalPkgRev)
 	if err != nil {
 		return PackageRevisionMeta{}, err
 	}
 
 	labels := internalPkgRev.Labels
 	delete(labels, PkgRevisionRepoLabel)
+	annotations := internalPkgRev.Annotations
+	delete(annotations, FieldManagersAnnotation)
 
 	return PackageRevisionMeta{
-		Name:        internalPkgRev.Name,
-		Namespace:   internalPkgRev.Namespace,
-		Labels:      labels,
-		Annotations: internalPkgRev.Annotations,
+		Name:             internalPkgRev.Name,
+		Namespace:        internalPkgRev.Namespace,
+		Labels:           labels,
+		Annotations:      annotations,
+		Artifacts:        toArtifacts(internalPkgRev.Status.Artifacts),
+		LifecycleTimes:   toLifecycleTimes(internalPkgRev.Status),
+		Archived:         toArchived(internalPkgRev.Spec),
+		Documents:        toDocuments(internalPkgRev.Spec.Documents),
+		Freeze:           toFreeze(internalPkgRev.Spec.Freeze),
+		PackageMetadata:  toPackageMetadata(internalPkgRev.Spec.PackageMetadata),
+		DeploymentStatus: toDeploymentStatus(internalPkgRev.Status.Deployment),
+		TaskResults:      toTaskResults(internalPkgRev.Status.TaskResults),
+		Deletions:        toRevisionDeletions(internalPkgRev.Status.Deletions),
+		ConditionChanges: toConditionChanges(internalPkgRev.Status.ConditionChanges),
 	}, nil
 }
 
-func (c *crdMetadataStore) List(ctx context.Context, repo *configapi.Repository) ([]PackageRevisionMeta, error) {
-	ctx, span := tracer.Start(ctx, "crdMetadataStore::List", trace.WithAttributes())
+func (c *packageRevMetadataStore) List(ctx context.Context, repo *configapi.Repository) ([]PackageRevisionMeta, error) {
+	ctx, span := tracer.Start(ctx, "packageRevMetadataStore::List", trace.WithAttributes())
 	defer span.End()
 
-	var internalPkgRevList internalapi.PackageRevList
-	err := c.coreClient.List(ctx, &internalPkgRevList, client.InNamespace(repo.Namespace), client.MatchingLabels(map[string]string{PkgRevisionRepoLabel: repo.Name}))
+	internalPkgRevs, err := c.storage.List(ctx, repo.Namespace, repo.Name)
 	if err != nil {
 		return nil, err
 	}
 	var pkgRevMetas []PackageRevisionMeta
 	var names []string
-	for _, ipr := range internalPkgRevList.Items {
+	for _, ipr := range internalPkgRevs {
 		labels := ipr.Labels
 		delete(labels, PkgRevisionRepoLabel)
+		annotations := ipr.Annotations
+		delete(annotations, FieldManagersAnnotation)
 		pkgRevMetas = append(pkgRevMetas, PackageRevisionMeta{
-			Name:        ipr.Name,
-			Namespace:   ipr.Namespace,
-			Labels:      labels,
-			Annotations: ipr.Annotations,
+			Name:             ipr.Name,
+			Namespace:        ipr.Namespace,
+			Labels:           labels,
+			Annotations:      annotations,
+			Artifacts:        toArtifacts(ipr.Status.Artifacts),
+			LifecycleTimes:   toLifecycleTimes(ipr.Status),
+			Archived:         toArchived(ipr.Spec),
+			Documents:        toDocuments(ipr.Spec.Documents),
+			Freeze:           toFreeze(ipr.Spec.Freeze),
+			PackageMetadata:  toPackageMetadata(ipr.Spec.PackageMetadata),
+			DeploymentStatus: toDeploymentStatus(ipr.Status.Deployment),
+			TaskResults:      toTaskResults(ipr.Status.TaskResults),
+			Deletions:        toRevisionDeletions(ipr.Status.Deletions),
+			ConditionChanges: toConditionChanges(ipr.Status.ConditionChanges),
 		})
 		names = append(names, ipr.Name)
 	}
 	return pkgRevMetas, nil
 }
 
-func (c *crdMetadataStore) Create(ctx context.Context, pkgRevMeta PackageRevisionMeta, repo *configapi.Repository) (PackageRevisionMeta, error) {
-	ctx, span := tracer.Start(ctx, "crdMetadataStore::Create", trace.WithAttributes())
+func (c *packageRevMetadataStore) Create(ctx context.Context, pkgRevMeta PackageRevisionMeta, repo *configapi.Repository) (PackageRevisionMeta, error) {
+	ctx, span := tracer.Start(ctx, "packageRevMetadataStore::Create", trace.WithAttributes())
 	defer span.End()
 
-	labels := pkgRevMeta.Labels
-	if labels == nil {
-		labels = make(map[string]string)
+	manager := FieldManagerFrom(ctx)
+	owners := fieldOwners{
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+	}
+	labels := make(map[string]string)
+	for k, v := range pkgRevMeta.Labels {
+		labels[k] = v
+		owners.Labels[k] = manager.Name
 	}
 	labels[PkgRevisionRepoLabel] = repo.Name
+	annotations := make(map[string]string)
+	for k, v := range pkgRevMeta.Annotations {
+		annotations[k] = v
+		owners.Annotations[k] = manager.Name
+	}
+	if err := writeFieldOwners(annotations, owners); err != nil {
+		return PackageRevisionMeta{}, err
+	}
 	internalPkgRev := internalapi.PackageRev{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        pkgRevMeta.Name,
 			Namespace:   pkgRevMeta.Namespace,
 			Labels:      labels,
-			Annotations: pkgRevMeta.Annotations,
+			Annotations: annotations,
 			// We probably should make these owner refs point to the PackageRevision CRs instead.
 			// But we need to make sure that deletion of these are correctly picked up by the
 			// GC. Currently we delete PackageRevisions through polling of the git/oci repos, and
@@ -141,23 +299,58 @@ func (c *crdMetadataStore) Create(ctx context.Context, pkgRevMeta PackageRevisio
 				},
 			},
 		},
+		Spec: internalapi.PackageRevSpec{
+			Archived:        pkgRevMeta.IsArchived(),
+			Documents:       fromDocuments(pkgRevMeta.Documents),
+			Freeze:          fromFreeze(pkgRevMeta.Freeze),
+			PackageMetadata: fromPackageMetadata(pkgRevMeta.PackageMetadata),
+		},
 	}
-	if err := c.coreClient.Create(ctx, &internalPkgRev); err != nil {
+	if err := c.storage.Create(ctx, &internalPkgRev); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			return c.Update(ctx, pkgRevMeta)
 		}
 		return PackageRevisionMeta{}, err
 	}
+	// The lifecycle times and task results live in the status subresource, which the
+	// create above ignores.
+	statusChanged := mergeLifecycleTimes(&internalPkgRev.Status, pkgRevMeta.LifecycleTimes)
+	if len(pkgRevMeta.TaskResults) != 0 {
+		internalPkgRev.Status.TaskResults = fromTaskResults(pkgRevMeta.TaskResults)
+		statusChanged = true
+	}
+	if len(pkgRevMeta.Deletions) != 0 {
+		internalPkgRev.Status.Deletions = fromRevisionDeletions(pkgRevMeta.Deletions)
+		statusChanged = true
+	}
+	if len(pkgRevMeta.ConditionChanges) != 0 {
+		internalPkgRev.Status.ConditionChanges = fromConditionChanges(pkgRevMeta.ConditionChanges)
+		statusChanged = true
+	}
+	if statusChanged {
+		if err := c.storage.UpdateStatus(ctx, &internalPkgRev); err != nil {
+			return PackageRevisionMeta{}, err
+		}
+	}
 	return PackageRevisionMeta{
-		Name:        internalPkgRev.Name,
-		Namespace:   internalPkgRev.Namespace,
-		Labels:      internalPkgRev.Labels,
-		Annotations: internalPkgRev.Annotations,
+		Name:             internalPkgRev.Name,
+		Namespace:        internalPkgRev.Namespace,
+		Labels:           pkgRevMeta.Labels,
+		Annotations:      pkgRevMeta.Annotations,
+		LifecycleTimes:   toLifecycleTimes(internalPkgRev.Status),
+		Archived:         toArchived(internalPkgRev.Spec),
+		Documents:        toDocuments(internalPkgRev.Spec.Documents),
+		Freeze:           toFreeze(internalPkgRev.Spec.Freeze),
+		PackageMetadata:  toPackageMetadata(internalPkgRev.Spec.PackageMetadata),
+		DeploymentStatus: toDeploymentStatus(internalPkgRev.Status.Deployment),
+		TaskResults:      toTaskResults(internalPkgRev.Status.TaskResults),
+		Deletions:        toRevisionDeletions(internalPkgRev.Status.Deletions),
+		ConditionChanges: toConditionChanges(internalPkgRev.Status.ConditionChanges),
 	}, nil
 }
 
-func (c *crdMetadataStore) Update(ctx context.Context, pkgRevMeta PackageRevisionMeta) (PackageRevisionMeta, error) {
-	ctx, span := tracer.Start(ctx, "crdMetadataStore::Update", trace.WithAttributes())
+func (c *packageRevMetadataStore) Update(ctx context.Context, pkgRevMeta PackageRevisionMeta) (PackageRevisionMeta, error) {
+	ctx, span := tracer.Start(ctx, "packageRevMetadataStore::Update", trace.WithAttributes())
 	defer span.End()
 
 	var internalPkgRev internalapi.PackageRev
@@ -165,46 +358,123 @@ func (c *crdMetadataStore) Update(ctx context.Context, pkgRevMeta PackageRevisio
 		Name:      pkgRevMeta.Name,
 		Namespace: pkgRevMeta.Namespace,
 	}
-	err := c.coreClient.Get(ctx, namespacedName, &internalPkgRev)
+	err := c.storage.Get(ctx, namespacedName, &internalPkgRev)
+	if err != nil {
+		return PackageRevisionMeta{}, err
+	}
+
+	currentLabels := make(map[string]string)
+	for k, v := range internalPkgRev.Labels {
+		currentLabels[k] = v
+	}
+	delete(currentLabels, PkgRevisionRepoLabel)
+	currentAnnotations := make(map[string]string)
+	for k, v := range internalPkgRev.Annotations {
+		currentAnnotations[k] = v
+	}
+	owners, err := readFieldOwners(currentAnnotations)
 	if err != nil {
 		return PackageRevisionMeta{}, err
 	}
 
-	var labels map[string]string
-	if pkgRevMeta.Labels != nil {
-		labels = pkgRevMeta.Labels
-	} else {
-		labels = make(map[string]string)
+	// Only the keys changed by this write are checked against their owners, so
+	// managers updating disjoint keys don't conflict with each other.
+	manager := FieldManagerFrom(ctx)
+	conflicts := claimFields("label", owners.Labels, currentLabels, pkgRevMeta.Labels, manager)
+	conflicts = append(conflicts, claimFields("annotation", owners.Annotations, currentAnnotations, pkgRevMeta.Annotations, manager)...)
+	if len(conflicts) > 0 {
+		return PackageRevisionMeta{}, newFieldConflictError(pkgRevMeta.Name, manager, conflicts)
+	}
+
+	labels := make(map[string]string)
+	for k, v := range pkgRevMeta.Labels {
+		labels[k] = v
 	}
 	labels[PkgRevisionRepoLabel] = internalPkgRev.Labels[PkgRevisionRepoLabel]
 	internalPkgRev.Labels = labels
 
-	var annotations map[string]string
-	if pkgRevMeta.Annotations != nil {
-		annotations = pkgRevMeta.Annotations
-	} else {
-		annotations = make(map[string]string)
+	annotations := make(map[string]string)
+	for k, v := range pkgRevMeta.Annotations {
+		annotations[k] = v
+	}
+	if err := writeFieldOwners(annotations, owners); err != nil {
+		return PackageRevisionMeta{}, err
 	}
 	internalPkgRev.Annotations = annotations
 
-	if err := c.coreClient.Update(ctx, &internalPkgRev); err != nil {
+	if pkgRevMeta.Archived != nil {
+		internalPkgRev.Spec.Archived = *pkgRevMeta.Archived
+	}
+	if pkgRevMeta.Documents != nil {
+		internalPkgRev.Spec.Documents = fromDocuments(pkgRevMeta.Documents)
+	}
+	if pkgRevMeta.Freeze != nil {
+		internalPkgRev.Spec.Freeze = fromFreeze(pkgRevMeta.Freeze)
+	}
+	if pkgRevMeta.PackageMetadata != nil {
+		internalPkgRev.Spec.PackageMetadata = fromPackageMetadata(pkgRevMeta.PackageMetadata)
+	}
+
+	status := *internalPkgRev.Status.DeepCopy()
+	if err := c.storage.Update(ctx, &internalPkgRev); err != nil {
 		return PackageRevisionMeta{}, err
 	}
-	delete(labels, PkgRevisionRepoLabel)
+	// The artifacts, lifecycle times, deployment status, task results, deletions and
+	// condition changes live in the status subresource, which the update above ignores.
+	statusChanged := mergeLifecycleTimes(&status, pkgRevMeta.LifecycleTimes)
+	if pkgRevMeta.Artifacts != nil {
+		status.Artifacts = fromArtifacts(pkgRevMeta.Artifacts)
+		statusChanged = true
+	}
+	if pkgRevMeta.TaskResults != nil {
+		status.TaskResults = fromTaskResults(pkgRevMeta.TaskResults)
+		statusChanged = true
+	}
+	if pkgRevMeta.Deletions != nil {
+		status.Deletions = fromRevisionDeletions(pkgRevMeta.Deletions)
+		statusChanged = true
+	}
+	if pkgRevMeta.ConditionChanges != nil {
+		status.ConditionChanges = fromConditionChanges(pkgRevMeta.ConditionChanges)
+		statusChanged = true
+	}
+	if pkgRevMeta.DeploymentStatus != nil {
+		deployment := fromDeploymentStatus(pkgRevMeta.DeploymentStatus)
+		if !reflect.DeepEqual(deployment, status.Deployment) {
+			status.Deployment = deployment
+			statusChanged = true
+		}
+	}
+	if statusChanged {
+		internalPkgRev.Status = status
+		if err := c.storage.UpdateStatus(ctx, &internalPkgRev); err != nil {
+			return PackageRevisionMeta{}, err
+		}
+	}
 	return PackageRevisionMeta{
-		Name:        pkgRevMeta.Name,
-		Namespace:   pkgRevMeta.Namespace,
-		Labels:      labels,
-		Annotations: internalPkgRev.Annotations,
+		Name:             pkgRevMeta.Name,
+		Namespace:        pkgRevMeta.Namespace,
+		Labels:           pkgRevMeta.Labels,
+		Annotations:      pkgRevMeta.Annotations,
+		Artifacts:        toArtifacts(status.Artifacts),
+		LifecycleTimes:   toLifecycleTimes(status),
+		Archived:         toArchived(internalPkgRev.Spec),
+		Documents:        toDocuments(internalPkgRev.Spec.Documents),
+		Freeze:           toFreeze(internalPkgRev.Spec.Freeze),
+		PackageMetadata:  toPackageMetadata(internalPkgRev.Spec.PackageMetadata),
+		DeploymentStatus: toDeploymentStatus(status.Deployment),
+		TaskResults:      toTaskResults(status.TaskResults),
+		Deletions:        toRevisionDeletions(status.Deletions),
+		ConditionChanges: toConditionChanges(status.ConditionChanges),
 	}, nil
 }
 
-func (c *crdMetadataStore) Delete(ctx context.Context, namespacedName types.NamespacedName) (PackageRevisionMeta, error) {
-	ctx, span := tracer.Start(ctx, "crdMetadataStore::Delete", trace.WithAttributes())
+func (c *packageRevMetadataStore) Delete(ctx context.Context, namespacedName types.NamespacedName) (PackageRevisionMeta, error) {
+	ctx, span := tracer.Start(ctx, "packageRevMetadataStore::Delete", trace.WithAttributes())
 	defer span.End()
 
 	var internalPkgRev internalapi.PackageRev
-	err := c.coreClient.Get(ctx, namespacedName, &internalPkgRev)
+	err := c.storage.Get(ctx, namespacedName, &internalPkgRev)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return PackageRevisionMeta{}, nil
@@ -212,7 +482,7 @@ func (c *crdMetadataStore) Delete(ctx context.Context, namespacedName types.Name
 		return PackageRevisionMeta{}, err
 	}
 
-	if err := c.coreClient.Delete(ctx, &internalPkgRev); err != nil {
+	if err := c.storage.Delete(ctx, &internalPkgRev); err != nil {
 		if apierrors.IsNotFound(err) {
 			return PackageRevisionMeta{}, nil
 		}
@@ -220,10 +490,273 @@ func (c *crdMetadataStore) Delete(ctx context.Context, namespacedName types.Name
 	}
 	labels := internalPkgRev.Labels
 	delete(labels, PkgRevisionRepoLabel)
+	annotations := internalPkgRev.Annotations
+	delete(annotations, FieldManagersAnnotation)
 	return PackageRevisionMeta{
-		Name:        internalPkgRev.Name,
-		Namespace:   internalPkgRev.Namespace,
-		Labels:      labels,
-		Annotations: internalPkgRev.Annotations,
+		Name:             internalPkgRev.Name,
+		Namespace:        internalPkgRev.Namespace,
+		Labels:           labels,
+		Annotations:      annotations,
+		Artifacts:        toArtifacts(internalPkgRev.Status.Artifacts),
+		LifecycleTimes:   toLifecycleTimes(internalPkgRev.Status),
+		Archived:         toArchived(internalPkgRev.Spec),
+		Documents:        toDocuments(internalPkgRev.Spec.Documents),
+		Freeze:           toFreeze(internalPkgRev.Spec.Freeze),
+		PackageMetadata:  toPackageMetadata(internalPkgRev.Spec.PackageMetadata),
+		DeploymentStatus: toDeploymentStatus(internalPkgRev.Status.Deployment),
+		TaskResults:      toTaskResults(internalPkgRev.Status.TaskResults),
+		Deletions:        toRevisionDeletions(internalPkgRev.Status.Deletions),
+		ConditionChanges: toConditionChanges(internalPkgRev.Status.ConditionChanges),
 	}, nil
 }
+
+func toArtifacts(artifacts []internalapi.Artifact) []api.Artifact {
+	var result []api.Artifact
+	for _, a := range artifacts {
+		result = append(result, api.Artifact{
+			Type:   api.ArtifactType(a.Type),
+			URL:    a.URL,
+			Digest: a.Digest,
+		})
+	}
+	return result
+}
+
+func fromArtifacts(artifacts []api.Artifact) []internalapi.Artifact {
+	result := []internalapi.Artifact{}
+	for _, a := range artifacts {
+		result = append(result, internalapi.Artifact{
+			Type:   string(a.Type),
+			URL:    a.URL,
+			Digest: a.Digest,
+		})
+	}
+	return result
+}
+
+func toTaskResults(results []internalapi.TaskResult) []api.TaskResult {
+	var result []api.TaskResult
+	for _, r := range results {
+		taskResult := api.TaskResult{
+			Task:      r.Task,
+			Type:      api.TaskType(r.Type),
+			Duration:  r.Duration,
+			StartedAt: r.StartedAt,
+			Added:     r.Added,
+			Changed:   r.Changed,
+			Deleted:   r.Deleted,
+		}
+		for _, fr := range r.Results {
+			taskResult.Results = append(taskResult.Results, api.FunctionResult(fr))
+		}
+		result = append(result, taskResult)
+	}
+	return result
+}
+
+func fromTaskResults(results []api.TaskResult) []internalapi.TaskResult {
+	result := []internalapi.TaskResult{}
+	for _, r := range results {
+		taskResult := internalapi.TaskResult{
+			Task:      r.Task,
+			Type:      string(r.Type),
+			Duration:  r.Duration,
+			StartedAt: r.StartedAt,
+			Added:     r.Added,
+			Changed:   r.Changed,
+			Deleted:   r.Deleted,
+		}
+		for _, fr := range r.Results {
+			taskResult.Results = append(taskResult.Results, internalapi.FunctionResult(fr))
+		}
+		result = append(result, taskResult)
+	}
+	return result
+}
+
+func toRevisionDeletions(deletions []internalapi.RevisionDeletion) []RevisionDeletion {
+	var result []RevisionDeletion
+	for _, d := range deletions {
+		result = append(result, RevisionDeletion{
+			Name:      d.Name,
+			Revision:  d.Revision,
+			DeletedBy: d.DeletedBy,
+			DeletedAt: d.DeletedAt,
+		})
+	}
+	return result
+}
+
+func fromRevisionDeletions(deletions []RevisionDeletion) []internalapi.RevisionDeletion {
+	result := []internalapi.RevisionDeletion{}
+	for _, d := range deletions {
+		result = append(result, internalapi.RevisionDeletion{
+			Name:      d.Name,
+			Revision:  d.Revision,
+			DeletedBy: d.DeletedBy,
+			DeletedAt: d.DeletedAt,
+		})
+	}
+	return result
+}
+
+func toConditionChanges(changes []internalapi.ConditionChange) []ConditionChange {
+	var result []ConditionChange
+	for _, c := range changes {
+		result = append(result, ConditionChange{
+			Type:      c.Type,
+			Status:    c.Status,
+			ChangedBy: c.ChangedBy,
+			ChangedAt: c.ChangedAt,
+		})
+	}
+	return result
+}
+
+func fromConditionChanges(changes []ConditionChange) []internalapi.ConditionChange {
+	result := []internalapi.ConditionChange{}
+	for _, c := range changes {
+		result = append(result, internalapi.ConditionChange{
+			Type:      c.Type,
+			Status:    c.Status,
+			ChangedBy: c.ChangedBy,
+			ChangedAt: c.ChangedAt,
+		})
+	}
+	return result
+}
+
+func toArchived(spec internalapi.PackageRevSpec) *bool {
+	archived := spec.Archived
+	return &archived
+}
+
+func toFreeze(freeze *internalapi.PackageFreeze) *PackageFreeze {
+	if freeze == nil {
+		return nil
+	}
+	return &PackageFreeze{
+		Reason:   freeze.Reason,
+		FrozenBy: freeze.FrozenBy,
+		FrozenAt: freeze.FrozenAt,
+	}
+}
+
+func fromFreeze(freeze *PackageFreeze) *internalapi.PackageFreeze {
+	if freeze == nil || freeze.IsZero() {
+		return nil
+	}
+	return &internalapi.PackageFreeze{
+		Reason:   freeze.Reason,
+		FrozenBy: freeze.FrozenBy,
+		FrozenAt: freeze.FrozenAt,
+	}
+}
+
+func toPackageMetadata(metadata *internalapi.PackageMetadata) *PackageMetadata {
+	if metadata == nil {
+		return nil
+	}
+	return &PackageMetadata{
+		Labels:      metadata.Labels,
+		Annotations: metadata.Annotations,
+		Description: metadata.Description,
+		UpdatedAt:   metadata.UpdatedAt,
+	}
+}
+
+func fromPackageMetadata(metadata *PackageMetadata) *internalapi.PackageMetadata {
+	if metadata == nil {
+		return nil
+	}
+	return &internalapi.PackageMetadata{
+		Labels:      metadata.Labels,
+		Annotations: metadata.Annotations,
+		Description: metadata.Description,
+		UpdatedAt:   metadata.UpdatedAt,
+	}
+}
+
+func toDeploymentStatus(status *internalapi.DeploymentStatus) *api.DeploymentStatus {
+	if status == nil {
+		return nil
+	}
+	return &api.DeploymentStatus{
+		Cluster:        status.Cluster,
+		ObservedCommit: status.ObservedCommit,
+		SyncStatus:     api.SyncStatus(status.SyncStatus),
+		SyncedTime:     status.SyncedTime,
+		ReportedTime:   status.ReportedTime,
+	}
+}
+
+func fromDeploymentStatus(status *api.DeploymentStatus) *internalapi.DeploymentStatus {
+	if status == nil || *status == (api.DeploymentStatus{}) {
+		return nil
+	}
+	return &internalapi.DeploymentStatus{
+		Cluster:        status.Cluster,
+		ObservedCommit: status.ObservedCommit,
+		SyncStatus:     string(status.SyncStatus),
+		SyncedTime:     status.SyncedTime,
+		ReportedTime:   status.ReportedTime,
+	}
+}
+
+func toDocuments(documents map[string]runtime.RawExtension) map[string]json.RawMessage {
+	if documents == nil {
+		return nil
+	}
+	result := make(map[string]json.RawMessage, len(documents))
+	for key, document := range documents {
+		result[key] = json.RawMessage(document.Raw)
+	}
+	return result
+}
+
+func fromDocuments(documents map[string]json.RawMessage) map[string]runtime.RawExtension {
+	if len(documents) == 0 {
+		return nil
+	}
+	result := make(map[string]runtime.RawExtension, len(documents))
+	for key, document := range documents {
+		result[key] = runtime.RawExtension{Raw: document}
+	}
+	return result
+}
+
+func toLifecycleTimes(status internalapi.PackageRevStatus) LifecycleTimes {
+	var times LifecycleTimes
+	if status.DraftCreatedAt != nil {
+		times.DraftCreatedAt = *status.DraftCreatedAt
+	}
+	if status.ProposedAt != nil {
+		times.ProposedAt = *status.ProposedAt
+	}
+	times.ProposedBy = status.ProposedBy
+	if status.PublishedAt != nil {
+		times.PublishedAt = *status.PublishedAt
+	}
+	return times
+}
+
+// mergeLifecycleTimes sets the non-zero times in the status, and returns true if
+// this changed the status.
+func mergeLifecycleTimes(status *internalapi.PackageRevStatus, times LifecycleTimes) bool {
+	changed := false
+	set := func(stored **metav1.Time, t metav1.Time) {
+		if t.IsZero() || (*stored != nil && (*stored).Equal(&t)) {
+			return
+		}
+		*stored = &t
+		changed = true
+	}
+	set(&status.DraftCreatedAt, times.DraftCreatedAt)
+	set(&status.ProposedAt, times.ProposedAt)
+	if times.ProposedBy != "" && times.ProposedBy != status.ProposedBy {
+		status.ProposedBy = times.ProposedBy
+		changed = true
+	}
+	set(&status.PublishedAt, times.PublishedAt)
+	return changed
+}
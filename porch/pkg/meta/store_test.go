@@ -0,0 +1,521 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// forEachBackend runs the test against the metadata store of each backend, which must
+// have the same semantics.
+func forEachBackend(t *testing.T, test func(t *testing.T, backend Backend)) {
+	for _, backend := range Backends {
+		backend := backend
+		t.Run(string(backend), func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+func newTestClient(t *testing.T) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := internalapi.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme failed: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme failed: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func newTestStore(t *testing.T, backend Backend) MetadataStore {
+	t.Helper()
+	store, err := NewMetadataStore(backend, newTestClient(t))
+	if err != nil {
+		t.Fatalf("NewMetadataStore failed: %v", err)
+	}
+	return store
+}
+
+func TestFieldManagerConflicts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		newStore := func(t *testing.T, labels map[string]string) MetadataStore {
+			store := newTestStore(t, backend)
+			ctx := WithFieldManager(ctx, FieldManager{Name: "alice"})
+			if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: labels}, repo); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			return store
+		}
+		update := func(store MetadataStore, manager FieldManager, labels map[string]string) error {
+			_, err := store.Update(WithFieldManager(ctx, manager), PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: labels})
+			return err
+		}
+
+		testCases := map[string]struct {
+			manager      FieldManager
+			labels       map[string]string
+			wantConflict bool
+			wantLabels   map[string]string
+		}{
+			"disjoint keys": {
+				manager:    FieldManager{Name: "controller"},
+				labels:     map[string]string{"team": "a", "env": "prod"},
+				wantLabels: map[string]string{"team": "a", "env": "prod"},
+			},
+			"overlapping key": {
+				manager:      FieldManager{Name: "controller"},
+				labels:       map[string]string{"team": "b"},
+				wantConflict: true,
+				wantLabels:   map[string]string{"team": "a"},
+			},
+			"removing key owned by another manager": {
+				manager:      FieldManager{Name: "controller"},
+				labels:       map[string]string{},
+				wantConflict: true,
+				wantLabels:   map[string]string{"team": "a"},
+			},
+			"overlapping key with the same value": {
+				manager:    FieldManager{Name: "controller"},
+				labels:     map[string]string{"team": "a"},
+				wantLabels: map[string]string{"team": "a"},
+			},
+			"overlapping key forced": {
+				manager:    FieldManager{Name: "controller", Force: true},
+				labels:     map[string]string{"team": "b"},
+				wantLabels: map[string]string{"team": "b"},
+			},
+			"overlapping key by owner": {
+				manager:    FieldManager{Name: "alice"},
+				labels:     map[string]string{"team": "b"},
+				wantLabels: map[string]string{"team": "b"},
+			},
+		}
+
+		for tn, tc := range testCases {
+			t.Run(tn, func(t *testing.T) {
+				store := newStore(t, map[string]string{"team": "a"})
+
+				err := update(store, tc.manager, tc.labels)
+				if tc.wantConflict {
+					if !apierrors.IsConflict(err) {
+						t.Fatalf("expected conflict, got %v", err)
+					}
+				} else if err != nil {
+					t.Fatalf("Update failed: %v", err)
+				}
+
+				got, err := store.Get(ctx, name)
+				if err != nil {
+					t.Fatalf("Get failed: %v", err)
+				}
+				if diff := cmp.Diff(tc.wantLabels, got.Labels); diff != "" {
+					t.Errorf("unexpected labels (-want, +got): %s", diff)
+				}
+				if _, found := got.Annotations[FieldManagersAnnotation]; found {
+					t.Errorf("field managers annotation must not be returned")
+				}
+			})
+		}
+	})
+}
+
+func TestFieldManagerOwnershipTransfer(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		store := newTestStore(t, backend)
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+		pkgRevMeta := func(labels map[string]string) PackageRevisionMeta {
+			return PackageRevisionMeta{Name: "repo-1234", Namespace: "default", Labels: labels}
+		}
+
+		alice := WithFieldManager(ctx, FieldManager{Name: "alice"})
+		bob := WithFieldManager(ctx, FieldManager{Name: "bob"})
+
+		if _, err := store.Create(alice, pkgRevMeta(map[string]string{"team": "a"}), repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		// Bob adds a key of its own, then alice can't remove it from a stale read.
+		if _, err := store.Update(bob, pkgRevMeta(map[string]string{"team": "a", "tier": "1"})); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if _, err := store.Update(alice, pkgRevMeta(map[string]string{"team": "b"})); !apierrors.IsConflict(err) {
+			t.Fatalf("expected conflict for stale update, got %v", err)
+		}
+		if _, err := store.Update(alice, pkgRevMeta(map[string]string{"team": "b", "tier": "1"})); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		// Once bob removes its key, alice may add it back and becomes the owner.
+		if _, err := store.Update(bob, pkgRevMeta(map[string]string{"team": "b"})); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if _, err := store.Update(alice, pkgRevMeta(map[string]string{"team": "b", "tier": "2"})); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if _, err := store.Update(bob, pkgRevMeta(map[string]string{"team": "b", "tier": "3"})); !apierrors.IsConflict(err) {
+			t.Fatalf("expected conflict, got %v", err)
+		}
+	})
+}
+
+func TestArtifacts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		artifacts := []api.Artifact{{Type: api.ArtifactTypeFluxOCI, URL: "oci://registry/repo/pkg:v1", Digest: "sha256:1234"}}
+		updated, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Artifacts: artifacts})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if diff := cmp.Diff(artifacts, updated.Artifacts); diff != "" {
+			t.Errorf("unexpected artifacts (-want, +got): %s", diff)
+		}
+
+		// Updating only the labels leaves the artifacts alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(artifacts, got.Artifacts); diff != "" {
+			t.Errorf("unexpected artifacts (-want, +got): %s", diff)
+		}
+	})
+}
+
+func TestTaskResults(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		created := []api.TaskResult{{
+			Task:      0,
+			Type:      api.TaskTypeInit,
+			Duration:  metav1.Duration{Duration: 20 * time.Millisecond},
+			StartedAt: metav1.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC),
+			Added:     []string{"Kptfile", "README.md"},
+		}}
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, TaskResults: created}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		// An update without task results, such as a lifecycle change, keeps them.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(created, got.TaskResults); diff != "" {
+			t.Errorf("unexpected task results (-want, +got): %s", diff)
+		}
+
+		updated := []api.TaskResult{{
+			Task:     0,
+			Type:     api.TaskTypeEval,
+			Duration: metav1.Duration{Duration: time.Second},
+			Changed:  []string{"deployment.yaml"},
+			Results:  []api.FunctionResult{{Function: "example.com/check", Message: "ok", Severity: "info"}},
+		}}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, TaskResults: updated}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if got, err = store.Get(ctx, name); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(updated, got.TaskResults); diff != "" {
+			t.Errorf("unexpected task results (-want, +got): %s", diff)
+		}
+	})
+}
+
+func TestDeletions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		deletions := []RevisionDeletion{{Name: "repo-5678", Revision: "v1", DeletedBy: "alice@example.com", DeletedAt: metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)}}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Deletions: deletions}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		// Updating only the labels leaves the deletions alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(deletions, got.Deletions); diff != "" {
+			t.Errorf("unexpected deletions (-want, +got): %s", diff)
+		}
+	})
+}
+
+func TestConditionChanges(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		changes := []ConditionChange{{Type: "Ready", Status: "True", ChangedBy: "alice@example.com", ChangedAt: metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)}}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, ConditionChanges: changes}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		// Updating only the labels leaves the condition changes alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(changes, got.ConditionChanges); diff != "" {
+			t.Errorf("unexpected condition changes (-want, +got): %s", diff)
+		}
+	})
+}
+
+func TestPackageMetadata(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		metadata := &PackageMetadata{
+			Labels:      map[string]string{"tier": "frontend"},
+			Annotations: map[string]string{"owner": "alice@example.com"},
+			Description: "The app blueprint",
+			UpdatedAt:   metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC),
+		}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, PackageMetadata: metadata}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		// Updating only the labels of the package revision leaves the package metadata alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(metadata, got.PackageMetadata); diff != "" {
+			t.Errorf("unexpected package metadata (-want, +got): %s", diff)
+		}
+	})
+}
+
+func TestLifecycleTimes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+
+		created := metav1.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+		proposed := metav1.Date(2022, 9, 2, 10, 0, 0, 0, time.UTC)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, LifecycleTimes: LifecycleTimes{DraftCreatedAt: created}}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		// Zero times in the update leave the stored times alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, LifecycleTimes: LifecycleTimes{ProposedAt: proposed, ProposedBy: "alice@example.com"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		want := LifecycleTimes{DraftCreatedAt: created, ProposedAt: proposed, ProposedBy: "alice@example.com"}
+		if !got.LifecycleTimes.DraftCreatedAt.Equal(&want.DraftCreatedAt) || !got.LifecycleTimes.ProposedAt.Equal(&want.ProposedAt) ||
+			got.LifecycleTimes.ProposedBy != want.ProposedBy || !got.LifecycleTimes.PublishedAt.IsZero() {
+			t.Errorf("unexpected lifecycle times: got %v, want %v", got.LifecycleTimes, want)
+		}
+	})
+}
+
+func TestDocuments(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+
+		store := newTestStore(t, backend)
+		if _, err := store.Create(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace}, repo); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		documents := map[string]json.RawMessage{"test-results": json.RawMessage(`{"passed":12,"failed":0}`)}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Documents: documents}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		// Updating only the labels leaves the documents alone.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Labels: map[string]string{"team": "a"}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := store.Get(ctx, name)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff(documents, got.Documents); diff != "" {
+			t.Errorf("unexpected documents (-want, +got): %s", diff)
+		}
+
+		// An empty map removes all documents.
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: name.Name, Namespace: name.Namespace, Documents: map[string]json.RawMessage{}}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if got, err = store.Get(ctx, name); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if len(got.Documents) != 0 {
+			t.Errorf("documents weren't removed: %v", got.Documents)
+		}
+	})
+}
+
+func TestCreateListDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		store := newTestStore(t, backend)
+		repo := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"}}
+		other := &configapi.Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}}
+
+		for _, m := range []struct {
+			name string
+			repo *configapi.Repository
+		}{{"repo-1", repo}, {"repo-2", repo}, {"other-1", other}} {
+			if _, err := store.Create(ctx, PackageRevisionMeta{Name: m.name, Namespace: "default", Labels: map[string]string{"team": "a"}}, m.repo); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		metas, err := store.List(ctx, repo)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		var names []string
+		for _, m := range metas {
+			names = append(names, m.Name)
+			if diff := cmp.Diff(map[string]string{"team": "a"}, m.Labels); diff != "" {
+				t.Errorf("unexpected labels of %s (-want, +got): %s", m.Name, diff)
+			}
+		}
+		if diff := cmp.Diff([]string{"repo-1", "repo-2"}, names); diff != "" {
+			t.Errorf("unexpected package revisions of repository (-want, +got): %s", diff)
+		}
+
+		if _, err := store.Delete(ctx, types.NamespacedName{Namespace: "default", Name: "repo-1"}); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := store.Get(ctx, types.NamespacedName{Namespace: "default", Name: "repo-1"}); !apierrors.IsNotFound(err) {
+			t.Errorf("Get of deleted metadata returned %v; want NotFound", err)
+		}
+		// Deleting missing metadata isn't an error.
+		if _, err := store.Delete(ctx, types.NamespacedName{Namespace: "default", Name: "repo-1"}); err != nil {
+			t.Errorf("Delete of deleted metadata failed: %v", err)
+		}
+		if _, err := store.Update(ctx, PackageRevisionMeta{Name: "repo-1", Namespace: "default"}); !apierrors.IsNotFound(err) {
+			t.Errorf("Update of deleted metadata returned %v; want NotFound", err)
+		}
+	})
+}
+
+func TestStaleUpdateConflict(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		ctx := context.Background()
+		storage, err := newPackageRevStorage(backend, newTestClient(t))
+		if err != nil {
+			t.Fatalf("newPackageRevStorage failed: %v", err)
+		}
+		if err := storage.Create(ctx, &internalapi.PackageRev{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo-1234"}}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		name := types.NamespacedName{Namespace: "default", Name: "repo-1234"}
+		var first, second internalapi.PackageRev
+		if err := storage.Get(ctx, name, &first); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if err := storage.Get(ctx, name, &second); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		first.Spec.Archived = true
+		if err := storage.Update(ctx, &first); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		second.Labels = map[string]string{"team": "a"}
+		if err := storage.Update(ctx, &second); !apierrors.IsConflict(err) {
+			t.Errorf("stale Update returned %v; want Conflict", err)
+		}
+		second.Status.Artifacts = []internalapi.Artifact{{Type: "flux-oci", URL: "oci://registry/repo/pkg:v1"}}
+		if err := storage.UpdateStatus(ctx, &second); !apierrors.IsConflict(err) {
+			t.Errorf("stale UpdateStatus returned %v; want Conflict", err)
+		}
+	})
+}
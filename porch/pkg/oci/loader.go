@@ -18,9 +18,11 @@ import (
 	"archive/tar"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -149,19 +151,61 @@ func LoadResources(ctx context.Context, s *oci.Storage, imageName *oci.ImageDige
 	// We need the per-digest cache here because otherwise we have to make a network request to look up the manifest in remote.Image
 	// (this could be cached by the go-containerregistry library, for some reason it is not...)
 	// TODO: Is there then any real reason to _also_ have the image-layer cache?
-	f, err := oci.WithCacheFile(filepath.Join(s.GetCacheDir(), "resources", imageName.Digest), fetcher)
+	cacheFile := filepath.Join(s.GetCacheDir(), "resources", imageName.Digest)
+	return loadResourcesWithCache(ctx, cacheFile, fetcher)
+}
+
+// loadResourcesWithCache loads the resources from the cache file, or from the stream
+// returned by fetcher if the file isn't cached. The stream is read within the read limit
+// of the context, so that the download stops as soon as the limit is exceeded, and is
+// written to the cache file while it is read; the cache file is only kept once the
+// whole stream was read.
+func loadResourcesWithCache(ctx context.Context, cacheFile string, fetcher func() (io.ReadCloser, error)) (*repository.PackageResources, error) {
+	f, err := os.Open(cacheFile)
+	if err == nil {
+		defer f.Close()
+		// TODO: Check hash here?  Or otherwise handle error?
+		return loadResourcesFromTar(ctx, tar.NewReader(f))
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error opening cache file %q: %w", cacheFile, err)
+	}
+
+	r, err := fetcher()
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	defer r.Close()
 
-	tarReader := tar.NewReader(f)
+	dir := filepath.Dir(cacheFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %q: %w", dir, err)
+	}
+	tempFile, err := os.CreateTemp(dir, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tempfile in directory %q: %w", dir, err)
+	}
+	defer func() {
+		// Closing twice is harmless; the tempfile no longer exists once it was renamed.
+		tempFile.Close()
+		if err := os.Remove(tempFile.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			klog.Warningf("failed to remove tempfile: %v", err)
+		}
+	}()
 
-	// TODO: Check hash here?  Or otherwise handle error?
-	resources, err := loadResourcesFromTar(ctx, tarReader)
+	// The tar reader reads the stream up to the end of the archive, so the cached
+	// archive is complete once it was read.
+	resources, err := loadResourcesFromTar(ctx, tar.NewReader(io.TeeReader(r, tempFile)))
 	if err != nil {
 		return nil, err
 	}
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("error closing temp file: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), cacheFile); err != nil {
+		// The resources were loaded; they are only not cached.
+		klog.Warningf("failed to rename tempfile to cache file %q: %v", cacheFile, err)
+	}
 	return resources, nil
 }
 
@@ -169,6 +213,7 @@ func loadResourcesFromTar(ctx context.Context, tarReader *tar.Reader) (*reposito
 	resources := &repository.PackageResources{
 		Contents: map[string]string{},
 	}
+	counter := repository.NewReadCounter(ctx)
 
 	for {
 		hdr, err := tarReader.Next()
@@ -187,6 +232,9 @@ func loadResourcesFromTar(ctx context.Context, tarReader *tar.Reader) (*reposito
 			// We probably don't want to support this; feels high-risk, low-reward
 			return nil, fmt.Errorf("package cannot contain symlink (%q)", path)
 		case 0:
+			if err := counter.Add(hdr.Size); err != nil {
+				return nil, err
+			}
 			b, err := io.ReadAll(tarReader)
 			if err != nil {
 				return nil, fmt.Errorf("error reading %q from image: %w", path, err)
@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingReader counts the bytes read from its reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// testFile is a file of a test archive, filled with size bytes.
+type testFile struct {
+	name string
+	size int
+}
+
+// testTar returns a tar archive of the files.
+func testTar(t *testing.T, files []testFile) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, file := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(file.size), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("cannot write header of %q: %v", file.name, err)
+		}
+		if _, err := tw.Write([]byte(strings.Repeat("x", file.size))); err != nil {
+			t.Fatalf("cannot write %q: %v", file.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("cannot close tar: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadResourcesFromTarStopsAtReadLimit(t *testing.T) {
+	buf := bytes.NewBuffer(testTar(t, []testFile{
+		{name: "Kptfile", size: 100},
+		{name: "huge.yaml", size: 4 << 20},
+		{name: "after.yaml", size: 100},
+	}))
+
+	reader := &countingReader{r: buf}
+	ctx := repository.WithReadLimit(context.Background(), repository.ReadLimit{MaxBytes: 1024})
+	_, err := loadResourcesFromTar(ctx, tar.NewReader(reader))
+	var limitErr *repository.ReadLimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("loadResourcesFromTar returned %v; want ReadLimitExceededError", err)
+	}
+	if got, want := limitErr.Files, 2; got != want {
+		t.Errorf("loadResourcesFromTar stopped after %d files; want %d", got, want)
+	}
+	// The contents of the file exceeding the limit aren't read.
+	if reader.n >= 1<<20 {
+		t.Errorf("loadResourcesFromTar read %d bytes of the stream; want it to stop before the oversized file", reader.n)
+	}
+}
+
+func TestLoadResourcesWithCache(t *testing.T) {
+	data := testTar(t, []testFile{{name: "Kptfile", size: 100}, {name: "app.yaml", size: 2000}})
+	cacheFile := filepath.Join(t.TempDir(), "resources", "sha256:1234")
+	ctx := repository.WithReadLimit(context.Background(), repository.ReadLimit{MaxBytes: 1 << 20})
+
+	fetches := 0
+	fetcher := func() (io.ReadCloser, error) {
+		fetches++
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	first, err := loadResourcesWithCache(ctx, cacheFile, fetcher)
+	if err != nil {
+		t.Fatalf("loadResourcesWithCache failed: %v", err)
+	}
+	second, err := loadResourcesWithCache(ctx, cacheFile, fetcher)
+	if err != nil {
+		t.Fatalf("loadResourcesWithCache failed: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("resources were fetched %d times; want the second load served from the cache", fetches)
+	}
+	if diff := cmp.Diff(first.Contents, second.Contents); diff != "" {
+		t.Errorf("unexpected cached resources (-fetched, +cached): %s", diff)
+	}
+	if len(second.Contents["app.yaml"]) != 2000 {
+		t.Errorf("cached app.yaml has %d bytes; want 2000", len(second.Contents["app.yaml"]))
+	}
+}
+
+func TestLoadResourcesWithCacheOverReadLimit(t *testing.T) {
+	data := testTar(t, []testFile{{name: "Kptfile", size: 100}, {name: "huge.yaml", size: 4 << 20}})
+	dir := filepath.Join(t.TempDir(), "resources")
+	ctx := repository.WithReadLimit(context.Background(), repository.ReadLimit{MaxBytes: 1024})
+
+	_, err := loadResourcesWithCache(ctx, filepath.Join(dir, "sha256:1234"), func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	var limitErr *repository.ReadLimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("loadResourcesWithCache returned %v; want ReadLimitExceededError", err)
+	}
+	// Neither the partial archive nor its tempfile are kept.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cannot read cache directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("cache directory has %d entries after a read over the limit; want none", len(entries))
+	}
+}
@@ -298,6 +298,10 @@ func (r *ociRepository) CreatePackage(ctx context.Context, obj *v1alpha1.Package
 	return nil, fmt.Errorf("CreatePackage not supported for OCI packages")
 }
 
+func (r *ociRepository) UpdatePackage(ctx context.Context, old repository.Package, new *v1alpha1.Package) (repository.Package, error) {
+	return nil, fmt.Errorf("%w for OCI packages", repository.ErrPackageUpdateNotSupported)
+}
+
 func (r *ociRepository) DeletePackage(ctx context.Context, obj repository.Package) error {
 	return fmt.Errorf("DeletePackage not supported for OCI packages")
 }
@@ -17,10 +17,13 @@ package porch
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
@@ -28,18 +31,32 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func RunBackground(ctx context.Context, coreClient client.WithWatch, cache *cache.Cache) {
+// RunBackground starts the background refresh of the repositories. pushWebhookPath is
+// the path of the push webhook endpoint reported in the status of repositories which
+// enable push webhooks.
+func RunBackground(ctx context.Context, coreClient client.WithWatch, cache *cache.Cache, enforcer RetentionEnforcer, pushWebhookPath string) {
 	b := background{
-		coreClient: coreClient,
-		cache:      cache,
+		coreClient:      coreClient,
+		cache:           cache,
+		enforcer:        enforcer,
+		pushWebhookPath: pushWebhookPath,
 	}
 	go b.run(ctx)
 }
 
+// RetentionEnforcer deletes the package revisions of a repository its retention policy
+// doesn't keep.
+type RetentionEnforcer interface {
+	EnforceRetention(ctx context.Context, repositoryObj *configapi.Repository, namespaceRepositories []configapi.Repository) ([]string, error)
+}
+
 // background manages background tasks
 type background struct {
 	coreClient client.WithWatch
 	cache      *cache.Cache
+	enforcer   RetentionEnforcer
+
+	pushWebhookPath string
 }
 
 const (
@@ -134,6 +151,11 @@ func (b *background) updateCache(ctx context.Context, event watch.EventType, rep
 	case watch.Modified:
 		klog.Infof("Repository modified: %s:%s", repository.ObjectMeta.Namespace, repository.ObjectMeta.Name)
 		// TODO: implement
+		// Run requested prunes right away rather than on the next tick. Once the result is
+		// recorded in the status the prune is no longer pending, so this doesn't loop.
+		if prunePending(repository) {
+			return b.cacheRepository(ctx, repository)
+		}
 	case watch.Deleted:
 		klog.Infof("Repository deleted: %s:%s", repository.ObjectMeta.Namespace, repository.ObjectMeta.Name)
 		return b.cache.CloseRepository(repository)
@@ -163,7 +185,7 @@ func (b *background) runOnce(ctx context.Context) error {
 
 func (b *background) cacheRepository(ctx context.Context, repo *configapi.Repository) error {
 	var condition v1.Condition
-	if _, err := b.cache.OpenRepository(ctx, repo); err == nil {
+	if cached, err := b.cache.OpenRepository(ctx, repo); err == nil {
 		condition = v1.Condition{
 			Type:               configapi.RepositoryReady,
 			Status:             v1.ConditionTrue,
@@ -171,6 +193,13 @@ func (b *background) cacheRepository(ctx context.Context, repo *configapi.Reposi
 			LastTransitionTime: v1.Now(),
 			Reason:             configapi.ReasonReady,
 		}
+		if prunePending(repo) {
+			pruneRepository(ctx, repo, cached)
+		}
+		if repo.Spec.Retention != nil && b.enforcer != nil {
+			b.enforceRetention(ctx, repo)
+		}
+		setPackagePathCollisionCondition(repo, cached.PackagePathCollisions())
 	} else {
 		condition = v1.Condition{
 			Type:               configapi.RepositoryReady,
@@ -183,12 +212,145 @@ func (b *background) cacheRepository(ctx context.Context, repo *configapi.Reposi
 	}
 
 	meta.SetStatusCondition(&repo.Status.Conditions, condition)
+	repo.Status.Webhook = webhookStatus(repo, b.pushWebhookPath)
 	if err := b.coreClient.Status().Update(ctx, repo); err != nil {
 		return fmt.Errorf("error updating repository status: %w", err)
 	}
 	return nil
 }
 
+// setPackagePathCollisionCondition flags the colliding package paths of the repository
+// with the configapi.RepositoryPackagePathCollision condition, or removes the condition
+// if there are none.
+func setPackagePathCollisionCondition(repo *configapi.Repository, collisions []repository.PackagePathCollision) {
+	if len(collisions) == 0 {
+		meta.RemoveStatusCondition(&repo.Status.Conditions, configapi.RepositoryPackagePathCollision)
+		return
+	}
+	var pairs []string
+	for _, c := range collisions {
+		pairs = append(pairs, fmt.Sprintf("%q and %q", c.Path, c.Other))
+	}
+	meta.SetStatusCondition(&repo.Status.Conditions, v1.Condition{
+		Type:               configapi.RepositoryPackagePathCollision,
+		Status:             v1.ConditionTrue,
+		ObservedGeneration: repo.Generation,
+		LastTransitionTime: v1.Now(),
+		Reason:             configapi.ReasonPackagePathCollision,
+		Message:            "package paths differ only by case or unicode normalization: " + strings.Join(pairs, ", "),
+	})
+}
+
+// webhookStatus returns how to configure the push webhook of the repository, or nil if
+// the repository doesn't enable push webhooks.
+func webhookStatus(repo *configapi.Repository, path string) *configapi.RepositoryWebhookStatus {
+	if repo.Spec.Git == nil || repo.Spec.Git.WebhookSecretRef.Name == "" || path == "" {
+		return nil
+	}
+	return &configapi.RepositoryWebhookStatus{
+		Path:       path,
+		SecretName: repo.Spec.Git.WebhookSecretRef.Name,
+		SecretKey:  configapi.WebhookSecretKey,
+	}
+}
+
+// prunePending returns true if the repository has a prune request which hasn't run yet.
+func prunePending(repo *configapi.Repository) bool {
+	request := repo.Annotations[configapi.PruneRequestAnnotation]
+	return request != "" && (repo.Status.Prune == nil || repo.Status.Prune.Request != request)
+}
+
+// pruneRepository runs the requested prune and records the result in the repository status.
+// Deleting refs requires the repository to opt in; dry runs are always allowed.
+func pruneRepository(ctx context.Context, repo *configapi.Repository, pruner repository.RefPruner) {
+	status := &configapi.RepositoryPruneStatus{
+		Request: repo.Annotations[configapi.PruneRequestAnnotation],
+		DryRun:  repo.Annotations[configapi.PruneDryRunAnnotation] == "true",
+		Time:    v1.Now(),
+	}
+
+	if !status.DryRun && (repo.Spec.Git == nil || !repo.Spec.Git.PruneStaleRefs) {
+		status.Error = "pruning is not enabled for the repository (spec.git.pruneStaleRefs); only dry runs are allowed"
+	} else if result, err := pruner.PruneStaleRefs(ctx, status.DryRun); err != nil {
+		klog.Errorf("Failed to prune repository %s:%s: %v", repo.Namespace, repo.Name, err)
+		status.Error = err.Error()
+	} else {
+		status.Refs = result.Refs
+		status.Bytes = result.Bytes
+	}
+
+	repo.Status.Prune = status
+}
+
+// enforceRetention enforces the retention policy of the repository, records the result in
+// the repository status, and emits an event for each deleted package revision.
+func (b *background) enforceRetention(ctx context.Context, repo *configapi.Repository) {
+	status := &configapi.RepositoryRetentionStatus{
+		DryRun: repo.Spec.Retention.DryRun,
+		Time:   v1.Now(),
+	}
+
+	var repositories configapi.RepositoryList
+	if err := b.coreClient.List(ctx, &repositories, client.InNamespace(repo.Namespace)); err != nil {
+		status.Error = fmt.Sprintf("error listing repository objects: %v", err)
+	} else {
+		revisions, err := b.enforcer.EnforceRetention(ctx, repo, repositories.Items)
+		if err != nil {
+			klog.Errorf("Failed to enforce retention policy of repository %s:%s: %v", repo.Namespace, repo.Name, err)
+			status.Error = err.Error()
+		}
+		status.Revisions = revisions
+	}
+
+	if !status.DryRun {
+		for _, name := range status.Revisions {
+			b.emitEvent(ctx, repo, corev1.EventTypeNormal, ReasonRetentionDeleted,
+				fmt.Sprintf("Deleted package revision %q per retention policy", name))
+		}
+	}
+	if status.Error != "" {
+		b.emitEvent(ctx, repo, corev1.EventTypeWarning, ReasonRetentionFailed, status.Error)
+	}
+	repo.Status.Retention = status
+}
+
+const (
+	// ReasonRetentionDeleted is the reason of the events emitted when a package revision
+	// is deleted per the retention policy of its repository.
+	ReasonRetentionDeleted = "RetentionDeleted"
+	// ReasonRetentionFailed is the reason of the events emitted when the retention policy
+	// of a repository couldn't be enforced.
+	ReasonRetentionFailed = "RetentionFailed"
+)
+
+func (b *background) emitEvent(ctx context.Context, repo *configapi.Repository, eventType, reason, message string) {
+	now := v1.Now()
+	event := &corev1.Event{
+		ObjectMeta: v1.ObjectMeta{
+			GenerateName: repo.Name + ".",
+			Namespace:    repo.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      configapi.GroupVersion.String(),
+			Kind:            configapi.RepositoryGVK.Kind,
+			Name:            repo.Name,
+			Namespace:       repo.Namespace,
+			UID:             repo.UID,
+			ResourceVersion: repo.ResourceVersion,
+		},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: "porch-server"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := b.coreClient.Create(ctx, event); err != nil {
+		klog.Warningf("Failed to emit %s event for repository %s:%s: %v", reason, repo.Namespace, repo.Name, err)
+	}
+}
+
 type backoffTimer struct {
 	min, max, curr time.Duration
 	timer          *time.Timer
@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"testing"
+
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakePruner struct {
+	calls []bool
+}
+
+func (p *fakePruner) PruneStaleRefs(ctx context.Context, dryRun bool) (*repository.PruneResult, error) {
+	p.calls = append(p.calls, dryRun)
+	return &repository.PruneResult{Refs: []string{"refs/heads/drafts/gone/v1"}, Bytes: 42}, nil
+}
+
+func TestPruneRepository(t *testing.T) {
+	testCases := map[string]struct {
+		dryRun    string
+		optIn     bool
+		wantCalls int
+		wantError bool
+	}{
+		"dry run without opt-in": {dryRun: "true", wantCalls: 1},
+		"prune without opt-in":   {wantError: true},
+		"prune with opt-in":      {optIn: true, wantCalls: 1},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo := &configapi.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						configapi.PruneRequestAnnotation: "1",
+						configapi.PruneDryRunAnnotation:  tc.dryRun,
+					},
+				},
+				Spec: configapi.RepositorySpec{
+					Git: &configapi.GitRepository{PruneStaleRefs: tc.optIn},
+				},
+			}
+			if !prunePending(repo) {
+				t.Fatalf("expected prune to be pending")
+			}
+
+			pruner := &fakePruner{}
+			pruneRepository(context.Background(), repo, pruner)
+
+			if got := len(pruner.calls); got != tc.wantCalls {
+				t.Errorf("expected %d prune calls, got %d", tc.wantCalls, got)
+			}
+			status := repo.Status.Prune
+			if status == nil || status.Request != "1" {
+				t.Fatalf("prune status wasn't recorded: %v", status)
+			}
+			if got := status.Error != ""; got != tc.wantError {
+				t.Errorf("unexpected prune error %q", status.Error)
+			}
+			if !tc.wantError && status.Bytes != 42 {
+				t.Errorf("expected 42 bytes pruned, got %d", status.Bytes)
+			}
+			if prunePending(repo) {
+				t.Errorf("expected prune to be no longer pending")
+			}
+		})
+	}
+}
+
+func TestPackagePathCollisionCondition(t *testing.T) {
+	repo := &configapi.Repository{}
+	setPackagePathCollisionCondition(repo, []repository.PackagePathCollision{{Path: "Foo", Other: "foo"}})
+	condition := meta.FindStatusCondition(repo.Status.Conditions, configapi.RepositoryPackagePathCollision)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("colliding package paths weren't flagged: %v", repo.Status.Conditions)
+	}
+	if want := `package paths differ only by case or unicode normalization: "Foo" and "foo"`; condition.Message != want {
+		t.Errorf("got message %q; want %q", condition.Message, want)
+	}
+
+	setPackagePathCollisionCondition(repo, nil)
+	if len(repo.Status.Conditions) != 0 {
+		t.Errorf("condition wasn't removed: %v", repo.Status.Conditions)
+	}
+}
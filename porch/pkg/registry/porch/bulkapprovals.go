@@ -0,0 +1,145 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// bulkApprovals approves several package revisions at once. Like a package move, an
+// approval is created to run it and is returned with its outcome, but isn't stored.
+type bulkApprovals struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &bulkApprovals{}
+var _ rest.Scoper = &bulkApprovals{}
+var _ rest.Creater = &bulkApprovals{}
+
+// New returns an empty object that can be used with Create after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (a *bulkApprovals) New() runtime.Object {
+	return &api.BulkApproval{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (a *bulkApprovals) NamespaceScoped() bool {
+	return true
+}
+
+// Create approves the package revisions and reports the outcome for each of them.
+func (a *bulkApprovals) Create(ctx context.Context, runtimeObject runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "bulkApprovals::Create", trace.WithAttributes())
+	defer span.End()
+
+	ns, namespaced := genericapirequest.NamespaceFrom(ctx)
+	if !namespaced {
+		return nil, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	obj, ok := runtimeObject.(*api.BulkApproval)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected BulkApproval object, got %T", runtimeObject))
+	}
+
+	fieldErrors := a.common.createStrategy.Validate(ctx, runtimeObject)
+	if len(fieldErrors) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("BulkApproval").GroupKind(), obj.Name, fieldErrors)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, runtimeObject); err != nil {
+			return nil, err
+		}
+	}
+
+	// Package revisions which can't be found are rejected; the others are approved by
+	// the engine.
+	results := make([]api.BulkApprovalResult, len(obj.Spec.PackageRevisions))
+	var requests []engine.ApprovalRequest
+	var indexes []int
+	for i, name := range obj.Spec.PackageRevisions {
+		req, err := a.approvalRequest(ctx, name)
+		if err != nil {
+			results[i] = api.BulkApprovalResult{
+				Name:    name,
+				Outcome: api.BulkApprovalOutcomeRejected,
+				Reason:  apierrors.ReasonForError(err),
+				Message: err.Error(),
+			}
+			continue
+		}
+		requests = append(requests, req)
+		indexes = append(indexes, i)
+	}
+	for i, result := range a.common.cad.ApprovePackageRevisions(ctx, requests) {
+		results[indexes[i]] = result
+	}
+
+	result := obj.DeepCopy()
+	result.Namespace = ns
+	result.Status.Results = results
+	return result, nil
+}
+
+func (a *bulkApprovals) approvalRequest(ctx context.Context, name string) (engine.ApprovalRequest, error) {
+	repositoryObj, err := a.common.getRepositoryObjFromName(ctx, name)
+	if err != nil {
+		return engine.ApprovalRequest{}, err
+	}
+	rev, err := a.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return engine.ApprovalRequest{}, err
+	}
+	return engine.ApprovalRequest{Repository: repositoryObj, PackageRevision: rev}, nil
+}
+
+type bulkApprovalStrategy struct{}
+
+var _ SimpleRESTCreateStrategy = bulkApprovalStrategy{}
+
+// Validate returns an ErrorList with validation errors or nil.  Validate
+// is invoked after default fields in the object have been filled in
+// before the object is persisted.  This method should not mutate the
+// object.
+func (s bulkApprovalStrategy) Validate(ctx context.Context, runtimeObj runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	obj := runtimeObj.(*api.BulkApproval)
+	path := field.NewPath("spec", "packageRevisions")
+	if len(obj.Spec.PackageRevisions) == 0 {
+		allErrs = append(allErrs, field.Required(path, "the package revisions to approve are required"))
+	}
+	seen := map[string]bool{}
+	for i, name := range obj.Spec.PackageRevisions {
+		if seen[name] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), name))
+		}
+		seen[name] = true
+	}
+	if len(obj.Status.Results) != 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("status"), "the outcome is reported by the approval"))
+	}
+	return allErrs
+}
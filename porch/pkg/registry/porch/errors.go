@@ -16,9 +16,12 @@ package porch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
@@ -52,3 +55,26 @@ func newResourceNotAcceptableError(ctx context.Context, resource schema.GroupRes
 		resource: resource,
 	}
 }
+
+// engineError returns the error of an engine operation as it is returned to the
+// client. Errors of invalid requests keep their API status, unwrapped as the apiserver
+// only recognizes the status of the returned error itself, so that clients get the
+// status code and the offending field, as do operations the repository doesn't support;
+// other errors are internal errors.
+func engineError(err error) error {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		switch status.Status().Reason {
+		case metav1.StatusReasonBadRequest, metav1.StatusReasonInvalid, metav1.StatusReasonConflict, metav1.StatusReasonForbidden,
+			metav1.StatusReasonMethodNotAllowed:
+			if statusErr, ok := status.(error); ok {
+				return statusErr
+			}
+		}
+	}
+	var taskErr *engine.TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr
+	}
+	return apierrors.NewInternalError(err)
+}
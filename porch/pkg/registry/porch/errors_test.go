@@ -0,0 +1,58 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+)
+
+func TestEngineError(t *testing.T) {
+	invalid := apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevision").GroupKind(), "blueprints-app-v1", field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "lifecycle"), "package revisions cannot be created Published"),
+	})
+	conflict := apierrors.NewConflict(api.PackageRevisionResourcesGVR.GroupResource(), "blueprints-app-v1", fmt.Errorf("package must be Draft"))
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		wantCode int32
+	}{
+		{"invalid", invalid, http.StatusUnprocessableEntity},
+		{"wrapped invalid", fmt.Errorf("appended task 1: %w", invalid), http.StatusUnprocessableEntity},
+		{"conflict", conflict, http.StatusConflict},
+		{"bad request", apierrors.NewBadRequest("the reason of a package freeze is required"), http.StatusBadRequest},
+		{"unsupported", fmt.Errorf("cannot update package: %w", &engine.PackageUpdateNotSupportedError{Repository: "blueprints", Package: "app"}), http.StatusMethodNotAllowed},
+		{"task error", &engine.TaskError{Class: engine.UserError, Err: fmt.Errorf("function failed")}, http.StatusBadRequest},
+		{"not found", fmt.Errorf("cannot get credentials: %w", apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "auth")), http.StatusInternalServerError},
+		{"plain", fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The status the apiserver returns to the client.
+			status := responsewriters.ErrorToAPIStatus(engineError(tc.err))
+			if status.Code != tc.wantCode {
+				t.Errorf("got status code %d; want %d", status.Code, tc.wantCode)
+			}
+		})
+	}
+}
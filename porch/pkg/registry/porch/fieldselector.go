@@ -16,6 +16,7 @@ package porch
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,7 +31,7 @@ func convertPackageFieldSelector(label, value string) (internalLabel, internalVa
 		return label, value, nil
 	case "metadata.namespace":
 		return label, value, nil
-	case "spec.packageName", "spec.repository":
+	case "spec.packageName", "spec.repository", "spec.archived":
 		return label, value, nil
 	default:
 		return "", "", fmt.Errorf("%q is not a known field selector", label)
@@ -44,7 +45,7 @@ func convertPackageRevisionFieldSelector(label, value string) (internalLabel, in
 		return label, value, nil
 	case "metadata.namespace":
 		return label, value, nil
-	case "spec.revision", "spec.packageName", "spec.repository":
+	case "spec.revision", "spec.packageName", "spec.repository", "status.archived", "status.sharedFrom":
 		return label, value, nil
 	default:
 		return "", "", fmt.Errorf("%q is not a known field selector", label)
@@ -65,6 +66,14 @@ type packageRevisionFilter struct {
 
 	// Repository restricts to repositories with the given name.
 	Repository string
+
+	// Archived selects the package revisions of archived packages instead of the others.
+	Archived bool
+
+	// SharedFrom, if set, also lists the published package revisions of the repositories of
+	// the namespace SharedFrom, or of all namespaces if it is "*", which are shared with the
+	// namespace of the request.
+	SharedFrom string
 }
 
 // packageFilter filters packages, extending repository.ListPackageFilter
@@ -76,6 +85,9 @@ type packageFilter struct {
 
 	// Repository restricts to repositories with the given name.
 	Repository string
+
+	// Archived selects archived packages instead of the others.
+	Archived bool
 }
 
 // parsePackageFieldSelector parses client-provided fields.Selector into a packageFilter
@@ -109,6 +121,12 @@ func parsePackageFieldSelector(fieldSelector fields.Selector) (packageFilter, er
 			filter.Package = requirement.Value
 		case "spec.repository":
 			filter.Repository = requirement.Value
+		case "spec.archived":
+			archived, err := parseArchivedSelector(requirement.Field, requirement.Value)
+			if err != nil {
+				return filter, err
+			}
+			filter.Archived = archived
 
 		default:
 			return filter, apierrors.NewBadRequest(fmt.Sprintf("unknown fieldSelector field %q", requirement.Field))
@@ -151,6 +169,14 @@ func parsePackageRevisionFieldSelector(fieldSelector fields.Selector) (packageRe
 			filter.Package = requirement.Value
 		case "spec.repository":
 			filter.Repository = requirement.Value
+		case "status.archived":
+			archived, err := parseArchivedSelector(requirement.Field, requirement.Value)
+			if err != nil {
+				return filter, err
+			}
+			filter.Archived = archived
+		case "status.sharedFrom":
+			filter.SharedFrom = requirement.Value
 
 		default:
 			return filter, apierrors.NewBadRequest(fmt.Sprintf("unknown fieldSelector field %q", requirement.Field))
@@ -160,9 +186,26 @@ func parsePackageRevisionFieldSelector(fieldSelector fields.Selector) (packageRe
 	return filter, nil
 }
 
+// parseArchivedSelector parses the value of a field selector on whether packages are archived.
+func parseArchivedSelector(field, value string) (bool, error) {
+	archived, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apierrors.NewBadRequest(fmt.Sprintf("unsupported fieldSelector value %q for field %q; must be true or false", value, field))
+	}
+	return archived, nil
+}
+
 // parsePackageRevisionResourcesFieldSelector parses client-provided fields.Selector into a packageRevisionFilter
 func parsePackageRevisionResourcesFieldSelector(fieldSelector fields.Selector) (packageRevisionFilter, error) {
 	// TOOD: This is a little weird, because we don't have the same fields on PackageRevisionResources.
 	// But we probably should have the key fields
-	return parsePackageRevisionFieldSelector(fieldSelector)
+	filter, err := parsePackageRevisionFieldSelector(fieldSelector)
+	if err != nil {
+		return filter, err
+	}
+	// PackageRevisionResources have no status marking them as shared.
+	if filter.SharedFrom != "" {
+		return filter, apierrors.NewBadRequest("fieldSelector field \"status.sharedFrom\" is only supported when listing packagerevisions")
+	}
+	return filter, nil
 }
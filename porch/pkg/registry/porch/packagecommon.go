@@ -23,6 +23,7 @@ import (
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/cache"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/meta"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/util/dryrun"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -71,23 +73,86 @@ func (r *packageCommon) listPackageRevisions(ctx context.Context, filter package
 			continue
 		}
 
-		revisions, err := r.cad.ListPackageRevisions(ctx, repositoryObj, filter.ListPackageRevisionFilter)
+		if err := r.listRepositoryPackageRevisions(ctx, repositoryObj, filter, selector, callback); err != nil {
+			return err
+		}
+	}
+
+	if filter.SharedFrom != "" {
+		return r.listSharedPackageRevisions(ctx, filter, selector, callback)
+	}
+	return nil
+}
+
+// listSharedPackageRevisions lists the published package revisions of the repositories of
+// other namespaces which are shared with the namespace of the request. They are marked
+// with the namespace they are shared from.
+func (r *packageCommon) listSharedPackageRevisions(ctx context.Context, filter packageRevisionFilter, selector labels.Selector, callback func(p *engine.PackageRevision) error) error {
+	ns, namespaced := genericapirequest.NamespaceFrom(ctx)
+	if !namespaced || ns == "" {
+		return apierrors.NewBadRequest("shared package revisions can only be listed in a namespace")
+	}
+
+	var opts []client.ListOption
+	if filter.SharedFrom != "*" {
+		opts = append(opts, client.InNamespace(filter.SharedFrom))
+	}
+	var repositories configapi.RepositoryList
+	if err := r.coreClient.List(ctx, &repositories, opts...); err != nil {
+		return fmt.Errorf("error listing repository objects: %w", err)
+	}
+
+	resolver := NewReferenceResolver(r.coreClient)
+	for i := range repositories.Items {
+		repositoryObj := &repositories.Items[i]
+
+		if repositoryObj.Namespace == ns || (filter.Repository != "" && filter.Repository != repositoryObj.GetName()) {
+			continue
+		}
+		shared, err := engine.IsRepositorySharedWith(ctx, resolver, repositoryObj, ns)
 		if err != nil {
 			return err
 		}
-		for _, rev := range revisions {
-			apiPkgRev, err := rev.GetPackageRevision(ctx)
-			if err != nil {
-				return err
-			}
+		if !shared {
+			continue
+		}
 
-			if selector != nil && !selector.Matches(labels.Set(apiPkgRev.Labels)) {
-				continue
+		if err := r.listRepositoryPackageRevisions(ctx, repositoryObj, filter, selector, func(p *engine.PackageRevision) error {
+			if p.Lifecycle() != api.PackageRevisionLifecyclePublished {
+				return nil
 			}
+			return callback(p.Shared())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			if err := callback(rev); err != nil {
-				return err
-			}
+// listRepositoryPackageRevisions lists the package revisions of the repository matching the
+// filter and selector.
+func (r *packageCommon) listRepositoryPackageRevisions(ctx context.Context, repositoryObj *configapi.Repository, filter packageRevisionFilter, selector labels.Selector, callback func(p *engine.PackageRevision) error) error {
+	revisions, _, err := r.cad.ListPackageRevisions(ctx, repositoryObj, filter.ListPackageRevisionFilter)
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		// Archived packages are only listed when selected explicitly.
+		if rev.IsArchived() != filter.Archived {
+			continue
+		}
+
+		apiPkgRev, err := rev.GetPackageRevision(ctx)
+		if err != nil {
+			return err
+		}
+
+		if selector != nil && !selector.Matches(labels.Set(apiPkgRev.Labels)) {
+			continue
+		}
+
+		if err := callback(rev); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -121,6 +186,10 @@ func (r *packageCommon) listPackages(ctx context.Context, filter packageFilter,
 			return err
 		}
 		for _, rev := range revisions {
+			// Archived packages are only listed when selected explicitly.
+			if rev.IsArchived() != filter.Archived {
+				continue
+			}
 			if err := callback(rev); err != nil {
 				return err
 			}
@@ -166,7 +235,7 @@ func (r *packageCommon) getRepoPkgRev(ctx context.Context, name string) (*engine
 	if err != nil {
 		return nil, err
 	}
-	revisions, err := r.cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{KubeObjectName: name})
+	revisions, _, err := r.cad.ListPackageRevisions(ctx, repositoryObj, repository.ListPackageRevisionFilter{KubeObjectName: name})
 	if err != nil {
 		return nil, err
 	}
@@ -255,6 +324,16 @@ func (r *packageCommon) updatePackageRevision(ctx context.Context, name string,
 		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("expected PackageRevision object, got %T", newRuntimeObj))
 	}
 
+	ctx = withFieldManager(ctx, options.FieldManager, newApiPkgRev)
+	if dryrun.IsDryRun(options.DryRun) {
+		// Updates are applied to the draft in the repository as they are made, so only
+		// creation can be a dry run.
+		if !isCreate {
+			return nil, false, apierrors.NewBadRequest("dry run is only supported when creating package revisions")
+		}
+		ctx = engine.WithDryRun(ctx)
+	}
+
 	repositoryName, err := ParseRepositoryName(name)
 	if err != nil {
 		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid name %q", name))
@@ -287,7 +366,7 @@ func (r *packageCommon) updatePackageRevision(ctx context.Context, name string,
 	if !isCreate {
 		rev, err := r.cad.UpdatePackageRevision(ctx, &repositoryObj, oldRepoPkgRev, oldApiPkgRev.(*api.PackageRevision), newApiPkgRev, parentPackage)
 		if err != nil {
-			return nil, false, apierrors.NewInternalError(err)
+			return nil, false, engineError(err)
 		}
 
 		updated, err := rev.GetPackageRevision(ctx)
@@ -300,7 +379,7 @@ func (r *packageCommon) updatePackageRevision(ctx context.Context, name string,
 		rev, err := r.cad.CreatePackageRevision(ctx, &repositoryObj, newApiPkgRev, parentPackage)
 		if err != nil {
 			klog.Infof("error creating package: %v", err)
-			return nil, false, apierrors.NewInternalError(err)
+			return nil, false, engineError(err)
 		}
 		createdApiPkgRev, err := rev.GetPackageRevision(ctx)
 		if err != nil {
@@ -388,7 +467,7 @@ func (r *packageCommon) updatePackage(ctx context.Context, name string, objInfo
 	if !isCreate {
 		rev, err := r.cad.UpdatePackage(ctx, &repositoryObj, oldPackage, oldRuntimeObj.(*api.Package), newObj)
 		if err != nil {
-			return nil, false, apierrors.NewInternalError(err)
+			return nil, false, engineError(err)
 		}
 
 		updated := rev.GetPackage()
@@ -463,3 +542,16 @@ func (r *packageCommon) validateUpdate(ctx context.Context, newRuntimeObj runtim
 	r.updateStrategy.Canonicalize(newRuntimeObj)
 	return nil
 }
+
+// withFieldManager returns a context identifying the field manager of the request for
+// metadata writes. The manager defaults to the name of the requesting user.
+func withFieldManager(ctx context.Context, fieldManager string, obj *api.PackageRevision) context.Context {
+	if fieldManager == "" {
+		if user, ok := genericapirequest.UserFrom(ctx); ok {
+			fieldManager = user.GetName()
+		}
+	}
+	force := obj.Annotations[api.ForceFieldOwnershipAnnotation] == "true"
+	delete(obj.Annotations, api.ForceFieldOwnershipAnnotation)
+	return meta.WithFieldManager(ctx, meta.FieldManager{Name: fieldManager, Force: force})
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// packageMoves moves packages between repositories. Like a consistency check, a move is
+// created to run it and is returned with its outcome, but isn't stored.
+type packageMoves struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageMoves{}
+var _ rest.Scoper = &packageMoves{}
+var _ rest.Creater = &packageMoves{}
+
+// New returns an empty object that can be used with Create after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (m *packageMoves) New() runtime.Object {
+	return &api.PackageMove{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (m *packageMoves) NamespaceScoped() bool {
+	return true
+}
+
+// Create moves the package, or reports the steps of the move if spec.dryRun is set.
+func (m *packageMoves) Create(ctx context.Context, runtimeObject runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "packageMoves::Create", trace.WithAttributes())
+	defer span.End()
+
+	ns, namespaced := genericapirequest.NamespaceFrom(ctx)
+	if !namespaced {
+		return nil, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	obj, ok := runtimeObject.(*api.PackageMove)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected PackageMove object, got %T", runtimeObject))
+	}
+
+	fieldErrors := m.common.createStrategy.Validate(ctx, runtimeObject)
+	if len(fieldErrors) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageMove").GroupKind(), obj.Name, fieldErrors)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, runtimeObject); err != nil {
+			return nil, err
+		}
+	}
+
+	srcRepo, err := m.common.getRepositoryObj(ctx, types.NamespacedName{Name: obj.Spec.SourceRepository, Namespace: ns})
+	if err != nil {
+		return nil, err
+	}
+	dstRepo, err := m.common.getRepositoryObj(ctx, types.NamespacedName{Name: obj.Spec.DestinationRepository, Namespace: ns})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := engine.MoveOptions{
+		IncludeHistory:    obj.Spec.IncludeHistory,
+		UpdateDownstreams: obj.Spec.UpdateDownstreams,
+		DryRun:            obj.Spec.DryRun,
+	}
+	if opts.UpdateDownstreams {
+		var repositories configapi.RepositoryList
+		if err := m.common.coreClient.List(ctx, &repositories, client.InNamespace(ns)); err != nil {
+			return nil, apierrors.NewInternalError(fmt.Errorf("error listing repository objects: %w", err))
+		}
+		opts.NamespaceRepositories = repositories.Items
+	}
+
+	status, err := m.common.cad.MovePackage(ctx, srcRepo, dstRepo, obj.Spec.PackageName, opts)
+	if err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) || apierrors.IsBadRequest(err) {
+			return nil, err
+		}
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	result := obj.DeepCopy()
+	result.Namespace = ns
+	result.Status = *status
+	return result, nil
+}
+
+type packageMoveStrategy struct{}
+
+var _ SimpleRESTCreateStrategy = packageMoveStrategy{}
+
+// Validate returns an ErrorList with validation errors or nil.  Validate
+// is invoked after default fields in the object have been filled in
+// before the object is persisted.  This method should not mutate the
+// object.
+func (s packageMoveStrategy) Validate(ctx context.Context, runtimeObj runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	obj := runtimeObj.(*api.PackageMove)
+	if obj.Spec.PackageName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "packageName"), "the package to move is required"))
+	}
+	if obj.Spec.SourceRepository == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "sourceRepository"), "the repository of the package is required"))
+	}
+	if obj.Spec.DestinationRepository == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "destinationRepository"), "the repository to move the package to is required"))
+	} else if obj.Spec.DestinationRepository == obj.Spec.SourceRepository {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "destinationRepository"), obj.Spec.DestinationRepository, "the package must be moved to another repository"))
+	}
+	if len(obj.Status.Revisions) != 0 || len(obj.Status.Downstreams) != 0 || obj.Status.Source != "" {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("status"), "the outcome is reported by the move"))
+	}
+	return allErrs
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/util/dryrun"
 	"k8s.io/klog/v2"
 )
 
@@ -154,9 +155,13 @@ func (r *packageRevisions) Create(ctx context.Context, runtimeObject runtime.Obj
 		parentPackage = p
 	}
 
+	ctx = withFieldManager(ctx, options.FieldManager, newApiPkgRev)
+	if dryrun.IsDryRun(options.DryRun) {
+		ctx = engine.WithDryRun(ctx)
+	}
 	createdRepoPkgRev, err := r.cad.CreatePackageRevision(ctx, repositoryObj, newApiPkgRev, parentPackage)
 	if err != nil {
-		return nil, apierrors.NewInternalError(err)
+		return nil, engineError(err)
 	}
 
 	createdApiPkgRev, err := createdRepoPkgRev.GetPackageRevision(ctx)
@@ -215,6 +220,9 @@ func (r *packageRevisions) Delete(ctx context.Context, name string, deleteValida
 	}
 
 	if err := r.cad.DeletePackageRevision(ctx, repositoryObj, repoPkgRev); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, false, err
+		}
 		return nil, false, apierrors.NewInternalError(err)
 	}
 
@@ -15,9 +15,11 @@
 package porch
 
 import (
+	"fmt"
 	"testing"
 
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func TestApprovalUpdateStrategy(t *testing.T) {
@@ -64,3 +66,38 @@ func TestApprovalUpdateStrategy(t *testing.T) {
 		}
 	}
 }
+
+func TestRepeatedApproval(t *testing.T) {
+	for _, tc := range []struct {
+		old, new api.PackageRevisionLifecycle
+		repeated bool
+		conflict bool
+	}{
+		{old: api.PackageRevisionLifecyclePublished, new: api.PackageRevisionLifecyclePublished, repeated: true},
+		{old: api.PackageRevisionLifecycleDraft, new: api.PackageRevisionLifecycleDraft, repeated: true},
+		{old: api.PackageRevisionLifecyclePublished, new: api.PackageRevisionLifecycleDraft, conflict: true},
+		{old: api.PackageRevisionLifecyclePublished, new: api.PackageRevisionLifecycleProposed, conflict: true},
+		{old: api.PackageRevisionLifecycleProposed, new: api.PackageRevisionLifecyclePublished},
+		{old: api.PackageRevisionLifecycleProposed, new: api.PackageRevisionLifecycleDraft},
+		{old: api.PackageRevisionLifecycleDraft, new: api.PackageRevisionLifecyclePublished},
+	} {
+		t.Run(fmt.Sprintf("%s-%s", tc.old, tc.new), func(t *testing.T) {
+			oldRev := &api.PackageRevision{Spec: api.PackageRevisionSpec{Lifecycle: tc.old}}
+			newRev := &api.PackageRevision{Spec: api.PackageRevisionSpec{Lifecycle: tc.new}}
+
+			repeated, err := repeatedApproval(oldRev, newRev)
+			if tc.conflict {
+				if !apierrors.IsConflict(err) {
+					t.Fatalf("got error %v, want a conflict", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("repeatedApproval failed: %v", err)
+			}
+			if repeated != tc.repeated {
+				t.Errorf("got repeated %t, want %t", repeated, tc.repeated)
+			}
+		})
+	}
+}
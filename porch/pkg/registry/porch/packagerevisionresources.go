@@ -164,7 +164,7 @@ func (r *packageRevisionResources) Update(ctx context.Context, name string, objI
 
 	rev, err := r.cad.UpdatePackageResources(ctx, &repositoryObj, oldRepoPkgRev, oldApiPkgRevResources, newObj)
 	if err != nil {
-		return nil, false, apierrors.NewInternalError(err)
+		return nil, false, engineError(err)
 	}
 
 	created, err := rev.GetResources(ctx)
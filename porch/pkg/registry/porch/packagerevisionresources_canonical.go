@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+// packageRevisionResourcesCanonical serves the canonical subresource of package revision
+// resources: the resources in canonical form, so that byte-level diffs between two reads
+// only show semantic changes.
+type packageRevisionResourcesCanonical struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageRevisionResourcesCanonical{}
+var _ rest.Scoper = &packageRevisionResourcesCanonical{}
+var _ rest.Getter = &packageRevisionResourcesCanonical{}
+
+// New returns an empty object that can be used with Create and Update after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (c *packageRevisionResourcesCanonical) New() runtime.Object {
+	return &api.PackageRevisionResources{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (c *packageRevisionResourcesCanonical) NamespaceScoped() bool {
+	return true
+}
+
+func (c *packageRevisionResourcesCanonical) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "packageRevisionResourcesCanonical::Get", trace.WithAttributes())
+	defer span.End()
+
+	pkg, err := c.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	apiPkgResources, err := pkg.GetResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := canonicalResources(apiPkgResources.Spec.Resources)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	apiPkgResources.Spec.Resources = resources
+	return apiPkgResources, nil
+}
+
+// canonicalResources returns resources in canonical form. YAML files and Kptfiles are
+// re-serialized with a consistent indentation and with fields ordered the way
+// `kpt fn fmt` orders them; comments and the order of documents and list items are
+// preserved. Other files are returned unchanged.
+func canonicalResources(resources map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(resources))
+	for path, contents := range resources {
+		if !isYAMLFile(path) {
+			result[path] = contents
+			continue
+		}
+		canonical, err := canonicalYAML(contents)
+		if err != nil {
+			return nil, fmt.Errorf("cannot canonicalize %s: %w", path, err)
+		}
+		result[path] = canonical
+	}
+	return result, nil
+}
+
+func canonicalYAML(contents string) (string, error) {
+	var out bytes.Buffer
+	if err := (kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{
+			Reader:            strings.NewReader(contents),
+			DisableUnwrapping: true,
+		}},
+		Filters: []kio.Filter{filters.FormatFilter{UseSchema: true}},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: &out}},
+	}).Execute(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func isYAMLFile(path string) bool {
+	name := filepath.Base(path)
+	if name == kptfile.KptFileName {
+		return true
+	}
+	for _, m := range kio.MatchAll {
+		if matched, err := filepath.Match(m, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
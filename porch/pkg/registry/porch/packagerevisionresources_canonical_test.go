@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCanonicalResources(t *testing.T) {
+	original := map[string]string{
+		"cm.yaml": strings.TrimSpace(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: game-config # the game
+  namespace: default
+data:
+  foo: bar
+  items:
+  - b
+  - a
+`),
+		"README.md": "# foo\n",
+	}
+	reformatted := map[string]string{
+		"cm.yaml": strings.TrimSpace(`
+data:
+    items:
+        - b
+        - a
+    foo: bar
+kind: ConfigMap
+metadata:
+    namespace: default
+    name: game-config # the game
+apiVersion: v1
+`),
+		"README.md": "# foo\n",
+	}
+
+	canonical := func(resources map[string]string) map[string]string {
+		got, err := canonicalResources(resources)
+		if err != nil {
+			t.Fatalf("canonicalResources failed: %v", err)
+		}
+		return got
+	}
+
+	first, second := canonical(original), canonical(original)
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("canonicalizing the same resources twice returned different bytes (-first, +second): %s", diff)
+	}
+	if diff := cmp.Diff(first, canonical(reformatted)); diff != "" {
+		t.Errorf("reformatted resources differ in canonical form (-original, +reformatted): %s", diff)
+	}
+	if cm := first["cm.yaml"]; !strings.Contains(cm, "name: game-config # the game") || !strings.Contains(cm, "- b\n  - a") {
+		t.Errorf("canonical form must preserve comments and list order, got:\n%s", cm)
+	}
+	if got, want := first["README.md"], original["README.md"]; got != want {
+		t.Errorf("non-YAML file changed: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// packageRevisionResourcesStaging serves the staging subresource of package revision
+// resources: it stages chunks of new resources of a draft for a resumable upload, and
+// reports the chunks which aren't staged yet.
+type packageRevisionResourcesStaging struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageRevisionResourcesStaging{}
+var _ rest.Scoper = &packageRevisionResourcesStaging{}
+var _ rest.NamedCreater = &packageRevisionResourcesStaging{}
+
+// New returns an empty object that can be used with Create after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (s *packageRevisionResourcesStaging) New() runtime.Object {
+	return &api.PackageRevisionResourcesStaging{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (s *packageRevisionResourcesStaging) NamespaceScoped() bool {
+	return true
+}
+
+// Create stages the chunks of the spec, and returns the digests of the spec which aren't
+// staged in the status. The data of the chunks is not returned.
+func (s *packageRevisionResourcesStaging) Create(ctx context.Context, name string, runtimeObject runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "packageRevisionResourcesStaging::Create", trace.WithAttributes())
+	defer span.End()
+
+	ns, namespaced := genericapirequest.NamespaceFrom(ctx)
+	if !namespaced {
+		return nil, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	obj, ok := runtimeObject.(*api.PackageRevisionResourcesStaging)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected PackageRevisionResourcesStaging object, got %T", runtimeObject))
+	}
+	if allErrs := validateStaging(obj); len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevisionResourcesStaging").GroupKind(), name, allErrs)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, runtimeObject); err != nil {
+			return nil, err
+		}
+	}
+
+	pkgRev, err := s.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	uploader, err := s.common.cad.StagedUploader(pkgRev)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	for _, chunk := range obj.Spec.Chunks {
+		if err := uploader.Put(ctx, chunk.Digest, chunk.Data); err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+	}
+	missing, err := uploader.Missing(ctx, obj.Spec.Digests)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	result := obj.DeepCopy()
+	result.Name = name
+	result.Namespace = ns
+	result.Spec.Chunks = nil
+	result.Status = api.PackageRevisionResourcesStagingStatus{Missing: missing}
+	return result, nil
+}
+
+// validateStaging checks the digests of the staging request, and that the digest of each
+// chunk matches its data.
+func validateStaging(obj *api.PackageRevisionResourcesStaging) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	for i, chunk := range obj.Spec.Chunks {
+		path := specPath.Child("chunks").Index(i).Child("digest")
+		if err := api.ValidateStagedChunkDigest(chunk.Digest); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, chunk.Digest, err.Error()))
+		} else if actual := api.StagedChunkDigest(chunk.Data); actual != chunk.Digest {
+			allErrs = append(allErrs, field.Invalid(path, chunk.Digest, fmt.Sprintf("does not match the digest %s of the data", actual)))
+		}
+	}
+	for i, digest := range obj.Spec.Digests {
+		if err := api.ValidateStagedChunkDigest(digest); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("digests").Index(i), digest, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// packageRevisionResourcesFinalize serves the finalize subresource of package revision
+// resources: it replaces the resources of a draft with the files of a manifest, assembled
+// from the chunks staged through the staging subresource.
+type packageRevisionResourcesFinalize struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageRevisionResourcesFinalize{}
+var _ rest.Scoper = &packageRevisionResourcesFinalize{}
+var _ rest.NamedCreater = &packageRevisionResourcesFinalize{}
+
+// New returns an empty object that can be used with Create after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (f *packageRevisionResourcesFinalize) New() runtime.Object {
+	return &api.PackageRevisionResourcesFinalize{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (f *packageRevisionResourcesFinalize) NamespaceScoped() bool {
+	return true
+}
+
+// Create updates the resources of the package revision to the files of the manifest, and
+// returns the updated PackageRevisionResources.
+func (f *packageRevisionResourcesFinalize) Create(ctx context.Context, name string, runtimeObject runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "packageRevisionResourcesFinalize::Create", trace.WithAttributes())
+	defer span.End()
+
+	if _, namespaced := genericapirequest.NamespaceFrom(ctx); !namespaced {
+		return nil, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	obj, ok := runtimeObject.(*api.PackageRevisionResourcesFinalize)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected PackageRevisionResourcesFinalize object, got %T", runtimeObject))
+	}
+	if allErrs := validateFinalize(obj); len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("PackageRevisionResourcesFinalize").GroupKind(), name, allErrs)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, runtimeObject); err != nil {
+			return nil, err
+		}
+	}
+
+	repositoryObj, err := f.common.getRepositoryObjFromName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	pkgRev, err := f.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := f.common.cad.FinalizeStagedResources(ctx, repositoryObj, pkgRev, staging.Manifest{Files: obj.Spec.Files})
+	if err != nil {
+		return nil, engineError(err)
+	}
+	resources, err := rev.GetResources(ctx)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	return resources, nil
+}
+
+// validateFinalize checks the digests of the manifest.
+func validateFinalize(obj *api.PackageRevisionResourcesFinalize) field.ErrorList {
+	var allErrs field.ErrorList
+	filesPath := field.NewPath("spec", "files")
+	if obj.Spec.Files == nil {
+		return append(allErrs, field.Required(filesPath, "the manifest of the resources is required"))
+	}
+	for path, digests := range obj.Spec.Files {
+		for i, digest := range digests {
+			if err := api.ValidateStagedChunkDigest(digest); err != nil {
+				allErrs = append(allErrs, field.Invalid(filesPath.Key(path).Index(i), digest, err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateStaging(t *testing.T) {
+	data := []byte("kind: Kptfile\n")
+	digest := api.StagedChunkDigest(data)
+
+	for name, tc := range map[string]struct {
+		spec api.PackageRevisionResourcesStagingSpec
+		want []string
+	}{
+		"valid": {
+			spec: api.PackageRevisionResourcesStagingSpec{
+				Chunks:  []api.StagedChunk{{Digest: digest, Data: data}},
+				Digests: []string{digest},
+			},
+		},
+		"mismatched digest": {
+			spec: api.PackageRevisionResourcesStagingSpec{
+				Chunks: []api.StagedChunk{{Digest: digest, Data: []byte("kind: Other\n")}},
+			},
+			want: []string{"spec.chunks[0].digest"},
+		},
+		"invalid digests": {
+			spec: api.PackageRevisionResourcesStagingSpec{
+				Chunks:  []api.StagedChunk{{Digest: "md5:abc", Data: data}},
+				Digests: []string{digest, "sha256:../../Kptfile"},
+			},
+			want: []string{"spec.chunks[0].digest", "spec.digests[1]"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, err := range validateStaging(&api.PackageRevisionResourcesStaging{Spec: tc.spec}) {
+				got = append(got, err.Field)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected invalid fields (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateFinalize(t *testing.T) {
+	digest := api.StagedChunkDigest([]byte("kind: Kptfile\n"))
+
+	for name, tc := range map[string]struct {
+		files map[string][]string
+		want  []string
+	}{
+		"valid": {
+			files: map[string][]string{"Kptfile": {digest}, "empty": {}},
+		},
+		"no manifest": {
+			want: []string{"spec.files"},
+		},
+		"invalid digest": {
+			files: map[string][]string{"Kptfile": {digest, "sha256:"}},
+			want:  []string{"spec.files[Kptfile][1]"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			obj := &api.PackageRevisionResourcesFinalize{Spec: api.PackageRevisionResourcesFinalizeSpec{Files: tc.files}}
+			for _, err := range validateFinalize(obj) {
+				got = append(got, err.Field)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected invalid fields (-want, +got): %s", diff)
+			}
+		})
+	}
+}
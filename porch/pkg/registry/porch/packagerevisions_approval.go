@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -59,9 +60,51 @@ func (a *packageRevisionsApproval) Get(ctx context.Context, name string, options
 // to true.
 func (a *packageRevisionsApproval) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
 	allowCreate := false // do not allow create on update
+
+	oldRepoPkgRev, err := a.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	oldApiPkgRev, err := oldRepoPkgRev.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	newRuntimeObj, err := objInfo.UpdatedObject(ctx, oldApiPkgRev)
+	if err != nil {
+		return nil, false, err
+	}
+	if newApiPkgRev, ok := newRuntimeObj.(*api.PackageRevision); ok {
+		repeated, err := repeatedApproval(oldApiPkgRev, newApiPkgRev)
+		if err != nil {
+			return nil, false, err
+		}
+		if repeated {
+			// Nothing changes, so nothing is written to the repository.
+			return oldApiPkgRev, false, nil
+		}
+	}
+
 	return a.common.updatePackageRevision(ctx, name, objInfo, createValidation, updateValidation, allowCreate, options)
 }
 
+// repeatedApproval returns whether the update of the approval of oldObj to newObj
+// repeats an approval or rejection already made, which succeeds without changing the
+// package revision. A published package revision can't be rejected; that returns a
+// Conflict error.
+func repeatedApproval(oldObj, newObj *api.PackageRevision) (bool, error) {
+	switch oldLifecycle, newLifecycle := oldObj.Spec.Lifecycle, newObj.Spec.Lifecycle; {
+	case oldLifecycle == api.PackageRevisionLifecyclePublished && newLifecycle == api.PackageRevisionLifecyclePublished:
+		return true, nil
+	case oldLifecycle == api.PackageRevisionLifecyclePublished:
+		return false, apierrors.NewConflict(api.PackageRevisionGVR.GroupResource(), oldObj.Name,
+			fmt.Errorf("package revision is already Published; its lifecycle cannot be changed to %q", newLifecycle))
+	case oldLifecycle == api.PackageRevisionLifecycleDraft && newLifecycle == api.PackageRevisionLifecycleDraft:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 type packageRevisionApprovalStrategy struct{}
 
 func (s packageRevisionApprovalStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
@@ -0,0 +1,161 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+)
+
+// packageRevisionsDeployment is the deployment subresource of packagerevisions, through
+// which the agents deploying package revisions report their status. It is a
+// subresource so that the agents can be allowed to report without being allowed to
+// change package revisions.
+type packageRevisionsDeployment struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageRevisionsDeployment{}
+var _ rest.Scoper = &packageRevisionsDeployment{}
+var _ rest.Getter = &packageRevisionsDeployment{}
+var _ rest.Updater = &packageRevisionsDeployment{}
+
+// New returns an empty object that can be used with Create and Update after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (d *packageRevisionsDeployment) New() runtime.Object {
+	return &api.PackageRevision{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (d *packageRevisionsDeployment) NamespaceScoped() bool {
+	return true
+}
+
+func (d *packageRevisionsDeployment) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	pkg, err := d.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.GetPackageRevision(ctx)
+}
+
+// Update records status.deploymentStatus of the package revision, and clears it if it
+// isn't set.
+func (d *packageRevisionsDeployment) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	ctx, span := tracer.Start(ctx, "packageRevisionsDeployment::Update", trace.WithAttributes())
+	defer span.End()
+
+	if _, namespaced := genericapirequest.NamespaceFrom(ctx); !namespaced {
+		return nil, false, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	oldPackage, err := d.common.getRepoPkgRev(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	oldObj, err := oldPackage.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	newRuntimeObj, err := objInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		klog.Infof("update failed to construct UpdatedObject: %v", err)
+		return nil, false, err
+	}
+	if err := d.common.validateUpdate(ctx, newRuntimeObj, oldObj, false, createValidation,
+		updateValidation, "PackageRevision", name); err != nil {
+		return nil, false, err
+	}
+	newObj, ok := newRuntimeObj.(*api.PackageRevision)
+	if !ok {
+		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("expected PackageRevision object, got %T", newRuntimeObj))
+	}
+
+	updated, err := d.common.cad.UpdateDeploymentStatus(ctx, oldPackage, newObj.Status.DeploymentStatus)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		return nil, false, apierrors.NewInternalError(err)
+	}
+	obj, err := updated.GetPackageRevision(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return obj, false, nil
+}
+
+type packageRevisionDeploymentStrategy struct{}
+
+func (s packageRevisionDeploymentStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+// ValidateUpdate rejects updates of the package revision other than of its deployment
+// status, and deployment statuses which aren't complete.
+func (s packageRevisionDeploymentStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	oldRevision := old.(*api.PackageRevision)
+	newRevision := obj.(*api.PackageRevision)
+
+	if !apiequality.Semantic.DeepEqual(oldRevision.Spec, newRevision.Spec) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "only status.deploymentStatus can be updated through the deployment subresource"))
+	}
+
+	status := newRevision.Status.DeploymentStatus
+	if status == nil {
+		return allErrs
+	}
+	path := field.NewPath("status", "deploymentStatus")
+	if strings.TrimSpace(status.Cluster) == "" {
+		allErrs = append(allErrs, field.Required(path.Child("cluster"), "the cluster the package revision is deployed to is required"))
+	}
+	switch status.SyncStatus {
+	case api.SyncStatusSynced, api.SyncStatusOutOfSync, api.SyncStatusError, api.SyncStatusUnknown:
+		// valid
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("syncStatus"), status.SyncStatus, []string{
+			string(api.SyncStatusSynced),
+			string(api.SyncStatusOutOfSync),
+			string(api.SyncStatusError),
+			string(api.SyncStatusUnknown),
+		}))
+	}
+	return allErrs
+}
+
+func (s packageRevisionDeploymentStrategy) Canonicalize(obj runtime.Object) {}
+
+var _ SimpleRESTCreateStrategy = packageRevisionDeploymentStrategy{}
+
+// Validate returns an ErrorList with validation errors or nil.  Validate
+// is invoked after default fields in the object have been filled in
+// before the object is persisted.  This method should not mutate the
+// object.
+func (s packageRevisionDeploymentStrategy) Validate(ctx context.Context, runtimeObj runtime.Object) field.ErrorList {
+	return field.ErrorList{}
+}
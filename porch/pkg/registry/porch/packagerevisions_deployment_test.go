@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+)
+
+func TestDeploymentUpdateStrategy(t *testing.T) {
+	s := packageRevisionDeploymentStrategy{}
+	old := &api.PackageRevision{Spec: api.PackageRevisionSpec{
+		PackageName:    "app",
+		Revision:       "v1",
+		RepositoryName: "deployments",
+		Lifecycle:      api.PackageRevisionLifecyclePublished,
+	}}
+
+	for name, tc := range map[string]struct {
+		update func(p *api.PackageRevision)
+		valid  bool
+	}{
+		"report": {
+			update: func(p *api.PackageRevision) {
+				p.Status.DeploymentStatus = &api.DeploymentStatus{Cluster: "prod", ObservedCommit: "abc123", SyncStatus: api.SyncStatusSynced}
+			},
+			valid: true,
+		},
+		"clear": {
+			update: func(p *api.PackageRevision) { p.Status.DeploymentStatus = nil },
+			valid:  true,
+		},
+		"no cluster": {
+			update: func(p *api.PackageRevision) {
+				p.Status.DeploymentStatus = &api.DeploymentStatus{SyncStatus: api.SyncStatusSynced}
+			},
+		},
+		"unsupported sync status": {
+			update: func(p *api.PackageRevision) {
+				p.Status.DeploymentStatus = &api.DeploymentStatus{Cluster: "prod", SyncStatus: "Healthy"}
+			},
+		},
+		"lifecycle": {
+			update: func(p *api.PackageRevision) {
+				p.Status.DeploymentStatus = &api.DeploymentStatus{Cluster: "prod", SyncStatus: api.SyncStatusSynced}
+				p.Spec.Lifecycle = api.PackageRevisionLifecycleDraft
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			tc.update(updated)
+			errs := s.ValidateUpdate(context.Background(), updated, old)
+			if tc.valid && len(errs) != 0 {
+				t.Errorf("update was rejected: %v", errs)
+			}
+			if !tc.valid && len(errs) == 0 {
+				t.Errorf("update was accepted")
+			}
+		})
+	}
+}
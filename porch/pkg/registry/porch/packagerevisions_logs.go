@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// packageRevisionsLogs serves the logs subresource of package revisions: the logs the
+// functions of a package revision wrote during its last create or update.
+type packageRevisionsLogs struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packageRevisionsLogs{}
+var _ rest.Scoper = &packageRevisionsLogs{}
+var _ rest.Getter = &packageRevisionsLogs{}
+
+// New returns an empty object that can be used with Create and Update after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (l *packageRevisionsLogs) New() runtime.Object {
+	return &api.PackageRevisionLogs{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (l *packageRevisionsLogs) NamespaceScoped() bool {
+	return true
+}
+
+func (l *packageRevisionsLogs) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "packageRevisionsLogs::Get", trace.WithAttributes())
+	defer span.End()
+
+	repositoryObj, err := l.common.getRepositoryObjFromName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	// The logs of deleted package revisions are not served.
+	if _, err := l.common.getRepoPkgRev(ctx, name); err != nil {
+		return nil, err
+	}
+	tasks, err := l.common.cad.GetFunctionLogs(ctx, repositoryObj, name)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	return &api.PackageRevisionLogs{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: api.SchemeGroupVersion.String(),
+			Kind:       "PackageRevisionLogs",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: repositoryObj.Namespace,
+		},
+		Tasks: tasks,
+	}, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+)
+
+// packagesFreeze is the freeze subresource of packages. Freezing is a subresource so
+// that it can be authorized separately from other updates of packages.
+type packagesFreeze struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &packagesFreeze{}
+var _ rest.Scoper = &packagesFreeze{}
+var _ rest.Getter = &packagesFreeze{}
+var _ rest.Updater = &packagesFreeze{}
+
+// New returns an empty object that can be used with Create and Update after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (f *packagesFreeze) New() runtime.Object {
+	return &api.Package{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (f *packagesFreeze) NamespaceScoped() bool {
+	return true
+}
+
+func (f *packagesFreeze) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	pkg, err := f.common.getPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.GetPackage(), nil
+}
+
+// Update freezes the package if spec.freeze is set, and unfreezes it otherwise.
+func (f *packagesFreeze) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	ctx, span := tracer.Start(ctx, "packagesFreeze::Update", trace.WithAttributes())
+	defer span.End()
+
+	if _, namespaced := genericapirequest.NamespaceFrom(ctx); !namespaced {
+		return nil, false, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	oldPackage, err := f.common.getPackage(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	oldObj := oldPackage.GetPackage()
+
+	newRuntimeObj, err := objInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		klog.Infof("update failed to construct UpdatedObject: %v", err)
+		return nil, false, err
+	}
+	if err := f.common.validateUpdate(ctx, newRuntimeObj, oldObj, false, createValidation,
+		updateValidation, "Package", name); err != nil {
+		return nil, false, err
+	}
+	newObj, ok := newRuntimeObj.(*api.Package)
+	if !ok {
+		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("expected Package object, got %T", newRuntimeObj))
+	}
+
+	repositoryObj, err := f.common.getRepositoryObjFromName(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updated, err := f.common.cad.UpdatePackageFreeze(ctx, repositoryObj, oldPackage, newObj.Spec.Freeze)
+	if err != nil {
+		if apierrors.IsBadRequest(err) {
+			return nil, false, err
+		}
+		return nil, false, apierrors.NewInternalError(err)
+	}
+	return updated.GetPackage(), false, nil
+}
+
+type packageFreezeStrategy struct{}
+
+func (s packageFreezeStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+// ValidateUpdate rejects updates of the package other than of its freeze.
+func (s packageFreezeStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	oldPackage := old.(*api.Package)
+	newPackage := obj.(*api.Package)
+
+	oldSpec, newSpec := oldPackage.Spec, newPackage.Spec
+	oldSpec.Freeze, newSpec.Freeze = nil, nil
+	if oldSpec != newSpec {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "only spec.freeze can be updated through the freeze subresource"))
+	}
+
+	if freeze := newPackage.Spec.Freeze; freeze != nil && strings.TrimSpace(freeze.Reason) == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "freeze", "reason"), "the reason of the freeze is required"))
+	}
+	return allErrs
+}
+
+func (s packageFreezeStrategy) Canonicalize(obj runtime.Object) {}
+
+var _ SimpleRESTCreateStrategy = packageFreezeStrategy{}
+
+// Validate returns an ErrorList with validation errors or nil.  Validate
+// is invoked after default fields in the object have been filled in
+// before the object is persisted.  This method should not mutate the
+// object.
+func (s packageFreezeStrategy) Validate(ctx context.Context, runtimeObj runtime.Object) field.ErrorList {
+	return field.ErrorList{}
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+)
+
+func TestFreezeUpdateStrategy(t *testing.T) {
+	s := packageFreezeStrategy{}
+	old := &api.Package{Spec: api.PackageSpec{PackageName: "basens", RepositoryName: "blueprints"}}
+
+	for name, tc := range map[string]struct {
+		update func(p *api.Package)
+		valid  bool
+	}{
+		"freeze": {
+			update: func(p *api.Package) { p.Spec.Freeze = &api.PackageFreeze{Reason: "incident"} },
+			valid:  true,
+		},
+		"unfreeze": {
+			update: func(p *api.Package) { p.Spec.Freeze = nil },
+			valid:  true,
+		},
+		"no reason": {
+			update: func(p *api.Package) { p.Spec.Freeze = &api.PackageFreeze{Reason: " "} },
+		},
+		"archive": {
+			update: func(p *api.Package) {
+				p.Spec.Freeze = &api.PackageFreeze{Reason: "incident"}
+				p.Spec.Archived = true
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			tc.update(updated)
+			errs := s.ValidateUpdate(context.Background(), updated, old)
+			if tc.valid && len(errs) != 0 {
+				t.Errorf("update was rejected: %v", errs)
+			}
+			if !tc.valid && len(errs) == 0 {
+				t.Errorf("update was accepted")
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package porch
 import (
 	"context"
 
+	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -39,3 +40,23 @@ func (r *referenceResolver) ResolveReference(ctx context.Context, namespace, nam
 		Name:      name,
 	}, result)
 }
+
+func NewRepositoryLister(coreClient client.Reader) engine.RepositoryLister {
+	return &repositoryLister{
+		coreClient: coreClient,
+	}
+}
+
+type repositoryLister struct {
+	coreClient client.Reader
+}
+
+var _ engine.RepositoryLister = &repositoryLister{}
+
+func (r *repositoryLister) ListRepositories(ctx context.Context, namespace string) ([]configapi.Repository, error) {
+	var repositories configapi.RepositoryList
+	if err := r.coreClient.List(ctx, &repositories, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	return repositories.Items, nil
+}
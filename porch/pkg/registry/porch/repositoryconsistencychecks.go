@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// repositoryConsistencyChecks runs consistency checks of repositories. Repositories
+// aren't served by Porch, so the check can't be a subresource of them; like a
+// SubjectAccessReview, a check is created to run it and is returned with its findings,
+// but isn't stored.
+type repositoryConsistencyChecks struct {
+	common packageCommon
+}
+
+var _ rest.Storage = &repositoryConsistencyChecks{}
+var _ rest.Scoper = &repositoryConsistencyChecks{}
+var _ rest.Creater = &repositoryConsistencyChecks{}
+
+// New returns an empty object that can be used with Create after request data has been put into it.
+// This object must be a pointer type for use with Codec.DecodeInto([]byte, runtime.Object)
+func (c *repositoryConsistencyChecks) New() runtime.Object {
+	return &api.RepositoryConsistencyCheck{}
+}
+
+// NamespaceScoped returns true if the storage is namespaced
+func (c *repositoryConsistencyChecks) NamespaceScoped() bool {
+	return true
+}
+
+// Create checks the consistency of the repository, repairing it if spec.repair is set.
+func (c *repositoryConsistencyChecks) Create(ctx context.Context, runtimeObject runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, span := tracer.Start(ctx, "repositoryConsistencyChecks::Create", trace.WithAttributes())
+	defer span.End()
+
+	ns, namespaced := genericapirequest.NamespaceFrom(ctx)
+	if !namespaced {
+		return nil, apierrors.NewBadRequest("namespace must be specified")
+	}
+
+	obj, ok := runtimeObject.(*api.RepositoryConsistencyCheck)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected RepositoryConsistencyCheck object, got %T", runtimeObject))
+	}
+
+	fieldErrors := c.common.createStrategy.Validate(ctx, runtimeObject)
+	if len(fieldErrors) > 0 {
+		return nil, apierrors.NewInvalid(api.SchemeGroupVersion.WithKind("RepositoryConsistencyCheck").GroupKind(), obj.Name, fieldErrors)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, runtimeObject); err != nil {
+			return nil, err
+		}
+	}
+
+	repositoryObj, err := c.common.getRepositoryObj(ctx, types.NamespacedName{Name: obj.Spec.RepositoryName, Namespace: ns})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []api.ConsistencyFinding
+	if obj.Spec.Repair {
+		findings, err = c.common.cad.RepairConsistency(ctx, repositoryObj)
+	} else {
+		findings, err = c.common.cad.CheckConsistency(ctx, repositoryObj)
+	}
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	result := obj.DeepCopy()
+	result.Namespace = ns
+	result.Status.Findings = findings
+	return result, nil
+}
+
+type repositoryConsistencyCheckStrategy struct{}
+
+var _ SimpleRESTCreateStrategy = repositoryConsistencyCheckStrategy{}
+
+// Validate returns an ErrorList with validation errors or nil.  Validate
+// is invoked after default fields in the object have been filled in
+// before the object is persisted.  This method should not mutate the
+// object.
+func (s repositoryConsistencyCheckStrategy) Validate(ctx context.Context, runtimeObj runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	obj := runtimeObj.(*api.RepositoryConsistencyCheck)
+	if obj.Spec.RepositoryName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "repositoryName"), "the repository to check is required"))
+	}
+	if len(obj.Status.Findings) != 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("status", "findings"), "findings are reported by the check"))
+	}
+	return allErrs
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package porch
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+)
+
+func TestRepositoryConsistencyCheckStrategy(t *testing.T) {
+	s := repositoryConsistencyCheckStrategy{}
+
+	for name, tc := range map[string]struct {
+		check api.RepositoryConsistencyCheck
+		valid bool
+	}{
+		"check": {
+			check: api.RepositoryConsistencyCheck{Spec: api.RepositoryConsistencyCheckSpec{RepositoryName: "blueprints"}},
+			valid: true,
+		},
+		"repair": {
+			check: api.RepositoryConsistencyCheck{Spec: api.RepositoryConsistencyCheckSpec{RepositoryName: "blueprints", Repair: true}},
+			valid: true,
+		},
+		"no repository": {
+			check: api.RepositoryConsistencyCheck{Spec: api.RepositoryConsistencyCheckSpec{Repair: true}},
+		},
+		"findings": {
+			check: api.RepositoryConsistencyCheck{
+				Spec: api.RepositoryConsistencyCheckSpec{RepositoryName: "blueprints"},
+				Status: api.RepositoryConsistencyCheckStatus{Findings: []api.ConsistencyFinding{
+					{Type: api.ConsistencyFindingOrphanedMetadata, PackageRevision: "blueprints-1111"},
+				}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs := s.Validate(context.Background(), &tc.check)
+			if tc.valid && len(errs) != 0 {
+				t.Errorf("check was rejected: %v", errs)
+			}
+			if !tc.valid && len(errs) == 0 {
+				t.Errorf("check was accepted")
+			}
+		})
+	}
+}
@@ -63,6 +63,78 @@ func NewRESTStorage(scheme *runtime.Scheme, codecs serializer.CodecFactory, cad
 		},
 	}
 
+	packageRevisionsDeployment := &packageRevisionsDeployment{
+		common: packageCommon{
+			scheme:         scheme,
+			cad:            cad,
+			coreClient:     coreClient,
+			gr:             porch.Resource("packagerevisions"),
+			updateStrategy: packageRevisionDeploymentStrategy{},
+			createStrategy: packageRevisionDeploymentStrategy{},
+		},
+	}
+
+	packageRevisionsLogs := &packageRevisionsLogs{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			gr:         porch.Resource("packagerevisions"),
+		},
+	}
+
+	packagesFreeze := &packagesFreeze{
+		common: packageCommon{
+			scheme:         scheme,
+			cad:            cad,
+			coreClient:     coreClient,
+			gr:             porch.Resource("packages"),
+			updateStrategy: packageFreezeStrategy{},
+			createStrategy: packageFreezeStrategy{},
+		},
+	}
+
+	packagesTimeline := &packagesTimeline{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			gr:         porch.Resource("packages"),
+		},
+	}
+
+	repositoryConsistencyChecks := &repositoryConsistencyChecks{
+		common: packageCommon{
+			scheme:         scheme,
+			cad:            cad,
+			coreClient:     coreClient,
+			gr:             porch.Resource("repositoryconsistencychecks"),
+			createStrategy: repositoryConsistencyCheckStrategy{},
+		},
+	}
+
+	packageMoves := &packageMoves{
+		common: packageCommon{
+			scheme:         scheme,
+			cad:            cad,
+			coreClient:     coreClient,
+			gr:             porch.Resource("packagemoves"),
+			createStrategy: packageMoveStrategy{},
+		},
+	}
+
+	bulkApprovals := &bulkApprovals{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			// The package revisions of an approval are looked up, and reported missing,
+			// as packagerevisions.
+			gr:             porch.Resource("packagerevisions"),
+			createStrategy: bulkApprovalStrategy{},
+		},
+	}
+
 	packageRevisionResources := &packageRevisionResources{
 		TableConvertor: packageRevisionResourcesTableConvertor,
 		packageCommon: packageCommon{
@@ -73,6 +145,33 @@ func NewRESTStorage(scheme *runtime.Scheme, codecs serializer.CodecFactory, cad
 		},
 	}
 
+	packageRevisionResourcesStaging := &packageRevisionResourcesStaging{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			gr:         porch.Resource("packagerevisionresources"),
+		},
+	}
+
+	packageRevisionResourcesFinalize := &packageRevisionResourcesFinalize{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			gr:         porch.Resource("packagerevisionresources"),
+		},
+	}
+
+	packageRevisionResourcesCanonical := &packageRevisionResourcesCanonical{
+		common: packageCommon{
+			scheme:     scheme,
+			cad:        cad,
+			coreClient: coreClient,
+			gr:         porch.Resource("packagerevisionresources"),
+		},
+	}
+
 	functions := &functions{
 		TableConvertor: rest.NewDefaultTableConvertor(porch.Resource("functions")),
 		cad:            cad,
@@ -83,11 +182,21 @@ func NewRESTStorage(scheme *runtime.Scheme, codecs serializer.CodecFactory, cad
 
 	group.VersionedResourcesStorageMap = map[string]map[string]rest.Storage{
 		apiv1alpha1.SchemeGroupVersion.Version: {
-			"packages":                  packages,
-			"packagerevisions":          packageRevisions,
-			"packagerevisions/approval": packageRevisionsApproval,
-			"packagerevisionresources":  packageRevisionResources,
-			"functions":                 functions,
+			"bulkapprovals":                      bulkApprovals,
+			"packages":                           packages,
+			"packages/freeze":                    packagesFreeze,
+			"packages/timeline":                  packagesTimeline,
+			"packagemoves":                       packageMoves,
+			"packagerevisions":                   packageRevisions,
+			"packagerevisions/approval":          packageRevisionsApproval,
+			"packagerevisions/deployment":        packageRevisionsDeployment,
+			"packagerevisions/logs":              packageRevisionsLogs,
+			"packagerevisionresources":           packageRevisionResources,
+			"packagerevisionresources/staging":   packageRevisionResourcesStaging,
+			"packagerevisionresources/finalize":  packageRevisionResourcesFinalize,
+			"packagerevisionresources/canonical": packageRevisionResourcesCanonical,
+			"functions":                          functions,
+			"repositoryconsistencychecks":        repositoryConsistencyChecks,
 		},
 	}
 
@@ -88,6 +88,20 @@ func (s packageRevisionStrategy) ValidateUpdate(ctx context.Context, obj, old ru
 		))
 	}
 
+	// Conditions are recorded in the Kptfile, which only has these statuses.
+	for i, c := range newRevision.Status.Conditions {
+		switch c.Status {
+		case api.ConditionTrue, api.ConditionFalse, api.ConditionUnknown:
+			// valid
+		default:
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("status", "conditions").Index(i).Child("status"), c.Status, []string{
+				string(api.ConditionTrue),
+				string(api.ConditionFalse),
+				string(api.ConditionUnknown),
+			}))
+		}
+	}
+
 	return allErrs
 }
 
@@ -16,13 +16,16 @@ package porch
 
 import (
 	"context"
+	"time"
 
 	"github.com/GoogleContainerTools/kpt/porch/api/porch"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/engine"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apiserver/pkg/registry/rest"
 )
 
@@ -120,6 +123,10 @@ var (
 				isLatest(pr),
 				pr.Spec.Lifecycle,
 				pr.Spec.RepositoryName,
+				ageInState(pr),
+				pr.Annotations[api.WorkspaceDescriptionAnnotation],
+				pr.Annotations[api.WorkspaceReferenceAnnotation],
+				syncStatus(pr),
 			}
 		},
 		columns: []metav1.TableColumnDefinition{
@@ -129,6 +136,10 @@ var (
 			{Name: "Latest", Type: "boolean"},
 			{Name: "Lifecycle", Type: "string"},
 			{Name: "Repository", Type: "string"},
+			{Name: "Age-In-State", Type: "string", Priority: 1, Description: "How long the package revision has been in its Draft or Proposed lifecycle."},
+			{Name: "Description", Type: "string", Priority: 1, Description: "Why the package revision exists, from the " + api.WorkspaceDescriptionAnnotation + " annotation."},
+			{Name: "Reference", Type: "string", Priority: 1, Description: "The ticket or pull request of the package revision, from the " + api.WorkspaceReferenceAnnotation + " annotation."},
+			{Name: "Synced", Type: "string", Priority: 1, Description: "The sync status last reported by the agent deploying the package revision; Unknown if the report is stale."},
 		},
 	}
 
@@ -161,3 +172,28 @@ func isLatest(pr *api.PackageRevision) bool {
 	val, ok := pr.Labels[api.LatestPackageRevisionKey]
 	return ok && val == api.LatestPackageRevisionValue
 }
+
+// ageInState returns how long a package revision which isn't published has been in its
+// current lifecycle, or an empty string if that isn't known.
+func ageInState(pr *api.PackageRevision) string {
+	var since metav1.Time
+	switch pr.Spec.Lifecycle {
+	case api.PackageRevisionLifecycleDraft:
+		since = pr.Status.DraftCreatedAt
+	case api.PackageRevisionLifecycleProposed:
+		since = pr.Status.ProposedAt
+	}
+	if since.IsZero() {
+		return ""
+	}
+	return duration.HumanDuration(time.Since(since.Time))
+}
+
+// syncStatus returns the sync status reported for a package revision, or an empty
+// string if none was reported.
+func syncStatus(pr *api.PackageRevision) string {
+	if pr.Status.DeploymentStatus == nil {
+		return ""
+	}
+	return string(engine.CurrentSyncStatus(pr.Status.DeploymentStatus, time.Now()))
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// PackagePathCollision is a pair of package paths of a repository which differ, but
+// which refer to the same directory when checked out on a case-insensitive or unicode
+// normalizing filesystem.
+type PackagePathCollision struct {
+	Path, Other string
+}
+
+// foldPathSegment returns the form of a path segment which is equal for segments
+// differing only by case or unicode normalization.
+func foldPathSegment(segment string) string {
+	return norm.NFC.String(cases.Fold().String(norm.NFC.String(segment)))
+}
+
+func foldPath(path string) []string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = foldPathSegment(s)
+	}
+	return segments
+}
+
+// PackagePathsCollide returns true if the package paths differ, but the paths or the
+// directories containing them differ only by case or unicode normalization. For example
+// "Foo" collides with "foo", and "Apps/foo" with "apps/bar".
+func PackagePathsCollide(a, b string) bool {
+	return segmentsCollide(strings.Split(a, "/"), foldPath(a), strings.Split(b, "/"), foldPath(b))
+}
+
+func segmentsCollide(a, foldedA, b, foldedB []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		return foldedA[i] == foldedB[i]
+	}
+	return false
+}
+
+// FindPackagePathCollisions returns the colliding pairs of the package paths, in order.
+func FindPackagePathCollisions(paths []string) []PackagePathCollision {
+	unique := map[string]bool{}
+	for _, p := range paths {
+		unique[p] = true
+	}
+	sorted := make([]string, 0, len(unique))
+	for p := range unique {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	segments := make([][]string, len(sorted))
+	folded := make([][]string, len(sorted))
+	for i, p := range sorted {
+		segments[i], folded[i] = strings.Split(p, "/"), foldPath(p)
+	}
+
+	var collisions []PackagePathCollision
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			// Paths can only collide if their first segments fold equal.
+			if folded[i][0] != folded[j][0] {
+				continue
+			}
+			if segmentsCollide(segments[i], folded[i], segments[j], folded[j]) {
+				collisions = append(collisions, PackagePathCollision{Path: sorted[i], Other: sorted[j]})
+			}
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPackagePathsCollide(t *testing.T) {
+	for _, tc := range []struct {
+		a, b    string
+		collide bool
+	}{
+		{a: "foo", b: "foo"},
+		{a: "foo", b: "bar"},
+		{a: "Foo", b: "foo", collide: true},
+		{a: "apps/Foo", b: "apps/foo", collide: true},
+		{a: "Apps/foo", b: "apps/bar", collide: true},
+		{a: "apps", b: "apps/foo"},
+		{a: "Apps", b: "apps/foo", collide: true},
+		{a: "STRASSE", b: "straße", collide: true},
+		// "é" precomposed and decomposed.
+		{a: "café", b: "café", collide: true},
+	} {
+		if got := PackagePathsCollide(tc.a, tc.b); got != tc.collide {
+			t.Errorf("PackagePathsCollide(%q, %q) = %t; want %t", tc.a, tc.b, got, tc.collide)
+		}
+	}
+}
+
+func TestFindPackagePathCollisions(t *testing.T) {
+	got := FindPackagePathCollisions([]string{"foo", "bar", "Foo", "foo", "catalog/a", "Catalog/b", "catalog/c"})
+	want := []PackagePathCollision{
+		{Path: "Catalog/b", Other: "catalog/a"},
+		{Path: "Catalog/b", Other: "catalog/c"},
+		{Path: "Foo", Other: "foo"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected collisions (-want, +got): %s", diff)
+	}
+}
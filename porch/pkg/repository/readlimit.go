@@ -0,0 +1,83 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReadLimit limits the package resources read by PackageRevision.GetResources. A zero
+// value for either limit means that dimension is unlimited.
+type ReadLimit struct {
+	MaxFiles int
+	MaxBytes int64
+}
+
+// ReadLimitExceededError is returned when reading package resources would exceed the
+// ReadLimit; Files and Bytes count the files read so far, including the one which
+// exceeded the limit.
+type ReadLimitExceededError struct {
+	Limit ReadLimit
+	Files int
+	Bytes int64
+}
+
+func (e *ReadLimitExceededError) Error() string {
+	if e.Limit.MaxFiles > 0 && e.Files > e.Limit.MaxFiles {
+		return fmt.Sprintf("package resources exceed the read limit: more than %d files", e.Limit.MaxFiles)
+	}
+	return fmt.Sprintf("package resources exceed the read limit: more than %d bytes", e.Limit.MaxBytes)
+}
+
+type readLimitKey struct{}
+
+// WithReadLimit returns a context in which repositories stop reading package resources
+// as soon as they exceed the limit, rather than reading all of them first.
+func WithReadLimit(ctx context.Context, limit ReadLimit) context.Context {
+	return context.WithValue(ctx, readLimitKey{}, limit)
+}
+
+// ReadCounter counts the package resources read against the limit set by WithReadLimit.
+type ReadCounter struct {
+	limit ReadLimit
+	files int
+	bytes int64
+}
+
+// NewReadCounter returns a counter for the limit set by WithReadLimit, or nil if ctx has
+// no limit.
+func NewReadCounter(ctx context.Context) *ReadCounter {
+	limit, ok := ctx.Value(readLimitKey{}).(ReadLimit)
+	if !ok || (limit.MaxFiles <= 0 && limit.MaxBytes <= 0) {
+		return nil
+	}
+	return &ReadCounter{limit: limit}
+}
+
+// Add counts a file of size bytes, and must be called before the file is read. It returns
+// a ReadLimitExceededError if reading the file would exceed the limit. A nil counter
+// counts nothing.
+func (c *ReadCounter) Add(size int64) error {
+	if c == nil {
+		return nil
+	}
+	c.files++
+	c.bytes += size
+	if (c.limit.MaxFiles > 0 && c.files > c.limit.MaxFiles) || (c.limit.MaxBytes > 0 && c.bytes > c.limit.MaxBytes) {
+		return &ReadLimitExceededError{Limit: c.limit, Files: c.files, Bytes: c.bytes}
+	}
+	return nil
+}
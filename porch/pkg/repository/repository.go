@@ -16,7 +16,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	"github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
@@ -26,6 +28,11 @@ import (
 // TODO: 	"sigs.k8s.io/kustomize/kyaml/filesys" FileSystem?
 type PackageResources struct {
 	Contents map[string]string
+
+	// FileModes are the modes of the files which aren't regular files, as in
+	// v1alpha1.PackageRevisionResourcesSpec. Resources produced without modes, such as
+	// by functions, keep the modes of the resources they were produced from.
+	FileModes map[string]v1alpha1.FileMode
 }
 
 type PackageRevisionKey struct {
@@ -121,6 +128,15 @@ type ListPackageRevisionFilter struct {
 
 	// Revision matches the revision of the package (spec.revision)
 	Revision string
+
+	// Limit is the maximum number of package revisions to list; zero lists all of them.
+	// The package revisions are listed by name, and a token to continue the list from
+	// is returned with each page but the last. Repositories ignore Limit and Continue.
+	Limit int
+
+	// Continue is the token returned with the previous page of the list, to list the
+	// next page.
+	Continue string
 }
 
 // Matches returns true if the provided PackageRevision satisfies the conditions in the filter.
@@ -179,10 +195,75 @@ type Repository interface {
 	// CreatePackage creates a new package
 	CreatePackage(ctx context.Context, obj *v1alpha1.Package) (Package, error)
 
+	// UpdatePackage updates a package. Repositories which can't update packages return
+	// an error wrapping ErrPackageUpdateNotSupported.
+	UpdatePackage(ctx context.Context, old Package, new *v1alpha1.Package) (Package, error)
+
 	// DeletePackage deletes a package
 	DeletePackage(ctx context.Context, old Package) error
 }
 
+// ErrPackageUpdateNotSupported is returned by repositories which can't update packages.
+var ErrPackageUpdateNotSupported = errors.New("updating packages is not supported")
+
+// PruneResult describes the references removed (or, in dry-run mode, that would be
+// removed) from a repository by PruneStaleRefs.
+type PruneResult struct {
+	// Refs are the names of the stale references, as they are named in the repository.
+	Refs []string
+	// Bytes is the size of the objects reachable only from the stale references.
+	Bytes int64
+}
+
+// RefPruner is implemented by repositories that can remove references which Porch
+// created but which no longer correspond to a package revision.
+type RefPruner interface {
+	// PruneStaleRefs finds the stale references and, unless dryRun is set, deletes them.
+	PruneStaleRefs(ctx context.Context, dryRun bool) (*PruneResult, error)
+}
+
+// SyncProgress is the progress of the initial sync of a repository whose content is
+// synced in the background.
+type SyncProgress struct {
+	// Synced is true once the initial sync completed.
+	Synced bool
+	// Started is when the initial sync started. It is zero if it didn't start yet.
+	Started time.Time
+}
+
+// SyncReporter is implemented by repositories which sync their content in the
+// background. Until the initial sync completes, a package revision missing from the
+// repository may only be missing because it wasn't synced yet.
+type SyncReporter interface {
+	// SyncProgress returns the progress of the initial sync.
+	SyncProgress() SyncProgress
+	// WaitForSync blocks until the initial sync completes or the context is done.
+	WaitForSync(ctx context.Context) error
+}
+
+// CacheDiscrepancy is a package revision a cache disagrees with the repository it caches
+// on.
+type CacheDiscrepancy struct {
+	// Name is the name of the package revision.
+	Name string
+	// Latest is true if the cache only disagrees on whether the package revision is the
+	// latest revision of its package.
+	Latest bool
+	// Message describes the discrepancy.
+	Message string
+}
+
+// CacheChecker is implemented by repositories caching the package revisions of another
+// repository.
+type CacheChecker interface {
+	// CheckCache compares the cached package revisions with those of the cached
+	// repository, without changing the cache.
+	CheckCache(ctx context.Context) ([]CacheDiscrepancy, error)
+	// RefreshCache replaces the cached package revisions with those of the cached
+	// repository.
+	RefreshCache(ctx context.Context) error
+}
+
 type FunctionRepository interface {
 	// TODO: Should repository understand functions, or just packages (and function is just a package in an OCI repo?)
 	ListFunctions(ctx context.Context) ([]Function, error)
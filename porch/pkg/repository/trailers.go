@@ -0,0 +1,38 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import "context"
+
+// CommitTrailer is a trailer, a `Key: value` line, added to the commit publishing a
+// package revision.
+type CommitTrailer struct {
+	Key   string
+	Value string
+}
+
+type commitTrailersKey struct{}
+
+// WithCommitTrailers returns a context in which the drafts published when closed add the
+// trailers to the commit publishing them. Repositories without commits ignore them.
+func WithCommitTrailers(ctx context.Context, trailers []CommitTrailer) context.Context {
+	return context.WithValue(ctx, commitTrailersKey{}, trailers)
+}
+
+// CommitTrailersFrom returns the trailers set by WithCommitTrailers, if any.
+func CommitTrailersFrom(ctx context.Context) []CommitTrailer {
+	trailers, _ := ctx.Value(commitTrailersKey{}).([]CommitTrailer)
+	return trailers
+}
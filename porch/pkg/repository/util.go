@@ -15,8 +15,6 @@
 package repository
 
 import (
-	"fmt"
-
 	kptfile "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 )
@@ -54,9 +52,10 @@ func toApiConditionStatus(s kptfile.ConditionStatus) api.ConditionStatus {
 		return api.ConditionTrue
 	case kptfile.ConditionFalse:
 		return api.ConditionFalse
-	case kptfile.ConditionUnknown:
-		return api.ConditionUnknown
 	default:
-		panic(fmt.Errorf("unknown condition status: %v", s))
+		// The Kptfile can be edited like the other resources of the package, so its
+		// condition statuses may be anything. Statuses other than True and False are
+		// treated as unknown.
+		return api.ConditionUnknown
 	}
 }
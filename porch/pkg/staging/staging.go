@@ -0,0 +1,298 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package staging supports resumable uploads of package contents. The contents are
+// split into content-addressed chunks, which are staged on the server one by one and
+// assembled into the package resources once all chunks of the manifest are staged.
+// An interrupted upload is resumed by uploading only the chunks the server is missing.
+package staging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"k8s.io/klog/v2"
+)
+
+// Manifest lists the chunks making up each file of the package, in order.
+type Manifest struct {
+	Files map[string][]string `json:"files"`
+}
+
+// Digests returns the distinct digests of the chunks of the manifest, sorted.
+func (m Manifest) Digests() []string {
+	seen := map[string]bool{}
+	var digests []string
+	for _, chunks := range m.Files {
+		for _, digest := range chunks {
+			if !seen[digest] {
+				seen[digest] = true
+				digests = append(digests, digest)
+			}
+		}
+	}
+	sort.Strings(digests)
+	return digests
+}
+
+// Uploader uploads chunks to the staging area of one package revision.
+type Uploader interface {
+	// Missing returns the digests which aren't staged yet.
+	Missing(ctx context.Context, digests []string) ([]string, error)
+	// Put stages a chunk.
+	Put(ctx context.Context, digest string, data []byte) error
+}
+
+// Upload stages the chunks of the manifest which the uploader is missing, and returns
+// how many chunks were uploaded. Running Upload again after it failed resumes the upload.
+func Upload(ctx context.Context, uploader Uploader, manifest Manifest, chunks map[string][]byte) (int, error) {
+	missing, err := uploader.Missing(ctx, manifest.Digests())
+	if err != nil {
+		return 0, err
+	}
+	uploaded := 0
+	for _, digest := range missing {
+		data, found := chunks[digest]
+		if !found {
+			return uploaded, fmt.Errorf("chunk %s of the manifest not found", digest)
+		}
+		if err := uploader.Put(ctx, digest, data); err != nil {
+			return uploaded, fmt.Errorf("cannot upload chunk %s: %w", digest, err)
+		}
+		uploaded++
+	}
+	return uploaded, nil
+}
+
+// Store keeps the staged chunks of uploads on disk, in one directory per upload key, so
+// that an upload can be resumed after the server restarts. Uploads without activity
+// for longer than the TTL are garbage-collected.
+type Store struct {
+	dir string
+	ttl time.Duration
+	// now returns the current time; tests replace it.
+	now func() time.Time
+
+	mutex sync.Mutex
+}
+
+// NewStore creates a store keeping the staged chunks in dir, and garbage-collecting
+// uploads without activity for longer than ttl.
+func NewStore(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("cannot create staging directory: %w", err)
+	}
+	return &Store{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}, nil
+}
+
+// Put stages a chunk of the upload. The digest must match the contents of the chunk.
+func (s *Store) Put(key, digest string, data []byte) error {
+	if actual := api.StagedChunkDigest(data); actual != digest {
+		return fmt.Errorf("chunk digest mismatch: got %s, want %s", actual, digest)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.collectLocked()
+	dir := s.uploadDir(key)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("cannot create upload directory: %w", err)
+	}
+	// The chunk is renamed into place, so that a partially written chunk is never staged.
+	tmp, err := os.CreateTemp(dir, "chunk-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot stage chunk %s: %w", digest, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot stage chunk %s: %w", digest, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot stage chunk %s: %w", digest, err)
+	}
+	if err := os.Rename(tmp.Name(), chunkPath(dir, digest)); err != nil {
+		return fmt.Errorf("cannot stage chunk %s: %w", digest, err)
+	}
+	s.touchLocked(dir)
+	return nil
+}
+
+// Missing returns the digests which aren't staged for the upload.
+func (s *Store) Missing(key string, digests []string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.collectLocked()
+	dir := s.uploadDir(key)
+	var missing []string
+	for _, digest := range digests {
+		staged, err := isStaged(dir, digest)
+		if err != nil {
+			return nil, err
+		}
+		if !staged {
+			missing = append(missing, digest)
+		}
+	}
+	s.touchLocked(dir)
+	return missing, nil
+}
+
+// Assemble returns the files of the manifest, assembled from the staged chunks of the
+// upload. It fails if any chunk isn't staged.
+func (s *Store) Assemble(key string, manifest Manifest) (map[string]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.collectLocked()
+	dir := s.uploadDir(key)
+	var missing []string
+	files := map[string]string{}
+	for path, digests := range manifest.Files {
+		var contents strings.Builder
+		for _, digest := range digests {
+			staged, err := isStaged(dir, digest)
+			if err != nil {
+				return nil, err
+			}
+			if !staged {
+				missing = append(missing, digest)
+				continue
+			}
+			chunk, err := os.ReadFile(chunkPath(dir, digest))
+			if err != nil {
+				return nil, fmt.Errorf("cannot read staged chunk %s: %w", digest, err)
+			}
+			contents.Write(chunk)
+		}
+		files[path] = contents.String()
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &MissingChunksError{Digests: missing}
+	}
+	return files, nil
+}
+
+// Discard removes the staged chunks of the upload.
+func (s *Store) Discard(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return os.RemoveAll(s.uploadDir(key))
+}
+
+// Uploader returns an Uploader staging chunks of the upload in the store.
+func (s *Store) Uploader(key string) Uploader {
+	return &storeUploader{store: s, key: key}
+}
+
+// MissingChunksError is returned by Assemble if chunks of the manifest aren't staged.
+type MissingChunksError struct {
+	Digests []string
+}
+
+func (e *MissingChunksError) Error() string {
+	return fmt.Sprintf("%d chunks of the manifest are not staged: %s", len(e.Digests), strings.Join(e.Digests, ", "))
+}
+
+// uploadDir returns the directory of the upload. Keys are hashed, as they contain
+// namespaces and names which aren't valid path elements on every platform.
+func (s *Store) uploadDir(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// touchLocked records activity on the upload directory, if it exists. The caller must
+// hold the mutex.
+func (s *Store) touchLocked(dir string) {
+	now := s.now()
+	if err := os.Chtimes(dir, now, now); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("cannot record activity of staged upload %s: %v", dir, err)
+	}
+}
+
+// collectLocked removes the uploads whose TTL expired, using the modification time of
+// their directories as time of the last activity. The caller must hold the mutex.
+func (s *Store) collectLocked() {
+	if s.ttl <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		klog.Warningf("cannot list staged uploads: %v", err)
+		return
+	}
+	expiry := s.now().Add(-s.ttl)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(expiry) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, entry.Name())); err != nil {
+			klog.Warningf("cannot remove expired staged upload %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+// chunkPath returns the path of a staged chunk in the upload directory.
+func chunkPath(dir, digest string) string {
+	return filepath.Join(dir, strings.Replace(digest, ":", "-", 1))
+}
+
+// isStaged returns whether the chunk is staged in the upload directory. Digests are
+// validated, as they are used as file names.
+func isStaged(dir, digest string) (bool, error) {
+	if err := api.ValidateStagedChunkDigest(digest); err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(chunkPath(dir, digest)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot check staged chunk %s: %w", digest, err)
+	}
+	return true, nil
+}
+
+type storeUploader struct {
+	store *Store
+	key   string
+}
+
+func (u *storeUploader) Missing(ctx context.Context, digests []string) ([]string, error) {
+	return u.store.Missing(u.key, digests)
+}
+
+func (u *storeUploader) Put(ctx context.Context, digest string, data []byte) error {
+	return u.store.Put(u.key, digest, data)
+}
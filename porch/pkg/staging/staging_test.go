@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package staging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+)
+
+// split splits the files into chunks like clients do, and returns their manifest.
+func split(files map[string]string, chunkSize int) (Manifest, map[string][]byte) {
+	manifestFiles, chunks := api.SplitResources(files, chunkSize)
+	return Manifest{Files: manifestFiles}, chunks
+}
+
+// interruptedUploader fails after a number of chunks was uploaded, like a dropped
+// connection.
+type interruptedUploader struct {
+	Uploader
+	remaining int
+	puts      int
+}
+
+func (u *interruptedUploader) Put(ctx context.Context, digest string, data []byte) error {
+	if u.remaining == 0 {
+		return errors.New("connection reset")
+	}
+	u.remaining--
+	u.puts++
+	return u.Uploader.Put(ctx, digest, data)
+}
+
+func testFiles() map[string]string {
+	files := map[string]string{
+		"Kptfile": "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: app\n",
+		"empty":   "",
+	}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("configmap-%d.yaml", i)] = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-%d\ndata:\n  value: %s\n", i, strings.Repeat("x", 100*i))
+	}
+	return files
+}
+
+func TestResumeUpload(t *testing.T) {
+	ctx := context.Background()
+	files := testFiles()
+	manifest, chunks := split(files, 64)
+	total := len(manifest.Digests())
+	store := newTestStore(t, t.TempDir())
+
+	// The first attempt is interrupted after half the chunks.
+	first := &interruptedUploader{Uploader: store.Uploader("default/app"), remaining: total / 2}
+	if _, err := Upload(ctx, first, manifest, chunks); err == nil {
+		t.Fatalf("interrupted upload succeeded")
+	}
+	if _, err := store.Assemble("default/app", manifest); err == nil {
+		t.Errorf("assembled an incomplete upload")
+	}
+
+	// The second attempt uploads only the remainder.
+	second := &interruptedUploader{Uploader: store.Uploader("default/app"), remaining: -1}
+	uploaded, err := Upload(ctx, second, manifest, chunks)
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if want := total - total/2; uploaded != want || second.puts != want {
+		t.Errorf("second attempt uploaded %d chunks (%d puts); want %d of %d", uploaded, second.puts, want, total)
+	}
+
+	got, err := store.Assemble("default/app", manifest)
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	if diff := cmp.Diff(files, got); diff != "" {
+		t.Errorf("unexpected assembled files (-want, +got): %s", diff)
+	}
+}
+
+func TestResumeUploadAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := testFiles()
+	manifest, chunks := split(files, 64)
+	total := len(manifest.Digests())
+
+	first := &interruptedUploader{Uploader: newTestStore(t, dir).Uploader("default/app"), remaining: total / 2}
+	if _, err := Upload(ctx, first, manifest, chunks); err == nil {
+		t.Fatalf("interrupted upload succeeded")
+	}
+
+	// A new store on the same directory, like a restarted server, has the staged chunks.
+	store := newTestStore(t, dir)
+	missing, err := store.Missing("default/app", manifest.Digests())
+	if err != nil {
+		t.Fatalf("Missing failed: %v", err)
+	}
+	if want := total - total/2; len(missing) != want {
+		t.Errorf("restarted store is missing %d chunks; want %d", len(missing), want)
+	}
+	if _, err := Upload(ctx, store.Uploader("default/app"), manifest, chunks); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	got, err := store.Assemble("default/app", manifest)
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	if diff := cmp.Diff(files, got); diff != "" {
+		t.Errorf("unexpected assembled files (-want, +got): %s", diff)
+	}
+
+	if err := store.Discard("default/app"); err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if _, err := store.Assemble("default/app", manifest); err == nil {
+		t.Errorf("assembled a discarded upload")
+	}
+}
+
+func TestStoreGarbageCollection(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	store := newTestStore(t, dir)
+	store.now = func() time.Time { return now }
+
+	manifest, chunks := split(map[string]string{"Kptfile": "kind: Kptfile\n"}, 64)
+	if _, err := Upload(context.Background(), store.Uploader("default/abandoned"), manifest, chunks); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if missing, err := store.Missing("default/abandoned", manifest.Digests()); err != nil || len(missing) != 1 {
+		t.Errorf("abandoned upload wasn't garbage-collected; missing %v (%v)", missing, err)
+	}
+	if _, err := os.Stat(store.uploadDir("default/abandoned")); !os.IsNotExist(err) {
+		t.Errorf("upload directory of the abandoned upload wasn't removed: %v", err)
+	}
+}
+
+func TestPutChecksDigest(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	if err := store.Put("default/app", api.StagedChunkDigest([]byte("a")), []byte("b")); err == nil {
+		t.Errorf("chunk with a mismatched digest was staged")
+	}
+}
+
+func TestMissingChecksDigest(t *testing.T) {
+	store := newTestStore(t, t.TempDir())
+	if _, err := store.Missing("default/app", []string{"sha256:../../etc/passwd"}); err == nil {
+		t.Errorf("invalid digest was accepted")
+	}
+}
+
+func newTestStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(dir, "staging"), time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return store
+}
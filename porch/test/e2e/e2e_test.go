@@ -29,6 +29,7 @@ import (
 	configapi "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	internalapi "github.com/GoogleContainerTools/kpt/porch/internal/api/porchinternal/v1alpha1"
 	"github.com/GoogleContainerTools/kpt/porch/pkg/repository"
+	"github.com/GoogleContainerTools/kpt/porch/pkg/staging"
 	"github.com/google/go-cmp/cmp"
 	coreapi "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -546,6 +547,109 @@ func (t *PorchSuite) TestUpdateResources(ctx context.Context) {
 	}
 }
 
+func (t *PorchSuite) TestResumeStagedPush(ctx context.Context) {
+	const (
+		repository  = "staged-push"
+		packageName = "staged-package"
+	)
+
+	t.registerMainGitRepositoryF(ctx, repository)
+
+	pr := &porchapi.PackageRevision{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PackageRevision",
+			APIVersion: porchapi.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: t.namespace,
+		},
+		Spec: porchapi.PackageRevisionSpec{
+			PackageName:    packageName,
+			Revision:       "v1",
+			RepositoryName: repository,
+		},
+	}
+	t.CreateF(ctx, pr)
+
+	var resources porchapi.PackageRevisionResources
+	t.GetF(ctx, client.ObjectKey{Namespace: t.namespace, Name: pr.Name}, &resources)
+	files := resources.Spec.Resources
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("configmap-%d.yaml", i)] = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-%d\ndata:\n  value: %s\n", i, strings.Repeat("x", 1000*i))
+	}
+	manifestFiles, chunks := porchapi.SplitResources(files, 1024)
+	digests := staging.Manifest{Files: manifestFiles}.Digests()
+
+	stage := func(digests []string, chunkDigests []string) *porchapi.PackageRevisionResourcesStaging {
+		obj := &porchapi.PackageRevisionResourcesStaging{
+			ObjectMeta: metav1.ObjectMeta{Namespace: t.namespace, Name: pr.Name},
+			Spec:       porchapi.PackageRevisionResourcesStagingSpec{Digests: digests},
+		}
+		for _, digest := range chunkDigests {
+			obj.Spec.Chunks = append(obj.Spec.Chunks, porchapi.StagedChunk{Digest: digest, Data: chunks[digest]})
+		}
+		var result porchapi.PackageRevisionResourcesStaging
+		if err := t.clientset.PorchV1alpha1().RESTClient().Post().
+			Namespace(t.namespace).
+			Resource("packagerevisionresources").
+			Name(pr.Name).
+			SubResource("staging").
+			Body(obj).
+			Do(ctx).
+			Into(&result); err != nil {
+			t.Fatalf("Staging chunks of %s failed: %v", pr.Name, err)
+		}
+		return &result
+	}
+	finalize := func() (*porchapi.PackageRevisionResources, error) {
+		var result porchapi.PackageRevisionResources
+		err := t.clientset.PorchV1alpha1().RESTClient().Post().
+			Namespace(t.namespace).
+			Resource("packagerevisionresources").
+			Name(pr.Name).
+			SubResource("finalize").
+			Body(&porchapi.PackageRevisionResourcesFinalize{
+				ObjectMeta: metav1.ObjectMeta{Namespace: t.namespace, Name: pr.Name},
+				Spec:       porchapi.PackageRevisionResourcesFinalizeSpec{Files: manifestFiles},
+			}).
+			Do(ctx).
+			Into(&result)
+		return &result, err
+	}
+
+	// The first push is interrupted after staging half the chunks.
+	if missing := stage(digests, nil).Status.Missing; len(missing) != len(digests) {
+		t.Fatalf("Server is missing %d of %d chunks before the push; want all", len(missing), len(digests))
+	}
+	half := len(digests) / 2
+	stage(nil, digests[:half])
+	if _, err := finalize(); !apierrors.IsBadRequest(err) {
+		t.Fatalf("Finalizing the interrupted push returned %v; want a BadRequest error", err)
+	}
+
+	// The resumed push uploads only the chunks the server is missing.
+	missing := stage(digests, nil).Status.Missing
+	if diff := cmp.Diff(digests[half:], missing); diff != "" {
+		t.Fatalf("Unexpected missing chunks of the resumed push (-want, +got): %s", diff)
+	}
+	stage(nil, missing)
+	updated, err := finalize()
+	if err != nil {
+		t.Fatalf("Finalizing the resumed push failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		path := fmt.Sprintf("configmap-%d.yaml", i)
+		if got, want := updated.Spec.Resources[path], files[path]; got != want {
+			t.Errorf("Unexpected contents of %s after the push: got %q, want %q", path, got, want)
+		}
+	}
+
+	// The staged chunks are discarded once the push is finalized.
+	if missing := stage(digests, nil).Status.Missing; len(missing) != len(digests) {
+		t.Errorf("Server still has %d staged chunks after the push was finalized", len(digests)-len(missing))
+	}
+}
+
 func (t *PorchSuite) TestFunctionRepository(ctx context.Context) {
 	repo := &configapi.Repository{
 		ObjectMeta: metav1.ObjectMeta{